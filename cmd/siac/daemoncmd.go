@@ -1,7 +1,12 @@
 package main
 
 import (
+	"encoding/json"
 	"fmt"
+	"os"
+	"strings"
+	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -18,6 +23,14 @@ var (
 		Run:   wrap(alertscmd),
 	}
 
+	eventsCmd = &cobra.Command{
+		Use:   "events",
+		Short: "view recent daemon events",
+		Long: `View the recent events of the daemon's modules. Events can be streamed
+from the /daemon/events endpoint or posted to webhooks.`,
+		Run: wrap(eventscmd),
+	}
+
 	stopCmd = &cobra.Command{
 		Use:   "stop",
 		Short: "Stop the Sia daemon",
@@ -56,6 +69,29 @@ Set them to 0 for no limit.`,
 		Long:  "Print version information.",
 		Run:   wrap(versioncmd),
 	}
+
+	webhooksCmd = &cobra.Command{
+		Use:   "webhooks",
+		Short: "view daemon webhooks",
+		Long:  "View the webhooks that the daemon posts its events to.",
+		Run:   wrap(webhookscmd),
+	}
+
+	webhooksAddCmd = &cobra.Command{
+		Use:   "add [url]",
+		Short: "add a webhook",
+		Long: `Add a webhook that the daemon posts its events to. Use --events to only
+post events of certain types. If --secret is set, every request contains
+the hex encoded HMAC-SHA256 of its body in the Sia-Signature header.`,
+		Run: wrap(webhooksaddcmd),
+	}
+
+	webhooksRemoveCmd = &cobra.Command{
+		Use:   "remove [id]",
+		Short: "remove a webhook",
+		Long:  "Remove a webhook.",
+		Run:   wrap(webhooksremovecmd),
+	}
 )
 
 // alertscmd prints the alerts from the daemon. This will not print critical
@@ -96,6 +132,82 @@ func alertscmd() {
 	fmt.Printf("\n------------------\n\n")
 }
 
+// eventscmd prints the recent events of the daemon.
+func eventscmd() {
+	deg, err := httpClient.DaemonEventsGet(eventsSince)
+	if err != nil {
+		die("Could not get daemon events:", err)
+	}
+	if len(deg.Events) == 0 {
+		fmt.Println("No events.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tTime\tType\tData")
+	for _, e := range deg.Events {
+		data, err := json.Marshal(e.Data)
+		if err != nil {
+			die("Could not encode event data:", err)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%s\n", e.ID, e.Timestamp.Format(time.RFC3339), e.Type, data)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// webhookscmd prints the webhooks of the daemon.
+func webhookscmd() {
+	dwg, err := httpClient.DaemonWebhooksGet()
+	if err != nil {
+		die("Could not get daemon webhooks:", err)
+	}
+	if len(dwg.Webhooks) == 0 {
+		fmt.Println("No webhooks.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tURL\tEvents\tFailed Deliveries\tLast Error")
+	for _, wh := range dwg.Webhooks {
+		events := "all"
+		if len(wh.Events) > 0 {
+			types := make([]string, 0, len(wh.Events))
+			for _, et := range wh.Events {
+				types = append(types, string(et))
+			}
+			events = strings.Join(types, ",")
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\n", wh.ID, wh.URL, events, wh.FailedDeliveries, wh.LastError)
+	}
+	if err := w.Flush(); err != nil {
+		die("failed to flush writer:", err)
+	}
+}
+
+// webhooksaddcmd adds a webhook to the daemon.
+func webhooksaddcmd(url string) {
+	var events []modules.EventType
+	for _, t := range strings.Split(webhooksAddEvents, ",") {
+		if t = strings.TrimSpace(t); t != "" {
+			events = append(events, modules.EventType(t))
+		}
+	}
+	wh, err := httpClient.DaemonWebhooksAddPost(url, events, webhooksAddSecret)
+	if err != nil {
+		die("Could not add webhook:", err)
+	}
+	fmt.Println("Added webhook", wh.ID)
+}
+
+// webhooksremovecmd removes a webhook from the daemon.
+func webhooksremovecmd(id string) {
+	err := httpClient.DaemonWebhooksRemovePost(id)
+	if err != nil {
+		die("Could not remove webhook:", err)
+	}
+	fmt.Println("Removed webhook", id)
+}
+
 // version prints the version of siac and siad.
 func versioncmd() {
 	fmt.Println("Sia Client")
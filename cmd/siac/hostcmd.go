@@ -1,12 +1,14 @@
 package main
 
 import (
+	"encoding/csv"
 	"fmt"
 	"math/big"
 	"os"
 	"sort"
 	"strings"
 	"text/tabwriter"
+	"time"
 
 	"github.com/spf13/cobra"
 
@@ -28,7 +30,10 @@ You can revert this by running:
 	siac host config acceptingcontracts false
 You may also supply a specific address to be announced, e.g.:
 	siac host announce my-host-domain.com:9001
-Doing so will override the standard connectivity checks.`,
+Doing so will override the standard connectivity checks.
+Hosts that are reachable on multiple addresses, e.g. over IPv4 and IPv6, can
+announce all of them. The first address is the primary address:
+	siac host announce 203.0.113.1:9982 [2001:db8::1]:9982`,
 		Run: hostannouncecmd,
 	}
 
@@ -39,6 +44,14 @@ Doing so will override the standard connectivity checks.`,
 		Run:   wrap(hostcmd),
 	}
 
+	hostClientsCmd = &cobra.Command{
+		Use:   "clients",
+		Short: "Show the host's clients",
+		Long: `Show the resources consumed by the renters and IP addresses that recently
+connected to the host, sorted by bandwidth.`,
+		Run: wrap(hostclientscmd),
+	}
+
 	hostConfigCmd = &cobra.Command{
 		Use:   "config [setting] [value]",
 		Short: "Modify host settings",
@@ -52,9 +65,26 @@ Available settings:
      netaddress:           string
      windowsize:           blocks
 
-     collateral:       currency
-     collateralbudget: currency
-     maxcollateral:    currency
+     alternatenetaddresses: comma-separated list of addresses
+
+     websocketaddress:     string
+     websockettlscertfile: string
+     websockettlskeyfile:  string
+
+     walletaccount: string
+
+     sectorcachesize: bytes
+     sectorcachedir:  string
+
+     clientbandwidthlimit:   bytes per second, e.g. 10Mbps or 1MB/s
+     clientreadrpclimit:     RPCs per second
+     clientsettingsrpclimit: RPCs per second
+     clientwriterpclimit:    RPCs per second
+
+     collateral:               currency
+     collateralbudget:         currency
+     collateralforecastwindow: blocks
+     maxcollateral:            currency
 
      minbaserpcprice:           currency
      mincontractprice:          currency
@@ -69,7 +99,7 @@ Available settings:
 
 Currency units can be specified, e.g. 10SC; run 'siac help wallet' for details.
 
-Durations (maxduration and windowsize) must be specified in either blocks (b),
+Durations (maxduration, windowsize and collateralforecastwindow) must be specified in either blocks (b),
 hours (h), days (d), or weeks (w). A block is approximately 10 minutes, so one
 hour is six blocks, a day is 144 blocks, and a week is 1008 blocks.
 
@@ -77,6 +107,27 @@ Timeouts (ephemeralaccountexpiry) must be specified in either seconds (s),
 hours (h), days (d), or weeks (w). One hour is 3600 seconds, a day is 86400
 seconds, and a week is 604800 seconds.
 
+The client limits apply separately to every renter and IP address. A limit of
+0 disables it.
+
+The alternate net addresses are announced alongside the netaddress on the next
+announcement. Renters try them if the host can't be reached on the netaddress.
+An empty value clears them.
+
+The host serves the renter-host protocol over WebSocket on websocketaddress,
+e.g. :9984, for renters that can't reach it over raw TCP. If a TLS certificate
+and key are set, the WebSocket listener only accepts TLS connections. An empty
+websocketaddress disables the listener.
+
+The host funds its collateral, announcements and storage proofs from the wallet
+account walletaccount and receives its payouts there. An empty value selects
+the primary account. Run 'siac wallet accounts' to list the accounts.
+
+The collateral planner projects the wallet funds needed to renew the contracts
+that expire within collateralforecastwindow and to pay their transaction fees.
+New contracts are refused if the wallet can't fund them on top of that. A
+window of 0 disables the planner.
+
 For a description of each parameter, see doc/API.md.
 
 To configure the host to accept new contracts, set acceptingcontracts to true:
@@ -85,6 +136,37 @@ To configure the host to accept new contracts, set acceptingcontracts to true:
 		Run: wrap(hostconfigcmd),
 	}
 
+	hostConfigAutoPricingCmd = &cobra.Command{
+		Use:   "autopricing [setting] [value]",
+		Short: "Modify the host's auto-pricing settings",
+		Long: `Modify the settings of the host's auto-pricing engine. While the engine
+is enabled, it periodically adjusts the host's prices within the configured
+floors and ceilings based on storage utilization, bandwidth saturation and the
+prices of other hosts on the network. A ceiling of 0 means that the price is
+unbounded. The host never charges less than the min prices set with
+'siac host config'.
+
+Available settings:
+     enabled:           boolean
+     bandwidthcapacity: bytes per second, e.g. 100Mbps or 10MB/s
+
+     minbaserpcprice:           currency
+     maxbaserpcprice:           currency
+     mindownloadbandwidthprice: currency / TB
+     maxdownloadbandwidthprice: currency / TB
+     minsectoraccessprice:      currency
+     maxsectoraccessprice:      currency
+     minstorageprice:           currency / TB / Month
+     maxstorageprice:           currency / TB / Month
+     minuploadbandwidthprice:   currency / TB
+     maxuploadbandwidthprice:   currency / TB
+
+To enable the auto-pricing engine:
+	siac host config autopricing enabled true
+`,
+		Run: wrap(hostconfigautopricingcmd),
+	}
+
 	hostContractCmd = &cobra.Command{
 		Use:   "contracts",
 		Short: "Show host contracts",
@@ -93,10 +175,31 @@ To configure the host to accept new contracts, set acceptingcontracts to true:
 Available output types:
      value:  show financial information
      status: show status information
+
+The contracts can be filtered by status and renter public key, sorted by
+collateral, datasize, expiration, negotiation or revenue, and paged with
+--offset and --limit. With --export the selected contracts are written to a
+CSV file instead, with all amounts in hastings.
 `,
 		Run: wrap(hostcontractcmd),
 	}
 
+	hostContractSummaryCmd = &cobra.Command{
+		Use:   "summary",
+		Short: "Show contract expirations per week",
+		Long: `Show the number of unresolved contracts that expire in each of the coming
+weeks, along with their data, collateral and potential revenue. Useful for
+capacity planning.`,
+		Run: wrap(hostcontractsummarycmd),
+	}
+
+	hostContractViewCmd = &cobra.Command{
+		Use:   "view [obligationid]",
+		Short: "Show the details of a host contract",
+		Long:  "Show the details of a host contract, including its recent revisions and storage proof transactions.",
+		Run:   wrap(hostcontractviewcmd),
+	}
+
 	hostFolderAddCmd = &cobra.Command{
 		Use:   "add [path] [size]",
 		Short: "Add a storage folder to the host",
@@ -106,8 +209,29 @@ Available output types:
 
 	hostFolderCmd = &cobra.Command{
 		Use:   "folder",
-		Short: "Add, remove, or resize a storage folder",
-		Long:  "Add, remove, or resize a storage folder.",
+		Short: "Add, remove, resize, or migrate a storage folder",
+		Long:  "Add, remove, resize, or migrate a storage folder.",
+	}
+
+	hostFolderMigrateCmd = &cobra.Command{
+		Use:   "migrate [source] [destination]",
+		Short: "Move sectors from one storage folder to another",
+		Long: `Move sectors from the source storage folder to the destination storage folder
+in the background. All sectors are moved unless the number of sectors is
+specified with --sectors. While all of its sectors are moved, the source folder
+doesn't receive new data, which allows replacing a disk without shrinking the
+host's capacity. The migration resumes after a restart of the host. Its progress
+is shown by 'siac host -v'.`,
+		Run: wrap(hostfoldermigratecmd),
+	}
+
+	hostFolderRebalanceCmd = &cobra.Command{
+		Use:   "rebalance",
+		Short: "Even out the utilization of the storage folders",
+		Long: `Move sectors between the storage folders in the background until all of them
+have about the same utilization. The rebalance resumes after a restart of the
+host. Its progress is shown by 'siac host -v'.`,
+		Run: wrap(hostfolderrebalancecmd),
 	}
 
 	hostFolderRemoveCmd = &cobra.Command{
@@ -128,6 +252,39 @@ other storage folders.`,
 		Run: wrap(hostfolderresizecmd),
 	}
 
+	hostMaintenanceCmd = &cobra.Command{
+		Use:   "maintenance",
+		Short: "Start, stop, or view the host's maintenance mode",
+		Long: `Start, stop, or view the host's maintenance mode. While in maintenance mode
+the host refuses new contracts, renewals and writes, but keeps serving reads and
+submitting storage proofs.`,
+		Run: wrap(hostmaintenancestatuscmd),
+	}
+
+	hostMaintenanceStartCmd = &cobra.Command{
+		Use:   "start [duration]",
+		Short: "Put the host into maintenance mode",
+		Long: `Put the host into maintenance mode for the planned duration, e.g. 4h or
+90m. The host stays in maintenance mode until it is stopped, even if the planned
+duration has passed.`,
+		Run: wrap(hostmaintenancestartcmd),
+	}
+
+	hostMaintenanceStatusCmd = &cobra.Command{
+		Use:   "status",
+		Short: "View the host's maintenance mode",
+		Long: `View whether the host is in maintenance mode and which contracts have a
+proof window during the planned downtime.`,
+		Run: wrap(hostmaintenancestatuscmd),
+	}
+
+	hostMaintenanceStopCmd = &cobra.Command{
+		Use:   "stop",
+		Short: "Take the host out of maintenance mode",
+		Long:  "Take the host out of maintenance mode.",
+		Run:   wrap(hostmaintenancestopcmd),
+	}
+
 	hostSectorCmd = &cobra.Command{
 		Use:   "sector",
 		Short: "Add or delete a sector (add not supported)",
@@ -163,6 +320,7 @@ func hostcmd() {
 
 	es := hg.ExternalSettings
 	fm := hg.FinancialMetrics
+	cf := hg.CollateralForecast
 	is := hg.InternalSettings
 	nm := hg.NetworkMetrics
 
@@ -192,6 +350,15 @@ func hostcmd() {
 		netaddr += " (manually specified)"
 	}
 
+	altAddrs := "none"
+	if len(is.AlternateNetAddresses) > 0 {
+		addrs := make([]string, 0, len(is.AlternateNetAddresses))
+		for _, addr := range is.AlternateNetAddresses {
+			addrs = append(addrs, string(addr))
+		}
+		altAddrs = strings.Join(addrs, ", ")
+	}
+
 	var connectabilityString string
 	if hg.WorkingStatus == "working" {
 		connectabilityString = "Host appears to be working."
@@ -217,9 +384,18 @@ Host Internal Settings:
 	netaddress:           %v
 	windowsize:           %v Hours
 
-	collateral:       %v / TB / Month
-	collateralbudget: %v
-	maxcollateral:    %v Per Contract
+	alternatenetaddresses: %v
+
+	websocketaddress:     %v
+	websockettlscertfile: %v
+	websockettlskeyfile:  %v
+
+	walletaccount: %v
+
+	collateral:               %v / TB / Month
+	collateralbudget:         %v
+	collateralforecastwindow: %v Blocks
+	maxcollateral:            %v Per Contract
 
 	mincontractprice:          %v
 	mindownloadbandwidthprice: %v / TB
@@ -248,6 +424,14 @@ Host Financials:
 	Upload Revenue:             %v
 	Potential Upload Revenue:   %v
 
+Collateral Forecast (blocks %v to %v):
+	Wallet Balance:     %v
+	Maturing Payouts:   %v
+	Renewal Collateral: %v
+	Transaction Fees:   %v
+	Projected Balance:  %v
+	Shortfall:          %v
+
 RPC Stats:
 	Error Calls:        %v
 	Unrecognized Calls: %v
@@ -265,8 +449,15 @@ RPC Stats:
 			modules.FilesizeUnits(is.MaxReviseBatchSize), netaddr,
 			is.WindowSize/6,
 
+			altAddrs,
+
+			is.WebSocketAddress, is.WebSocketTLSCertFile, is.WebSocketTLSKeyFile,
+
+			walletAccountName(is.WalletAccount),
+
 			currencyUnits(is.Collateral.Mul(modules.BlockBytesPerMonthTerabyte)),
 			currencyUnits(is.CollateralBudget),
+			is.CollateralForecastWindow,
 			currencyUnits(is.MaxCollateral),
 
 			currencyUnits(is.MinContractPrice),
@@ -294,6 +485,11 @@ RPC Stats:
 			currencyUnits(fm.UploadBandwidthRevenue),
 			currencyUnits(fm.PotentialUploadBandwidthRevenue),
 
+			cf.BlockHeight, cf.EndHeight,
+			currencyUnits(cf.Balance), currencyUnits(cf.Payouts),
+			currencyUnits(cf.RenewalCollateral), currencyUnits(cf.TransactionFees),
+			currencyUnits(cf.Projected), currencyUnits(cf.Shortfall),
+
 			nm.ErrorCalls, nm.UnrecognizedCalls, nm.DownloadCalls,
 			nm.RenewCalls, nm.ReviseCalls, nm.SettingsCalls,
 			nm.FormContractCalls)
@@ -321,6 +517,11 @@ RPC Stats:
 			currencyUnits(totalRevenue))
 	}
 
+	// if the wallet can't fund the projected needs print warning
+	if !cf.Shortfall.IsZero() {
+		fmt.Printf("\nWarning:\n	Your wallet is projected to be %v short of the collateral and fees needed\n	over the next %v blocks. New contracts are refused until you add funds.\n", currencyUnits(cf.Shortfall), cf.Window)
+	}
+
 	// if wallet is locked print warning
 	walletstatus, walleterr := httpClient.WalletGet()
 	if walleterr != nil {
@@ -345,9 +546,32 @@ RPC Stats:
 	for _, folder := range sg.Folders {
 		curSize := int64(folder.Capacity - folder.CapacityRemaining)
 		pctUsed := 100 * (float64(curSize) / float64(folder.Capacity))
-		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", modules.FilesizeUnits(uint64(curSize)), modules.FilesizeUnits(folder.Capacity), pctUsed, folder.Path)
+		path := folder.Path
+		if folder.ProgressDenominator > 0 {
+			path += fmt.Sprintf(" (%.2f%% of operation complete)", 100*float64(folder.ProgressNumerator)/float64(folder.ProgressDenominator))
+		}
+		if folder.MigrationError != "" {
+			path += fmt.Sprintf(" (migration failed: %v)", folder.MigrationError)
+		}
+		fmt.Fprintf(w, "\t%s\t%s\t%.2f\t%s\n", modules.FilesizeUnits(uint64(curSize)), modules.FilesizeUnits(folder.Capacity), pctUsed, path)
 	}
 	w.Flush()
+
+	// display sector cache statistics
+	if cache := sg.Cache; cache.Capacity > 0 {
+		fmt.Printf("\nSector Cache: %v / %v used, %v hits, %v misses\n", modules.FilesizeUnits(cache.Size), modules.FilesizeUnits(cache.Capacity), cache.Hits, cache.Misses)
+	}
+
+	// display scrubber progress
+	scrub := sg.Scrub
+	if scrub.TotalSectors == 0 {
+		return
+	}
+	scrubState := "idle"
+	if scrub.Running {
+		scrubState = "running"
+	}
+	fmt.Printf("\nScrubber (%v): %v/%v sectors verified, %v corrupt, %v read errors\n", scrubState, scrub.ScrubbedSectors, scrub.TotalSectors, scrub.CorruptSectors, scrub.ReadErrors)
 }
 
 // hostconfigcmd is the handler for the command `siac host config [setting] [value]`.
@@ -392,7 +616,7 @@ func hostconfigcmd(param, value string) {
 		}
 
 	// duration (convert to blocks)
-	case "maxduration", "windowsize":
+	case "collateralforecastwindow", "maxduration", "windowsize":
 		value, err = parsePeriod(value)
 		if err != nil {
 			die("Could not parse "+param+":", err)
@@ -405,8 +629,26 @@ func hostconfigcmd(param, value string) {
 			die("Could not parse "+param+":", err)
 		}
 
+	// filesize (convert to bytes)
+	case "sectorcachesize":
+		value, err = parseFilesize(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+
+	// bandwidth (convert to bytes per second)
+	case "clientbandwidthlimit":
+		bps, err := parseRatelimit(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		value = fmt.Sprint(bps)
+
 	// other valid settings
-	case "maxdownloadbatchsize", "maxrevisebatchsize", "netaddress":
+	case "clientreadrpclimit", "clientsettingsrpclimit", "clientwriterpclimit",
+		"maxdownloadbatchsize", "maxrevisebatchsize", "netaddress", "sectorcachedir",
+		"alternatenetaddresses", "websocketaddress", "websockettlscertfile", "websockettlskeyfile",
+		"walletaccount":
 
 	// invalid settings
 	default:
@@ -430,13 +672,105 @@ func hostconfigcmd(param, value string) {
 	fmt.Printf("Estimated conversion rate: %v%%\n", eg.ConversionRate)
 }
 
+// hostconfigautopricingcmd is the handler for the command `siac host config
+// autopricing [setting] [value]`.
+func hostconfigautopricingcmd(param, value string) {
+	var err error
+	switch param {
+	// currency (convert to hastings)
+	case "minbaserpcprice", "maxbaserpcprice", "minsectoraccessprice", "maxsectoraccessprice":
+		value, err = parseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+
+	// currency/TB (convert to hastings/byte)
+	case "mindownloadbandwidthprice", "maxdownloadbandwidthprice", "minuploadbandwidthprice", "maxuploadbandwidthprice":
+		hastings, err := parseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		c := types.NewCurrency(i).Div(modules.BytesPerTerabyte)
+		value = c.String()
+
+	// currency/TB/month (convert to hastings/byte/block)
+	case "minstorageprice", "maxstorageprice":
+		hastings, err := parseCurrency(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		i, _ := new(big.Int).SetString(hastings, 10)
+		c := types.NewCurrency(i).Div(modules.BlockBytesPerMonthTerabyte)
+		value = c.String()
+
+	// bandwidth (convert to bytes per second)
+	case "bandwidthcapacity":
+		bps, err := parseRatelimit(value)
+		if err != nil {
+			die("Could not parse "+param+":", err)
+		}
+		value = fmt.Sprint(bps)
+
+	// bool (allow "yes" and "no")
+	case "enabled":
+		switch strings.ToLower(value) {
+		case "yes":
+			value = "true"
+		case "no":
+			value = "false"
+		}
+		param = ""
+
+	// invalid settings
+	default:
+		die("\"" + param + "\" is not an auto-pricing setting")
+	}
+	err = httpClient.HostModifySettingPost(client.HostParam("autopricing"+param), value)
+	if err != nil {
+		die("Failed to update auto-pricing settings:", err)
+	}
+	fmt.Println("Auto-pricing settings updated.")
+}
+
+// hostclientscmd is the handler for the command `siac host clients`.
+func hostclientscmd() {
+	hcg, err := httpClient.HostClientsGet()
+	if err != nil {
+		die("Could not fetch host clients:", err)
+	}
+	if len(hcg.Clients) == 0 {
+		fmt.Println("No clients connected to the host recently.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Type\tID\tConnections\tDownload\tUpload\tRead RPCs\tWrite RPCs\tSettings RPCs\tRejected RPCs\tLast Seen\n")
+	for _, c := range hcg.Clients {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", c.Type, c.ID, c.Connections, modules.FilesizeUnits(c.Download), modules.FilesizeUnits(c.Upload),
+			c.ReadRPCs, c.WriteRPCs, c.SettingsRPCs, c.RejectedRPCs, c.LastSeen.Format(time.RFC822))
+	}
+	w.Flush()
+}
+
 // hostcontractcmd is the handler for the command `siac host contracts [type]`.
 func hostcontractcmd() {
-	cg, err := httpClient.HostContractInfoGet()
+	query := hostContractQuery()
+	query.SortBy = hostContractSortBy
+	query.Descending = hostContractDescending
+	query.Offset = hostContractOffset
+	query.Limit = hostContractLimit
+	cg, err := httpClient.HostContractInfoQueryGet(query)
 	if err != nil {
 		die("Could not fetch host contract info:", err)
 	}
-	sort.Slice(cg.Contracts, func(i, j int) bool { return cg.Contracts[i].ExpirationHeight < cg.Contracts[j].ExpirationHeight })
+	if hostContractExport != "" {
+		err = writeHostContractsCSV(hostContractExport, cg.Contracts)
+		if err != nil {
+			die("Could not export host contracts:", err)
+		}
+		fmt.Printf("Exported %v of %v contracts to %v\n", len(cg.Contracts), cg.Total, hostContractExport)
+		return
+	}
 	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
 	switch hostContractOutputType {
 	case "value":
@@ -456,11 +790,201 @@ func hostcontractcmd() {
 		die("\"" + hostContractOutputType + "\" is not a format")
 	}
 	w.Flush()
+	if uint64(len(cg.Contracts)) < cg.Total {
+		fmt.Printf("\nShowing %v of %v contracts.\n", len(cg.Contracts), cg.Total)
+	}
+}
+
+// hostcontractsummarycmd is the handler for the command `siac host contracts
+// summary`.
+func hostcontractsummarycmd() {
+	csg, err := httpClient.HostContractSummaryGet(hostContractQuery())
+	if err != nil {
+		die("Could not fetch host contract summary:", err)
+	}
+	if len(csg.Periods) == 0 {
+		fmt.Println("The host has no unresolved contracts.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Week\tBlock Heights\tExpirations\tData\tLocked Collateral\tRisked Collateral\tPotential Revenue\n")
+	for i, p := range csg.Periods {
+		fmt.Fprintf(w, "%d\t%d-%d\t%d\t%s\t%s\t%s\t%s\n", i+1, p.StartHeight, p.EndHeight, p.Expirations, modules.FilesizeUnits(p.DataSize),
+			currencyUnits(p.LockedCollateral), currencyUnits(p.RiskedCollateral), currencyUnits(p.PotentialRevenue))
+	}
+	w.Flush()
+}
+
+// hostcontractviewcmd is the handler for the command `siac host contracts
+// view [obligationid]`.
+func hostcontractviewcmd(idStr string) {
+	var id types.FileContractID
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse obligation id:", err)
+	}
+	so, err := httpClient.HostContractGet(id)
+	if err != nil {
+		die("Could not fetch host contract:", err)
+	}
+	potentialRevenue := so.PotentialDownloadRevenue.Add(so.PotentialUploadRevenue).Add(so.PotentialStorageRevenue)
+	fmt.Printf(`Obligation ID:      %v
+Status:             %v
+Renter:             %v
+Transaction ID:     %v
+Negotiation Height: %v
+Expiration Height:  %v
+Proof Deadline:     %v
+Data Size:          %v (%v sectors)
+
+Contract Cost:      %v
+Locked Collateral:  %v
+Risked Collateral:  %v
+Potential Revenue:  %v
+Transaction Fees:   %v
+
+Origin Confirmed:     %v
+Revision Constructed: %v
+Revision Confirmed:   %v
+Proof Constructed:    %v
+Proof Confirmed:      %v
+`, so.ObligationId, strings.TrimPrefix(so.ObligationStatus, "obligation"), so.RenterPublicKey, so.TransactionID,
+		so.NegotiationHeight, so.ExpirationHeight, so.ProofDeadLine, modules.FilesizeUnits(so.DataSize), so.SectorRootsCount,
+		currencyUnits(so.ContractCost), currencyUnits(so.LockedCollateral), currencyUnits(so.RiskedCollateral),
+		currencyUnits(potentialRevenue), currencyUnits(so.TransactionFeesAdded),
+		so.OriginConfirmed, so.RevisionConstructed, so.RevisionConfirmed, so.ProofConstructed, so.ProofConfirmed)
+
+	fmt.Println()
+	if len(so.ProofTransactionIDs) == 0 {
+		fmt.Println("No storage proofs submitted.")
+	} else {
+		fmt.Println("Storage Proof Transactions:")
+		for _, txid := range so.ProofTransactionIDs {
+			fmt.Println("  ", txid)
+		}
+	}
+
+	fmt.Println()
+	if len(so.Revisions) == 0 {
+		fmt.Println("No revisions recorded.")
+		return
+	}
+	fmt.Println("Revisions:")
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "  Revision\tBlock Height\tFile Size\tRenter Payout\tHost Payout\tTransaction ID\n")
+	for _, rev := range so.Revisions {
+		fmt.Fprintf(w, "  %d\t%d\t%s\t%s\t%s\t%s\n", rev.RevisionNumber, rev.BlockHeight, modules.FilesizeUnits(rev.FileSize),
+			currencyUnits(rev.RenterPayout), currencyUnits(rev.HostPayout), rev.TransactionID)
+	}
+	w.Flush()
+}
+
+// hostContractQuery returns the storage obligation query for the filter flags
+// of `siac host contracts`.
+func hostContractQuery() modules.StorageObligationQuery {
+	query := modules.StorageObligationQuery{
+		Status: hostContractStatus,
+	}
+	if hostContractRenter != "" {
+		query.RenterPublicKey.LoadString(hostContractRenter)
+		if query.RenterPublicKey.Key == nil {
+			die("Could not parse renter public key:", hostContractRenter)
+		}
+	}
+	return query
+}
+
+// writeHostContractsCSV writes the host contracts to a CSV file.
+func writeHostContractsCSV(path string, sos []modules.StorageObligation) (err error) {
+	f, err := os.Create(path)
+	if err != nil {
+		return err
+	}
+	defer func() {
+		err = errors.Compose(err, f.Close())
+	}()
+	w := csv.NewWriter(f)
+	err = w.Write([]string{"obligationid", "status", "renter", "transactionid", "negotiationheight", "expirationheight", "proofdeadline",
+		"datasize", "contractcost", "lockedcollateral", "riskedcollateral", "potentialdownloadrevenue", "potentialstoragerevenue",
+		"potentialuploadrevenue", "transactionfees", "originconfirmed", "revisionconfirmed", "proofconfirmed"})
+	if err != nil {
+		return err
+	}
+	for _, so := range sos {
+		err = w.Write([]string{so.ObligationId.String(), strings.TrimPrefix(so.ObligationStatus, "obligation"), so.RenterPublicKey.String(),
+			so.TransactionID.String(), fmt.Sprint(so.NegotiationHeight), fmt.Sprint(so.ExpirationHeight), fmt.Sprint(so.ProofDeadLine),
+			fmt.Sprint(so.DataSize), so.ContractCost.String(), so.LockedCollateral.String(), so.RiskedCollateral.String(),
+			so.PotentialDownloadRevenue.String(), so.PotentialStorageRevenue.String(), so.PotentialUploadRevenue.String(),
+			so.TransactionFeesAdded.String(), fmt.Sprint(so.OriginConfirmed), fmt.Sprint(so.RevisionConfirmed), fmt.Sprint(so.ProofConfirmed)})
+		if err != nil {
+			return err
+		}
+	}
+	w.Flush()
+	return w.Error()
+}
+
+// hostmaintenancestartcmd is the handler for the command `siac host
+// maintenance start [duration]`.
+func hostmaintenancestartcmd(durationStr string) {
+	duration, err := time.ParseDuration(durationStr)
+	if err != nil {
+		die("Could not parse maintenance duration:", err)
+	}
+	err = httpClient.HostMaintenanceStartPost(duration)
+	if err != nil {
+		die("Could not start maintenance mode:", err)
+	}
+	fmt.Println(`The host is now in maintenance mode. New contracts, renewals and writes are
+refused. Run 'siac host maintenance status' to see which contracts have a proof
+window during the planned downtime.`)
+}
+
+// hostmaintenancestatuscmd is the handler for the commands `siac host
+// maintenance` and `siac host maintenance status`.
+func hostmaintenancestatuscmd() {
+	status, err := httpClient.HostMaintenanceGet()
+	if err != nil {
+		die("Could not fetch maintenance status:", err)
+	}
+	if !status.Active {
+		fmt.Println("The host is not in maintenance mode.")
+		return
+	}
+	fmt.Printf(`Maintenance Mode:
+  Started:      %v
+  Planned End:  %v (around block %v)
+  Block Height: %v
+
+`, status.StartTime.Format(time.RFC822), status.EndTime.Format(time.RFC822), status.EndHeight, status.BlockHeight)
+	if len(status.Obligations) == 0 {
+		fmt.Println("No contracts have a proof window during the planned downtime.")
+		return
+	}
+	fmt.Printf("%v contracts have a proof window during the planned downtime:\n", len(status.Obligations))
+	sort.Slice(status.Obligations, func(i, j int) bool {
+		return status.Obligations[i].ExpirationHeight < status.Obligations[j].ExpirationHeight
+	})
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 4, ' ', 0)
+	fmt.Fprintf(w, "Obligation ID\tProof Window Start\tProof Window End\tRisked Collateral\n")
+	for _, so := range status.Obligations {
+		fmt.Fprintf(w, "%s\t%d\t%d\t%s\n", so.ObligationId, so.ExpirationHeight, so.ProofDeadLine, currencyUnits(so.RiskedCollateral))
+	}
+	w.Flush()
+}
+
+// hostmaintenancestopcmd is the handler for the command `siac host
+// maintenance stop`.
+func hostmaintenancestopcmd() {
+	err := httpClient.HostMaintenanceStopPost()
+	if err != nil {
+		die("Could not stop maintenance mode:", err)
+	}
+	fmt.Println("The host is no longer in maintenance mode.")
 }
 
 // hostannouncecmd is the handler for the command `siac host announce`.
-// Announces yourself as a host to the network. Optionally takes an address to
-// announce as.
+// Announces yourself as a host to the network. Optionally takes the addresses
+// to announce as.
 func hostannouncecmd(cmd *cobra.Command, args []string) {
 	var err error
 	switch len(args) {
@@ -469,8 +993,11 @@ func hostannouncecmd(cmd *cobra.Command, args []string) {
 	case 1:
 		err = httpClient.HostAnnounceAddrPost(modules.NetAddress(args[0]))
 	default:
-		cmd.UsageFunc()(cmd)
-		os.Exit(exitCodeUsage)
+		addrs := make([]modules.NetAddress, 0, len(args))
+		for _, arg := range args {
+			addrs = append(addrs, modules.NetAddress(arg))
+		}
+		err = httpClient.HostAnnounceAddrsPost(addrs)
 	}
 	if err != nil {
 		die("Could not announce host:", err)
@@ -506,6 +1033,28 @@ func hostfolderaddcmd(path, size string) {
 	fmt.Println("Added folder", path)
 }
 
+// hostfoldermigratecmd moves sectors from one folder of the host to another.
+func hostfoldermigratecmd(source, destination string) {
+	err := httpClient.HostStorageFoldersMigratePost(abs(source), abs(destination), hostFolderMigrateSectors)
+	if err != nil {
+		die("Could not migrate sectors:", err)
+	}
+	if hostFolderMigrateSectors == 0 {
+		fmt.Printf("Started moving all sectors from %v to %v\n", source, destination)
+	} else {
+		fmt.Printf("Started moving %v sectors from %v to %v\n", hostFolderMigrateSectors, source, destination)
+	}
+}
+
+// hostfolderrebalancecmd evens out the utilization of the host's folders.
+func hostfolderrebalancecmd() {
+	err := httpClient.HostStorageFoldersRebalancePost()
+	if err != nil {
+		die("Could not rebalance folders:", err)
+	}
+	fmt.Println("Started rebalancing the storage folders")
+}
+
 // hostfolderremovecmd removes a folder from the host.
 func hostfolderremovecmd(path string) {
 
@@ -3,7 +3,9 @@ package main
 import (
 	"fmt"
 	"math/big"
+	"net"
 	"os"
+	"strings"
 	"text/tabwriter"
 
 	"github.com/spf13/cobra"
@@ -37,11 +39,18 @@ var (
 	}
 
 	hostdbSetFiltermodeCmd = &cobra.Command{
-		Use:   "setfiltermode [filtermode] [host] [host] [host]...",
+		Use:   "setfiltermode [filtermode] [rule] [rule] [rule]...",
 		Short: "Set the filtermode.",
-		Long: `Set the hostdb filtermode and specify hosts.
+		Long: `Set the hostdb filtermode and specify the rules that select the listed hosts.
         [filtermode] can be whitelist, blacklist, or disable.
-        [host] is the host public key.`,
+        [rule] is a host public key (ed25519:...), a CIDR subnet or IP address
+        (10.0.0.0/8), or a hostname (host.example.com, *.example.com).
+        Prefix a rule with '!' to exclude the matching hosts from the list.
+        The most specific matching rule decides whether a host is listed:
+        keys take precedence over hostnames, which take precedence over
+        subnets, and longer subnet prefixes take precedence over shorter ones.
+        ASN rules are not supported, use the subnets of the autonomous
+        system instead.`,
 		Run: hostdbsetfiltermodecmd,
 	}
 
@@ -311,20 +320,66 @@ func hostdbfiltermodecmd() {
 	}
 	fmt.Println()
 	fmt.Println("  HostDB Filter Mode:", hdfmg.FilterMode)
+	printFilterRules := func(name string, rules []string) {
+		if len(rules) == 0 {
+			return
+		}
+		fmt.Printf("  %v:\n", name)
+		for _, rule := range rules {
+			fmt.Println("    ", rule)
+		}
+	}
 	fmt.Println("  Hosts:")
 	for _, host := range hdfmg.Hosts {
 		fmt.Println("    ", host)
 	}
+	printFilterRules("NetAddresses", hdfmg.NetAddresses)
+	printFilterRules("Subnets", hdfmg.Subnets)
+	printFilterRules("Excluded Hosts", hdfmg.ExcludedHosts)
+	printFilterRules("Excluded NetAddresses", hdfmg.ExcludedNetAddresses)
+	printFilterRules("Excluded Subnets", hdfmg.ExcludedSubnets)
 	fmt.Println()
 }
 
+// parseFilterRule adds a rule passed to `siac hostdb setfiltermode` to the
+// filter rules.
+func parseFilterRule(rules *modules.HostDBFilterRules, rule string) {
+	excluded := strings.HasPrefix(rule, "!")
+	rule = strings.TrimPrefix(rule, "!")
+	_, _, cidrErr := net.ParseCIDR(rule)
+	switch {
+	case strings.HasPrefix(rule, types.SignatureEd25519.String()+":"):
+		var pk types.SiaPublicKey
+		pk.LoadString(rule)
+		if pk.Key == nil {
+			die("Could not parse host public key:", rule)
+		}
+		if excluded {
+			rules.ExcludedHosts = append(rules.ExcludedHosts, pk)
+		} else {
+			rules.Hosts = append(rules.Hosts, pk)
+		}
+	case cidrErr == nil || net.ParseIP(rule) != nil:
+		if excluded {
+			rules.ExcludedSubnets = append(rules.ExcludedSubnets, rule)
+		} else {
+			rules.Subnets = append(rules.Subnets, rule)
+		}
+	default:
+		if excluded {
+			rules.ExcludedNetAddresses = append(rules.ExcludedNetAddresses, rule)
+		} else {
+			rules.NetAddresses = append(rules.NetAddresses, rule)
+		}
+	}
+}
+
 // hostdbsetfiltermodecmd is the handler for the command `siac hostdb
 // setfiltermode`. sets the hostdb filtermode (whitelist, blacklist, disable)
 func hostdbsetfiltermodecmd(cmd *cobra.Command, args []string) {
 	var fm modules.FilterMode
 	var filterModeStr string
-	var host types.SiaPublicKey
-	var hosts []types.SiaPublicKey
+	var rules modules.HostDBFilterRules
 	switch len(args) {
 	case 0:
 		cmd.UsageFunc()(cmd)
@@ -337,8 +392,7 @@ func hostdbsetfiltermodecmd(cmd *cobra.Command, args []string) {
 	default:
 		filterModeStr = args[0]
 		for i := 1; i < len(args); i++ {
-			host.LoadString(args[i])
-			hosts = append(hosts, host)
+			parseFilterRule(&rules, args[i])
 		}
 	}
 	err := fm.FromString(filterModeStr)
@@ -347,7 +401,7 @@ func hostdbsetfiltermodecmd(cmd *cobra.Command, args []string) {
 		die()
 	}
 
-	err = httpClient.HostDbFilterModePost(fm, hosts)
+	err = httpClient.HostDbFilterRulesPost(fm, rules)
 	if err != nil {
 		fmt.Println("Could not set hostdb filtermode: ", err)
 		die()
@@ -20,8 +20,17 @@ var (
 	// Flags.
 	dictionaryLanguage        string // dictionary for seed utils
 	uploadedsizeUtilVerbose   bool   // display additional info for "utils upload-size"
+	eventsSince               uint64 // only show events after the event with this ID
+	hostContractDescending    bool   // sort host contracts in descending order
+	hostContractExport        string // file to export host contracts to as CSV
+	hostContractLimit         uint64 // maximum number of host contracts to show
+	hostContractOffset        uint64 // number of host contracts to skip
 	hostContractOutputType    string // output type for host contracts
+	hostContractRenter        string // renter public key to filter host contracts by
+	hostContractSortBy        string // field to sort host contracts by
+	hostContractStatus        string // obligation status to filter host contracts by
 	hostVerbose               bool   // display additional host info
+	hostFolderMigrateSectors  uint64 // number of sectors to migrate
 	hostFolderRemoveForce     bool   // force folder remove
 	initForce                 bool   // destroy and re-encrypt the wallet on init if it already exists
 	initPassword              bool   // supply a custom password when creating a wallet
@@ -40,11 +49,34 @@ var (
 	skynetLsRoot              bool   // Use root as the base instead of the Skynet folder.
 	skynetUploadRoot          bool   // Use root as the base instead of the Skynet folder.
 	statusVerbose             bool   // Display additional siac information
+	walletAccount             string // Wallet account that generates an address or funds a transaction.
+	walletAddressTimelock     uint64 // Height until which the outputs of a new address are locked.
+	walletBumpFeePerByte      string // Fee per byte a bumped transaction should reach.
+	walletBumpReplace         bool   // Replace the transaction instead of paying for it with a child.
+	walletMultisigUnused      bool   // The multisig address has never appeared in the blockchain.
+	walletRequestLabel        string // Label of the address of a payment request.
+	walletScheduledCount      uint64 // Number of payments of a recurring scheduled payment.
+	walletScheduledInterval   uint64 // Number of blocks between the payments of a scheduled payment.
+	walletSendChangeAddress   string // Address that receives the change of a transaction.
+	walletSendDryRun          bool   // Print the unsigned transaction instead of sending it.
+	walletSendFee             string // Exact fee of a transaction.
+	walletSendFeePerByte      string // Fee per byte of a transaction.
+	walletSendFeeTarget       uint64 // Number of blocks within which a transaction should confirm.
+	walletSendInputs          string // Comma-separated list of outputs to spend.
+	walletSendSpendAll        bool   // Send the value of all inputs to the destination.
+	walletSignerPassword      bool   // Require a password from the wallet using the signer.
+	walletSignerUnused        bool   // The signer addresses have never appeared in the blockchain.
 	walletRawTxn              bool   // Encode/decode transactions in base64-encoded binary.
+	walletTransactionsCSV     bool   // Export the transactions as CSV.
+	walletTransactionsLabel   string // Only show the transactions of addresses with this label.
+	webhooksAddEvents         string // Comma-separated list of event types to post to a webhook.
+	webhooksAddSecret         string // Secret used to sign the events posted to a webhook.
 
 	dataPieces   string // the number of data pieces a files should be uploaded with
 	parityPieces string // the number of parity pieces a files should be uploaded with
 
+	renterContractAmount string // amount of money to renew or refresh a contract with
+
 	allowanceFunds                         string // amount of money to be used within a period
 	allowancePeriod                        string // length of period
 	allowanceHosts                         string // number of hosts to form contracts with
@@ -60,6 +92,10 @@ var (
 	allowanceMaxSectorAccessPrice          string // max allowed price to access a sector on a host
 	allowanceMaxStoragePrice               string // max allowed price to store data on a host
 	allowanceMaxUploadBandwidthPrice       string // max allowed price to upload data to a host
+	allowanceMigrationPriceMultiple        string // price multiple of the median host that triggers a migration
+	allowanceMigrationMinSuccessRate       string // host success rate below which a migration is triggered
+	allowanceWalletAccount                 string // wallet account that funds the contracts
+	allowanceFeeTarget                     string // blocks within which contract transactions should confirm
 )
 
 var (
@@ -225,15 +261,32 @@ func main() {
 	root.AddCommand(versionCmd, stopCmd, globalRatelimitCmd, alertsCmd)
 	root.Flags().BoolVarP(&statusVerbose, "verbose", "v", false, "Display additional siac information")
 
+	root.AddCommand(eventsCmd, webhooksCmd)
+	eventsCmd.Flags().Uint64VarP(&eventsSince, "since", "", 0, "Only show the events after the event with this ID")
+	webhooksCmd.AddCommand(webhooksAddCmd, webhooksRemoveCmd)
+	webhooksAddCmd.Flags().StringVarP(&webhooksAddEvents, "events", "", "", "Comma-separated list of event types to post, all events are posted if empty")
+	webhooksAddCmd.Flags().StringVarP(&webhooksAddSecret, "secret", "", "", "Secret used to sign the posted events")
+
 	root.AddCommand(updateCmd)
 	updateCmd.AddCommand(updateCheckCmd)
 
 	root.AddCommand(hostCmd)
-	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostFolderCmd, hostContractCmd, hostSectorCmd)
-	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostCmd.AddCommand(hostConfigCmd, hostAnnounceCmd, hostClientsCmd, hostFolderCmd, hostContractCmd, hostMaintenanceCmd, hostSectorCmd)
+	hostConfigCmd.AddCommand(hostConfigAutoPricingCmd)
+	hostFolderCmd.AddCommand(hostFolderAddCmd, hostFolderMigrateCmd, hostFolderRebalanceCmd, hostFolderRemoveCmd, hostFolderResizeCmd)
+	hostFolderMigrateCmd.Flags().Uint64VarP(&hostFolderMigrateSectors, "sectors", "n", 0, "Number of sectors to move, 0 moves all sectors")
+	hostMaintenanceCmd.AddCommand(hostMaintenanceStartCmd, hostMaintenanceStatusCmd, hostMaintenanceStopCmd)
 	hostSectorCmd.AddCommand(hostSectorDeleteCmd)
 	hostCmd.Flags().BoolVarP(&hostVerbose, "verbose", "v", false, "Display detailed host info")
+	hostContractCmd.AddCommand(hostContractSummaryCmd, hostContractViewCmd)
 	hostContractCmd.Flags().StringVarP(&hostContractOutputType, "type", "t", "value", "Select output type")
+	hostContractCmd.Flags().StringVarP(&hostContractExport, "export", "", "", "Export the contracts to a CSV file")
+	hostContractCmd.Flags().StringVarP(&hostContractSortBy, "sort", "", "", "Sort by collateral, datasize, expiration, negotiation or revenue")
+	hostContractCmd.Flags().BoolVarP(&hostContractDescending, "desc", "", false, "Sort in descending order")
+	hostContractCmd.Flags().Uint64VarP(&hostContractOffset, "offset", "", 0, "Number of contracts to skip")
+	hostContractCmd.Flags().Uint64VarP(&hostContractLimit, "limit", "", 0, "Maximum number of contracts to show, 0 shows all")
+	hostContractCmd.PersistentFlags().StringVarP(&hostContractStatus, "status", "", "", "Only show contracts with this status (unresolved, rejected, succeeded, failed)")
+	hostContractCmd.PersistentFlags().StringVarP(&hostContractRenter, "renter", "", "", "Only show contracts with the renter with this public key")
 	hostFolderRemoveCmd.Flags().BoolVarP(&hostFolderRemoveForce, "force", "f", false, "Force the removal of the folder and its data")
 
 	root.AddCommand(hostdbCmd)
@@ -245,14 +298,40 @@ func main() {
 	minerCmd.AddCommand(minerStartCmd, minerStopCmd)
 
 	root.AddCommand(walletCmd)
-	walletCmd.AddCommand(walletAddressCmd, walletAddressesCmd, walletChangepasswordCmd, walletInitCmd, walletInitSeedCmd,
-		walletLoadCmd, walletLockCmd, walletSeedsCmd, walletSendCmd, walletSweepCmd, walletSignCmd,
-		walletBalanceCmd, walletBroadcastCmd, walletTransactionsCmd, walletUnlockCmd)
+	walletCmd.AddCommand(walletAccountsCmd, walletAddressCmd, walletAddressesCmd, walletChangepasswordCmd, walletInitCmd, walletInitSeedCmd,
+		walletLabelCmd, walletLabelsCmd, walletLoadCmd, walletLockCmd, walletMultisigCmd, walletNoteCmd, walletRequestCmd,
+		walletRequestsCmd, walletScheduledCmd, walletSeedsCmd, walletSendCmd, walletSweepCmd, walletSignCmd, walletSignerCmd,
+		walletBalanceCmd, walletBroadcastCmd, walletBumpCmd, walletTransactionsCmd, walletUnlockCmd)
+	walletAccountsCmd.AddCommand(walletAccountsCreateCmd, walletAccountsRenameCmd, walletAccountsTransactionsCmd)
+	walletAddressCmd.Flags().StringVarP(&walletAccount, "account", "", "", "Wallet account of the address")
+	walletAddressCmd.Flags().Uint64VarP(&walletAddressTimelock, "timelock", "", 0, "Height until which siacoins sent to the address are locked")
+	walletBumpCmd.Flags().StringVarP(&walletBumpFeePerByte, "fee-per-byte", "", "", "Transaction fee per byte to reach, e.g. 10uS")
+	walletBumpCmd.Flags().BoolVarP(&walletBumpReplace, "replace", "", false, "Replace the transaction with a copy that pays the higher fee")
 	walletInitCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Prompt for a custom password")
 	walletInitCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet and re-encrypt")
 	walletInitSeedCmd.Flags().BoolVarP(&initForce, "force", "", false, "destroy the existing wallet")
 	walletLoadCmd.AddCommand(walletLoad033xCmd, walletLoadSeedCmd, walletLoadSiagCmd)
+	walletMultisigCmd.AddCommand(walletMultisigAddressCmd, walletMultisigBroadcastCmd, walletMultisigFundCmd,
+		walletMultisigMergeCmd, walletMultisigPubkeyCmd, walletMultisigSignCmd)
+	walletMultisigAddressCmd.Flags().BoolVarP(&walletMultisigUnused, "unused", "", false, "Skip the blockchain rescan because the address has never been used")
 	walletSendCmd.AddCommand(walletSendSiacoinsCmd, walletSendSiafundsCmd)
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendInputs, "inputs", "", "", "Comma-separated list of output IDs to spend")
+	walletSendSiacoinsCmd.Flags().BoolVarP(&walletSendSpendAll, "spend-all", "", false, "Send the value of all inputs, minus the fee, to the destination")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendChangeAddress, "change-address", "", "", "Address that receives the change")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendFee, "fee", "", "", "Exact transaction fee, e.g. 10mS")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletSendFeePerByte, "fee-per-byte", "", "", "Transaction fee per byte, e.g. 10uS")
+	walletSendSiacoinsCmd.Flags().Uint64VarP(&walletSendFeeTarget, "fee-target", "", 0, "Estimate the fee to confirm the transaction within this many blocks")
+	walletSendSiacoinsCmd.Flags().BoolVarP(&walletSendDryRun, "dry-run", "", false, "Print the unsigned transaction and its fees instead of sending it")
+	walletSendSiacoinsCmd.Flags().StringVarP(&walletAccount, "account", "", "", "Wallet account that funds the transaction and receives the change")
+	walletScheduledCmd.AddCommand(walletScheduledAddCmd, walletScheduledCancelCmd)
+	walletScheduledAddCmd.Flags().Uint64VarP(&walletScheduledInterval, "interval", "", 0, "Repeat the payment every interval blocks")
+	walletScheduledAddCmd.Flags().Uint64VarP(&walletScheduledCount, "count", "", 0, "Number of payments of a recurring payment, unlimited if zero")
+	walletSignerCmd.AddCommand(walletSignerAddressesCmd, walletSignerServeCmd, walletSignerSetCmd)
+	walletSignerAddressesCmd.Flags().BoolVarP(&walletSignerUnused, "unused", "", false, "Skip the blockchain rescan because the addresses have never been used")
+	walletSignerServeCmd.Flags().BoolVarP(&walletSignerPassword, "password", "", false, "Prompt for a password that the wallet has to provide")
+	walletRequestCmd.Flags().StringVarP(&walletRequestLabel, "label", "", "", "Label of the address of the request")
+	walletTransactionsCmd.Flags().StringVarP(&walletTransactionsLabel, "label", "", "", "Only show transactions of addresses with this label")
+	walletTransactionsCmd.Flags().BoolVarP(&walletTransactionsCSV, "csv", "", false, "Export the transactions with their labels and notes as CSV")
 	walletUnlockCmd.Flags().BoolVarP(&initPassword, "password", "p", false, "Display interactive password prompt even if SIA_WALLET_PASSWORD is set")
 	walletBroadcastCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Decode transaction as base64 instead of JSON")
 	walletSignCmd.Flags().BoolVarP(&walletRawTxn, "raw", "", false, "Encode signed transaction as base64 instead of JSON")
@@ -265,9 +344,14 @@ func main() {
 		renterExportCmd, renterPricesCmd, renterBackupCreateCmd, renterBackupLoadCmd,
 		renterBackupListCmd, renterTriggerContractRecoveryScanCmd, renterFilesUnstuckCmd,
 		renterContractsRecoveryScanProgressCmd, renterDownloadCancelCmd, renterRatelimitCmd,
-		renterFuseCmd)
+		renterFuseCmd, renterContractCmd, renterProfilesCmd, renterAuditCmd)
 
+	renterContractCmd.AddCommand(renterContractCancelCmd, renterContractFormCmd, renterContractPinCmd,
+		renterContractRefreshCmd, renterContractRenewCmd, renterContractUnpinCmd)
+	renterContractRefreshCmd.Flags().StringVar(&renterContractAmount, "amount", "", "amount of money to fund the refreshed contract with")
+	renterContractRenewCmd.Flags().StringVar(&renterContractAmount, "amount", "", "amount of money to fund the renewed contract with")
 	renterContractsCmd.AddCommand(renterContractsViewCmd)
+	renterProfilesCmd.AddCommand(renterProfilesCreateCmd, renterProfilesRemoveCmd)
 	renterAllowanceCmd.AddCommand(renterAllowanceCancelCmd)
 
 	renterCmd.Flags().BoolVarP(&renterVerbose, "verbose", "v", false, "Show additional renter info such as allowance details")
@@ -297,6 +381,10 @@ func main() {
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxSectorAccessPrice, "max-sector-access-price", "", "the maximum price that the renter will pay to access a sector on a host")
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxStoragePrice, "max-storage-price", "", "the maximum price that the renter will pay to store data on a host")
 	renterSetAllowanceCmd.Flags().StringVar(&allowanceMaxUploadBandwidthPrice, "max-upload-bandwidth-price", "", "the maximum price that the renter will pay to upload data to a host")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMigrationPriceMultiple, "migration-price-multiple", "", "migrate data off hosts that are more expensive than this multiple of the median host, 0 to disable")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceMigrationMinSuccessRate, "migration-min-success-rate", "", "migrate data off hosts whose recent success rate drops below this value, 0 to disable")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceWalletAccount, "wallet-account", "", "the wallet account that funds the contracts, empty for the primary account")
+	renterSetAllowanceCmd.Flags().StringVar(&allowanceFeeTarget, "fee-target", "", "the number of blocks within which contract transactions should confirm, 0 to use the maximum fee estimate")
 
 	renterFuseCmd.AddCommand(renterFuseMountCmd, renterFuseUnmountCmd)
 	renterFuseMountCmd.Flags().BoolVarP(&renterFuseMountAllowOther, "allow-other", "", false, "Allow users other than the user that mounted the fuse directory to access and use the fuse directory")
@@ -312,6 +400,9 @@ func main() {
 	gatewayCmd.AddCommand(gatewayConnectCmd, gatewayDisconnectCmd, gatewayAddressCmd, gatewayListCmd, gatewayRatelimitCmd, gatewayBlacklistCmd)
 	gatewayBlacklistCmd.AddCommand(gatewayBlacklistAppendCmd, gatewayBlacklistClearCmd, gatewayBlacklistRemoveCmd, gatewayBlacklistSetCmd)
 
+	root.AddCommand(tpoolCmd)
+	tpoolCmd.AddCommand(tpoolRejectionsCmd, tpoolSetCmd, tpoolSetsCmd)
+
 	root.AddCommand(consensusCmd)
 	consensusCmd.Flags().BoolVarP(&consensusCmdVerbose, "verbose", "v", false, "Display full consensus information")
 
@@ -327,6 +418,7 @@ func main() {
 	root.PersistentFlags().StringVarP(&httpClient.Password, "apipassword", "", "", "the password for the API's http authentication")
 	root.PersistentFlags().StringVarP(&siaDir, "sia-directory", "d", build.DefaultSiaDir(), "location of the sia directory")
 	root.PersistentFlags().StringVarP(&httpClient.UserAgent, "useragent", "", "Sia-Agent", "the useragent used by siac to connect to the daemon's API")
+	root.PersistentFlags().StringVarP(&httpClient.RenterProfile, "renter-profile", "", "", "the renter profile that renter and hostdb commands are run against")
 
 	// Check if the api password environment variable is set.
 	apiPassword := os.Getenv("SIA_API_PASSWORD")
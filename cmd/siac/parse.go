@@ -11,6 +11,7 @@ import (
 	"strings"
 
 	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
 	"gitlab.com/NebulousLabs/errors"
 )
@@ -322,3 +323,21 @@ func parseTxn(s string) (types.Transaction, error) {
 	}
 	return txn, nil
 }
+
+// parsePartiallySignedTransaction decodes a partially signed transaction from
+// s, which can be JSON or a path to a file containing JSON.
+func parsePartiallySignedTransaction(s string) (modules.PartiallySignedTransaction, error) {
+	// first assume s is a file
+	pstBytes, err := ioutil.ReadFile(s)
+	if os.IsNotExist(err) {
+		// assume s is a literal encoding
+		pstBytes = []byte(s)
+	} else if err != nil {
+		return modules.PartiallySignedTransaction{}, errors.New("could not read transaction file: " + err.Error())
+	}
+	var pst modules.PartiallySignedTransaction
+	if err := json.Unmarshal(pstBytes, &pst); err != nil {
+		return modules.PartiallySignedTransaction{}, errors.New("could not decode JSON transaction: " + err.Error())
+	}
+	return pst, nil
+}
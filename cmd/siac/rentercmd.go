@@ -45,6 +45,17 @@ var (
 		Run:   wrap(renterallowancecmd),
 	}
 
+	renterAuditCmd = &cobra.Command{
+		Use:   "audit [interval] [maxprice]",
+		Short: "set the audit interval and the max price of an audit",
+		Long: `Set the interval between two audits of the renter's hosts and the
+maximum price the renter pays for a single audit. An audit downloads a random
+segment of a random sector from every host and verifies its Merkle proof.
+The interval is a duration like 30m or 6h and 0 disables audits. A maxprice
+of 0 means no limit.`,
+		Run: wrap(renterauditcmd),
+	}
+
 	renterBackupCreateCmd = &cobra.Command{
 		Use:   "createbackup [name]",
 		Short: "Create a backup of the renter's siafiles",
@@ -73,6 +84,59 @@ var (
 		Run:   wrap(rentercmd),
 	}
 
+	renterContractCmd = &cobra.Command{
+		Use:   "contract",
+		Short: "Manage individual contracts",
+		Long:  "Form, renew, refresh, pin, unpin or cancel individual contracts.",
+	}
+
+	renterContractCancelCmd = &cobra.Command{
+		Use:   "cancel [contract-id]",
+		Short: "Cancel a contract",
+		Long: `Cancel a contract by marking it as neither good for upload nor good for
+renew. The contract can still be used for downloads.`,
+		Run: wrap(rentercontractcancelcmd),
+	}
+
+	renterContractFormCmd = &cobra.Command{
+		Use:   "form [host-pubkey] [amount]",
+		Short: "Form a contract with a host",
+		Long: `Form a contract with the specified host, funded with the specified amount.
+The amount is taken from the allowance. Units can be specified, e.g. 100SC.`,
+		Run: wrap(rentercontractformcmd),
+	}
+
+	renterContractPinCmd = &cobra.Command{
+		Use:   "pin [contract-id]",
+		Short: "Pin a contract",
+		Long: `Pin a contract. Contract maintenance keeps pinned contracts good for upload
+and good for renew regardless of the host's score, and never churns them.`,
+		Run: wrap(rentercontractpincmd),
+	}
+
+	renterContractRefreshCmd = &cobra.Command{
+		Use:   "refresh [contract-id]",
+		Short: "Refresh a contract that is running out of funds",
+		Long: `Add funds to a contract by renewing it without changing its end height. Use
+--amount to set the funding, otherwise twice the contract's total cost is used.`,
+		Run: wrap(rentercontractrefreshcmd),
+	}
+
+	renterContractRenewCmd = &cobra.Command{
+		Use:   "renew [contract-id]",
+		Short: "Renew a contract early",
+		Long: `Renew a contract before it enters the renew window. Use --amount to set the
+funding, otherwise the funding is estimated from the contract's usage.`,
+		Run: wrap(rentercontractrenewcmd),
+	}
+
+	renterContractUnpinCmd = &cobra.Command{
+		Use:   "unpin [contract-id]",
+		Short: "Unpin a contract",
+		Long:  "Remove the pin from a contract, handing it back to contract maintenance.",
+		Run:   wrap(rentercontractunpincmd),
+	}
+
 	renterContractsCmd = &cobra.Command{
 		Use:   "contracts",
 		Short: "View the Renter's contracts",
@@ -198,6 +262,31 @@ allowance of 500SC, 12w period, 50 hosts, and 4w renew window will be used.`,
 		Run: renterpricescmd,
 	}
 
+	renterProfilesCmd = &cobra.Command{
+		Use:   "profiles",
+		Short: "List the renter profiles",
+		Long: `List the additional renter profiles of siad. Every profile has its own
+allowance, contracts and files. Use the --renter-profile flag to run any renter
+command against a profile.`,
+		Run: wrap(renterprofilescmd),
+	}
+
+	renterProfilesCreateCmd = &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a renter profile",
+		Long:  "Create a new renter profile with the given name.",
+		Run:   wrap(renterprofilescreatecmd),
+	}
+
+	renterProfilesRemoveCmd = &cobra.Command{
+		Use:   "remove [name]",
+		Short: "Remove a renter profile",
+		Long: `Stop the renter of the profile with the given name and remove the profile.
+The profile's data is kept on disk and will be used again if a profile with the
+same name is created.`,
+		Run: wrap(renterprofilesremovecmd),
+	}
+
 	renterRatelimitCmd = &cobra.Command{
 		Use:   "ratelimit [maxdownloadspeed] [maxuploadspeed]",
 		Short: "set maxdownloadspeed and maxuploadspeed",
@@ -530,6 +619,13 @@ Price Protections:
   MaxSectorAccessPrice:      %v per million accesses
   MaxStoragePrice:           %v per TB per Month
   MaxUploadBandwidthPrice:   %v per TB
+
+Migration Policy:
+  Price Multiple:            %v
+  Min Success Rate:          %v
+
+Wallet Account:              %v
+Fee Target:                  %v
 `, currencyUnits(allowance.Funds), allowance.Period, allowance.RenewWindow,
 		allowance.Hosts, currencyUnits(allowance.PaymentContractInitialFunding),
 		modules.FilesizeUnits(allowance.ExpectedStorage),
@@ -541,7 +637,9 @@ Price Protections:
 		currencyUnits(allowance.MaxDownloadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
 		currencyUnits(allowance.MaxSectorAccessPrice.Mul64(1e6)),
 		currencyUnits(allowance.MaxStoragePrice.Mul(modules.BlockBytesPerMonthTerabyte)),
-		currencyUnits(allowance.MaxUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)))
+		currencyUnits(allowance.MaxUploadBandwidthPrice.Mul(modules.BytesPerTerabyte)),
+		allowance.MigrationPriceMultiple, allowance.MigrationMinSuccessRate,
+		walletAccountName(allowance.WalletAccount), feeTargetString(allowance.FeeTarget))
 
 	// Show detailed current Period spending metrics
 	renterallowancespending(rg)
@@ -560,6 +658,14 @@ Price Protections:
 	}
 }
 
+// feeTargetString returns a description of an allowance's fee target.
+func feeTargetString(target types.BlockHeight) string {
+	if target == 0 {
+		return "(maximum estimate)"
+	}
+	return fmt.Sprintf("%v blocks", target)
+}
+
 // renterallowancecancelcmd is the handler for `siac renter allowance cancel`.
 // cancels the current allowance.
 func renterallowancecancelcmd() {
@@ -802,6 +908,38 @@ func rentersetallowancecmd(cmd *cobra.Command, args []string) {
 		req = req.WithMaxUploadBandwidthPrice(price)
 		changedFields++
 	}
+	// parse migrationpricemultiple
+	if allowanceMigrationPriceMultiple != "" {
+		multiple, err := strconv.ParseFloat(allowanceMigrationPriceMultiple, 64)
+		if err != nil {
+			die("Could not parse migration price multiple")
+		}
+		req = req.WithMigrationPriceMultiple(multiple)
+		changedFields++
+	}
+	// parse migrationminsuccessrate
+	if allowanceMigrationMinSuccessRate != "" {
+		rate, err := strconv.ParseFloat(allowanceMigrationMinSuccessRate, 64)
+		if err != nil {
+			die("Could not parse migration min success rate")
+		}
+		req = req.WithMigrationMinSuccessRate(rate)
+		changedFields++
+	}
+	// parse walletaccount
+	if cmd.Flags().Changed("wallet-account") {
+		req = req.WithWalletAccount(allowanceWalletAccount)
+		changedFields++
+	}
+	// parse feetarget
+	if allowanceFeeTarget != "" {
+		target, err := strconv.ParseUint(allowanceFeeTarget, 10, 64)
+		if err != nil {
+			die("Could not parse fee target")
+		}
+		req = req.WithFeeTarget(types.BlockHeight(target))
+		changedFields++
+	}
 
 	// check if any fields were updated.
 	if changedFields == 0 {
@@ -1286,6 +1424,102 @@ func writeContracts(contracts []api.RenterContract) {
 	w.Flush()
 }
 
+// parseContractID parses a contract id, exiting on failure.
+func parseContractID(cid string) types.FileContractID {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(cid); err != nil {
+		die("Could not parse contract id:", err)
+	}
+	return fcid
+}
+
+// parseContractFunds parses a currency amount used to fund a contract, exiting
+// on failure.
+func parseContractFunds(amount string) types.Currency {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var funds types.Currency
+	if _, err := fmt.Sscan(hastings, &funds); err != nil {
+		die("Could not parse amount:", err)
+	}
+	return funds
+}
+
+// rentercontractcancelcmd is the handler for the command `siac renter contract
+// cancel [contract-id]`.
+func rentercontractcancelcmd(cid string) {
+	err := httpClient.RenterContractCancelPost(parseContractID(cid))
+	if err != nil {
+		die("Could not cancel contract:", err)
+	}
+	fmt.Println("Contract canceled")
+}
+
+// rentercontractformcmd is the handler for the command `siac renter contract
+// form [host-pubkey] [amount]`.
+func rentercontractformcmd(pubkey, amount string) {
+	var hostKey types.SiaPublicKey
+	hostKey.LoadString(pubkey)
+	if hostKey.Key == nil {
+		die("Could not parse host public key")
+	}
+	rcp, err := httpClient.RenterContractFormPost(hostKey, parseContractFunds(amount))
+	if err != nil {
+		die("Could not form contract:", err)
+	}
+	fmt.Println("Formed contract", rcp.ID)
+}
+
+// rentercontractpincmd is the handler for the command `siac renter contract pin
+// [contract-id]`.
+func rentercontractpincmd(cid string) {
+	err := httpClient.RenterContractPinPost(parseContractID(cid))
+	if err != nil {
+		die("Could not pin contract:", err)
+	}
+	fmt.Println("Contract pinned")
+}
+
+// rentercontractrefreshcmd is the handler for the command `siac renter contract
+// refresh [contract-id]`.
+func rentercontractrefreshcmd(cid string) {
+	var funds types.Currency
+	if renterContractAmount != "" {
+		funds = parseContractFunds(renterContractAmount)
+	}
+	rcp, err := httpClient.RenterContractRefreshPost(parseContractID(cid), funds)
+	if err != nil {
+		die("Could not refresh contract:", err)
+	}
+	fmt.Println("Refreshed contract, new contract id is", rcp.ID)
+}
+
+// rentercontractrenewcmd is the handler for the command `siac renter contract
+// renew [contract-id]`.
+func rentercontractrenewcmd(cid string) {
+	var funds types.Currency
+	if renterContractAmount != "" {
+		funds = parseContractFunds(renterContractAmount)
+	}
+	rcp, err := httpClient.RenterContractRenewPost(parseContractID(cid), funds)
+	if err != nil {
+		die("Could not renew contract:", err)
+	}
+	fmt.Println("Renewed contract, new contract id is", rcp.ID)
+}
+
+// rentercontractunpincmd is the handler for the command `siac renter contract
+// unpin [contract-id]`.
+func rentercontractunpincmd(cid string) {
+	err := httpClient.RenterContractUnpinPost(parseContractID(cid))
+	if err != nil {
+		die("Could not unpin contract:", err)
+	}
+	fmt.Println("Contract unpinned")
+}
+
 // rentercontractscmd is the handler for the comand `siac renter contracts`.
 // It lists the Renter's contracts.
 func rentercontractscmd() {
@@ -1419,6 +1653,9 @@ Contract %v
 
   Start Height: %v
   End Height:   %v
+  Pinned:       %v
+  Migrate:      %v
+  Audits:       %v passed, %v failed, %v lost sectors
 
   Total cost:        %v (Fees: %v)
   Funds Allocated:   %v
@@ -1428,7 +1665,9 @@ Contract %v
   Remaining Funds:   %v
 
   File Size: %v
-`, rc.ID, rc.NetAddress, rc.HostVersion, rc.HostPublicKey.String(), rc.StartHeight, rc.EndHeight,
+`, rc.ID, rc.NetAddress, rc.HostVersion, rc.HostPublicKey.String(), rc.StartHeight, rc.EndHeight, rc.Pinned,
+				migrationStatus(rc),
+				rc.SuccessfulAudits, rc.FailedAudits, rc.LostSectors,
 				currencyUnits(rc.TotalCost),
 				currencyUnits(rc.Fees),
 				currencyUnits(rc.TotalCost.Sub(rc.Fees)),
@@ -1446,6 +1685,19 @@ Contract %v
 	fmt.Println("Contract not found")
 }
 
+// migrationStatus returns a description of the migration state of a
+// contract.
+func migrationStatus(rc api.RenterContract) string {
+	switch {
+	case !rc.Migrate:
+		return "no"
+	case rc.MigrationActive:
+		return "active (" + rc.MigrationReason + ")"
+	default:
+		return "pending (" + rc.MigrationReason + ")"
+	}
+}
+
 // downloadDir downloads the dir at the specified siaPath to the specified
 // location. It returns all the files for which a download was initialized as
 // tracked files and the ones which were ignored as skipped. Errors are composed
@@ -2566,6 +2818,66 @@ func renterpricescmd(cmd *cobra.Command, args []string) {
 	w.Flush()
 }
 
+// renterprofilescmd is the handler for the command `siac renter profiles`. It
+// lists the renter profiles.
+func renterprofilescmd() {
+	rpg, err := httpClient.RenterProfilesGet()
+	if err != nil {
+		die("Could not get renter profiles:", err)
+	}
+	if len(rpg.Profiles) == 0 {
+		fmt.Println("No renter profiles.")
+		return
+	}
+	fmt.Println("Renter profiles:")
+	for _, name := range rpg.Profiles {
+		fmt.Println("  " + name)
+	}
+}
+
+// renterprofilescreatecmd is the handler for the command `siac renter profiles
+// create [name]`.
+func renterprofilescreatecmd(name string) {
+	err := httpClient.RenterProfilesCreatePost(name)
+	if err != nil {
+		die("Could not create renter profile:", err)
+	}
+	fmt.Printf("Created renter profile %v\n", name)
+}
+
+// renterprofilesremovecmd is the handler for the command `siac renter profiles
+// remove [name]`.
+func renterprofilesremovecmd(name string) {
+	err := httpClient.RenterProfilesRemovePost(name)
+	if err != nil {
+		die("Could not remove renter profile:", err)
+	}
+	fmt.Printf("Removed renter profile %v\n", name)
+}
+
+// renterauditcmd is the handler for the command `siac renter audit` which
+// sets the audit interval and the maximum price of a single audit.
+func renterauditcmd(intervalStr, maxPriceStr string) {
+	interval, err := time.ParseDuration(intervalStr)
+	if err != nil {
+		die("Could not parse audit interval:", err)
+	}
+	priceStr, err := parseCurrency(maxPriceStr)
+	if err != nil {
+		die("Could not parse max audit price:", err)
+	}
+	var maxPrice types.Currency
+	_, err = fmt.Sscan(priceStr, &maxPrice)
+	if err != nil {
+		die("Could not read max audit price:", err)
+	}
+	err = httpClient.RenterAuditPost(interval, maxPrice)
+	if err != nil {
+		die("Could not set audit settings:", err)
+	}
+	fmt.Printf("Set renter audit interval to %v and max audit price to %v\n", interval, currencyUnits(maxPrice))
+}
+
 // renterratelimitcmd is the handler for the command `siac renter ratelimit`
 // which sets the maxuploadspeed and maxdownloadspeed in bytes-per-second for
 // the renter module
@@ -0,0 +1,171 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+	"time"
+
+	"github.com/spf13/cobra"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	tpoolCmd = &cobra.Command{
+		Use:   "tpool",
+		Short: "Inspect the transaction pool",
+		Long:  "Print a summary of the transaction pool and the current fee estimates.",
+		Run:   wrap(tpoolcmd),
+	}
+
+	tpoolRejectionsCmd = &cobra.Command{
+		Use:   "rejections",
+		Short: "List recently rejected transaction sets",
+		Long:  "List the transaction sets that were recently rejected by the transaction pool, newest first, together with the reason of the rejection.",
+		Run:   wrap(tpoolrejectionscmd),
+	}
+
+	tpoolSetCmd = &cobra.Command{
+		Use:   "set [id]",
+		Short: "View a transaction set",
+		Long: `View a transaction set of the transaction pool and the unconfirmed parents
+and children of its transactions. The id can either be the id of the set or the
+id of one of its transactions.`,
+		Run: wrap(tpoolsetcmd),
+	}
+
+	tpoolSetsCmd = &cobra.Command{
+		Use:   "sets",
+		Short: "List the transaction sets",
+		Long:  "List the transaction sets of the transaction pool in the order they arrived.",
+		Run:   wrap(tpoolsetscmd),
+	}
+)
+
+// peerString returns the peer that relayed a transaction set, or "local" if
+// the set wasn't relayed by a peer.
+func peerString(peer modules.NetAddress) string {
+	if peer == "" {
+		return "local"
+	}
+	return string(peer)
+}
+
+// feeString returns a fee per byte as a fee per KB.
+func feeString(feePerByte types.Currency) string {
+	return feePerByte.Mul64(1e3).HumanString() + " / KB"
+}
+
+// tpoolcmd is the handler for the command `siac tpool`.
+// Prints a summary of the transaction pool.
+func tpoolcmd() {
+	sets, err := httpClient.TransactionPoolSetsGet()
+	if err != nil {
+		die("Could not get transaction sets:", err)
+	}
+	fees, err := httpClient.TransactionPoolFeeTargetGet(modules.DefaultFeeTarget, modules.DefaultFeeConfidence)
+	if err != nil {
+		die("Could not get fee estimation:", err)
+	}
+	rejections, err := httpClient.TransactionPoolRejectionsGet()
+	if err != nil {
+		die("Could not get rejected transaction sets:", err)
+	}
+	var txns int
+	var size uint64
+	for _, set := range sets.Sets {
+		txns += len(set.Transactions)
+		size += set.Size
+	}
+	fmt.Printf(`Transaction Pool:
+  Transaction Sets:     %v
+  Transactions:         %v
+  Size:                 %v
+  Recent Rejections:    %v
+
+Fee Estimation:
+  Minimum:              %v
+  Maximum:              %v
+  Within %v blocks:      %v (%v%% confidence)
+`, len(sets.Sets), txns, modules.FilesizeUnits(size), len(rejections.Rejections),
+		feeString(fees.Minimum), feeString(fees.Maximum),
+		fees.Estimate.Target, feeString(fees.Estimate.FeePerByte), fees.Estimate.Confidence*100)
+}
+
+// tpoolrejectionscmd is the handler for the command `siac tpool rejections`.
+// Lists the recently rejected transaction sets.
+func tpoolrejectionscmd() {
+	rejections, err := httpClient.TransactionPoolRejectionsGet()
+	if err != nil {
+		die("Could not get rejected transaction sets:", err)
+	}
+	if len(rejections.Rejections) == 0 {
+		fmt.Println("No transaction sets were rejected recently.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Time\tReason\tSet ID\tTransactions\tSize\tFee\tPeer\tError")
+	for _, r := range rejections.Rejections {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\t%v\n", r.Time.Format(time.RFC822), r.Reason, crypto.Hash(r.SetID),
+			len(r.TransactionIDs), modules.FilesizeUnits(r.Size), feeString(r.FeePerByte), peerString(r.Peer), r.Error)
+	}
+	w.Flush()
+}
+
+// tpoolsetcmd is the handler for the command `siac tpool set [id]`.
+// Prints a transaction set and the relationships of its transactions.
+func tpoolsetcmd(idStr string) {
+	var id crypto.Hash
+	if err := id.LoadString(idStr); err != nil {
+		die("Could not parse id:", err)
+	}
+	tsg, err := httpClient.TransactionPoolSetGet(id)
+	if err != nil {
+		die("Could not get transaction set:", err)
+	}
+	set := tsg.Set
+	fmt.Printf(`Transaction Set %v:
+  Arrival:       %v (height %v)
+  Peer:          %v
+  Size:          %v
+  Fees:          %v
+  Fee:           %v
+
+Transactions:
+`, crypto.Hash(set.ID), set.Arrival.Format(time.RFC822), set.ArrivalHeight, peerString(set.Peer),
+		modules.FilesizeUnits(set.Size), set.Fees.HumanString(), feeString(set.FeePerByte))
+	for _, ti := range set.Transactions {
+		fmt.Printf("  %v\n", ti.ID)
+		fmt.Printf("    Size:       %v\n", modules.FilesizeUnits(ti.Size))
+		fmt.Printf("    Fees:       %v\n", ti.Fees.HumanString())
+		for _, parent := range ti.Parents {
+			fmt.Printf("    Parent:     %v\n", parent)
+		}
+		for _, child := range ti.Children {
+			fmt.Printf("    Child:      %v\n", child)
+		}
+	}
+}
+
+// tpoolsetscmd is the handler for the command `siac tpool sets`.
+// Lists the transaction sets of the transaction pool.
+func tpoolsetscmd() {
+	sets, err := httpClient.TransactionPoolSetsGet()
+	if err != nil {
+		die("Could not get transaction sets:", err)
+	}
+	if len(sets.Sets) == 0 {
+		fmt.Println("The transaction pool is empty.")
+		return
+	}
+	w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Set ID\tTransactions\tSize\tFee\tAge\tPeer")
+	for _, set := range sets.Sets {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", crypto.Hash(set.ID), len(set.Transactions), modules.FilesizeUnits(set.Size),
+			feeString(set.FeePerByte), time.Since(set.Arrival).Round(time.Second), peerString(set.Peer))
+	}
+	w.Flush()
+}
@@ -6,10 +6,14 @@ import (
 	"fmt"
 	"math"
 	"math/big"
+	"net"
+	"net/http"
 	"os"
+	"sort"
 	"strconv"
 	"strings"
 	"syscall"
+	"text/tabwriter"
 	"time"
 
 	"github.com/spf13/cobra"
@@ -26,11 +30,47 @@ import (
 )
 
 var (
+	walletAccountsCmd = &cobra.Command{
+		Use:   "accounts",
+		Short: "List wallet accounts",
+		Long: `List the accounts of the wallet and their balances. Every account has its own
+addresses, derived from the primary seed, and only spends its own outputs. The
+primary account holds all other addresses of the wallet. Recovering the wallet
+from its seed restores the accounts that received coins, named account-N.`,
+		Run: wrap(walletaccountscmd),
+	}
+
+	walletAccountsCreateCmd = &cobra.Command{
+		Use:   "create [name]",
+		Short: "Create a wallet account",
+		Long: `Create a new wallet account. Names may contain letters, digits, '-' and '_'.
+Run 'siac wallet address --account [name]' to get an address of the account.`,
+		Run: wrap(walletaccountscreatecmd),
+	}
+
+	walletAccountsRenameCmd = &cobra.Command{
+		Use:   "rename [name] [newname]",
+		Short: "Rename a wallet account",
+		Long: `Rename a wallet account. Modules that fund from or pay into the account have to
+be configured with the new name.`,
+		Run: wrap(walletaccountsrenamecmd),
+	}
+
+	walletAccountsTransactionsCmd = &cobra.Command{
+		Use:   "transactions [name]",
+		Short: "View the transactions of a wallet account",
+		Long:  "View the transactions that involve the addresses of a wallet account.",
+		Run:   wrap(walletaccountstransactionscmd),
+	}
+
 	walletAddressCmd = &cobra.Command{
 		Use:   "address",
 		Short: "Get a new wallet address",
-		Long:  "Generate a new wallet address from the wallet's primary seed.",
-		Run:   wrap(walletaddresscmd),
+		Long: `Generate a new wallet address from the wallet's primary seed. With --timelock,
+siacoins sent to the address can't be spent before the given height, which must
+be a multiple of 144 and at most 5 years in the future. With --account, the
+address belongs to the given wallet account.`,
+		Run: wrap(walletaddresscmd),
 	}
 
 	walletAddressesCmd = &cobra.Command{
@@ -55,6 +95,20 @@ be valid. txn may be either JSON, base64, or a file containing either.`,
 		Run: wrap(walletbroadcastcmd),
 	}
 
+	walletBumpCmd = &cobra.Command{
+		Use:   "bump [txid]",
+		Short: "Raise the fee of an unconfirmed transaction",
+		Long: `Raise the fee of an unconfirmed transaction of the wallet that is stuck in the
+transaction pool. By default, a child transaction spends an output of the wallet
+created by the transaction and pays enough fees for both of them.
+
+--replace instead replaces the transaction with a copy that pays the higher fee
+from its change. All inputs of the transaction must belong to the wallet.
+--fee-per-byte sets the fee to reach, e.g. 10uS. The fee estimate of the
+transaction pool is used by default.`,
+		Run: wrap(walletbumpcmd),
+	}
+
 	walletChangepasswordCmd = &cobra.Command{
 		Use:   "change-password",
 		Short: "Change the wallet password",
@@ -96,6 +150,22 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletinitseedcmd),
 	}
 
+	walletLabelCmd = &cobra.Command{
+		Use:   "label [address] [label]",
+		Short: "Label an address",
+		Long: `Attach a label to an address, e.g. the name of a customer. An empty label
+removes the label of the address. 'siac wallet transactions --label' lists the
+transactions of the labeled addresses.`,
+		Run: wrap(walletlabelcmd),
+	}
+
+	walletLabelsCmd = &cobra.Command{
+		Use:   "labels",
+		Short: "List address labels",
+		Long:  "List the labels attached to addresses.",
+		Run:   wrap(walletlabelscmd),
+	}
+
 	walletLoad033xCmd = &cobra.Command{
 		Use:   "033x [filepath]",
 		Short: "Load a v0.3.3.x wallet",
@@ -132,6 +202,159 @@ By default the wallet encryption / unlock password is the same as the generated
 		Run:   wrap(walletlockcmd),
 	}
 
+	walletMultisigCmd = &cobra.Command{
+		Use:   "multisig",
+		Short: "Create and spend from multisig addresses",
+		Long: `Create addresses that require signatures from several keys, and collect the
+signatures of a transaction spending from them.
+
+Partially signed transactions are printed as JSON and may be passed to the
+other commands either as JSON or as a file containing it.`,
+		// Run field is not set, as the multisig command itself is not a valid
+		// command. A subcommand must be provided.
+	}
+
+	walletMultisigAddressCmd = &cobra.Command{
+		Use:   "address [required] [pubkey]...",
+		Short: "Create a multisig address",
+		Long: `Create an address that requires 'required' signatures from the provided public
+keys, e.g. 2 of 3. Public keys are of the form ed25519:<hex>; use
+'siac wallet multisig pubkey' to get a key of this wallet. The address is added
+to the wallet's watch set. Unless --unused is provided, the wallet rescans the
+blockchain for outputs of the address.`,
+		Run: walletmultisigaddresscmd,
+	}
+
+	walletMultisigBroadcastCmd = &cobra.Command{
+		Use:   "broadcast [txn]",
+		Short: "Broadcast a multisig transaction",
+		Long:  "Broadcast a partially signed transaction once it has enough signatures for every input.",
+		Run:   wrap(walletmultisigbroadcastcmd),
+	}
+
+	walletMultisigFundCmd = &cobra.Command{
+		Use:   "fund [address] [amount] [dest]",
+		Short: "Create a transaction spending from a multisig address",
+		Long: `Create an unsigned transaction that sends 'amount' from the multisig 'address'
+to 'dest'. The change is returned to the multisig address. The transaction
+must be signed by enough cosigners before it can be broadcast.`,
+		Run: wrap(walletmultisigfundcmd),
+	}
+
+	walletMultisigMergeCmd = &cobra.Command{
+		Use:   "merge [txn]...",
+		Short: "Merge the signatures of multisig transactions",
+		Long:  "Combine the signatures that the cosigners added to copies of the same partially signed transaction.",
+		Run:   walletmultisigmergecmd,
+	}
+
+	walletMultisigPubkeyCmd = &cobra.Command{
+		Use:   "pubkey",
+		Short: "Get a public key to share with cosigners",
+		Long:  "Generate a new address and print its public key, which can be used to create a multisig address.",
+		Run:   wrap(walletmultisigpubkeycmd),
+	}
+
+	walletMultisigSignCmd = &cobra.Command{
+		Use:   "sign [txn]",
+		Short: "Sign a multisig transaction",
+		Long:  "Add the wallet's signatures to a partially signed transaction.",
+		Run:   wrap(walletmultisigsigncmd),
+	}
+
+	walletSignerCmd = &cobra.Command{
+		Use:   "signer",
+		Short: "View and configure the external signer",
+		Long: `View the external signer of the wallet. While a signer is configured, the
+wallet sends siacoins from the watched addresses of the signer and asks the
+signer to sign the transactions, so that the seed of these addresses never has
+to be stored by siad. Contracts and other transactions built by the renter and
+host are still funded and signed by the wallet's own keys.`,
+		Run: wrap(walletsignercmd),
+	}
+
+	walletSignerAddressesCmd = &cobra.Command{
+		Use:   "addresses [n]",
+		Short: "Fetch addresses from the signer",
+		Long: `Fetch the next n addresses from the signer and add them to the wallet's watch
+set. Unless --unused is provided, the wallet rescans the blockchain for outputs
+of the addresses.`,
+		Run: wrap(walletsigneraddressescmd),
+	}
+
+	walletSignerServeCmd = &cobra.Command{
+		Use:   "serve [address]",
+		Short: "Run a signer",
+		Long: `Run a signer that holds a seed and signs the transactions of a wallet. The
+signer listens on the host:port 'address', or on a unix socket if the address
+is of the form unix:///path/to/socket. The seed is requested when the signer
+starts. With --password, the signer also requires a password, which has to be
+part of the address configured in the wallet, e.g.
+http://:password@localhost:9990.
+
+The signer signs every transaction it receives, so anyone who can reach it can
+spend the seed's funds. It only listens on a loopback address or a unix socket
+unless --password is provided, and it doesn't use TLS.`,
+		Run: wrap(walletsignerservecmd),
+	}
+
+	walletSignerSetCmd = &cobra.Command{
+		Use:   "set [address]",
+		Short: "Configure the external signer",
+		Long: `Configure the signer at 'address', which is either an http://host:port or a
+unix:///path/to/socket URL. A password is provided as part of the URL, e.g.
+http://:password@localhost:9990. An empty address removes the signer.`,
+		Run: wrap(walletsignersetcmd),
+	}
+
+	walletNoteCmd = &cobra.Command{
+		Use:   "note [txid] [note]",
+		Short: "Attach a note to a transaction",
+		Long:  "Attach a free-text note to a transaction. An empty note removes the note of the transaction.",
+		Run:   wrap(walletnotecmd),
+	}
+
+	walletRequestCmd = &cobra.Command{
+		Use:   "request [amount] [blocks]",
+		Short: "Request a payment",
+		Long: `Request a payment of 'amount' to a fresh address of the wallet. Payments that
+are confirmed within 'blocks' blocks count towards the request.
+--label labels the address of the request.`,
+		Run: wrap(walletrequestcmd),
+	}
+
+	walletRequestsCmd = &cobra.Command{
+		Use:   "requests",
+		Short: "List payment requests",
+		Long:  "List the payment requests of the wallet and how much of them has been paid.",
+		Run:   wrap(walletrequestscmd),
+	}
+
+	walletScheduledCmd = &cobra.Command{
+		Use:   "scheduled",
+		Short: "List scheduled payments",
+		Long:  "List the payments that the wallet sends once the blockchain reaches their height.",
+		Run:   wrap(walletscheduledcmd),
+	}
+
+	walletScheduledAddCmd = &cobra.Command{
+		Use:   "add [amount] [dest] [height]",
+		Short: "Schedule a payment",
+		Long: `Send 'amount' to 'dest' once the blockchain reaches 'height'. With --interval,
+the payment is repeated every 'interval' blocks until --count payments were
+made, or indefinitely if no count is given. siad has to be running and the
+wallet unlocked for the payments to be sent. Failed payments are retried after
+the next block.`,
+		Run: wrap(walletscheduledaddcmd),
+	}
+
+	walletScheduledCancelCmd = &cobra.Command{
+		Use:   "cancel [id]",
+		Short: "Cancel a scheduled payment",
+		Long:  "Remove a payment from the queue of scheduled payments.",
+		Run:   wrap(walletscheduledcancelcmd),
+	}
+
 	walletSeedsCmd = &cobra.Command{
 		Use:   "seeds",
 		Short: "View information about your seeds",
@@ -154,8 +377,17 @@ By default the wallet encryption / unlock password is the same as the generated
 'amount' can be specified in units, e.g. 1.23KS. Run 'wallet --help' for a list of units.
 If no unit is supplied, hastings will be assumed.
 
-A dynamic transaction fee is applied depending on the size of the transaction and how busy the network is.`,
-		Run: wrap(walletsendsiacoinscmd),
+A dynamic transaction fee is applied depending on the size of the transaction and how busy the network is.
+
+Coin control:
+--inputs spends exactly the listed outputs, as reported by /wallet/unspent.
+--spend-all sends the value of all inputs, or of the whole wallet if no inputs are listed,
+minus the fee to 'dest'. The amount is omitted: 'siacoins --spend-all [dest]'.
+--change-address sends the change to another address, which may be watch-only.
+--fee and --fee-per-byte replace the dynamic fee.
+--account spends the outputs of a wallet account and sends the change back to it.
+--dry-run prints the unsigned transaction and its fees without sending it.`,
+		Run: walletsendsiacoinscmd,
 	}
 
 	walletSendSiafundsCmd = &cobra.Command{
@@ -192,8 +424,10 @@ will be sent to your wallet.`,
 	walletTransactionsCmd = &cobra.Command{
 		Use:   "transactions",
 		Short: "View transactions",
-		Long:  "View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction",
-		Run:   wrap(wallettransactionscmd),
+		Long: `View transactions related to addresses spendable by the wallet, providing a net flow of siacoins and siafunds for each transaction.
+--label only shows the transactions related to addresses with that label.
+--csv exports the transactions with their labels and notes as CSV.`,
+		Run: wrap(wallettransactionscmd),
 	}
 
 	walletUnlockCmd = &cobra.Command{
@@ -236,9 +470,83 @@ func confirmPassword(prev string) error {
 	return nil
 }
 
+// walletAccountName returns the name of a wallet account for display.
+func walletAccountName(name string) string {
+	if name == "" {
+		return "(primary)"
+	}
+	return name
+}
+
+// walletaccountscmd lists the accounts of the wallet.
+func walletaccountscmd() {
+	wag, err := httpClient.WalletAccountsGet()
+	if err != nil {
+		die("Could not fetch accounts:", err)
+	}
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Name\tIndex\tAddresses\tConfirmed Balance\tUnconfirmed Delta\tSiafunds")
+	for _, wa := range wag.Accounts {
+		index := "-"
+		if wa.Name != "" {
+			index = fmt.Sprint(wa.Index)
+		}
+		var delta string
+		if wa.UnconfirmedIncomingSiacoins.Cmp(wa.UnconfirmedOutgoingSiacoins) < 0 {
+			delta = "-" + wa.UnconfirmedOutgoingSiacoins.Sub(wa.UnconfirmedIncomingSiacoins).HumanString()
+		} else {
+			delta = "+" + wa.UnconfirmedIncomingSiacoins.Sub(wa.UnconfirmedOutgoingSiacoins).HumanString()
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v SF\n", walletAccountName(wa.Name), index, wa.Addresses, wa.ConfirmedSiacoinBalance.HumanString(), delta, wa.ConfirmedSiafundBalance)
+	}
+	w.Flush()
+}
+
+// walletaccountscreatecmd creates a new wallet account.
+func walletaccountscreatecmd(name string) {
+	wa, err := httpClient.WalletAccountsPost(name)
+	if err != nil {
+		die("Could not create account:", err)
+	}
+	fmt.Printf("Created account %v with index %v\n", wa.Name, wa.Index)
+}
+
+// walletaccountsrenamecmd renames a wallet account.
+func walletaccountsrenamecmd(name, newName string) {
+	if err := httpClient.WalletAccountsRenamePost(name, newName); err != nil {
+		die("Could not rename account:", err)
+	}
+	fmt.Printf("Renamed account %v to %v\n", name, newName)
+}
+
+// walletaccountstransactionscmd lists the transactions of a wallet account.
+func walletaccountstransactionscmd(name string) {
+	watg, err := httpClient.WalletAccountsTransactionsGet(name)
+	if err != nil {
+		die("Could not fetch transaction history:", err)
+	}
+	printTransactions(watg.Transactions)
+}
+
 // walletaddresscmd fetches a new address from the wallet that will be able to
 // receive coins.
 func walletaddresscmd() {
+	if walletAccount != "" {
+		addr, err := httpClient.WalletAccountAddressGet(walletAccount)
+		if err != nil {
+			die("Could not generate new address:", err)
+		}
+		fmt.Printf("Created new address of account %v: %s\n", walletAccount, addr.Address)
+		return
+	}
+	if walletAddressTimelock > 0 {
+		addr, err := httpClient.WalletTimelockedAddressGet(types.BlockHeight(walletAddressTimelock))
+		if err != nil {
+			die("Could not generate new address:", err)
+		}
+		fmt.Printf("Created new address locked until height %v: %s\n", walletAddressTimelock, addr.Address)
+		return
+	}
 	addr, err := httpClient.WalletAddressGet()
 	if err != nil {
 		die("Could not generate new address:", err)
@@ -369,6 +677,43 @@ func walletloadsiagcmd(keyfiles string) {
 	fmt.Println("Wallet loading successful.")
 }
 
+// walletlabelcmd labels an address.
+func walletlabelcmd(addrStr, label string) {
+	var addr types.UnlockHash
+	if err := addr.LoadString(addrStr); err != nil {
+		die("Could not parse address:", err)
+	}
+	if err := httpClient.WalletLabelsPost(addr, label); err != nil {
+		die("Could not label address:", err)
+	}
+	if label == "" {
+		fmt.Println("Removed the label of", addr)
+		return
+	}
+	fmt.Printf("Labeled %v as %q\n", addr, label)
+}
+
+// walletlabelscmd lists the address labels of the wallet.
+func walletlabelscmd() {
+	wlg, err := httpClient.WalletLabelsGet()
+	if err != nil {
+		die("Could not fetch labels:", err)
+	}
+	if len(wlg.Labels) == 0 {
+		fmt.Println("No labeled addresses.")
+		return
+	}
+	sort.Slice(wlg.Labels, func(i, j int) bool {
+		return wlg.Labels[i].Label < wlg.Labels[j].Label
+	})
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Label\tAddress")
+	for _, l := range wlg.Labels {
+		fmt.Fprintf(w, "%v\t%v\n", l.Label, l.Address)
+	}
+	w.Flush()
+}
+
 // walletlockcmd locks the wallet
 func walletlockcmd() {
 	err := httpClient.WalletLockPost()
@@ -377,6 +722,120 @@ func walletlockcmd() {
 	}
 }
 
+// walletnotecmd attaches a note to a transaction.
+func walletnotecmd(txidStr, note string) {
+	var txid types.TransactionID
+	if err := txid.UnmarshalJSON([]byte(`"` + txidStr + `"`)); err != nil {
+		die("Could not parse transaction id:", err)
+	}
+	if err := httpClient.WalletTransactionNotePost(txid, note); err != nil {
+		die("Could not attach note:", err)
+	}
+	fmt.Println("Note saved")
+}
+
+// walletrequestcmd requests a payment to a fresh address.
+func walletrequestcmd(amount, blocks string) {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	expiry, err := strconv.ParseUint(blocks, 10, 64)
+	if err != nil {
+		die("Could not parse blocks:", err)
+	}
+	pr, err := httpClient.WalletPaymentRequestsPost(value, types.BlockHeight(expiry), walletRequestLabel)
+	if err != nil {
+		die("Could not create payment request:", err)
+	}
+	fmt.Printf("Requested %v to %v, expiring at height %v\n", pr.Amount.HumanString(), pr.Address, pr.ExpiryHeight)
+}
+
+// walletrequestscmd lists the payment requests of the wallet.
+func walletrequestscmd() {
+	wprg, err := httpClient.WalletPaymentRequestsGet()
+	if err != nil {
+		die("Could not fetch payment requests:", err)
+	}
+	if len(wprg.PaymentRequests) == 0 {
+		fmt.Println("No payment requests.")
+		return
+	}
+	sort.Slice(wprg.PaymentRequests, func(i, j int) bool {
+		return wprg.PaymentRequests[i].CreateHeight < wprg.PaymentRequests[j].CreateHeight
+	})
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "Address\tLabel\tAmount\tReceived\tExpiry\tStatus")
+	for _, pr := range wprg.PaymentRequests {
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\n", pr.Address, pr.Label, pr.Amount.HumanString(), pr.Received.HumanString(), pr.ExpiryHeight, pr.Status)
+	}
+	w.Flush()
+}
+
+// walletscheduledcmd lists the scheduled payments of the wallet.
+func walletscheduledcmd() {
+	wsg, err := httpClient.WalletScheduledGet()
+	if err != nil {
+		die("Could not fetch scheduled payments:", err)
+	}
+	if len(wsg.ScheduledPayments) == 0 {
+		fmt.Println("No scheduled payments.")
+		return
+	}
+	sort.Slice(wsg.ScheduledPayments, func(i, j int) bool {
+		return wsg.ScheduledPayments[i].Height < wsg.ScheduledPayments[j].Height
+	})
+	w := tabwriter.NewWriter(os.Stdout, 2, 0, 2, ' ', 0)
+	fmt.Fprintln(w, "ID\tDestination\tAmount\tNext Height\tInterval\tPayments\tLast Error")
+	for _, sp := range wsg.ScheduledPayments {
+		payments := fmt.Sprintf("%v/%v", sp.Payments, sp.Count)
+		if sp.Interval == 0 {
+			payments = fmt.Sprintf("%v/1", sp.Payments)
+		} else if sp.Count == 0 {
+			payments = fmt.Sprintf("%v/unlimited", sp.Payments)
+		}
+		fmt.Fprintf(w, "%v\t%v\t%v\t%v\t%v\t%v\t%v\n", sp.ID, sp.Destination, sp.Amount.HumanString(), sp.Height, sp.Interval, payments, sp.LastError)
+	}
+	w.Flush()
+}
+
+// walletscheduledaddcmd schedules a payment.
+func walletscheduledaddcmd(amount, dest, height string) {
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var addr types.UnlockHash
+	if err := addr.LoadString(dest); err != nil {
+		die("Could not parse destination address:", err)
+	}
+	h, err := strconv.ParseUint(height, 10, 64)
+	if err != nil {
+		die("Could not parse height:", err)
+	}
+	sp, err := httpClient.WalletScheduledPost(addr, value, types.BlockHeight(h), types.BlockHeight(walletScheduledInterval), walletScheduledCount)
+	if err != nil {
+		die("Could not schedule payment:", err)
+	}
+	fmt.Printf("Scheduled payment %v of %v to %v at height %v\n", sp.ID, sp.Amount.HumanString(), sp.Destination, sp.Height)
+}
+
+// walletscheduledcancelcmd cancels a scheduled payment.
+func walletscheduledcancelcmd(id string) {
+	if err := httpClient.WalletScheduledCancelPost(id); err != nil {
+		die("Could not cancel scheduled payment:", err)
+	}
+	fmt.Println("Cancelled scheduled payment", id)
+}
+
 // walletseedcmd returns the current seed {
 func walletseedscmd() {
 	seedInfo, err := httpClient.WalletSeedsGet()
@@ -401,7 +860,18 @@ func walletseedscmd() {
 }
 
 // walletsendsiacoinscmd sends siacoins to a destination address.
-func walletsendsiacoinscmd(amount, dest string) {
+func walletsendsiacoinscmd(cmd *cobra.Command, args []string) {
+	coinControl := walletSendInputs != "" || walletSendSpendAll || walletSendChangeAddress != "" ||
+		walletSendFee != "" || walletSendFeePerByte != "" || walletSendFeeTarget != 0 || walletSendDryRun || walletAccount != ""
+	if walletSendSpendAll && len(args) == 1 {
+		args = append([]string{"0"}, args...)
+	}
+	if len(args) != 2 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	amount, dest := args[0], args[1]
+
 	hastings, err := parseCurrency(amount)
 	if err != nil {
 		die("Could not parse amount:", err)
@@ -414,11 +884,69 @@ func walletsendsiacoinscmd(amount, dest string) {
 	if _, err := fmt.Sscan(dest, &hash); err != nil {
 		die("Failed to parse destination address", err)
 	}
-	_, err = httpClient.WalletSiacoinsPost(value, hash)
+	if !coinControl {
+		_, err = httpClient.WalletSiacoinsPost(value, hash)
+		if err != nil {
+			die("Could not send siacoins:", err)
+		}
+		fmt.Printf("Sent %s hastings to %s\n", hastings, dest)
+		return
+	}
+
+	cc := modules.CoinControl{
+		SpendAll: walletSendSpendAll,
+		DryRun:   walletSendDryRun,
+		Account:  walletAccount,
+	}
+	if walletSendInputs != "" {
+		for _, str := range strings.Split(walletSendInputs, ",") {
+			var id crypto.Hash
+			if err := id.LoadString(strings.TrimSpace(str)); err != nil {
+				die("Failed to parse input", str, err)
+			}
+			cc.Inputs = append(cc.Inputs, types.SiacoinOutputID(id))
+		}
+	}
+	if walletSendChangeAddress != "" {
+		if _, err := fmt.Sscan(walletSendChangeAddress, &cc.ChangeAddress); err != nil {
+			die("Failed to parse change address", err)
+		}
+	}
+	parseFee := func(str string) types.Currency {
+		hastings, err := parseCurrency(str)
+		if err != nil {
+			die("Could not parse fee:", err)
+		}
+		var fee types.Currency
+		if _, err := fmt.Sscan(hastings, &fee); err != nil {
+			die("Failed to parse fee", err)
+		}
+		return fee
+	}
+	if walletSendFee != "" {
+		cc.Fee = parseFee(walletSendFee)
+	}
+	if walletSendFeePerByte != "" {
+		cc.FeePerByte = parseFee(walletSendFeePerByte)
+	}
+	cc.FeeTarget = types.BlockHeight(walletSendFeeTarget)
+
+	wsp, err := httpClient.WalletSiacoinsCoinControlPost([]types.SiacoinOutput{{Value: value, UnlockHash: hash}}, cc)
 	if err != nil {
 		die("Could not send siacoins:", err)
 	}
-	fmt.Printf("Sent %s hastings to %s\n", hastings, dest)
+	if fb := wsp.FeeBreakdown; fb != nil {
+		fmt.Fprintf(os.Stderr, `Inputs:       %v
+Outputs:      %v
+Change:       %v
+Fee:          %v (%v per byte, %v bytes)
+`, fb.Inputs.HumanString(), fb.Outputs.HumanString(), fb.Change.HumanString(), fb.Fee.HumanString(), fb.FeePerByte.HumanString(), fb.Size)
+	}
+	if walletSendDryRun {
+		json.NewEncoder(os.Stdout).Encode(wsp.Transactions[0])
+		return
+	}
+	fmt.Printf("Sent transaction %v to %s\n", wsp.TransactionIDs[0], dest)
 }
 
 // walletsendsiafundscmd sends siafunds to a destination address.
@@ -449,7 +977,7 @@ func walletbalancecmd() {
 		die("Could not get wallet status:", err)
 	}
 
-	fees, err := httpClient.TransactionPoolFeeGet()
+	fees, err := httpClient.TransactionPoolFeeTargetGet(modules.DefaultFeeTarget, modules.DefaultFeeConfidence)
 	if err != nil {
 		die("Could not get fee estimation:", err)
 	}
@@ -477,15 +1005,18 @@ Unlock the wallet to view balance
 %s, Unlocked
 Height:              %v
 Confirmed Balance:   %v
+  Spendable:         %v
+  Locked:            %v
 Unconfirmed Delta:  %v
 Exact:               %v H
 Siafunds:            %v SF
 Siafund Claims:      %v H
 
 Estimated Fee:       %v / KB
-`, encStatus, status.Height, currencyUnits(status.ConfirmedSiacoinBalance), delta,
+`, encStatus, status.Height, currencyUnits(status.ConfirmedSiacoinBalance),
+		currencyUnits(status.SpendableSiacoinBalance), currencyUnits(status.LockedSiacoinBalance), delta,
 		status.ConfirmedSiacoinBalance, status.SiafundBalance, status.SiacoinClaimBalance,
-		fees.Maximum.Mul64(1e3).HumanString())
+		fees.Estimate.FeePerByte.Mul64(1e3).HumanString())
 }
 
 // walletbroadcastcmd broadcasts a transaction.
@@ -501,6 +1032,35 @@ func walletbroadcastcmd(txnStr string) {
 	fmt.Println("Transaction has been broadcast successfully")
 }
 
+// walletbumpcmd raises the fee of an unconfirmed transaction.
+func walletbumpcmd(txidStr string) {
+	var txid types.TransactionID
+	if err := txid.UnmarshalJSON([]byte(`"` + txidStr + `"`)); err != nil {
+		die("Could not parse transaction id:", err)
+	}
+	var feePerByte types.Currency
+	if walletBumpFeePerByte != "" {
+		hastings, err := parseCurrency(walletBumpFeePerByte)
+		if err != nil {
+			die("Could not parse fee per byte:", err)
+		}
+		if _, err := fmt.Sscan(hastings, &feePerByte); err != nil {
+			die("Could not parse fee per byte:", err)
+		}
+	}
+	wtbp, err := httpClient.WalletTransactionBumpPost(txid, feePerByte, walletBumpReplace)
+	if err != nil {
+		die("Could not bump transaction fee:", err)
+	}
+	fb := wtbp.FeeBreakdown
+	if walletBumpReplace {
+		fmt.Printf("Replaced transaction %v with %v\n", txid, wtbp.TransactionID)
+	} else {
+		fmt.Printf("Broadcast child transaction %v paying for %v\n", wtbp.TransactionID, txid)
+	}
+	fmt.Printf("Fee: %v (%v per byte, %v bytes)\n", fb.Fee.HumanString(), fb.FeePerByte.HumanString(), fb.Size)
+}
+
 // walletsweepcmd sweeps coins and funds from a seed.
 func walletsweepcmd() {
 	seed, err := passwordPrompt("Seed: ")
@@ -594,16 +1154,34 @@ func walletsigncmdoffline(txn *types.Transaction, toSign []crypto.Hash) {
 // wallettransactionscmd lists all of the transactions related to the wallet,
 // providing a net flow of siacoins and siafunds for each.
 func wallettransactionscmd() {
-	wtg, err := httpClient.WalletTransactionsGet(0, math.MaxInt64)
+	if walletTransactionsCSV {
+		csv, err := httpClient.WalletTransactionsCSVGet(0, math.MaxInt64, walletTransactionsLabel)
+		if err != nil {
+			die("Could not export transaction history:", err)
+		}
+		os.Stdout.Write(csv)
+		return
+	}
+	var wtg api.WalletTransactionsGET
+	var err error
+	if walletTransactionsLabel != "" {
+		wtg, err = httpClient.WalletTransactionsLabelGet(0, math.MaxInt64, walletTransactionsLabel)
+	} else {
+		wtg, err = httpClient.WalletTransactionsGet(0, math.MaxInt64)
+	}
 	if err != nil {
 		die("Could not fetch transaction history:", err)
 	}
+	printTransactions(append(wtg.ConfirmedTransactions, wtg.UnconfirmedTransactions...))
+}
+
+// printTransactions prints the net value of the transactions for the wallet.
+func printTransactions(txns []modules.ProcessedTransaction) {
 	cg, err := httpClient.ConsensusGet()
 	if err != nil {
 		die("Could not fetch consensus information:", err)
 	}
 	fmt.Println("             [timestamp]    [height]                                                   [transaction id]    [net siacoins]   [net siafunds]")
-	txns := append(wtg.ConfirmedTransactions, wtg.UnconfirmedTransactions...)
 	sts, err := wallet.ComputeValuedTransactions(txns, cg.Height)
 	if err != nil {
 		die("Could not compute valued transaction: ", err)
@@ -674,3 +1252,225 @@ func walletunlockcmd() {
 		die("Could not unlock wallet:", err)
 	}
 }
+
+// printMultisigTxn prints a partially signed transaction as JSON and reports
+// the signatures collected for each input on stderr, so that the output can be
+// redirected to a file.
+func printMultisigTxn(resp api.WalletMultisigPOSTResp) {
+	for _, s := range resp.Status {
+		fmt.Fprintf(os.Stderr, "Input %v: %v of %v signatures\n", s.ParentID, s.Collected, s.Required)
+	}
+	if resp.ThresholdMet {
+		fmt.Fprintln(os.Stderr, "The transaction has enough signatures to be broadcast.")
+	}
+	json.NewEncoder(os.Stdout).Encode(resp.Transaction)
+}
+
+// walletmultisigaddresscmd creates a multisig address.
+func walletmultisigaddresscmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	required, err := strconv.ParseUint(args[0], 10, 64)
+	if err != nil {
+		die("Could not parse number of required signatures:", err)
+	}
+	var pks []types.SiaPublicKey
+	for _, arg := range args[1:] {
+		var pk types.SiaPublicKey
+		pk.LoadString(arg)
+		if len(pk.Key) == 0 {
+			die("Could not parse public key", arg)
+		}
+		pks = append(pks, pk)
+	}
+	wmap, err := httpClient.WalletMultisigAddressPost(pks, required, walletMultisigUnused)
+	if err != nil {
+		die("Could not create multisig address:", err)
+	}
+	fmt.Printf("Created %v-of-%v multisig address %v\n", required, len(pks), wmap.Address)
+}
+
+// walletmultisigbroadcastcmd broadcasts a multisig transaction.
+func walletmultisigbroadcastcmd(txnStr string) {
+	pst, err := parsePartiallySignedTransaction(txnStr)
+	if err != nil {
+		die("Could not decode transaction:", err)
+	}
+	wmbp, err := httpClient.WalletMultisigBroadcastPost(pst)
+	if err != nil {
+		die("Could not broadcast transaction:", err)
+	}
+	fmt.Println("Transaction", wmbp.TransactionID, "has been broadcast successfully")
+}
+
+// walletmultisigfundcmd creates a transaction spending from a multisig
+// address.
+func walletmultisigfundcmd(addr, amount, dest string) {
+	var from types.UnlockHash
+	if _, err := fmt.Sscan(addr, &from); err != nil {
+		die("Failed to parse multisig address", err)
+	}
+	hastings, err := parseCurrency(amount)
+	if err != nil {
+		die("Could not parse amount:", err)
+	}
+	var value types.Currency
+	if _, err := fmt.Sscan(hastings, &value); err != nil {
+		die("Failed to parse amount", err)
+	}
+	var to types.UnlockHash
+	if _, err := fmt.Sscan(dest, &to); err != nil {
+		die("Failed to parse destination address", err)
+	}
+	wmp, err := httpClient.WalletMultisigFundPost(from, []types.SiacoinOutput{{Value: value, UnlockHash: to}})
+	if err != nil {
+		die("Could not fund multisig transaction:", err)
+	}
+	printMultisigTxn(wmp)
+}
+
+// walletmultisigmergecmd merges the signatures of multisig transactions.
+func walletmultisigmergecmd(cmd *cobra.Command, args []string) {
+	if len(args) < 2 {
+		cmd.UsageFunc()(cmd)
+		os.Exit(exitCodeUsage)
+	}
+	var psts []modules.PartiallySignedTransaction
+	for _, arg := range args {
+		pst, err := parsePartiallySignedTransaction(arg)
+		if err != nil {
+			die("Could not decode transaction:", err)
+		}
+		psts = append(psts, pst)
+	}
+	wmp, err := httpClient.WalletMultisigMergePost(psts...)
+	if err != nil {
+		die("Could not merge transactions:", err)
+	}
+	printMultisigTxn(wmp)
+}
+
+// walletmultisigpubkeycmd prints a public key of the wallet.
+func walletmultisigpubkeycmd() {
+	wag, err := httpClient.WalletAddressGet()
+	if err != nil {
+		die("Could not generate new address:", err)
+	}
+	wucg, err := httpClient.WalletUnlockConditionsGet(wag.Address)
+	if err != nil {
+		die("Could not get unlock conditions:", err)
+	}
+	fmt.Println(wucg.UnlockConditions.PublicKeys[0])
+}
+
+// walletmultisigsigncmd signs a multisig transaction.
+func walletmultisigsigncmd(txnStr string) {
+	pst, err := parsePartiallySignedTransaction(txnStr)
+	if err != nil {
+		die("Could not decode transaction:", err)
+	}
+	wmp, err := httpClient.WalletMultisigSignPost(pst)
+	if err != nil {
+		die("Could not sign transaction:", err)
+	}
+	printMultisigTxn(wmp)
+}
+
+// walletsignercmd prints the external signer of the wallet.
+func walletsignercmd() {
+	ws, err := httpClient.WalletSignerGet()
+	if err != nil {
+		die("Could not get signer:", err)
+	}
+	if ws.Address == "" {
+		fmt.Println("No signer configured.")
+	} else {
+		fmt.Println("Signer:", ws.Address)
+	}
+	fmt.Println("Watched signer addresses:", ws.Addresses)
+}
+
+// walletsignersetcmd configures the external signer of the wallet.
+func walletsignersetcmd(address string) {
+	err := httpClient.WalletSignerPost(address)
+	if err != nil {
+		die("Could not set signer:", err)
+	}
+	if address == "" {
+		fmt.Println("Removed signer.")
+	} else {
+		fmt.Println("Set signer.")
+	}
+}
+
+// walletsigneraddressescmd fetches addresses from the external signer.
+func walletsigneraddressescmd(nStr string) {
+	n, err := strconv.ParseUint(nStr, 10, 64)
+	if err != nil {
+		die("Could not parse number of addresses:", err)
+	}
+	wsap, err := httpClient.WalletSignerAddressesPost(n, walletSignerUnused)
+	if err != nil {
+		die("Could not fetch signer addresses:", err)
+	}
+	for _, uc := range wsap.UnlockConditions {
+		fmt.Println(uc.UnlockHash())
+	}
+}
+
+// walletsignerservecmd runs a signer for the seed provided by the user.
+func walletsignerservecmd(address string) {
+	network, url := "tcp", "http://"+address
+	if strings.HasPrefix(address, "unix://") {
+		network, address, url = "unix", strings.TrimPrefix(address, "unix://"), address
+	}
+	// The signer signs every transaction it receives, so it must not be
+	// reachable by others without a password.
+	if network == "tcp" && !walletSignerPassword && !isLoopbackAddress(address) {
+		die("Refusing to listen on a non-loopback address without --password")
+	}
+
+	seedString, err := passwordPrompt("Seed: ")
+	if err != nil {
+		die("Reading seed failed:", err)
+	}
+	seed, err := modules.StringToSeed(seedString, mnemonics.English)
+	if err != nil {
+		die("Invalid seed:", err)
+	}
+	var password string
+	if walletSignerPassword {
+		password, err = passwordPrompt("Signer password: ")
+		if err != nil {
+			die("Reading password failed:", err)
+		}
+		if err := confirmPassword(password); err != nil {
+			die(err)
+		}
+	}
+
+	l, err := net.Listen(network, address)
+	if err != nil {
+		die("Could not listen:", err)
+	}
+	fmt.Println("Signer listening on", url)
+	if err := http.Serve(l, wallet.SignerHandler(seed, password)); err != nil {
+		die("Signer stopped:", err)
+	}
+}
+
+// isLoopbackAddress returns true if the host of the host:port address is
+// localhost or a loopback IP. An empty host listens on all interfaces.
+func isLoopbackAddress(address string) bool {
+	host, _, err := net.SplitHostPort(address)
+	if err != nil {
+		return false
+	}
+	if host == "localhost" {
+		return true
+	}
+	ip := net.ParseIP(host)
+	return ip != nil && ip.IsLoopback()
+}
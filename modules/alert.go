@@ -51,6 +51,14 @@ const (
 	// registered if the host has insufficient collateral budget left to form or
 	// renew a contract
 	AlertIDHostInsufficientCollateral = "host-insufficient-collateral"
+	// AlertIDHostCorruptSectors is the id of the alert that is registered
+	// when the host's scrubber found corrupt sectors that belong to active
+	// storage obligations
+	AlertIDHostCorruptSectors = "host-corrupt-sectors"
+	// AlertIDHostCollateralShortfall is the id of the alert that is
+	// registered when the host's wallet balance is below the funds that its
+	// storage obligations are projected to need
+	AlertIDHostCollateralShortfall = "host-collateral-shortfall"
 )
 
 // AlertIDSiafileLowRedundancy uses a Siafile's UID to create a unique AlertID
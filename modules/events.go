@@ -0,0 +1,191 @@
+package modules
+
+import (
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// EventsDir is the name of the directory that is used to store the
+	// persistent data of the event manager.
+	EventsDir = "events"
+)
+
+// The following consts are the types of the events published by the event
+// manager.
+const (
+	// EventAlertRegistered is published when a module registers an alert.
+	EventAlertRegistered EventType = "alert.registered"
+	// EventAlertUnregistered is published when a module unregisters an
+	// alert.
+	EventAlertUnregistered EventType = "alert.unregistered"
+
+	// EventConsensusBlock is published for every block that is applied to
+	// the consensus set.
+	EventConsensusBlock EventType = "consensus.block"
+	// EventConsensusReorg is published when blocks are reverted from the
+	// consensus set.
+	EventConsensusReorg EventType = "consensus.reorg"
+
+	// EventHostContractFormed is published when the host forms a new
+	// storage obligation.
+	EventHostContractFormed EventType = "host.contractformed"
+	// EventHostProofMissed is published when a storage obligation of the
+	// host fails because no storage proof was confirmed.
+	EventHostProofMissed EventType = "host.proofmissed"
+	// EventHostProofSubmitted is published when a storage proof of the host
+	// is confirmed.
+	EventHostProofSubmitted EventType = "host.proofsubmitted"
+
+	// EventRenterContractRenewed is published when the renter renews a
+	// contract.
+	EventRenterContractRenewed EventType = "renter.contractrenewed"
+	// EventRenterFileHealthDropped is published when the health of a file
+	// drops.
+	EventRenterFileHealthDropped EventType = "renter.filehealthdropped"
+	// EventRenterUploadComplete is published when a file is fully uploaded.
+	EventRenterUploadComplete EventType = "renter.uploadcomplete"
+
+	// EventWalletPaymentReceived is published when a transaction that adds
+	// siacoins to the wallet is confirmed.
+	EventWalletPaymentReceived EventType = "wallet.paymentreceived"
+	// EventWalletPaymentSent is published when a transaction that spends
+	// siacoins of the wallet is confirmed.
+	EventWalletPaymentSent EventType = "wallet.paymentsent"
+)
+
+type (
+	// EventType is the type of an event.
+	EventType string
+
+	// Event is a notification about something that happened in one of the
+	// modules of the node. Events are numbered in the order in which they
+	// were published. The numbering restarts with the node. The data of the
+	// alert events is the Alert itself.
+	Event struct {
+		ID        uint64      `json:"id"`
+		Type      EventType   `json:"type"`
+		Timestamp time.Time   `json:"timestamp"`
+		Data      interface{} `json:"data"`
+	}
+
+	// EventBlockData is the data of an EventConsensusBlock.
+	EventBlockData struct {
+		BlockID types.BlockID     `json:"blockid"`
+		Height  types.BlockHeight `json:"height"`
+	}
+
+	// EventReorgData is the data of an EventConsensusReorg.
+	EventReorgData struct {
+		// Depth is the number of reverted blocks.
+		Depth uint64 `json:"depth"`
+		// ForkHeight is the height of the last block that both chains have
+		// in common.
+		ForkHeight types.BlockHeight `json:"forkheight"`
+	}
+
+	// EventContractData is the data of the host events.
+	EventContractData struct {
+		ContractID types.FileContractID `json:"contractid"`
+		Height     types.BlockHeight    `json:"height"`
+	}
+
+	// EventContractRenewedData is the data of an EventRenterContractRenewed.
+	EventContractRenewedData struct {
+		HostPublicKey types.SiaPublicKey   `json:"hostpublickey"`
+		NewContractID types.FileContractID `json:"newcontractid"`
+		OldContractID types.FileContractID `json:"oldcontractid"`
+	}
+
+	// EventFileData is the data of the renter file events.
+	EventFileData struct {
+		SiaPath          SiaPath `json:"siapath"`
+		MaxHealthPercent float64 `json:"maxhealthpercent"`
+		Redundancy       float64 `json:"redundancy"`
+	}
+
+	// EventPaymentData is the data of the wallet events.
+	EventPaymentData struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		Height        types.BlockHeight   `json:"height"`
+		// Value is the net amount of siacoins that the transaction added
+		// to or spent from the wallet.
+		Value types.Currency `json:"value"`
+	}
+
+	// Webhook is an HTTP endpoint that events are posted to.
+	Webhook struct {
+		ID  string `json:"id"`
+		URL string `json:"url"`
+		// Events are the types of the events that are posted to the webhook.
+		// All events are posted if no types are specified.
+		Events []EventType `json:"events"`
+		// Secret is used to sign the events posted to the webhook. It is
+		// never returned by the event manager.
+		Secret string `json:"secret,omitempty"`
+
+		// FailedDeliveries is the number of events that couldn't be
+		// delivered, even after retrying, or that were dropped because too
+		// many events were queued. LastError is the error of the last failed
+		// delivery.
+		FailedDeliveries uint64 `json:"faileddeliveries"`
+		LastError        string `json:"lasterror,omitempty"`
+	}
+
+	// EventManager publishes the events of the modules of a node to
+	// subscribers and webhooks.
+	EventManager interface {
+		// AddWebhook adds a webhook that receives the events of the given
+		// types, or all events if no types are specified. If secret is not
+		// empty, the events are signed with it.
+		AddWebhook(url string, events []EventType, secret string) (Webhook, error)
+
+		// Close stops the event manager.
+		Close() error
+
+		// Events returns the recent events that were published after the
+		// event with the given ID.
+		Events(since uint64) []Event
+
+		// RemoveWebhook removes the webhook with the given ID.
+		RemoveWebhook(id string) error
+
+		// Subscribe returns a channel that receives all future events and a
+		// function that cancels the subscription. The channel is closed if
+		// the subscriber falls too far behind, in which case it should catch
+		// up using Events.
+		Subscribe() (<-chan Event, func())
+
+		// Webhooks returns the webhooks of the event manager.
+		Webhooks() []Webhook
+	}
+)
+
+// IsValid returns true if the event type is one of the types published by the
+// event manager.
+func (et EventType) IsValid() bool {
+	switch et {
+	case EventAlertRegistered, EventAlertUnregistered,
+		EventConsensusBlock, EventConsensusReorg,
+		EventHostContractFormed, EventHostProofMissed, EventHostProofSubmitted,
+		EventRenterContractRenewed, EventRenterFileHealthDropped, EventRenterUploadComplete,
+		EventWalletPaymentReceived, EventWalletPaymentSent:
+		return true
+	default:
+		return false
+	}
+}
+
+// Matches returns true if the event should be posted to the webhook.
+func (wh Webhook) Matches(et EventType) bool {
+	if len(wh.Events) == 0 {
+		return true
+	}
+	for _, t := range wh.Events {
+		if t == et {
+			return true
+		}
+	}
+	return false
+}
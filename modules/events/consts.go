@@ -0,0 +1,75 @@
+package events
+
+import (
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/persist"
+)
+
+const (
+	// historySize is the number of recent events that are kept in memory for
+	// subscribers that reconnect.
+	historySize = 1000
+
+	// logFilename is the name of the event manager's log file.
+	logFilename = "events.log"
+
+	// maxWebhooks is the maximum number of webhooks.
+	maxWebhooks = 32
+
+	// minHealthDrop is the number of percentage points that the health of a
+	// file has to drop before an event is published.
+	minHealthDrop = 10
+
+	// obligationFailed is the status of a storage obligation that failed.
+	obligationFailed = "obligationFailed"
+
+	// subscriberBufferSize is the number of events that are buffered for a
+	// subscriber before it is considered to be too slow and dropped.
+	subscriberBufferSize = 256
+
+	// webhookMaxAttempts is the number of times the delivery of an event to a
+	// webhook is attempted before giving up.
+	webhookMaxAttempts = 5
+
+	// webhookQueueSize is the number of events that are queued for a webhook
+	// before further events are dropped.
+	webhookQueueSize = 256
+
+	// webhookSignatureHeader is the header that contains the hex encoded
+	// HMAC-SHA256 of the body of a webhook request if the webhook has a
+	// secret.
+	webhookSignatureHeader = "Sia-Signature"
+
+	// webhookTimeout is the timeout of a single webhook request.
+	webhookTimeout = 10 * time.Second
+
+	// webhooksFilename is the name of the file that contains the webhooks.
+	webhooksFilename = "webhooks.json"
+)
+
+var (
+	// pollInterval is the interval at which the modules are polled for new
+	// events. The wallet and the alerts are also polled after every
+	// consensus change.
+	pollInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 30 * time.Second,
+		Testing:  100 * time.Millisecond,
+	}).(time.Duration)
+
+	// webhookRetryInterval is the time before the first retry of a failed
+	// webhook delivery. It doubles with every further attempt.
+	webhookRetryInterval = build.Select(build.Var{
+		Dev:      time.Second,
+		Standard: 10 * time.Second,
+		Testing:  10 * time.Millisecond,
+	}).(time.Duration)
+
+	// webhooksMetadata is the metadata of the webhooks persist file.
+	webhooksMetadata = persist.Metadata{
+		Header:  "Event Webhooks",
+		Version: "1.4.4",
+	}
+)
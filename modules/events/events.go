@@ -0,0 +1,254 @@
+package events
+
+// events.go contains the EventManager which turns the state changes of the
+// modules of a node into events. Consensus changes are received from the
+// consensus set, everything else is found by comparing the state of the
+// modules after every consensus change and at a regular interval. Events are
+// kept in memory for subscribers and posted to the webhooks.
+
+import (
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/threadgroup"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/persist"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// EventManager publishes the events of the modules of a node.
+	EventManager struct {
+		// The modules that are watched for events. Any of them may be nil.
+		cs    modules.ConsensusSet
+		g     modules.Gateway
+		h     modules.Host
+		r     modules.Renter
+		tpool modules.TransactionPool
+		w     modules.Wallet
+
+		// history contains the most recent events, nextID is the ID of the
+		// next event.
+		history []modules.Event
+		nextID  uint64
+
+		// The subscribers by subscription number.
+		subscribers      map[uint64]chan modules.Event
+		nextSubscription uint64
+
+		// webhooks are the webhooks that events are posted to. Every webhook
+		// has a queue of events that are delivered in order by a single
+		// thread.
+		webhooks      []modules.Webhook
+		webhookQueues map[string]chan modules.Event
+
+		// height is the height of the consensus set as seen by the event
+		// manager. walletHeight is the height up to which the transactions
+		// of the wallet were checked for payments.
+		height       types.BlockHeight
+		walletHeight types.BlockHeight
+
+		// consensusChanged is signaled after every consensus change. synced
+		// reports whether the consensus set was synced at the last change.
+		consensusChanged chan struct{}
+		synced           bool
+
+		// The state of the modules at the last poll. They are only accessed by
+		// the polling thread and are nil until the first poll.
+		alerts      map[modules.Alert]struct{}
+		contracts   map[types.FileContractID]modules.RenterContract
+		files       map[modules.SiaPath]fileState
+		obligations map[types.FileContractID]modules.StorageObligation
+
+		log        *persist.Logger
+		mu         sync.Mutex
+		persistDir string
+		tg         threadgroup.ThreadGroup
+	}
+
+	// fileState is the state of a file at the last poll.
+	fileState struct {
+		// health is the lowest health of the file since the last health
+		// event, or since it last improved.
+		health         float64
+		uploadProgress float64
+	}
+
+	// webhooksPersist contains the persistent data of the EventManager.
+	webhooksPersist struct {
+		Webhooks []modules.Webhook `json:"webhooks"`
+	}
+)
+
+// New creates a new EventManager that watches the provided modules, any of
+// which may be nil.
+func New(g modules.Gateway, cs modules.ConsensusSet, tpool modules.TransactionPool, w modules.Wallet, r modules.Renter, h modules.Host, persistDir string) (*EventManager, error) {
+	em := &EventManager{
+		cs:    cs,
+		g:     g,
+		h:     h,
+		r:     r,
+		tpool: tpool,
+		w:     w,
+
+		nextID:           1,
+		subscribers:      make(map[uint64]chan modules.Event),
+		webhookQueues:    make(map[string]chan modules.Event),
+		consensusChanged: make(chan struct{}, 1),
+		persistDir:       persistDir,
+	}
+	if err := os.MkdirAll(persistDir, 0700); err != nil {
+		return nil, err
+	}
+	log, err := persist.NewFileLogger(filepath.Join(persistDir, logFilename))
+	if err != nil {
+		return nil, err
+	}
+	em.log = log
+	err = em.tg.AfterStop(func() error {
+		return em.log.Close()
+	})
+	if err != nil {
+		return nil, errors.Compose(err, log.Close())
+	}
+	// Closing the channels of the subscribers ends their subscriptions.
+	err = em.tg.OnStop(func() error {
+		em.mu.Lock()
+		defer em.mu.Unlock()
+		for id, c := range em.subscribers {
+			close(c)
+			delete(em.subscribers, id)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, errors.Compose(err, em.tg.Stop())
+	}
+
+	// Load the webhooks.
+	var data webhooksPersist
+	err = persist.LoadJSON(webhooksMetadata, &data, filepath.Join(persistDir, webhooksFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Compose(err, em.tg.Stop())
+	}
+	em.webhooks = data.Webhooks
+	for _, wh := range em.webhooks {
+		em.startWebhook(wh)
+	}
+
+	// Subscribe to the consensus set, starting at the current block.
+	em.synced = cs == nil
+	if cs != nil {
+		em.height = cs.Height()
+		em.walletHeight = em.height
+		em.synced = cs.Synced()
+		err = cs.ConsensusSetSubscribe(em, modules.ConsensusChangeRecent, em.tg.StopChan())
+		if err != nil {
+			return nil, errors.Compose(err, em.tg.Stop())
+		}
+		err = em.tg.OnStop(func() error {
+			cs.Unsubscribe(em)
+			return nil
+		})
+		if err != nil {
+			return nil, errors.Compose(err, em.tg.Stop())
+		}
+	}
+
+	go em.threadedMonitor()
+	return em, nil
+}
+
+// publish publishes an event to the subscribers and webhooks.
+func (em *EventManager) publish(et modules.EventType, data interface{}) {
+	e := modules.Event{
+		ID:        em.nextID,
+		Type:      et,
+		Timestamp: time.Now(),
+		Data:      data,
+	}
+	em.nextID++
+
+	em.history = append(em.history, e)
+	if len(em.history) > historySize {
+		em.history = em.history[len(em.history)-historySize:]
+	}
+	for id, c := range em.subscribers {
+		select {
+		case c <- e:
+		default:
+			// The subscriber is too slow, drop it.
+			close(c)
+			delete(em.subscribers, id)
+		}
+	}
+	for i, wh := range em.webhooks {
+		if !wh.Matches(et) {
+			continue
+		}
+		select {
+		case em.webhookQueues[wh.ID] <- e:
+		default:
+			// The webhook is too slow, drop the event.
+			em.log.Printf("WARN: dropping event %v for webhook %v since its queue is full", e.ID, wh.ID)
+			em.webhooks[i].FailedDeliveries++
+			em.webhooks[i].LastError = errWebhookQueueFull.Error()
+		}
+	}
+}
+
+// managedPublish publishes an event to the subscribers and webhooks.
+func (em *EventManager) managedPublish(et modules.EventType, data interface{}) {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	em.publish(et, data)
+}
+
+// Close stops the event manager.
+func (em *EventManager) Close() error {
+	return em.tg.Stop()
+}
+
+// Events returns the recent events that were published after the event with
+// the given ID.
+func (em *EventManager) Events(since uint64) []modules.Event {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	events := []modules.Event{}
+	for _, e := range em.history {
+		if e.ID > since {
+			events = append(events, e)
+		}
+	}
+	return events
+}
+
+// Subscribe returns a channel that receives all future events and a function
+// that cancels the subscription. The channel is closed if the subscriber falls
+// too far behind.
+func (em *EventManager) Subscribe() (<-chan modules.Event, func()) {
+	if err := em.tg.Add(); err != nil {
+		c := make(chan modules.Event)
+		close(c)
+		return c, func() {}
+	}
+	defer em.tg.Done()
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	id := em.nextSubscription
+	em.nextSubscription++
+	c := make(chan modules.Event, subscriberBufferSize)
+	em.subscribers[id] = c
+	return c, func() {
+		em.mu.Lock()
+		defer em.mu.Unlock()
+		if _, exists := em.subscribers[id]; exists {
+			close(c)
+			delete(em.subscribers, id)
+		}
+	}
+}
@@ -0,0 +1,313 @@
+package events
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"net/http/httptest"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// newTestEventManager creates an EventManager without any modules.
+func newTestEventManager(t *testing.T) *EventManager {
+	em, err := New(nil, nil, nil, nil, nil, nil, build.TempDir(modules.EventsDir, t.Name()))
+	if err != nil {
+		t.Fatal(err)
+	}
+	return em
+}
+
+// TestSubscribe checks that subscribers receive the published events and that
+// slow subscribers are dropped.
+func TestSubscribe(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	em := newTestEventManager(t)
+	defer em.Close()
+
+	c, cancel := em.Subscribe()
+	em.ProcessConsensusChange(modules.ConsensusChange{
+		AppliedBlocks: []types.Block{{Timestamp: 1}, {Timestamp: 2}},
+	})
+	for height := types.BlockHeight(1); height <= 2; height++ {
+		e := <-c
+		if e.ID != uint64(height) || e.Type != modules.EventConsensusBlock || e.Data.(modules.EventBlockData).Height != height {
+			t.Fatal("unexpected event", e)
+		}
+	}
+	em.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks: []types.Block{{Timestamp: 2}},
+	})
+	if e := <-c; e.Type != modules.EventConsensusReorg || e.Data.(modules.EventReorgData) != (modules.EventReorgData{Depth: 1, ForkHeight: 1}) {
+		t.Fatal("unexpected event", e)
+	}
+	cancel()
+	if _, ok := <-c; ok {
+		t.Fatal("channel should be closed after canceling the subscription")
+	}
+
+	// Recent events can be fetched from the history.
+	if events := em.Events(1); len(events) != 2 || events[0].ID != 2 {
+		t.Fatal("unexpected history", events)
+	}
+
+	// A subscriber that doesn't keep up is dropped.
+	c, cancel = em.Subscribe()
+	defer cancel()
+	for i := 0; i <= subscriberBufferSize; i++ {
+		em.managedPublish(modules.EventAlertRegistered, modules.Alert{})
+	}
+	for i := 0; i < subscriberBufferSize; i++ {
+		<-c
+	}
+	if _, ok := <-c; ok {
+		t.Fatal("slow subscriber wasn't dropped")
+	}
+	if events := em.Events(0); len(events) != subscriberBufferSize+4 {
+		t.Fatal("unexpected number of events in history", len(events))
+	}
+}
+
+// TestWebhooks checks that events are delivered to the matching webhooks,
+// that failed deliveries are retried and that webhooks are persisted.
+func TestWebhooks(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	em := newTestEventManager(t)
+
+	// The server fails the first request of every event.
+	var mu sync.Mutex
+	attempts := make(map[uint64]int)
+	received := make(chan modules.Event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		body, _ := ioutil.ReadAll(req.Body)
+		mac := hmac.New(sha256.New, []byte("secret"))
+		mac.Write(body)
+		if req.Header.Get(webhookSignatureHeader) != hex.EncodeToString(mac.Sum(nil)) {
+			t.Error("invalid signature")
+		}
+		var e modules.Event
+		if err := json.Unmarshal(body, &e); err != nil {
+			t.Error(err)
+		}
+		mu.Lock()
+		attempts[e.ID]++
+		first := attempts[e.ID] == 1
+		mu.Unlock()
+		if first {
+			w.WriteHeader(http.StatusServiceUnavailable)
+			return
+		}
+		received <- e
+	}))
+	defer srv.Close()
+
+	if _, err := em.AddWebhook("ftp://example.com", nil, ""); err != errInvalidWebhookURL {
+		t.Fatal("expected errInvalidWebhookURL, got", err)
+	}
+	if _, err := em.AddWebhook(srv.URL, []modules.EventType{"foo"}, ""); err == nil {
+		t.Fatal("expected an error for an unknown event type")
+	}
+	wh, err := em.AddWebhook(srv.URL, []modules.EventType{modules.EventConsensusReorg}, "secret")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wh.Secret != "" {
+		t.Fatal("secret of the webhook was returned")
+	}
+
+	// Only the reorg is posted to the webhook.
+	em.ProcessConsensusChange(modules.ConsensusChange{
+		AppliedBlocks: []types.Block{{}},
+	})
+	em.ProcessConsensusChange(modules.ConsensusChange{
+		RevertedBlocks: []types.Block{{}},
+	})
+	select {
+	case e := <-received:
+		if e.Type != modules.EventConsensusReorg || e.ID != 2 {
+			t.Fatal("unexpected event", e)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("event wasn't delivered")
+	}
+
+	// The webhook is persisted together with its secret.
+	if err := em.Close(); err != nil {
+		t.Fatal(err)
+	}
+	em, err = New(nil, nil, nil, nil, nil, nil, em.persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer em.Close()
+	if whs := em.Webhooks(); len(whs) != 1 || whs[0].ID != wh.ID || whs[0].Secret != "" {
+		t.Fatal("unexpected webhooks", whs)
+	}
+	if em.webhooks[0].Secret != "secret" {
+		t.Fatal("secret wasn't persisted")
+	}
+	if err := em.RemoveWebhook(wh.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := em.RemoveWebhook(wh.ID); err != errWebhookNotFound {
+		t.Fatal("expected errWebhookNotFound, got", err)
+	}
+}
+
+// TestWebhookQueue checks that events are posted to a webhook in order and
+// that events are dropped once the queue of a slow webhook is full.
+func TestWebhookQueue(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	em := newTestEventManager(t)
+	defer em.Close()
+
+	// The server blocks the first request until it is released.
+	blocked := make(chan struct{})
+	release := make(chan struct{})
+	received := make(chan modules.Event, webhookQueueSize+1)
+	var once sync.Once
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var e modules.Event
+		if err := json.NewDecoder(req.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		once.Do(func() {
+			close(blocked)
+			<-release
+		})
+		received <- e
+	}))
+	defer srv.Close()
+	if _, err := em.AddWebhook(srv.URL, nil, ""); err != nil {
+		t.Fatal(err)
+	}
+	alert := modules.Alert{Severity: modules.SeverityWarning}
+
+	// Fill the queue while the first event is being delivered. The last event
+	// doesn't fit into the queue anymore.
+	em.managedPublish(modules.EventAlertRegistered, alert)
+	<-blocked
+	for i := 0; i <= webhookQueueSize; i++ {
+		em.managedPublish(modules.EventAlertRegistered, alert)
+	}
+	if whs := em.Webhooks(); whs[0].FailedDeliveries != 1 || whs[0].LastError != errWebhookQueueFull.Error() {
+		t.Fatal("event wasn't dropped", whs[0])
+	}
+	close(release)
+
+	// The remaining events are delivered in order.
+	for id := uint64(1); id <= webhookQueueSize+1; id++ {
+		select {
+		case e := <-received:
+			if e.ID != id {
+				t.Fatalf("expected event %v but got %v", id, e.ID)
+			}
+		case <-time.After(10 * time.Second):
+			t.Fatal("event wasn't delivered", id)
+		}
+	}
+}
+
+// TestModuleEvents checks the events that are derived from the state of the
+// modules.
+func TestModuleEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	// The modules are polled by the test instead of the monitor thread.
+	em := &EventManager{
+		nextID:      1,
+		subscribers: make(map[uint64]chan modules.Event),
+	}
+	c, cancel := em.Subscribe()
+	defer cancel()
+	expect := func(et modules.EventType) modules.Event {
+		t.Helper()
+		select {
+		case e := <-c:
+			if e.Type != et {
+				t.Fatalf("expected %v, got %v", et, e.Type)
+			}
+			return e
+		default:
+			t.Fatal("expected event", et)
+		}
+		return modules.Event{}
+	}
+	expectNone := func() {
+		t.Helper()
+		select {
+		case e := <-c:
+			t.Fatal("unexpected event", e)
+		default:
+		}
+	}
+
+	// The first state of every module only serves as the baseline.
+	so := modules.StorageObligation{ObligationId: types.FileContractID{1}, OriginConfirmed: true, ObligationStatus: "obligationUnresolved"}
+	rc := modules.RenterContract{ID: types.FileContractID{2}, HostPublicKey: types.SiaPublicKey{Key: []byte{1}}}
+	fi := modules.FileInfo{SiaPath: modules.RandomSiaPath(), UploadProgress: 50, MaxHealthPercent: 100}
+	alert := modules.Alert{Module: "wallet", Msg: "locked", Severity: modules.SeverityWarning}
+	em.updateObligations([]modules.StorageObligation{so})
+	em.updateContracts([]modules.RenterContract{rc})
+	em.updateFiles([]modules.FileInfo{fi})
+	em.updateAlerts([]modules.Alert{alert})
+	expectNone()
+
+	// host
+	so2 := modules.StorageObligation{ObligationId: types.FileContractID{3}}
+	em.updateObligations([]modules.StorageObligation{so, so2})
+	expect(modules.EventHostContractFormed)
+	so.ProofConfirmed = true
+	em.updateObligations([]modules.StorageObligation{so, so2})
+	expect(modules.EventHostProofSubmitted)
+	so2.OriginConfirmed = true
+	so2.ObligationStatus = obligationFailed
+	em.updateObligations([]modules.StorageObligation{so, so2})
+	expect(modules.EventHostProofMissed)
+	expectNone()
+
+	// renter
+	rc2 := modules.RenterContract{ID: types.FileContractID{4}, HostPublicKey: rc.HostPublicKey}
+	em.updateContracts([]modules.RenterContract{rc2})
+	if data := expect(modules.EventRenterContractRenewed).Data.(modules.EventContractRenewedData); data.OldContractID != rc.ID || data.NewContractID != rc2.ID {
+		t.Fatal("unexpected renewal", data)
+	}
+	fi.UploadProgress = 100
+	em.updateFiles([]modules.FileInfo{fi})
+	expect(modules.EventRenterUploadComplete)
+	// Small drops add up.
+	fi.MaxHealthPercent = 95
+	em.updateFiles([]modules.FileInfo{fi})
+	expectNone()
+	fi.MaxHealthPercent = 90
+	em.updateFiles([]modules.FileInfo{fi})
+	expect(modules.EventRenterFileHealthDropped)
+	expectNone()
+
+	// alerts
+	alert2 := modules.Alert{Module: "renter", Msg: "low funds", Severity: modules.SeverityError}
+	em.updateAlerts([]modules.Alert{alert2})
+	expect(modules.EventAlertRegistered)
+	if e := expect(modules.EventAlertUnregistered); e.Data.(modules.Alert) != alert {
+		t.Fatal("unexpected alert", e.Data)
+	}
+}
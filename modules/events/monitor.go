@@ -0,0 +1,293 @@
+package events
+
+import (
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// ProcessConsensusChange publishes the block and reorg events of a consensus
+// change and triggers a poll of the other modules.
+func (em *EventManager) ProcessConsensusChange(cc modules.ConsensusChange) {
+	em.mu.Lock()
+	if len(cc.RevertedBlocks) > 0 {
+		depth := types.BlockHeight(len(cc.RevertedBlocks))
+		if depth > em.height {
+			depth = em.height
+		}
+		em.height -= depth
+		if em.walletHeight > em.height {
+			em.walletHeight = em.height
+		}
+		em.publish(modules.EventConsensusReorg, modules.EventReorgData{
+			Depth:      uint64(len(cc.RevertedBlocks)),
+			ForkHeight: em.height,
+		})
+	}
+	for _, b := range cc.AppliedBlocks {
+		em.height++
+		em.publish(modules.EventConsensusBlock, modules.EventBlockData{
+			BlockID: b.ID(),
+			Height:  em.height,
+		})
+	}
+	em.synced = cc.Synced
+	em.mu.Unlock()
+
+	select {
+	case em.consensusChanged <- struct{}{}:
+	default:
+	}
+}
+
+// threadedMonitor polls the modules for events after every consensus change
+// and at a regular interval. Polling the host and the renter means fetching
+// all of their storage obligations and files, so they are only polled at the
+// regular interval and not while the consensus set is syncing.
+func (em *EventManager) threadedMonitor() {
+	if err := em.tg.Add(); err != nil {
+		return
+	}
+	defer em.tg.Done()
+	var lastPoll time.Time
+	for {
+		em.managedPollWallet()
+		em.pollAlerts()
+		em.mu.Lock()
+		synced := em.synced
+		em.mu.Unlock()
+		if synced && time.Since(lastPoll) >= pollInterval {
+			em.pollHost()
+			em.pollRenter()
+			lastPoll = time.Now()
+		}
+
+		select {
+		case <-em.tg.StopChan():
+			return
+		case <-em.consensusChanged:
+		case <-time.After(pollInterval):
+		}
+	}
+}
+
+// managedPollWallet publishes the payments of the wallet that were confirmed
+// since the last poll.
+func (em *EventManager) managedPollWallet() {
+	if em.w == nil {
+		return
+	}
+	em.mu.Lock()
+	start, end := em.walletHeight+1, em.height
+	em.mu.Unlock()
+	if start > end {
+		return
+	}
+	pts, err := em.w.Transactions(start, end)
+	if err != nil {
+		// The wallet might not have processed the latest blocks yet, try
+		// again at the next poll.
+		em.log.Debugln("Unable to fetch wallet transactions:", err)
+		return
+	}
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	if em.walletHeight != start-1 {
+		// A reorg happened in the meantime, the transactions are checked
+		// again at the next poll.
+		return
+	}
+	for _, pt := range pts {
+		var incoming, outgoing types.Currency
+		for _, input := range pt.Inputs {
+			if input.FundType == types.SpecifierSiacoinInput && input.WalletAddress {
+				outgoing = outgoing.Add(input.Value)
+			}
+		}
+		for _, output := range pt.Outputs {
+			if (output.FundType == types.SpecifierSiacoinOutput || output.FundType == types.SpecifierMinerPayout) && output.WalletAddress {
+				incoming = incoming.Add(output.Value)
+			}
+		}
+		switch incoming.Cmp(outgoing) {
+		case 1:
+			em.publish(modules.EventWalletPaymentReceived, modules.EventPaymentData{
+				TransactionID: pt.TransactionID,
+				Height:        pt.ConfirmationHeight,
+				Value:         incoming.Sub(outgoing),
+			})
+		case -1:
+			em.publish(modules.EventWalletPaymentSent, modules.EventPaymentData{
+				TransactionID: pt.TransactionID,
+				Height:        pt.ConfirmationHeight,
+				Value:         outgoing.Sub(incoming),
+			})
+		}
+	}
+	em.walletHeight = end
+}
+
+// pollHost publishes the changes of the host's storage obligations.
+func (em *EventManager) pollHost() {
+	if em.h == nil {
+		return
+	}
+	em.updateObligations(em.h.StorageObligations())
+}
+
+// updateObligations publishes the changes of the host's storage obligations
+// since the last poll.
+func (em *EventManager) updateObligations(sos []modules.StorageObligation) {
+	obligations := make(map[types.FileContractID]modules.StorageObligation, len(sos))
+	for _, so := range sos {
+		obligations[so.ObligationId] = so
+	}
+	prevObligations := em.obligations
+	em.obligations = obligations
+	if prevObligations == nil {
+		return
+	}
+
+	for _, so := range sos {
+		prev, exists := prevObligations[so.ObligationId]
+		switch {
+		case !exists:
+			em.managedPublish(modules.EventHostContractFormed, modules.EventContractData{
+				ContractID: so.ObligationId,
+				Height:     so.NegotiationHeight,
+			})
+		case so.ProofConfirmed && !prev.ProofConfirmed:
+			em.managedPublish(modules.EventHostProofSubmitted, modules.EventContractData{
+				ContractID: so.ObligationId,
+				Height:     so.ProofDeadLine,
+			})
+		case so.ObligationStatus == obligationFailed && prev.ObligationStatus != obligationFailed && so.OriginConfirmed:
+			em.managedPublish(modules.EventHostProofMissed, modules.EventContractData{
+				ContractID: so.ObligationId,
+				Height:     so.ProofDeadLine,
+			})
+		}
+	}
+}
+
+// pollRenter publishes the renewed contracts and the upload and health
+// changes of the files of the renter.
+func (em *EventManager) pollRenter() {
+	if em.r == nil {
+		return
+	}
+	em.updateContracts(em.r.Contracts())
+	files, err := em.r.FileList(modules.RootSiaPath(), true, true)
+	if err != nil {
+		em.log.Debugln("Unable to fetch renter files:", err)
+		return
+	}
+	em.updateFiles(files)
+}
+
+// updateContracts publishes the contracts that were renewed since the last
+// poll. A contract counts as renewed if it replaced a contract with the same
+// host.
+func (em *EventManager) updateContracts(rcs []modules.RenterContract) {
+	contracts := make(map[types.FileContractID]modules.RenterContract, len(rcs))
+	for _, rc := range rcs {
+		contracts[rc.ID] = rc
+	}
+	prevContracts := em.contracts
+	em.contracts = contracts
+	if prevContracts == nil {
+		return
+	}
+
+	// Find the contracts that disappeared by host.
+	replaced := make(map[string]types.FileContractID)
+	for id, rc := range prevContracts {
+		if _, exists := contracts[id]; !exists {
+			replaced[rc.HostPublicKey.String()] = id
+		}
+	}
+	for _, rc := range rcs {
+		if _, exists := prevContracts[rc.ID]; exists {
+			continue
+		}
+		oldID, renewed := replaced[rc.HostPublicKey.String()]
+		if !renewed {
+			continue
+		}
+		em.managedPublish(modules.EventRenterContractRenewed, modules.EventContractRenewedData{
+			HostPublicKey: rc.HostPublicKey,
+			NewContractID: rc.ID,
+			OldContractID: oldID,
+		})
+	}
+}
+
+// updateFiles publishes the files that finished uploading or whose health
+// dropped since the last poll.
+func (em *EventManager) updateFiles(fis []modules.FileInfo) {
+	files := make(map[modules.SiaPath]fileState, len(fis))
+	prevFiles := em.files
+	for _, fi := range fis {
+		prev, exists := prevFiles[fi.SiaPath]
+		fs := fileState{
+			health:         fi.MaxHealthPercent,
+			uploadProgress: fi.UploadProgress,
+		}
+		data := modules.EventFileData{
+			SiaPath:          fi.SiaPath,
+			MaxHealthPercent: fi.MaxHealthPercent,
+			Redundancy:       fi.Redundancy,
+		}
+		switch {
+		case prevFiles == nil:
+		case fi.UploadProgress >= 100 && (!exists || prev.uploadProgress < 100):
+			em.managedPublish(modules.EventRenterUploadComplete, data)
+		case exists && prev.uploadProgress >= 100 && fi.MaxHealthPercent <= prev.health-minHealthDrop:
+			em.managedPublish(modules.EventRenterFileHealthDropped, data)
+		case exists && fi.MaxHealthPercent < prev.health:
+			// Small drops add up until they are large enough for an event.
+			fs.health = prev.health
+		}
+		files[fi.SiaPath] = fs
+	}
+	em.files = files
+}
+
+// pollAlerts publishes the alerts that were registered or unregistered since
+// the last poll.
+func (em *EventManager) pollAlerts() {
+	var alerts []modules.Alert
+	for _, a := range []modules.Alerter{em.g, em.cs, em.tpool, em.w, em.r, em.h} {
+		if a != nil {
+			alerts = append(alerts, a.Alerts()...)
+		}
+	}
+	em.updateAlerts(alerts)
+}
+
+// updateAlerts publishes the alerts that were registered or unregistered
+// since the last poll.
+func (em *EventManager) updateAlerts(as []modules.Alert) {
+	alerts := make(map[modules.Alert]struct{}, len(as))
+	for _, a := range as {
+		alerts[a] = struct{}{}
+	}
+	prevAlerts := em.alerts
+	em.alerts = alerts
+	if prevAlerts == nil {
+		return
+	}
+
+	for _, a := range as {
+		if _, exists := prevAlerts[a]; !exists {
+			em.managedPublish(modules.EventAlertRegistered, a)
+		}
+	}
+	for a := range prevAlerts {
+		if _, exists := alerts[a]; !exists {
+			em.managedPublish(modules.EventAlertUnregistered, a)
+		}
+	}
+}
@@ -0,0 +1,226 @@
+package events
+
+import (
+	"bytes"
+	"context"
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/persist"
+)
+
+var (
+	// errInvalidWebhookURL is returned when adding a webhook with a URL that
+	// isn't an absolute http or https URL.
+	errInvalidWebhookURL = errors.New("webhook URL must be an absolute http or https URL")
+
+	// errTooManyWebhooks is returned when adding a webhook while there are
+	// already maxWebhooks webhooks.
+	errTooManyWebhooks = errors.New("too many webhooks")
+
+	// errUnknownEventType is returned when adding a webhook for an event type
+	// that doesn't exist.
+	errUnknownEventType = errors.New("unknown event type")
+
+	// errWebhookQueueFull is the error of events that were dropped because
+	// too many events were queued for the webhook.
+	errWebhookQueueFull = errors.New("too many queued events")
+
+	// errWebhookNotFound is returned when removing a webhook that doesn't
+	// exist.
+	errWebhookNotFound = errors.New("webhook not found")
+)
+
+// save saves the webhooks to disk.
+func (em *EventManager) save() error {
+	data := webhooksPersist{
+		Webhooks: em.webhooks,
+	}
+	return persist.SaveJSON(webhooksMetadata, data, filepath.Join(em.persistDir, webhooksFilename))
+}
+
+// AddWebhook adds a webhook that receives the events of the given types, or
+// all events if no types are specified.
+func (em *EventManager) AddWebhook(rawURL string, events []modules.EventType, secret string) (modules.Webhook, error) {
+	if err := em.tg.Add(); err != nil {
+		return modules.Webhook{}, err
+	}
+	defer em.tg.Done()
+	u, err := url.Parse(rawURL)
+	if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+		return modules.Webhook{}, errInvalidWebhookURL
+	}
+	for _, et := range events {
+		if !et.IsValid() {
+			return modules.Webhook{}, errors.AddContext(errUnknownEventType, string(et))
+		}
+	}
+
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	if len(em.webhooks) >= maxWebhooks {
+		return modules.Webhook{}, errTooManyWebhooks
+	}
+	wh := modules.Webhook{
+		ID:     hex.EncodeToString(fastrand.Bytes(8)),
+		URL:    rawURL,
+		Events: append([]modules.EventType(nil), events...),
+		Secret: secret,
+	}
+	em.webhooks = append(em.webhooks, wh)
+	if err := em.save(); err != nil {
+		em.webhooks = em.webhooks[:len(em.webhooks)-1]
+		return modules.Webhook{}, err
+	}
+	em.startWebhook(wh)
+	wh.Secret = ""
+	return wh, nil
+}
+
+// RemoveWebhook removes the webhook with the given ID.
+func (em *EventManager) RemoveWebhook(id string) error {
+	if err := em.tg.Add(); err != nil {
+		return err
+	}
+	defer em.tg.Done()
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	for i, wh := range em.webhooks {
+		if wh.ID != id {
+			continue
+		}
+		em.webhooks = append(em.webhooks[:i:i], em.webhooks[i+1:]...)
+		close(em.webhookQueues[id])
+		delete(em.webhookQueues, id)
+		return em.save()
+	}
+	return errWebhookNotFound
+}
+
+// Webhooks returns the webhooks of the event manager without their secrets.
+func (em *EventManager) Webhooks() []modules.Webhook {
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	webhooks := make([]modules.Webhook, 0, len(em.webhooks))
+	for _, wh := range em.webhooks {
+		wh.Secret = ""
+		webhooks = append(webhooks, wh)
+	}
+	return webhooks
+}
+
+// startWebhook creates the queue of a webhook and starts the thread that
+// delivers its events.
+func (em *EventManager) startWebhook(wh modules.Webhook) {
+	queue := make(chan modules.Event, webhookQueueSize)
+	em.webhookQueues[wh.ID] = queue
+	go em.threadedDeliverQueue(wh, queue)
+}
+
+// threadedDeliverQueue posts the queued events to a webhook one at a time in
+// the order they were queued. It returns once the queue is closed or the event
+// manager is stopped.
+func (em *EventManager) threadedDeliverQueue(wh modules.Webhook, queue <-chan modules.Event) {
+	if err := em.tg.Add(); err != nil {
+		return
+	}
+	defer em.tg.Done()
+	for {
+		select {
+		case <-em.tg.StopChan():
+			return
+		case e, ok := <-queue:
+			if !ok {
+				return
+			}
+			em.managedDeliver(wh, e)
+		}
+	}
+}
+
+// managedDeliver posts an event to a webhook. Failed deliveries are retried
+// with an exponential backoff.
+func (em *EventManager) managedDeliver(wh modules.Webhook, e modules.Event) {
+	body, err := json.Marshal(e)
+	if err != nil {
+		em.log.Println("ERROR: unable to encode event:", err)
+		return
+	}
+
+	wait := webhookRetryInterval
+	for attempt := 1; ; attempt++ {
+		err = postEvent(wh, body, em.tg.StopChan())
+		if err == nil {
+			return
+		}
+		if attempt == webhookMaxAttempts {
+			break
+		}
+		select {
+		case <-em.tg.StopChan():
+			return
+		case <-time.After(wait):
+		}
+		wait *= 2
+	}
+
+	em.log.Printf("WARN: unable to deliver event %v to webhook %v: %v", e.ID, wh.ID, err)
+	em.mu.Lock()
+	defer em.mu.Unlock()
+	for i := range em.webhooks {
+		if em.webhooks[i].ID == wh.ID {
+			em.webhooks[i].FailedDeliveries++
+			em.webhooks[i].LastError = err.Error()
+			if err := em.save(); err != nil {
+				em.log.Println("ERROR: unable to save webhooks:", err)
+			}
+			return
+		}
+	}
+}
+
+// postEvent makes a single attempt to post an encoded event to a webhook. The
+// request is canceled when stop is closed.
+func postEvent(wh modules.Webhook, body []byte, stop <-chan struct{}) error {
+	ctx, cancel := context.WithTimeout(context.Background(), webhookTimeout)
+	defer cancel()
+	go func() {
+		select {
+		case <-stop:
+			cancel()
+		case <-ctx.Done():
+		}
+	}()
+	req, err := http.NewRequest("POST", wh.URL, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req = req.WithContext(ctx)
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("User-Agent", "Sia-Agent")
+	if wh.Secret != "" {
+		mac := hmac.New(sha256.New, []byte(wh.Secret))
+		mac.Write(body)
+		req.Header.Set(webhookSignatureHeader, hex.EncodeToString(mac.Sum(nil)))
+	}
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return err
+	}
+	resp.Body.Close()
+	if resp.StatusCode < 200 || resp.StatusCode >= 300 {
+		return fmt.Errorf("webhook responded with status %v", resp.Status)
+	}
+	return nil
+}
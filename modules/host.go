@@ -12,6 +12,16 @@ const (
 	HostDir = "host"
 )
 
+// The fields that storage obligations can be sorted by in a
+// StorageObligationQuery.
+const (
+	StorageObligationSortCollateral  = "collateral"
+	StorageObligationSortDataSize    = "datasize"
+	StorageObligationSortExpiration  = "expiration"
+	StorageObligationSortNegotiation = "negotiation"
+	StorageObligationSortRevenue     = "revenue"
+)
+
 var (
 	// BlockBytesPerMonthTerabyte is the conversion rate between block-bytes and month-TB.
 	BlockBytesPerMonthTerabyte = BytesPerTerabyte.Mul64(uint64(types.BlocksPerMonth))
@@ -82,6 +92,62 @@ type (
 		UploadBandwidthRevenue            types.Currency `json:"uploadbandwidthrevenue"`
 	}
 
+	// HostAutoPricingSettings configures the host's auto-pricing engine. When
+	// the engine is enabled, it periodically adjusts the host's prices based
+	// on the utilization of its storage, the saturation of its bandwidth and
+	// the prices of the other hosts on the network. Prices never leave the
+	// range set by the Min and Max fields. A Max value of 0 means that the
+	// price has no ceiling.
+	HostAutoPricingSettings struct {
+		Enabled bool `json:"enabled"`
+
+		// BandwidthCapacity is the number of bytes per second the host can
+		// serve. A value of 0 means that bandwidth saturation is ignored.
+		BandwidthCapacity uint64 `json:"bandwidthcapacity"`
+
+		MinBaseRPCPrice           types.Currency `json:"minbaserpcprice"`
+		MaxBaseRPCPrice           types.Currency `json:"maxbaserpcprice"`
+		MinDownloadBandwidthPrice types.Currency `json:"mindownloadbandwidthprice"`
+		MaxDownloadBandwidthPrice types.Currency `json:"maxdownloadbandwidthprice"`
+		MinSectorAccessPrice      types.Currency `json:"minsectoraccessprice"`
+		MaxSectorAccessPrice      types.Currency `json:"maxsectoraccessprice"`
+		MinStoragePrice           types.Currency `json:"minstorageprice"`
+		MaxStoragePrice           types.Currency `json:"maxstorageprice"`
+		MinUploadBandwidthPrice   types.Currency `json:"minuploadbandwidthprice"`
+		MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+	}
+
+	// HostMarket provides the settings of the other hosts on the network. It
+	// is used by the host's auto-pricing engine.
+	HostMarket interface {
+		// ActiveHosts returns the hosts that are announced on the network
+		// and online.
+		ActiveHosts() ([]HostDBEntry, error)
+	}
+
+	// HostCollateralForecast projects the wallet funds that the host needs
+	// between BlockHeight and EndHeight to renew the storage obligations that
+	// expire in that period and to pay the fees of their revisions and
+	// storage proofs. Renewals are assumed to lock the same collateral as the
+	// expiring obligation. Payouts are the host payouts that mature in the
+	// period. Projected is the balance plus the payouts minus the need, or
+	// the Shortfall if the need is larger. A Window of 0 means that the
+	// planner is disabled or that no forecast was made yet.
+	HostCollateralForecast struct {
+		Window      types.BlockHeight `json:"window"`
+		BlockHeight types.BlockHeight `json:"blockheight"`
+		EndHeight   types.BlockHeight `json:"endheight"`
+		Timestamp   time.Time         `json:"timestamp"`
+
+		Balance           types.Currency `json:"balance"`
+		Payouts           types.Currency `json:"payouts"`
+		RenewalCollateral types.Currency `json:"renewalcollateral"`
+		TransactionFees   types.Currency `json:"transactionfees"`
+		Need              types.Currency `json:"need"`
+		Projected         types.Currency `json:"projected"`
+		Shortfall         types.Currency `json:"shortfall"`
+	}
+
 	// HostInternalSettings contains a list of settings that can be changed.
 	HostInternalSettings struct {
 		AcceptingContracts   bool              `json:"acceptingcontracts"`
@@ -91,10 +157,28 @@ type (
 		NetAddress           NetAddress        `json:"netaddress"`
 		WindowSize           types.BlockHeight `json:"windowsize"`
 
+		// AlternateNetAddresses are announced alongside the NetAddress, e.g.
+		// the IPv6 address of a dual-stack host or the addresses of other
+		// uplinks. Renters try them if the NetAddress is unreachable.
+		AlternateNetAddresses []NetAddress `json:"alternatenetaddresses"`
+
+		// WebSocketAddress is the address on which the host serves the
+		// renter-host protocol over WebSocket, e.g. ":9984". An empty address
+		// disables the WebSocket listener. If a TLS certificate and key are
+		// provided, the listener only accepts TLS connections.
+		WebSocketAddress     string `json:"websocketaddress"`
+		WebSocketTLSCertFile string `json:"websockettlscertfile"`
+		WebSocketTLSKeyFile  string `json:"websockettlskeyfile"`
+
 		Collateral       types.Currency `json:"collateral"`
 		CollateralBudget types.Currency `json:"collateralbudget"`
 		MaxCollateral    types.Currency `json:"maxcollateral"`
 
+		// CollateralForecastWindow is the number of blocks over which the
+		// collateral planner projects the wallet funds needed by the
+		// host's storage obligations. A window of 0 disables the planner.
+		CollateralForecastWindow types.BlockHeight `json:"collateralforecastwindow"`
+
 		MinBaseRPCPrice           types.Currency `json:"minbaserpcprice"`
 		MinContractPrice          types.Currency `json:"mincontractprice"`
 		MinDownloadBandwidthPrice types.Currency `json:"mindownloadbandwidthprice"`
@@ -105,6 +189,61 @@ type (
 		EphemeralAccountExpiry     uint64         `json:"ephemeralaccountexpiry"`
 		MaxEphemeralAccountBalance types.Currency `json:"maxephemeralaccountbalance"`
 		MaxEphemeralAccountRisk    types.Currency `json:"maxephemeralaccountrisk"`
+
+		// SectorCacheSize is the size of the cache of recently read sectors
+		// in bytes. If SectorCacheDir is set, the cached sectors are stored in
+		// that directory instead of memory.
+		SectorCacheSize uint64 `json:"sectorcachesize"`
+		SectorCacheDir  string `json:"sectorcachedir"`
+
+		// The per-client limits apply separately to every renter, identified
+		// by its public key, and to every IP address. The bandwidth limit is
+		// in bytes per second in each direction, the RPC limits are in RPCs
+		// per second. A limit of 0 disables it.
+		ClientBandwidthLimit   uint64 `json:"clientbandwidthlimit"`
+		ClientReadRPCLimit     uint64 `json:"clientreadrpclimit"`
+		ClientSettingsRPCLimit uint64 `json:"clientsettingsrpclimit"`
+		ClientWriteRPCLimit    uint64 `json:"clientwriterpclimit"`
+
+		// AutoPricing configures the auto-pricing engine. While the engine is
+		// enabled, the host charges the prices computed by the engine as long
+		// as they are higher than the Min prices above.
+		AutoPricing HostAutoPricingSettings `json:"autopricing"`
+
+		// WalletAccount is the wallet account that funds the collateral,
+		// announcements and storage proofs of the host and receives its
+		// payouts. An empty name selects the primary account.
+		WalletAccount string `json:"walletaccount"`
+	}
+
+	// HostClient reports the resources consumed by a client of the host. A
+	// client is either a renter, identified by its public key, or an IP
+	// address. Download and upload are from the perspective of the client.
+	HostClient struct {
+		ID           string    `json:"id"`
+		Type         string    `json:"type"`
+		Connections  uint64    `json:"connections"`
+		Download     uint64    `json:"download"`
+		Upload       uint64    `json:"upload"`
+		ReadRPCs     uint64    `json:"readrpcs"`
+		SettingsRPCs uint64    `json:"settingsrpcs"`
+		WriteRPCs    uint64    `json:"writerpcs"`
+		RejectedRPCs uint64    `json:"rejectedrpcs"`
+		LastSeen     time.Time `json:"lastseen"`
+	}
+
+	// HostMaintenanceStatus reports whether the host is in maintenance mode.
+	// While in maintenance mode the host refuses new contracts, renewals and
+	// writes but keeps serving reads and submitting storage proofs. The
+	// obligations are the unresolved storage obligations whose proof windows
+	// overlap with the planned downtime.
+	HostMaintenanceStatus struct {
+		Active      bool                `json:"active"`
+		StartTime   time.Time           `json:"starttime"`
+		EndTime     time.Time           `json:"endtime"`
+		BlockHeight types.BlockHeight   `json:"blockheight"`
+		EndHeight   types.BlockHeight   `json:"endheight"`
+		Obligations []StorageObligation `json:"obligations"`
 	}
 
 	// HostNetworkMetrics reports the quantity of each type of RPC call that
@@ -129,6 +268,7 @@ type (
 		PotentialDownloadRevenue types.Currency       `json:"potentialdownloadrevenue"`
 		PotentialStorageRevenue  types.Currency       `json:"potentialstoragerevenue"`
 		PotentialUploadRevenue   types.Currency       `json:"potentialuploadrevenue"`
+		RenterPublicKey          types.SiaPublicKey   `json:"renterpublickey"`
 		RiskedCollateral         types.Currency       `json:"riskedcollateral"`
 		SectorRootsCount         uint64               `json:"sectorrootscount"`
 		TransactionFeesAdded     types.Currency       `json:"transactionfeesadded"`
@@ -152,6 +292,62 @@ type (
 		RevisionConstructed bool   `json:"revisionconstructed"`
 	}
 
+	// StorageObligationDetail contains the information about a storage
+	// obligation together with its revision history and the IDs of the
+	// transactions containing the storage proofs submitted by the host.
+	StorageObligationDetail struct {
+		StorageObligation
+		Revisions           []StorageObligationRevision `json:"revisions"`
+		ProofTransactionIDs []types.TransactionID       `json:"prooftransactionids"`
+	}
+
+	// StorageObligationPeriod aggregates the unresolved storage obligations
+	// that expire between StartHeight and EndHeight, which is useful for
+	// capacity planning.
+	StorageObligationPeriod struct {
+		StartHeight      types.BlockHeight `json:"startheight"`
+		EndHeight        types.BlockHeight `json:"endheight"`
+		Expirations      uint64            `json:"expirations"`
+		DataSize         uint64            `json:"datasize"`
+		LockedCollateral types.Currency    `json:"lockedcollateral"`
+		RiskedCollateral types.Currency    `json:"riskedcollateral"`
+		PotentialRevenue types.Currency    `json:"potentialrevenue"`
+	}
+
+	// StorageObligationQuery selects, orders and pages storage obligations.
+	// Zero values disable a filter. The status is the obligation status with
+	// or without the "obligation" prefix. The revenue is the sum of the
+	// potential download, upload and storage revenue and the collateral is
+	// the locked collateral. SortBy is one of the StorageObligationSort
+	// constants, obligations are sorted by expiration height by default. A
+	// Limit of 0 returns all of the obligations after the Offset.
+	StorageObligationQuery struct {
+		Status          string
+		RenterPublicKey types.SiaPublicKey
+		MinExpiration   types.BlockHeight
+		MaxExpiration   types.BlockHeight
+		MinRevenue      types.Currency
+		MinCollateral   types.Currency
+
+		SortBy     string
+		Descending bool
+		Offset     uint64
+		Limit      uint64
+	}
+
+	// StorageObligationRevision describes a revision of the file contract
+	// that governs a storage obligation. The payouts are the valid proof
+	// outputs of the revision.
+	StorageObligationRevision struct {
+		RevisionNumber uint64              `json:"revisionnumber"`
+		BlockHeight    types.BlockHeight   `json:"blockheight"`
+		TransactionID  types.TransactionID `json:"transactionid"`
+		FileSize       uint64              `json:"filesize"`
+		FileMerkleRoot crypto.Hash         `json:"filemerkleroot"`
+		RenterPayout   types.Currency      `json:"renterpayout"`
+		HostPayout     types.Currency      `json:"hostpayout"`
+	}
+
 	// HostWorkingStatus reports the working state of a host. Can be one of
 	// "checking", "working", or "not working".
 	HostWorkingStatus string
@@ -195,9 +391,21 @@ type (
 		// AnnounceAddress submits an announcement using the given address.
 		AnnounceAddress(NetAddress) error
 
+		// AnnounceAddresses submits an announcement using the given addresses,
+		// the first of which is the primary address of the host.
+		AnnounceAddresses([]NetAddress) error
+
+		// Clients returns the resources consumed by the renters and IP
+		// addresses that recently connected to the host.
+		Clients() []HostClient
+
 		// The host needs to be able to shut down.
 		Close() error
 
+		// CollateralForecast returns the latest projection of the wallet
+		// funds needed by the host's storage obligations.
+		CollateralForecast() HostCollateralForecast
+
 		// ConnectabilityStatus returns the connectability status of the host,
 		// that is, if it can connect to itself on the configured NetAddress.
 		ConnectabilityStatus() HostConnectabilityStatus
@@ -224,6 +432,16 @@ type (
 		// potentially private or sensitive information.
 		InternalSettings() HostInternalSettings
 
+		// MaintenanceStatus returns whether the host is in maintenance mode
+		// and which storage obligations have a proof window during the
+		// planned downtime.
+		MaintenanceStatus() (HostMaintenanceStatus, error)
+
+		// MigrateSectors moves numSectors sectors from the source storage
+		// folder to the destination storage folder in the background, or all
+		// of the sectors in the source if numSectors is 0.
+		MigrateSectors(source, destination uint16, numSectors uint64) error
+
 		// NetworkMetrics returns information on the types of RPC calls that
 		// have been made to the host.
 		NetworkMetrics() HostNetworkMetrics
@@ -238,10 +456,20 @@ type (
 		// PublicKey returns the public key of the host.
 		PublicKey() types.SiaPublicKey
 
+		// QueryStorageObligations returns the page of storage obligations
+		// that match the query, along with the total number of matching
+		// obligations.
+		QueryStorageObligations(query StorageObligationQuery) ([]StorageObligation, uint64, error)
+
 		// ReadSector will read a sector from the host, returning the bytes that
 		// match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
 
+		// RebalanceStorageFolders moves sectors between the storage folders
+		// of the host in the background until all of them have about the
+		// same utilization.
+		RebalanceStorageFolders() error
+
 		// RemoveSector will remove a sector from the host. The height at which
 		// the sector expires should be provided, so that the auto-expiry
 		// information for that sector can be properly updated.
@@ -275,13 +503,42 @@ type (
 		// and the resize operation completed, meaning that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// ScrubStatus returns the progress of the background scrubber that
+		// verifies the sectors stored by the host.
+		ScrubStatus() StorageScrubStatus
+
+		// SectorCacheStatus returns the size and the hit and miss counters of
+		// the host's sector cache.
+		SectorCacheStatus() SectorCacheStatus
+
 		// SetInternalSettings sets the hosting parameters of the host.
 		SetInternalSettings(HostInternalSettings) error
 
+		// SetMarket sets the source of the prices of other hosts which is
+		// used by the auto-pricing engine.
+		SetMarket(HostMarket)
+
+		// StartMaintenance puts the host into maintenance mode for the
+		// planned duration. New contracts, renewals and writes are refused
+		// until StopMaintenance is called.
+		StartMaintenance(duration time.Duration) error
+
+		// StopMaintenance takes the host out of maintenance mode.
+		StopMaintenance() error
+
+		// StorageObligation returns the storage obligation with the provided
+		// id, including its revision history.
+		StorageObligation(id types.FileContractID) (StorageObligationDetail, error)
+
 		// StorageObligations returns the set of storage obligations held by
 		// the host.
 		StorageObligations() []StorageObligation
 
+		// StorageObligationsSummary aggregates the unresolved storage
+		// obligations that match the query by the week in which they expire.
+		// The sorting and paging of the query are ignored.
+		StorageObligationsSummary(query StorageObligationQuery) ([]StorageObligationPeriod, error)
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// host.
 		StorageFolders() []StorageFolderMetadata
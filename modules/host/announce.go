@@ -14,6 +14,10 @@ var (
 	// errAnnWalletLocked is returned during a host announcement if the wallet
 	// is locked.
 	errAnnWalletLocked = errors.New("cannot announce the host while the wallet is locked")
+
+	// errAnnNoAddresses is returned if an announcement is requested without
+	// any addresses.
+	errAnnNoAddresses = errors.New("cannot announce the host without an address")
 )
 
 // differentTypeIPs is a helper that returns true if two IPs are of a different
@@ -49,10 +53,39 @@ func (h *Host) staticVerifyAnnouncementAddress(addr modules.NetAddress) error {
 	return nil
 }
 
-// managedAnnounce creates an announcement transaction and submits it to the network.
-func (h *Host) managedAnnounce(addr modules.NetAddress) (err error) {
-	// Verify address first.
-	if err := h.staticVerifyAnnouncementAddress(addr); err != nil {
+// verifyAlternateAddresses checks that the alternate addresses of the host are
+// valid and can be announced together with its primary address.
+func verifyAlternateAddresses(primary modules.NetAddress, alternates []modules.NetAddress) error {
+	if len(alternates)+1 > modules.MaxAnnouncementAddresses {
+		return modules.ErrAnnTooManyAddresses
+	}
+	seen := map[modules.NetAddress]struct{}{primary: {}}
+	for _, addr := range alternates {
+		if err := addr.IsValid(); err != nil {
+			return errors.AddContext(err, fmt.Sprintf("invalid alternate address %v", addr))
+		}
+		if _, exists := seen[addr]; exists {
+			return modules.ErrAnnDuplicateAddress
+		}
+		seen[addr] = struct{}{}
+	}
+	return nil
+}
+
+// managedAnnounce creates an announcement transaction for the provided
+// addresses and submits it to the network. The first address is the primary
+// address of the host.
+func (h *Host) managedAnnounce(addrs []modules.NetAddress) (err error) {
+	// Verify the addresses first.
+	if len(addrs) == 0 {
+		return errAnnNoAddresses
+	}
+	for _, addr := range addrs {
+		if err := h.staticVerifyAnnouncementAddress(addr); err != nil {
+			return err
+		}
+	}
+	if err := verifyAlternateAddresses(addrs[0], addrs[1:]); err != nil {
 		return err
 	}
 
@@ -70,6 +103,7 @@ func (h *Host) managedAnnounce(addr modules.NetAddress) (err error) {
 	h.mu.Lock()
 	pubKey := h.publicKey
 	secKey := h.secretKey
+	account := h.settings.WalletAccount
 	err = h.checkUnlockHash()
 	h.mu.Unlock()
 	if err != nil {
@@ -78,13 +112,13 @@ func (h *Host) managedAnnounce(addr modules.NetAddress) (err error) {
 
 	// Create the announcement that's going to be added to the arbitrary data
 	// field of the transaction.
-	signedAnnouncement, err := modules.CreateAnnouncement(addr, pubKey, secKey)
+	signedAnnouncement, err := modules.CreateMultiAddressAnnouncement(addrs, pubKey, secKey)
 	if err != nil {
 		return err
 	}
 
 	// Create a transaction, with a fee, that contains the full announcement.
-	txnBuilder, err := h.wallet.StartTransaction()
+	txnBuilder, err := h.wallet.StartAccountTransaction(account)
 	if err != nil {
 		return err
 	}
@@ -94,7 +128,9 @@ func (h *Host) managedAnnounce(addr modules.NetAddress) (err error) {
 		}
 	}()
 	_, fee := h.tpool.FeeEstimation()
-	fee = fee.Mul64(600) // Estimated txn size (in bytes) of a host announcement.
+	// Estimated txn size (in bytes) of a host announcement, plus the size of
+	// the alternate addresses.
+	fee = fee.Mul64(600 + 100*uint64(len(addrs)-1))
 	err = txnBuilder.FundSiacoins(fee)
 	if err != nil {
 		return err
@@ -115,7 +151,7 @@ func (h *Host) managedAnnounce(addr modules.NetAddress) (err error) {
 	h.mu.Lock()
 	h.announced = true
 	h.mu.Unlock()
-	h.log.Printf("INFO: Successfully announced as %v", addr)
+	h.log.Printf("INFO: Successfully announced as %v", addrs)
 	return nil
 }
 
@@ -132,6 +168,7 @@ func (h *Host) Announce() error {
 	h.mu.RLock()
 	userSet := h.settings.NetAddress
 	autoSet := h.autoAddress
+	alternates := append([]modules.NetAddress(nil), h.settings.AlternateNetAddresses...)
 	h.mu.RUnlock()
 
 	// Check that we have at least one address to work with.
@@ -147,14 +184,23 @@ func (h *Host) Announce() error {
 		annAddr = autoSet
 	}
 
-	// Address has cleared inspection, perform the announcement.
-	return h.managedAnnounce(annAddr)
+	// Address has cleared inspection, perform the announcement together with
+	// the alternate addresses.
+	return h.managedAnnounce(append([]modules.NetAddress{annAddr}, alternates...))
 }
 
 // AnnounceAddress submits a host announcement to the blockchain to announce a
 // specific address. If there is no error, the host's address will be updated
-// to the supplied address.
+// to the supplied address and its alternate addresses will be cleared.
 func (h *Host) AnnounceAddress(addr modules.NetAddress) error {
+	return h.AnnounceAddresses([]modules.NetAddress{addr})
+}
+
+// AnnounceAddresses submits a host announcement to the blockchain to announce
+// specific addresses, the first of which is the primary address of the host.
+// If there is no error, the host's address and alternate addresses will be
+// updated to the supplied addresses.
+func (h *Host) AnnounceAddresses(addrs []modules.NetAddress) error {
 	err := h.tg.Add()
 	if err != nil {
 		return err
@@ -162,15 +208,30 @@ func (h *Host) AnnounceAddress(addr modules.NetAddress) error {
 	defer h.tg.Done()
 
 	// Attempt the actual announcement.
-	err = h.managedAnnounce(addr)
+	err = h.managedAnnounce(addrs)
 	if err != nil {
 		return build.ExtendErr("unable to perform manual host announcement", err)
 	}
 
-	// Address is valid, update the host's internal net address to match the
-	// specified addr.
+	// Addresses are valid, update the host's internal net addresses to match
+	// the specified addrs.
 	h.mu.Lock()
-	h.settings.NetAddress = addr
+	h.settings.NetAddress = addrs[0]
+	h.settings.AlternateNetAddresses = append([]modules.NetAddress(nil), addrs[1:]...)
 	h.mu.Unlock()
 	return nil
 }
+
+// equalNetAddresses returns true if both slices contain the same addresses in
+// the same order.
+func equalNetAddresses(a, b []modules.NetAddress) bool {
+	if len(a) != len(b) {
+		return false
+	}
+	for i := range a {
+		if a[i] != b[i] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,302 @@
+package host
+
+// autopricing.go contains the auto-pricing engine of the host. When enabled,
+// the engine periodically recomputes the host's prices. Every price starts out
+// at the median price of the other active hosts on the network, or at the
+// operator's floor if the market is unknown. The storage price is then scaled
+// by the utilization of the host's storage folders and the bandwidth related
+// prices are scaled by the saturation of the host's bandwidth. The result is
+// clamped to the floor and ceiling set by the operator.
+
+import (
+	"fmt"
+	"math/big"
+	"sort"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errAutoPricingCeiling is returned if an auto-pricing ceiling is lower
+	// than the corresponding floor.
+	errAutoPricingCeiling = errors.New("auto-pricing ceiling must be 0 or greater than or equal to the floor")
+)
+
+// autoPriceChange describes a single price change made by the auto-pricing
+// engine.
+type autoPriceChange struct {
+	name   string
+	old    types.Currency
+	new    types.Currency
+	reason string
+}
+
+// hostPrices are the prices charged by the host that the auto-pricing engine
+// adjusts.
+type hostPrices struct {
+	BaseRPCPrice           types.Currency `json:"baserpcprice"`
+	DownloadBandwidthPrice types.Currency `json:"downloadbandwidthprice"`
+	SectorAccessPrice      types.Currency `json:"sectoraccessprice"`
+	StoragePrice           types.Currency `json:"storageprice"`
+	UploadBandwidthPrice   types.Currency `json:"uploadbandwidthprice"`
+}
+
+// higherPrice returns the higher of the two prices.
+func higherPrice(a, b types.Currency) types.Currency {
+	if a.Cmp(b) < 0 {
+		return b
+	}
+	return a
+}
+
+// prices returns the prices charged by the host. These are the operator's min
+// prices, raised to the prices computed by the auto-pricing engine while the
+// engine is enabled.
+func (h *Host) prices() hostPrices {
+	p := hostPrices{
+		BaseRPCPrice:           h.settings.MinBaseRPCPrice,
+		DownloadBandwidthPrice: h.settings.MinDownloadBandwidthPrice,
+		SectorAccessPrice:      h.settings.MinSectorAccessPrice,
+		StoragePrice:           h.settings.MinStoragePrice,
+		UploadBandwidthPrice:   h.settings.MinUploadBandwidthPrice,
+	}
+	if !h.settings.AutoPricing.Enabled {
+		return p
+	}
+	p.BaseRPCPrice = higherPrice(p.BaseRPCPrice, h.autoPrices.BaseRPCPrice)
+	p.DownloadBandwidthPrice = higherPrice(p.DownloadBandwidthPrice, h.autoPrices.DownloadBandwidthPrice)
+	p.SectorAccessPrice = higherPrice(p.SectorAccessPrice, h.autoPrices.SectorAccessPrice)
+	p.StoragePrice = higherPrice(p.StoragePrice, h.autoPrices.StoragePrice)
+	p.UploadBandwidthPrice = higherPrice(p.UploadBandwidthPrice, h.autoPrices.UploadBandwidthPrice)
+	return p
+}
+
+// validateAutoPricingSettings checks that none of the ceilings is lower than
+// its floor.
+func validateAutoPricingSettings(aps modules.HostAutoPricingSettings) error {
+	bounds := [][2]types.Currency{
+		{aps.MinBaseRPCPrice, aps.MaxBaseRPCPrice},
+		{aps.MinDownloadBandwidthPrice, aps.MaxDownloadBandwidthPrice},
+		{aps.MinSectorAccessPrice, aps.MaxSectorAccessPrice},
+		{aps.MinStoragePrice, aps.MaxStoragePrice},
+		{aps.MinUploadBandwidthPrice, aps.MaxUploadBandwidthPrice},
+	}
+	for _, b := range bounds {
+		if !b[1].IsZero() && b[1].Cmp(b[0]) < 0 {
+			return errAutoPricingCeiling
+		}
+	}
+	return nil
+}
+
+// medianPrice returns the median of the provided prices. The slice is sorted
+// in place.
+func medianPrice(prices []types.Currency) types.Currency {
+	if len(prices) == 0 {
+		return types.ZeroCurrency
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	return prices[len(prices)/2]
+}
+
+// autoPrice computes a new price. The price is based on the market median if
+// it is known and on the floor otherwise. The base is scaled by the
+// multiplier and clamped to the floor and ceiling. A zero ceiling means that
+// the price is unbounded.
+func autoPrice(floor, ceiling, median types.Currency, multiplier float64) types.Currency {
+	base := median
+	if base.IsZero() {
+		base = floor
+	}
+	price := base.MulFloat(multiplier)
+	if price.Cmp(floor) < 0 {
+		price = floor
+	}
+	if !ceiling.IsZero() && price.Cmp(ceiling) > 0 {
+		price = ceiling
+	}
+	return price
+}
+
+// significantPriceChange returns true if the new price differs from the old
+// price by more than autoPricingMinChange. Small changes are ignored to avoid
+// churning the host's settings.
+func significantPriceChange(old, new types.Currency) bool {
+	if old.IsZero() {
+		return !new.IsZero()
+	}
+	var diff types.Currency
+	if new.Cmp(old) > 0 {
+		diff = new.Sub(old)
+	} else {
+		diff = old.Sub(new)
+	}
+	return diff.Cmp(old.MulRat(big.NewRat(autoPricingMinChange, 100))) > 0
+}
+
+// SetMarket sets the source of the other hosts' prices which is used by the
+// auto-pricing engine.
+func (h *Host) SetMarket(m modules.HostMarket) {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.market = m
+}
+
+// managedMarketMedians returns the median prices of the other active hosts on
+// the network. Zero prices are returned if the market is unknown.
+func (h *Host) managedMarketMedians() (medians modules.HostExternalSettings, hosts int) {
+	h.mu.RLock()
+	market := h.market
+	pk := h.publicKey
+	h.mu.RUnlock()
+	if market == nil {
+		return
+	}
+	entries, err := market.ActiveHosts()
+	if err != nil {
+		h.log.Debugln("Unable to fetch active hosts for auto-pricing:", err)
+		return
+	}
+	var baseRPC, download, sectorAccess, storage, upload []types.Currency
+	for _, entry := range entries {
+		if entry.PublicKey.Equals(pk) {
+			continue
+		}
+		baseRPC = append(baseRPC, entry.BaseRPCPrice)
+		download = append(download, entry.DownloadBandwidthPrice)
+		sectorAccess = append(sectorAccess, entry.SectorAccessPrice)
+		storage = append(storage, entry.StoragePrice)
+		upload = append(upload, entry.UploadBandwidthPrice)
+	}
+	if len(storage) < autoPricingMinMarketHosts {
+		return modules.HostExternalSettings{}, len(storage)
+	}
+	medians.BaseRPCPrice = medianPrice(baseRPC)
+	medians.DownloadBandwidthPrice = medianPrice(download)
+	medians.SectorAccessPrice = medianPrice(sectorAccess)
+	medians.StoragePrice = medianPrice(storage)
+	medians.UploadBandwidthPrice = medianPrice(upload)
+	return medians, len(storage)
+}
+
+// managedAutoPrice recomputes the host's prices from the storage
+// utilization, the bandwidth saturation and the market prices. The prices are
+// never lower than the operator's min prices. The changed prices are stored
+// apart from the host's settings and the price table is refreshed.
+func (h *Host) managedAutoPrice(saturation float64) {
+	// Skip fetching the market prices if auto-pricing is disabled.
+	h.mu.RLock()
+	enabled := h.settings.AutoPricing.Enabled
+	h.mu.RUnlock()
+	if !enabled {
+		return
+	}
+	medians, numHosts := h.managedMarketMedians()
+
+	h.mu.Lock()
+	aps := h.settings.AutoPricing
+	if !aps.Enabled {
+		h.mu.Unlock()
+		return
+	}
+	total, remaining := h.capacity()
+	utilization := 0.0
+	if total > 0 && remaining <= total {
+		utilization = 1 - float64(remaining)/float64(total)
+	}
+	storageMultiplier := 0.5 + utilization
+	bandwidthMultiplier := 1.0
+	if aps.BandwidthCapacity > 0 {
+		bandwidthMultiplier = 0.5 + saturation
+	}
+	market := "floor"
+	if !medians.StoragePrice.IsZero() {
+		market = fmt.Sprintf("median of %v hosts", numHosts)
+	}
+	storageReason := fmt.Sprintf("storage utilization %.1f%%, %v", utilization*100, market)
+	bandwidthReason := fmt.Sprintf("bandwidth saturation %.1f%%, %v", saturation*100, market)
+
+	// Compute the new prices.
+	settings := h.settings
+	prices := &h.autoPrices
+	candidates := []struct {
+		name   string
+		price  *types.Currency
+		new    types.Currency
+		reason string
+	}{
+		{"base RPC price", &prices.BaseRPCPrice, higherPrice(autoPrice(aps.MinBaseRPCPrice, aps.MaxBaseRPCPrice, medians.BaseRPCPrice, bandwidthMultiplier), settings.MinBaseRPCPrice), bandwidthReason},
+		{"download bandwidth price", &prices.DownloadBandwidthPrice, higherPrice(autoPrice(aps.MinDownloadBandwidthPrice, aps.MaxDownloadBandwidthPrice, medians.DownloadBandwidthPrice, bandwidthMultiplier), settings.MinDownloadBandwidthPrice), bandwidthReason},
+		{"sector access price", &prices.SectorAccessPrice, higherPrice(autoPrice(aps.MinSectorAccessPrice, aps.MaxSectorAccessPrice, medians.SectorAccessPrice, bandwidthMultiplier), settings.MinSectorAccessPrice), bandwidthReason},
+		{"storage price", &prices.StoragePrice, higherPrice(autoPrice(aps.MinStoragePrice, aps.MaxStoragePrice, medians.StoragePrice, storageMultiplier), settings.MinStoragePrice), storageReason},
+		{"upload bandwidth price", &prices.UploadBandwidthPrice, higherPrice(autoPrice(aps.MinUploadBandwidthPrice, aps.MaxUploadBandwidthPrice, medians.UploadBandwidthPrice, bandwidthMultiplier), settings.MinUploadBandwidthPrice), bandwidthReason},
+	}
+	var changes []autoPriceChange
+	for _, c := range candidates {
+		if !significantPriceChange(*c.price, c.new) {
+			continue
+		}
+		changes = append(changes, autoPriceChange{name: c.name, old: *c.price, new: c.new, reason: c.reason})
+		*c.price = c.new
+	}
+	if len(changes) == 0 {
+		h.mu.Unlock()
+		return
+	}
+	h.revisionNumber++
+	err := h.saveSync()
+	h.mu.Unlock()
+	if err != nil {
+		h.log.Println("ERROR: unable to save auto-pricing changes:", err)
+	}
+	for _, c := range changes {
+		h.log.Printf("Auto-pricing changed %v from %v to %v (%v)", c.name, c.old.HumanString(), c.new.HumanString(), c.reason)
+	}
+
+	// Refresh the price table to reflect the new prices.
+	h.managedUpdatePriceTable()
+}
+
+// threadedAutoPricing periodically runs the auto-pricing engine. The thread
+// group is only held while the prices are updated to not block calls to
+// Flush.
+func (h *Host) threadedAutoPricing() {
+	lastRead, lastWrite := h.staticMonitor.Counts()
+	lastCheck := time.Now()
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(autoPricingInterval):
+		}
+
+		// Compute the bandwidth saturation since the last check.
+		read, write := h.staticMonitor.Counts()
+		now := time.Now()
+		h.mu.RLock()
+		capacity := h.settings.AutoPricing.BandwidthCapacity
+		h.mu.RUnlock()
+		saturation := 0.0
+		elapsed := now.Sub(lastCheck).Seconds()
+		if capacity > 0 && elapsed > 0 && read >= lastRead && write >= lastWrite {
+			rate := float64(read-lastRead+write-lastWrite) / elapsed
+			saturation = rate / float64(capacity)
+			if saturation > 1 {
+				saturation = 1
+			}
+		}
+		lastRead, lastWrite, lastCheck = read, write, now
+
+		if err := h.tg.Add(); err != nil {
+			return
+		}
+		h.managedAutoPrice(saturation)
+		h.tg.Done()
+	}
+}
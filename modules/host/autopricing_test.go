@@ -0,0 +1,164 @@
+package host
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// testMarket is a HostMarket with a static set of hosts.
+type testMarket []modules.HostDBEntry
+
+// ActiveHosts implements the HostMarket interface.
+func (tm testMarket) ActiveHosts() ([]modules.HostDBEntry, error) {
+	return tm, nil
+}
+
+// TestAutoPrice checks the price computation of the auto-pricing engine.
+func TestAutoPrice(t *testing.T) {
+	floor := types.NewCurrency64(100)
+	ceiling := types.NewCurrency64(1000)
+	tests := []struct {
+		median     uint64
+		multiplier float64
+		ceiling    types.Currency
+		expected   uint64
+	}{
+		{0, 1, ceiling, 100},                 // no market, use floor
+		{0, 1.5, ceiling, 150},               // no market, scaled floor
+		{400, 1, ceiling, 400},               // market median
+		{400, 0.5, ceiling, 200},             // scaled down median
+		{150, 0.5, ceiling, 100},             // clamped to floor
+		{800, 1.5, ceiling, 1000},            // clamped to ceiling
+		{800, 1.5, types.ZeroCurrency, 1200}, // no ceiling
+	}
+	for i, test := range tests {
+		price := autoPrice(floor, test.ceiling, types.NewCurrency64(test.median), test.multiplier)
+		if !price.Equals64(test.expected) {
+			t.Errorf("%v: expected %v but got %v", i, test.expected, price)
+		}
+	}
+
+	// Check the median and the change threshold.
+	prices := []types.Currency{types.NewCurrency64(3), types.NewCurrency64(1), types.NewCurrency64(2)}
+	if median := medianPrice(prices); !median.Equals64(2) {
+		t.Fatal("wrong median", median)
+	}
+	if significantPriceChange(types.NewCurrency64(100), types.NewCurrency64(104)) {
+		t.Fatal("4% change shouldn't be significant")
+	}
+	if !significantPriceChange(types.NewCurrency64(100), types.NewCurrency64(94)) {
+		t.Fatal("6% change should be significant")
+	}
+
+	// Check the validation of the ceilings.
+	aps := modules.HostAutoPricingSettings{MinStoragePrice: floor, MaxStoragePrice: ceiling}
+	if err := validateAutoPricingSettings(aps); err != nil {
+		t.Fatal(err)
+	}
+	aps.MaxStoragePrice = floor.Sub(types.NewCurrency64(1))
+	if err := validateAutoPricingSettings(aps); err != errAutoPricingCeiling {
+		t.Fatal("expected errAutoPricingCeiling but got", err)
+	}
+}
+
+// TestHostAutoPricing checks that the host updates its prices and price table
+// from the market when auto-pricing is enabled.
+func TestHostAutoPricing(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Set a market with a single other host.
+	var entry modules.HostDBEntry
+	entry.BaseRPCPrice = types.NewCurrency64(500)
+	entry.DownloadBandwidthPrice = types.NewCurrency64(500)
+	entry.SectorAccessPrice = types.NewCurrency64(500)
+	entry.StoragePrice = types.NewCurrency64(500)
+	entry.UploadBandwidthPrice = types.NewCurrency64(500)
+	ht.host.SetMarket(testMarket{entry})
+
+	// Lower the operator's min prices below the market except for the
+	// storage price.
+	settings := ht.host.InternalSettings()
+	settings.MinBaseRPCPrice = types.NewCurrency64(100)
+	settings.MinDownloadBandwidthPrice = types.NewCurrency64(100)
+	settings.MinSectorAccessPrice = types.NewCurrency64(100)
+	settings.MinStoragePrice = types.NewCurrency64(300)
+	settings.MinUploadBandwidthPrice = types.NewCurrency64(100)
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Nothing happens while auto-pricing is disabled.
+	ht.host.managedAutoPrice(0)
+	if !ht.host.ExternalSettings().BaseRPCPrice.Equals64(100) {
+		t.Fatal("prices shouldn't change while auto-pricing is disabled")
+	}
+
+	// Enable auto-pricing with a storage price ceiling below the operator's
+	// min storage price.
+	settings.AutoPricing = modules.HostAutoPricingSettings{
+		Enabled:         true,
+		MinStoragePrice: types.NewCurrency64(100),
+		MaxStoragePrice: types.NewCurrency64(200),
+	}
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedAutoPrice(0)
+	es := ht.host.ExternalSettings()
+	if !es.BaseRPCPrice.Equals64(500) {
+		t.Fatal("base RPC price should follow the market", es.BaseRPCPrice)
+	}
+	if !es.StoragePrice.Equals64(300) {
+		t.Fatal("storage price shouldn't undercut the operator's min price", es.StoragePrice)
+	}
+	// The operator's min prices are left alone.
+	is := ht.host.InternalSettings()
+	if !is.MinBaseRPCPrice.Equals64(100) || !is.MinStoragePrice.Equals64(300) {
+		t.Fatal("auto-pricing shouldn't change the min prices", is.MinBaseRPCPrice, is.MinStoragePrice)
+	}
+	ht.host.mu.RLock()
+	pt := ht.host.priceTable
+	ht.host.mu.RUnlock()
+	if !pt.Costs[modules.MDMOperationDiskRead].Equals64(500) {
+		t.Fatal("price table wasn't refreshed")
+	}
+	if !pt.Costs[modules.RPCUpdatePriceTable].Equals64(500) {
+		t.Fatal("price table should use the computed base RPC price")
+	}
+
+	// Raising the operator's min price above the computed price takes effect
+	// immediately.
+	settings.MinBaseRPCPrice = types.NewCurrency64(1000)
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if !ht.host.ExternalSettings().BaseRPCPrice.Equals64(1000) {
+		t.Fatal("the operator's min price should win")
+	}
+
+	// Disabling auto-pricing reverts to the operator's min prices.
+	settings.AutoPricing.Enabled = false
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+	if !ht.host.ExternalSettings().DownloadBandwidthPrice.Equals64(100) {
+		t.Fatal("prices should revert to the min prices")
+	}
+	settings.AutoPricing.Enabled = true
+
+	// Ceilings below the floor are rejected.
+	settings.AutoPricing.MaxStoragePrice = types.NewCurrency64(50)
+	if err := ht.host.SetInternalSettings(settings); err == nil {
+		t.Fatal("expected error for invalid ceiling")
+	}
+}
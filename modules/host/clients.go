@@ -0,0 +1,274 @@
+package host
+
+// clients.go tracks the resources consumed by the clients of the host and
+// enforces the per-client limits of the host's internal settings. Every
+// connection is tracked by the IP address of the renter and, once the renter
+// locked a contract in an RPC session, by the renter's public key. Each
+// client has a token bucket for read, write and settings RPCs and a bandwidth
+// limit which is shared by all of its connections.
+
+import (
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/ratelimit"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	connmonitor "gitlab.com/NebulousLabs/monitor"
+)
+
+const (
+	// clientTypeIP and clientTypePublicKey are the types of clients tracked
+	// by the host.
+	clientTypeIP        = "ip"
+	clientTypePublicKey = "publickey"
+)
+
+// The classes of RPCs which are limited separately. RPCs of other classes,
+// like locking and unlocking a contract, are not limited.
+const (
+	rpcClassRead rpcClass = iota
+	rpcClassSettings
+	rpcClassWrite
+	rpcClassOther
+)
+
+var (
+	// errClientRateLimited is returned to clients which exceed one of the
+	// host's per-client RPC limits.
+	errClientRateLimited = ErrorCommunication("client exceeded the host's RPC rate limit")
+)
+
+type (
+	// rpcClass is the class of an RPC for the purpose of rate limiting.
+	rpcClass int
+
+	// tokenBucket is a token bucket which is refilled continuously. The
+	// capacity of the bucket equals the refill rate per second.
+	tokenBucket struct {
+		tokens float64
+		last   time.Time
+	}
+
+	// hostClient is a client of the host. The counters and buckets are
+	// protected by the clientTracker's mutex.
+	hostClient struct {
+		id       string
+		typ      string
+		buckets  [rpcClassOther]tokenBucket
+		rpcs     [rpcClassOther]uint64
+		rejected uint64
+		conns    uint64
+		lastSeen time.Time
+
+		staticMonitor   *connmonitor.Monitor
+		staticRateLimit *ratelimit.RateLimit
+	}
+
+	// clientTracker tracks the clients of the host and their limits.
+	clientTracker struct {
+		clients   map[string]*hostClient
+		lastPrune time.Time
+
+		bandwidthLimit uint64
+		rpcLimits      [rpcClassOther]uint64
+		mu             sync.Mutex
+	}
+)
+
+// rpcClassOf returns the class of an RPC.
+func rpcClassOf(id types.Specifier) rpcClass {
+	switch id {
+	case modules.RPCDownload, modules.RPCLoopRead, modules.RPCLoopSectorRoots:
+		return rpcClassRead
+	case modules.RPCSettings, modules.RPCLoopSettings, modules.RPCUpdatePriceTable:
+		return rpcClassSettings
+	case modules.RPCFormContract, modules.RPCRenewContract, modules.RPCReviseContract,
+		modules.RPCLoopFormContract, modules.RPCLoopRenewContract, modules.RPCLoopWrite:
+		return rpcClassWrite
+	default:
+		return rpcClassOther
+	}
+}
+
+// take removes a token from the bucket. It returns false if the bucket is
+// empty. A rate of 0 means that the bucket is unlimited.
+func (tb *tokenBucket) take(rate uint64, now time.Time) bool {
+	if rate == 0 {
+		return true
+	}
+	if tb.last.IsZero() {
+		tb.tokens = float64(rate)
+	} else {
+		tb.tokens += now.Sub(tb.last).Seconds() * float64(rate)
+		if tb.tokens > float64(rate) {
+			tb.tokens = float64(rate)
+		}
+	}
+	tb.last = now
+	if tb.tokens < 1 {
+		return false
+	}
+	tb.tokens--
+	return true
+}
+
+// setBandwidthLimit sets the bandwidth limit of a client's rate limiter.
+func setBandwidthLimit(rl *ratelimit.RateLimit, limit uint64) {
+	if limit == 0 {
+		rl.SetLimits(0, 0, 0)
+	} else {
+		rl.SetLimits(int64(limit), int64(limit), clientPacketSize)
+	}
+}
+
+// staticWrapConn wraps a connection to enforce the bandwidth limit of the
+// client and to count its traffic.
+func (hc *hostClient) staticWrapConn(conn net.Conn, cancel <-chan struct{}) net.Conn {
+	return connmonitor.NewMonitoredConn(ratelimit.NewRLConn(conn, hc.staticRateLimit, cancel), hc.staticMonitor)
+}
+
+// newClientTracker creates a client tracker without limits.
+func newClientTracker() *clientTracker {
+	return &clientTracker{
+		clients: make(map[string]*hostClient),
+	}
+}
+
+// prune removes the clients which have no open connections and haven't been
+// seen for clientExpiry.
+func (ct *clientTracker) prune(now time.Time) {
+	for key, hc := range ct.clients {
+		if hc.conns == 0 && now.Sub(hc.lastSeen) > clientExpiry {
+			delete(ct.clients, key)
+		}
+	}
+	ct.lastPrune = now
+}
+
+// managedSetLimits updates the per-client limits from the host's internal
+// settings.
+func (ct *clientTracker) managedSetLimits(settings modules.HostInternalSettings) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.bandwidthLimit = settings.ClientBandwidthLimit
+	ct.rpcLimits[rpcClassRead] = settings.ClientReadRPCLimit
+	ct.rpcLimits[rpcClassSettings] = settings.ClientSettingsRPCLimit
+	ct.rpcLimits[rpcClassWrite] = settings.ClientWriteRPCLimit
+	for _, hc := range ct.clients {
+		setBandwidthLimit(hc.staticRateLimit, ct.bandwidthLimit)
+	}
+}
+
+// managedConnect registers a new connection of a client, creating the client
+// if it isn't tracked yet.
+func (ct *clientTracker) managedConnect(typ, id string) *hostClient {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	now := time.Now()
+	if now.Sub(ct.lastPrune) > clientExpiry {
+		ct.prune(now)
+	}
+	key := typ + ":" + id
+	hc, exists := ct.clients[key]
+	if !exists {
+		hc = &hostClient{
+			id:              id,
+			typ:             typ,
+			staticMonitor:   connmonitor.NewMonitor(),
+			staticRateLimit: ratelimit.NewRateLimit(0, 0, 0),
+		}
+		setBandwidthLimit(hc.staticRateLimit, ct.bandwidthLimit)
+		ct.clients[key] = hc
+	}
+	hc.conns++
+	hc.lastSeen = now
+	return hc
+}
+
+// managedDisconnect registers that a connection of a client was closed.
+func (ct *clientTracker) managedDisconnect(hc *hostClient) {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	hc.conns--
+	hc.lastSeen = time.Now()
+}
+
+// managedAllowRPC checks whether the clients are allowed to make an RPC of
+// the provided class. The RPC is rejected if any of the clients exceeds its
+// limit. Nil clients are ignored.
+func (ct *clientTracker) managedAllowRPC(class rpcClass, clients ...*hostClient) bool {
+	if class == rpcClassOther {
+		return true
+	}
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	now := time.Now()
+	allowed := true
+	for _, hc := range clients {
+		if hc != nil && !hc.buckets[class].take(ct.rpcLimits[class], now) {
+			allowed = false
+		}
+	}
+	for _, hc := range clients {
+		if hc == nil {
+			continue
+		}
+		hc.lastSeen = now
+		if allowed {
+			hc.rpcs[class]++
+		} else {
+			hc.rejected++
+		}
+	}
+	return allowed
+}
+
+// managedClients returns the tracked clients, sorted by the amount of
+// bandwidth they consumed.
+func (ct *clientTracker) managedClients() []modules.HostClient {
+	ct.mu.Lock()
+	defer ct.mu.Unlock()
+	ct.prune(time.Now())
+	clients := make([]modules.HostClient, 0, len(ct.clients))
+	for _, hc := range ct.clients {
+		// The host reads the client's uploads and writes its downloads.
+		upload, download := hc.staticMonitor.Counts()
+		clients = append(clients, modules.HostClient{
+			ID:           hc.id,
+			Type:         hc.typ,
+			Connections:  hc.conns,
+			Download:     download,
+			Upload:       upload,
+			ReadRPCs:     hc.rpcs[rpcClassRead],
+			SettingsRPCs: hc.rpcs[rpcClassSettings],
+			WriteRPCs:    hc.rpcs[rpcClassWrite],
+			RejectedRPCs: hc.rejected,
+			LastSeen:     hc.lastSeen,
+		})
+	}
+	sort.Slice(clients, func(i, j int) bool {
+		return clients[i].Download+clients[i].Upload > clients[j].Download+clients[j].Upload
+	})
+	return clients
+}
+
+// managedConnectIP registers a new connection from the IP address of the
+// remote end of conn and wraps conn to enforce the client's limits.
+func (h *Host) managedConnectIP(conn net.Conn) (*hostClient, net.Conn) {
+	ip := conn.RemoteAddr().String()
+	if host, _, err := net.SplitHostPort(ip); err == nil {
+		ip = host
+	}
+	hc := h.staticClients.managedConnect(clientTypeIP, ip)
+	return hc, hc.staticWrapConn(conn, h.tg.StopChan())
+}
+
+// Clients returns the resources consumed by the renters and IP addresses that
+// recently connected to the host.
+func (h *Host) Clients() []modules.HostClient {
+	return h.staticClients.managedClients()
+}
@@ -0,0 +1,156 @@
+package host
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestTokenBucket checks that the token bucket limits the rate at which
+// tokens can be taken.
+func TestTokenBucket(t *testing.T) {
+	var tb tokenBucket
+	now := time.Now()
+
+	// The bucket starts out full.
+	for i := 0; i < 3; i++ {
+		if !tb.take(3, now) {
+			t.Fatal("bucket should have tokens left", i)
+		}
+	}
+	if tb.take(3, now) {
+		t.Fatal("bucket should be empty")
+	}
+
+	// After half a second, one and a half tokens were refilled.
+	now = now.Add(time.Second / 2)
+	if !tb.take(3, now) {
+		t.Fatal("bucket should have been refilled")
+	}
+	if tb.take(3, now) {
+		t.Fatal("bucket should be empty")
+	}
+
+	// The bucket doesn't fill beyond its capacity.
+	now = now.Add(time.Hour)
+	for i := 0; i < 3; i++ {
+		if !tb.take(3, now) {
+			t.Fatal("bucket should have tokens left", i)
+		}
+	}
+	if tb.take(3, now) {
+		t.Fatal("bucket should be empty")
+	}
+
+	// A rate of 0 is unlimited.
+	if !tb.take(0, now) {
+		t.Fatal("unlimited bucket should never be empty")
+	}
+}
+
+// TestClientTracker checks that the client tracker enforces the limits of
+// every client separately.
+func TestClientTracker(t *testing.T) {
+	ct := newClientTracker()
+	ct.managedSetLimits(modules.HostInternalSettings{
+		ClientBandwidthLimit: 1 << 20,
+		ClientReadRPCLimit:   1,
+	})
+	ip := ct.managedConnect(clientTypeIP, "1.2.3.4")
+	renter1 := ct.managedConnect(clientTypePublicKey, "renter1")
+	renter2 := ct.managedConnect(clientTypePublicKey, "renter2")
+	if readBPS, writeBPS, _ := ip.staticRateLimit.Limits(); readBPS != 1<<20 || writeBPS != 1<<20 {
+		t.Fatal("bandwidth limit wasn't applied", readBPS, writeBPS)
+	}
+
+	// The first read RPC is allowed, the second one exceeds the limit of the
+	// IP address.
+	if !ct.managedAllowRPC(rpcClassRead, ip, renter1) {
+		t.Fatal("first RPC should be allowed")
+	}
+	if ct.managedAllowRPC(rpcClassRead, ip, renter2) {
+		t.Fatal("second RPC from the same IP should be rejected")
+	}
+	// Other classes of RPCs are limited separately.
+	if !ct.managedAllowRPC(rpcClassSettings, ip, renter1) || !ct.managedAllowRPC(rpcClassOther, ip, renter1) {
+		t.Fatal("unlimited RPCs should be allowed")
+	}
+
+	// Raising the limits applies to the existing clients.
+	ct.managedSetLimits(modules.HostInternalSettings{})
+	if readBPS, writeBPS, _ := renter1.staticRateLimit.Limits(); readBPS != 0 || writeBPS != 0 {
+		t.Fatal("bandwidth limit wasn't removed", readBPS, writeBPS)
+	}
+	if !ct.managedAllowRPC(rpcClassRead, ip, renter1) {
+		t.Fatal("RPC should be allowed without limits")
+	}
+
+	// Check the reported clients.
+	clients := make(map[string]modules.HostClient)
+	for _, c := range ct.managedClients() {
+		clients[c.ID] = c
+	}
+	if len(clients) != 3 {
+		t.Fatal("expected 3 clients but got", len(clients))
+	}
+	if c := clients["1.2.3.4"]; c.Type != clientTypeIP || c.ReadRPCs != 2 || c.SettingsRPCs != 1 || c.RejectedRPCs != 1 || c.Connections != 1 {
+		t.Fatalf("unexpected client %+v", c)
+	}
+	if c := clients["renter2"]; c.Type != clientTypePublicKey || c.ReadRPCs != 0 || c.RejectedRPCs != 1 {
+		t.Fatalf("unexpected client %+v", c)
+	}
+
+	// Disconnected clients expire.
+	ct.managedDisconnect(ip)
+	ct.managedDisconnect(renter1)
+	ct.managedDisconnect(renter2)
+	ct.mu.Lock()
+	ct.prune(time.Now().Add(clientExpiry + time.Second))
+	ct.mu.Unlock()
+	if len(ct.managedClients()) != 0 {
+		t.Fatal("disconnected clients should expire")
+	}
+}
+
+// TestClientRateLimitRPC checks that the host rejects RPCs of clients which
+// exceed the configured limit.
+func TestClientRateLimitRPC(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := blankHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.ClientSettingsRPCLimit = 1
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first price table RPC is allowed, the second one is rejected.
+	for i := 0; i < 2; i++ {
+		cc, sc := createTestingConns()
+		err = ht.host.managedRPCUpdatePriceTable(sc)
+		cc.Close()
+		sc.Close()
+		if i == 0 && err != nil {
+			t.Fatal(err)
+		} else if i == 1 && err != errClientRateLimited {
+			t.Fatal("expected errClientRateLimited but got", err)
+		}
+	}
+
+	clients := ht.host.Clients()
+	if len(clients) != 1 {
+		t.Fatal("expected 1 client but got", len(clients))
+	}
+	c := clients[0]
+	if c.ID != "127.0.0.1" || c.SettingsRPCs != 1 || c.RejectedRPCs != 1 || c.Connections != 0 || c.Download == 0 {
+		t.Fatalf("unexpected client %+v", c)
+	}
+}
@@ -0,0 +1,209 @@
+package host
+
+// collateralplanner.go implements the collateral planner of the host. The
+// collateral budget only limits the collateral that the host locks in its
+// contracts, it doesn't guarantee that the wallet can actually fund it. The
+// planner periodically projects the wallet funds that the storage obligations
+// need over the next blocks, which are the collateral of the renewals of the
+// obligations that expire and the fees of their revisions and storage proofs.
+// New contracts are refused if the host couldn't fund them on top of that, and
+// an alert is registered if the wallet balance is below the projected need.
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/bits"
+	"time"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errCollateralForecastShortfall is returned if a new contract would
+	// lock collateral that the host's wallet is not projected to be able to
+	// fund.
+	errCollateralForecastShortfall = ErrorInternal("host does not have enough projected wallet funds to cover the collateral of the file contract")
+)
+
+// proofTransactionSize estimates the size of the transaction containing the
+// storage proof of the storage obligation.
+func (so storageObligation) proofTransactionSize() uint64 {
+	numSegments := uint64(len(so.SectorRoots)) * (modules.SectorSize / crypto.SegmentSize)
+	hashSetLen := uint64(bits.Len64(numSegments))
+	return uint64(len(encoding.Marshal(types.StorageProof{}))) + hashSetLen*crypto.HashSize + 300
+}
+
+// projectCollateralNeeds adds the needs and payouts of a storage obligation
+// between the height and the end height of the forecast to the forecast. The
+// fee is the fee per byte of the transactions.
+func projectCollateralNeeds(f *modules.HostCollateralForecast, so storageObligation, fee types.Currency) {
+	// The host payout of a succeeded obligation matures after the proof
+	// deadline.
+	if so.ObligationStatus == obligationSucceeded || so.ObligationStatus == obligationUnresolved {
+		maturity := so.proofDeadline() + types.MaturityDelay
+		if maturity > f.BlockHeight && maturity <= f.EndHeight {
+			valid, _ := so.payouts()
+			f.Payouts = f.Payouts.Add(valid[1].Value)
+		}
+	}
+	if so.ObligationStatus != obligationUnresolved {
+		return
+	}
+
+	// An obligation that expires in the window is expected to be renewed with
+	// the same collateral.
+	expiration := so.expiration()
+	if expiration > f.BlockHeight && expiration <= f.EndHeight {
+		f.RenewalCollateral = f.RenewalCollateral.Add(so.LockedCollateral)
+	}
+	// The latest revision needs to be submitted before the expiration.
+	if !so.RevisionConfirmed && len(so.RevisionTransactionSet) > 0 && expiration > f.BlockHeight && expiration-revisionSubmissionBuffer <= f.EndHeight {
+		txnSize := uint64(len(encoding.MarshalAll(so.RevisionTransactionSet)) + 300)
+		f.TransactionFees = f.TransactionFees.Add(fee.Mul64(txnSize))
+	}
+	// The storage proof needs to be submitted during the proof window.
+	if !so.ProofConfirmed && expiration <= f.EndHeight && so.proofDeadline() >= f.BlockHeight {
+		f.TransactionFees = f.TransactionFees.Add(fee.Mul64(so.proofTransactionSize()))
+	}
+}
+
+// forecastShortfall returns true if the host isn't projected to be able to
+// fund the provided collateral on top of the needs of its storage obligations.
+func forecastShortfall(f modules.HostCollateralForecast, collateral types.Currency) bool {
+	if f.Window == 0 {
+		return false
+	}
+	return f.Balance.Add(f.Payouts).Cmp(f.Need.Add(collateral)) < 0
+}
+
+// renewalCollateralNeed returns the collateral of the renewal of a storage
+// obligation that isn't part of the forecast yet. The forecast already
+// expects an obligation that expires in the window to be renewed with its
+// locked collateral.
+func renewalCollateralNeed(f modules.HostCollateralForecast, so storageObligation, collateral types.Currency) types.Currency {
+	expiration := so.expiration()
+	if expiration <= f.BlockHeight || expiration > f.EndHeight {
+		return collateral
+	}
+	if collateral.Cmp(so.LockedCollateral) <= 0 {
+		return types.ZeroCurrency
+	}
+	return collateral.Sub(so.LockedCollateral)
+}
+
+// managedCollateralForecast returns the latest collateral forecast. The
+// forecast is only updated periodically, so the returned projection uses the
+// current balance of the host's wallet account.
+func (h *Host) managedCollateralForecast() modules.HostCollateralForecast {
+	h.mu.RLock()
+	f := h.collateralForecast
+	account := h.settings.WalletAccount
+	h.mu.RUnlock()
+	if f.Window == 0 {
+		return f
+	}
+	if balance, err := h.wallet.ConfirmedAccountBalance(account); err == nil {
+		f.Balance = balance
+	}
+	return f
+}
+
+// CollateralForecast returns the latest projection of the wallet funds needed
+// by the host's storage obligations.
+func (h *Host) CollateralForecast() modules.HostCollateralForecast {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.collateralForecast
+}
+
+// managedUpdateCollateralForecast projects the wallet funds needed by the
+// host's storage obligations over the forecast window and registers an alert
+// if the wallet balance is below the need.
+func (h *Host) managedUpdateCollateralForecast() {
+	h.mu.RLock()
+	f := modules.HostCollateralForecast{
+		Window:      h.settings.CollateralForecastWindow,
+		BlockHeight: h.blockHeight,
+		EndHeight:   h.blockHeight + h.settings.CollateralForecastWindow,
+		Timestamp:   time.Now(),
+	}
+	account := h.settings.WalletAccount
+	h.mu.RUnlock()
+	if f.Window == 0 {
+		h.mu.Lock()
+		h.collateralForecast = modules.HostCollateralForecast{}
+		h.mu.Unlock()
+		h.staticAlerter.UnregisterAlert(modules.AlertIDHostCollateralShortfall)
+		return
+	}
+
+	// The forecast is only updated while the wallet is unlocked. Collateral
+	// is funded from the wallet account of the host, so only its balance
+	// counts.
+	balance, err := h.wallet.ConfirmedAccountBalance(account)
+	if err != nil {
+		h.log.Debugln("Unable to fetch the wallet balance for the collateral forecast:", err)
+		return
+	}
+	f.Balance = balance
+	_, fee := h.tpool.FeeEstimation()
+
+	h.mu.RLock()
+	err = h.db.View(func(tx *bolt.Tx) error {
+		return tx.Bucket(bucketStorageObligations).ForEach(func(_, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return err
+			}
+			projectCollateralNeeds(&f, so, fee)
+			return nil
+		})
+	})
+	h.mu.RUnlock()
+	if err != nil {
+		h.log.Println("ERROR: unable to compute the collateral forecast:", err)
+		return
+	}
+	f.Need = f.RenewalCollateral.Add(f.TransactionFees)
+	if available := f.Balance.Add(f.Payouts); available.Cmp(f.Need) >= 0 {
+		f.Projected = available.Sub(f.Need)
+	} else {
+		f.Shortfall = f.Need.Sub(available)
+	}
+
+	h.mu.Lock()
+	h.collateralForecast = f
+	h.mu.Unlock()
+
+	if f.Balance.Cmp(f.Need) < 0 {
+		cause := fmt.Sprintf("wallet balance of %v is below the %v needed over the next %v blocks", f.Balance.HumanString(), f.Need.HumanString(), f.Window)
+		h.staticAlerter.RegisterAlert(modules.AlertIDHostCollateralShortfall, AlertMSGHostCollateralShortfall, cause, modules.SeverityWarning)
+	} else {
+		h.staticAlerter.UnregisterAlert(modules.AlertIDHostCollateralShortfall)
+	}
+}
+
+// threadedCollateralPlanner periodically updates the collateral forecast. The
+// thread group is only held while the forecast is computed to not block
+// calls to Flush.
+func (h *Host) threadedCollateralPlanner() {
+	for {
+		if err := h.tg.Add(); err != nil {
+			return
+		}
+		h.managedUpdateCollateralForecast()
+		h.tg.Done()
+
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(collateralPlannerInterval):
+		}
+	}
+}
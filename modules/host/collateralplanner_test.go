@@ -0,0 +1,186 @@
+package host
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// plannerTestWindowSize is the proof window of the test obligations. It
+// doesn't depend on the build so that the projected maturity heights of the
+// payouts are the same in every build.
+const plannerTestWindowSize = 144
+
+// newPlannerTestObligation returns a storage obligation with a file contract
+// that expires at the provided height.
+func newPlannerTestObligation(expiration types.BlockHeight, collateral, hostPayout uint64, status storageObligationStatus) storageObligation {
+	return storageObligation{
+		LockedCollateral: types.NewCurrency64(collateral),
+		ObligationStatus: status,
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{
+				WindowStart: expiration,
+				WindowEnd:   expiration + plannerTestWindowSize,
+				ValidProofOutputs: []types.SiacoinOutput{
+					{Value: types.ZeroCurrency},
+					{Value: types.NewCurrency64(hostPayout)},
+				},
+			}},
+		}},
+	}
+}
+
+// TestProjectCollateralNeeds checks that the renewal collateral, payouts and
+// fees of storage obligations are only projected within the forecast window.
+func TestProjectCollateralNeeds(t *testing.T) {
+	t.Parallel()
+	f := modules.HostCollateralForecast{
+		Window:      1000,
+		BlockHeight: 100,
+		EndHeight:   1100,
+	}
+	sos := []storageObligation{
+		// Expires in the window, the payout matures in the window.
+		newPlannerTestObligation(200, 10, 20, obligationUnresolved),
+		// Expires after the window.
+		newPlannerTestObligation(2000, 100, 200, obligationUnresolved),
+		// Succeeded, the payout matures in the window.
+		newPlannerTestObligation(50, 1000, 2000, obligationSucceeded),
+		// Failed obligations are ignored.
+		newPlannerTestObligation(300, 10000, 20000, obligationFailed),
+	}
+	for _, so := range sos {
+		projectCollateralNeeds(&f, so, types.ZeroCurrency)
+	}
+	if !f.RenewalCollateral.Equals64(10) {
+		t.Fatal("unexpected renewal collateral", f.RenewalCollateral)
+	}
+	if !f.Payouts.Equals64(2020) {
+		t.Fatal("unexpected payouts", f.Payouts)
+	}
+	if !f.TransactionFees.IsZero() {
+		t.Fatal("expected no fees", f.TransactionFees)
+	}
+
+	// With a non-zero fee the storage proof of the obligation that expires
+	// in the window is accounted for.
+	f.TransactionFees = types.ZeroCurrency
+	projectCollateralNeeds(&f, sos[0], types.NewCurrency64(1))
+	if !f.TransactionFees.Equals64(sos[0].proofTransactionSize()) {
+		t.Fatal("unexpected fees", f.TransactionFees)
+	}
+	f.TransactionFees = types.ZeroCurrency
+	projectCollateralNeeds(&f, sos[1], types.NewCurrency64(1))
+	if !f.TransactionFees.IsZero() {
+		t.Fatal("expected no fees for an obligation outside the window", f.TransactionFees)
+	}
+}
+
+// TestForecastShortfall checks that new collateral is only refused if it
+// can't be funded on top of the projected needs.
+func TestForecastShortfall(t *testing.T) {
+	t.Parallel()
+	f := modules.HostCollateralForecast{
+		Window:  1000,
+		Balance: types.NewCurrency64(100),
+		Payouts: types.NewCurrency64(50),
+		Need:    types.NewCurrency64(120),
+	}
+	if forecastShortfall(f, types.NewCurrency64(30)) {
+		t.Fatal("collateral that can be funded was refused")
+	}
+	if !forecastShortfall(f, types.NewCurrency64(31)) {
+		t.Fatal("collateral that can't be funded was accepted")
+	}
+	// A disabled forecast never refuses collateral.
+	f.Window = 0
+	if forecastShortfall(f, types.NewCurrency64(1000)) {
+		t.Fatal("disabled forecast refused collateral")
+	}
+}
+
+// TestRenewalCollateralNeed checks that the renewal of an obligation only
+// needs the collateral that the forecast didn't already project for it.
+func TestRenewalCollateralNeed(t *testing.T) {
+	t.Parallel()
+	f := modules.HostCollateralForecast{
+		Window:      1000,
+		BlockHeight: 100,
+		EndHeight:   1100,
+	}
+	so := newPlannerTestObligation(200, 10, 20, obligationUnresolved)
+	if need := renewalCollateralNeed(f, so, types.NewCurrency64(15)); !need.Equals64(5) {
+		t.Fatal("unexpected need for a renewal with more collateral", need)
+	}
+	if need := renewalCollateralNeed(f, so, types.NewCurrency64(5)); !need.IsZero() {
+		t.Fatal("unexpected need for a renewal with less collateral", need)
+	}
+	// The renewal of an obligation that expires after the window isn't part
+	// of the forecast.
+	so = newPlannerTestObligation(2000, 10, 20, obligationUnresolved)
+	if need := renewalCollateralNeed(f, so, types.NewCurrency64(15)); !need.Equals64(15) {
+		t.Fatal("unexpected need for a renewal outside the window", need)
+	}
+}
+
+// TestCollateralForecast checks that the host projects the renewal collateral
+// of its storage obligations and alerts if the wallet can't fund it.
+func TestCollateralForecast(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	so.LockedCollateral = types.SiacoinPrecision.Mul64(1e9)
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUnlockStorageObligation(so.id())
+
+	ht.host.managedUpdateCollateralForecast()
+	f := ht.host.CollateralForecast()
+	if f.Balance.IsZero() {
+		t.Fatal("expected the forecast to include the wallet balance")
+	}
+	if f.RenewalCollateral.Cmp(so.LockedCollateral) != 0 {
+		t.Fatal("unexpected renewal collateral", f.RenewalCollateral)
+	}
+	if f.Shortfall.IsZero() {
+		t.Fatal("expected a shortfall")
+	}
+	if !forecastShortfall(f, types.ZeroCurrency) {
+		t.Fatal("expected new contracts to be refused")
+	}
+	found := false
+	alerts := ht.host.Alerts()
+	for _, a := range alerts {
+		found = found || a.Msg == AlertMSGHostCollateralShortfall
+	}
+	if !found {
+		t.Fatal("expected a collateral shortfall alert")
+	}
+
+	// Disabling the forecast clears it.
+	settings := ht.host.InternalSettings()
+	settings.CollateralForecastWindow = 0
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedUpdateCollateralForecast()
+	if f := ht.host.CollateralForecast(); f.Window != 0 || !f.Need.IsZero() {
+		t.Fatal("expected an empty forecast", f)
+	}
+}
@@ -13,9 +13,26 @@ const (
 	// AlertMSGHostInsufficientCollateral indicates that a host has insufficient
 	// collateral budget remaining
 	AlertMSGHostInsufficientCollateral = "host has insufficient collateral budget"
+
+	// AlertMSGHostCorruptSectors indicates that the host stores corrupt
+	// sectors of active storage obligations
+	AlertMSGHostCorruptSectors = "host has corrupt sectors in active contracts"
+
+	// AlertMSGHostCollateralShortfall indicates that the host's wallet
+	// balance is below the funds its storage obligations are projected to
+	// need
+	AlertMSGHostCollateralShortfall = "host wallet balance is below the projected collateral and fee needs"
 )
 
 const (
+	// autoPricingMinChange is the minimum change of a price in percent for
+	// the auto-pricing engine to update it.
+	autoPricingMinChange = 5
+
+	// clientPacketSize is the packet size used to enforce the per-client
+	// bandwidth limit.
+	clientPacketSize = 4 * 4096
+
 	// defaultMaxDuration defines the maximum number of blocks into the future
 	// that the host will accept for the duration of an incoming file contract
 	// obligation. 6 months is chosen because hosts are expected to be
@@ -34,6 +51,11 @@ const (
 	// contract revision, or a storage proof.
 	resubmissionTimeout = 3
 
+	// revisionHistoryLen is the number of the most recent revisions of a
+	// storage obligation that the host keeps for the storage obligation
+	// explorer.
+	revisionHistoryLen = 100
+
 	// rpcRequestInterval is the amount of time that the renter has to send
 	// the next RPC ID in the new RPC loop. (More time is alloted for sending
 	// the actual RPC request object.)
@@ -93,6 +115,12 @@ var (
 	// furious for losing access to it for a few weeks.
 	defaultCollateralBudget = types.SiacoinPrecision.Mul64(100e3)
 
+	// defaultCollateralForecastWindow is the default number of blocks over
+	// which the collateral planner projects the funds needed by the host's
+	// storage obligations. Two weeks cover the renewal window of most
+	// renters.
+	defaultCollateralForecastWindow = 2 * types.BlocksPerWeek
+
 	// defaultContractPrice defines the default price of creating a contract
 	// with the host. The current default is 0.1. This was chosen since it is
 	// the minimum fee estimation of the transactionpool for a filecontract
@@ -231,6 +259,46 @@ var (
 		Testing:  time.Second * 3,
 	}).(time.Duration)
 
+	// autoPricingInterval defines how frequently the auto-pricing engine
+	// recomputes the host's prices.
+	autoPricingInterval = build.Select(build.Var{
+		Standard: time.Minute * 10,
+		Dev:      time.Minute * 1,
+		Testing:  time.Second * 3,
+	}).(time.Duration)
+
+	// autoPricingMinMarketHosts is the minimum number of other active hosts
+	// required for the auto-pricing engine to base its prices on the market.
+	autoPricingMinMarketHosts = build.Select(build.Var{
+		Standard: 10,
+		Dev:      1,
+		Testing:  1,
+	}).(int)
+
+	// collateralPlannerInterval defines how frequently the collateral planner
+	// recomputes the forecast of the funds needed by the host.
+	collateralPlannerInterval = build.Select(build.Var{
+		Standard: time.Minute * 5,
+		Dev:      time.Minute * 1,
+		Testing:  time.Second * 3,
+	}).(time.Duration)
+
+	// corruptSectorsCheckInterval defines how frequently the host checks the
+	// results of the contract manager's scrubber for corrupt sectors.
+	corruptSectorsCheckInterval = build.Select(build.Var{
+		Standard: time.Minute * 10,
+		Dev:      time.Minute * 1,
+		Testing:  time.Second * 2,
+	}).(time.Duration)
+
+	// clientExpiry defines how long a client without open connections is
+	// tracked after it was last seen.
+	clientExpiry = build.Select(build.Var{
+		Standard: time.Minute * 30,
+		Dev:      time.Minute * 5,
+		Testing:  time.Second * 5,
+	}).(time.Duration)
+
 	// workingStatusFrequency defines how frequently the Host's working status
 	// check runs
 	workingStatusFrequency = build.Select(build.Var{
@@ -261,6 +329,11 @@ var (
 	// using the id.
 	bucketActionItems = []byte("BucketActionItems")
 
+	// bucketRevisionHistories maps a file contract id to the revision history
+	// of its storage obligation. The history is kept apart from the storage
+	// obligation to not decode it whenever the storage obligation is read.
+	bucketRevisionHistories = []byte("BucketRevisionHistories")
+
 	// bucketStorageObligations contains a set of serialized
 	// 'storageObligations' sorted by their file contract id.
 	bucketStorageObligations = []byte("BucketStorageObligations")
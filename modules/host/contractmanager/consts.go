@@ -27,6 +27,10 @@ const (
 	// house all of the sectors associated with a storage folder.
 	sectorFile = "siahostdata.dat"
 
+	// sectorCacheDir is the name of the directory that is created within
+	// the directory of the sector cache to hold the cached sectors.
+	sectorCacheDir = "siasectorcache"
+
 	// settingsFile is the name of the file that is used to save the contract
 	// manager's settings.
 	settingsFile = "contractmanager.json"
@@ -136,3 +140,29 @@ var (
 		Testing:  time.Second * 8,
 	}).(time.Duration)
 )
+
+var (
+	// scrubCycleInterval specifies the amount of time between the start of
+	// two full scrubs of the stored sectors.
+	scrubCycleInterval = build.Select(build.Var{
+		Dev:      time.Minute * 10,
+		Standard: time.Hour * 24 * 7,
+		Testing:  time.Second * 3,
+	}).(time.Duration)
+
+	// scrubFirstWait specifies the amount of time the contract manager waits
+	// after startup before it starts the first scrub.
+	scrubFirstWait = build.Select(build.Var{
+		Dev:      time.Minute,
+		Standard: time.Hour,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// scrubSectorInterval rate limits the scrubber. The scrubber waits this
+	// long after verifying a sector before it verifies the next one.
+	scrubSectorInterval = build.Select(build.Var{
+		Dev:      time.Millisecond * 10,
+		Standard: time.Millisecond * 100,
+		Testing:  time.Millisecond,
+	}).(time.Duration)
+)
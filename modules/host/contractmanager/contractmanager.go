@@ -27,6 +27,7 @@ package contractmanager
 
 import (
 	"path/filepath"
+	"sync"
 	"sync/atomic"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
@@ -92,6 +93,21 @@ type ContractManager struct {
 	// or modified.
 	lockedSectors map[sectorID]*sectorLock
 
+	// corruptSectors contains the sectors which didn't match their Merkle
+	// root when they were last scrubbed. scrubStatus reports the progress of
+	// the scrubber. Both are protected by scrubMu.
+	corruptSectors map[sectorID]struct{}
+	scrubMu        sync.Mutex
+	scrubStatus    modules.StorageScrubStatus
+
+	// migration is the sector migration that is currently under way, if any.
+	// It is persisted through the WAL and the settings file so that an
+	// interrupted migration is resumed after startup.
+	migration *storageFolderMigration
+
+	// staticSectorCache caches the data of recently read sectors.
+	staticSectorCache *sectorCache
+
 	// Utilities.
 	dependencies  modules.Dependencies
 	staticAlerter *modules.GenericAlerter
@@ -115,6 +131,10 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 
 		lockedSectors: make(map[sectorID]*sectorLock),
 
+		corruptSectors: make(map[sectorID]struct{}),
+
+		staticSectorCache: newSectorCache(),
+
 		dependencies: dependencies,
 		persistDir:   persistDir,
 
@@ -150,6 +170,13 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 	cm.tg.AfterStop(func() {
 		err = errors.Compose(cm.log.Close(), err)
 	})
+	// Clear the sector cache before the logger is closed.
+	cm.tg.AfterStop(func() {
+		err := cm.staticSectorCache.managedClose()
+		if err != nil {
+			cm.log.Println("Unable to clear the sector cache:", err)
+		}
+	})
 
 	// Load the atomic state of the contract manager. Unclean shutdown may have
 	// wiped out some changes that got made. Anything really important will be
@@ -214,6 +241,12 @@ func newContractManager(dependencies modules.Dependencies, persistDir string) (*
 	// and adds them if they are discovered.
 	go cm.threadedFolderRecheck()
 
+	// Spin up the thread that periodically verifies the stored sectors.
+	go cm.threadedScrub()
+
+	// Resume any sector migration that was interrupted by shutdown.
+	go cm.threadedMigrate()
+
 	// Simulate an error to make sure the cleanup code is triggered correctly.
 	if cm.dependencies.Disrupt("erroredStartup") {
 		err = errors.New("startup disrupted")
@@ -28,6 +28,7 @@ type (
 	savedSettings struct {
 		SectorSalt     crypto.Hash
 		StorageFolders []savedStorageFolder
+		Migration      *storageFolderMigration `json:",omitempty"`
 	}
 )
 
@@ -73,6 +74,7 @@ func (cm *ContractManager) loadSettings() error {
 
 	// Copy the saved settings into the contract manager.
 	cm.sectorSalt = ss.SectorSalt
+	cm.migration = ss.Migration
 	for i := range ss.StorageFolders {
 		sf := new(storageFolder)
 		sf.index = ss.StorageFolders[i].Index
@@ -141,6 +143,12 @@ func (cm *ContractManager) savedSettings() savedSettings {
 	ss := savedSettings{
 		SectorSalt: cm.sectorSalt,
 	}
+	if cm.migration != nil {
+		// Copy the migration so that the saved settings don't change with the
+		// state.
+		sfm := *cm.migration
+		ss.Migration = &sfm
+	}
 	for _, sf := range cm.storageFolders {
 		// Unset all of the usage bits in the storage folder for the queued sectors.
 		for _, sectorIndex := range sf.availableSectors {
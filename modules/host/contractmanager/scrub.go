@@ -0,0 +1,167 @@
+package contractmanager
+
+import (
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// CorruptSectors returns the subset of the provided sector roots that were
+// found to be corrupt by the scrubber.
+func (cm *ContractManager) CorruptSectors(sectorRoots []crypto.Hash) []crypto.Hash {
+	cm.scrubMu.Lock()
+	defer cm.scrubMu.Unlock()
+	if len(cm.corruptSectors) == 0 {
+		return nil
+	}
+	var corrupt []crypto.Hash
+	for _, root := range sectorRoots {
+		if _, exists := cm.corruptSectors[cm.managedSectorID(root)]; exists {
+			corrupt = append(corrupt, root)
+		}
+	}
+	return corrupt
+}
+
+// ScrubStatus returns the progress of the scrubber.
+func (cm *ContractManager) ScrubStatus() modules.StorageScrubStatus {
+	cm.scrubMu.Lock()
+	defer cm.scrubMu.Unlock()
+	status := cm.scrubStatus
+	status.CorruptSectors = uint64(len(cm.corruptSectors))
+	return status
+}
+
+// managedScrubSector reads a sector from disk and checks that its data still
+// matches the sector's id. A sector that was removed in the meantime or is
+// stored on an unavailable storage folder is skipped.
+func (cm *ContractManager) managedScrubSector(id sectorID) (corrupt bool, err error) {
+	cm.wal.managedLockSector(id)
+	defer cm.wal.managedUnlockSector(id)
+
+	cm.wal.mu.Lock()
+	sl, exists1 := cm.sectorLocations[id]
+	sf, exists2 := cm.storageFolders[sl.storageFolder]
+	cm.wal.mu.Unlock()
+	if !exists1 || !exists2 || atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
+		return false, nil
+	}
+
+	sectorData, err := readSector(sf.sectorFile, sl.index)
+	if err != nil {
+		atomic.AddUint64(&sf.atomicFailedReads, 1)
+		return false, build.ExtendErr("unable to read sector", err)
+	}
+	if cm.managedSectorID(crypto.MerkleRoot(sectorData)) == id {
+		return false, nil
+	}
+	// A corrupt sector counts as a failed read of the storage folder.
+	atomic.AddUint64(&sf.atomicFailedReads, 1)
+	return true, nil
+}
+
+// managedScrub verifies every sector stored by the contract manager once.
+func (cm *ContractManager) managedScrub() {
+	// Grab the ids of all the sectors. Sectors which are added during the
+	// scrub are verified by the next scrub.
+	cm.wal.mu.Lock()
+	ids := make([]sectorID, 0, len(cm.sectorLocations))
+	for id := range cm.sectorLocations {
+		ids = append(ids, id)
+	}
+	cm.wal.mu.Unlock()
+
+	// Forget about corrupt sectors which were removed since the last scrub.
+	cm.scrubMu.Lock()
+	if len(cm.corruptSectors) > 0 {
+		stored := make(map[sectorID]struct{}, len(ids))
+		for _, id := range ids {
+			stored[id] = struct{}{}
+		}
+		for id := range cm.corruptSectors {
+			if _, exists := stored[id]; !exists {
+				delete(cm.corruptSectors, id)
+			}
+		}
+	}
+	cm.scrubStatus.Running = true
+	cm.scrubStatus.ScrubbedSectors = 0
+	cm.scrubStatus.TotalSectors = uint64(len(ids))
+	cm.scrubStatus.ReadErrors = 0
+	cm.scrubStatus.LastScrubStart = time.Now()
+	cm.scrubMu.Unlock()
+
+	defer func() {
+		cm.scrubMu.Lock()
+		cm.scrubStatus.Running = false
+		cm.scrubMu.Unlock()
+	}()
+	for _, id := range ids {
+		// Rate limit the scrubber and check for shutdown.
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-time.After(scrubSectorInterval):
+		}
+
+		// The thread group is only held while a sector is verified to not
+		// block calls to Flush for the duration of a scrub.
+		if err := cm.tg.Add(); err != nil {
+			return
+		}
+		corrupt, err := cm.managedScrubSector(id)
+		cm.tg.Done()
+		if err != nil {
+			cm.log.Println("Scrubber unable to read sector:", err)
+		}
+		if corrupt {
+			cm.log.Printf("WARN: scrubber found corrupt sector %x", id)
+		}
+		cm.scrubMu.Lock()
+		cm.scrubStatus.ScrubbedSectors++
+		if err != nil {
+			cm.scrubStatus.ReadErrors++
+		}
+		if corrupt {
+			cm.corruptSectors[id] = struct{}{}
+		} else if err == nil {
+			delete(cm.corruptSectors, id)
+		}
+		cm.scrubMu.Unlock()
+	}
+
+	cm.scrubMu.Lock()
+	cm.scrubStatus.CompletedScrubs++
+	cm.scrubStatus.LastScrubEnd = time.Now()
+	corrupt := len(cm.corruptSectors)
+	cm.scrubMu.Unlock()
+	cm.log.Printf("Scrubbed %v sectors, %v corrupt", len(ids), corrupt)
+}
+
+// threadedScrub periodically verifies all of the sectors stored by the
+// contract manager against their Merkle roots to detect bit rot before it is
+// noticed by a renter or a failed storage proof.
+func (cm *ContractManager) threadedScrub() {
+	// Don't spawn the loop if 'noScrub' disruption is set.
+	if cm.dependencies.Disrupt("noScrub") {
+		return
+	}
+
+	sleepTime := scrubFirstWait
+	for {
+		select {
+		case <-cm.tg.StopChan():
+			return
+		case <-time.After(sleepTime):
+		}
+		start := time.Now()
+		cm.managedScrub()
+		sleepTime = scrubCycleInterval - time.Since(start)
+		if sleepTime < 0 {
+			sleepTime = 0
+		}
+	}
+}
@@ -0,0 +1,96 @@
+package contractmanager
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestScrub checks that the scrubber detects sectors whose data was corrupted
+// on disk.
+func TestScrub(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder and two sectors.
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	root1, data1 := randSector()
+	root2, data2 := randSector()
+	if err := cmt.cm.AddSector(root1, data1); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.AddSector(root2, data2); err != nil {
+		t.Fatal(err)
+	}
+
+	// A scrub of healthy sectors finds nothing. The background scrubber might
+	// run concurrently, so only the totals are checked.
+	cmt.cm.managedScrub()
+	status := cmt.cm.ScrubStatus()
+	if status.TotalSectors != 2 || status.CorruptSectors != 0 || status.CompletedScrubs == 0 {
+		t.Fatalf("unexpected scrub status %+v", status)
+	}
+	if len(cmt.cm.CorruptSectors([]crypto.Hash{root1, root2})) != 0 {
+		t.Fatal("no sectors should be corrupt")
+	}
+
+	// Corrupt the first sector on disk.
+	id := cmt.cm.managedSectorID(root1)
+	cmt.cm.wal.mu.Lock()
+	sl := cmt.cm.sectorLocations[id]
+	sf := cmt.cm.storageFolders[sl.storageFolder]
+	cmt.cm.wal.mu.Unlock()
+	err = writeSector(sf.sectorFile, sl.index, fastrand.Bytes(int(modules.SectorSize)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	failedReads := cmt.cm.StorageFolders()[0].FailedReads
+
+	// The scrubber should find the corrupt sector.
+	cmt.cm.managedScrub()
+	status = cmt.cm.ScrubStatus()
+	if status.CorruptSectors != 1 || status.CompletedScrubs < 2 {
+		t.Fatalf("unexpected scrub status %+v", status)
+	}
+	corrupt := cmt.cm.CorruptSectors([]crypto.Hash{root1, root2})
+	if len(corrupt) != 1 || corrupt[0] != root1 {
+		t.Fatal("the first sector should be corrupt", corrupt)
+	}
+	sfm := cmt.cm.StorageFolders()[0]
+	if sfm.CorruptSectors != 1 {
+		t.Fatal("storage folder should report a corrupt sector", sfm.CorruptSectors)
+	}
+	if sfm.FailedReads <= failedReads {
+		t.Fatal("corrupt sector should count as a failed read")
+	}
+
+	// Removing the sector clears it from the corrupt sectors with the next
+	// scrub.
+	if err := cmt.cm.RemoveSector(root1); err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm.managedScrub()
+	if status := cmt.cm.ScrubStatus(); status.CorruptSectors != 0 || status.TotalSectors != 1 {
+		t.Fatalf("unexpected scrub status %+v", status)
+	}
+}
@@ -142,6 +142,11 @@ func (cm *ContractManager) ReadSector(root crypto.Hash) ([]byte, error) {
 		return nil, ErrSectorNotFound
 	}
 
+	// Serve the sector from the cache if possible.
+	if sectorData, cached := cm.staticSectorCache.managedGet(id); cached {
+		return sectorData, nil
+	}
+
 	// Read the sector.
 	sectorData, err := readSector(sf.sectorFile, sl.index)
 	if err != nil {
@@ -149,6 +154,7 @@ func (cm *ContractManager) ReadSector(root crypto.Hash) ([]byte, error) {
 		return nil, build.ExtendErr("unable to fetch sector", err)
 	}
 	atomic.AddUint64(&sf.atomicSuccessfulReads, 1)
+	cm.staticSectorCache.managedAdd(id, sectorData)
 	return sectorData, nil
 }
 
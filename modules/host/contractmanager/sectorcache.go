@@ -0,0 +1,264 @@
+package contractmanager
+
+import (
+	"container/list"
+	"encoding/hex"
+	"fmt"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"sync/atomic"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errSectorCacheDir is returned if the directory of the sector cache is
+	// not an absolute path.
+	errSectorCacheDir = errors.New("sector cache directory must be an absolute path")
+)
+
+// sectorCache is a bounded LRU cache of recently read sectors. Popular sectors
+// are served from the cache instead of the storage folders. The cached data is
+// either kept in memory or, if a directory is set, in files within that
+// directory, which is useful if the directory is on a faster drive than the
+// storage folders.
+type sectorCache struct {
+	// Hit and miss counters for this boot cycle.
+	atomicHits   uint64
+	atomicMisses uint64
+
+	// dir is the directory of the cached sectors. If dir is empty, the
+	// sectors are kept in memory.
+	dir        string
+	entries    map[sectorID]*list.Element
+	lru        *list.List
+	maxSectors uint64
+	nextFile   uint64
+	mu         sync.Mutex
+}
+
+// sectorCacheEntry is an element of the sector cache's lru list. data is nil
+// if the cache is backed by a directory. Every entry of a directory backed
+// cache gets its own file, which allows for reading and writing the file
+// without holding the cache's lock. An entry is pending until its file is
+// written.
+type sectorCacheEntry struct {
+	id      sectorID
+	data    []byte
+	path    string
+	pending bool
+}
+
+// newSectorCache returns an empty, disabled sector cache.
+func newSectorCache() *sectorCache {
+	return &sectorCache{
+		entries: make(map[sectorID]*list.Element),
+		lru:     list.New(),
+	}
+}
+
+// newPath returns a new path for the file of a sector in the cache
+// directory.
+func (sc *sectorCache) newPath(id sectorID) string {
+	sc.nextFile++
+	return filepath.Join(sc.dir, fmt.Sprintf("%v-%v.dat", hex.EncodeToString(id[:]), sc.nextFile))
+}
+
+// removeElement removes an entry from the cache.
+func (sc *sectorCache) removeElement(e *list.Element) {
+	entry := sc.lru.Remove(e).(*sectorCacheEntry)
+	delete(sc.entries, entry.id)
+	if entry.path != "" {
+		_ = os.Remove(entry.path)
+	}
+}
+
+// managedRemoveEntry removes an entry from the cache unless it was already
+// removed.
+func (sc *sectorCache) managedRemoveEntry(e *list.Element) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.entries[e.Value.(*sectorCacheEntry).id] == e {
+		sc.removeElement(e)
+	}
+}
+
+// clear removes all entries from the cache.
+func (sc *sectorCache) clear() {
+	for e := sc.lru.Front(); e != nil; e = sc.lru.Front() {
+		sc.removeElement(e)
+	}
+}
+
+// managedClose removes all cached sectors.
+func (sc *sectorCache) managedClose() error {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	sc.clear()
+	if sc.dir == "" {
+		return nil
+	}
+	return os.RemoveAll(sc.dir)
+}
+
+// managedConfigure changes the size and the directory of the cache. The cache
+// is cleared if the directory changes and shrunk if the size decreases. A
+// size of 0 disables the cache.
+func (sc *sectorCache) managedConfigure(size uint64, dir string) error {
+	if dir != "" && !filepath.IsAbs(dir) {
+		return errSectorCacheDir
+	}
+	if dir != "" {
+		dir = filepath.Join(dir, sectorCacheDir)
+	}
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if dir != sc.dir {
+		sc.clear()
+		if sc.dir != "" {
+			_ = os.RemoveAll(sc.dir)
+		}
+		sc.dir = ""
+		// Remove the sectors cached by a previous run.
+		if dir != "" {
+			if err := os.RemoveAll(dir); err != nil {
+				return errors.AddContext(err, "unable to clear sector cache directory")
+			}
+			if err := os.MkdirAll(dir, 0700); err != nil {
+				return errors.AddContext(err, "unable to create sector cache directory")
+			}
+		}
+		sc.dir = dir
+	}
+	sc.maxSectors = size / modules.SectorSize
+	for uint64(sc.lru.Len()) > sc.maxSectors {
+		sc.removeElement(sc.lru.Back())
+	}
+	return nil
+}
+
+// managedGet returns the data of a cached sector. The file of a directory
+// backed cache is read without holding the lock.
+func (sc *sectorCache) managedGet(id sectorID) ([]byte, bool) {
+	sc.mu.Lock()
+	if sc.maxSectors == 0 {
+		sc.mu.Unlock()
+		return nil, false
+	}
+	e, exists := sc.entries[id]
+	if !exists || e.Value.(*sectorCacheEntry).pending {
+		sc.mu.Unlock()
+		atomic.AddUint64(&sc.atomicMisses, 1)
+		return nil, false
+	}
+	sc.lru.MoveToFront(e)
+	entry := e.Value.(*sectorCacheEntry)
+	if entry.path == "" {
+		data := append([]byte(nil), entry.data...)
+		sc.mu.Unlock()
+		atomic.AddUint64(&sc.atomicHits, 1)
+		return data, true
+	}
+	path := entry.path
+	sc.mu.Unlock()
+
+	data, err := ioutil.ReadFile(path)
+	if err != nil || uint64(len(data)) != modules.SectorSize {
+		// Treat a broken or evicted cache file as a miss.
+		sc.managedRemoveEntry(e)
+		atomic.AddUint64(&sc.atomicMisses, 1)
+		return nil, false
+	}
+	atomic.AddUint64(&sc.atomicHits, 1)
+	return data, true
+}
+
+// managedAdd adds a sector to the cache, evicting the least recently used
+// sectors if the cache is full. The file of a directory backed cache is
+// written without holding the lock.
+func (sc *sectorCache) managedAdd(id sectorID, data []byte) {
+	sc.mu.Lock()
+	if sc.maxSectors == 0 {
+		sc.mu.Unlock()
+		return
+	}
+	if e, exists := sc.entries[id]; exists {
+		sc.lru.MoveToFront(e)
+		sc.mu.Unlock()
+		return
+	}
+	for uint64(sc.lru.Len()) >= sc.maxSectors {
+		sc.removeElement(sc.lru.Back())
+	}
+	entry := &sectorCacheEntry{id: id}
+	if sc.dir == "" {
+		entry.data = append([]byte(nil), data...)
+		sc.entries[id] = sc.lru.PushFront(entry)
+		sc.mu.Unlock()
+		return
+	}
+	entry.path = sc.newPath(id)
+	entry.pending = true
+	e := sc.lru.PushFront(entry)
+	sc.entries[id] = e
+	sc.mu.Unlock()
+
+	err := ioutil.WriteFile(entry.path, data, 0600)
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if sc.entries[id] != e {
+		// The entry was evicted or removed while the file was written.
+		_ = os.Remove(entry.path)
+		return
+	}
+	if err != nil {
+		// Don't cache the sector if it can't be written to the cache
+		// directory.
+		sc.removeElement(e)
+		return
+	}
+	entry.pending = false
+}
+
+// managedRemove removes a sector from the cache.
+func (sc *sectorCache) managedRemove(id sectorID) {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	if e, exists := sc.entries[id]; exists {
+		sc.removeElement(e)
+	}
+}
+
+// managedStatus returns the size and the hit and miss counters of the cache.
+func (sc *sectorCache) managedStatus() modules.SectorCacheStatus {
+	sc.mu.Lock()
+	defer sc.mu.Unlock()
+	return modules.SectorCacheStatus{
+		Capacity: sc.maxSectors * modules.SectorSize,
+		Size:     uint64(sc.lru.Len()) * modules.SectorSize,
+		Hits:     atomic.LoadUint64(&sc.atomicHits),
+		Misses:   atomic.LoadUint64(&sc.atomicMisses),
+	}
+}
+
+// SectorCacheStatus returns the size and the hit and miss counters of the
+// sector cache.
+func (cm *ContractManager) SectorCacheStatus() modules.SectorCacheStatus {
+	return cm.staticSectorCache.managedStatus()
+}
+
+// SetSectorCache sets the size of the sector cache in bytes and the
+// directory that backs it. If the directory is empty, the cached sectors are
+// kept in memory. A size of 0 disables the cache.
+func (cm *ContractManager) SetSectorCache(size uint64, dir string) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	return cm.staticSectorCache.managedConfigure(size, dir)
+}
@@ -0,0 +1,203 @@
+package contractmanager
+
+import (
+	"bytes"
+	"io/ioutil"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestSectorCacheLRU checks that the sector cache evicts the least recently
+// used sectors, both in memory and when backed by a directory.
+func TestSectorCacheLRU(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir(modules.ContractManagerDir, t.Name())
+	for _, cacheDir := range []string{"", dir} {
+		sc := newSectorCache()
+		var id1, id2, id3 sectorID
+		fastrand.Read(id1[:])
+		fastrand.Read(id2[:])
+		fastrand.Read(id3[:])
+		data := fastrand.Bytes(int(modules.SectorSize))
+
+		// A disabled cache doesn't store anything.
+		sc.managedAdd(id1, data)
+		if _, cached := sc.managedGet(id1); cached {
+			t.Fatal("disabled cache shouldn't cache sectors")
+		}
+
+		// Enable a cache for two sectors.
+		if err := sc.managedConfigure(2*modules.SectorSize, cacheDir); err != nil {
+			t.Fatal(err)
+		}
+		sc.managedAdd(id1, data)
+		sc.managedAdd(id2, data)
+		cached, ok := sc.managedGet(id1)
+		if !ok || !bytes.Equal(cached, data) {
+			t.Fatal("sector should be cached")
+		}
+		// The returned data must not alias the cached data.
+		cached[0]++
+		if cached, _ := sc.managedGet(id1); !bytes.Equal(cached, data) {
+			t.Fatal("cached data was modified")
+		}
+
+		// Adding a third sector evicts the least recently used one.
+		sc.managedAdd(id3, data)
+		if _, ok := sc.managedGet(id2); ok {
+			t.Fatal("least recently used sector should be evicted")
+		}
+		if _, ok := sc.managedGet(id1); !ok {
+			t.Fatal("recently used sector shouldn't be evicted")
+		}
+
+		// Removed sectors are no longer cached.
+		sc.managedRemove(id1)
+		if _, ok := sc.managedGet(id1); ok {
+			t.Fatal("removed sector shouldn't be cached")
+		}
+		status := sc.managedStatus()
+		if status.Capacity != 2*modules.SectorSize || status.Size != modules.SectorSize {
+			t.Fatalf("unexpected status %+v", status)
+		}
+		if status.Hits != 3 || status.Misses != 2 {
+			t.Fatalf("unexpected hits and misses %+v", status)
+		}
+
+		// Closing the cache removes the cached sectors from disk.
+		if err := sc.managedClose(); err != nil {
+			t.Fatal(err)
+		}
+		if cacheDir != "" {
+			if _, err := os.Stat(filepath.Join(cacheDir, sectorCacheDir)); !os.IsNotExist(err) {
+				t.Fatal("cache directory should be removed", err)
+			}
+		}
+	}
+
+	// Relative directories are rejected.
+	if err := newSectorCache().managedConfigure(modules.SectorSize, "cache"); err != errSectorCacheDir {
+		t.Fatal("expected errSectorCacheDir but got", err)
+	}
+}
+
+// TestReadSectorCache checks that ReadSector serves sectors from the cache
+// and that removed sectors are evicted.
+func TestReadSectorCache(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	storageFolderDir := filepath.Join(cmt.persistDir, "storageFolderOne")
+	err = os.MkdirAll(storageFolderDir, 0700)
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = cmt.cm.AddStorageFolder(storageFolderDir, modules.SectorSize*storageFolderGranularity)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.SetSectorCache(4*modules.SectorSize, ""); err != nil {
+		t.Fatal(err)
+	}
+	root, data := randSector()
+	if err := cmt.cm.AddSector(root, data); err != nil {
+		t.Fatal(err)
+	}
+
+	// The first read misses the cache, the second one hits it.
+	for i := 0; i < 2; i++ {
+		sectorData, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(sectorData, data) {
+			t.Fatal("wrong sector data")
+		}
+	}
+	status := cmt.cm.SectorCacheStatus()
+	if status.Hits != 1 || status.Misses != 1 || status.Size != modules.SectorSize {
+		t.Fatalf("unexpected cache status %+v", status)
+	}
+	if cmt.cm.StorageFolders()[0].SuccessfulReads != 1 {
+		t.Fatal("cache hit shouldn't read from disk")
+	}
+
+	// Removing the sector evicts it from the cache.
+	if err := cmt.cm.RemoveSector(root); err != nil {
+		t.Fatal(err)
+	}
+	if status := cmt.cm.SectorCacheStatus(); status.Size != 0 {
+		t.Fatal("removed sector should be evicted", status.Size)
+	}
+	if _, err := cmt.cm.ReadSector(root); err != ErrSectorNotFound {
+		t.Fatal("expected ErrSectorNotFound but got", err)
+	}
+}
+
+// TestSectorCacheConcurrent checks that a directory backed sector cache can
+// be used concurrently while sectors are evicted.
+func TestSectorCacheConcurrent(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	dir := build.TempDir(modules.ContractManagerDir, t.Name())
+	sc := newSectorCache()
+	if err := sc.managedConfigure(2*modules.SectorSize, dir); err != nil {
+		t.Fatal(err)
+	}
+	ids := make([]sectorID, 4)
+	for i := range ids {
+		fastrand.Read(ids[i][:])
+	}
+	data := fastrand.Bytes(int(modules.SectorSize))
+
+	var wg sync.WaitGroup
+	for i := 0; i < 4; i++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for j := 0; j < 20; j++ {
+				id := ids[fastrand.Intn(len(ids))]
+				sc.managedAdd(id, data)
+				if cached, ok := sc.managedGet(id); ok && !bytes.Equal(cached, data) {
+					t.Error("cached data is corrupted")
+				}
+			}
+		}()
+	}
+	wg.Wait()
+
+	// The cache never holds more than its capacity, neither in memory nor on
+	// disk.
+	if status := sc.managedStatus(); status.Size > status.Capacity {
+		t.Fatalf("unexpected status %+v", status)
+	}
+	files, err := ioutil.ReadDir(filepath.Join(dir, sectorCacheDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(files) > 2 {
+		t.Fatal("expected at most 2 cache files but got", len(files))
+	}
+	if err := sc.managedClose(); err != nil {
+		t.Fatal(err)
+	}
+}
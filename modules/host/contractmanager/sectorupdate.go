@@ -216,6 +216,9 @@ func (wal *writeAheadLog) managedAddVirtualSector(id sectorID, location sectorLo
 
 // managedDeleteSector will delete a sector (physical) from the contract manager.
 func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
+	// Evict the sector from the cache.
+	wal.cm.staticSectorCache.managedRemove(id)
+
 	// Write the sector delete to the WAL.
 	var location sectorLocation
 	var syncChan chan struct{}
@@ -271,6 +274,9 @@ func (wal *writeAheadLog) managedDeleteSector(id sectorID) error {
 // managedRemoveSector will remove a sector (virtual or physical) from the
 // contract manager.
 func (wal *writeAheadLog) managedRemoveSector(id sectorID) error {
+	// Evict the sector from the cache.
+	wal.cm.staticSectorCache.managedRemove(id)
+
 	// Inform the WAL of the removed sector.
 	var location sectorLocation
 	var su sectorUpdate
@@ -412,6 +412,16 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 	cm.wal.mu.Lock()
 	defer cm.wal.mu.Unlock()
 
+	// Count the corrupt sectors of each storage folder.
+	corrupt := make(map[uint16]uint64)
+	cm.scrubMu.Lock()
+	for id := range cm.corruptSectors {
+		if sl, exists := cm.sectorLocations[id]; exists {
+			corrupt[sl.storageFolder]++
+		}
+	}
+	cm.scrubMu.Unlock()
+
 	// Iterate over the storage folders that are in memory first, and then
 	// suppliment them with the storage folders that are not in memory.
 	var smfs []modules.StorageFolderMetadata
@@ -426,12 +436,20 @@ func (cm *ContractManager) StorageFolders() []modules.StorageFolderMetadata {
 			SuccessfulReads:  atomic.LoadUint64(&sf.atomicSuccessfulReads),
 			SuccessfulWrites: atomic.LoadUint64(&sf.atomicSuccessfulWrites),
 
+			CorruptSectors: corrupt[sf.index],
+
 			Capacity:          modules.SectorSize * 64 * uint64(len(sf.usage)),
 			CapacityRemaining: ((64 * uint64(len(sf.usage))) - sf.sectors) * modules.SectorSize,
 			Index:             sf.index,
 			Path:              sf.path,
 		}
 
+		// Report the error of a failed sector migration out of the storage
+		// folder.
+		if m := cm.migration; m != nil && (m.Rebalance || m.Source == sf.index) {
+			sfm.MigrationError = m.Err
+		}
+
 		// Set some of the values to extreme numbers if the storage folder is
 		// unavailable, to flag the user's attention.
 		if atomic.LoadUint64(&sf.atomicUnavailable) == 1 {
@@ -0,0 +1,364 @@
+package contractmanager
+
+import (
+	"errors"
+	"sort"
+	"sync/atomic"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errMigrationInProgress is returned if a sector migration is started
+	// while another migration is still under way.
+	errMigrationInProgress = errors.New("a sector migration is already in progress")
+
+	// errMigrationInterrupted is returned if a sector migration is stopped by
+	// the shutdown of the contract manager. The migration is resumed after
+	// the next startup.
+	errMigrationInterrupted = errors.New("sector migration was interrupted by shutdown")
+
+	// errMigrationNoSpace is returned if the destination of a sector
+	// migration doesn't have enough free space for the migrated sectors.
+	errMigrationNoSpace = errors.New("destination storage folder does not have enough free space for the migration")
+
+	// errMigrationSameFolder is returned if the source and the destination of
+	// a sector migration are the same storage folder.
+	errMigrationSameFolder = errors.New("source and destination of a sector migration must be different storage folders")
+)
+
+type (
+	// storageFolderMigration records the progress of a sector migration in
+	// the WAL. Sectors are moved from the Source to the Destination storage
+	// folder, or between all storage folders to even out their utilization
+	// if Rebalance is set. Sectors is the number of sectors to move, 0 means
+	// that all of the sectors in the Source are moved. A migration which is
+	// Finished is removed from the state. A migration which failed keeps its
+	// Err and stays in the state until it is retried.
+	storageFolderMigration struct {
+		Source      uint16
+		Destination uint16
+		Rebalance   bool
+		Sectors     uint64
+		Moved       uint64
+		Finished    bool
+		Err         string
+	}
+)
+
+// commitStorageFolderMigration commits the progress of a sector migration to
+// the state.
+func (wal *writeAheadLog) commitStorageFolderMigration(sfm storageFolderMigration) {
+	if sfm.Finished {
+		wal.cm.migration = nil
+		return
+	}
+	wal.cm.migration = &sfm
+}
+
+// updateMigration appends the progress of a sector migration to the WAL and
+// applies it to the state.
+func (wal *writeAheadLog) updateMigration(sfm storageFolderMigration) {
+	wal.appendChange(stateChange{
+		StorageFolderMigrations: []storageFolderMigration{sfm},
+	})
+	wal.commitStorageFolderMigration(sfm)
+}
+
+// managedMigrationMoved records that a sector of the current migration was
+// moved.
+func (wal *writeAheadLog) managedMigrationMoved() {
+	wal.mu.Lock()
+	defer wal.mu.Unlock()
+	sfm := *wal.cm.migration
+	sfm.Moved++
+	wal.updateMigration(sfm)
+}
+
+// folderSectors returns the ids of the sectors stored in a storage folder.
+func (cm *ContractManager) folderSectors(index uint16) []sectorID {
+	var ids []sectorID
+	for id, sl := range cm.sectorLocations {
+		if sl.storageFolder == index {
+			ids = append(ids, id)
+		}
+	}
+	return ids
+}
+
+// managedMigrateSectors moves the sectors out of a storage folder. The
+// destinations are queried for every sector. The progress is reported in
+// bytes out of total sectors, of which done sectors were already moved by a
+// previous run of the migration.
+func (cm *ContractManager) managedMigrateSectors(sf *storageFolder, ids []sectorID, done, total uint64, destinations func() []*storageFolder) error {
+	// Lock the storage folder for the duration of the operation so that no
+	// new sectors are added to it.
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	atomic.StoreUint64(&sf.atomicProgressNumerator, done*modules.SectorSize)
+	atomic.StoreUint64(&sf.atomicProgressDenominator, total*modules.SectorSize)
+	defer func() {
+		atomic.StoreUint64(&sf.atomicProgressNumerator, 0)
+		atomic.StoreUint64(&sf.atomicProgressDenominator, 0)
+	}()
+
+	for _, id := range ids {
+		// The thread group is only held while a sector is moved to not block
+		// shutdown for the duration of the migration.
+		if err := cm.tg.Add(); err != nil {
+			return errMigrationInterrupted
+		}
+		dsts := destinations()
+		if len(dsts) == 0 {
+			// There are no storage folders left to move sectors to.
+			cm.tg.Done()
+			return nil
+		}
+		if cm.dependencies.Disrupt("migrationFailure") {
+			cm.tg.Done()
+			return errors.New("sector migration failed")
+		}
+		err := cm.wal.managedMoveSectorTo(id, dsts)
+		if err != nil {
+			// Sectors which were removed in the meantime are skipped.
+			cm.wal.mu.Lock()
+			sl, exists := cm.sectorLocations[id]
+			cm.wal.mu.Unlock()
+			if exists && sl.storageFolder == sf.index {
+				cm.tg.Done()
+				return err
+			}
+		} else {
+			cm.wal.managedMigrationMoved()
+			atomic.AddUint64(&sf.atomicProgressNumerator, modules.SectorSize)
+		}
+		cm.tg.Done()
+	}
+	return nil
+}
+
+// managedMigrate moves the sectors of a migration from its source to its
+// destination storage folder.
+func (cm *ContractManager) managedMigrate(sfm storageFolderMigration) error {
+	cm.wal.mu.Lock()
+	src, exists1 := cm.storageFolders[sfm.Source]
+	dst, exists2 := cm.storageFolders[sfm.Destination]
+	if !exists1 || !exists2 {
+		cm.wal.mu.Unlock()
+		return errStorageFolderNotFound
+	}
+	ids := cm.folderSectors(sfm.Source)
+	cm.wal.mu.Unlock()
+
+	total := sfm.Moved + uint64(len(ids))
+	if sfm.Sectors != 0 {
+		if sfm.Moved >= sfm.Sectors {
+			return nil
+		}
+		if remaining := sfm.Sectors - sfm.Moved; uint64(len(ids)) > remaining {
+			ids = ids[:remaining]
+		}
+		total = sfm.Sectors
+	}
+	return cm.managedMigrateSectors(src, ids, sfm.Moved, total, func() []*storageFolder {
+		return []*storageFolder{dst}
+	})
+}
+
+// managedRebalance moves sectors between the storage folders until all of them
+// have about the same utilization.
+func (cm *ContractManager) managedRebalance(sfm storageFolderMigration) error {
+	if err := cm.tg.Add(); err != nil {
+		return errMigrationInterrupted
+	}
+	// Compute the number of sectors that every storage folder stores in
+	// excess of the average utilization.
+	cm.wal.mu.Lock()
+	sfs := cm.availableStorageFolders()
+	var used, capacity uint64
+	for _, sf := range sfs {
+		used += sf.sectors
+		capacity += uint64(len(sf.usage)) * storageFolderGranularity
+	}
+	if capacity == 0 {
+		cm.wal.mu.Unlock()
+		cm.tg.Done()
+		return nil
+	}
+	target := func(sf *storageFolder) uint64 {
+		return used * uint64(len(sf.usage)) * storageFolderGranularity / capacity
+	}
+	excess := make(map[uint16]uint64)
+	var total uint64
+	for _, sf := range sfs {
+		if t := target(sf); sf.sectors > t+1 {
+			excess[sf.index] = sf.sectors - t
+			total += sf.sectors - t
+		}
+	}
+	// Record the number of sectors that need to be moved for the progress
+	// report.
+	sfm.Sectors = sfm.Moved + total
+	cm.wal.updateMigration(sfm)
+	cm.wal.mu.Unlock()
+	cm.tg.Done()
+
+	// Move the excess sectors of the most utilized storage folders first.
+	sort.Slice(sfs, func(i, j int) bool {
+		return excess[sfs[i].index] > excess[sfs[j].index]
+	})
+	for _, src := range sfs {
+		if excess[src.index] == 0 {
+			continue
+		}
+		cm.wal.mu.Lock()
+		ids := cm.folderSectors(src.index)
+		cm.wal.mu.Unlock()
+		if uint64(len(ids)) > excess[src.index] {
+			ids = ids[:excess[src.index]]
+		}
+		// The sectors are moved to the storage folders which are below the
+		// average utilization.
+		destinations := func() []*storageFolder {
+			cm.wal.mu.Lock()
+			defer cm.wal.mu.Unlock()
+			var dsts []*storageFolder
+			for _, sf := range sfs {
+				if sf != src && sf.sectors < target(sf) {
+					dsts = append(dsts, sf)
+				}
+			}
+			return dsts
+		}
+		err := cm.managedMigrateSectors(src, ids, 0, uint64(len(ids)), destinations)
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// threadedMigrate runs the current sector migration to completion. An
+// interrupted migration is resumed by the next call to threadedMigrate.
+func (cm *ContractManager) threadedMigrate() {
+	cm.wal.mu.Lock()
+	if cm.migration == nil {
+		cm.wal.mu.Unlock()
+		return
+	}
+	sfm := *cm.migration
+	cm.wal.mu.Unlock()
+
+	var err error
+	if sfm.Rebalance {
+		err = cm.managedRebalance(sfm)
+	} else {
+		err = cm.managedMigrate(sfm)
+	}
+	if err == errMigrationInterrupted {
+		return
+	}
+	if tgErr := cm.tg.Add(); tgErr != nil {
+		return
+	}
+	defer cm.tg.Done()
+
+	// A failed migration stays in the state with its error so that it can be
+	// retried, a successful migration is removed from the state.
+	cm.wal.mu.Lock()
+	defer cm.wal.mu.Unlock()
+	sfm = *cm.migration
+	if err != nil {
+		sfm.Err = err.Error()
+		cm.wal.updateMigration(sfm)
+		cm.log.Println("ERROR: unable to complete sector migration:", err)
+		return
+	}
+	sfm.Err = ""
+	sfm.Finished = true
+	cm.wal.updateMigration(sfm)
+	cm.log.Printf("Finished sector migration, moved %v sectors\n", sfm.Moved)
+}
+
+// managedStartMigration records a new sector migration in the WAL and starts
+// it in the background once the WAL is synced. A failed migration is resumed
+// if the new migration moves sectors between the same storage folders, and
+// replaced otherwise.
+func (cm *ContractManager) managedStartMigration(sfm storageFolderMigration) error {
+	cm.wal.mu.Lock()
+	if cm.migration != nil {
+		if cm.migration.Err == "" {
+			cm.wal.mu.Unlock()
+			return errMigrationInProgress
+		}
+		old := *cm.migration
+		if old.Source == sfm.Source && old.Destination == sfm.Destination && old.Rebalance == sfm.Rebalance {
+			sfm = old
+			sfm.Err = ""
+		}
+	}
+	cm.wal.updateMigration(sfm)
+	syncChan := cm.wal.syncChan
+	cm.wal.mu.Unlock()
+	<-syncChan
+
+	go cm.threadedMigrate()
+	return nil
+}
+
+// MigrateSectors moves sectors from the source storage folder to the
+// destination storage folder in the background. If numSectors is 0, all of
+// the sectors in the source are moved and the source does not receive new
+// sectors until the migration is complete. The progress is reported in the
+// metadata of the source storage folder.
+func (cm *ContractManager) MigrateSectors(source, destination uint16, numSectors uint64) error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	if source == destination {
+		return errMigrationSameFolder
+	}
+
+	cm.wal.mu.Lock()
+	src, exists1 := cm.storageFolders[source]
+	dst, exists2 := cm.storageFolders[destination]
+	if !exists1 || !exists2 || atomic.LoadUint64(&src.atomicUnavailable) == 1 || atomic.LoadUint64(&dst.atomicUnavailable) == 1 {
+		cm.wal.mu.Unlock()
+		return errStorageFolderNotFound
+	}
+	if numSectors > src.sectors {
+		numSectors = src.sectors
+	}
+	sectors := numSectors
+	if sectors == 0 {
+		sectors = src.sectors
+	}
+	free := uint64(len(dst.usage))*storageFolderGranularity - dst.sectors
+	cm.wal.mu.Unlock()
+	if sectors > free {
+		return errMigrationNoSpace
+	}
+
+	return cm.managedStartMigration(storageFolderMigration{
+		Source:      source,
+		Destination: destination,
+		Sectors:     numSectors,
+	})
+}
+
+// RebalanceStorageFolders moves sectors between the storage folders in the
+// background until all of them have about the same utilization. The progress
+// is reported in the metadata of the storage folders that sectors are moved
+// out of.
+func (cm *ContractManager) RebalanceStorageFolders() error {
+	err := cm.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer cm.tg.Done()
+	return cm.managedStartMigration(storageFolderMigration{
+		Rebalance: true,
+	})
+}
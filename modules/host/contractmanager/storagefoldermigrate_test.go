@@ -0,0 +1,262 @@
+package contractmanager
+
+import (
+	"bytes"
+	"errors"
+	"os"
+	"path/filepath"
+	"sync"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// folderSectorCounts returns the number of sectors stored in each storage
+// folder of the contract manager tester, indexed by the folder's path.
+func (cmt *contractManagerTester) folderSectorCounts() map[string]uint64 {
+	counts := make(map[string]uint64)
+	for _, sf := range cmt.cm.StorageFolders() {
+		counts[sf.Path] = (sf.Capacity - sf.CapacityRemaining) / modules.SectorSize
+	}
+	return counts
+}
+
+// waitForMigration waits until the current sector migration is finished.
+func (cmt *contractManagerTester) waitForMigration() error {
+	return build.Retry(100, 100*time.Millisecond, func() error {
+		cmt.cm.wal.mu.Lock()
+		defer cmt.cm.wal.mu.Unlock()
+		if cmt.cm.migration != nil {
+			return errors.New("migration still in progress")
+		}
+		return nil
+	})
+}
+
+// TestMigrateSectors checks that sectors can be moved between storage folders
+// and that an interrupted migration is resumed after a restart.
+func TestMigrateSectors(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	cmt, err := newContractManagerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add a storage folder with some sectors, then add an empty storage
+	// folder.
+	dirA := filepath.Join(cmt.persistDir, "storageFolderA")
+	dirB := filepath.Join(cmt.persistDir, "storageFolderB")
+	for _, dir := range []string{dirA, dirB} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(dirA, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	sectors := make(map[crypto.Hash][]byte)
+	for i := 0; i < 10; i++ {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+		sectors[root] = data
+	}
+	err = cmt.cm.AddStorageFolder(dirB, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexA, indexB uint16
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Path == dirA {
+			indexA = sf.Index
+		} else {
+			indexB = sf.Index
+		}
+	}
+
+	// Check the argument validation.
+	if err := cmt.cm.MigrateSectors(indexA, indexA, 0); err != errMigrationSameFolder {
+		t.Fatal("expected errMigrationSameFolder but got", err)
+	}
+	if err := cmt.cm.MigrateSectors(indexA, indexA+indexB+1, 0); err != errStorageFolderNotFound {
+		t.Fatal("expected errStorageFolderNotFound but got", err)
+	}
+
+	// Move some of the sectors.
+	if err := cmt.cm.MigrateSectors(indexA, indexB, 4); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.waitForMigration(); err != nil {
+		t.Fatal(err)
+	}
+	if counts := cmt.folderSectorCounts(); counts[dirA] != 6 || counts[dirB] != 4 {
+		t.Fatal("unexpected sector counts after migration", counts)
+	}
+
+	// Start moving all of the remaining sectors, but block the migration
+	// before it starts by holding the lock of the source folder.
+	cmt.cm.wal.mu.Lock()
+	sfA := cmt.cm.storageFolders[indexA]
+	cmt.cm.wal.mu.Unlock()
+	sfA.mu.RLock()
+	if err := cmt.cm.MigrateSectors(indexA, indexB, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.cm.RebalanceStorageFolders(); err != errMigrationInProgress {
+		t.Fatal("expected errMigrationInProgress but got", err)
+	}
+
+	// Restart the contract manager, the migration should resume.
+	err = cmt.cm.Close()
+	sfA.mu.RUnlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm, err = New(filepath.Join(cmt.persistDir, modules.ContractManagerDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.waitForMigration(); err != nil {
+		t.Fatal(err)
+	}
+	if counts := cmt.folderSectorCounts(); counts[dirA] != 0 || counts[dirB] != 10 {
+		t.Fatal("unexpected sector counts after resumed migration", counts)
+	}
+
+	// Rebalance the storage folders.
+	if err := cmt.cm.RebalanceStorageFolders(); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.waitForMigration(); err != nil {
+		t.Fatal(err)
+	}
+	if counts := cmt.folderSectorCounts(); counts[dirA] != 5 || counts[dirB] != 5 {
+		t.Fatal("unexpected sector counts after rebalance", counts)
+	}
+
+	// All of the sectors should still be readable.
+	for root, data := range sectors {
+		readData, err := cmt.cm.ReadSector(root)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if !bytes.Equal(readData, data) {
+			t.Fatal("sector data changed during migration")
+		}
+	}
+}
+
+// dependencyMigrationFailure is a mocked dependency that makes sector
+// migrations fail while it is triggered.
+type dependencyMigrationFailure struct {
+	modules.ProductionDependencies
+	triggered bool
+	mu        sync.Mutex
+}
+
+// Disrupt fails the migration of every sector while the dependency is
+// triggered.
+func (d *dependencyMigrationFailure) Disrupt(s string) bool {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	return s == "migrationFailure" && d.triggered
+}
+
+// TestMigrateSectorsFailure checks that a failed migration is not reported as
+// finished and that it can be retried.
+func TestMigrateSectorsFailure(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	d := &dependencyMigrationFailure{triggered: true}
+	cmt, err := newMockedContractManagerTester(d, t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer cmt.panicClose()
+
+	// Add two storage folders and put some sectors into the first one.
+	dirA := filepath.Join(cmt.persistDir, "storageFolderA")
+	dirB := filepath.Join(cmt.persistDir, "storageFolderB")
+	for _, dir := range []string{dirA, dirB} {
+		if err := os.MkdirAll(dir, 0700); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(dirA, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i := 0; i < 4; i++ {
+		root, data := randSector()
+		if err := cmt.cm.AddSector(root, data); err != nil {
+			t.Fatal(err)
+		}
+	}
+	err = cmt.cm.AddStorageFolder(dirB, modules.SectorSize*storageFolderGranularity*2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var indexA, indexB uint16
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.Path == dirA {
+			indexA = sf.Index
+		} else {
+			indexB = sf.Index
+		}
+	}
+
+	// The migration should fail and stay in the state with its error.
+	if err := cmt.cm.MigrateSectors(indexA, indexB, 0); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		for _, sf := range cmt.cm.StorageFolders() {
+			if sf.Path == dirA && sf.MigrationError != "" {
+				return nil
+			}
+		}
+		return errors.New("migration error not reported")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cmt.cm.wal.mu.Lock()
+	sfm := cmt.cm.migration
+	cmt.cm.wal.mu.Unlock()
+	if sfm == nil || sfm.Finished {
+		t.Fatal("failed migration should not be finished", sfm)
+	}
+	if counts := cmt.folderSectorCounts(); counts[dirA] != 4 || counts[dirB] != 0 {
+		t.Fatal("unexpected sector counts after failed migration", counts)
+	}
+
+	// Retry the migration, it should complete now.
+	d.mu.Lock()
+	d.triggered = false
+	d.mu.Unlock()
+	if err := cmt.cm.MigrateSectors(indexA, indexB, 0); err != nil {
+		t.Fatal(err)
+	}
+	if err := cmt.waitForMigration(); err != nil {
+		t.Fatal(err)
+	}
+	if counts := cmt.folderSectorCounts(); counts[dirA] != 0 || counts[dirB] != 4 {
+		t.Fatal("unexpected sector counts after retried migration", counts)
+	}
+	for _, sf := range cmt.cm.StorageFolders() {
+		if sf.MigrationError != "" {
+			t.Fatal("migration error still reported after retry", sf.MigrationError)
+		}
+	}
+}
@@ -52,6 +52,7 @@ type (
 		ErroredStorageFolderExtensions    []uint16
 		StorageFolderAdditions            []savedStorageFolder
 		StorageFolderExtensions           []storageFolderExtension
+		StorageFolderMigrations           []storageFolderMigration
 		StorageFolderRemovals             []storageFolderRemoval
 		StorageFolderReductions           []storageFolderReduction
 		UnfinishedStorageFolderAdditions  []savedStorageFolder
@@ -192,6 +193,11 @@ func (wal *writeAheadLog) commitChange(sc stateChange) {
 			wal.commitUpdateSector(su)
 		}
 	}
+	for _, sfm := range sc.StorageFolderMigrations {
+		for i := uint64(0); i < wal.cm.dependencies.AtLeastOne(); i++ {
+			wal.commitStorageFolderMigration(sfm)
+		}
+	}
 }
 
 // createWALTmp will open up the temporary WAL file.
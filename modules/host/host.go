@@ -67,6 +67,7 @@ import (
 	"errors"
 	"fmt"
 	"net"
+	"net/http"
 	"path/filepath"
 	"sync"
 	"time"
@@ -152,6 +153,7 @@ type Host struct {
 
 	// Subsystems
 	staticAccountManager *accountManager
+	staticClients        *clientTracker
 
 	// Host ACID fields - these fields need to be updated in serial, ACID
 	// transactions.
@@ -171,6 +173,16 @@ type Host struct {
 	workingStatus        modules.HostWorkingStatus
 	connectabilityStatus modules.HostConnectabilityStatus
 
+	// maintenanceStart and maintenanceEnd are the start and the planned end of
+	// the host's maintenance mode. Both are zero if the host is not in
+	// maintenance mode.
+	maintenanceStart time.Time
+	maintenanceEnd   time.Time
+
+	// collateralForecast is the latest projection of the wallet funds needed
+	// by the host's storage obligations.
+	collateralForecast modules.HostCollateralForecast
+
 	// A map of storage obligations that are currently being modified. Locks on
 	// storage obligations can be long-running, and each storage obligation can
 	// be locked separately.
@@ -183,6 +195,22 @@ type Host struct {
 	// are congestion, load, liquidity, etc.
 	priceTable modules.RPCPriceTable
 
+	// webSocketServer serves the renter-host protocol over WebSocket on
+	// webSocketListener. Both are nil if the WebSocket listener is disabled.
+	// webSocketTLS indicates whether the listener uses TLS.
+	webSocketListener net.Listener
+	webSocketServer   *http.Server
+	webSocketTLS      bool
+
+	// market provides the prices of the other hosts on the network to the
+	// auto-pricing engine. It is nil if the node doesn't run a renter.
+	market modules.HostMarket
+
+	// autoPrices are the prices computed by the auto-pricing engine. They
+	// are only charged while the engine is enabled and if they are higher
+	// than the min prices in the settings.
+	autoPrices hostPrices
+
 	// Misc state.
 	db            *persist.BoltDatabase
 	listener      net.Listener
@@ -196,8 +224,8 @@ type Host struct {
 
 // checkUnlockHash will check that the host has an unlock hash. If the host
 // does not have an unlock hash, an attempt will be made to get an unlock hash
-// from the wallet. That may fail due to the wallet being locked, in which case
-// an error is returned.
+// from the wallet account of the host. That may fail due to the wallet being
+// locked, in which case an error is returned.
 func (h *Host) checkUnlockHash() error {
 	addrs, err := h.wallet.AllAddresses()
 	if err != nil {
@@ -211,7 +239,7 @@ func (h *Host) checkUnlockHash() error {
 		}
 	}
 	if !hasAddr || h.unlockHash == (types.UnlockHash{}) {
-		uc, err := h.wallet.NextAddress()
+		uc, err := h.wallet.NextAccountAddress(h.settings.WalletAccount)
 		if err != nil {
 			return err
 		}
@@ -238,12 +266,14 @@ func (h *Host) managedUpdatePriceTable() {
 	priceTable.Costs[modules.RPCUpdatePriceTable] = h.managedCalculateUpdatePriceTableRPCPrice()
 
 	// TODO: hardcoded MDM costs, needs a better place
-	his := h.InternalSettings()
+	h.mu.RLock()
+	prices := h.prices()
+	h.mu.RUnlock()
 	priceTable.Costs[modules.MDMComponentCompute] = types.ZeroCurrency
 	priceTable.Costs[modules.MDMComponentMemory] = types.ZeroCurrency
 	priceTable.Costs[modules.MDMOperationDiskAccess] = types.ZeroCurrency
-	priceTable.Costs[modules.MDMOperationDiskRead] = his.MinBaseRPCPrice
-	priceTable.Costs[modules.MDMOperationDiskWrite] = his.MinBaseRPCPrice
+	priceTable.Costs[modules.MDMOperationDiskRead] = prices.BaseRPCPrice
+	priceTable.Costs[modules.MDMOperationDiskWrite] = prices.BaseRPCPrice
 
 	// update the pricetable
 	h.mu.Lock()
@@ -341,6 +371,12 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 		}
 	})
 
+	// Configure the sector cache of the storage manager.
+	err = h.StorageManager.SetSectorCache(h.settings.SectorCacheSize, h.settings.SectorCacheDir)
+	if err != nil {
+		h.log.Println("Could not configure the sector cache:", err)
+	}
+
 	// Add the account manager subsystem
 	h.staticAccountManager, err = h.newAccountManager()
 	if err != nil {
@@ -362,6 +398,10 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 	// Create bandwidth monitor
 	h.staticMonitor = connmonitor.NewMonitor()
 
+	// Create the tracker of the per-client limits.
+	h.staticClients = newClientTracker()
+	h.staticClients.managedSetLimits(h.settings)
+
 	// Initialize the networking. We need to hold the lock while doing so since
 	// the previous load subscribed the host to the consensus set.
 	h.mu.Lock()
@@ -375,6 +415,15 @@ func newHost(dependencies modules.Dependencies, smDeps modules.Dependencies, cs
 	// Initialize the RPC price table.
 	h.managedUpdatePriceTable()
 
+	// Start the auto-pricing engine.
+	go h.threadedAutoPricing()
+
+	// Start the collateral planner.
+	go h.threadedCollateralPlanner()
+
+	// Start tracking the corrupt sectors found by the scrubber.
+	go h.threadedTrackCorruptSectors()
+
 	return h, nil
 }
 
@@ -473,21 +522,56 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 	h.mu.Lock()
 	defer h.mu.Unlock()
 
-	// The host should not be accepting file contracts if it does not have an
-	// unlock hash.
-	if settings.AcceptingContracts {
+	// Payouts go to the wallet account of the host, so the unlock hash has to
+	// be replaced if the account changes. The host should not be accepting
+	// file contracts if it does not have an unlock hash.
+	var accountUC types.UnlockConditions
+	accountChanged := settings.WalletAccount != h.settings.WalletAccount
+	if accountChanged {
+		accountUC, err = h.wallet.NextAccountAddress(settings.WalletAccount)
+		if err != nil {
+			return errors.New("internal settings not updated, invalid wallet account: " + err.Error())
+		}
+	} else if settings.AcceptingContracts {
 		err := h.checkUnlockHash()
 		if err != nil {
 			return errors.New("internal settings not updated, no unlock hash: " + err.Error())
 		}
 	}
 
+	if settings.AutoPricing.Enabled {
+		err := validateAutoPricingSettings(settings.AutoPricing)
+		if err != nil {
+			return errors.New("internal settings not updated, invalid auto-pricing settings: " + err.Error())
+		}
+	}
+
 	if settings.NetAddress != "" {
 		err := settings.NetAddress.IsValid()
 		if err != nil {
 			return errors.New("internal settings not updated, invalid NetAddress: " + err.Error())
 		}
 	}
+	if len(settings.AlternateNetAddresses) > 0 {
+		err := verifyAlternateAddresses(settings.NetAddress, settings.AlternateNetAddresses)
+		if err != nil {
+			return errors.New("internal settings not updated, invalid AlternateNetAddresses: " + err.Error())
+		}
+	}
+
+	if settings.SectorCacheSize != h.settings.SectorCacheSize || settings.SectorCacheDir != h.settings.SectorCacheDir {
+		err := h.StorageManager.SetSectorCache(settings.SectorCacheSize, settings.SectorCacheDir)
+		if err != nil {
+			return errors.New("internal settings not updated, invalid sector cache: " + err.Error())
+		}
+	}
+
+	if webSocketSettingsChanged(h.settings, settings) {
+		err := h.updateWebSocketListener(settings)
+		if err != nil {
+			return errors.New("internal settings not updated, invalid WebSocket listener: " + err.Error())
+		}
+	}
 
 	// Check if the net address for the host has changed. If it has, and it's
 	// not equal to the auto address, then the host is going to need to make
@@ -495,9 +579,16 @@ func (h *Host) SetInternalSettings(settings modules.HostInternalSettings) error
 	if h.settings.NetAddress != settings.NetAddress && settings.NetAddress != h.autoAddress {
 		h.announced = false
 	}
+	if !equalNetAddresses(h.settings.AlternateNetAddresses, settings.AlternateNetAddresses) {
+		h.announced = false
+	}
 
+	if accountChanged {
+		h.unlockHash = accountUC.UnlockHash()
+	}
 	h.settings = settings
 	h.revisionNumber++
+	h.staticClients.managedSetLimits(settings)
 
 	// The locked storage collateral was altered, we potentially want to
 	// unregister the insufficient collateral budget alert
@@ -0,0 +1,148 @@
+package host
+
+// maintenance.go implements the maintenance mode of the host. A host that
+// needs disk maintenance can enter maintenance mode to drain its workload.
+// While in maintenance mode the host refuses new contracts, renewals and
+// writes, but it keeps serving reads and submitting storage proofs so that
+// the existing contracts are not put at risk.
+
+import (
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errMaintenance is returned to renters which try to form or renew a
+	// contract or write data while the host is in maintenance mode.
+	errMaintenance = ErrorCommunication("host is in maintenance mode and not accepting new contracts, renewals or writes")
+
+	// errMaintenanceActive is returned if maintenance mode is started while
+	// the host is already in maintenance mode.
+	errMaintenanceActive = errors.New("host is already in maintenance mode")
+
+	// errMaintenanceDuration is returned if maintenance mode is started
+	// without a planned duration.
+	errMaintenanceDuration = errors.New("maintenance duration must be greater than 0")
+
+	// errMaintenanceInactive is returned if maintenance mode is stopped while
+	// the host is not in maintenance mode.
+	errMaintenanceInactive = errors.New("host is not in maintenance mode")
+)
+
+// inMaintenance returns true if the host is in maintenance mode.
+func (h *Host) inMaintenance() bool {
+	return !h.maintenanceStart.IsZero()
+}
+
+// managedInMaintenance returns true if the host is in maintenance mode.
+func (h *Host) managedInMaintenance() bool {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.inMaintenance()
+}
+
+// MaintenanceStatus returns whether the host is in maintenance mode and
+// which unresolved storage obligations have a proof window that overlaps
+// with the planned downtime.
+func (h *Host) MaintenanceStatus() (modules.HostMaintenanceStatus, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return modules.HostMaintenanceStatus{}, err
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	status := modules.HostMaintenanceStatus{
+		Active:      h.inMaintenance(),
+		StartTime:   h.maintenanceStart,
+		EndTime:     h.maintenanceEnd,
+		BlockHeight: h.blockHeight,
+	}
+	h.mu.RUnlock()
+	if !status.Active {
+		return status, nil
+	}
+
+	// Estimate the height at the end of the downtime, rounding up to not miss
+	// any proof windows.
+	status.EndHeight = status.BlockHeight
+	if remaining := time.Until(status.EndTime); remaining > 0 {
+		blockTime := time.Duration(types.BlockFrequency) * time.Second
+		status.EndHeight += types.BlockHeight((remaining + blockTime - 1) / blockTime)
+	}
+	for _, so := range h.StorageObligations() {
+		if so.ObligationStatus != obligationUnresolved.String() {
+			continue
+		}
+		if so.ExpirationHeight <= status.EndHeight && so.ProofDeadLine >= status.BlockHeight {
+			status.Obligations = append(status.Obligations, so)
+		}
+	}
+	return status, nil
+}
+
+// StartMaintenance puts the host into maintenance mode for the planned
+// duration. The mode persists across restarts and is only left by calling
+// StopMaintenance, even if the planned duration has passed.
+func (h *Host) StartMaintenance(duration time.Duration) error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+	if duration <= 0 {
+		return errMaintenanceDuration
+	}
+
+	h.mu.Lock()
+	if h.inMaintenance() {
+		h.mu.Unlock()
+		return errMaintenanceActive
+	}
+	h.maintenanceStart = time.Now()
+	h.maintenanceEnd = h.maintenanceStart.Add(duration)
+	// The host's external settings change because the host is no longer
+	// accepting contracts.
+	h.revisionNumber++
+	err = h.saveSync()
+	if err != nil {
+		h.maintenanceStart, h.maintenanceEnd = time.Time{}, time.Time{}
+		h.mu.Unlock()
+		return errors.AddContext(err, "unable to save maintenance mode")
+	}
+	end := h.maintenanceEnd
+	h.mu.Unlock()
+	h.log.Printf("Entered maintenance mode, planned end at %v", end.Format(time.RFC3339))
+	return nil
+}
+
+// StopMaintenance takes the host out of maintenance mode.
+func (h *Host) StopMaintenance() error {
+	err := h.tg.Add()
+	if err != nil {
+		return err
+	}
+	defer h.tg.Done()
+
+	h.mu.Lock()
+	if !h.inMaintenance() {
+		h.mu.Unlock()
+		return errMaintenanceInactive
+	}
+	start, end := h.maintenanceStart, h.maintenanceEnd
+	h.maintenanceStart, h.maintenanceEnd = time.Time{}, time.Time{}
+	h.revisionNumber++
+	err = h.saveSync()
+	if err != nil {
+		h.maintenanceStart, h.maintenanceEnd = start, end
+		h.mu.Unlock()
+		return errors.AddContext(err, "unable to save maintenance mode")
+	}
+	h.mu.Unlock()
+	h.log.Printf("Left maintenance mode after %v", time.Since(start).Round(time.Second))
+	return nil
+}
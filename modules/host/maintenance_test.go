@@ -0,0 +1,115 @@
+package host
+
+import (
+	"path/filepath"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestMaintenance checks that the host refuses contracts while in maintenance
+// mode, reports the obligations with a proof window during the downtime and
+// persists the mode across restarts.
+func TestMaintenance(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	settings := ht.host.InternalSettings()
+	settings.AcceptingContracts = true
+	if err := ht.host.SetInternalSettings(settings); err != nil {
+		t.Fatal(err)
+	}
+
+	// Add a storage obligation whose proof window starts a few blocks from
+	// now.
+	so, err := ht.newTesterStorageObligation()
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host.managedLockStorageObligation(so.id())
+	err = ht.host.managedAddStorageObligation(so)
+	ht.host.managedUnlockStorageObligation(so.id())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// The host isn't in maintenance mode yet.
+	if err := ht.host.StopMaintenance(); err != errMaintenanceInactive {
+		t.Fatal("expected errMaintenanceInactive but got", err)
+	}
+	status, err := ht.host.MaintenanceStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Active || len(status.Obligations) != 0 {
+		t.Fatalf("unexpected maintenance status %+v", status)
+	}
+
+	// Start the maintenance mode with a downtime that covers the proof
+	// window.
+	downtime := time.Duration(so.expiration()-ht.host.blockHeight+1) * time.Duration(types.BlockFrequency) * time.Second
+	if err := ht.host.StartMaintenance(0); err != errMaintenanceDuration {
+		t.Fatal("expected errMaintenanceDuration but got", err)
+	}
+	if err := ht.host.StartMaintenance(downtime); err != nil {
+		t.Fatal(err)
+	}
+	if err := ht.host.StartMaintenance(downtime); err != errMaintenanceActive {
+		t.Fatal("expected errMaintenanceActive but got", err)
+	}
+	if ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host in maintenance mode shouldn't accept contracts")
+	}
+	status, err = ht.host.MaintenanceStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Active || status.EndHeight < so.expiration() {
+		t.Fatalf("unexpected maintenance status %+v", status)
+	}
+	if len(status.Obligations) != 1 || status.Obligations[0].ObligationId != so.id() {
+		t.Fatal("obligation should have a proof window during the downtime", status.Obligations)
+	}
+
+	// Reload the host and verify that it is still in maintenance mode.
+	err = ht.host.Close()
+	if err != nil {
+		t.Fatal(err)
+	}
+	rebootHost, err := New(ht.cs, ht.gateway, ht.tpool, ht.wallet, "localhost:0", filepath.Join(ht.persistDir, modules.HostDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	ht.host = rebootHost
+	status, err = ht.host.MaintenanceStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !status.Active {
+		t.Fatal("maintenance mode should persist")
+	}
+
+	// Stop the maintenance mode.
+	if err := ht.host.StopMaintenance(); err != nil {
+		t.Fatal(err)
+	}
+	if !ht.host.ExternalSettings().AcceptingContracts {
+		t.Fatal("host should accept contracts again")
+	}
+	status, err = ht.host.MaintenanceStatus()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if status.Active || len(status.Obligations) != 0 {
+		t.Fatalf("unexpected maintenance status %+v", status)
+	}
+}
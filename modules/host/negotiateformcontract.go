@@ -38,7 +38,10 @@ func (h *Host) managedAddCollateral(settings modules.HostExternalSettings, txnSe
 	parents := txnSet[:len(txnSet)-1]
 	fc := txn.FileContracts[0]
 	hostPortion := contractCollateral(settings, fc)
-	builder, err = h.wallet.RegisterTransaction(txn, parents)
+	h.mu.RLock()
+	account := h.settings.WalletAccount
+	h.mu.RUnlock()
+	builder, err = h.wallet.RegisterAccountTransaction(account, txn, parents)
 	if err != nil {
 		return
 	}
@@ -293,6 +296,11 @@ func (h *Host) managedVerifyNewContract(txnSet []types.Transaction, renterPK cry
 		registerHostInsufficientCollateral = true
 		return errCollateralBudgetExceeded
 	}
+	// Check that the wallet is projected to be able to fund the collateral on
+	// top of the renewals and fees of the existing storage obligations.
+	if forecastShortfall(h.managedCollateralForecast(), expectedCollateral) {
+		return errCollateralForecastShortfall
+	}
 
 	// The unlock hash for the file contract must match the unlock hash that
 	// the host knows how to spend.
@@ -44,7 +44,10 @@ func (h *Host) managedAddRenewCollateral(so storageObligation, settings modules.
 	parents := txnSet[:len(txnSet)-1]
 	fc := txn.FileContracts[0]
 	hostPortion := renewContractCollateral(so, settings, fc)
-	builder, err = h.wallet.RegisterTransaction(txn, parents)
+	h.mu.RLock()
+	account := h.settings.WalletAccount
+	h.mu.RUnlock()
+	builder, err = h.wallet.RegisterAccountTransaction(account, txn, parents)
 	if err != nil {
 		return
 	}
@@ -116,8 +119,15 @@ func (h *Host) managedRPCRenewContract(conn net.Conn) error {
 
 	h.mu.Lock()
 	settings := h.externalSettings()
+	maintenance := h.inMaintenance()
 	h.mu.Unlock()
 
+	// Renewals are refused while the host is in maintenance mode.
+	if maintenance {
+		modules.WriteNegotiationRejection(conn, errMaintenance) // Error is ignored to preserve type for extendErr
+		return errMaintenance
+	}
+
 	// Verify that the transaction coming over the wire is a proper renewal.
 	err = h.managedVerifyRenewedContract(so, txnSet, renterPK)
 	if err != nil {
@@ -284,6 +294,12 @@ func (h *Host) managedVerifyRenewedContract(so storageObligation, txnSet []types
 		registerHostInsufficientCollateral = true
 		return errCollateralBudgetExceeded
 	}
+	// Check that the wallet is projected to be able to fund the collateral on
+	// top of the renewals and fees of the existing storage obligations.
+	collateralForecast := h.managedCollateralForecast()
+	if forecastShortfall(collateralForecast, renewalCollateralNeed(collateralForecast, so, expectedCollateral)) {
+		return errCollateralForecastShortfall
+	}
 
 	// Check that the missed proof outputs contain enough money, and that the
 	// void output contains enough money.
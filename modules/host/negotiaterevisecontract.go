@@ -70,6 +70,12 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 		return extendErr("unable to read proposed revision: ", ErrorConnection(err.Error()))
 	}
 
+	// Writes are rejected while the host is in maintenance mode.
+	if h.managedInMaintenance() {
+		modules.WriteNegotiationRejection(conn, errMaintenance) // Error is ignored to preserve type for extendErr
+		return errMaintenance
+	}
+
 	// First read all of the modifications. Then make the modifications, but
 	// with the ability to reverse them. Then verify the file contract revision
 	// correctly accounts for the changes.
@@ -150,7 +156,11 @@ func (h *Host) managedRevisionIteration(conn net.Conn, so *storageObligation, fi
 			}
 		}
 		newRevenue := storageRevenue.Add(bandwidthRevenue)
-		return extendErr("unable to verify updated contract: ", verifyRevision(*so, revision, blockHeight, newRevenue, newCollateral))
+		err := verifyRevision(*so, revision, blockHeight, newRevenue, newCollateral)
+		if err != nil {
+			return extendErr("unable to verify updated contract: ", err)
+		}
+		return nil
 	}()
 	if err != nil {
 		modules.WriteNegotiationRejection(conn, err) // Error is ignored so that the error type can be preserved in extendErr.
@@ -49,10 +49,14 @@ func (h *Host) externalSettings() modules.HostExternalSettings {
 	if unlocked, err := h.wallet.Unlocked(); err != nil || !unlocked {
 		acceptingContracts = false
 	}
-	// If the host's wallet cannot afford to put MaxCollateral coins into a
-	// contract, reduce its advertised MaxCollateral.
+	// A host in maintenance mode is not accepting contracts either.
+	if h.inMaintenance() {
+		acceptingContracts = false
+	}
+	// If the host's wallet account cannot afford to put MaxCollateral coins
+	// into a contract, reduce its advertised MaxCollateral.
 	maxCollateral := h.settings.MaxCollateral
-	balance, _, _, err := h.wallet.ConfirmedBalance()
+	balance, err := h.wallet.ConfirmedAccountBalance(h.settings.WalletAccount)
 	if err != nil {
 		maxCollateral = types.ZeroCurrency
 	}
@@ -65,6 +69,7 @@ func (h *Host) externalSettings() modules.HostExternalSettings {
 		maxCollateral = h.settings.CollateralBudget.Sub(h.financialMetrics.LockedStorageCollateral)
 	}
 
+	prices := h.prices()
 	return modules.HostExternalSettings{
 		AcceptingContracts:   acceptingContracts,
 		MaxDownloadBatchSize: h.settings.MaxDownloadBatchSize,
@@ -80,12 +85,14 @@ func (h *Host) externalSettings() modules.HostExternalSettings {
 		Collateral:    h.settings.Collateral,
 		MaxCollateral: maxCollateral,
 
-		BaseRPCPrice:           h.settings.MinBaseRPCPrice,
+		BaseRPCPrice:           prices.BaseRPCPrice,
 		ContractPrice:          contractPrice,
-		DownloadBandwidthPrice: h.settings.MinDownloadBandwidthPrice,
-		SectorAccessPrice:      h.settings.MinSectorAccessPrice,
-		StoragePrice:           h.settings.MinStoragePrice,
-		UploadBandwidthPrice:   h.settings.MinUploadBandwidthPrice,
+		DownloadBandwidthPrice: prices.DownloadBandwidthPrice,
+		SectorAccessPrice:      prices.SectorAccessPrice,
+		StoragePrice:           prices.StoragePrice,
+		UploadBandwidthPrice:   prices.UploadBandwidthPrice,
+
+		WebSocketURL: h.webSocketURL(netAddr),
 
 		RevisionNumber: h.revisionNumber,
 		Version:        build.Version,
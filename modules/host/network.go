@@ -231,6 +231,18 @@ func (h *Host) initNetworking(address string) (err error) {
 
 	// Launch the listener.
 	go h.threadedListen(threadedListenerClosedChan)
+
+	// Launch the WebSocket listener. A misconfigured WebSocket listener
+	// doesn't prevent the host from starting.
+	err = h.listenWebSocket(h.settings)
+	if err != nil {
+		h.log.Println("ERROR: unable to start the WebSocket listener:", err)
+	}
+	h.tg.OnStop(func() {
+		h.mu.Lock()
+		h.closeWebSocketListener()
+		h.mu.Unlock()
+	})
 	return nil
 }
 
@@ -263,6 +275,11 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 		return
 	}
 
+	// Track the connection by the IP address of the renter to enforce the
+	// per-client limits.
+	ipClient, conn := h.managedConnectIP(conn)
+	defer h.staticClients.managedDisconnect(ipClient)
+
 	// Read the first 16 bytes. If those bytes are RPCLoopEnter, then the
 	// renter is attempting to use the new protocol; otherweise, assume the
 	// renter is using the old protocol, and that the following 8 bytes
@@ -289,10 +306,17 @@ func (h *Host) threadedHandleConn(conn net.Conn) {
 		}
 	}
 
+	// Old RPCs are limited by the IP address of the renter. The RPCs of the
+	// new protocol are limited within the RPC loop.
+	if !h.staticClients.managedAllowRPC(rpcClassOf(id), ipClient) {
+		h.log.Debugf("WARN: incoming conn %v exceeded the rate limit for RPC \"%v\"", conn.RemoteAddr(), id)
+		return
+	}
+
 	switch id {
 	// new RPCs: enter an infinite request/response loop
 	case modules.RPCLoopEnter:
-		err = extendErr("incoming RPCLoopEnter failed: ", h.managedRPCLoop(conn))
+		err = extendErr("incoming RPCLoopEnter failed: ", h.managedRPCLoop(conn, ipClient))
 	// old RPCs: handle a single request/response
 	case modules.RPCDownload:
 		atomic.AddUint64(&h.atomicDownloadCalls, 1)
@@ -2,17 +2,18 @@ package host
 
 import (
 	"encoding/json"
-	"errors"
 	"math/bits"
 	"sort"
 	"sync/atomic"
 	"time"
 
 	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/host/contractmanager"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
 
@@ -159,6 +160,12 @@ func (h *Host) managedRPCLoopWrite(s *rpcSession) error {
 		return err
 	}
 
+	// Writes are rejected while the host is in maintenance mode.
+	if h.managedInMaintenance() {
+		s.writeError(errMaintenance)
+		return errMaintenance
+	}
+
 	// Read some internal fields for later.
 	h.mu.Lock()
 	blockHeight := h.blockHeight
@@ -517,7 +524,13 @@ func (h *Host) managedRPCLoopRead(s *rpcSession) error {
 	for i, sec := range req.Sections {
 		// Fetch the requested data.
 		sectorData, err := h.ReadSector(sec.MerkleRoot)
-		if err != nil {
+		if errors.Contains(err, contractmanager.ErrSectorNotFound) {
+			s.writeError(&modules.RPCError{
+				Type:        modules.RPCErrorSectorNotFound,
+				Description: err.Error(),
+			})
+			return err
+		} else if err != nil {
 			s.writeError(err)
 			return err
 		}
@@ -572,8 +585,12 @@ func (h *Host) managedRPCLoopFormContract(s *rpcSession) error {
 
 	h.mu.Lock()
 	settings := h.externalSettings()
+	maintenance := h.inMaintenance()
 	h.mu.Unlock()
-	if !settings.AcceptingContracts {
+	if maintenance {
+		s.writeError(errMaintenance)
+		return nil
+	} else if !settings.AcceptingContracts {
 		s.writeError(errors.New("host is not accepting new contracts"))
 		return nil
 	}
@@ -651,8 +668,12 @@ func (h *Host) managedRPCLoopRenewContract(s *rpcSession) error {
 
 	h.mu.Lock()
 	settings := h.externalSettings()
+	maintenance := h.inMaintenance()
 	h.mu.Unlock()
-	if !settings.AcceptingContracts {
+	if maintenance {
+		s.writeError(errMaintenance)
+		return nil
+	} else if !settings.AcceptingContracts {
 		s.writeError(errors.New("host is not accepting new contracts"))
 		return nil
 	} else if len(s.so.RevisionTransactionSet) == 0 {
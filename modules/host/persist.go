@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"os"
 	"path/filepath"
+	"time"
 
 	"gitlab.com/NebulousLabs/bolt"
 
@@ -29,6 +30,13 @@ type persistence struct {
 	SecretKey        crypto.SecretKey             `json:"secretkey"`
 	Settings         modules.HostInternalSettings `json:"settings"`
 	UnlockHash       types.UnlockHash             `json:"unlockhash"`
+
+	// Maintenance mode.
+	MaintenanceStart time.Time `json:"maintenancestart"`
+	MaintenanceEnd   time.Time `json:"maintenanceend"`
+
+	// Auto-pricing.
+	AutoPrices hostPrices `json:"autoprices"`
 }
 
 // persistData returns the data in the Host that will be saved to disk.
@@ -47,6 +55,13 @@ func (h *Host) persistData() persistence {
 		SecretKey:        h.secretKey,
 		Settings:         h.settings,
 		UnlockHash:       h.unlockHash,
+
+		// Maintenance mode.
+		MaintenanceStart: h.maintenanceStart,
+		MaintenanceEnd:   h.maintenanceEnd,
+
+		// Auto-pricing.
+		AutoPrices: h.autoPrices,
 	}
 }
 
@@ -60,9 +75,10 @@ func (h *Host) establishDefaults() error {
 		MaxReviseBatchSize:   uint64(defaultMaxReviseBatchSize),
 		WindowSize:           defaultWindowSize,
 
-		Collateral:       defaultCollateral,
-		CollateralBudget: defaultCollateralBudget,
-		MaxCollateral:    defaultMaxCollateral,
+		Collateral:               defaultCollateral,
+		CollateralBudget:         defaultCollateralBudget,
+		CollateralForecastWindow: defaultCollateralForecastWindow,
+		MaxCollateral:            defaultMaxCollateral,
 
 		MinBaseRPCPrice:           defaultBaseRPCPrice,
 		MinContractPrice:          defaultContractPrice,
@@ -107,7 +123,18 @@ func (h *Host) loadPersistObject(p *persistence) {
 		h.log.Printf("WARN: NetAddress '%v' loaded from persist is invalid: %v", p.Settings.NetAddress, err)
 		h.settings.NetAddress = ""
 	}
+	if err := verifyAlternateAddresses(h.settings.NetAddress, p.Settings.AlternateNetAddresses); err != nil {
+		h.log.Printf("WARN: AlternateNetAddresses '%v' loaded from persist are invalid: %v", p.Settings.AlternateNetAddresses, err)
+		h.settings.AlternateNetAddresses = nil
+	}
 	h.unlockHash = p.UnlockHash
+
+	// Copy over the maintenance mode.
+	h.maintenanceStart = p.MaintenanceStart
+	h.maintenanceEnd = p.MaintenanceEnd
+
+	// Copy over the auto-pricing prices.
+	h.autoPrices = p.AutoPrices
 }
 
 // initDB will check that the database has been initialized and if not, will
@@ -130,6 +157,7 @@ func (h *Host) initDB() (err error) {
 		// database needs to be initialized. Create the database buckets.
 		buckets := [][]byte{
 			bucketActionItems,
+			bucketRevisionHistories,
 			bucketStorageObligations,
 		}
 		for _, bucket := range buckets {
@@ -22,6 +22,13 @@ type rpcSession struct {
 	aead      cipher.AEAD
 	so        storageObligation
 	challenge [16]byte
+
+	// ipConn is the connection limited by the renter's IP address. Once the
+	// renter locks a contract, conn additionally enforces the limits of the
+	// renter's public key.
+	ipConn       net.Conn
+	ipClient     *hostClient
+	renterClient *hostClient
 }
 
 // extendDeadline extends the read/write deadline on the underlying connection
@@ -50,10 +57,32 @@ func (s *rpcSession) writeError(err error) error {
 	return modules.WriteRPCResponse(s.conn, s.aead, nil, err)
 }
 
+// managedUpdateRenterClient tracks the session by the public key of the
+// renter of the locked contract. If the renter changed, the session's
+// connection is wrapped to enforce the limits of the new renter.
+func (h *Host) managedUpdateRenterClient(s *rpcSession) {
+	if len(s.so.RevisionTransactionSet) == 0 {
+		return
+	}
+	rev := s.so.RevisionTransactionSet[len(s.so.RevisionTransactionSet)-1].FileContractRevisions[0]
+	if len(rev.UnlockConditions.PublicKeys) == 0 {
+		return
+	}
+	renterKey := rev.UnlockConditions.PublicKeys[0].String()
+	if s.renterClient != nil && s.renterClient.id == renterKey {
+		return
+	}
+	if s.renterClient != nil {
+		h.staticClients.managedDisconnect(s.renterClient)
+	}
+	s.renterClient = h.staticClients.managedConnect(clientTypePublicKey, renterKey)
+	s.conn = s.renterClient.staticWrapConn(s.ipConn, h.tg.StopChan())
+}
+
 // managedRPCLoop reads new RPCs from the renter, each consisting of a single
 // request and response. The loop terminates when the an RPC encounters an
 // error or the renter sends modules.RPCLoopExit.
-func (h *Host) managedRPCLoop(conn net.Conn) error {
+func (h *Host) managedRPCLoop(conn net.Conn, ipClient *hostClient) error {
 	// read renter's half of key exchange
 	conn.SetDeadline(time.Now().Add(rpcRequestInterval))
 	var req modules.LoopKeyExchangeRequest
@@ -98,8 +127,10 @@ func (h *Host) managedRPCLoop(conn net.Conn) error {
 	}
 	// create the session object
 	s := &rpcSession{
-		conn: conn,
-		aead: aead,
+		conn:     conn,
+		aead:     aead,
+		ipConn:   conn,
+		ipClient: ipClient,
 	}
 	fastrand.Read(s.challenge[:])
 
@@ -117,6 +148,11 @@ func (h *Host) managedRPCLoop(conn net.Conn) error {
 			h.managedUnlockStorageObligation(s.so.id())
 		}
 	}()
+	defer func() {
+		if s.renterClient != nil {
+			h.staticClients.managedDisconnect(s.renterClient)
+		}
+	}()
 
 	// enter RPC loop
 	rpcs := map[types.Specifier]func(*rpcSession) error{
@@ -130,8 +166,8 @@ func (h *Host) managedRPCLoop(conn net.Conn) error {
 		modules.RPCLoopSectorRoots:   h.managedRPCLoopSectorRoots,
 	}
 	for {
-		conn.SetDeadline(time.Now().Add(rpcRequestInterval))
-		id, err := modules.ReadRPCID(conn, aead)
+		s.extendDeadline(rpcRequestInterval)
+		id, err := modules.ReadRPCID(s.conn, aead)
 		if err != nil {
 			h.log.Debugf("WARN: could not read RPC ID: %v", err)
 			s.writeError(err) // try to write, even though this is probably due to a faulty connection
@@ -139,10 +175,19 @@ func (h *Host) managedRPCLoop(conn net.Conn) error {
 		} else if id == modules.RPCLoopExit {
 			return nil
 		}
-		if rpcFn, ok := rpcs[id]; !ok {
+		rpcFn, ok := rpcs[id]
+		if !ok {
 			return errors.New("invalid or unknown RPC ID: " + id.String())
-		} else if err := rpcFn(s); err != nil {
+		}
+		// The request of a rejected RPC is never read, so the session can't
+		// continue.
+		if !h.staticClients.managedAllowRPC(rpcClassOf(id), s.ipClient, s.renterClient) {
+			s.writeError(errClientRateLimited)
+			return extendErr("incoming RPC"+id.String()+" rejected: ", errClientRateLimited)
+		}
+		if err := rpcFn(s); err != nil {
 			return extendErr("incoming RPC"+id.String()+" failed: ", err)
 		}
+		h.managedUpdateRenterClient(s)
 	}
 }
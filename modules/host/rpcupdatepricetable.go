@@ -14,6 +14,13 @@ import (
 // managedRPCUpdatePriceTable handles the RPC request from the renter to fetch
 // the host's latest RPC price table.
 func (h *Host) managedRPCUpdatePriceTable(stream net.Conn) error {
+	// The price table RPC is limited by the IP address of the renter.
+	ipClient, stream := h.managedConnectIP(stream)
+	defer h.staticClients.managedDisconnect(ipClient)
+	if !h.staticClients.managedAllowRPC(rpcClassSettings, ipClient) {
+		return errClientRateLimited
+	}
+
 	h.mu.RLock()
 	pt := h.priceTable
 	h.mu.RUnlock()
@@ -49,6 +56,7 @@ func (h *Host) managedRPCUpdatePriceTable(stream net.Conn) error {
 // UpdatePriceTableRPC. The price can be dependant on numerous factors.
 // Note: for now this is a fixed cost equaling the base RPC price.
 func (h *Host) managedCalculateUpdatePriceTableRPCPrice() types.Currency {
-	hIS := h.InternalSettings()
-	return hIS.MinBaseRPCPrice
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	return h.prices().BaseRPCPrice
 }
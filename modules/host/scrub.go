@@ -0,0 +1,87 @@
+package host
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// managedCorruptContracts returns the ids of the active storage obligations
+// which contain sectors that were found to be corrupt by the scrubber.
+func (h *Host) managedCorruptContracts() (ids []types.FileContractID, err error) {
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	err = h.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(bucketStorageObligations)
+		return b.ForEach(func(idBytes, soBytes []byte) error {
+			var so storageObligation
+			err := json.Unmarshal(soBytes, &so)
+			if err != nil {
+				return build.ExtendErr("unable to unmarshal storage obligation:", err)
+			}
+			if so.ObligationStatus != obligationUnresolved {
+				return nil
+			}
+			if len(h.CorruptSectors(so.SectorRoots)) > 0 {
+				ids = append(ids, so.id())
+			}
+			return nil
+		})
+	})
+	return ids, err
+}
+
+// managedUpdateCorruptSectorsAlert registers an alert listing the contracts
+// with corrupt sectors, or unregisters it if there are none.
+func (h *Host) managedUpdateCorruptSectorsAlert() {
+	if h.ScrubStatus().CorruptSectors == 0 {
+		h.staticAlerter.UnregisterAlert(modules.AlertIDHostCorruptSectors)
+		return
+	}
+	ids, err := h.managedCorruptContracts()
+	if err != nil {
+		h.log.Println("Unable to check storage obligations for corrupt sectors:", err)
+		return
+	}
+	if len(ids) == 0 {
+		h.staticAlerter.UnregisterAlert(modules.AlertIDHostCorruptSectors)
+		return
+	}
+	contracts := make([]string, 0, len(ids))
+	for _, id := range ids {
+		contracts = append(contracts, id.String())
+	}
+	cause := fmt.Sprintf("corrupt sectors in %v contracts: %v", len(ids), strings.Join(contracts, ", "))
+	h.staticAlerter.RegisterAlert(modules.AlertIDHostCorruptSectors, AlertMSGHostCorruptSectors, cause, modules.SeverityCritical)
+}
+
+// threadedTrackCorruptSectors periodically checks the results of the
+// contract manager's scrubber and updates the corrupt sectors alert.
+func (h *Host) threadedTrackCorruptSectors() {
+	var lastStatus modules.StorageScrubStatus
+	for {
+		select {
+		case <-h.tg.StopChan():
+			return
+		case <-time.After(corruptSectorsCheckInterval):
+		}
+		// Only check the storage obligations if the scrubber made progress.
+		status := h.ScrubStatus()
+		if status.CorruptSectors == lastStatus.CorruptSectors && status.CompletedScrubs == lastStatus.CompletedScrubs {
+			continue
+		}
+		lastStatus = status
+		if err := h.tg.Add(); err != nil {
+			return
+		}
+		h.managedUpdateCorruptSectorsAlert()
+		h.tg.Done()
+	}
+}
@@ -0,0 +1,237 @@
+package host
+
+// storageobligationexplorer.go implements the filtering, sorting, paging and
+// aggregation of the storage obligations for the API. A host can hold tens of
+// thousands of storage obligations, which makes the full list returned by
+// StorageObligations impractical to browse.
+
+import (
+	"sort"
+	"strings"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errUnknownObligationSort is returned if storage obligations are queried
+	// with an unknown sort field.
+	errUnknownObligationSort = errors.New("unknown storage obligation sort field")
+
+	// errUnknownObligationStatus is returned if storage obligations are
+	// queried with an unknown obligation status.
+	errUnknownObligationStatus = errors.New("unknown storage obligation status")
+)
+
+// compareUint64 compares two integers, returning -1, 0 or 1.
+func compareUint64(a, b uint64) int {
+	if a < b {
+		return -1
+	} else if a > b {
+		return 1
+	}
+	return 0
+}
+
+// obligationRevenue returns the potential revenue of a storage obligation.
+func obligationRevenue(so modules.StorageObligation) types.Currency {
+	return so.PotentialDownloadRevenue.Add(so.PotentialUploadRevenue).Add(so.PotentialStorageRevenue)
+}
+
+// parseObligationStatus returns the name of the obligation status matching
+// the provided status, which may omit the "obligation" prefix and is case
+// insensitive.
+func parseObligationStatus(status string) (string, error) {
+	for _, sos := range []storageObligationStatus{obligationUnresolved, obligationRejected, obligationSucceeded, obligationFailed} {
+		name := sos.String()
+		if strings.EqualFold(status, name) || strings.EqualFold(status, strings.TrimPrefix(name, "obligation")) {
+			return name, nil
+		}
+	}
+	return "", errors.AddContext(errUnknownObligationStatus, status)
+}
+
+// filterStorageObligations returns the storage obligations that match the
+// filters of the query.
+func filterStorageObligations(sos []modules.StorageObligation, query modules.StorageObligationQuery) ([]modules.StorageObligation, error) {
+	var status string
+	if query.Status != "" {
+		var err error
+		status, err = parseObligationStatus(query.Status)
+		if err != nil {
+			return nil, err
+		}
+	}
+	var filtered []modules.StorageObligation
+	for _, so := range sos {
+		if status != "" && so.ObligationStatus != status {
+			continue
+		}
+		if query.RenterPublicKey.Key != nil && !so.RenterPublicKey.Equals(query.RenterPublicKey) {
+			continue
+		}
+		if so.ExpirationHeight < query.MinExpiration {
+			continue
+		}
+		if query.MaxExpiration != 0 && so.ExpirationHeight > query.MaxExpiration {
+			continue
+		}
+		if obligationRevenue(so).Cmp(query.MinRevenue) < 0 || so.LockedCollateral.Cmp(query.MinCollateral) < 0 {
+			continue
+		}
+		filtered = append(filtered, so)
+	}
+	return filtered, nil
+}
+
+// sortStorageObligations sorts the storage obligations by the field of the
+// query. Obligations with the same value are sorted by their id to keep the
+// order stable between pages.
+func sortStorageObligations(sos []modules.StorageObligation, query modules.StorageObligationQuery) error {
+	var cmp func(a, b modules.StorageObligation) int
+	switch query.SortBy {
+	case "", modules.StorageObligationSortExpiration:
+		cmp = func(a, b modules.StorageObligation) int {
+			return compareUint64(uint64(a.ExpirationHeight), uint64(b.ExpirationHeight))
+		}
+	case modules.StorageObligationSortCollateral:
+		cmp = func(a, b modules.StorageObligation) int { return a.LockedCollateral.Cmp(b.LockedCollateral) }
+	case modules.StorageObligationSortDataSize:
+		cmp = func(a, b modules.StorageObligation) int { return compareUint64(a.DataSize, b.DataSize) }
+	case modules.StorageObligationSortNegotiation:
+		cmp = func(a, b modules.StorageObligation) int {
+			return compareUint64(uint64(a.NegotiationHeight), uint64(b.NegotiationHeight))
+		}
+	case modules.StorageObligationSortRevenue:
+		cmp = func(a, b modules.StorageObligation) int { return obligationRevenue(a).Cmp(obligationRevenue(b)) }
+	default:
+		return errors.AddContext(errUnknownObligationSort, query.SortBy)
+	}
+	sort.Slice(sos, func(i, j int) bool {
+		c := cmp(sos[i], sos[j])
+		if query.Descending {
+			c = -c
+		}
+		if c == 0 {
+			return strings.Compare(sos[i].ObligationId.String(), sos[j].ObligationId.String()) < 0
+		}
+		return c < 0
+	})
+	return nil
+}
+
+// QueryStorageObligations returns the page of storage obligations that match
+// the query, along with the total number of matching obligations.
+func (h *Host) QueryStorageObligations(query modules.StorageObligationQuery) ([]modules.StorageObligation, uint64, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return nil, 0, err
+	}
+	defer h.tg.Done()
+
+	sos, err := filterStorageObligations(h.StorageObligations(), query)
+	if err != nil {
+		return nil, 0, err
+	}
+	err = sortStorageObligations(sos, query)
+	if err != nil {
+		return nil, 0, err
+	}
+	total := uint64(len(sos))
+	if query.Offset >= total {
+		return []modules.StorageObligation{}, total, nil
+	}
+	sos = sos[query.Offset:]
+	if query.Limit != 0 && query.Limit < uint64(len(sos)) {
+		sos = sos[:query.Limit]
+	}
+	return sos, total, nil
+}
+
+// StorageObligation returns the storage obligation with the provided id,
+// including its revision history and the IDs of its storage proof
+// transactions.
+func (h *Host) StorageObligation(id types.FileContractID) (modules.StorageObligationDetail, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return modules.StorageObligationDetail{}, err
+	}
+	defer h.tg.Done()
+
+	h.mu.RLock()
+	defer h.mu.RUnlock()
+	var so storageObligation
+	var history []modules.StorageObligationRevision
+	err = h.db.View(func(tx *bolt.Tx) error {
+		so, err = getStorageObligation(tx, id)
+		if err != nil {
+			return err
+		}
+		history, err = getRevisionHistory(tx, id)
+		return err
+	})
+	if err != nil {
+		return modules.StorageObligationDetail{}, err
+	}
+	sod := modules.StorageObligationDetail{
+		StorageObligation:   so.metadata(),
+		Revisions:           history,
+		ProofTransactionIDs: so.ProofTransactionIDs,
+	}
+	if sod.Revisions == nil {
+		sod.Revisions = []modules.StorageObligationRevision{}
+	}
+	if sod.ProofTransactionIDs == nil {
+		sod.ProofTransactionIDs = []types.TransactionID{}
+	}
+	return sod, nil
+}
+
+// StorageObligationsSummary aggregates the unresolved storage obligations that
+// match the query by the week in which they expire, starting with the current
+// week. Obligations whose proof window already started are counted in the
+// current week. Weeks without expirations are included so that the periods
+// are contiguous.
+func (h *Host) StorageObligationsSummary(query modules.StorageObligationQuery) ([]modules.StorageObligationPeriod, error) {
+	err := h.tg.Add()
+	if err != nil {
+		return nil, err
+	}
+	defer h.tg.Done()
+
+	sos, err := filterStorageObligations(h.StorageObligations(), query)
+	if err != nil {
+		return nil, err
+	}
+	h.mu.RLock()
+	height := h.blockHeight
+	h.mu.RUnlock()
+
+	periods := []modules.StorageObligationPeriod{}
+	for _, so := range sos {
+		if so.ObligationStatus != obligationUnresolved.String() {
+			continue
+		}
+		var week int
+		if so.ExpirationHeight > height {
+			week = int((so.ExpirationHeight - height) / types.BlocksPerWeek)
+		}
+		for len(periods) <= week {
+			start := height + types.BlockHeight(len(periods))*types.BlocksPerWeek
+			periods = append(periods, modules.StorageObligationPeriod{
+				StartHeight: start,
+				EndHeight:   start + types.BlocksPerWeek,
+			})
+		}
+		p := &periods[week]
+		p.Expirations++
+		p.DataSize += so.DataSize
+		p.LockedCollateral = p.LockedCollateral.Add(so.LockedCollateral)
+		p.RiskedCollateral = p.RiskedCollateral.Add(so.RiskedCollateral)
+		p.PotentialRevenue = p.PotentialRevenue.Add(obligationRevenue(so))
+	}
+	return periods, nil
+}
@@ -0,0 +1,149 @@
+package host
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/persist"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestFilterStorageObligations checks that storage obligations are filtered
+// by the fields of a query.
+func TestFilterStorageObligations(t *testing.T) {
+	t.Parallel()
+	renter := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1, 2, 3}}
+	sos := []modules.StorageObligation{
+		{ObligationId: types.FileContractID{1}, ObligationStatus: obligationUnresolved.String(), ExpirationHeight: 10, RenterPublicKey: renter, LockedCollateral: types.NewCurrency64(5)},
+		{ObligationId: types.FileContractID{2}, ObligationStatus: obligationSucceeded.String(), ExpirationHeight: 20, PotentialStorageRevenue: types.NewCurrency64(7)},
+		{ObligationId: types.FileContractID{3}, ObligationStatus: obligationUnresolved.String(), ExpirationHeight: 30, PotentialUploadRevenue: types.NewCurrency64(3)},
+	}
+	tests := []struct {
+		query modules.StorageObligationQuery
+		ids   []types.FileContractID
+	}{
+		{modules.StorageObligationQuery{}, []types.FileContractID{{1}, {2}, {3}}},
+		{modules.StorageObligationQuery{Status: "unresolved"}, []types.FileContractID{{1}, {3}}},
+		{modules.StorageObligationQuery{Status: "obligationSucceeded"}, []types.FileContractID{{2}}},
+		{modules.StorageObligationQuery{RenterPublicKey: renter}, []types.FileContractID{{1}}},
+		{modules.StorageObligationQuery{MinExpiration: 15, MaxExpiration: 25}, []types.FileContractID{{2}}},
+		{modules.StorageObligationQuery{MinRevenue: types.NewCurrency64(3)}, []types.FileContractID{{2}, {3}}},
+		{modules.StorageObligationQuery{MinCollateral: types.NewCurrency64(1)}, []types.FileContractID{{1}}},
+	}
+	for i, test := range tests {
+		filtered, err := filterStorageObligations(sos, test.query)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(filtered) != len(test.ids) {
+			t.Fatalf("test %v: expected %v obligations but got %v", i, len(test.ids), len(filtered))
+		}
+		for j := range filtered {
+			if filtered[j].ObligationId != test.ids[j] {
+				t.Fatalf("test %v: unexpected obligation %v", i, filtered[j].ObligationId)
+			}
+		}
+	}
+
+	// An unknown status is an error.
+	_, err := filterStorageObligations(sos, modules.StorageObligationQuery{Status: "foo"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown status")
+	}
+}
+
+// TestSortStorageObligations checks that storage obligations are sorted by the
+// field of a query, with ties broken by the obligation id.
+func TestSortStorageObligations(t *testing.T) {
+	t.Parallel()
+	sos := []modules.StorageObligation{
+		{ObligationId: types.FileContractID{3}, ExpirationHeight: 10, DataSize: 5},
+		{ObligationId: types.FileContractID{1}, ExpirationHeight: 20, DataSize: 5},
+		{ObligationId: types.FileContractID{2}, ExpirationHeight: 10, DataSize: 9},
+	}
+	err := sortStorageObligations(sos, modules.StorageObligationQuery{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sos[0].ObligationId != (types.FileContractID{2}) || sos[1].ObligationId != (types.FileContractID{3}) || sos[2].ObligationId != (types.FileContractID{1}) {
+		t.Fatal("obligations not sorted by expiration", sos)
+	}
+	err = sortStorageObligations(sos, modules.StorageObligationQuery{SortBy: modules.StorageObligationSortDataSize, Descending: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if sos[0].ObligationId != (types.FileContractID{2}) || sos[1].ObligationId != (types.FileContractID{1}) || sos[2].ObligationId != (types.FileContractID{3}) {
+		t.Fatal("obligations not sorted by descending data size", sos)
+	}
+	err = sortStorageObligations(sos, modules.StorageObligationQuery{SortBy: "foo"})
+	if err == nil {
+		t.Fatal("expected an error for an unknown sort field")
+	}
+}
+
+// TestRecordRevision checks that the revision history of a storage obligation
+// only records new revisions and is capped at revisionHistoryLen.
+func TestRecordRevision(t *testing.T) {
+	t.Parallel()
+	dir := build.TempDir(modules.HostDir, t.Name())
+	if err := os.MkdirAll(dir, 0700); err != nil {
+		t.Fatal(err)
+	}
+	db, err := persist.OpenDatabase(dbMetadata, filepath.Join(dir, dbFilename))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	so := storageObligation{
+		OriginTransactionSet: []types.Transaction{{
+			FileContracts: []types.FileContract{{}},
+		}},
+	}
+	var history []modules.StorageObligationRevision
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucket(bucketRevisionHistories)
+		if err != nil {
+			return err
+		}
+		for i := uint64(1); i <= revisionHistoryLen+10; i++ {
+			so.RevisionTransactionSet = []types.Transaction{{
+				FileContractRevisions: []types.FileContractRevision{{
+					NewRevisionNumber: i,
+					NewFileSize:       i * modules.SectorSize,
+					NewValidProofOutputs: []types.SiacoinOutput{
+						{Value: types.NewCurrency64(100 - i)},
+						{Value: types.NewCurrency64(i)},
+					},
+				}},
+			}}
+			if err := so.recordRevision(tx, types.BlockHeight(i)); err != nil {
+				return err
+			}
+			// Recording the same revision twice is a no-op.
+			if err := so.recordRevision(tx, types.BlockHeight(i)); err != nil {
+				return err
+			}
+		}
+		history, err = getRevisionHistory(tx, so.id())
+		return err
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(history) != revisionHistoryLen {
+		t.Fatalf("expected %v revisions but got %v", revisionHistoryLen, len(history))
+	}
+	last := history[len(history)-1]
+	if last.RevisionNumber != revisionHistoryLen+10 || last.FileSize != (revisionHistoryLen+10)*modules.SectorSize || !last.HostPayout.Equals64(revisionHistoryLen+10) {
+		t.Fatal("unexpected last revision", last)
+	}
+	if history[0].RevisionNumber != 11 {
+		t.Fatal("expected the oldest revisions to be dropped", history[0].RevisionNumber)
+	}
+}
@@ -156,6 +156,10 @@ type storageObligation struct {
 	ProofConstructed    bool
 	RevisionConfirmed   bool
 	RevisionConstructed bool
+
+	// The IDs of the storage proof transactions submitted by the host,
+	// which are kept for the storage obligation explorer.
+	ProofTransactionIDs []types.TransactionID
 }
 
 func (i storageObligationStatus) String() string {
@@ -280,6 +284,36 @@ func (so storageObligation) merkleRoot() crypto.Hash {
 	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0].FileMerkleRoot
 }
 
+// metadata returns the information about the storage obligation that is
+// reported by the API.
+func (so storageObligation) metadata() modules.StorageObligation {
+	return modules.StorageObligation{
+		ContractCost:             so.ContractCost,
+		DataSize:                 so.fileSize(),
+		LockedCollateral:         so.LockedCollateral,
+		ObligationId:             so.id(),
+		PotentialDownloadRevenue: so.PotentialDownloadRevenue,
+		PotentialStorageRevenue:  so.PotentialStorageRevenue,
+		PotentialUploadRevenue:   so.PotentialUploadRevenue,
+		RenterPublicKey:          so.renterPublicKey(),
+		RiskedCollateral:         so.RiskedCollateral,
+		SectorRootsCount:         uint64(len(so.SectorRoots)),
+		TransactionFeesAdded:     so.TransactionFeesAdded,
+		TransactionID:            so.transactionID(),
+
+		ExpirationHeight:  so.expiration(),
+		NegotiationHeight: so.NegotiationHeight,
+		ProofDeadLine:     so.proofDeadline(),
+
+		ObligationStatus:    so.ObligationStatus.String(),
+		OriginConfirmed:     so.OriginConfirmed,
+		ProofConfirmed:      so.ProofConfirmed,
+		ProofConstructed:    so.ProofConstructed,
+		RevisionConfirmed:   so.RevisionConfirmed,
+		RevisionConstructed: so.RevisionConstructed,
+	}
+}
+
 // payouts returns the set of valid payouts and missed payouts that represent
 // the latest revision for the storage obligation.
 func (so storageObligation) payouts() (valid []types.SiacoinOutput, missed []types.SiacoinOutput) {
@@ -304,6 +338,68 @@ func (so storageObligation) proofDeadline() types.BlockHeight {
 	return so.OriginTransactionSet[len(so.OriginTransactionSet)-1].FileContracts[0].WindowEnd
 }
 
+// getRevisionHistory fetches the revision history of a storage obligation
+// from the database tx.
+func getRevisionHistory(tx *bolt.Tx, soid types.FileContractID) (history []modules.StorageObligationRevision, err error) {
+	historyBytes := tx.Bucket(bucketRevisionHistories).Get(soid[:])
+	if historyBytes == nil {
+		return nil, nil
+	}
+	err = json.Unmarshal(historyBytes, &history)
+	return history, err
+}
+
+// recordRevision appends the latest revision of the storage obligation to its
+// revision history in the database tx, unless it is already part of it. Only
+// the most recent revisionHistoryLen revisions are kept.
+func (so storageObligation) recordRevision(tx *bolt.Tx, height types.BlockHeight) error {
+	if len(so.RevisionTransactionSet) == 0 {
+		return nil
+	}
+	soid := so.id()
+	history, err := getRevisionHistory(tx, soid)
+	if err != nil {
+		return err
+	}
+	txn := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1]
+	rev := txn.FileContractRevisions[0]
+	if len(history) > 0 && history[len(history)-1].RevisionNumber >= rev.NewRevisionNumber {
+		return nil
+	}
+	sor := modules.StorageObligationRevision{
+		RevisionNumber: rev.NewRevisionNumber,
+		BlockHeight:    height,
+		TransactionID:  txn.ID(),
+		FileSize:       rev.NewFileSize,
+		FileMerkleRoot: rev.NewFileMerkleRoot,
+	}
+	if len(rev.NewValidProofOutputs) == 2 {
+		sor.RenterPayout = rev.NewValidProofOutputs[0].Value
+		sor.HostPayout = rev.NewValidProofOutputs[1].Value
+	}
+	history = append(history, sor)
+	if len(history) > revisionHistoryLen {
+		history = history[len(history)-revisionHistoryLen:]
+	}
+	historyBytes, err := json.Marshal(history)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketRevisionHistories).Put(soid[:], historyBytes)
+}
+
+// renterPublicKey returns the public key of the renter of the storage
+// obligation, or an empty key if the contract was never revised.
+func (so storageObligation) renterPublicKey() types.SiaPublicKey {
+	if len(so.RevisionTransactionSet) > 0 {
+		uc := so.RevisionTransactionSet[len(so.RevisionTransactionSet)-1].FileContractRevisions[0].UnlockConditions
+		if len(uc.PublicKeys) > 0 {
+			return uc.PublicKeys[0]
+		}
+	}
+	return types.SiaPublicKey{}
+}
+
 // transactionID returns the ID of the transaction containing the file
 // contract.
 func (so storageObligation) transactionID() types.TransactionID {
@@ -322,13 +418,18 @@ func (h *Host) deleteStorageObligations(soids []types.FileContractID) error {
 	h.mu.RLock()
 	defer h.mu.RUnlock()
 	err := h.db.Update(func(tx *bolt.Tx) error {
-		// Delete obligations.
+		// Delete obligations along with their revision histories.
 		b := tx.Bucket(bucketStorageObligations)
+		brh := tx.Bucket(bucketRevisionHistories)
 		for _, soid := range soids {
 			err := b.Delete([]byte(soid[:]))
 			if err != nil {
 				return build.ExtendErr("unable to delete transaction id:", err)
 			}
+			err = brh.Delete(soid[:])
+			if err != nil {
+				return build.ExtendErr("unable to delete revision history:", err)
+			}
 		}
 		return nil
 	})
@@ -418,6 +519,10 @@ func (h *Host) managedAddStorageObligation(so storageObligation) error {
 			}
 
 			// Add the storage obligation to the database.
+			err := so.recordRevision(tx, h.blockHeight)
+			if err != nil {
+				return err
+			}
 			soBytes, err := json.Marshal(so)
 			if err != nil {
 				return err
@@ -543,6 +648,10 @@ func (h *Host) modifyStorageObligation(so storageObligation, sectorsRemoved []cr
 		if err != nil {
 			return err
 		}
+		err = so.recordRevision(tx, h.blockHeight)
+		if err != nil {
+			return err
+		}
 
 		// Store the new storage obligation to replace the old one.
 		return putStorageObligation(tx, so)
@@ -985,7 +1094,10 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 		copy(sp.Segment[:], base)
 
 		// Create and build the transaction with the storage proof.
-		builder, err := h.wallet.StartTransaction()
+		h.mu.RLock()
+		account := h.settings.WalletAccount
+		h.mu.RUnlock()
+		builder, err := h.wallet.StartAccountTransaction(account)
 		if err != nil {
 			h.log.Println("Failed to start transaction:", err)
 			return
@@ -1021,6 +1133,7 @@ func (h *Host) threadedHandleActionItem(soid types.FileContractID) {
 			return
 		}
 		so.TransactionFeesAdded = so.TransactionFeesAdded.Add(requiredFee)
+		so.ProofTransactionIDs = append(so.ProofTransactionIDs, storageProofSet[len(storageProofSet)-1].ID())
 
 		// Queue another action item to check whether the storage proof
 		// got confirmed.
@@ -1068,31 +1181,7 @@ func (h *Host) StorageObligations() (sos []modules.StorageObligation) {
 			if err != nil {
 				return build.ExtendErr("unable to unmarshal storage obligation:", err)
 			}
-			mso := modules.StorageObligation{
-				ContractCost:             so.ContractCost,
-				DataSize:                 so.fileSize(),
-				LockedCollateral:         so.LockedCollateral,
-				ObligationId:             so.id(),
-				PotentialDownloadRevenue: so.PotentialDownloadRevenue,
-				PotentialStorageRevenue:  so.PotentialStorageRevenue,
-				PotentialUploadRevenue:   so.PotentialUploadRevenue,
-				RiskedCollateral:         so.RiskedCollateral,
-				SectorRootsCount:         uint64(len(so.SectorRoots)),
-				TransactionFeesAdded:     so.TransactionFeesAdded,
-				TransactionID:            so.transactionID(),
-
-				ExpirationHeight:  so.expiration(),
-				NegotiationHeight: so.NegotiationHeight,
-				ProofDeadLine:     so.proofDeadline(),
-
-				ObligationStatus:    so.ObligationStatus.String(),
-				OriginConfirmed:     so.OriginConfirmed,
-				ProofConfirmed:      so.ProofConfirmed,
-				ProofConstructed:    so.ProofConstructed,
-				RevisionConfirmed:   so.RevisionConfirmed,
-				RevisionConstructed: so.RevisionConstructed,
-			}
-			sos = append(sos, mso)
+			sos = append(sos, so.metadata())
 			return nil
 		})
 		if err != nil {
@@ -21,6 +21,7 @@ func (h *Host) managedLearnHostname() {
 	netAddr := h.settings.NetAddress
 	hostPort := h.port
 	hostAutoAddress := h.autoAddress
+	alternates := append([]modules.NetAddress(nil), h.settings.AlternateNetAddresses...)
 	hostAnnounced := h.announced
 	hostAcceptingContracts := h.settings.AcceptingContracts
 	hostContractCount := h.financialMetrics.ContractCount
@@ -65,7 +66,7 @@ func (h *Host) managedLearnHostname() {
 	// address has changed.
 	if hostAcceptingContracts || hostContractCount > 0 {
 		h.log.Println("Host external IP address changed from", hostAutoAddress, "to", autoAddress, "- performing host announcement.")
-		err = h.managedAnnounce(autoAddress)
+		err = h.managedAnnounce(append([]modules.NetAddress{autoAddress}, alternates...))
 		if err != nil {
 			// Set h.announced to false, as the address has changed yet the
 			// renewed annoucement has failed.
@@ -0,0 +1,171 @@
+package host
+
+// websocket.go implements the WebSocket transport of the host. Renters that
+// can't reach the host over raw TCP, e.g. browsers or renters on restrictive
+// networks, can run the same RPC loop over a WebSocket connection, optionally
+// secured with TLS using a certificate supplied by the operator. The session
+// encryption of the RPC loop stays end-to-end over either transport.
+
+import (
+	"crypto/tls"
+	"net"
+	"net/http"
+	"net/url"
+
+	"gitlab.com/NebulousLabs/errors"
+	connmonitor "gitlab.com/NebulousLabs/monitor"
+	"golang.org/x/net/websocket"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errWebSocketTLSConfig is returned if only one of the TLS certificate
+	// and key of the WebSocket listener is provided.
+	errWebSocketTLSConfig = errors.New("both a TLS certificate and key are required for the WebSocket listener")
+)
+
+// webSocketConn is a WebSocket connection to a renter. The remote address of
+// a websocket.Conn is the origin of the renter, so webSocketConn replaces it
+// with the address of the renter to apply the per-IP limits.
+type webSocketConn struct {
+	*websocket.Conn
+	remoteAddr net.Addr
+}
+
+// RemoteAddr returns the address of the renter.
+func (c webSocketConn) RemoteAddr() net.Addr { return c.remoteAddr }
+
+// webSocketSettingsChanged returns true if the settings of the WebSocket
+// listener differ.
+func webSocketSettingsChanged(old, new modules.HostInternalSettings) bool {
+	return old.WebSocketAddress != new.WebSocketAddress ||
+		old.WebSocketTLSCertFile != new.WebSocketTLSCertFile ||
+		old.WebSocketTLSKeyFile != new.WebSocketTLSKeyFile
+}
+
+// webSocketTLSConfig loads the TLS configuration of the WebSocket listener. A
+// nil config is returned if the listener doesn't use TLS.
+func webSocketTLSConfig(settings modules.HostInternalSettings) (*tls.Config, error) {
+	if settings.WebSocketTLSCertFile == "" && settings.WebSocketTLSKeyFile == "" {
+		return nil, nil
+	}
+	if settings.WebSocketTLSCertFile == "" || settings.WebSocketTLSKeyFile == "" {
+		return nil, errWebSocketTLSConfig
+	}
+	cert, err := tls.LoadX509KeyPair(settings.WebSocketTLSCertFile, settings.WebSocketTLSKeyFile)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to load the TLS certificate")
+	}
+	return &tls.Config{
+		Certificates: []tls.Certificate{cert},
+		MinVersion:   tls.VersionTLS12,
+	}, nil
+}
+
+// closeWebSocketListener stops the WebSocket listener. Connections that were
+// already established are not interrupted.
+func (h *Host) closeWebSocketListener() {
+	if h.webSocketServer == nil {
+		return
+	}
+	if err := h.webSocketServer.Close(); err != nil {
+		h.log.Println("WARN: closing the WebSocket listener failed:", err)
+	}
+	h.webSocketListener = nil
+	h.webSocketServer = nil
+	h.webSocketTLS = false
+}
+
+// listenWebSocket starts the WebSocket listener for the provided settings.
+func (h *Host) listenWebSocket(settings modules.HostInternalSettings) error {
+	if settings.WebSocketAddress == "" {
+		return nil
+	}
+	tlsConfig, err := webSocketTLSConfig(settings)
+	if err != nil {
+		return err
+	}
+	l, err := h.dependencies.Listen("tcp", settings.WebSocketAddress)
+	if err != nil {
+		return err
+	}
+	if tlsConfig != nil {
+		l = tls.NewListener(l, tlsConfig)
+	}
+
+	mux := http.NewServeMux()
+	mux.Handle(modules.WebSocketRPCPath, websocket.Server{
+		// Connections are accepted from any origin, the RPC loop
+		// authenticates the host and encrypts the session.
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler:   h.managedHandleWebSocket,
+	})
+	srv := &http.Server{Handler: mux}
+	h.webSocketListener = l
+	h.webSocketServer = srv
+	h.webSocketTLS = tlsConfig != nil
+	go func() {
+		err := srv.Serve(l)
+		if err != nil && err != http.ErrServerClosed {
+			h.log.Println("ERROR: the WebSocket listener failed:", err)
+		}
+	}()
+	return nil
+}
+
+// updateWebSocketListener restarts the WebSocket listener with the provided
+// settings. If the new listener can't be started, the previous listener is
+// restored.
+func (h *Host) updateWebSocketListener(settings modules.HostInternalSettings) error {
+	// Check the TLS config before closing the current listener.
+	if _, err := webSocketTLSConfig(settings); err != nil {
+		return err
+	}
+	h.closeWebSocketListener()
+	err := h.listenWebSocket(settings)
+	if err != nil {
+		if restoreErr := h.listenWebSocket(h.settings); restoreErr != nil {
+			h.log.Println("WARN: unable to restore the WebSocket listener:", restoreErr)
+		}
+		return err
+	}
+	return nil
+}
+
+// webSocketURL returns the URL at which renters can reach the WebSocket
+// listener, which uses the hostname of the host's net address. An empty string
+// is returned if the listener is disabled.
+func (h *Host) webSocketURL(netAddr modules.NetAddress) string {
+	if h.webSocketListener == nil || netAddr.Host() == "" {
+		return ""
+	}
+	_, port, err := net.SplitHostPort(h.webSocketListener.Addr().String())
+	if err != nil {
+		return ""
+	}
+	scheme := "ws"
+	if h.webSocketTLS {
+		scheme = "wss"
+	}
+	u := url.URL{
+		Scheme: scheme,
+		Host:   net.JoinHostPort(netAddr.Host(), port),
+		Path:   modules.WebSocketRPCPath,
+	}
+	return u.String()
+}
+
+// managedHandleWebSocket handles an incoming WebSocket connection like a raw
+// TCP connection to the host. The connection is closed when the method
+// returns.
+func (h *Host) managedHandleWebSocket(ws *websocket.Conn) {
+	ws.PayloadType = websocket.BinaryFrame
+	remoteAddr, err := net.ResolveTCPAddr("tcp", ws.Request().RemoteAddr)
+	if err != nil {
+		h.log.Debugln("WARN: incoming WebSocket conn has an invalid remote address:", err)
+		return
+	}
+	conn := connmonitor.NewMonitoredConn(webSocketConn{Conn: ws, remoteAddr: remoteAddr}, h.staticMonitor)
+	h.threadedHandleConn(conn)
+}
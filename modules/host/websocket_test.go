@@ -0,0 +1,90 @@
+package host
+
+import (
+	"encoding/json"
+	"net"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestWebSocketTLSConfig checks that the TLS config of the WebSocket listener
+// requires both a certificate and a key.
+func TestWebSocketTLSConfig(t *testing.T) {
+	t.Parallel()
+	config, err := webSocketTLSConfig(modules.HostInternalSettings{})
+	if err != nil || config != nil {
+		t.Fatal("expected no TLS config", config, err)
+	}
+	_, err = webSocketTLSConfig(modules.HostInternalSettings{WebSocketTLSCertFile: "cert.pem"})
+	if err != errWebSocketTLSConfig {
+		t.Fatal("expected errWebSocketTLSConfig but got", err)
+	}
+	_, err = webSocketTLSConfig(modules.HostInternalSettings{WebSocketTLSCertFile: "missing-cert.pem", WebSocketTLSKeyFile: "missing-key.pem"})
+	if err == nil {
+		t.Fatal("expected an error for missing certificate files")
+	}
+}
+
+// TestWebSocketRPCLoop checks that renters can run the RPC loop with the host
+// over WebSocket.
+func TestWebSocketRPCLoop(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	ht, err := newHostTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer ht.Close()
+
+	// Enable the WebSocket listener.
+	settings := ht.host.InternalSettings()
+	settings.WebSocketAddress = "127.0.0.1:0"
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	wsURL := ht.host.ExternalSettings().WebSocketURL
+	if !strings.HasPrefix(wsURL, "ws://") || !strings.HasSuffix(wsURL, modules.WebSocketRPCPath) {
+		t.Fatal("unexpected WebSocket URL", wsURL)
+	}
+
+	// Request the settings of the host over WebSocket.
+	conn, err := modules.DialWebSocket(&net.Dialer{Timeout: time.Minute}, wsURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	s, _, err := modules.NewRenterSession(conn, ht.host.PublicKey())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := s.WriteRequest(modules.RPCLoopSettings, nil); err != nil {
+		t.Fatal(err)
+	}
+	var resp modules.LoopSettingsResponse
+	if err := s.ReadResponse(&resp, 4096); err != nil {
+		t.Fatal(err)
+	}
+	var hes modules.HostExternalSettings
+	if err := json.Unmarshal(resp.Settings, &hes); err != nil {
+		t.Fatal(err)
+	}
+	if hes.WebSocketURL != wsURL {
+		t.Fatal("settings don't advertise the WebSocket URL", hes.WebSocketURL)
+	}
+
+	// Disabling the listener removes the WebSocket URL.
+	settings.WebSocketAddress = ""
+	err = ht.host.SetInternalSettings(settings)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if url := ht.host.ExternalSettings().WebSocketURL; url != "" {
+		t.Fatal("expected no WebSocket URL", url)
+	}
+}
@@ -0,0 +1,191 @@
+package modules
+
+import (
+	"bytes"
+	"errors"
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// ErrMultisigMismatch is returned when partially signed transactions that
+	// spend different inputs or create different outputs are merged.
+	ErrMultisigMismatch = errors.New("partially signed transactions do not describe the same transaction")
+
+	// ErrMultisigThreshold is returned when a partially signed transaction
+	// doesn't have enough signatures to be broadcast.
+	ErrMultisigThreshold = errors.New("not enough signatures to satisfy the unlock conditions of every input")
+)
+
+type (
+	// A PartiallySignedTransaction is a transaction that spends from one or
+	// more multisig addresses and is passed between the cosigners until
+	// enough signatures have been collected. The inputs of the transaction
+	// carry the unlock conditions of the addresses they spend from, and the
+	// transaction itself has no signatures; those are collected separately
+	// so that every cosigner can sign independently.
+	PartiallySignedTransaction struct {
+		Transaction types.Transaction `json:"transaction"`
+		// Parents are the unconfirmed transactions that create the outputs
+		// spent by the transaction. They are broadcast along with it.
+		Parents []types.Transaction `json:"parents"`
+		// Signatures are the signatures collected so far.
+		Signatures []types.TransactionSignature `json:"signatures"`
+	}
+
+	// MultisigInputStatus reports how many signatures have been collected
+	// for an input of a partially signed transaction.
+	MultisigInputStatus struct {
+		ParentID  crypto.Hash `json:"parentid"`
+		Required  uint64      `json:"required"`
+		Collected uint64      `json:"collected"`
+	}
+)
+
+// NewMultisigUnlockConditions returns the unlock conditions of an address that
+// requires 'required' signatures from the provided ed25519 public keys.
+func NewMultisigUnlockConditions(pks []types.SiaPublicKey, required uint64) (types.UnlockConditions, error) {
+	if len(pks) == 0 {
+		return types.UnlockConditions{}, errors.New("at least one public key is required")
+	}
+	if required == 0 || required > uint64(len(pks)) {
+		return types.UnlockConditions{}, errors.New("the number of required signatures must be between 1 and the number of public keys")
+	}
+	for i, pk := range pks {
+		if pk.Algorithm != types.SignatureEd25519 || len(pk.Key) != crypto.PublicKeySize {
+			return types.UnlockConditions{}, errors.New("public key " + pk.String() + " is not a valid ed25519 key")
+		}
+		for _, other := range pks[:i] {
+			if bytes.Equal(pk.Key, other.Key) {
+				return types.UnlockConditions{}, errors.New("public key " + pk.String() + " was provided more than once")
+			}
+		}
+	}
+	return types.UnlockConditions{
+		PublicKeys:         pks,
+		SignaturesRequired: required,
+	}, nil
+}
+
+// inputUnlockConditions returns the parent IDs of the siacoin and siafund
+// inputs of the transaction mapped to their unlock conditions.
+func (pst PartiallySignedTransaction) inputUnlockConditions() ([]crypto.Hash, map[crypto.Hash]types.UnlockConditions) {
+	var ids []crypto.Hash
+	ucs := make(map[crypto.Hash]types.UnlockConditions)
+	for _, sci := range pst.Transaction.SiacoinInputs {
+		ids = append(ids, crypto.Hash(sci.ParentID))
+		ucs[crypto.Hash(sci.ParentID)] = sci.UnlockConditions
+	}
+	for _, sfi := range pst.Transaction.SiafundInputs {
+		ids = append(ids, crypto.Hash(sfi.ParentID))
+		ucs[crypto.Hash(sfi.ParentID)] = sfi.UnlockConditions
+	}
+	return ids, ucs
+}
+
+// Status returns the number of required and collected signatures of every
+// input of the transaction. Signatures that don't refer to a public key of
+// the input aren't counted.
+func (pst PartiallySignedTransaction) Status() []MultisigInputStatus {
+	ids, ucs := pst.inputUnlockConditions()
+	collected := make(map[crypto.Hash]map[uint64]struct{})
+	for _, sig := range pst.Signatures {
+		uc, ok := ucs[sig.ParentID]
+		if !ok || sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)) || len(sig.Signature) == 0 {
+			continue
+		}
+		if collected[sig.ParentID] == nil {
+			collected[sig.ParentID] = make(map[uint64]struct{})
+		}
+		collected[sig.ParentID][sig.PublicKeyIndex] = struct{}{}
+	}
+	status := make([]MultisigInputStatus, 0, len(ids))
+	for _, id := range ids {
+		status = append(status, MultisigInputStatus{
+			ParentID:  id,
+			Required:  ucs[id].SignaturesRequired,
+			Collected: uint64(len(collected[id])),
+		})
+	}
+	return status
+}
+
+// ThresholdMet returns true if enough signatures have been collected for
+// every input of the transaction.
+func (pst PartiallySignedTransaction) ThresholdMet() bool {
+	for _, s := range pst.Status() {
+		if s.Collected < s.Required {
+			return false
+		}
+	}
+	return true
+}
+
+// SignedTransaction returns the transaction with exactly as many of the
+// collected signatures as each input requires. Any additional signature would
+// make the transaction invalid.
+func (pst PartiallySignedTransaction) SignedTransaction() (types.Transaction, error) {
+	if !pst.ThresholdMet() {
+		return types.Transaction{}, ErrMultisigThreshold
+	}
+	_, ucs := pst.inputUnlockConditions()
+	sigs := make([]types.TransactionSignature, len(pst.Signatures))
+	copy(sigs, pst.Signatures)
+	sort.SliceStable(sigs, func(i, j int) bool {
+		return sigs[i].PublicKeyIndex < sigs[j].PublicKeyIndex
+	})
+
+	txn := pst.Transaction
+	txn.TransactionSignatures = nil
+	used := make(map[crypto.Hash]map[uint64]struct{})
+	for _, sig := range sigs {
+		uc, ok := ucs[sig.ParentID]
+		if !ok || sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)) || len(sig.Signature) == 0 {
+			continue
+		}
+		if used[sig.ParentID] == nil {
+			used[sig.ParentID] = make(map[uint64]struct{})
+		}
+		if _, exists := used[sig.ParentID][sig.PublicKeyIndex]; exists || uint64(len(used[sig.ParentID])) == uc.SignaturesRequired {
+			continue
+		}
+		used[sig.ParentID][sig.PublicKeyIndex] = struct{}{}
+		txn.TransactionSignatures = append(txn.TransactionSignatures, sig)
+	}
+	return txn, nil
+}
+
+// MergePartiallySignedTransactions combines the signatures collected by the
+// cosigners of a transaction. All of the partially signed transactions must
+// describe the same transaction.
+func MergePartiallySignedTransactions(psts ...PartiallySignedTransaction) (PartiallySignedTransaction, error) {
+	if len(psts) == 0 {
+		return PartiallySignedTransaction{}, errors.New("no transactions to merge")
+	}
+	merged := PartiallySignedTransaction{
+		Transaction: psts[0].Transaction,
+		Parents:     psts[0].Parents,
+	}
+	merged.Transaction.TransactionSignatures = nil
+	type sigKey struct {
+		parentID crypto.Hash
+		index    uint64
+	}
+	seen := make(map[sigKey]struct{})
+	for _, pst := range psts {
+		if pst.Transaction.ID() != merged.Transaction.ID() {
+			return PartiallySignedTransaction{}, ErrMultisigMismatch
+		}
+		for _, sig := range pst.Signatures {
+			key := sigKey{sig.ParentID, sig.PublicKeyIndex}
+			if _, exists := seen[key]; exists || len(sig.Signature) == 0 {
+				continue
+			}
+			seen[key] = struct{}{}
+			merged.Signatures = append(merged.Signatures, sig)
+		}
+	}
+	return merged, nil
+}
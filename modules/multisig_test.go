@@ -0,0 +1,101 @@
+package modules
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestNewMultisigUnlockConditions probes the validation of multisig unlock
+// conditions.
+func TestNewMultisigUnlockConditions(t *testing.T) {
+	_, pk1 := crypto.GenerateKeyPair()
+	_, pk2 := crypto.GenerateKeyPair()
+	spk1, spk2 := types.Ed25519PublicKey(pk1), types.Ed25519PublicKey(pk2)
+
+	tests := []struct {
+		pks      []types.SiaPublicKey
+		required uint64
+		valid    bool
+	}{
+		{[]types.SiaPublicKey{spk1, spk2}, 2, true},
+		{[]types.SiaPublicKey{spk1, spk2}, 1, true},
+		{[]types.SiaPublicKey{spk1, spk2}, 0, false},
+		{[]types.SiaPublicKey{spk1, spk2}, 3, false},
+		{[]types.SiaPublicKey{spk1, spk1}, 1, false},
+		{[]types.SiaPublicKey{{Algorithm: types.SignatureEntropy, Key: spk1.Key}}, 1, false},
+		{nil, 0, false},
+	}
+	for i, test := range tests {
+		uc, err := NewMultisigUnlockConditions(test.pks, test.required)
+		if (err == nil) != test.valid {
+			t.Errorf("test %v: expected valid %v, got %v", i, test.valid, err)
+		} else if err == nil && uc.SignaturesRequired != test.required {
+			t.Errorf("test %v: wrong number of required signatures", i)
+		}
+	}
+}
+
+// TestMergePartiallySignedTransactions checks that signatures are merged,
+// counted and trimmed to the number each input requires.
+func TestMergePartiallySignedTransactions(t *testing.T) {
+	pks := make([]types.SiaPublicKey, 3)
+	for i := range pks {
+		_, pk := crypto.GenerateKeyPair()
+		pks[i] = types.Ed25519PublicKey(pk)
+	}
+	uc, err := NewMultisigUnlockConditions(pks, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	pst := PartiallySignedTransaction{
+		Transaction: types.Transaction{
+			SiacoinInputs: []types.SiacoinInput{{ParentID: types.SiacoinOutputID{1}, UnlockConditions: uc}},
+		},
+	}
+	sig := func(index uint64) types.TransactionSignature {
+		return types.TransactionSignature{
+			ParentID:       crypto.Hash{1},
+			CoveredFields:  types.CoveredFields{WholeTransaction: true},
+			PublicKeyIndex: index,
+			Signature:      []byte{byte(index + 1)},
+		}
+	}
+	a, b, c := pst, pst, pst
+	a.Signatures = []types.TransactionSignature{sig(2)}
+	b.Signatures = []types.TransactionSignature{sig(2), sig(0)}
+	c.Signatures = []types.TransactionSignature{sig(1)}
+
+	if a.ThresholdMet() {
+		t.Fatal("threshold shouldn't be met")
+	}
+	if _, err := a.SignedTransaction(); err != ErrMultisigThreshold {
+		t.Fatal("expected ErrMultisigThreshold, got", err)
+	}
+
+	merged, err := MergePartiallySignedTransactions(a, b, c)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(merged.Signatures) != 3 {
+		t.Fatal("duplicate signatures should be merged", merged.Signatures)
+	}
+	status := merged.Status()
+	if len(status) != 1 || status[0].Required != 2 || status[0].Collected != 3 {
+		t.Fatal("unexpected status", status)
+	}
+	txn, err := merged.SignedTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.TransactionSignatures) != 2 || txn.TransactionSignatures[0].PublicKeyIndex != 0 || txn.TransactionSignatures[1].PublicKeyIndex != 1 {
+		t.Fatal("expected exactly the required signatures", txn.TransactionSignatures)
+	}
+
+	// transactions that differ can't be merged
+	c.Transaction.MinerFees = []types.Currency{types.NewCurrency64(1)}
+	if _, err := MergePartiallySignedTransactions(a, c); err != ErrMultisigMismatch {
+		t.Fatal("expected ErrMultisigMismatch, got", err)
+	}
+}
@@ -44,6 +44,12 @@ const (
 	V1420ContractNotRecognizedErrString = "no record of that contract"
 )
 
+const (
+	// MaxAnnouncementAddresses is the maximum number of addresses a host can
+	// announce in a single announcement.
+	MaxAnnouncementAddresses = 4
+)
+
 const (
 	// AcceptResponse is the response given to an RPC call to indicate
 	// acceptance, i.e. that the sender wishes to continue communication.
@@ -164,6 +170,22 @@ var (
 	// announcement is not a type of signature that is recognized.
 	ErrAnnUnrecognizedSignature = errors.New("the signature provided in the host announcement is not recognized")
 
+	// ErrAnnNoAddresses is returned when a host announcement doesn't contain
+	// any addresses.
+	ErrAnnNoAddresses = errors.New("the host announcement doesn't contain any addresses")
+
+	// ErrAnnTooManyAddresses is returned when a host announcement contains
+	// more than MaxAnnouncementAddresses addresses.
+	ErrAnnTooManyAddresses = fmt.Errorf("the host announcement contains more than %v addresses", MaxAnnouncementAddresses)
+
+	// ErrAnnDuplicateAddress is returned when a host announcement contains the
+	// same address more than once.
+	ErrAnnDuplicateAddress = errors.New("the host announcement contains a duplicate address")
+
+	// ErrAnnAddressType is returned when the type of an address in a host
+	// announcement doesn't match the address.
+	ErrAnnAddressType = errors.New("the type of an address in the host announcement doesn't match the address")
+
 	// ErrRevisionCoveredFields is returned if there is a covered fields object
 	// in a transaction signature which has the 'WholeTransaction' field set to
 	// true, meaning that miner fees cannot be added to the transaction without
@@ -183,6 +205,11 @@ var (
 	// announcement will follow this prefix.
 	PrefixHostAnnouncement = types.NewSpecifier("HostAnnouncement")
 
+	// PrefixHostAnnouncementV2 is used to indicate that a transaction's
+	// Arbitrary Data field contains a host announcement with multiple typed
+	// addresses. The encoded announcement will follow this prefix.
+	PrefixHostAnnouncementV2 = types.NewSpecifier("HostAnnounceV2")
+
 	// PrefixFileContractIdentifier is used to indicate that a transaction's
 	// Arbitrary Data field contains a file contract identifier. The identifier
 	// and its signature will follow this prefix.
@@ -238,6 +265,23 @@ type (
 		PublicKey  types.SiaPublicKey
 	}
 
+	// HostAddress is an address announced by a host together with its type.
+	HostAddress struct {
+		Type    NetAddressType `json:"type"`
+		Address NetAddress     `json:"address"`
+	}
+
+	// HostAnnouncementV2 is an announcement by a host that is reachable on
+	// multiple addresses, e.g. a dual-stack host or a host with multiple
+	// uplinks. 'Specifier' is always 'PrefixHostAnnouncementV2' and the first
+	// address is the host's primary address. Like the HostAnnouncement it is
+	// followed by a signature of the whole announcement.
+	HostAnnouncementV2 struct {
+		Specifier types.Specifier
+		Addresses []HostAddress
+		PublicKey types.SiaPublicKey
+	}
+
 	// HostExternalSettings are the parameters advertised by the host. These
 	// are the values that the renter will request from the host in order to
 	// build its database.
@@ -303,6 +347,12 @@ type (
 		StoragePrice           types.Currency `json:"storageprice"`
 		UploadBandwidthPrice   types.Currency `json:"uploadbandwidthprice"`
 
+		// WebSocketURL is the ws:// or wss:// URL at which the host serves
+		// the renter-host protocol over WebSocket, for renters that can't
+		// reach the host over raw TCP. It is empty if the host doesn't accept
+		// WebSocket connections.
+		WebSocketURL string `json:"websocketurl"`
+
 		// Because the host has a public key, and settings are signed, and
 		// because settings may be MITM'd, settings need a revision number so
 		// that a renter can compare multiple sets of settings and determine
@@ -390,6 +440,10 @@ var (
 	// RPCChallengePrefix is the prefix prepended to the challenge data
 	// supplied by the host when proving ownership of a contract's secret key.
 	RPCChallengePrefix = types.NewSpecifier("challenge")
+
+	// RPCErrorSectorNotFound is the type of the RPCError sent by a host that
+	// doesn't store a requested sector.
+	RPCErrorSectorNotFound = types.NewSpecifier("SectorNotFound")
 )
 
 // New RPC request and response types
@@ -665,7 +719,14 @@ func ReadRPCResponse(r io.Reader, aead cipher.AEAD, resp interface{}, maxLen uin
 		build.Critical("maxLen must be at least RPCMinLen")
 		maxLen = RPCMinLen
 	}
-	return ReadRPCMessage(r, aead, &rpcResponse{nil, resp}, maxLen)
+	rr := &rpcResponse{nil, resp}
+	err := ReadRPCMessage(r, aead, rr, maxLen)
+	// Return the RPCError sent by the host as is instead of the decoding
+	// error that wraps it, so that its type can be inspected.
+	if rr.err != nil {
+		return rr.err
+	}
+	return err
 }
 
 // A RenterHostSession is a session of the new renter-host protocol.
@@ -825,42 +886,132 @@ func CreateAnnouncement(addr NetAddress, pk types.SiaPublicKey, sk crypto.Secret
 	return append(annBytes, sig[:]...), nil
 }
 
+// CreateMultiAddressAnnouncement encodes an announcement of all the provided
+// addresses, the first of which is the primary address of the host. A single
+// address is announced using the original announcement format to remain
+// compatible with nodes that don't understand the multi-address format.
+func CreateMultiAddressAnnouncement(addrs []NetAddress, pk types.SiaPublicKey, sk crypto.SecretKey) (signedAnnouncement []byte, err error) {
+	if len(addrs) == 1 {
+		return CreateAnnouncement(addrs[0], pk, sk)
+	}
+	ha := HostAnnouncementV2{
+		Specifier: PrefixHostAnnouncementV2,
+		PublicKey: pk,
+	}
+	for _, addr := range addrs {
+		if err := addr.IsValid(); err != nil {
+			return nil, err
+		}
+		ha.Addresses = append(ha.Addresses, HostAddress{
+			Type:    addr.Type(),
+			Address: addr,
+		})
+	}
+	if err := verifyAnnouncementAddresses(ha.Addresses); err != nil {
+		return nil, err
+	}
+
+	// Sign the announcement.
+	annBytes := encoding.Marshal(ha)
+	annHash := crypto.HashBytes(annBytes)
+	sig := crypto.SignHash(annHash, sk)
+	return append(annBytes, sig[:]...), nil
+}
+
+// verifyAnnouncementAddresses checks that the addresses of a multi-address
+// announcement are unique, typed correctly and within the limits.
+func verifyAnnouncementAddresses(addrs []HostAddress) error {
+	if len(addrs) == 0 {
+		return ErrAnnNoAddresses
+	}
+	if len(addrs) > MaxAnnouncementAddresses {
+		return ErrAnnTooManyAddresses
+	}
+	seen := make(map[NetAddress]struct{})
+	for _, addr := range addrs {
+		if addr.Type != addr.Address.Type() {
+			return ErrAnnAddressType
+		}
+		if _, exists := seen[addr.Address]; exists {
+			return ErrAnnDuplicateAddress
+		}
+		seen[addr.Address] = struct{}{}
+	}
+	return nil
+}
+
 // DecodeAnnouncement decodes announcement bytes into a host announcement,
-// verifying the prefix and the signature.
+// verifying the prefix and the signature. For announcements of multiple
+// addresses the primary address is returned.
 func DecodeAnnouncement(fullAnnouncement []byte) (na NetAddress, spk types.SiaPublicKey, err error) {
-	// Read the first part of the announcement to get the intended host
-	// announcement.
-	var ha HostAnnouncement
-	dec := encoding.NewDecoder(bytes.NewReader(fullAnnouncement), len(fullAnnouncement)*3)
-	err = dec.Decode(&ha)
+	addrs, spk, err := DecodeAnnouncementAddresses(fullAnnouncement)
 	if err != nil {
 		return "", types.SiaPublicKey{}, err
 	}
+	return addrs[0], spk, nil
+}
 
-	// Check that the announcement was registered as a host announcement.
-	if ha.Specifier != PrefixHostAnnouncement {
-		return "", types.SiaPublicKey{}, ErrAnnNotAnnouncement
+// DecodeAnnouncementAddresses decodes announcement bytes of either
+// announcement format into the announced addresses and the public key of the
+// host, verifying the prefix and the signature. The first address is the
+// primary address of the host.
+func DecodeAnnouncementAddresses(fullAnnouncement []byte) (addrs []NetAddress, spk types.SiaPublicKey, err error) {
+	// Peek at the specifier to determine the format of the announcement.
+	var prefix types.Specifier
+	if len(fullAnnouncement) < len(prefix) {
+		return nil, types.SiaPublicKey{}, ErrAnnNotAnnouncement
+	}
+	copy(prefix[:], fullAnnouncement)
+
+	// Read the first part of the announcement to get the intended host
+	// announcement.
+	dec := encoding.NewDecoder(bytes.NewReader(fullAnnouncement), len(fullAnnouncement)*3)
+	var annHash crypto.Hash
+	switch prefix {
+	case PrefixHostAnnouncement:
+		var ha HostAnnouncement
+		if err := dec.Decode(&ha); err != nil {
+			return nil, types.SiaPublicKey{}, err
+		}
+		addrs = []NetAddress{ha.NetAddress}
+		spk = ha.PublicKey
+		annHash = crypto.HashObject(ha)
+	case PrefixHostAnnouncementV2:
+		var ha HostAnnouncementV2
+		if err := dec.Decode(&ha); err != nil {
+			return nil, types.SiaPublicKey{}, err
+		}
+		if err := verifyAnnouncementAddresses(ha.Addresses); err != nil {
+			return nil, types.SiaPublicKey{}, err
+		}
+		for _, addr := range ha.Addresses {
+			addrs = append(addrs, addr.Address)
+		}
+		spk = ha.PublicKey
+		annHash = crypto.HashObject(ha)
+	default:
+		// Check that the announcement was registered as a host announcement.
+		return nil, types.SiaPublicKey{}, ErrAnnNotAnnouncement
 	}
 	// Check that the public key is a recognized type of public key.
-	if ha.PublicKey.Algorithm != types.SignatureEd25519 {
-		return "", types.SiaPublicKey{}, ErrAnnUnrecognizedSignature
+	if spk.Algorithm != types.SignatureEd25519 {
+		return nil, types.SiaPublicKey{}, ErrAnnUnrecognizedSignature
 	}
 
 	// Read the signature out of the reader.
 	var sig crypto.Signature
 	err = dec.Decode(&sig)
 	if err != nil {
-		return "", types.SiaPublicKey{}, err
+		return nil, types.SiaPublicKey{}, err
 	}
 	// Verify the signature.
 	var pk crypto.PublicKey
-	copy(pk[:], ha.PublicKey.Key)
-	annHash := crypto.HashObject(ha)
+	copy(pk[:], spk.Key)
 	err = crypto.VerifyHash(annHash, pk, sig)
 	if err != nil {
-		return "", types.SiaPublicKey{}, err
+		return nil, types.SiaPublicKey{}, err
 	}
-	return ha.NetAddress, ha.PublicKey, nil
+	return addrs, spk, nil
 }
 
 // IsOOSErr is a helper function to determine whether an error from a host is
@@ -4,7 +4,10 @@ import (
 	"bytes"
 	"testing"
 
+	"golang.org/x/crypto/chacha20poly1305"
+
 	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
 
@@ -158,3 +161,117 @@ func TestRenterPayoutsPreTax(t *testing.T) {
 		t.Fatal("Negative currency returned for host collateral", hostCollateral)
 	}
 }
+
+// TestMultiAddressAnnouncementHandling checks that
+// CreateMultiAddressAnnouncement and DecodeAnnouncementAddresses work together
+// correctly.
+func TestMultiAddressAnnouncementHandling(t *testing.T) {
+	t.Parallel()
+
+	// Create the keys that will be used to generate the announcement.
+	sk, pk := crypto.GenerateKeyPair()
+	spk := types.SiaPublicKey{
+		Algorithm: types.SignatureEd25519,
+		Key:       pk[:],
+	}
+	addrs := []NetAddress{"203.0.113.1:9982", "[2001:db8::1]:9982", "f.o:1234"}
+
+	// Generate and decode the announcement.
+	annBytes, err := CreateMultiAddressAnnouncement(addrs, spk, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	decAddrs, decPubKey, err := DecodeAnnouncementAddresses(annBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decAddrs) != len(addrs) {
+		t.Fatal("decoded announcement has the wrong number of addresses", decAddrs)
+	}
+	for i := range addrs {
+		if decAddrs[i] != addrs[i] {
+			t.Error("decoded announcement has the wrong net address", decAddrs[i])
+		}
+	}
+	if !decPubKey.Equals(spk) {
+		t.Error("decoded announcement has the wrong public key")
+	}
+	// DecodeAnnouncement returns the primary address.
+	decAddr, _, err := DecodeAnnouncement(annBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if decAddr != addrs[0] {
+		t.Error("decoded announcement has the wrong primary address", decAddr)
+	}
+
+	// Corrupt the final byte of the signature.
+	annBytes[len(annBytes)-1]++
+	_, _, err = DecodeAnnouncementAddresses(annBytes)
+	if err != crypto.ErrInvalidSignature {
+		t.Error(err)
+	}
+
+	// A single address uses the original announcement format.
+	annBytes, err = CreateMultiAddressAnnouncement(addrs[:1], spk, sk)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.HasPrefix(annBytes, PrefixHostAnnouncement[:]) {
+		t.Error("single address announcement doesn't use the original format")
+	}
+	decAddrs, _, err = DecodeAnnouncementAddresses(annBytes)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(decAddrs) != 1 || decAddrs[0] != addrs[0] {
+		t.Error("decoded announcement has the wrong net addresses", decAddrs)
+	}
+
+	// Duplicate and too many addresses are rejected.
+	_, err = CreateMultiAddressAnnouncement([]NetAddress{addrs[0], addrs[0]}, spk, sk)
+	if err != ErrAnnDuplicateAddress {
+		t.Error(err)
+	}
+	_, err = CreateMultiAddressAnnouncement([]NetAddress{"a.b:1", "a.b:2", "a.b:3", "a.b:4", "a.b:5"}, spk, sk)
+	if err != ErrAnnTooManyAddresses {
+		t.Error(err)
+	}
+
+	// An address with the wrong type is rejected when decoding.
+	ha := HostAnnouncementV2{
+		Specifier: PrefixHostAnnouncementV2,
+		Addresses: []HostAddress{{Type: NetAddressTypeDNS, Address: addrs[0]}},
+		PublicKey: spk,
+	}
+	annBytes = encoding.Marshal(ha)
+	sig := crypto.SignHash(crypto.HashBytes(annBytes), sk)
+	_, _, err = DecodeAnnouncementAddresses(append(annBytes, sig[:]...))
+	if err != ErrAnnAddressType {
+		t.Error(err)
+	}
+}
+
+// TestReadRPCResponseError checks that ReadRPCResponse returns the RPCError
+// sent by the other side with its type intact.
+func TestReadRPCResponseError(t *testing.T) {
+	t.Parallel()
+	aead, err := chacha20poly1305.New(make([]byte, chacha20poly1305.KeySize))
+	if err != nil {
+		t.Fatal(err)
+	}
+	var buf bytes.Buffer
+	sent := &RPCError{Type: RPCErrorSectorNotFound, Description: "sector not found"}
+	if err := WriteRPCResponse(&buf, aead, nil, sent); err != nil {
+		t.Fatal(err)
+	}
+	var resp LoopReadResponse
+	err = ReadRPCResponse(&buf, aead, &resp, RPCMinLen)
+	rpcErr, ok := err.(*RPCError)
+	if !ok {
+		t.Fatalf("expected *RPCError but got %T: %v", err, err)
+	}
+	if rpcErr.Type != RPCErrorSectorNotFound || rpcErr.Description != sent.Description {
+		t.Fatal("wrong error", rpcErr)
+	}
+}
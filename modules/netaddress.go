@@ -15,8 +15,25 @@ import (
 // string length prefix.
 const MaxEncodedNetAddressLength = 266
 
-// A NetAddress contains the information needed to contact a peer.
-type NetAddress string
+const (
+	// NetAddressTypeIPv4 is the type of a NetAddress whose host is an IPv4
+	// address.
+	NetAddressTypeIPv4 NetAddressType = "ipv4"
+	// NetAddressTypeIPv6 is the type of a NetAddress whose host is an IPv6
+	// address.
+	NetAddressTypeIPv6 NetAddressType = "ipv6"
+	// NetAddressTypeDNS is the type of a NetAddress whose host is a hostname
+	// that needs to be resolved.
+	NetAddressTypeDNS NetAddressType = "dns"
+)
+
+type (
+	// A NetAddress contains the information needed to contact a peer.
+	NetAddress string
+
+	// NetAddressType describes how the host of a NetAddress is specified.
+	NetAddressType string
+)
 
 // Host removes the port from a NetAddress, returning just the host. If the
 // address is not of the form "host:port" the empty string is returned. The
@@ -32,6 +49,20 @@ func (na NetAddress) Host() string {
 	return host
 }
 
+// Type returns the type of the NetAddress, which is determined by whether its
+// host is an IPv4 address, an IPv6 address or a hostname.
+func (na NetAddress) Type() NetAddressType {
+	ip := net.ParseIP(na.Host())
+	switch {
+	case ip == nil:
+		return NetAddressTypeDNS
+	case ip.To4() != nil:
+		return NetAddressTypeIPv4
+	default:
+		return NetAddressTypeIPv6
+	}
+}
+
 // Port returns the NetAddress object's port number. If the address is not of
 // the form "host:port" the empty string is returned. The port will still be
 // returned for invalid NetAddresses (e.g. "localhost:0" will return "0"), but
@@ -168,3 +199,22 @@ func (na NetAddress) IsStdValid() error {
 
 	return nil
 }
+
+// DialAddresses dials the provided addresses in order until a connection can
+// be established, returning the connection and the address it was established
+// with. This allows reaching a host on an alternate address if its primary
+// address is unreachable.
+func DialAddresses(dialer *net.Dialer, addrs []NetAddress) (net.Conn, NetAddress, error) {
+	if len(addrs) == 0 {
+		return nil, "", errors.New("no addresses to dial")
+	}
+	var errs []error
+	for _, addr := range addrs {
+		conn, err := dialer.Dial("tcp", string(addr))
+		if err == nil {
+			return conn, addr, nil
+		}
+		errs = append(errs, err)
+	}
+	return nil, "", build.ComposeErrors(errs...)
+}
@@ -320,3 +320,23 @@ func TestIsLocal(t *testing.T) {
 		}
 	}
 }
+
+// TestNetAddressType checks that the type of a NetAddress is determined by its
+// host.
+func TestNetAddressType(t *testing.T) {
+	testSet := []struct {
+		query        NetAddress
+		expectedType NetAddressType
+	}{
+		{"203.0.113.1:9982", NetAddressTypeIPv4},
+		{"[2001:db8::1]:9982", NetAddressTypeIPv6},
+		{"[::ffff:203.0.113.1]:9982", NetAddressTypeIPv4},
+		{"siahost.example.net:9982", NetAddressTypeDNS},
+		{"localhost:9982", NetAddressTypeDNS},
+	}
+	for _, test := range testSet {
+		if test.query.Type() != test.expectedType {
+			t.Error("test failed:", test, test.query.Type())
+		}
+	}
+}
@@ -64,6 +64,40 @@ const (
 	HostDBActiveWhitelist
 )
 
+// HostDBFilterRules are the rules that determine which hosts are listed by the
+// hostdb's filter. Listed hosts are the only hosts used in whitelist mode and
+// are never used in blacklist mode.
+//
+// A host can be matched by its public key, by the hostname or IP of its
+// NetAddress and by CIDR subnets. A netaddress rule starting with "*." matches
+// all subdomains of a domain. A subnet rule matches the IP of a host's
+// NetAddress and the subnets the host was resolved to. If multiple rules match
+// a host, the most specific rule decides: key rules take precedence over
+// netaddress rules, which take precedence over subnet rules, and longer subnet
+// prefixes take precedence over shorter ones. If a listing and an excluding
+// rule are equally specific, the excluding rule wins.
+//
+// Hosts can't be matched by their autonomous system number since the node
+// has no IP-to-ASN database. The subnets announced by an autonomous system
+// can be used as subnet rules instead.
+type HostDBFilterRules struct {
+	// Hosts, NetAddresses and Subnets are the rules that list a host.
+	Hosts        []types.SiaPublicKey `json:"hosts"`
+	NetAddresses []string             `json:"netaddresses"`
+	Subnets      []string             `json:"subnets"`
+
+	// ExcludedHosts, ExcludedNetAddresses and ExcludedSubnets are the rules
+	// that prevent a host from being listed.
+	ExcludedHosts        []types.SiaPublicKey `json:"excludedhosts"`
+	ExcludedNetAddresses []string             `json:"excludednetaddresses"`
+	ExcludedSubnets      []string             `json:"excludedsubnets"`
+}
+
+// Empty returns true if the rules can't list any host.
+func (r HostDBFilterRules) Empty() bool {
+	return len(r.Hosts) == 0 && len(r.NetAddresses) == 0 && len(r.Subnets) == 0
+}
+
 // Filesystem related consts.
 const (
 	// DefaultDirPerm defines the default permissions used for a new dir if no
@@ -119,6 +153,15 @@ const (
 	// renter's persistent data.
 	RenterDir = "renter"
 
+	// RenterProfilesDir is the name of the directory that is used to store the
+	// persistent data of the additional renter profiles. Every profile gets its
+	// own subdirectory named after the profile.
+	RenterProfilesDir = "renterprofiles"
+
+	// DefaultRenterProfile is the name of the renter profile that refers to
+	// the node's main renter.
+	DefaultRenterProfile = "default"
+
 	// FileSystemRoot is the name of the directory that is used as the root of
 	// the renter's filesystem.
 	FileSystemRoot = "fs"
@@ -288,6 +331,26 @@ type Allowance struct {
 	MaxSectorAccessPrice      types.Currency `json:"maxsectoraccessprice"`
 	MaxStoragePrice           types.Currency `json:"maxstorageprice"`
 	MaxUploadBandwidthPrice   types.Currency `json:"maxuploadbandwidthprice"`
+
+	// The following fields define the migration policy of the contractor.
+	// Contracts with hosts that are more expensive than
+	// MigrationPriceMultiple times the median host or that have a recent
+	// success rate below MigrationMinSuccessRate are marked for migration and
+	// their data is moved to other hosts before the contracts are dropped. A
+	// value of 0 disables the corresponding check.
+	MigrationPriceMultiple  float64 `json:"migrationpricemultiple"`
+	MigrationMinSuccessRate float64 `json:"migrationminsuccessrate"`
+
+	// WalletAccount is the wallet account that funds the contracts of the
+	// renter and receives their refunds. An empty name selects the primary
+	// account.
+	WalletAccount string `json:"walletaccount"`
+
+	// FeeTarget is the number of blocks within which the transactions that
+	// form and renew contracts should be confirmed. The transaction fees are
+	// estimated for the target instead of using the maximum estimate of the
+	// transaction pool. A value of 0 uses the maximum estimate.
+	FeeTarget types.BlockHeight `json:"feetarget"`
 }
 
 // Active returns true if and only if this allowance has been set in the
@@ -314,6 +377,40 @@ type ContractUtility struct {
 	Locked bool
 }
 
+// ContractMigration contains information about a contract whose data is being
+// migrated to other hosts.
+type ContractMigration struct {
+	// Reason describes why the contract was marked for migration.
+	Reason string `json:"reason"`
+
+	// MarkedHeight is the height at which the contract was marked for
+	// migration. Marked contracts are no longer used for uploads.
+	MarkedHeight types.BlockHeight `json:"markedheight"`
+
+	// Active indicates that the repair loop is moving the data of the
+	// contract to other hosts. StartHeight is the height at which the
+	// migration became active.
+	Active      bool              `json:"active"`
+	StartHeight types.BlockHeight `json:"startheight"`
+}
+
+// ContractAuditStatus contains the results of the audits of a contract. An
+// audit downloads a random segment of a random sector of the contract and
+// verifies the Merkle proof provided by the host.
+type ContractAuditStatus struct {
+	// LastAudit is the time of the last audit of the contract.
+	LastAudit time.Time `json:"lastaudit"`
+
+	// SuccessfulAudits and FailedAudits count the audits the host passed and
+	// failed.
+	SuccessfulAudits uint64 `json:"successfulaudits"`
+	FailedAudits     uint64 `json:"failedaudits"`
+
+	// LostSectors is the number of sectors which repeatedly failed audits
+	// and were marked as lost.
+	LostSectors uint64 `json:"lostsectors"`
+}
+
 // ContractWatchStatus provides information about the status of a contract in
 // the renter's watchdog.
 type ContractWatchStatus struct {
@@ -468,6 +565,11 @@ type HostDBEntry struct {
 	// FirstSeen is the last block height at which this host was announced.
 	FirstSeen types.BlockHeight `json:"firstseen"`
 
+	// NetAddresses are all the addresses announced by the host, starting with
+	// its primary address. The alternate addresses are tried if the host
+	// can't be reached on its primary address.
+	NetAddresses []NetAddress `json:"netaddresses"`
+
 	// Measurements that have been taken on the host. The most recent
 	// measurements are kept in full detail, historic ones are compressed into
 	// the historic values.
@@ -496,6 +598,18 @@ type HostDBEntry struct {
 	Filtered bool `json:"filtered"`
 }
 
+// Addresses returns the addresses of the host, starting with its primary
+// address followed by the alternate addresses it announced.
+func (he HostDBEntry) Addresses() []NetAddress {
+	addrs := []NetAddress{he.NetAddress}
+	for _, addr := range he.NetAddresses {
+		if addr != he.NetAddress {
+			addrs = append(addrs, addr)
+		}
+	}
+	return addrs
+}
+
 // HostDBScan represents a single scan event.
 type HostDBScan struct {
 	Timestamp time.Time `json:"timestamp"`
@@ -556,6 +670,13 @@ type RenterSettings struct {
 	MaxUploadSpeed   int64         `json:"maxuploadspeed"`
 	MaxDownloadSpeed int64         `json:"maxdownloadspeed"`
 	UploadsStatus    UploadsStatus `json:"uploadsstatus"`
+
+	// AuditInterval is the time between two audits of the renter's hosts. A
+	// value of 0 disables audits. MaxAuditPrice is the maximum amount the
+	// renter is willing to pay for a single audit. A value of 0 means no
+	// limit.
+	AuditInterval time.Duration  `json:"auditinterval"`
+	MaxAuditPrice types.Currency `json:"maxauditprice"`
 }
 
 // UploadsStatus contains information about the Renter's Uploads
@@ -760,6 +881,10 @@ type Renter interface {
 	// nil, the backup will be encrypted using the provided secret.
 	CreateBackup(dst string, secret []byte) error
 
+	// FormContract forms a contract with the specified host using the provided
+	// funds.
+	FormContract(pk types.SiaPublicKey, funds types.Currency) (RenterContract, error)
+
 	// LoadBackup loads the siafiles of a previously created backup into the
 	// renter. If the backup is encrypted, secret will be used to decrypt it.
 	// Otherwise the argument is ignored.
@@ -800,6 +925,21 @@ type Renter interface {
 	// billing period.
 	PeriodSpending() (ContractorSpending, error)
 
+	// PinContract pins a contract, preventing contract maintenance from
+	// marking it !GoodForUpload or !GoodForRenew.
+	PinContract(id types.FileContractID) error
+
+	// PinnedContract returns whether a contract is pinned.
+	PinnedContract(id types.FileContractID) bool
+
+	// MigratingContract returns the migration of a contract if the contract
+	// was marked for migration.
+	MigratingContract(id types.FileContractID) (ContractMigration, bool)
+
+	// ContractAuditStatus returns the audit results of a contract if the
+	// contract was audited before.
+	ContractAuditStatus(id types.FileContractID) (ContractAuditStatus, bool)
+
 	// RecoverableContracts returns the contracts that the contractor deems
 	// recoverable. That means they are not expired yet and also not part of the
 	// active contracts. Usually this should return an empty slice unless the host
@@ -810,12 +950,23 @@ type Renter interface {
 	// contracts is in progress and if it is, the current progress of the scan.
 	RecoveryScanStatus() (bool, types.BlockHeight)
 
+	// RefreshContract adds funds to a contract by renewing it without changing
+	// its end height. If funds is zero, the contractor picks the funding.
+	RefreshContract(id types.FileContractID, funds types.Currency) (RenterContract, error)
+
 	// RefreshedContract checks if the contract was previously refreshed
 	RefreshedContract(fcid types.FileContractID) bool
 
+	// RenewContract renews a contract before it enters the renew window. If
+	// funds is zero, the contractor picks the funding.
+	RenewContract(id types.FileContractID, funds types.Currency) (RenterContract, error)
+
 	// SetFileStuck sets the 'stuck' status of a file.
 	SetFileStuck(siaPath SiaPath, stuck bool) error
 
+	// UnpinContract removes the pin from a contract.
+	UnpinContract(id types.FileContractID) error
+
 	// UploadBackup uploads a backup to hosts, such that it can be retrieved
 	// using only the seed.
 	UploadBackup(src string, name string) error
@@ -866,9 +1017,16 @@ type Renter interface {
 	// Filter returns the renter's hostdb's filterMode and filteredHosts
 	Filter() (FilterMode, map[string]types.SiaPublicKey, error)
 
+	// FilterRules returns the renter's hostdb's filterMode and filter rules.
+	FilterRules() (FilterMode, HostDBFilterRules, error)
+
 	// SetFilterMode sets the renter's hostdb filter mode
 	SetFilterMode(fm FilterMode, hosts []types.SiaPublicKey) error
 
+	// SetFilterRules sets the renter's hostdb filter mode using the provided
+	// rules.
+	SetFilterRules(fm FilterMode, rules HostDBFilterRules) error
+
 	// Host provides the DB entry and score breakdown for the requested host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool, error)
 
@@ -1016,9 +1174,15 @@ type HostDB interface {
 	// Filter returns the hostdb's filterMode and filteredHosts
 	Filter() (FilterMode, map[string]types.SiaPublicKey, error)
 
+	// FilterRules returns the hostdb's filterMode and filter rules.
+	FilterRules() (FilterMode, HostDBFilterRules, error)
+
 	// SetFilterMode sets the renter's hostdb filter mode
 	SetFilterMode(lm FilterMode, hosts []types.SiaPublicKey) error
 
+	// SetFilterRules sets the hostdb's filter mode using the provided rules.
+	SetFilterRules(fm FilterMode, rules HostDBFilterRules) error
+
 	// Host returns the HostDBEntry for a given host.
 	Host(pk types.SiaPublicKey) (HostDBEntry, bool, error)
 
@@ -1066,6 +1230,29 @@ type HostDB interface {
 	UpdateContracts([]RenterContract) error
 }
 
+// RenterProfiles manages additional, named renters that run next to the
+// node's main renter. Every profile has its own allowance, contracts, hostdb
+// and filesystem but shares the gateway, consensus set, transaction pool and
+// wallet with the rest of the node.
+type RenterProfiles interface {
+	// Close closes the renters of all profiles.
+	Close() error
+
+	// CreateProfile creates and starts a new renter profile with the given
+	// name.
+	CreateProfile(name string) error
+
+	// Profile returns the renter of the profile with the given name.
+	Profile(name string) (Renter, error)
+
+	// Profiles returns the names of all profiles, sorted alphabetically.
+	Profiles() []string
+
+	// RemoveProfile stops the renter of the profile with the given name and
+	// removes the profile. The profile's data is kept on disk.
+	RemoveProfile(name string) error
+}
+
 // SkyfileMetadata is all of the metadata that gets placed into the first 4096
 // bytes of the skyfile, and is used to set the metadata of the file when
 // writing back to disk. The data is json-encoded when it is placed into the
@@ -0,0 +1,273 @@
+package renter
+
+// audit.go contains the audit worker of the renter. The audit worker
+// periodically downloads a random segment of a random sector from every host
+// the renter has a contract with. The host has to provide a Merkle proof for
+// the segment which is verified against the sector root stored in the
+// contract. A passed or failed audit is counted towards the host's
+// interactions in the hostdb. Only incorrect data and missing sectors fail an
+// audit, transport errors don't. Sectors which fail multiple audits in a row are
+// considered lost and their pieces are removed from the siafiles to trigger a
+// repair before the host's storage proof is due.
+
+import (
+	"math/bits"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/renter/proto"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// auditFailed returns whether the error of downloading the audited segment
+// means that the host failed the audit. Only incorrect sector data or Merkle
+// proofs and sectors that the host doesn't store count as failures. Other
+// errors like timeouts or a busy host are left to the hostdb scans.
+func auditFailed(err error) bool {
+	return errors.Contains(err, proto.ErrBadSectorData) || errors.Contains(err, proto.ErrSectorNotFound)
+}
+
+// pendingAudit is a sector of a contract that failed its last audit. The same
+// sector is audited again in the next round.
+type pendingAudit struct {
+	index    int
+	root     crypto.Hash
+	failures int
+}
+
+// auditCost returns the estimated cost of downloading a single segment with a
+// Merkle proof from a host with the given settings.
+func auditCost(hes modules.HostExternalSettings) types.Currency {
+	proofHashes := uint64(2 * bits.Len64(modules.SectorSize/crypto.SegmentSize))
+	bandwidth := crypto.SegmentSize + proofHashes*crypto.HashSize
+	if bandwidth < modules.RPCMinLen {
+		bandwidth = modules.RPCMinLen
+	}
+	return hes.BaseRPCPrice.Add(hes.SectorAccessPrice).Add(hes.DownloadBandwidthPrice.Mul64(bandwidth))
+}
+
+// ContractAuditStatus returns the audit results of a contract if the contract
+// was audited before.
+func (r *Renter) ContractAuditStatus(id types.FileContractID) (modules.ContractAuditStatus, bool) {
+	lockID := r.mu.RLock()
+	defer r.mu.RUnlock(lockID)
+	status, ok := r.persist.ContractAudits[id.String()]
+	return status, ok
+}
+
+// managedAuditContract audits a single contract. It returns the root of the
+// audited sector if the sector is considered lost.
+func (r *Renter) managedAuditContract(rc modules.RenterContract, maxPrice types.Currency) (crypto.Hash, bool, error) {
+	// Nothing to audit if the contract doesn't store any data.
+	if len(rc.Transaction.FileContractRevisions) == 0 {
+		return crypto.Hash{}, false, nil
+	}
+	numSectors := rc.Transaction.FileContractRevisions[0].NewFileSize / modules.SectorSize
+	if numSectors == 0 {
+		return crypto.Hash{}, false, nil
+	}
+
+	// Audit the sector that failed the last audit again or pick a random
+	// one.
+	id := r.mu.RLock()
+	pending, isPending := r.pendingAudits[rc.ID]
+	r.mu.RUnlock(id)
+	if !isPending || uint64(pending.index) >= numSectors {
+		index := fastrand.Intn(int(numSectors))
+		root, err := r.hostContractor.MerkleRoot(rc.ID, index)
+		if err != nil {
+			return crypto.Hash{}, false, errors.AddContext(err, "unable to get sector root")
+		}
+		// The roots of sectors which were uploaded before the contract
+		// tracked its sector roots are unknown and can't be audited.
+		if root == (crypto.Hash{}) {
+			return crypto.Hash{}, false, nil
+		}
+		pending = pendingAudit{index: index, root: root}
+	}
+
+	// Open a session with the host. If the host can't be reached, it is
+	// penalized by the hostdb scans and not by the audit.
+	session, err := r.hostContractor.Session(rc.HostPublicKey, r.tg.StopChan())
+	if err != nil {
+		return crypto.Hash{}, false, errors.AddContext(err, "unable to open session")
+	}
+	defer session.Close()
+
+	// Check the price of the audit. Skipping an audit doesn't count as a
+	// failure.
+	cost := auditCost(session.HostSettings())
+	if !maxPrice.IsZero() && cost.Cmp(maxPrice) > 0 {
+		return crypto.Hash{}, false, nil
+	}
+	if rc.RenterFunds.Cmp(cost) < 0 {
+		return crypto.Hash{}, false, nil
+	}
+
+	// Download a random segment of the sector. The session verifies the
+	// Merkle proof and updates the host's interactions.
+	offset := uint32(fastrand.Intn(int(modules.SectorSize/crypto.SegmentSize))) * crypto.SegmentSize
+	_, auditErr := session.Download(pending.root, offset, crypto.SegmentSize)
+	if auditErr != nil && !auditFailed(auditErr) {
+		// The sector is audited again in the next round.
+		return crypto.Hash{}, false, errors.AddContext(auditErr, "unable to download audited segment")
+	}
+
+	// Update the audit results.
+	id = r.mu.Lock()
+	defer r.mu.Unlock(id)
+	status := r.persist.ContractAudits[rc.ID.String()]
+	status.LastAudit = time.Now()
+	lost := false
+	if auditErr == nil {
+		status.SuccessfulAudits++
+		delete(r.pendingAudits, rc.ID)
+	} else {
+		r.log.Debugf("Contract %v failed audit of sector %v: %v", rc.ID, pending.root, auditErr)
+		status.FailedAudits++
+		pending.failures++
+		r.pendingAudits[rc.ID] = pending
+		if pending.failures >= auditMaxFailures {
+			status.LostSectors++
+			delete(r.pendingAudits, rc.ID)
+			lost = true
+		}
+	}
+	r.persist.ContractAudits[rc.ID.String()] = status
+	return pending.root, lost, r.saveSync()
+}
+
+// lostSectors are the roots of the sectors of a host which are considered
+// lost.
+type lostSectors struct {
+	hostKey types.SiaPublicKey
+	roots   map[crypto.Hash]struct{}
+}
+
+// managedAuditContracts audits all the renter's contracts once. The pieces of
+// the sectors that are considered lost after the round are removed from the
+// siafiles at once.
+func (r *Renter) managedAuditContracts(maxPrice types.Currency) {
+	lost := make(map[string]lostSectors)
+	for _, rc := range r.hostContractor.Contracts() {
+		select {
+		case <-r.tg.StopChan():
+			return
+		default:
+		}
+		root, isLost, err := r.managedAuditContract(rc, maxPrice)
+		if err != nil {
+			r.log.Debugf("Unable to audit contract %v: %v", rc.ID, err)
+		}
+		if !isLost {
+			continue
+		}
+		r.log.Printf("Sector %v of contract %v failed %v audits in a row and is considered lost", root, rc.ID, auditMaxFailures)
+		ls, exists := lost[rc.HostPublicKey.String()]
+		if !exists {
+			ls = lostSectors{
+				hostKey: rc.HostPublicKey,
+				roots:   make(map[crypto.Hash]struct{}),
+			}
+			lost[rc.HostPublicKey.String()] = ls
+		}
+		ls.roots[root] = struct{}{}
+	}
+	if len(lost) == 0 {
+		return
+	}
+	if err := r.managedMarkSectorsLost(lost); err != nil {
+		r.log.Println("WARN: unable to mark lost sectors:", err)
+	}
+}
+
+// managedMarkSectorsLost removes the pieces of lost sectors from all the
+// siafiles that reference them. The lost sectors are indexed by the host's
+// public key, so the filesystem is only walked once for all of them. The
+// metadata of the affected directories is bubbled afterwards which triggers a
+// repair of the chunks.
+func (r *Renter) managedMarkSectorsLost(lost map[string]lostSectors) error {
+	rootDir := r.staticFileSystem.DirPath(modules.RootSiaPath())
+	dirs := make(map[modules.SiaPath]struct{})
+	err := r.staticFileSystem.Walk(modules.RootSiaPath(), func(path string, info os.FileInfo, err error) error {
+		// This error is non-nil if filepath.Walk couldn't stat a file or
+		// folder.
+		if err != nil {
+			return err
+		}
+		// Only siafiles can contain pieces.
+		if info.IsDir() || filepath.Ext(path) != modules.SiaFileExtension {
+			return nil
+		}
+		var siaPath modules.SiaPath
+		if err := siaPath.FromSysPath(path, rootDir); err != nil {
+			return err
+		}
+		entry, err := r.staticFileSystem.OpenSiaFile(siaPath)
+		if err != nil {
+			return err
+		}
+		defer entry.Close()
+		removed := 0
+		for _, ls := range lost {
+			n, err := entry.RemovePieces(ls.hostKey, ls.roots)
+			if err != nil {
+				return errors.AddContext(err, "unable to remove pieces of "+siaPath.String())
+			}
+			removed += n
+		}
+		if removed == 0 {
+			return nil
+		}
+		dirSiaPath, err := siaPath.Dir()
+		if err != nil {
+			return err
+		}
+		dirs[dirSiaPath] = struct{}{}
+		return nil
+	})
+	// Bubble the directories of the affected files even if the walk failed
+	// midway.
+	for dir := range dirs {
+		go r.callThreadedBubbleMetadata(dir)
+	}
+	return err
+}
+
+// threadedAuditLoop periodically audits the renter's contracts.
+func (r *Renter) threadedAuditLoop() {
+	err := r.tg.Add()
+	if err != nil {
+		return
+	}
+	defer r.tg.Done()
+
+	var lastAudit time.Time
+	for {
+		select {
+		case <-r.tg.StopChan():
+			return
+		case <-time.After(auditCheckInterval):
+		}
+		// Check if an audit round is due.
+		id := r.mu.RLock()
+		interval := r.persist.AuditInterval
+		maxPrice := r.persist.MaxAuditPrice
+		r.mu.RUnlock(id)
+		if interval == 0 || time.Since(lastAudit) < interval {
+			continue
+		}
+		// Don't audit while the renter is offline.
+		if !r.g.Online() {
+			continue
+		}
+		r.managedAuditContracts(maxPrice)
+		lastAudit = time.Now()
+	}
+}
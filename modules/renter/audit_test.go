@@ -0,0 +1,90 @@
+package renter
+
+import (
+	"errors"
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/modules/renter/proto"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestAuditFailed is a unit test for auditFailed.
+func TestAuditFailed(t *testing.T) {
+	tests := []struct {
+		err    error
+		failed bool
+	}{
+		{proto.ErrBadSectorData, true},
+		{proto.ErrSectorNotFound, true},
+		{errors.New("could not find the desired sector"), false},
+		{modules.ErrHostFault, false},
+		{errors.New("i/o timeout"), false},
+		{errors.New("connection reset by peer"), false},
+	}
+	for _, test := range tests {
+		if failed := auditFailed(test.err); failed != test.failed {
+			t.Errorf("%v: expected %v but got %v", test.err, test.failed, failed)
+		}
+	}
+}
+
+// TestMarkSectorsLost checks that managedMarkSectorsLost removes the pieces of
+// lost sectors from the renter's siafiles.
+func TestMarkSectorsLost(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	// Create a file with a piece on two hosts.
+	entry, err := rt.renter.newRenterTestFile()
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer entry.Close()
+	hpk1 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: fastrand.Bytes(crypto.PublicKeySize)}
+	hpk2 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: fastrand.Bytes(crypto.PublicKeySize)}
+	var root crypto.Hash
+	fastrand.Read(root[:])
+	if err := entry.AddPiece(hpk1, 0, 0, root); err != nil {
+		t.Fatal(err)
+	}
+	if err := entry.AddPiece(hpk2, 0, 0, root); err != nil {
+		t.Fatal(err)
+	}
+
+	// Mark the sector of the first host as lost.
+	lost := map[string]lostSectors{
+		hpk1.String(): {
+			hostKey: hpk1,
+			roots:   map[crypto.Hash]struct{}{root: {}},
+		},
+	}
+	if err := rt.renter.managedMarkSectorsLost(lost); err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := entry.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pieces[0]) != 1 {
+		t.Fatalf("Expected 1 piece but got %v", len(pieces[0]))
+	}
+	if !pieces[0][0].HostPubKey.Equals(hpk2) {
+		t.Fatal("The wrong piece was removed")
+	}
+
+	// The contract wasn't audited yet.
+	if _, ok := rt.renter.ContractAuditStatus(types.FileContractID{}); ok {
+		t.Fatal("Contract shouldn't have an audit status")
+	}
+}
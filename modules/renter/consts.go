@@ -284,6 +284,27 @@ var (
 	}).(time.Duration)
 )
 
+// Constants that tune the audit worker.
+var (
+	// auditCheckInterval defines how often the audit loop checks whether an
+	// audit round is due. Audit rounds themselves happen at the interval set
+	// in the renter settings.
+	auditCheckInterval = build.Select(build.Var{
+		Dev:      10 * time.Second,
+		Standard: 1 * time.Minute,
+		Testing:  time.Second,
+	}).(time.Duration)
+
+	// auditMaxFailures is the number of consecutive failed audits of the same
+	// sector after which the sector is considered lost and its pieces are
+	// removed from the siafiles.
+	auditMaxFailures = build.Select(build.Var{
+		Dev:      3,
+		Standard: 3,
+		Testing:  2,
+	}).(int)
+)
+
 // Constants which don't fit into another category very well.
 const (
 	// defaultFilePerm defines the default permissions used for a new file if no
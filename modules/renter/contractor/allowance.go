@@ -90,6 +90,11 @@ func (c *Contractor) SetAllowance(a modules.Allowance) error {
 	} else if !c.cs.Synced() {
 		return errAllowanceNotSynced
 	}
+	// Contracts are funded from the wallet account, so an unknown account
+	// would make every formation and renewal fail.
+	if _, err := c.wallet.ConfirmedAccountBalance(a.WalletAccount); err != nil {
+		return errors.New("invalid wallet account: " + err.Error())
+	}
 
 	c.log.Println("INFO: setting allowance to", a)
 	c.mu.Lock()
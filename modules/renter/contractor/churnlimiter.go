@@ -191,8 +191,12 @@ func (cl *churnLimiter) managedAggregateAndMaxChurn() (uint64, uint64) {
 }
 
 // managedCanChurnContract returns true if and only if the churnLimiter can
-// churn the contract right now, given its current budget.
+// churn the contract right now, given its current budget. Pinned contracts are
+// never churned.
 func (cl *churnLimiter) managedCanChurnContract(contract modules.RenterContract) bool {
+	if cl.contractor.managedContractPinned(contract.ID) {
+		return false
+	}
 	size := contract.Transaction.FileContractRevisions[0].NewFileSize
 	maxPeriodChurn := cl.managedMaxPeriodChurn()
 	maxChurnBudget := cl.managedMaxChurnBudget()
@@ -226,6 +230,9 @@ func (c *Contractor) managedMarkContractsUtility() error {
 	// judgment.
 	suggestedUpdateQueue := make([]contractScoreAndUtil, 0)
 
+	// The median price of the hosts is used by the migration policy.
+	medianPrice := c.managedMedianSectorPrice()
+
 	// Update utility fields for each contract.
 	for _, contract := range c.staticContracts.ViewAll() {
 		u := contract.Utility
@@ -244,6 +251,37 @@ func (c *Contractor) managedMarkContractsUtility() error {
 			continue
 		}
 
+		// Pinned contracts are neither migrated nor churned and stay
+		// GoodForUpload and GoodForRenew no matter the host's score. They
+		// still have to pass the critical checks.
+		if c.managedContractPinned(contract.ID) {
+			u, needsUpdate = c.managedCriticalUtilityChecks(contract, host)
+			if !needsUpdate {
+				u.GoodForUpload = true
+				u.GoodForRenew = true
+			}
+			if err = c.managedAcquireAndUpdateContractUtility(contract.ID, u); err != nil {
+				return errors.AddContext(err, "unable to update utility of pinned contract")
+			}
+			continue
+		}
+
+		// Apply the migration policy. Contracts with an active migration are
+		// neither GFU nor GFR which causes the repair loop to move their data
+		// to other hosts.
+		migrating, active, err := c.managedMigrationCheck(contract, host, medianPrice)
+		if err != nil {
+			return errors.AddContext(err, "unable to apply migration policy")
+		}
+		if active {
+			u.GoodForUpload = false
+			u.GoodForRenew = false
+			if err = c.managedAcquireAndUpdateMigratingContractUtility(contract.ID, u); err != nil {
+				return errors.AddContext(err, "unable to update utility of migrating contract")
+			}
+			continue
+		}
+
 		// Do critical contract checks and update the utility if any checks fail.
 		u, needsUpdate = c.managedCriticalUtilityChecks(contract, host)
 		if needsUpdate {
@@ -254,6 +292,17 @@ func (c *Contractor) managedMarkContractsUtility() error {
 			continue
 		}
 
+		// Contracts that are marked for migration are no longer used for
+		// uploads but keep their data until the migration starts.
+		if migrating {
+			u.GoodForUpload = false
+			u.GoodForRenew = true
+			if err = c.managedAcquireAndUpdateContractUtility(contract.ID, u); err != nil {
+				return errors.AddContext(err, "unable to update utility of contract marked for migration")
+			}
+			continue
+		}
+
 		sb, err := c.hdb.ScoreBreakdown(host)
 		if err != nil {
 			return err
@@ -80,4 +80,15 @@ func TestCanChurnContract(t *testing.T) {
 	if ok {
 		t.Fatal("Expected not to be able to churn contract")
 	}
+
+	// Test: pinned contracts are never churned.
+	pinned := contractWithSize(1)
+	pinned.ID = types.FileContractID{1}
+	cl.contractor.pinnedContracts = map[types.FileContractID]struct{}{pinned.ID: {}}
+	cl.remainingChurnBudget = 500
+	cl.aggregateCurrentPeriodChurn = 0
+	ok = cl.managedCanChurnContract(pinned)
+	if ok {
+		t.Fatal("Expected not to be able to churn pinned contract")
+	}
 }
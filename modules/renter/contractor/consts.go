@@ -95,6 +95,25 @@ var (
 	}).(types.BlockHeight)
 )
 
+// Constants related to the migration policy of the contractor.
+var (
+	// migrationMinHosts is the minimum number of hosts the contractor needs
+	// to have contracts with before hosts are compared to the median price.
+	migrationMinHosts = build.Select(build.Var{
+		Dev:      3,
+		Standard: 5,
+		Testing:  3,
+	}).(int)
+
+	// migrationMinInteractions is the minimum number of recent interactions
+	// with a host before its success rate is considered meaningful.
+	migrationMinInteractions = build.Select(build.Var{
+		Dev:      float64(10),
+		Standard: float64(20),
+		Testing:  float64(5),
+	}).(float64)
+)
+
 // Constants related to the safety values for when the contractor is forming
 // contracts.
 var (
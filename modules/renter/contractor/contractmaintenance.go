@@ -89,6 +89,10 @@ func (c *Contractor) managedCheckForDuplicates() {
 			c.renewedFrom[newContract.ID] = oldContract.ID
 			c.renewedTo[oldContract.ID] = newContract.ID
 			c.oldContracts[oldContract.ID] = oldSC.Metadata()
+			if _, pinned := c.pinnedContracts[oldContract.ID]; pinned {
+				delete(c.pinnedContracts, oldContract.ID)
+				c.pinnedContracts[newContract.ID] = struct{}{}
+			}
 			c.pubKeysToContractID[string(newContract.HostPublicKey.Key)] = newContract.ID
 
 			// Save the contractor and delete the contract.
@@ -211,8 +215,7 @@ func (c *Contractor) managedEstimateRenewFundingRequirements(contract modules.Re
 
 	// Get an estimate for how much money we will be charged before going into
 	// the transaction pool.
-	_, maxTxnFee := c.tpool.FeeEstimation()
-	txnFees := maxTxnFee.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	txnFees := c.staticContractTxnFee(allowance)
 
 	// Add them all up and then return the estimate plus 33% for error margin
 	// and just general volatility of usage pattern.
@@ -229,6 +232,18 @@ func (c *Contractor) managedEstimateRenewFundingRequirements(contract modules.Re
 	return estimatedCost, nil
 }
 
+// staticContractTxnFee returns the anticipated fee of the transaction set that
+// forms or renews a contract. The fee is estimated for the fee target of the
+// allowance if it is set, and is the maximum estimate otherwise.
+func (c *Contractor) staticContractTxnFee(allowance modules.Allowance) types.Currency {
+	if allowance.FeeTarget != 0 {
+		est := c.tpool.FeeEstimationTarget(allowance.FeeTarget, modules.DefaultFeeConfidence)
+		return est.FeePerByte.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	}
+	_, maxFee := c.tpool.FeeEstimation()
+	return maxFee.Mul64(modules.EstimatedFileContractTransactionSetSize)
+}
+
 // callInterruptContractMaintenance will issue an interrupt signal to any
 // running maintenance, stopping that maintenance. If there are multiple threads
 // running maintenance, they will all be stopped.
@@ -347,8 +362,9 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 		return types.ZeroCurrency, modules.RenterContract{}, errors.AddContext(err, "unable to form a contract due to price gouging detection")
 	}
 
-	// get an address to use for negotiation
-	uc, err := c.wallet.NextAddress()
+	// get an address of the allowance's wallet account to use for
+	// negotiation
+	uc, err := c.wallet.NextAccountAddress(allowance.WalletAccount)
 	if err != nil {
 		return types.ZeroCurrency, modules.RenterContract{}, err
 	}
@@ -379,7 +395,7 @@ func (c *Contractor) managedNewContract(host modules.HostDBEntry, contractFundin
 	defer fastrand.Read(params.RenterSeed[:])
 
 	// create transaction builder and trigger contract formation.
-	txnBuilder, err := c.wallet.StartTransaction()
+	txnBuilder, err := c.wallet.StartAccountTransaction(allowance.WalletAccount)
 	if err != nil {
 		return types.ZeroCurrency, modules.RenterContract{}, err
 	}
@@ -443,7 +459,7 @@ func (c *Contractor) managedPrunePubkeyMap() {
 // managedPrunedRedundantAddressRange uses the hostdb to find hosts that
 // violate the rules about address ranges and cancels them.
 func (c *Contractor) managedPrunedRedundantAddressRange() {
-	// Get all contracts which are not canceled.
+	// Get all contracts which are not canceled or pinned.
 	allContracts := c.staticContracts.ViewAll()
 	var contracts []modules.RenterContract
 	for _, contract := range allContracts {
@@ -451,6 +467,10 @@ func (c *Contractor) managedPrunedRedundantAddressRange() {
 			// contract is canceled
 			continue
 		}
+		if c.managedContractPinned(contract.ID) {
+			// the user wants to keep the contract
+			continue
+		}
 		contracts = append(contracts, contract)
 	}
 
@@ -541,6 +561,7 @@ func (c *Contractor) managedRenew(sc *proto.SafeContract, contractFunding types.
 		return modules.RenterContract{}, errors.New("called managedRenew but allowance isn't set")
 	}
 	period := c.allowance.Period
+	account := c.allowance.WalletAccount
 	c.mu.Unlock()
 	if !ok {
 		return modules.RenterContract{}, errors.New("no record of that host")
@@ -563,8 +584,9 @@ func (c *Contractor) managedRenew(sc *proto.SafeContract, contractFunding types.
 		return modules.RenterContract{}, errors.AddContext(err, "unable to renew - price gouging protection enabled")
 	}
 
-	// get an address to use for negotiation
-	uc, err := c.wallet.NextAddress()
+	// get an address of the allowance's wallet account to use for
+	// negotiation
+	uc, err := c.wallet.NextAccountAddress(account)
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
@@ -595,7 +617,7 @@ func (c *Contractor) managedRenew(sc *proto.SafeContract, contractFunding types.
 	defer fastrand.Read(params.RenterSeed[:])
 
 	// execute negotiation protocol
-	txnBuilder, err := c.wallet.StartTransaction()
+	txnBuilder, err := c.wallet.StartAccountTransaction(account)
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
@@ -768,6 +790,11 @@ func (c *Contractor) managedRenewContract(renewInstructions fileContractRenewal,
 	// Link Contracts
 	c.renewedFrom[newContract.ID] = id
 	c.renewedTo[id] = newContract.ID
+	// Carry over the pin.
+	if _, pinned := c.pinnedContracts[id]; pinned {
+		delete(c.pinnedContracts, id)
+		c.pinnedContracts[newContract.ID] = struct{}{}
+	}
 	// Store the contract in the record of historic contracts.
 	c.oldContracts[id] = oldContract.Metadata()
 	// Save the contractor.
@@ -947,6 +974,13 @@ func (c *Contractor) threadedContractMaintenance() {
 			c.log.Debugln("Contract skipped because host is using an outdated version", host.Version)
 		}
 
+		// Skip contracts that were marked for migration. Their data is moved
+		// to other hosts instead.
+		if c.managedContractMigrating(contract.ID) {
+			c.log.Debugln("Contract skipped because it was marked for migration")
+			continue
+		}
+
 		// Skip any contracts which do not exist or are otherwise unworthy for
 		// renewal.
 		utility, ok := c.managedContractUtility(contract.ID)
@@ -1204,8 +1238,7 @@ func (c *Contractor) threadedContractMaintenance() {
 	c.log.Debugln("trying to form contracts with hosts, pulled this many hosts from hostdb:", len(hosts))
 
 	// Calculate the anticipated transaction fee.
-	_, maxFee := c.tpool.FeeEstimation()
-	txnFee := maxFee.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	txnFee := c.staticContractTxnFee(allowance)
 
 	// Form contracts with the hosts one at a time, until we have enough
 	// contracts.
@@ -74,6 +74,16 @@ type Contractor struct {
 	pubKeysToContractID map[string]types.FileContractID
 	renewing            map[types.FileContractID]bool // prevent revising during renewal
 
+	// pinnedContracts are contracts that the user wants to keep using
+	// regardless of the host's score. The pin is moved to the new contract
+	// whenever a pinned contract is renewed.
+	pinnedContracts map[types.FileContractID]struct{}
+
+	// migratingContracts are contracts that were marked for migration by the
+	// migration policy. Their data is moved to other hosts before the
+	// contracts are dropped.
+	migratingContracts map[types.FileContractID]modules.ContractMigration
+
 	// renewedFrom links the new contract's ID to the old contract's ID
 	// renewedTo links the old contract's ID to the new contract's ID
 	// doubleSpentContracts keep track of all contracts that were double spent by
@@ -339,6 +349,9 @@ func contractorBlockingStartup(cs modules.ConsensusSet, w modules.Wallet, tp mod
 		oldContracts:         make(map[types.FileContractID]modules.RenterContract),
 		doubleSpentContracts: make(map[types.FileContractID]types.BlockHeight),
 		recoverableContracts: make(map[types.FileContractID]modules.RecoverableContract),
+		numFailedRenews:      make(map[types.FileContractID]types.BlockHeight),
+		pinnedContracts:      make(map[types.FileContractID]struct{}),
+		migratingContracts:   make(map[types.FileContractID]modules.ContractMigration),
 		pubKeysToContractID:  make(map[string]types.FileContractID),
 		renewing:             make(map[types.FileContractID]bool),
 		renewedFrom:          make(map[types.FileContractID]types.FileContractID),
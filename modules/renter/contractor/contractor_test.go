@@ -202,6 +202,12 @@ func TestIntegrationSetAllowance(t *testing.T) {
 	}
 	a.ExpectedRedundancy = modules.DefaultAllowance.ExpectedRedundancy
 	a.MaxPeriodChurn = modules.DefaultAllowance.MaxPeriodChurn
+	a.WalletAccount = "unknown"
+	err = c.SetAllowance(a)
+	if err == nil {
+		t.Error("expected an error for an unknown wallet account")
+	}
+	a.WalletAccount = ""
 
 	// reasonable values; should succeed
 	a.Funds = types.SiacoinPrecision.Mul64(100)
@@ -1,6 +1,7 @@
 package contractor
 
 import (
+	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
 
@@ -37,6 +38,12 @@ func (c *Contractor) managedContractByPublicKey(pk types.SiaPublicKey) (modules.
 	return c.staticContracts.View(id)
 }
 
+// MerkleRoot returns the Merkle root of the sector at the given index of the
+// contract with the given id.
+func (c *Contractor) MerkleRoot(id types.FileContractID, index int) (crypto.Hash, error) {
+	return c.staticContracts.MerkleRoot(id, index)
+}
+
 // managedContractUtility returns the ContractUtility for a contract with a given id.
 func (c *Contractor) managedContractUtility(id types.FileContractID) (modules.ContractUtility, bool) {
 	rc, exists := c.staticContracts.View(id)
@@ -54,13 +61,16 @@ func (c *Contractor) ContractByPublicKey(pk types.SiaPublicKey) (modules.RenterC
 }
 
 // CancelContract cancels the Contractor's contract by marking it !GoodForRenew
-// and !GoodForUpload
+// and !GoodForUpload. If the contract was pinned, the pin is removed.
 func (c *Contractor) CancelContract(id types.FileContractID) error {
 	if err := c.tg.Add(); err != nil {
 		return err
 	}
 	defer c.tg.Done()
 	defer c.threadedContractMaintenance()
+	c.mu.Lock()
+	delete(c.pinnedContracts, id)
+	c.mu.Unlock()
 	return c.managedCancelContract(id)
 }
 
@@ -44,6 +44,7 @@ type (
 	transactionPool interface {
 		AcceptTransactionSet([]types.Transaction) error
 		FeeEstimation() (min types.Currency, max types.Currency)
+		FeeEstimationTarget(target types.BlockHeight, confidence float64) modules.TargetFeeEstimate
 	}
 
 	hostDB interface {
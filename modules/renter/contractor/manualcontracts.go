@@ -0,0 +1,346 @@
+package contractor
+
+// manualcontracts.go contains the operations that allow the user to manage
+// contracts directly instead of leaving every decision to
+// threadedContractMaintenance. This includes forming a contract with a specific
+// host, renewing or refreshing a specific contract and pinning contracts so
+// that maintenance keeps using them.
+
+import (
+	"reflect"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errAllowanceNotSet is returned when a contract is supposed to be formed
+	// or renewed manually before an allowance was set.
+	errAllowanceNotSet = errors.New("an allowance needs to be set before contracts can be managed")
+
+	// errBadContract is returned when trying to pin a contract that was marked
+	// as bad.
+	errBadContract = errors.New("contract has been marked as bad")
+
+	// errContractNotFound is returned when the specified contract is not one of
+	// the contractor's active contracts.
+	errContractNotFound = errors.New("contract not found")
+
+	// errContractNotGFR is returned when trying to renew or refresh a contract
+	// that is not good for renew.
+	errContractNotGFR = errors.New("contract is not good for renew")
+
+	// errExistingContract is returned when trying to form a contract with a
+	// host that the contractor already has an active contract with.
+	errExistingContract = errors.New("an active contract with that host already exists")
+
+	// errHostFiltered is returned when trying to form a contract with a host
+	// that is filtered by the hostdb.
+	errHostFiltered = errors.New("host is filtered by the hostdb")
+
+	// errHostNotFound is returned when trying to form a contract with a host
+	// that is unknown to the hostdb.
+	errHostNotFound = errors.New("host not found in hostdb")
+
+	// errInsufficientAllowanceFunds is returned when the funding for a manual
+	// operation exceeds the funds remaining in the allowance.
+	errInsufficientAllowanceFunds = errors.New("not enough funds remaining in the allowance")
+
+	// errZeroFunding is returned when trying to form a contract without funds.
+	errZeroFunding = errors.New("contract funding must be greater than zero")
+)
+
+// managedAllowanceFundsRemaining returns the funds of the allowance that have
+// not been allocated to contracts yet in the current period.
+func (c *Contractor) managedAllowanceFundsRemaining() (types.Currency, error) {
+	spending, err := c.PeriodSpending()
+	if err != nil {
+		return types.ZeroCurrency, err
+	}
+	allowance := c.Allowance()
+	// Check for an underflow. This can happen if the user reduced their
+	// allowance at some point to less than what we've already spent.
+	if spending.TotalAllocated.Cmp(allowance.Funds) >= 0 {
+		return types.ZeroCurrency, nil
+	}
+	return allowance.Funds.Sub(spending.TotalAllocated), nil
+}
+
+// managedContractPinned returns whether the contract with the given id has been
+// pinned by the user.
+func (c *Contractor) managedContractPinned(id types.FileContractID) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, pinned := c.pinnedContracts[id]
+	return pinned
+}
+
+// managedManualRenew renews the contract with the given id using the provided
+// funding and end height. A zero funding will be replaced by the provided
+// default. It returns the contract that replaced the old one.
+func (c *Contractor) managedManualRenew(id types.FileContractID, funds, defaultFunds types.Currency, endHeight types.BlockHeight) (modules.RenterContract, error) {
+	if funds.IsZero() {
+		funds = defaultFunds
+	}
+	remaining, err := c.managedAllowanceFundsRemaining()
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+	if funds.Cmp(remaining) > 0 {
+		return modules.RenterContract{}, errInsufficientAllowanceFunds
+	}
+	if unlocked, err := c.wallet.Unlocked(); !unlocked || err != nil {
+		return modules.RenterContract{}, modules.ErrLockedWallet
+	}
+
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	currentPeriod := c.currentPeriod
+	c.mu.RUnlock()
+
+	renewal := fileContractRenewal{
+		id:     id,
+		amount: funds,
+	}
+	_, err = c.managedRenewContract(renewal, currentPeriod, allowance, blockHeight, endHeight)
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+
+	// Look up the contract that replaced the old one.
+	c.mu.RLock()
+	newID, renewed := c.renewedTo[id]
+	c.mu.RUnlock()
+	if !renewed {
+		return modules.RenterContract{}, errors.New("renewed contract could not be found")
+	}
+	newContract, exists := c.staticContracts.View(newID)
+	if !exists {
+		return modules.RenterContract{}, errors.New("renewed contract could not be found")
+	}
+	return newContract, nil
+}
+
+// managedRenewableContract returns the contract with the given id after
+// checking that it can be renewed manually.
+func (c *Contractor) managedRenewableContract(id types.FileContractID) (modules.RenterContract, error) {
+	c.mu.RLock()
+	allowanceSet := !reflect.DeepEqual(c.allowance, modules.Allowance{})
+	c.mu.RUnlock()
+	if !allowanceSet {
+		return modules.RenterContract{}, errAllowanceNotSet
+	}
+	contract, exists := c.staticContracts.View(id)
+	if !exists {
+		return modules.RenterContract{}, errContractNotFound
+	}
+	if !contract.Utility.GoodForRenew {
+		return modules.RenterContract{}, errContractNotGFR
+	}
+	return contract, nil
+}
+
+// FormContract forms a contract with the host with the given public key using
+// the provided funding. The contract is formed regardless of the host's score,
+// but the host still has to be known to the hostdb and must not be filtered.
+func (c *Contractor) FormContract(pk types.SiaPublicKey, funds types.Currency) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+	if funds.IsZero() {
+		return modules.RenterContract{}, errZeroFunding
+	}
+
+	// Make sure maintenance doesn't form contracts at the same time.
+	c.callInterruptContractMaintenance()
+	c.maintenanceLock.Lock()
+	defer c.maintenanceLock.Unlock()
+
+	c.mu.RLock()
+	allowanceSet := !reflect.DeepEqual(c.allowance, modules.Allowance{})
+	endHeight := c.contractEndHeight()
+	c.mu.RUnlock()
+	if !allowanceSet {
+		return modules.RenterContract{}, errAllowanceNotSet
+	}
+
+	// Check the host.
+	host, exists, err := c.hdb.Host(pk)
+	if err != nil {
+		return modules.RenterContract{}, errors.AddContext(err, "error getting host from hostdb")
+	}
+	if !exists {
+		return modules.RenterContract{}, errHostNotFound
+	}
+	if host.Filtered {
+		return modules.RenterContract{}, errHostFiltered
+	}
+
+	// Only one active contract per host is allowed. Prune the pubkey map first
+	// to avoid conflicts with contracts that were archived already.
+	c.managedPrunePubkeyMap()
+	if _, exists := c.managedContractByPublicKey(pk); exists {
+		return modules.RenterContract{}, errExistingContract
+	}
+
+	// Check the funds.
+	remaining, err := c.managedAllowanceFundsRemaining()
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+	if funds.Cmp(remaining) > 0 {
+		return modules.RenterContract{}, errInsufficientAllowanceFunds
+	}
+	if unlocked, err := c.wallet.Unlocked(); !unlocked || err != nil {
+		return modules.RenterContract{}, modules.ErrLockedWallet
+	}
+
+	_, contract, err := c.managedNewContract(host, funds, endHeight)
+	if err != nil {
+		return modules.RenterContract{}, errors.AddContext(err, "unable to form contract")
+	}
+
+	// Add this contract to the contractor and save.
+	err = c.managedAcquireAndUpdateContractUtility(contract.ID, modules.ContractUtility{
+		GoodForUpload: true,
+		GoodForRenew:  true,
+	})
+	if err != nil {
+		return modules.RenterContract{}, errors.AddContext(err, "failed to update the contract utility")
+	}
+	c.mu.Lock()
+	err = c.save()
+	c.mu.Unlock()
+	if err != nil {
+		c.log.Println("Unable to save the contractor:", err)
+	}
+	contract, _ = c.staticContracts.View(contract.ID)
+	return contract, nil
+}
+
+// PinContract pins the contract with the given id. Contract maintenance will
+// keep a pinned contract GoodForUpload and GoodForRenew regardless of the
+// host's score, and the churn limiter will never churn it, as long as the
+// contract passes the critical utility checks. Pinning a contract cancels its
+// migration. The utility of a locked contract isn't changed. Pins are carried
+// over to the contract that replaces a pinned contract on renewal.
+func (c *Contractor) PinContract(id types.FileContractID) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+
+	sc, exists := c.staticContracts.Acquire(id)
+	if !exists {
+		return errContractNotFound
+	}
+	defer c.staticContracts.Return(sc)
+	u := sc.Utility()
+	if u.BadContract {
+		return errBadContract
+	}
+
+	c.mu.Lock()
+	c.pinnedContracts[id] = struct{}{}
+	delete(c.migratingContracts, id)
+	err := c.save()
+	c.mu.Unlock()
+	if err != nil {
+		return errors.AddContext(err, "unable to save the contractor")
+	}
+
+	if u.Locked {
+		return nil
+	}
+	u.GoodForUpload = true
+	u.GoodForRenew = true
+	return errors.AddContext(c.callUpdateUtility(sc, u, false), "unable to update contract utility")
+}
+
+// PinnedContract returns whether the contract with the given id is pinned.
+func (c *Contractor) PinnedContract(id types.FileContractID) bool {
+	return c.managedContractPinned(id)
+}
+
+// RefreshContract renews the contract with the given id without changing its
+// end height, adding funds to a contract that is running out of money. If
+// funds is zero, the contract is refreshed with twice its previous total cost,
+// just like contract maintenance would.
+func (c *Contractor) RefreshContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+
+	// Make sure maintenance doesn't renew contracts at the same time.
+	c.callInterruptContractMaintenance()
+	c.maintenanceLock.Lock()
+	defer c.maintenanceLock.Unlock()
+
+	contract, err := c.managedRenewableContract(id)
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+	return c.managedManualRenew(id, funds, contract.TotalCost.Mul64(2), contract.EndHeight)
+}
+
+// RenewContract renews the contract with the given id before it enters the
+// renew window. The new contract ends at the end of the current period, or at
+// the end of the next period if the contract already ends with the current
+// one. If funds is zero, the funding is estimated the same way contract
+// maintenance estimates it.
+func (c *Contractor) RenewContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error) {
+	if err := c.tg.Add(); err != nil {
+		return modules.RenterContract{}, err
+	}
+	defer c.tg.Done()
+
+	// Make sure maintenance doesn't renew contracts at the same time.
+	c.callInterruptContractMaintenance()
+	c.maintenanceLock.Lock()
+	defer c.maintenanceLock.Unlock()
+
+	contract, err := c.managedRenewableContract(id)
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	endHeight := c.contractEndHeight()
+	c.mu.RUnlock()
+	if contract.EndHeight >= endHeight {
+		endHeight += allowance.Period
+	}
+
+	var estimate types.Currency
+	if funds.IsZero() {
+		estimate, err = c.managedEstimateRenewFundingRequirements(contract, blockHeight, allowance)
+		if err != nil {
+			return modules.RenterContract{}, errors.AddContext(err, "unable to estimate renew funding")
+		}
+	}
+	return c.managedManualRenew(id, funds, estimate, endHeight)
+}
+
+// UnpinContract removes the pin from the contract with the given id. The
+// contract's utility will be evaluated by the next contract maintenance like
+// any other contract.
+func (c *Contractor) UnpinContract(id types.FileContractID) error {
+	if err := c.tg.Add(); err != nil {
+		return err
+	}
+	defer c.tg.Done()
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, pinned := c.pinnedContracts[id]; !pinned {
+		return errors.New("contract is not pinned")
+	}
+	delete(c.pinnedContracts, id)
+	return c.save()
+}
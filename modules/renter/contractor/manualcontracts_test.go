@@ -0,0 +1,152 @@
+package contractor
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestManualContracts tests forming, pinning, refreshing and unpinning
+// contracts manually.
+func TestManualContracts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// Forming a contract without an allowance should fail.
+	funds := types.SiacoinPrecision.Mul64(50)
+	if _, err := c.FormContract(h.PublicKey(), funds); err != errAllowanceNotSet {
+		t.Fatal("expected errAllowanceNotSet, got", err)
+	}
+
+	// set an allowance but don't use SetAllowance to avoid automatic contract
+	// formation.
+	c.mu.Lock()
+	c.allowance = modules.DefaultAllowance
+	c.mu.Unlock()
+
+	// Form a contract with the host.
+	contract, err := c.FormContract(h.PublicKey(), funds)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !contract.Utility.GoodForUpload || !contract.Utility.GoodForRenew {
+		t.Fatal("new contract should be GFU and GFR", contract.Utility)
+	}
+	// A second contract with the same host is not allowed.
+	if _, err := c.FormContract(h.PublicKey(), funds); err != errExistingContract {
+		t.Fatal("expected errExistingContract, got", err)
+	}
+
+	// Pin the contract.
+	if err := c.PinContract(contract.ID); err != nil {
+		t.Fatal(err)
+	}
+	if !c.PinnedContract(contract.ID) {
+		t.Fatal("contract should be pinned")
+	}
+
+	// Refresh the contract. The pin should be moved to the new contract.
+	newContract, err := c.RefreshContract(contract.ID, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newContract.ID == contract.ID {
+		t.Fatal("refresh should create a new contract")
+	}
+	if newContract.EndHeight != contract.EndHeight {
+		t.Fatal("refresh should not change the end height", newContract.EndHeight, contract.EndHeight)
+	}
+	if c.PinnedContract(contract.ID) || !c.PinnedContract(newContract.ID) {
+		t.Fatal("pin wasn't carried over to the refreshed contract")
+	}
+
+	// The pin should survive a restart.
+	c.mu.Lock()
+	data := c.persistData()
+	c.mu.Unlock()
+	if len(data.PinnedContracts) != 1 || data.PinnedContracts[0] != newContract.ID {
+		t.Fatal("pinned contracts not persisted", data.PinnedContracts)
+	}
+
+	// Unpin the contract.
+	if err := c.UnpinContract(newContract.ID); err != nil {
+		t.Fatal(err)
+	}
+	if c.PinnedContract(newContract.ID) {
+		t.Fatal("contract should not be pinned")
+	}
+	if err := c.UnpinContract(newContract.ID); err == nil {
+		t.Fatal("unpinning a contract twice should fail")
+	}
+}
+
+// TestPinnedContractUtility tests that pinning a contract doesn't unlock its
+// utility and that pinned contracts still have to pass the critical utility
+// checks.
+func TestPinnedContractUtility(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// set an allowance but don't use SetAllowance to avoid automatic contract
+	// formation.
+	c.mu.Lock()
+	c.allowance = modules.DefaultAllowance
+	c.mu.Unlock()
+
+	contract, err := c.FormContract(h.PublicKey(), types.SiacoinPrecision.Mul64(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Lock the utility of the contract like a canceled allowance would.
+	locked := modules.ContractUtility{Locked: true}
+	if err := c.managedAcquireAndUpdateContractUtility(contract.ID, locked); err != nil {
+		t.Fatal(err)
+	}
+	if err := c.PinContract(contract.ID); err != nil {
+		t.Fatal(err)
+	}
+	u, ok := c.ContractUtility(contract.HostPublicKey)
+	if !ok {
+		t.Fatal("contract not found")
+	}
+	if !u.Locked || u.GoodForUpload || u.GoodForRenew {
+		t.Fatal("pinning shouldn't change the utility of a locked contract", u)
+	}
+
+	// Unlock the contract and move the block height past its end height. The
+	// pinned contract should fail the critical checks and not be GFU.
+	if err := c.managedAcquireAndUpdateContractUtility(contract.ID, modules.ContractUtility{}); err != nil {
+		t.Fatal(err)
+	}
+	c.mu.Lock()
+	c.blockHeight = contract.EndHeight
+	c.mu.Unlock()
+	if err := c.managedMarkContractsUtility(); err != nil {
+		t.Fatal(err)
+	}
+	u, ok = c.ContractUtility(contract.HostPublicKey)
+	if !ok {
+		t.Fatal("contract not found")
+	}
+	if u.GoodForUpload {
+		t.Fatal("pinned contract past its renew window shouldn't be GFU", u)
+	}
+}
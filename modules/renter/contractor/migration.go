@@ -0,0 +1,163 @@
+package contractor
+
+// migration.go contains the migration policy of the contractor. Contracts with
+// hosts that raise their prices far above the prices of the other hosts or that
+// start failing interactions are marked for migration. Marked contracts are no
+// longer used for uploads. Once the churnLimiter has enough budget, or at the
+// latest when the contract is up for renewal, the migration becomes active and
+// the contract is marked !GoodForRenew. This causes the repair loop to move the
+// contract's data to other hosts before the contract is dropped, while the
+// churnLimiter bounds the rate at which data is migrated.
+
+import (
+	"fmt"
+	"sort"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// sectorPrice returns the price of storing a sector on the host for a period
+// and uploading and downloading it once.
+func sectorPrice(host modules.HostDBEntry, period types.BlockHeight) types.Currency {
+	blockBytes := types.NewCurrency64(modules.SectorSize * uint64(period))
+	storagePrice := host.StoragePrice.Mul(blockBytes)
+	bandwidthPrice := host.UploadBandwidthPrice.Add(host.DownloadBandwidthPrice).Mul64(modules.SectorSize)
+	return storagePrice.Add(bandwidthPrice)
+}
+
+// migrationReason returns why the data stored on the host should be migrated
+// to other hosts according to the allowance's migration policy. An empty
+// string is returned if the data can stay on the host. A zero medianPrice
+// disables the price check.
+func migrationReason(allowance modules.Allowance, host modules.HostDBEntry, medianPrice types.Currency) string {
+	if allowance.MigrationPriceMultiple > 0 && !medianPrice.IsZero() {
+		maxPrice := medianPrice.MulFloat(allowance.MigrationPriceMultiple)
+		if sectorPrice(host, allowance.Period).Cmp(maxPrice) > 0 {
+			return fmt.Sprintf("host is more than %v times as expensive as the median host", allowance.MigrationPriceMultiple)
+		}
+	}
+	if allowance.MigrationMinSuccessRate > 0 {
+		total := host.RecentSuccessfulInteractions + host.RecentFailedInteractions
+		if total >= migrationMinInteractions {
+			successRate := host.RecentSuccessfulInteractions / total
+			if successRate < allowance.MigrationMinSuccessRate {
+				return fmt.Sprintf("recent success rate of host dropped to %.2f", successRate)
+			}
+		}
+	}
+	return ""
+}
+
+// managedMedianSectorPrice returns the median sectorPrice of the hosts that the
+// contractor has contracts with. If there are fewer than migrationMinHosts
+// hosts, zero is returned.
+func (c *Contractor) managedMedianSectorPrice() types.Currency {
+	period := c.Allowance().Period
+	var prices []types.Currency
+	for _, contract := range c.staticContracts.ViewAll() {
+		host, exists, err := c.hdb.Host(contract.HostPublicKey)
+		if err != nil || !exists {
+			continue
+		}
+		prices = append(prices, sectorPrice(host, period))
+	}
+	if len(prices) < migrationMinHosts {
+		return types.ZeroCurrency
+	}
+	sort.Slice(prices, func(i, j int) bool {
+		return prices[i].Cmp(prices[j]) < 0
+	})
+	return prices[len(prices)/2]
+}
+
+// managedMigrationCheck applies the migration policy to the contract. It
+// returns whether the contract is marked for migration and whether the
+// migration is active.
+func (c *Contractor) managedMigrationCheck(contract modules.RenterContract, host modules.HostDBEntry, medianPrice types.Currency) (marked bool, active bool, err error) {
+	c.mu.RLock()
+	allowance := c.allowance
+	blockHeight := c.blockHeight
+	migration, exists := c.migratingContracts[contract.ID]
+	c.mu.RUnlock()
+
+	// Active migrations can't be cancelled anymore since the contract won't be
+	// renewed.
+	if exists && migration.Active {
+		return true, true, nil
+	}
+
+	reason := migrationReason(allowance, host, medianPrice)
+	switch {
+	case !exists && reason == "":
+		return false, false, nil
+	case !exists:
+		c.log.Printf("Marking contract %v for migration: %v", contract.ID, reason)
+		migration = modules.ContractMigration{
+			Reason:       reason,
+			MarkedHeight: blockHeight,
+		}
+	case reason == "":
+		// The host is fine again before the migration started.
+		c.log.Println("Cancelling migration of contract", contract.ID)
+		c.mu.Lock()
+		delete(c.migratingContracts, contract.ID)
+		err = c.save()
+		c.mu.Unlock()
+		return false, false, errors.AddContext(err, "unable to save the contractor")
+	}
+
+	// Start the migration once the churnLimiter allows for the contract to be
+	// churned. Contracts that are up for renewal are migrated right away
+	// since they won't be renewed anymore. The churn budget is consumed right
+	// away to hold back the migrations of the remaining contracts until the
+	// budget recovers.
+	upForRenewal := blockHeight+allowance.RenewWindow >= contract.EndHeight
+	if upForRenewal || c.staticChurnLimiter.managedCanChurnContract(contract) {
+		c.log.Println("Starting migration of contract", contract.ID)
+		c.staticChurnLimiter.callNotifyChurnedContract(contract)
+		migration.Active = true
+		migration.StartHeight = blockHeight
+	} else if exists {
+		return true, false, nil
+	}
+
+	c.mu.Lock()
+	c.migratingContracts[contract.ID] = migration
+	err = c.save()
+	c.mu.Unlock()
+	return true, migration.Active, errors.AddContext(err, "unable to save the contractor")
+}
+
+// managedAcquireAndUpdateMigratingContractUtility updates the utility of a
+// contract with an active migration. Unlike
+// managedAcquireAndUpdateContractUtility it doesn't notify the churnLimiter
+// since managedMigrationCheck already did so when the migration started.
+func (c *Contractor) managedAcquireAndUpdateMigratingContractUtility(id types.FileContractID, utility modules.ContractUtility) error {
+	safeContract, ok := c.staticContracts.Acquire(id)
+	if !ok {
+		return errors.New("failed to acquire contract for update")
+	}
+	defer c.staticContracts.Return(safeContract)
+	return safeContract.UpdateUtility(utility)
+}
+
+// managedContractMigrating returns whether the contract with the given id was
+// marked for migration.
+func (c *Contractor) managedContractMigrating(id types.FileContractID) bool {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	_, migrating := c.migratingContracts[id]
+	return migrating
+}
+
+// MigratingContract returns the migration of the contract with the given id if
+// the contract was marked for migration.
+func (c *Contractor) MigratingContract(id types.FileContractID) (modules.ContractMigration, bool) {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	migration, exists := c.migratingContracts[id]
+	return migration, exists
+}
@@ -0,0 +1,200 @@
+package contractor
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestMigrationReason is a unit test for migrationReason.
+func TestMigrationReason(t *testing.T) {
+	allowance := modules.DefaultAllowance
+	allowance.MigrationPriceMultiple = 2
+	allowance.MigrationMinSuccessRate = 0.9
+
+	var median modules.HostDBEntry
+	median.StoragePrice = types.SiacoinPrecision
+	median.UploadBandwidthPrice = types.SiacoinPrecision
+	median.DownloadBandwidthPrice = types.SiacoinPrecision
+	medianPrice := sectorPrice(median, allowance.Period)
+
+	cheap := median
+	expensive := median
+	expensive.StoragePrice = median.StoragePrice.Mul64(3)
+	failing := median
+	failing.RecentSuccessfulInteractions = migrationMinInteractions
+	failing.RecentFailedInteractions = migrationMinInteractions
+	unknown := median
+	unknown.RecentFailedInteractions = migrationMinInteractions - 1
+
+	tests := []struct {
+		name        string
+		allowance   modules.Allowance
+		host        modules.HostDBEntry
+		medianPrice types.Currency
+		migrate     bool
+	}{
+		{"median host", allowance, cheap, medianPrice, false},
+		{"expensive host", allowance, expensive, medianPrice, true},
+		{"expensive host without median", allowance, expensive, types.ZeroCurrency, false},
+		{"expensive host with check disabled", modules.DefaultAllowance, expensive, medianPrice, false},
+		{"failing host", allowance, failing, medianPrice, true},
+		{"failing host with check disabled", modules.DefaultAllowance, failing, medianPrice, false},
+		{"too few interactions", allowance, unknown, medianPrice, false},
+	}
+	for _, test := range tests {
+		reason := migrationReason(test.allowance, test.host, test.medianPrice)
+		if migrate := reason != ""; migrate != test.migrate {
+			t.Errorf("%v: expected migrate to be %v but reason was %q", test.name, test.migrate, reason)
+		}
+	}
+}
+
+// TestMigrationCheck tests that contracts of failing hosts are marked for
+// migration, are no longer renewed and that pinning a contract cancels its
+// migration.
+func TestMigrationCheck(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// set an allowance but don't use SetAllowance to avoid automatic contract
+	// formation.
+	c.mu.Lock()
+	c.allowance = modules.DefaultAllowance
+	c.allowance.MigrationMinSuccessRate = 0.9
+	c.mu.Unlock()
+	contract, err := c.FormContract(h.PublicKey(), types.SiacoinPrecision.Mul64(50))
+	if err != nil {
+		t.Fatal(err)
+	}
+	host, _, err := c.hdb.Host(contract.HostPublicKey)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A host without failed interactions shouldn't be migrated.
+	marked, active, err := c.managedMigrationCheck(contract, host, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if marked || active {
+		t.Fatal("contract shouldn't be marked for migration")
+	}
+
+	// A failing host should be migrated. The empty contract fits into the
+	// churn budget so the migration should start right away.
+	host.RecentFailedInteractions = migrationMinInteractions
+	marked, active, err = c.managedMigrationCheck(contract, host, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !marked || !active {
+		t.Fatal("contract should be migrated", marked, active)
+	}
+	migration, exists := c.MigratingContract(contract.ID)
+	if !exists || !migration.Active || migration.Reason == "" {
+		t.Fatal("migration wasn't recorded", migration, exists)
+	}
+	c.mu.Lock()
+	data := c.persistData()
+	c.mu.Unlock()
+	if _, persisted := data.MigratingContracts[contract.ID.String()]; !persisted {
+		t.Fatal("migration wasn't persisted")
+	}
+
+	// Pinning the contract should cancel the migration.
+	if err := c.PinContract(contract.ID); err != nil {
+		t.Fatal(err)
+	}
+	if c.managedContractMigrating(contract.ID) {
+		t.Fatal("pinning the contract should cancel the migration")
+	}
+}
+
+// TestMigrationCheckChurnBudget tests that starting a migration consumes the
+// churn budget and that the migrations of other contracts are held back until
+// the budget recovers.
+func TestMigrationCheckChurnBudget(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	h, c, _, err := newTestingTrio(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer h.Close()
+	defer c.Close()
+
+	// set an allowance but don't use SetAllowance to avoid automatic contract
+	// formation.
+	c.mu.Lock()
+	c.allowance = modules.DefaultAllowance
+	c.allowance.MigrationMinSuccessRate = 0.9
+	c.allowance.MaxPeriodChurn = 2 * modules.SectorSize
+	endHeight := c.blockHeight + c.allowance.Period
+	c.mu.Unlock()
+	cl := c.staticChurnLimiter
+	cl.mu.Lock()
+	cl.remainingChurnBudget = int(modules.SectorSize)
+	cl.aggregateCurrentPeriodChurn = 0
+	cl.mu.Unlock()
+
+	// Create two contracts that aren't up for renewal and that each use up the
+	// whole churn budget.
+	first := contractWithSize(modules.SectorSize)
+	first.ID = types.FileContractID{1}
+	first.EndHeight = endHeight
+	second := contractWithSize(modules.SectorSize)
+	second.ID = types.FileContractID{2}
+	second.EndHeight = endHeight
+
+	var host modules.HostDBEntry
+	host.RecentFailedInteractions = migrationMinInteractions
+
+	// The migration of the first contract should start and consume the
+	// budget.
+	marked, active, err := c.managedMigrationCheck(first, host, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !marked || !active {
+		t.Fatal("migration of the first contract should start", marked, active)
+	}
+	if budget, _ := cl.managedChurnBudget(); budget != 0 {
+		t.Fatal("migration didn't consume the churn budget", budget)
+	}
+
+	// The second contract should be marked but held back.
+	marked, active, err = c.managedMigrationCheck(second, host, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !marked || active {
+		t.Fatal("migration of the second contract should be held back", marked, active)
+	}
+	if migration, exists := c.MigratingContract(second.ID); !exists || migration.Active {
+		t.Fatal("second contract should be marked but not active", migration, exists)
+	}
+
+	// Once the budget recovers the second migration should start.
+	cl.mu.Lock()
+	cl.remainingChurnBudget = int(modules.SectorSize)
+	cl.mu.Unlock()
+	marked, active, err = c.managedMigrationCheck(second, host, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !marked || !active {
+		t.Fatal("migration of the second contract should start", marked, active)
+	}
+}
@@ -26,17 +26,19 @@ var (
 
 // contractorPersist defines what Contractor data persists across sessions.
 type contractorPersist struct {
-	Allowance            modules.Allowance               `json:"allowance"`
-	BlockHeight          types.BlockHeight               `json:"blockheight"`
-	CurrentPeriod        types.BlockHeight               `json:"currentperiod"`
-	LastChange           modules.ConsensusChangeID       `json:"lastchange"`
-	RecentRecoveryChange modules.ConsensusChangeID       `json:"recentrecoverychange"`
-	OldContracts         []modules.RenterContract        `json:"oldcontracts"`
-	DoubleSpentContracts map[string]types.BlockHeight    `json:"doublespentcontracts"`
-	RecoverableContracts []modules.RecoverableContract   `json:"recoverablecontracts"`
-	RenewedFrom          map[string]types.FileContractID `json:"renewedfrom"`
-	RenewedTo            map[string]types.FileContractID `json:"renewedto"`
-	Synced               bool                            `json:"synced"`
+	Allowance            modules.Allowance                    `json:"allowance"`
+	BlockHeight          types.BlockHeight                    `json:"blockheight"`
+	CurrentPeriod        types.BlockHeight                    `json:"currentperiod"`
+	LastChange           modules.ConsensusChangeID            `json:"lastchange"`
+	RecentRecoveryChange modules.ConsensusChangeID            `json:"recentrecoverychange"`
+	OldContracts         []modules.RenterContract             `json:"oldcontracts"`
+	PinnedContracts      []types.FileContractID               `json:"pinnedcontracts"`
+	MigratingContracts   map[string]modules.ContractMigration `json:"migratingcontracts"`
+	DoubleSpentContracts map[string]types.BlockHeight         `json:"doublespentcontracts"`
+	RecoverableContracts []modules.RecoverableContract        `json:"recoverablecontracts"`
+	RenewedFrom          map[string]types.FileContractID      `json:"renewedfrom"`
+	RenewedTo            map[string]types.FileContractID      `json:"renewedto"`
+	Synced               bool                                 `json:"synced"`
 
 	// Subsystem persistence:
 	ChurnLimiter churnLimiterPersist `json:"churnlimiter"`
@@ -60,6 +62,7 @@ func (c *Contractor) persistData() contractorPersist {
 		RenewedFrom:          make(map[string]types.FileContractID),
 		RenewedTo:            make(map[string]types.FileContractID),
 		DoubleSpentContracts: make(map[string]types.BlockHeight),
+		MigratingContracts:   make(map[string]modules.ContractMigration),
 		Synced:               synced,
 	}
 	for k, v := range c.renewedFrom {
@@ -77,6 +80,12 @@ func (c *Contractor) persistData() contractorPersist {
 	for _, contract := range c.recoverableContracts {
 		data.RecoverableContracts = append(data.RecoverableContracts, contract)
 	}
+	for fcID := range c.pinnedContracts {
+		data.PinnedContracts = append(data.PinnedContracts, fcID)
+	}
+	for fcID, migration := range c.migratingContracts {
+		data.MigratingContracts[fcID.String()] = migration
+	}
 	data.ChurnLimiter = c.staticChurnLimiter.callPersistData()
 	data.WatchdogData = c.staticWatchdog.callPersistData()
 	return data
@@ -146,6 +155,15 @@ func (c *Contractor) load() error {
 	for _, contract := range data.RecoverableContracts {
 		c.recoverableContracts[contract.ID] = contract
 	}
+	for _, fcID := range data.PinnedContracts {
+		c.pinnedContracts[fcID] = struct{}{}
+	}
+	for fcIDString, migration := range data.MigratingContracts {
+		if err := fcid.LoadString(fcIDString); err != nil {
+			return err
+		}
+		c.migratingContracts[fcid] = migration
+	}
 
 	c.staticChurnLimiter = newChurnLimiterFromPersist(c, data.ChurnLimiter)
 
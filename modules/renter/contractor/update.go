@@ -53,6 +53,8 @@ func (c *Contractor) managedArchiveContracts() {
 			id := contract.ID
 			c.mu.Lock()
 			c.oldContracts[id] = contract
+			delete(c.pinnedContracts, id)
+			delete(c.migratingContracts, id)
 			c.mu.Unlock()
 			expired = append(expired, id)
 			c.log.Println("INFO: archived expired contract", id)
@@ -95,10 +95,14 @@ type HostDB struct {
 
 	// filteredTree is a hosttree that only contains the hosts that align with
 	// the filterMode. The filteredHosts are the hosts that are submitted with
-	// the filterMode to determine which host should be in the filteredTree
+	// the filterMode to determine which host should be in the filteredTree.
+	// The filterRules contain the remaining rules submitted with the
+	// filterMode, and the hostFilter evaluates all of them.
 	filteredTree  *hosttree.HostTree
 	filteredHosts map[string]types.SiaPublicKey
+	filterRules   modules.HostDBFilterRules
 	filterMode    modules.FilterMode
+	hostFilter    *hosttree.HostFilter
 
 	blockHeight types.BlockHeight
 	lastChange  modules.ConsensusChangeID
@@ -107,12 +111,30 @@ type HostDB struct {
 // Enforce that HostDB satisfies the modules.HostDB interface.
 var _ modules.HostDB = (*HostDB)(nil)
 
+// listed returns whether the host is listed by the filter rules of the
+// current filter mode. Listed hosts are marked as filtered in the hostTree.
+func (hdb *HostDB) listed(host modules.HostDBEntry) bool {
+	if hdb.filterMode != modules.HostDBActivateBlacklist && hdb.filterMode != modules.HostDBActiveWhitelist {
+		return false
+	}
+	return hdb.hostFilter.Listed(host)
+}
+
+// filtered returns whether the host is filtered from the filteredTree
+// according to the current filter mode and rules.
+func (hdb *HostDB) filtered(host modules.HostDBEntry) bool {
+	if hdb.filterMode != modules.HostDBActivateBlacklist && hdb.filterMode != modules.HostDBActiveWhitelist {
+		return false
+	}
+	isWhitelist := hdb.filterMode == modules.HostDBActiveWhitelist
+	return isWhitelist != hdb.hostFilter.Listed(host)
+}
+
 // insert inserts the HostDBEntry into both hosttrees
 func (hdb *HostDB) insert(host modules.HostDBEntry) error {
+	host.Filtered = hdb.listed(host)
 	err := hdb.hostTree.Insert(host)
-	_, ok := hdb.filteredHosts[host.PublicKey.String()]
-	isWhitelist := hdb.filterMode == modules.HostDBActiveWhitelist
-	if isWhitelist == ok {
+	if !hdb.filtered(host) {
 		errF := hdb.filteredTree.Insert(host)
 		if errF != nil && errF != hosttree.ErrHostExists {
 			err = errors.Compose(err, errF)
@@ -121,13 +143,25 @@ func (hdb *HostDB) insert(host modules.HostDBEntry) error {
 	return err
 }
 
-// modify modifies the HostDBEntry in both hosttrees
+// modify modifies the HostDBEntry in both hosttrees. Since the rules of the
+// filter might match the modified entry differently, e.g. because the host's
+// IPNets changed, the host is added to or removed from the filteredTree if
+// necessary.
 func (hdb *HostDB) modify(host modules.HostDBEntry) error {
+	host.Filtered = hdb.listed(host)
 	err := hdb.hostTree.Modify(host)
-	_, ok := hdb.filteredHosts[host.PublicKey.String()]
-	isWhitelist := hdb.filterMode == modules.HostDBActiveWhitelist
-	if isWhitelist == ok {
+	if hdb.filteredTree == hdb.hostTree {
+		return err
+	}
+	filtered := hdb.filtered(host)
+	_, inFilteredTree := hdb.filteredTree.Select(host.PublicKey)
+	switch {
+	case !filtered && inFilteredTree:
 		err = errors.Compose(err, hdb.filteredTree.Modify(host))
+	case !filtered:
+		err = errors.Compose(err, hdb.filteredTree.Insert(host))
+	case inFilteredTree:
+		err = errors.Compose(err, hdb.filteredTree.Remove(host.PublicKey))
 	}
 	return err
 }
@@ -135,16 +169,12 @@ func (hdb *HostDB) modify(host modules.HostDBEntry) error {
 // remove removes the HostDBEntry from both hosttrees
 func (hdb *HostDB) remove(pk types.SiaPublicKey) error {
 	err := hdb.hostTree.Remove(pk)
-	_, ok := hdb.filteredHosts[pk.String()]
-	isWhitelist := hdb.filterMode == modules.HostDBActiveWhitelist
-	if isWhitelist == ok {
-		errF := hdb.filteredTree.Remove(pk)
-		if err == nil && errF == hosttree.ErrNoSuchHost {
-			return nil
-		}
-		err = errors.Compose(err, errF)
+	errF := hdb.filteredTree.Remove(pk)
+	if errF == hosttree.ErrNoSuchHost {
+		// The host is either filtered or the trees are the same.
+		return err
 	}
-	return err
+	return errors.Compose(err, errF)
 }
 
 // managedSetWeightFunction is a helper function that sets the weightFunc field
@@ -456,12 +486,12 @@ func (hdb *HostDB) CheckForIPViolations(hosts []types.SiaPublicKey) ([]types.Sia
 	filter := hosttree.NewFilter(hdb.deps.Resolver())
 	for _, entry := range entries {
 		// Check if the host violates the rules.
-		if filter.Filtered(entry.NetAddress) {
+		if filter.Filtered(entry.Addresses()...) {
 			badHosts = append(badHosts, entry.PublicKey)
 			continue
 		}
 		// If it didn't then we add it to the filter.
-		filter.Add(entry.NetAddress)
+		filter.Add(entry.Addresses()...)
 	}
 	return badHosts, nil
 }
@@ -481,17 +511,12 @@ func (hdb *HostDB) Host(spk types.SiaPublicKey) (modules.HostDBEntry, bool, erro
 	}
 	defer hdb.tg.Done()
 
-	hdb.mu.Lock()
-	whitelist := hdb.filterMode == modules.HostDBActiveWhitelist
-	filteredHosts := hdb.filteredHosts
-	hdb.mu.Unlock()
 	host, exists := hdb.hostTree.Select(spk)
 	if !exists {
 		return host, exists, errHostNotFoundInTree
 	}
-	_, ok := filteredHosts[spk.String()]
-	host.Filtered = whitelist != ok
 	hdb.mu.RLock()
+	host.Filtered = hdb.filtered(host)
 	updateHostHistoricInteractions(&host, hdb.blockHeight)
 	hdb.mu.RUnlock()
 	return host, exists, nil
@@ -513,8 +538,33 @@ func (hdb *HostDB) Filter() (modules.FilterMode, map[string]types.SiaPublicKey,
 	return hdb.filterMode, filteredHosts, nil
 }
 
+// FilterRules returns the hostdb's filterMode and filter rules.
+func (hdb *HostDB) FilterRules() (modules.FilterMode, modules.HostDBFilterRules, error) {
+	if err := hdb.tg.Add(); err != nil {
+		return modules.HostDBFilterError, modules.HostDBFilterRules{}, errors.AddContext(err, "error adding hostdb threadgroup:")
+	}
+	defer hdb.tg.Done()
+
+	hdb.mu.RLock()
+	defer hdb.mu.RUnlock()
+	rules := hdb.filterRules
+	rules.Hosts = nil
+	for _, pk := range hdb.filteredHosts {
+		rules.Hosts = append(rules.Hosts, pk)
+	}
+	sort.Slice(rules.Hosts, func(i, j int) bool {
+		return rules.Hosts[i].String() < rules.Hosts[j].String()
+	})
+	return hdb.filterMode, rules, nil
+}
+
 // SetFilterMode sets the hostdb filter mode
 func (hdb *HostDB) SetFilterMode(fm modules.FilterMode, hosts []types.SiaPublicKey) error {
+	return hdb.SetFilterRules(fm, modules.HostDBFilterRules{Hosts: hosts})
+}
+
+// SetFilterRules sets the hostdb filter mode using the provided rules.
+func (hdb *HostDB) SetFilterRules(fm modules.FilterMode, rules modules.HostDBFilterRules) error {
 	if err := hdb.tg.Add(); err != nil {
 		return errors.AddContext(err, "error adding hostdb threadgroup:")
 	}
@@ -529,8 +579,11 @@ func (hdb *HostDB) SetFilterMode(fm modules.FilterMode, hosts []types.SiaPublicK
 	// Check if disabling
 	if fm == modules.HostDBDisableFilter {
 		// Reset filtered field for hosts
-		for _, pk := range hdb.filteredHosts {
-			err := hdb.hostTree.SetFiltered(pk, false)
+		for _, host := range hdb.hostTree.All() {
+			if !host.Filtered {
+				continue
+			}
+			err := hdb.hostTree.SetFiltered(host.PublicKey, false)
 			if err != nil {
 				hdb.log.Println("Unable to mark entry as not filtered:", err)
 			}
@@ -538,40 +591,47 @@ func (hdb *HostDB) SetFilterMode(fm modules.FilterMode, hosts []types.SiaPublicK
 		// Reset filtered fields
 		hdb.filteredTree = hdb.hostTree
 		hdb.filteredHosts = make(map[string]types.SiaPublicKey)
+		hdb.filterRules = modules.HostDBFilterRules{}
+		hdb.hostFilter = nil
 		hdb.filterMode = fm
-		return nil
+		return hdb.saveSync()
 	}
 
 	// Check for no hosts submitted with whitelist enabled
 	isWhitelist := fm == modules.HostDBActiveWhitelist
-	if len(hosts) == 0 && isWhitelist {
+	if rules.Empty() && isWhitelist {
 		return errors.New("cannot enable whitelist without hosts")
 	}
-
-	// Create filtered HostTree
-	hdb.filteredTree = hosttree.New(hdb.weightFunc, modules.ProdDependencies.Resolver())
+	hostFilter, err := hosttree.NewHostFilter(rules)
+	if err != nil {
+		return errors.AddContext(err, "invalid filter rules")
+	}
 
 	// Create filteredHosts map
 	filteredHosts := make(map[string]types.SiaPublicKey)
-	for _, h := range hosts {
-		// Add host to filtered host map
-		if _, ok := filteredHosts[h.String()]; ok {
-			continue
-		}
+	for _, h := range rules.Hosts {
 		filteredHosts[h.String()] = h
-
-		// Update host in unfiltered hosttree
-		err := hdb.hostTree.SetFiltered(h, true)
-		if err != nil {
-			hdb.log.Println("Unable to mark entry as filtered:", err)
-		}
 	}
+	rules.Hosts = nil
+	hdb.filteredHosts = filteredHosts
+	hdb.filterRules = rules
+	hdb.hostFilter = hostFilter
+	hdb.filterMode = fm
+
+	// Create filtered HostTree
+	hdb.filteredTree = hosttree.New(hdb.weightFunc, modules.ProdDependencies.Resolver())
 	var allErrs error
 	allHosts := hdb.hostTree.All()
 	for _, host := range allHosts {
+		// Update host in unfiltered hosttree
+		if listed := hdb.listed(host); listed != host.Filtered {
+			err := hdb.hostTree.SetFiltered(host.PublicKey, listed)
+			if err != nil {
+				hdb.log.Println("Unable to update filtered field of entry:", err)
+			}
+		}
 		// Add hosts to filtered tree
-		_, ok := filteredHosts[host.PublicKey.String()]
-		if isWhitelist != ok {
+		if hdb.filtered(host) {
 			continue
 		}
 		err := hdb.filteredTree.Insert(host)
@@ -579,8 +639,6 @@ func (hdb *HostDB) SetFilterMode(fm modules.FilterMode, hosts []types.SiaPublicK
 			allErrs = errors.Compose(allErrs, err)
 		}
 	}
-	hdb.filteredHosts = filteredHosts
-	hdb.filterMode = fm
 	return errors.Compose(allErrs, hdb.saveSync())
 }
 
@@ -6,6 +6,7 @@ import (
 	"net"
 	"os"
 	"path/filepath"
+	"reflect"
 	"testing"
 	"time"
 
@@ -20,6 +21,7 @@ import (
 	"gitlab.com/NebulousLabs/Sia/modules/wallet"
 	"gitlab.com/NebulousLabs/Sia/persist"
 	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
 )
 
 // hdbTester contains a hostdb and all dependencies.
@@ -741,3 +743,88 @@ func TestCheckForIPViolations(t *testing.T) {
 		t.Error("Hdb returned violation for wrong host")
 	}
 }
+
+// TestSetFilterRules tests that hosts are added to and removed from the
+// filteredTree according to the filter rules, also when a host's address
+// changes after the rules were set.
+func TestSetFilterRules(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	hdbt, err := newHDBTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer hdbt.hdb.Close()
+
+	// Add hosts inside and outside of a subnet.
+	inside := makeHostDBEntry()
+	inside.NetAddress = "10.0.0.1:9982"
+	excluded := makeHostDBEntry()
+	excluded.NetAddress = "10.0.0.2:9982"
+	outside := makeHostDBEntry()
+	outside.NetAddress = "11.0.0.1:9982"
+	hdbt.hdb.mu.Lock()
+	for _, host := range []modules.HostDBEntry{inside, excluded, outside} {
+		if err := hdbt.hdb.insert(host); err != nil {
+			hdbt.hdb.mu.Unlock()
+			t.Fatal(err)
+		}
+	}
+	hdbt.hdb.mu.Unlock()
+
+	// Whitelist the subnet but exclude one of its hosts.
+	rules := modules.HostDBFilterRules{
+		Subnets:       []string{"10.0.0.0/8"},
+		ExcludedHosts: []types.SiaPublicKey{excluded.PublicKey},
+	}
+	if err := hdbt.hdb.SetFilterRules(modules.HostDBActiveWhitelist, rules); err != nil {
+		t.Fatal(err)
+	}
+	isFiltered := func(pk types.SiaPublicKey) bool {
+		hdbt.hdb.mu.RLock()
+		defer hdbt.hdb.mu.RUnlock()
+		// In whitelist mode, the listed hosts are marked as filtered in the
+		// hostTree and are the only hosts in the filteredTree.
+		_, inTree := hdbt.hdb.filteredTree.Select(pk)
+		entry, _ := hdbt.hdb.hostTree.Select(pk)
+		if hdbt.hdb.filterMode == modules.HostDBActiveWhitelist && entry.Filtered != inTree {
+			t.Fatal("Filtered field doesn't match filteredTree")
+		}
+		return !inTree
+	}
+	if isFiltered(inside.PublicKey) || !isFiltered(excluded.PublicKey) || !isFiltered(outside.PublicKey) {
+		t.Fatal("wrong hosts filtered after setting the rules")
+	}
+
+	// Move the outside host into the subnet and the inside host out of it.
+	inside.NetAddress = "12.0.0.1:9982"
+	outside.NetAddress = "10.0.0.3:9982"
+	hdbt.hdb.mu.Lock()
+	err = errors.Compose(hdbt.hdb.modify(inside), hdbt.hdb.modify(outside))
+	hdbt.hdb.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !isFiltered(inside.PublicKey) || isFiltered(outside.PublicKey) {
+		t.Fatal("wrong hosts filtered after modifying the hosts")
+	}
+
+	// The rules should be returned including the hosts.
+	fm, gotRules, err := hdbt.hdb.FilterRules()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fm != modules.HostDBActiveWhitelist || !reflect.DeepEqual(gotRules, rules) {
+		t.Fatal("wrong rules returned", fm, gotRules)
+	}
+
+	// Disable the filter.
+	if err := hdbt.hdb.SetFilterRules(modules.HostDBDisableFilter, modules.HostDBFilterRules{}); err != nil {
+		t.Fatal(err)
+	}
+	if isFiltered(inside.PublicKey) || isFiltered(excluded.PublicKey) || isFiltered(outside.PublicKey) {
+		t.Fatal("no hosts should be filtered after disabling the filter")
+	}
+}
@@ -31,80 +31,83 @@ func NewFilter(resolver modules.Resolver) *Filter {
 	}
 }
 
-// Add adds a host to the filter. This will resolve the hostname into one
-// or more IP addresses, extract the subnets used by those addresses and
-// add the subnets to the filter. Add doesn't return an error, but if the
-// addresses of a host can't be resolved it will be handled as if the host
-// had no addresses associated with it.
-func (af *Filter) Add(host modules.NetAddress) {
-	// Translate the hostname to one or multiple IPs. If the argument is an IP
-	// address LookupIP will just return that IP.
-	addresses, err := af.resolver.LookupIP(host.Host())
-	if err != nil {
-		return
-	}
-	// If any of the addresses is blocked we ignore the host.
-	for _, ip := range addresses {
-		// Set the filterRange according to the type of IP address.
-		var filterRange int
-		if ip.To4() != nil {
-			filterRange = IPv4FilterRange
-		} else {
-			filterRange = IPv6FilterRange
-		}
-		// Get the subnet.
-		_, ipnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", ip.String(), filterRange))
+// Add adds a host to the filter. This will resolve the hostnames of all the
+// addresses of the host into one or more IP addresses, extract the subnets
+// used by those addresses and add the subnets to the filter. Add doesn't
+// return an error, but if an address of a host can't be resolved it will be
+// handled as if the address had no IP addresses associated with it.
+func (af *Filter) Add(host ...modules.NetAddress) {
+	for _, addr := range host {
+		// Translate the hostname to one or multiple IPs. If the argument is
+		// an IP address LookupIP will just return that IP.
+		addresses, err := af.resolver.LookupIP(addr.Host())
 		if err != nil {
 			continue
 		}
-		// Add the subnet to the map.
-		af.filter[ipnet.String()] = struct{}{}
+		for _, ip := range addresses {
+			// Get the subnet.
+			ipnet, err := filterSubnet(ip)
+			if err != nil {
+				continue
+			}
+			// Add the subnet to the map.
+			af.filter[ipnet.String()] = struct{}{}
+		}
 	}
 }
 
 // Filtered checks if a host uses a subnet that is already in use by a host
-// that was previously added to the filter. If it is in use, or if the host is
-// associated with 2 addresses of the same type (e.g. IPv4 and IPv4) or if it
-// is associated with more than 2 addresses, Filtered will return 'true'.
-func (af *Filter) Filtered(host modules.NetAddress) bool {
-	// Translate the hostname to one or multiple IPs. If the argument is an IP
-	// address LookupIP will just return that IP.
-	addresses, err := af.resolver.LookupIP(host.Host())
-	if err != nil {
-		return true
-	}
-	// If the hostname is associated with more than 2 addresses we filter it
-	if len(addresses) > 2 {
-		return true
-	}
-	// If the hostname is associated with 2 addresses of the same type, we
-	// filter it.
-	if (len(addresses) == 2) && (len(addresses[0]) == len(addresses[1])) {
-		return true
-	}
-	// If any of the addresses is blocked we ignore the host.
-	for _, ip := range addresses {
-		// Set the filterRange according to the type of IP address.
-		var filterRange int
-		if ip.To4() != nil {
-			filterRange = IPv4FilterRange
-		} else {
-			filterRange = IPv6FilterRange
-		}
-
-		// Get the subnet.
-		_, ipnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", ip.String(), filterRange))
+// that was previously added to the filter. If it is in use, or if any address
+// of the host is associated with 2 IP addresses of the same type (e.g. IPv4
+// and IPv4) or with more than 2 IP addresses, Filtered will return 'true'.
+// Hosts that announced multiple addresses are checked for all of them.
+func (af *Filter) Filtered(host ...modules.NetAddress) bool {
+	for _, addr := range host {
+		// Translate the hostname to one or multiple IPs. If the argument is
+		// an IP address LookupIP will just return that IP.
+		addresses, err := af.resolver.LookupIP(addr.Host())
 		if err != nil {
-			continue
+			return true
+		}
+		// If the hostname is associated with more than 2 addresses we filter
+		// it.
+		if len(addresses) > 2 {
+			return true
 		}
-		// Check if the subnet is in the map. If it is, we filter the host.
-		if _, exists := af.filter[ipnet.String()]; exists {
+		// If the hostname is associated with 2 addresses of the same type, we
+		// filter it.
+		if (len(addresses) == 2) && (len(addresses[0]) == len(addresses[1])) {
 			return true
 		}
+		// If any of the addresses is blocked we ignore the host.
+		for _, ip := range addresses {
+			// Get the subnet.
+			ipnet, err := filterSubnet(ip)
+			if err != nil {
+				continue
+			}
+			// Check if the subnet is in the map. If it is, we filter the
+			// host.
+			if _, exists := af.filter[ipnet.String()]; exists {
+				return true
+			}
+		}
 	}
 	return false
 }
 
+// filterSubnet returns the subnet of an IP address that is used by the
+// filter, which depends on the type of the IP address.
+func filterSubnet(ip net.IP) (*net.IPNet, error) {
+	// Set the filterRange according to the type of IP address.
+	filterRange := IPv6FilterRange
+	if ip.To4() != nil {
+		filterRange = IPv4FilterRange
+	}
+	_, ipnet, err := net.ParseCIDR(fmt.Sprintf("%s/%d", ip.String(), filterRange))
+	return ipnet, err
+}
+
 // Reset clears the filter's contents.
 func (af *Filter) Reset() {
 	af.filter = make(map[string]struct{})
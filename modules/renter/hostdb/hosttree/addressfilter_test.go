@@ -236,3 +236,35 @@ func TestFilterIPv6(t *testing.T) {
 		t.Error("host9 wasn't filtered")
 	}
 }
+
+// TestFilterMultipleAddresses tests filtering hosts that announced multiple
+// addresses.
+func TestFilterMultipleAddresses(t *testing.T) {
+	filter := NewFilter(testFilterIPv4Resolver{})
+
+	host1 := modules.NetAddress("host1:1234")
+	host2 := modules.NetAddress("host2:1234")
+	host3 := modules.NetAddress("host3:1234")
+	host5 := modules.NetAddress("host5:1234")
+	host6 := modules.NetAddress("host6:1234")
+
+	// A host with addresses in two subnets isn't filtered.
+	if filter.Filtered(host1, host5) {
+		t.Error("host1 was filtered")
+	}
+	filter.Add(host1, host5)
+
+	// A host that shares a subnet with any of those addresses is filtered,
+	// even if its primary address doesn't.
+	if !filter.Filtered(host3, host6) {
+		t.Error("host3 wasn't filtered")
+	}
+	if !filter.Filtered(host2) {
+		t.Error("host2 wasn't filtered")
+	}
+
+	// A host in unused subnets isn't filtered.
+	if filter.Filtered(host3) {
+		t.Error("host3 was filtered")
+	}
+}
@@ -0,0 +1,195 @@
+package hosttree
+
+import (
+	"net"
+	"regexp"
+	"strings"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+var (
+	// errASNRule is returned for rules that name an autonomous system. The
+	// node doesn't ship with an IP-to-ASN database, so a host's ASN is
+	// unknown and can't be matched. The subnets announced by the autonomous
+	// system can be used as subnet rules instead.
+	errASNRule = errors.New("ASN rules are not supported, use the subnets of the autonomous system instead")
+
+	// asnRuleRegexp matches rules of the form AS13335.
+	asnRuleRegexp = regexp.MustCompile(`^as[0-9]+$`)
+)
+
+// HostFilter decides which hosts are listed by a set of
+// modules.HostDBFilterRules. It only uses the information within a host's
+// entry and never resolves a host's address itself, which makes it cheap
+// enough to be evaluated whenever a host is inserted or modified.
+type HostFilter struct {
+	hosts         map[string]struct{}
+	excludedHosts map[string]struct{}
+
+	netAddresses         []string
+	excludedNetAddresses []string
+
+	subnets         []*net.IPNet
+	excludedSubnets []*net.IPNet
+}
+
+// NewHostFilter creates a HostFilter from the provided rules.
+func NewHostFilter(rules modules.HostDBFilterRules) (*HostFilter, error) {
+	hf := &HostFilter{
+		hosts:         make(map[string]struct{}),
+		excludedHosts: make(map[string]struct{}),
+	}
+	for _, pk := range rules.Hosts {
+		hf.hosts[pk.String()] = struct{}{}
+	}
+	for _, pk := range rules.ExcludedHosts {
+		hf.excludedHosts[pk.String()] = struct{}{}
+	}
+	var err error
+	hf.netAddresses, err = parseNetAddressRules(rules.NetAddresses)
+	if err != nil {
+		return nil, err
+	}
+	hf.excludedNetAddresses, err = parseNetAddressRules(rules.ExcludedNetAddresses)
+	if err != nil {
+		return nil, err
+	}
+	hf.subnets, err = parseSubnetRules(rules.Subnets)
+	if err != nil {
+		return nil, err
+	}
+	hf.excludedSubnets, err = parseSubnetRules(rules.ExcludedSubnets)
+	if err != nil {
+		return nil, err
+	}
+	return hf, nil
+}
+
+// parseNetAddressRules normalizes the provided netaddress rules.
+func parseNetAddressRules(rules []string) ([]string, error) {
+	var parsed []string
+	for _, rule := range rules {
+		rule = strings.TrimSuffix(strings.ToLower(strings.TrimSpace(rule)), ".")
+		if asnRuleRegexp.MatchString(rule) {
+			return nil, errASNRule
+		}
+		if rule == "" || rule == "*" || strings.ContainsAny(rule, "/ ") {
+			return nil, errors.New("invalid netaddress rule: " + rule)
+		}
+		if strings.Contains(strings.TrimPrefix(rule, "*."), "*") {
+			return nil, errors.New("wildcards are only allowed as the first label of a netaddress rule: " + rule)
+		}
+		parsed = append(parsed, rule)
+	}
+	return parsed, nil
+}
+
+// parseSubnetRules parses the provided CIDR subnets. Single IP addresses are
+// treated as subnets that only contain that address.
+func parseSubnetRules(rules []string) ([]*net.IPNet, error) {
+	var parsed []*net.IPNet
+	for _, rule := range rules {
+		rule = strings.TrimSpace(rule)
+		if ip := net.ParseIP(rule); ip != nil {
+			bits := 8 * net.IPv6len
+			if ip.To4() != nil {
+				ip = ip.To4()
+				bits = 8 * net.IPv4len
+			}
+			parsed = append(parsed, &net.IPNet{IP: ip, Mask: net.CIDRMask(bits, bits)})
+			continue
+		}
+		if asnRuleRegexp.MatchString(strings.ToLower(rule)) {
+			return nil, errASNRule
+		}
+		_, ipnet, err := net.ParseCIDR(rule)
+		if err != nil {
+			return nil, errors.AddContext(err, "invalid subnet rule")
+		}
+		parsed = append(parsed, ipnet)
+	}
+	return parsed, nil
+}
+
+// matchNetAddress returns true if the host matches one of the netaddress
+// rules.
+func matchNetAddress(rules []string, host string) bool {
+	for _, rule := range rules {
+		if rule == host {
+			return true
+		}
+		if strings.HasPrefix(rule, "*.") && strings.HasSuffix(host, rule[1:]) {
+			return true
+		}
+	}
+	return false
+}
+
+// longestSubnetMatch returns the prefix length of the most specific subnet rule
+// that contains the host's IP or one of its subnets. If no rule matches, -1 is
+// returned.
+func longestSubnetMatch(rules []*net.IPNet, ip net.IP, ipNets []*net.IPNet) int {
+	longest := -1
+	for _, rule := range rules {
+		ones, _ := rule.Mask.Size()
+		if ones <= longest {
+			continue
+		}
+		if ip != nil && rule.Contains(ip) {
+			longest = ones
+			continue
+		}
+		// A rule only contains a subnet of the host if the rule is at most
+		// as specific as the subnet.
+		for _, ipNet := range ipNets {
+			subnetOnes, _ := ipNet.Mask.Size()
+			if ones <= subnetOnes && rule.Contains(ipNet.IP) {
+				longest = ones
+				break
+			}
+		}
+	}
+	return longest
+}
+
+// Listed returns true if the host is listed by the filter's rules. See
+// modules.HostDBFilterRules for the precedence of the rules. A nil HostFilter
+// doesn't list any hosts.
+func (hf *HostFilter) Listed(entry modules.HostDBEntry) bool {
+	if hf == nil {
+		return false
+	}
+	// Key rules.
+	pk := entry.PublicKey.String()
+	if _, excluded := hf.excludedHosts[pk]; excluded {
+		return false
+	}
+	if _, listed := hf.hosts[pk]; listed {
+		return true
+	}
+	// Netaddress rules.
+	host := strings.TrimSuffix(strings.ToLower(entry.NetAddress.Host()), ".")
+	if matchNetAddress(hf.excludedNetAddresses, host) {
+		return false
+	}
+	if matchNetAddress(hf.netAddresses, host) {
+		return true
+	}
+	// Subnet rules.
+	if len(hf.subnets) == 0 {
+		return false
+	}
+	ip := net.ParseIP(host)
+	var ipNets []*net.IPNet
+	for _, s := range entry.IPNets {
+		if _, ipNet, err := net.ParseCIDR(s); err == nil {
+			ipNets = append(ipNets, ipNet)
+		}
+	}
+	listed := longestSubnetMatch(hf.subnets, ip, ipNets)
+	excluded := longestSubnetMatch(hf.excludedSubnets, ip, ipNets)
+	return listed >= 0 && listed > excluded
+}
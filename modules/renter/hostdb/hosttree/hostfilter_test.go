@@ -0,0 +1,94 @@
+package hosttree
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestHostFilterListed tests that the HostFilter applies the precedence rules
+// of the filter rules correctly.
+func TestHostFilterListed(t *testing.T) {
+	pk1 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	pk2 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{2}}
+	pk3 := types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{3}}
+	entry := func(pk types.SiaPublicKey, addr string, ipNets ...string) modules.HostDBEntry {
+		var e modules.HostDBEntry
+		e.PublicKey = pk
+		e.NetAddress = modules.NetAddress(addr)
+		e.IPNets = ipNets
+		return e
+	}
+
+	hf, err := NewHostFilter(modules.HostDBFilterRules{
+		Hosts:                []types.SiaPublicKey{pk1},
+		NetAddresses:         []string{"*.example.com", "host.sia.tech"},
+		Subnets:              []string{"10.0.0.0/8", "192.168.1.1"},
+		ExcludedHosts:        []types.SiaPublicKey{pk2},
+		ExcludedNetAddresses: []string{"bad.example.com"},
+		ExcludedSubnets:      []string{"10.1.0.0/16", "192.168.0.0/16"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	tests := []struct {
+		name   string
+		entry  modules.HostDBEntry
+		listed bool
+	}{
+		{"listed key", entry(pk1, "1.1.1.1:9982"), true},
+		{"listed key in excluded subnet", entry(pk1, "10.1.2.3:9982"), true},
+		{"excluded key in listed subnet", entry(pk2, "10.2.3.4:9982"), false},
+		{"wildcard netaddress", entry(pk3, "Foo.Example.com:9982"), true},
+		{"wildcard doesn't match domain", entry(pk3, "example.com:9982"), false},
+		{"excluded netaddress", entry(pk3, "bad.example.com:9982"), false},
+		{"exact netaddress", entry(pk3, "host.sia.tech:9982"), true},
+		{"listed subnet", entry(pk3, "10.2.3.4:9982"), true},
+		{"longer excluded subnet", entry(pk3, "10.1.2.3:9982"), false},
+		{"longer listed ip", entry(pk3, "192.168.1.1:9982"), true},
+		{"excluded subnet", entry(pk3, "192.168.1.2:9982"), false},
+		{"unlisted ip", entry(pk3, "1.1.1.1:9982"), false},
+		{"listed ipnet", entry(pk3, "unknown.sia.tech:9982", "10.2.3.0/24"), true},
+		{"excluded ipnet", entry(pk3, "unknown.sia.tech:9982", "10.1.3.0/24"), false},
+		{"ipnet less specific than rule", entry(pk3, "unknown.sia.tech:9982", "192.168.1.0/24"), false},
+	}
+	for _, test := range tests {
+		if listed := hf.Listed(test.entry); listed != test.listed {
+			t.Errorf("%v: expected listed to be %v but was %v", test.name, test.listed, listed)
+		}
+	}
+
+	// A nil filter doesn't list anything.
+	var nilFilter *HostFilter
+	if nilFilter.Listed(entry(pk1, "10.0.0.1:9982")) {
+		t.Fatal("nil filter shouldn't list hosts")
+	}
+
+	// Invalid rules should be rejected.
+	invalid := []modules.HostDBFilterRules{
+		{Subnets: []string{"10.0.0.0/33"}},
+		{ExcludedSubnets: []string{"foo"}},
+		{NetAddresses: []string{"*"}},
+		{NetAddresses: []string{"foo.*.com"}},
+		{ExcludedNetAddresses: []string{""}},
+	}
+	for _, rules := range invalid {
+		if _, err := NewHostFilter(rules); err == nil {
+			t.Errorf("expected rules %v to be invalid", rules)
+		}
+	}
+
+	// ASN rules aren't supported.
+	asn := []modules.HostDBFilterRules{
+		{NetAddresses: []string{"AS13335"}},
+		{ExcludedNetAddresses: []string{"as64512"}},
+		{Subnets: []string{"AS13335"}},
+		{ExcludedSubnets: []string{"as64512"}},
+	}
+	for _, rules := range asn {
+		if _, err := NewHostFilter(rules); err != errASNRule {
+			t.Errorf("expected errASNRule for rules %v, got %v", rules, err)
+		}
+	}
+}
@@ -321,7 +321,7 @@ func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaP
 			continue
 		}
 		// Add the node to the addressFilter.
-		filter.Add(node.entry.NetAddress)
+		filter.Add(node.entry.Addresses()...)
 	}
 	// Remove hosts we want to blacklist from the tree but remember them to make
 	// sure we can insert them later.
@@ -348,7 +348,7 @@ func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaP
 		if node.entry.AcceptingContracts &&
 			len(node.entry.ScanHistory) > 0 &&
 			node.entry.ScanHistory[len(node.entry.ScanHistory)-1].Success &&
-			!filter.Filtered(node.entry.NetAddress) &&
+			!filter.Filtered(node.entry.Addresses()...) &&
 			node.entry.weight.Cmp(weightOne) > 0 {
 			// The host must be online and accepting contracts to be returned
 			// by the random function. It also has to pass the addressFilter
@@ -356,7 +356,7 @@ func (ht *HostTree) SelectRandom(n int, blacklist, addressBlacklist []types.SiaP
 			hosts = append(hosts, node.entry.HostDBEntry)
 
 			// If the host passed the filter, we add it to the filter.
-			filter.Add(node.entry.NetAddress)
+			filter.Add(node.entry.Addresses()...)
 		}
 
 		removedEntries = append(removedEntries, node.entry)
@@ -4,6 +4,8 @@ import (
 	"path/filepath"
 	"time"
 
+	"gitlab.com/NebulousLabs/errors"
+
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/modules/renter/hostdb/hosttree"
 	"gitlab.com/NebulousLabs/Sia/persist"
@@ -32,6 +34,7 @@ type hdbPersist struct {
 	LastChange               modules.ConsensusChangeID
 	FilteredHosts            map[string]types.SiaPublicKey
 	FilterMode               modules.FilterMode
+	FilterRules              modules.HostDBFilterRules
 }
 
 // persistData returns the data in the hostdb that will be saved to disk.
@@ -43,6 +46,7 @@ func (hdb *HostDB) persistData() (data hdbPersist) {
 	data.LastChange = hdb.lastChange
 	data.FilteredHosts = hdb.filteredHosts
 	data.FilterMode = hdb.filterMode
+	data.FilterRules = hdb.filterRules
 	return data
 }
 
@@ -68,8 +72,19 @@ func (hdb *HostDB) load() error {
 	hdb.knownContracts = data.KnownContracts
 	hdb.filteredHosts = data.FilteredHosts
 	hdb.filterMode = data.FilterMode
+	hdb.filterRules = data.FilterRules
 
-	if len(hdb.filteredHosts) > 0 {
+	if hdb.filterMode == modules.HostDBActivateBlacklist || hdb.filterMode == modules.HostDBActiveWhitelist {
+		// The filtered hosts are stored separately from the other rules.
+		rules := hdb.filterRules
+		for _, pk := range hdb.filteredHosts {
+			rules.Hosts = append(rules.Hosts, pk)
+		}
+		hostFilter, err := hosttree.NewHostFilter(rules)
+		if err != nil {
+			return errors.AddContext(err, "unable to load hostdb filter rules")
+		}
+		hdb.hostFilter = hostFilter
 		hdb.filteredTree = hosttree.New(hdb.weightFunc, modules.ProdDependencies.Resolver())
 	}
 
@@ -32,8 +32,6 @@ func (hdb *HostDB) RandomHosts(n int, blacklist, addressBlacklist []types.SiaPub
 func (hdb *HostDB) RandomHostsWithAllowance(n int, blacklist, addressBlacklist []types.SiaPublicKey, allowance modules.Allowance) ([]modules.HostDBEntry, error) {
 	hdb.mu.RLock()
 	initialScanComplete := hdb.initialScanComplete
-	filteredHosts := hdb.filteredHosts
-	filterType := hdb.filterMode
 	hdb.mu.RUnlock()
 	if !initialScanComplete && !hdb.deps.Disrupt("InitialScanComplete") {
 		return []modules.HostDBEntry{}, ErrInitialScanIncomplete
@@ -46,11 +44,9 @@ func (hdb *HostDB) RandomHostsWithAllowance(n int, blacklist, addressBlacklist [
 	defer hdb.mu.RUnlock()
 	var insertErrs error
 	allHosts := hdb.hostTree.All()
-	isWhitelist := filterType == modules.HostDBActiveWhitelist
 	for _, host := range allHosts {
 		// Filter out listed hosts
-		_, ok := filteredHosts[host.PublicKey.String()]
-		if isWhitelist != ok {
+		if hdb.filtered(host) {
 			continue
 		}
 		if err := ht.Insert(host); err != nil {
@@ -11,6 +11,7 @@ import (
 	"sort"
 	"time"
 
+	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/fastrand"
 
 	"gitlab.com/NebulousLabs/Sia/build"
@@ -324,11 +325,16 @@ func (hdb *HostDB) updateEntry(entry modules.HostDBEntry, netErr error) {
 // about the error because we don't update host entries if we are offline
 // anyway. So if we fail to resolve a hostname, the problem is not related to
 // us.
-func (hdb *HostDB) managedLookupIPNets(address modules.NetAddress) (ipNets []string, err error) {
-	// Lookup the IP addresses of the host.
-	addresses, err := hdb.deps.Resolver().LookupIP(address.Host())
-	if err != nil {
-		return nil, err
+func (hdb *HostDB) managedLookupIPNets(netAddresses ...modules.NetAddress) (ipNets []string, err error) {
+	// Lookup the IP addresses of the host. A host that announced multiple
+	// addresses uses the subnets of all of them.
+	var addresses []net.IP
+	for _, address := range netAddresses {
+		ips, err := hdb.deps.Resolver().LookupIP(address.Host())
+		if err != nil {
+			return nil, err
+		}
+		addresses = append(addresses, ips...)
 	}
 	// Get the subnets of the addresses.
 	for _, ip := range addresses {
@@ -354,22 +360,24 @@ func (hdb *HostDB) managedLookupIPNets(address modules.NetAddress) (ipNets []str
 // managedScanHost will connect to a host and grab the settings, verifying
 // uptime and updating to the host's preferences.
 func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
-	// Request settings from the queued host entry.
-	netAddr := entry.NetAddress
+	// Request settings from the queued host entry. The alternate addresses of
+	// the host are tried if it can't be reached on its primary address.
+	netAddrs := entry.Addresses()
 	pubKey := entry.PublicKey
-	hdb.log.Debugf("Scanning host %v at %v", pubKey, netAddr)
+	hdb.log.Debugf("Scanning host %v at %v", pubKey, netAddrs)
 
 	// If we use a custom resolver for testing, we replace the custom domain
 	// with 127.0.0.1. Otherwise the scan will fail.
 	if hdb.deps.Disrupt("customResolver") {
-		port := netAddr.Port()
-		netAddr = modules.NetAddress(fmt.Sprintf("127.0.0.1:%s", port))
+		for i, netAddr := range netAddrs {
+			netAddrs[i] = modules.NetAddress(fmt.Sprintf("127.0.0.1:%s", netAddr.Port()))
+		}
 	}
 
 	// Resolve the host's used subnets and update the timestamp if they
 	// changed. We only update the timestamp if resolving the ipNets was
 	// successful.
-	ipNets, err := hdb.managedLookupIPNets(entry.NetAddress)
+	ipNets, err := hdb.managedLookupIPNets(entry.Addresses()...)
 	if err == nil && !equalIPNets(ipNets, entry.IPNets) {
 		entry.IPNets = ipNets
 		entry.LastIPNetChange = time.Now()
@@ -408,8 +416,21 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 			Cancel:  hdb.tg.StopChan(),
 			Timeout: timeout,
 		}
+		// Dial the host over raw TCP on its addresses, falling back to its
+		// WebSocket endpoint if it's unreachable.
+		dial := func() (net.Conn, error) {
+			conn, _, err := modules.DialAddresses(dialer, netAddrs)
+			if err == nil || entry.WebSocketURL == "" {
+				return conn, err
+			}
+			conn, wsErr := modules.DialWebSocket(dialer, entry.WebSocketURL)
+			if wsErr != nil {
+				return nil, errors.Compose(err, wsErr)
+			}
+			return conn, nil
+		}
 		start := time.Now()
-		conn, err := dialer.Dial("tcp", string(netAddr))
+		conn, err := dial()
 		latency = time.Since(start)
 		if err != nil {
 			return err
@@ -461,7 +482,7 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 		// closing. Additionally, we can't assign the result of Dial to conn,
 		// because if the Dial fails and conn is nil, then the deferred call to
 		// Close will segfault.
-		conn2, err := dialer.Dial("tcp", string(netAddr))
+		conn2, err := dial()
 		if err != nil {
 			return err
 		}
@@ -526,10 +547,11 @@ func (hdb *HostDB) managedScanHost(entry modules.HostDBEntry) {
 	hdb.mu.Lock()
 	defer hdb.mu.Unlock()
 	// We don't want to override the NetAddress during a scan so we need to
-	// retrieve the most recent NetAddress from the tree first.
+	// retrieve the most recent NetAddresses from the tree first.
 	oldEntry, exists := hdb.hostTree.Select(entry.PublicKey)
 	if exists {
 		entry.NetAddress = oldEntry.NetAddress
+		entry.NetAddresses = oldEntry.NetAddresses
 	}
 	// Update the host tree to have a new entry, including the new error. Then
 	// delete the entry from the scan map as the scan has been successful.
@@ -16,14 +16,15 @@ func findHostAnnouncements(b types.Block) (announcements []modules.HostDBEntry)
 		// the HostAnnouncement must be prefaced by the standard host
 		// announcement string
 		for _, arb := range t.ArbitraryData {
-			addr, pubKey, err := modules.DecodeAnnouncement(arb)
+			addrs, pubKey, err := modules.DecodeAnnouncementAddresses(arb)
 			if err != nil {
 				continue
 			}
 
 			// Add the announcement to the slice being returned.
 			var host modules.HostDBEntry
-			host.NetAddress = addr
+			host.NetAddress = addrs[0]
+			host.NetAddresses = addrs
 			host.PublicKey = pubKey
 			announcements = append(announcements, host)
 		}
@@ -44,6 +45,16 @@ func (hdb *HostDB) insertBlockchainHost(host modules.HostDBEntry) {
 	if build.Release == "standard" && host.NetAddress.IsLocal() {
 		return
 	}
+	// Drop the alternate addresses that are invalid or local.
+	var addrs []modules.NetAddress
+	for _, addr := range host.Addresses() {
+		if addr.IsValid() != nil || (build.Release == "standard" && addr.IsLocal()) {
+			hdb.log.Debugf("WARN: host '%v' announced an invalid alternate NetAddress: %v", host.NetAddress, addr)
+			continue
+		}
+		addrs = append(addrs, addr)
+	}
+	host.NetAddresses = addrs
 
 	// Make sure the host gets into the host tree so it does not get dropped if
 	// shutdown occurs before a scan can be performed.
@@ -55,13 +66,14 @@ func (hdb *HostDB) insertBlockchainHost(host modules.HostDBEntry) {
 		// first seen height of zero, but due to rescans hosts can end up with
 		// a zero-value FirstSeen field.
 		oldEntry.NetAddress = host.NetAddress
+		oldEntry.NetAddresses = host.NetAddresses
 		if oldEntry.FirstSeen == 0 {
 			oldEntry.FirstSeen = hdb.blockHeight
 		}
 		// Resolve the host's used subnets and update the timestamp if they
 		// changed. We only update the timestamp if resolving the ipNets was
 		// successful.
-		ipNets, err := hdb.managedLookupIPNets(oldEntry.NetAddress)
+		ipNets, err := hdb.managedLookupIPNets(oldEntry.Addresses()...)
 		if err == nil && !equalIPNets(ipNets, oldEntry.IPNets) {
 			oldEntry.IPNets = ipNets
 			oldEntry.LastIPNetChange = time.Now()
@@ -3,6 +3,7 @@ package renter
 import (
 	"os"
 	"path/filepath"
+	"time"
 
 	"gitlab.com/NebulousLabs/errors"
 	"gitlab.com/NebulousLabs/writeaheadlog"
@@ -61,6 +62,11 @@ type (
 		MaxUploadSpeed   int64
 		UploadedBackups  []modules.UploadedBackup
 		SyncedContracts  []types.FileContractID
+
+		// Audit settings and the audit results of each contract.
+		AuditInterval  time.Duration
+		MaxAuditPrice  types.Currency
+		ContractAudits map[string]modules.ContractAuditStatus
 	}
 )
 
@@ -109,6 +115,11 @@ func (r *Renter) managedLoadSettings() error {
 		return err
 	}
 
+	// Older persist files don't contain any audit results.
+	if r.persist.ContractAudits == nil {
+		r.persist.ContractAudits = make(map[string]modules.ContractAuditStatus)
+	}
+
 	// Set the bandwidth limits on the contractor, which was already initialized
 	// without bandwidth limits.
 	return r.setBandwidthLimits(r.persist.MaxDownloadSpeed, r.persist.MaxUploadSpeed)
@@ -0,0 +1,313 @@
+package renter
+
+// profiles.go contains the ProfileManager which runs additional, named renters
+// next to the node's main renter. Every profile is a complete renter with its
+// own hostdb, contractor and filesystem that is stored in its own directory.
+// The profiles share the gateway, consensus set, transaction pool and wallet
+// of the node. Every profile derives its own renter seed from the wallet seed
+// and its name, so profiles can't recover or read each other's contracts and
+// snapshots.
+
+import (
+	"os"
+	"path/filepath"
+	"sort"
+	"sync"
+
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/threadgroup"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/persist"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+const (
+	// maxProfileNameLen is the maximum length of a profile name.
+	maxProfileNameLen = 64
+
+	// profilesFilename is the name of the file that contains the list of
+	// profiles.
+	profilesFilename = "profiles.json"
+
+	// profilesLogFilename is the name of the ProfileManager's log file.
+	profilesLogFilename = "profiles.log"
+)
+
+var (
+	// errInvalidProfileName is returned when a profile name contains invalid
+	// characters or has an invalid length.
+	errInvalidProfileName = errors.New("profile names must be 1-64 characters long and may only contain letters, digits, '-' and '_'")
+
+	// errProfileExists is returned when trying to create a profile that
+	// already exists.
+	errProfileExists = errors.New("renter profile already exists")
+
+	// errProfileNotFound is returned when trying to access a profile that
+	// doesn't exist.
+	errProfileNotFound = errors.New("renter profile not found")
+
+	// errReservedProfileName is returned when trying to create or remove the
+	// profile of the main renter.
+	errReservedProfileName = errors.New("profile name is reserved for the main renter")
+
+	// profileSeedSpecifier is used to derive the seeds of the profiles from
+	// the primary seed of the wallet.
+	profileSeedSpecifier = types.NewSpecifier("renterprofile")
+
+	// profilesMetadata is the metadata of the profiles persist file.
+	profilesMetadata = persist.Metadata{
+		Header:  "Renter Profiles",
+		Version: "1.4.4",
+	}
+)
+
+type (
+	// ProfileManager runs the renters of the additional renter profiles.
+	ProfileManager struct {
+		// The renters of the profiles by name.
+		profiles map[string]*Renter
+
+		// Modules shared by all profiles.
+		cs    modules.ConsensusSet
+		g     modules.Gateway
+		tpool modules.TransactionPool
+		w     modules.Wallet
+
+		log        *persist.Logger
+		mu         sync.Mutex
+		persistDir string
+		tg         threadgroup.ThreadGroup
+	}
+
+	// profileWallet is the wallet of a profile. It replaces the primary seed
+	// of the wallet with a seed derived from the name of the profile, which
+	// the renter uses to derive its renter seed.
+	profileWallet struct {
+		modules.Wallet
+		name string
+	}
+
+	// profilesPersist contains the persistent data of the ProfileManager.
+	profilesPersist struct {
+		Profiles []string `json:"profiles"`
+	}
+)
+
+// validateProfileName checks that a profile name can be used as a directory
+// name and doesn't refer to the main renter.
+func validateProfileName(name string) error {
+	if name == modules.DefaultRenterProfile {
+		return errReservedProfileName
+	}
+	if len(name) == 0 || len(name) > maxProfileNameLen {
+		return errInvalidProfileName
+	}
+	for _, c := range name {
+		switch {
+		case c >= 'a' && c <= 'z':
+		case c >= 'A' && c <= 'Z':
+		case c >= '0' && c <= '9':
+		case c == '-' || c == '_':
+		default:
+			return errInvalidProfileName
+		}
+	}
+	return nil
+}
+
+// NewProfileManager creates a new ProfileManager and starts the renters of
+// all the profiles that were created previously.
+func NewProfileManager(g modules.Gateway, cs modules.ConsensusSet, tpool modules.TransactionPool, w modules.Wallet, persistDir string) (*ProfileManager, error) {
+	if g == nil {
+		return nil, errNilGateway
+	}
+	if cs == nil {
+		return nil, errNilCS
+	}
+	if tpool == nil {
+		return nil, errNilTpool
+	}
+	if w == nil {
+		return nil, errNilWallet
+	}
+	pm := &ProfileManager{
+		profiles: make(map[string]*Renter),
+
+		cs:         cs,
+		g:          g,
+		tpool:      tpool,
+		w:          w,
+		persistDir: persistDir,
+	}
+	if err := os.MkdirAll(persistDir, 0700); err != nil {
+		return nil, err
+	}
+	log, err := persist.NewFileLogger(filepath.Join(persistDir, profilesLogFilename))
+	if err != nil {
+		return nil, err
+	}
+	pm.log = log
+	err = pm.tg.AfterStop(func() error {
+		return pm.log.Close()
+	})
+	if err != nil {
+		return nil, errors.Compose(err, log.Close())
+	}
+
+	// Load the profiles.
+	var data profilesPersist
+	err = persist.LoadJSON(profilesMetadata, &data, filepath.Join(persistDir, profilesFilename))
+	if err != nil && !os.IsNotExist(err) {
+		return nil, errors.Compose(err, pm.tg.Stop())
+	}
+	for _, name := range data.Profiles {
+		r, err := pm.newProfileRenter(name)
+		if err != nil {
+			err = errors.AddContext(err, "unable to start renter profile "+name)
+			return nil, errors.Compose(err, pm.Close())
+		}
+		pm.profiles[name] = r
+	}
+	return pm, nil
+}
+
+// PrimarySeed returns the seed of the profile, which is derived from the
+// primary seed of the wallet and the name of the profile.
+func (pw profileWallet) PrimarySeed() (modules.Seed, uint64, error) {
+	seed, progress, err := pw.Wallet.PrimarySeed()
+	if err != nil {
+		return modules.Seed{}, 0, err
+	}
+	return modules.Seed(crypto.HashAll(seed, profileSeedSpecifier, pw.name)), progress, nil
+}
+
+// newProfileRenter creates the renter for the profile with the given name.
+// Errors during the asynchronous startup of the renter are logged.
+func (pm *ProfileManager) newProfileRenter(name string) (*Renter, error) {
+	w := profileWallet{Wallet: pm.w, name: name}
+	r, errChan := New(pm.g, pm.cs, w, pm.tpool, filepath.Join(pm.persistDir, name))
+	if err := modules.PeekErr(errChan); err != nil {
+		return nil, err
+	}
+	if err := pm.tg.Add(); err != nil {
+		return nil, errors.Compose(err, r.Close())
+	}
+	go func() {
+		defer pm.tg.Done()
+		if err := <-errChan; err != nil {
+			pm.log.Printf("WARN: asynchronous startup of renter profile %v failed: %v", name, err)
+		}
+	}()
+	return r, nil
+}
+
+// save saves the list of profiles to disk.
+func (pm *ProfileManager) save() error {
+	data := profilesPersist{
+		Profiles: make([]string, 0, len(pm.profiles)),
+	}
+	for name := range pm.profiles {
+		data.Profiles = append(data.Profiles, name)
+	}
+	sort.Strings(data.Profiles)
+	return persist.SaveJSON(profilesMetadata, data, filepath.Join(pm.persistDir, profilesFilename))
+}
+
+// Close closes the renters of all profiles.
+func (pm *ProfileManager) Close() error {
+	pm.mu.Lock()
+	var err error
+	for _, r := range pm.profiles {
+		err = errors.Compose(err, r.Close())
+	}
+	pm.mu.Unlock()
+	return errors.Compose(err, pm.tg.Stop())
+}
+
+// CreateProfile creates and starts a new renter profile with the given name.
+// If the directory of a previously removed profile with the same name still
+// exists, the new profile will pick up its data.
+func (pm *ProfileManager) CreateProfile(name string) error {
+	if err := pm.tg.Add(); err != nil {
+		return err
+	}
+	defer pm.tg.Done()
+	if err := validateProfileName(name); err != nil {
+		return err
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	if _, exists := pm.profiles[name]; exists {
+		return errProfileExists
+	}
+	r, err := pm.newProfileRenter(name)
+	if err != nil {
+		return errors.AddContext(err, "unable to create renter")
+	}
+	pm.profiles[name] = r
+	if err := pm.save(); err != nil {
+		delete(pm.profiles, name)
+		return errors.Compose(errors.AddContext(err, "unable to save profiles"), r.Close())
+	}
+	pm.log.Println("Created renter profile", name)
+	return nil
+}
+
+// Profile returns the renter of the profile with the given name.
+func (pm *ProfileManager) Profile(name string) (modules.Renter, error) {
+	if err := pm.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer pm.tg.Done()
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	r, exists := pm.profiles[name]
+	if !exists {
+		return nil, errProfileNotFound
+	}
+	return r, nil
+}
+
+// Profiles returns the names of all profiles, sorted alphabetically.
+func (pm *ProfileManager) Profiles() []string {
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	names := make([]string, 0, len(pm.profiles))
+	for name := range pm.profiles {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// RemoveProfile stops the renter of the profile with the given name and
+// removes the profile. The profile's directory is kept on disk to avoid losing
+// contracts and files by accident.
+func (pm *ProfileManager) RemoveProfile(name string) error {
+	if err := pm.tg.Add(); err != nil {
+		return err
+	}
+	defer pm.tg.Done()
+	if name == modules.DefaultRenterProfile {
+		return errReservedProfileName
+	}
+
+	pm.mu.Lock()
+	defer pm.mu.Unlock()
+	r, exists := pm.profiles[name]
+	if !exists {
+		return errProfileNotFound
+	}
+	delete(pm.profiles, name)
+	if err := pm.save(); err != nil {
+		pm.profiles[name] = r
+		return errors.AddContext(err, "unable to save profiles")
+	}
+	pm.log.Println("Removed renter profile", name)
+	return errors.AddContext(r.Close(), "unable to close renter")
+}
+
+var _ modules.RenterProfiles = (*ProfileManager)(nil)
@@ -0,0 +1,122 @@
+package renter
+
+import (
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// TestValidateProfileName is a unit test for validateProfileName.
+func TestValidateProfileName(t *testing.T) {
+	tests := []struct {
+		name string
+		err  error
+	}{
+		{"", errInvalidProfileName},
+		{modules.DefaultRenterProfile, errReservedProfileName},
+		{"project-a", nil},
+		{"Project_B2", nil},
+		{"project/a", errInvalidProfileName},
+		{"..", errInvalidProfileName},
+		{"projekt ä", errInvalidProfileName},
+		{strings.Repeat("a", maxProfileNameLen), nil},
+		{strings.Repeat("a", maxProfileNameLen+1), errInvalidProfileName},
+	}
+	for _, test := range tests {
+		if err := validateProfileName(test.name); err != test.err {
+			t.Errorf("validateProfileName(%q): expected %v, got %v", test.name, test.err, err)
+		}
+	}
+}
+
+// TestProfileManager tests creating, loading and removing renter profiles.
+func TestProfileManager(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	rt, err := newRenterTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer rt.Close()
+
+	persistDir := filepath.Join(rt.dir, modules.RenterProfilesDir)
+	pm, err := NewProfileManager(rt.gateway, rt.cs, rt.tpool, rt.wallet, persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pm.Profiles()) != 0 {
+		t.Fatal("new profile manager shouldn't have any profiles")
+	}
+
+	// Create two profiles.
+	for _, name := range []string{"b", "a"} {
+		if err := pm.CreateProfile(name); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := pm.CreateProfile("a"); err != errProfileExists {
+		t.Fatal("expected errProfileExists, got", err)
+	}
+	if err := pm.CreateProfile(modules.DefaultRenterProfile); err != errReservedProfileName {
+		t.Fatal("expected errReservedProfileName, got", err)
+	}
+	if profiles := pm.Profiles(); !reflect.DeepEqual(profiles, []string{"a", "b"}) {
+		t.Fatal("wrong profiles", profiles)
+	}
+
+	// The profiles should have independent renters.
+	ra, err := pm.Profile("a")
+	if err != nil {
+		t.Fatal(err)
+	}
+	rb, err := pm.Profile("b")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ra == rb || ra == modules.Renter(rt.renter) {
+		t.Fatal("profiles should have their own renters")
+	}
+
+	// The profiles should derive their own renter seeds.
+	seeds := make(map[modules.Seed]struct{})
+	for _, r := range []*Renter{rt.renter, ra.(*Renter), rb.(*Renter)} {
+		seed, _, err := r.w.PrimarySeed()
+		if err != nil {
+			t.Fatal(err)
+		}
+		seeds[seed] = struct{}{}
+	}
+	if len(seeds) != 3 {
+		t.Fatal("profiles should have their own seeds")
+	}
+	if _, err := pm.Profile("c"); err != errProfileNotFound {
+		t.Fatal("expected errProfileNotFound, got", err)
+	}
+
+	// Remove a profile.
+	if err := pm.RemoveProfile("b"); err != nil {
+		t.Fatal(err)
+	}
+	if err := pm.RemoveProfile("b"); err != errProfileNotFound {
+		t.Fatal("expected errProfileNotFound, got", err)
+	}
+
+	// Restart the profile manager. Only the remaining profile should be
+	// loaded.
+	if err := pm.Close(); err != nil {
+		t.Fatal(err)
+	}
+	pm, err = NewProfileManager(rt.gateway, rt.cs, rt.tpool, rt.wallet, persistDir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer pm.Close()
+	if profiles := pm.Profiles(); !reflect.DeepEqual(profiles, []string{"a"}) {
+		t.Fatal("wrong profiles after restart", profiles)
+	}
+}
@@ -89,4 +89,12 @@ var (
 	// ErrBadHostVersion indicates that the host is using an older, incompatible
 	// version of the renter-host protocol.
 	ErrBadHostVersion = errors.New("Bad host version; host does not support required protocols")
+
+	// ErrBadSectorData is returned by Read if the host sent too little sector
+	// data or data that doesn't match the Merkle proof.
+	ErrBadSectorData = errors.New("host provided incorrect sector data or Merkle proof")
+
+	// ErrSectorNotFound is returned by Read if the host doesn't store a
+	// requested sector.
+	ErrSectorNotFound = errors.New("host doesn't store the requested sector")
 )
@@ -5,6 +5,7 @@ import (
 	"io"
 	"os"
 	"path/filepath"
+	"sort"
 	"sync"
 
 	"gitlab.com/NebulousLabs/errors"
@@ -24,6 +25,7 @@ const (
 
 	updateNameSetHeader = "setHeader"
 	updateNameSetRoot   = "setRoot"
+	updateNameTrimRoots = "trimRoots"
 )
 
 type updateSetHeader struct {
@@ -37,6 +39,26 @@ type updateSetRoot struct {
 	Index int
 }
 
+type updateTrimRoots struct {
+	ID       types.FileContractID
+	NumRoots int
+}
+
+// sectorRootChanges are the changes that a revision makes to the sector roots
+// of a contract. The roots in set are written to their index before the roots
+// are trimmed to numRoots.
+type sectorRootChanges struct {
+	set      []sectorRootChange
+	numRoots int
+}
+
+// sectorRootChange is a root that is written to an index of a contract's
+// sector roots.
+type sectorRootChange struct {
+	index int
+	root  crypto.Hash
+}
+
 type contractHeader struct {
 	// transaction is the signed transaction containing the most recent
 	// revision of the file contract.
@@ -184,6 +206,21 @@ func (c *SafeContract) Utility() modules.ContractUtility {
 	return c.header.Utility
 }
 
+// MerkleRoot returns the Merkle root of the sector at the given index of the
+// contract.
+func (c *SafeContract) MerkleRoot(index int) (crypto.Hash, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if index < 0 || index >= c.merkleRoots.len() {
+		return crypto.Hash{}, errors.New("sector index out of bounds")
+	}
+	roots, err := c.merkleRoots.merkleRootsFromIndexFromDisk(index, index+1)
+	if err != nil {
+		return crypto.Hash{}, err
+	}
+	return roots[0], nil
+}
+
 func (c *SafeContract) makeUpdateSetHeader(h contractHeader) writeaheadlog.Update {
 	id := c.header.ID()
 	return writeaheadlog.Update{
@@ -207,6 +244,26 @@ func (c *SafeContract) makeUpdateSetRoot(root crypto.Hash, index int) writeahead
 	}
 }
 
+func (c *SafeContract) makeUpdateTrimRoots(numRoots int) writeaheadlog.Update {
+	id := c.header.ID()
+	return writeaheadlog.Update{
+		Name: updateNameTrimRoots,
+		Instructions: encoding.Marshal(updateTrimRoots{
+			ID:       id,
+			NumRoots: numRoots,
+		}),
+	}
+}
+
+// makeRootChangesUpdates returns the updates that apply the root changes.
+func (c *SafeContract) makeRootChangesUpdates(changes sectorRootChanges) []writeaheadlog.Update {
+	updates := make([]writeaheadlog.Update, 0, len(changes.set)+1)
+	for _, change := range changes.set {
+		updates = append(updates, c.makeUpdateSetRoot(change.root, change.index))
+	}
+	return append(updates, c.makeUpdateTrimRoots(changes.numRoots))
+}
+
 func (c *SafeContract) applySetHeader(h contractHeader) error {
 	if build.DEBUG {
 		// read the existing header on disk, to make sure we aren't overwriting
@@ -235,6 +292,82 @@ func (c *SafeContract) applySetRoot(root crypto.Hash, index int) error {
 	return c.merkleRoots.insert(index, root)
 }
 
+func (c *SafeContract) applyTrimRoots(numRoots int) error {
+	return c.merkleRoots.trim(numRoots)
+}
+
+// applyRootChanges applies the root changes to the contract's sector roots.
+func (c *SafeContract) applyRootChanges(changes sectorRootChanges) error {
+	for _, change := range changes.set {
+		if err := c.applySetRoot(change.root, change.index); err != nil {
+			return err
+		}
+	}
+	return c.applyTrimRoots(changes.numRoots)
+}
+
+// managedSectorRootChanges returns the changes that the write actions make to
+// the sector roots of a contract with numSectors sectors. The roots of swapped
+// sectors are read from the contract. Roots that aren't stored locally are
+// assumed to be empty.
+func (c *SafeContract) managedSectorRootChanges(actions []modules.LoopWriteAction, numSectors uint64) (sectorRootChanges, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	changed := make(map[int]crypto.Hash)
+	root := func(index int) (crypto.Hash, error) {
+		if r, ok := changed[index]; ok {
+			return r, nil
+		}
+		if index >= c.merkleRoots.len() {
+			return crypto.Hash{}, nil
+		}
+		roots, err := c.merkleRoots.merkleRootsFromIndexFromDisk(index, index+1)
+		if err != nil {
+			return crypto.Hash{}, err
+		}
+		return roots[0], nil
+	}
+	n := int(numSectors)
+	for _, action := range actions {
+		switch action.Type {
+		case modules.WriteActionAppend:
+			changed[n] = crypto.MerkleRoot(action.Data)
+			n++
+
+		case modules.WriteActionTrim:
+			if action.A > uint64(n) {
+				return sectorRootChanges{}, errors.New("trim exceeds the number of sectors")
+			}
+			n -= int(action.A)
+
+		case modules.WriteActionSwap:
+			a, b := int(action.A), int(action.B)
+			rootA, err := root(a)
+			if err != nil {
+				return sectorRootChanges{}, err
+			}
+			rootB, err := root(b)
+			if err != nil {
+				return sectorRootChanges{}, err
+			}
+			changed[a], changed[b] = rootB, rootA
+
+		default:
+			return sectorRootChanges{}, errors.New("unsupported write action " + action.Type.String())
+		}
+	}
+	changes := sectorRootChanges{numRoots: n}
+	for index, r := range changed {
+		if index < n {
+			changes.set = append(changes.set, sectorRootChange{index: index, root: r})
+		}
+	}
+	sort.Slice(changes.set, func(i, j int) bool {
+		return changes.set[i].index < changes.set[j].index
+	})
+	return changes, nil
+}
+
 func (c *SafeContract) managedRecordUploadIntent(rev types.FileContractRevision, root crypto.Hash, storageCost, bandwidthCost types.Currency) (*writeaheadlog.Transaction, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -285,6 +418,58 @@ func (c *SafeContract) managedCommitUpload(t *writeaheadlog.Transaction, signedT
 	return nil
 }
 
+// managedRecordWriteIntent records the revision and the changes it makes to
+// the contract's sector roots in the WAL.
+func (c *SafeContract) managedRecordWriteIntent(rev types.FileContractRevision, changes sectorRootChanges, storageCost, bandwidthCost types.Currency) (*writeaheadlog.Transaction, error) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// construct new header
+	// NOTE: this header will not include the host signature
+	newHeader := c.header
+	newHeader.Transaction.FileContractRevisions = []types.FileContractRevision{rev}
+	newHeader.Transaction.TransactionSignatures = nil
+	newHeader.StorageSpending = newHeader.StorageSpending.Add(storageCost)
+	newHeader.UploadSpending = newHeader.UploadSpending.Add(bandwidthCost)
+
+	updates := append([]writeaheadlog.Update{c.makeUpdateSetHeader(newHeader)}, c.makeRootChangesUpdates(changes)...)
+	t, err := c.wal.NewTransaction(updates)
+	if err != nil {
+		return nil, err
+	}
+	if err := <-t.SignalSetupComplete(); err != nil {
+		return nil, err
+	}
+	c.unappliedTxns = append(c.unappliedTxns, t)
+	return t, nil
+}
+
+// managedCommitWrite applies the signed revision and the changes it makes to
+// the contract's sector roots.
+func (c *SafeContract) managedCommitWrite(t *writeaheadlog.Transaction, signedTxn types.Transaction, changes sectorRootChanges, storageCost, bandwidthCost types.Currency) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	// construct new header
+	newHeader := c.header
+	newHeader.Transaction = signedTxn
+	newHeader.StorageSpending = newHeader.StorageSpending.Add(storageCost)
+	newHeader.UploadSpending = newHeader.UploadSpending.Add(bandwidthCost)
+
+	if err := c.applySetHeader(newHeader); err != nil {
+		return err
+	}
+	if err := c.applyRootChanges(changes); err != nil {
+		return err
+	}
+	if err := c.headerFile.Sync(); err != nil {
+		return err
+	}
+	if err := t.SignalUpdatesApplied(); err != nil {
+		return err
+	}
+	c.unappliedTxns = nil
+	return nil
+}
+
 func (c *SafeContract) managedRecordDownloadIntent(rev types.FileContractRevision, bandwidthCost types.Currency) (*writeaheadlog.Transaction, error) {
 	c.mu.Lock()
 	defer c.mu.Unlock()
@@ -353,6 +538,14 @@ func (c *SafeContract) managedCommitTxns() error {
 				if err := c.applySetRoot(u.Root, u.Index); err != nil {
 					return err
 				}
+			case updateNameTrimRoots:
+				var u updateTrimRoots
+				if err := encoding.Unmarshal(update.Instructions, &u); err != nil {
+					return err
+				}
+				if err := c.applyTrimRoots(u.NumRoots); err != nil {
+					return err
+				}
 			}
 		}
 		if err := c.headerFile.Sync(); err != nil {
@@ -575,6 +768,12 @@ func (cs *ContractSet) loadSafeContract(filename string, walTxns []*writeaheadlo
 				return errors.AddContext(err, "unable to unmarshal the update root set during wal txn recovery")
 			}
 			id = u.ID
+		case updateNameTrimRoots:
+			var u updateTrimRoots
+			if err := encoding.Unmarshal(update.Instructions, &u); err != nil {
+				return errors.AddContext(err, "unable to unmarshal the update roots trim during wal txn recovery")
+			}
+			id = u.ID
 		}
 		if id == header.ID() {
 			unappliedTxns = append(unappliedTxns, t)
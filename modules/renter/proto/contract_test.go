@@ -6,6 +6,8 @@ import (
 	"reflect"
 	"testing"
 
+	"gitlab.com/NebulousLabs/fastrand"
+
 	"gitlab.com/NebulousLabs/Sia/build"
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
@@ -230,3 +232,148 @@ func TestContractIncompleteWrite(t *testing.T) {
 	cs.Return(sc)
 	cs.Close()
 }
+
+// TestContractMerkleRoot tests that MerkleRoot returns the roots of a
+// contract's sectors.
+func TestContractMerkleRoot(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	// create contract set with one contract
+	dir := build.TempDir(filepath.Join("proto", t.Name()))
+	cs, err := NewContractSet(dir, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := contractHeader{
+		Transaction: types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				NewRevisionNumber:    1,
+				NewValidProofOutputs: []types.SiacoinOutput{{}, {}},
+				UnlockConditions: types.UnlockConditions{
+					PublicKeys: []types.SiaPublicKey{{}, {}},
+				},
+			}},
+		},
+	}
+	roots := []crypto.Hash{{1}, {2}, {3}}
+	c, err := cs.managedInsertContract(header, roots)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Every root should be returned.
+	for i, expected := range roots {
+		root, err := cs.MerkleRoot(c.ID, i)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if root != expected {
+			t.Fatalf("root %v should be %v but was %v", i, expected, root)
+		}
+	}
+	// Indices out of bounds should fail.
+	if _, err := cs.MerkleRoot(c.ID, len(roots)); err == nil {
+		t.Fatal("expected out of bounds error")
+	}
+	if _, err := cs.MerkleRoot(c.ID, -1); err == nil {
+		t.Fatal("expected out of bounds error")
+	}
+	// Unknown contracts should fail.
+	if _, err := cs.MerkleRoot(types.FileContractID{1}, 0); err == nil {
+		t.Fatal("expected error for unknown contract")
+	}
+}
+
+// TestContractWriteRootChanges tests that the sector roots of a contract are
+// kept in sync with appends, swaps and trims.
+func TestContractWriteRootChanges(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	// create contract set with one contract
+	dir := build.TempDir(filepath.Join("proto", t.Name()))
+	cs, err := NewContractSet(dir, modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	header := contractHeader{
+		Transaction: types.Transaction{
+			FileContractRevisions: []types.FileContractRevision{{
+				NewRevisionNumber:    1,
+				NewValidProofOutputs: []types.SiacoinOutput{{}, {}},
+				UnlockConditions: types.UnlockConditions{
+					PublicKeys: []types.SiaPublicKey{{}, {}},
+				},
+			}},
+		},
+	}
+	c, err := cs.managedInsertContract(header, []crypto.Hash{{1}, {2}, {3}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	sc, ok := cs.Acquire(c.ID)
+	if !ok {
+		t.Fatal("failed to acquire contract")
+	}
+	defer cs.Return(sc)
+
+	// Swap the first and last sector, trim two sectors and append a new one.
+	data := fastrand.Bytes(int(modules.SectorSize))
+	actions := []modules.LoopWriteAction{
+		{Type: modules.WriteActionSwap, A: 0, B: 2},
+		{Type: modules.WriteActionTrim, A: 2},
+		{Type: modules.WriteActionAppend, Data: data},
+	}
+	changes, err := sc.managedSectorRootChanges(actions, 3)
+	if err != nil {
+		t.Fatal(err)
+	}
+	expected := []crypto.Hash{{3}, crypto.MerkleRoot(data)}
+
+	// Record the intent and apply it like a recovery would.
+	rev := sc.header.LastRevision()
+	rev.NewRevisionNumber++
+	if _, err := sc.managedRecordWriteIntent(rev, changes, types.ZeroCurrency, types.ZeroCurrency); err != nil {
+		t.Fatal(err)
+	}
+	if err := sc.managedCommitTxns(); err != nil {
+		t.Fatal(err)
+	}
+	roots, err := sc.merkleRoots.merkleRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roots, expected) {
+		t.Fatal("wrong roots after recovery", roots, expected)
+	}
+
+	// Swap the remaining sectors and commit the write.
+	actions = []modules.LoopWriteAction{{Type: modules.WriteActionSwap, A: 0, B: 1}}
+	changes, err = sc.managedSectorRootChanges(actions, 2)
+	if err != nil {
+		t.Fatal(err)
+	}
+	rev.NewRevisionNumber++
+	walTxn, err := sc.managedRecordWriteIntent(rev, changes, types.ZeroCurrency, types.ZeroCurrency)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := types.Transaction{FileContractRevisions: []types.FileContractRevision{rev}}
+	if err := sc.managedCommitWrite(walTxn, txn, changes, types.ZeroCurrency, types.ZeroCurrency); err != nil {
+		t.Fatal(err)
+	}
+	roots, err = sc.merkleRoots.merkleRoots()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(roots, []crypto.Hash{expected[1], expected[0]}) {
+		t.Fatal("wrong roots after commit", roots)
+	}
+
+	// Trimming more sectors than the contract has should fail.
+	actions = []modules.LoopWriteAction{{Type: modules.WriteActionTrim, A: 3}}
+	if _, err := sc.managedSectorRootChanges(actions, 2); err == nil {
+		t.Fatal("expected trim to fail")
+	}
+}
@@ -27,6 +27,9 @@ type ContractSet struct {
 	mu        sync.Mutex
 	rl        *ratelimit.RateLimit
 	wal       *writeaheadlog.WAL
+
+	// staticTransports picks the transport used to dial hosts.
+	staticTransports *transportCache
 }
 
 // Acquire looks up the contract for the specified host key and locks it before
@@ -103,6 +106,19 @@ func (cs *ContractSet) InsertContract(rc modules.RecoverableContract, revTxn typ
 	}, roots)
 }
 
+// MerkleRoot returns the Merkle root of the sector at the given index of a
+// contract. The contract is not acquired, so MerkleRoot can be called while
+// another thread is revising the contract.
+func (cs *ContractSet) MerkleRoot(id types.FileContractID, index int) (crypto.Hash, error) {
+	cs.mu.Lock()
+	safeContract, ok := cs.contracts[id]
+	cs.mu.Unlock()
+	if !ok {
+		return crypto.Hash{}, errors.New("no contract with that id")
+	}
+	return safeContract.MerkleRoot(index)
+}
+
 // Len returns the number of contracts in the set.
 func (cs *ContractSet) Len() int {
 	cs.mu.Lock()
@@ -207,6 +223,8 @@ func NewContractSet(dir string, deps modules.Dependencies) (*ContractSet, error)
 		deps: deps,
 		dir:  dir,
 		wal:  wal,
+
+		staticTransports: newTransportCache(),
 	}
 	// Set the initial rate limit to 'unlimited' bandwidth with 4kib packets.
 	cs.rl = ratelimit.NewRateLimit(0, 0, 0)
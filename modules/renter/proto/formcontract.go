@@ -25,8 +25,7 @@ func (cs *ContractSet) FormContract(params ContractParams, txnBuilder transactio
 	allowance, host, funding, startHeight, endHeight, refundAddress := params.Allowance, params.Host, params.Funding, params.StartHeight, params.EndHeight, params.RefundAddress
 
 	// Calculate the anticipated transaction fee.
-	_, maxFee := tpool.FeeEstimation()
-	txnFee := maxFee.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	txnFee := contractTxnFee(tpool, allowance)
 
 	// Calculate the payouts for the renter, host, and whole contract.
 	period := endHeight - startHeight
@@ -302,6 +302,22 @@ func (mr *merkleRoots) push(root crypto.Hash) error {
 	return nil
 }
 
+// trim removes roots from the end of the contract until numRoots roots are
+// left. Like delete, trim is idempotent.
+func (mr *merkleRoots) trim(numRoots int) error {
+	for mr.numMerkleRoots > numRoots {
+		index := mr.numMerkleRoots - 1
+		lastRoot, truncateSize, err := mr.prepareDelete(index)
+		if err != nil {
+			return err
+		}
+		if err := mr.delete(index, lastRoot, truncateSize); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 // root returns the root of the merkle roots.
 func (mr *merkleRoots) root() crypto.Hash {
 	tree := crypto.NewTree()
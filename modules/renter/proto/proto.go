@@ -28,6 +28,7 @@ type (
 	transactionPool interface {
 		AcceptTransactionSet([]types.Transaction) error
 		FeeEstimation() (min types.Currency, max types.Currency)
+		FeeEstimationTarget(target types.BlockHeight, confidence float64) modules.TargetFeeEstimate
 	}
 
 	hostDB interface {
@@ -64,3 +65,15 @@ func IsRevisionMismatch(err error) bool {
 	_, ok := err.(*revisionNumberMismatchError)
 	return ok
 }
+
+// contractTxnFee returns the anticipated fee of the transaction set that forms
+// or renews a contract. The fee is estimated for the fee target of the
+// allowance if it is set, and is the maximum estimate otherwise.
+func contractTxnFee(tpool transactionPool, allowance modules.Allowance) types.Currency {
+	if allowance.FeeTarget != 0 {
+		est := tpool.FeeEstimationTarget(allowance.FeeTarget, modules.DefaultFeeConfidence)
+		return est.FeePerByte.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	}
+	_, maxFee := tpool.FeeEstimation()
+	return maxFee.Mul64(modules.EstimatedFileContractTransactionSetSize)
+}
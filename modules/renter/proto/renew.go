@@ -61,8 +61,7 @@ func (cs *ContractSet) Renew(oldContract *SafeContract, params ContractParams, t
 	}
 
 	// Calculate the anticipated transaction fee.
-	_, maxFee := tpool.FeeEstimation()
-	txnFee := maxFee.Mul64(modules.EstimatedFileContractTransactionSetSize)
+	txnFee := contractTxnFee(tpool, allowance)
 
 	// Calculate the payouts for the renter, host, and whole contract.
 	period := endHeight - startHeight
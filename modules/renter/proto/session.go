@@ -266,12 +266,20 @@ func (s *Session) write(sc *SafeContract, actions []modules.LoopWriteAction) (_
 		req.NewMissedProofValues[i] = o.Value
 	}
 
+	// compute the changes to the contract's sector roots so that they stay in
+	// sync with the host and the sectors can be audited later.
+	//
+	// TODO: update this for non-local root storage
+	numSectors := contract.LastRevision().NewFileSize / modules.SectorSize
+	rootChanges, err := sc.managedSectorRootChanges(actions, numSectors)
+	if err != nil {
+		return modules.RenterContract{}, err
+	}
+
 	// record the change we are about to make to the contract. If we lose power
 	// mid-revision, this allows us to restore either the pre-revision or
 	// post-revision contract.
-	//
-	// TODO: update this for non-local root storage
-	walTxn, err := sc.managedRecordUploadIntent(rev, crypto.Hash{}, storagePrice, bandwidthPrice)
+	walTxn, err := sc.managedRecordWriteIntent(rev, rootChanges, storagePrice, bandwidthPrice)
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
@@ -305,7 +313,6 @@ func (s *Session) write(sc *SafeContract, actions []modules.LoopWriteAction) (_
 		return modules.RenterContract{}, err
 	}
 	// verify the proof, first by verifying the old Merkle root...
-	numSectors := contract.LastRevision().NewFileSize / modules.SectorSize
 	proofRanges := calculateProofRanges(actions, numSectors)
 	proofHashes := merkleResp.OldSubtreeHashes
 	leafHashes := merkleResp.OldLeafHashes
@@ -369,7 +376,7 @@ func (s *Session) write(sc *SafeContract, actions []modules.LoopWriteAction) (_
 	// update contract
 	//
 	// TODO: unnecessary?
-	err = sc.managedCommitUpload(walTxn, txn, crypto.Hash{}, storagePrice, bandwidthPrice)
+	err = sc.managedCommitWrite(walTxn, txn, rootChanges, storagePrice, bandwidthPrice)
 	if err != nil {
 		return modules.RenterContract{}, err
 	}
@@ -512,20 +519,22 @@ func (s *Session) Read(w io.Writer, req modules.LoopReadRequest, cancel <-chan s
 	for _, sec := range req.Sections {
 		var resp modules.LoopReadResponse
 		err = s.readResponse(&resp, modules.RPCMinLen+uint64(sec.Length))
-		if err != nil {
+		if rpcErr, ok := err.(*modules.RPCError); ok && rpcErr.Type == modules.RPCErrorSectorNotFound {
+			return modules.RenterContract{}, errors.Compose(ErrSectorNotFound, err)
+		} else if err != nil {
 			return modules.RenterContract{}, err
 		}
 		// The host may have sent data, a signature, or both. If they sent data,
 		// validate it.
 		if len(resp.Data) > 0 {
 			if len(resp.Data) != int(sec.Length) {
-				return modules.RenterContract{}, errors.New("host did not send enough sector data")
+				return modules.RenterContract{}, errors.AddContext(ErrBadSectorData, "host did not send enough sector data")
 			}
 			if req.MerkleProof {
 				proofStart := int(sec.Offset) / crypto.SegmentSize
 				proofEnd := int(sec.Offset+sec.Length) / crypto.SegmentSize
 				if !crypto.VerifyRangeProof(resp.Data, resp.MerkleProof, proofStart, proofEnd, sec.MerkleRoot) {
-					return modules.RenterContract{}, errors.New("host provided incorrect sector data or Merkle proof")
+					return modules.RenterContract{}, ErrBadSectorData
 				}
 			}
 			// write sector data
@@ -842,10 +851,7 @@ func (cs *ContractSet) managedNewSession(host modules.HostDBEntry, currentHeight
 		}
 	}()
 
-	c, err := (&net.Dialer{
-		Cancel:  cancel,
-		Timeout: 45 * time.Second, // TODO: Constant
-	}).Dial("tcp", string(host.NetAddress))
+	c, err := cs.staticTransports.managedDial(host, cancel, 45*time.Second) // TODO: Constant
 	if err != nil {
 		return nil, errors.AddContext(err, "unsuccessful dial when creating a new session")
 	}
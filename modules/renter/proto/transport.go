@@ -0,0 +1,78 @@
+package proto
+
+import (
+	"net"
+	"sync"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+// transportCache remembers the hosts that were only reachable over WebSocket,
+// so that they are dialed over WebSocket first instead of waiting for the raw
+// TCP dial to time out every time.
+type transportCache struct {
+	webSocketHosts map[string]struct{}
+	mu             sync.Mutex
+}
+
+// newTransportCache creates an empty transportCache.
+func newTransportCache() *transportCache {
+	return &transportCache{
+		webSocketHosts: make(map[string]struct{}),
+	}
+}
+
+// managedPreferWebSocket returns true if the host should be dialed over
+// WebSocket first.
+func (tc *transportCache) managedPreferWebSocket(host modules.HostDBEntry) bool {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	_, exists := tc.webSocketHosts[host.PublicKey.String()]
+	return exists && host.WebSocketURL != ""
+}
+
+// managedSetPreferWebSocket updates whether the host should be dialed over
+// WebSocket first.
+func (tc *transportCache) managedSetPreferWebSocket(host modules.HostDBEntry, prefer bool) {
+	tc.mu.Lock()
+	defer tc.mu.Unlock()
+	if prefer {
+		tc.webSocketHosts[host.PublicKey.String()] = struct{}{}
+	} else {
+		delete(tc.webSocketHosts, host.PublicKey.String())
+	}
+}
+
+// managedDial establishes a connection to the host, picking the transport.
+// The host is dialed over raw TCP on its announced addresses first. If none of
+// them are reachable and the host advertises a WebSocket endpoint, the
+// connection is established over WebSocket instead. Hosts that were only
+// reachable over WebSocket are dialed over WebSocket first the next time. The
+// session handshake authenticates the host and encrypts the session over
+// either transport.
+func (tc *transportCache) managedDial(host modules.HostDBEntry, cancel <-chan struct{}, timeout time.Duration) (net.Conn, error) {
+	dialer := &net.Dialer{
+		Cancel:  cancel,
+		Timeout: timeout,
+	}
+	if tc.managedPreferWebSocket(host) {
+		conn, err := modules.DialWebSocket(dialer, host.WebSocketURL)
+		if err == nil {
+			return conn, nil
+		}
+		tc.managedSetPreferWebSocket(host, false)
+	}
+	conn, _, err := modules.DialAddresses(dialer, host.Addresses())
+	if err == nil || host.WebSocketURL == "" {
+		return conn, err
+	}
+	conn, wsErr := modules.DialWebSocket(dialer, host.WebSocketURL)
+	if wsErr != nil {
+		return nil, errors.Compose(err, errors.AddContext(wsErr, "unable to dial the WebSocket endpoint"))
+	}
+	tc.managedSetPreferWebSocket(host, true)
+	return conn, nil
+}
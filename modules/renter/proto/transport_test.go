@@ -0,0 +1,72 @@
+package proto
+
+import (
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"golang.org/x/net/websocket"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestTransportCacheDial checks that hosts are dialed over WebSocket if they
+// can't be reached over raw TCP, and that the transport is remembered.
+func TestTransportCacheDial(t *testing.T) {
+	t.Parallel()
+	srv := httptest.NewServer(websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			io.Copy(ws, ws)
+		},
+	})
+	defer srv.Close()
+
+	// Get an address that refuses connections.
+	l, err := net.Listen("tcp", "127.0.0.1:0")
+	if err != nil {
+		t.Fatal(err)
+	}
+	closedAddr := modules.NetAddress(l.Addr().String())
+	l.Close()
+
+	var host modules.HostDBEntry
+	host.NetAddress = closedAddr
+	host.PublicKey = types.SiaPublicKey{Algorithm: types.SignatureEd25519, Key: []byte{1}}
+	tc := newTransportCache()
+
+	// Without a WebSocket endpoint the dial fails.
+	_, err = tc.managedDial(host, nil, time.Minute)
+	if err == nil {
+		t.Fatal("expected the dial to fail")
+	}
+	if tc.managedPreferWebSocket(host) {
+		t.Fatal("host shouldn't prefer WebSocket")
+	}
+
+	// With a WebSocket endpoint the dial falls back to it.
+	host.WebSocketURL = "ws" + strings.TrimPrefix(srv.URL, "http") + modules.WebSocketRPCPath
+	conn, err := tc.managedDial(host, nil, time.Minute)
+	if err != nil {
+		t.Fatal(err)
+	}
+	conn.Close()
+	if !tc.managedPreferWebSocket(host) {
+		t.Fatal("host should prefer WebSocket")
+	}
+
+	// If the WebSocket endpoint goes away, raw TCP is tried again.
+	host.WebSocketURL = "ws://" + string(closedAddr) + modules.WebSocketRPCPath
+	_, err = tc.managedDial(host, nil, time.Minute)
+	if err == nil {
+		t.Fatal("expected the dial to fail")
+	}
+	if tc.managedPreferWebSocket(host) {
+		t.Fatal("host shouldn't prefer WebSocket anymore")
+	}
+}
@@ -35,6 +35,7 @@ import (
 	"gitlab.com/NebulousLabs/writeaheadlog"
 
 	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/modules/renter/contractor"
 	"gitlab.com/NebulousLabs/Sia/modules/renter/filesystem"
@@ -94,6 +95,10 @@ type hostContractor interface {
 	// began.
 	CurrentPeriod() types.BlockHeight
 
+	// FormContract forms a contract with the specified host using the
+	// provided funds.
+	FormContract(types.SiaPublicKey, types.Currency) (modules.RenterContract, error)
+
 	// InitRecoveryScan starts scanning the whole blockchain for recoverable
 	// contracts within a separate thread.
 	InitRecoveryScan() error
@@ -102,6 +107,21 @@ type hostContractor interface {
 	// billing period.
 	PeriodSpending() (modules.ContractorSpending, error)
 
+	// PinContract pins a contract, preventing contract maintenance from
+	// marking it !GoodForUpload or !GoodForRenew.
+	PinContract(types.FileContractID) error
+
+	// PinnedContract returns whether a contract is pinned.
+	PinnedContract(types.FileContractID) bool
+
+	// MerkleRoot returns the Merkle root of the sector at the given index of
+	// a contract.
+	MerkleRoot(types.FileContractID, int) (crypto.Hash, error)
+
+	// MigratingContract returns the migration of a contract if the contract
+	// was marked for migration.
+	MigratingContract(types.FileContractID) (modules.ContractMigration, bool)
+
 	// OldContracts returns the oldContracts of the renter's hostContractor.
 	OldContracts() []modules.RenterContract
 
@@ -129,9 +149,16 @@ type hostContractor interface {
 	// contracts is in progress and if it is, the current progress of the scan.
 	RecoveryScanStatus() (bool, types.BlockHeight)
 
+	// RefreshContract adds funds to a contract by renewing it without
+	// changing its end height.
+	RefreshContract(types.FileContractID, types.Currency) (modules.RenterContract, error)
+
 	// RefreshedContract checks if the contract was previously refreshed
 	RefreshedContract(fcid types.FileContractID) bool
 
+	// RenewContract renews a contract before it enters the renew window.
+	RenewContract(types.FileContractID, types.Currency) (modules.RenterContract, error)
+
 	// RateLimits Gets the bandwidth limits for connections created by the
 	// contractor and its submodules.
 	RateLimits() (readBPS int64, writeBPS int64, packetSize uint64)
@@ -143,6 +170,9 @@ type hostContractor interface {
 	// Synced returns a channel that is closed when the contractor is fully
 	// synced with the peer-to-peer network.
 	Synced() <-chan struct{}
+
+	// UnpinContract removes the pin from a contract.
+	UnpinContract(types.FileContractID) error
 }
 
 type renterFuseManager interface {
@@ -198,6 +228,10 @@ type Renter struct {
 	bubbleUpdates   map[string]bubbleStatus
 	bubbleUpdatesMu sync.Mutex
 
+	// pendingAudits contains the sectors which failed their last audit. They
+	// are audited again in the next audit round.
+	pendingAudits map[types.FileContractID]pendingAudit
+
 	// Utilities.
 	cs                    modules.ConsensusSet
 	deps                  modules.Dependencies
@@ -533,6 +567,9 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	if s.MaxDownloadSpeed < 0 || s.MaxUploadSpeed < 0 {
 		return errors.New("bandwidth limits cannot be negative")
 	}
+	if s.AuditInterval < 0 {
+		return errors.New("audit interval cannot be negative")
+	}
 
 	// Set allowance.
 	err := r.hostContractor.SetAllowance(s.Allowance)
@@ -552,6 +589,8 @@ func (r *Renter) SetSettings(s modules.RenterSettings) error {
 	id := r.mu.Lock()
 	r.persist.MaxDownloadSpeed = s.MaxDownloadSpeed
 	r.persist.MaxUploadSpeed = s.MaxUploadSpeed
+	r.persist.AuditInterval = s.AuditInterval
+	r.persist.MaxAuditPrice = s.MaxAuditPrice
 	err = r.saveSync()
 	r.mu.Unlock(id)
 	if err != nil {
@@ -617,24 +656,45 @@ func (r *Renter) Filter() (modules.FilterMode, map[string]types.SiaPublicKey, er
 	return fm, hosts, nil
 }
 
+// FilterRules returns the renter's hostdb's filterMode and filter rules.
+func (r *Renter) FilterRules() (modules.FilterMode, modules.HostDBFilterRules, error) {
+	if err := r.tg.Add(); err != nil {
+		return modules.HostDBFilterError, modules.HostDBFilterRules{}, err
+	}
+	defer r.tg.Done()
+	fm, rules, err := r.hostDB.FilterRules()
+	if err != nil {
+		return fm, rules, errors.AddContext(err, "error getting hostdb filter rules:")
+	}
+	return fm, rules, nil
+}
+
 // SetFilterMode sets the renter's hostdb filter mode
 func (r *Renter) SetFilterMode(lm modules.FilterMode, hosts []types.SiaPublicKey) error {
+	return r.SetFilterRules(lm, modules.HostDBFilterRules{Hosts: hosts})
+}
+
+// SetFilterRules sets the renter's hostdb filter mode using the provided
+// rules.
+func (r *Renter) SetFilterRules(lm modules.FilterMode, rules modules.HostDBFilterRules) error {
 	if err := r.tg.Add(); err != nil {
 		return err
 	}
 	defer r.tg.Done()
-	// Check to see how many hosts are needed for the allowance
+	// Check to see how many hosts are needed for the allowance. This can only
+	// be checked if the whitelist consists of keys only.
 	settings, err := r.Settings()
 	if err != nil {
 		return errors.AddContext(err, "error getting renter settings:")
 	}
 	minHosts := settings.Allowance.Hosts
-	if len(hosts) < int(minHosts) && lm == modules.HostDBActiveWhitelist {
-		r.log.Printf("WARN: There are fewer whitelisted hosts than the allowance requires.  Have %v whitelisted hosts, need %v to support allowance\n", len(hosts), minHosts)
+	keysOnly := len(rules.NetAddresses) == 0 && len(rules.Subnets) == 0
+	if keysOnly && len(rules.Hosts) < int(minHosts) && lm == modules.HostDBActiveWhitelist {
+		r.log.Printf("WARN: There are fewer whitelisted hosts than the allowance requires.  Have %v whitelisted hosts, need %v to support allowance\n", len(rules.Hosts), minHosts)
 	}
 
 	// Set list mode filter for the hostdb
-	if err := r.hostDB.SetFilterMode(lm, hosts); err != nil {
+	if err := r.hostDB.SetFilterRules(lm, rules); err != nil {
 		return err
 	}
 
@@ -678,6 +738,45 @@ func (r *Renter) CancelContract(id types.FileContractID) error {
 // Contracts returns an array of host contractor's staticContracts
 func (r *Renter) Contracts() []modules.RenterContract { return r.hostContractor.Contracts() }
 
+// FormContract forms a contract with the specified host using the provided
+// funds.
+func (r *Renter) FormContract(pk types.SiaPublicKey, funds types.Currency) (modules.RenterContract, error) {
+	return r.hostContractor.FormContract(pk, funds)
+}
+
+// PinContract pins a contract, preventing contract maintenance from marking it
+// !GoodForUpload or !GoodForRenew.
+func (r *Renter) PinContract(id types.FileContractID) error {
+	return r.hostContractor.PinContract(id)
+}
+
+// PinnedContract returns whether a contract is pinned.
+func (r *Renter) PinnedContract(id types.FileContractID) bool {
+	return r.hostContractor.PinnedContract(id)
+}
+
+// MigratingContract returns the migration of a contract if the contract was
+// marked for migration.
+func (r *Renter) MigratingContract(id types.FileContractID) (modules.ContractMigration, bool) {
+	return r.hostContractor.MigratingContract(id)
+}
+
+// RefreshContract adds funds to a contract by renewing it without changing its
+// end height.
+func (r *Renter) RefreshContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error) {
+	return r.hostContractor.RefreshContract(id, funds)
+}
+
+// RenewContract renews a contract before it enters the renew window.
+func (r *Renter) RenewContract(id types.FileContractID, funds types.Currency) (modules.RenterContract, error) {
+	return r.hostContractor.RenewContract(id, funds)
+}
+
+// UnpinContract removes the pin from a contract.
+func (r *Renter) UnpinContract(id types.FileContractID) error {
+	return r.hostContractor.UnpinContract(id)
+}
+
 // CurrentPeriod returns the host contractor's current period
 func (r *Renter) CurrentPeriod() types.BlockHeight { return r.hostContractor.CurrentPeriod() }
 
@@ -743,6 +842,10 @@ func (r *Renter) Settings() (modules.RenterSettings, error) {
 		return modules.RenterSettings{}, errors.AddContext(err, "error getting IPViolationsCheck:")
 	}
 	paused, endTime := r.uploadHeap.managedPauseStatus()
+	id := r.mu.RLock()
+	auditInterval := r.persist.AuditInterval
+	maxAuditPrice := r.persist.MaxAuditPrice
+	r.mu.RUnlock(id)
 	return modules.RenterSettings{
 		Allowance:        r.hostContractor.Allowance(),
 		IPViolationCheck: enabled,
@@ -752,6 +855,8 @@ func (r *Renter) Settings() (modules.RenterSettings, error) {
 			Paused:       paused,
 			PauseEndTime: endTime,
 		},
+		AuditInterval: auditInterval,
+		MaxAuditPrice: maxAuditPrice,
 	}, nil
 }
 
@@ -834,6 +939,7 @@ func renterBlockingStartup(g modules.Gateway, cs modules.ConsensusSet, tpool mod
 		},
 
 		bubbleUpdates:   make(map[string]bubbleStatus),
+		pendingAudits:   make(map[types.FileContractID]pendingAudit),
 		downloadHistory: make(map[modules.DownloadID]*download),
 
 		cs:                    cs,
@@ -904,6 +1010,8 @@ func renterAsyncStartup(r *Renter, cs modules.ConsensusSet) error {
 	}
 	// Spin up the snapshot synchronization thread.
 	go r.threadedSynchronizeSnapshots()
+	// Spin up the audit thread.
+	go r.threadedAuditLoop()
 	return nil
 }
 
@@ -809,6 +809,79 @@ func (sf *SiaFile) Redundancy(offlineMap map[string]bool, goodForRenewMap map[st
 	return
 }
 
+// RemovePieces removes all pieces with one of the given Merkle roots which are
+// stored on the host with the given public key. It returns the number of
+// pieces that were removed. Pieces of partial chunks are not removed since they
+// belong to the combined chunks of the partials siafile.
+func (sf *SiaFile) RemovePieces(pk types.SiaPublicKey, merkleRoots map[crypto.Hash]struct{}) (int, error) {
+	sf.mu.Lock()
+	defer sf.mu.Unlock()
+	// If the file was deleted we can't remove pieces since it would write the
+	// file to disk again.
+	if sf.deleted {
+		return 0, errors.AddContext(ErrDeleted, "can't remove pieces from deleted file")
+	}
+	// Get the index of the host in the public key table. If we don't know
+	// the host, there is nothing to remove.
+	tableIndex := -1
+	for i, hpk := range sf.pubKeyTable {
+		if hpk.PublicKey.Equals(pk) {
+			tableIndex = i
+			break
+		}
+	}
+	if tableIndex == -1 {
+		return 0, nil
+	}
+
+	// Update cache.
+	defer sf.uploadProgressAndBytes()
+
+	// Remove the matching pieces from all the chunks.
+	var removed int
+	updates, err := sf.iterateChunks(func(chunk *chunk) (bool, error) {
+		if sf.isIncompletePartialChunk(uint64(chunk.Index)) {
+			return false, nil
+		}
+		if _, ok := sf.isIncludedPartialChunk(uint64(chunk.Index)); ok {
+			return false, nil
+		}
+		modified := false
+		for pieceIndex, pieceSet := range chunk.Pieces {
+			var pieces []piece
+			for _, p := range pieceSet {
+				if _, lost := merkleRoots[p.MerkleRoot]; lost && p.HostTableOffset == uint32(tableIndex) {
+					removed++
+					modified = true
+					continue
+				}
+				pieces = append(pieces, p)
+			}
+			chunk.Pieces[pieceIndex] = pieces
+		}
+		return modified, nil
+	})
+	if err != nil {
+		return 0, err
+	}
+	if removed == 0 {
+		return 0, nil
+	}
+
+	// Update the ChangeTime and ModTime.
+	sf.staticMetadata.ChangeTime = time.Now()
+	sf.staticMetadata.ModTime = sf.staticMetadata.ChangeTime
+	metadataUpdates, err := sf.saveMetadataUpdates()
+	if err != nil {
+		return 0, err
+	}
+	updates = append(updates, metadataUpdates...)
+	if err := sf.createAndApplyTransaction(updates...); err != nil {
+		return 0, err
+	}
+	return removed, nil
+}
+
 // SetAllStuck sets the Stuck field of all chunks to stuck.
 func (sf *SiaFile) SetAllStuck(stuck bool) (err error) {
 	sf.mu.Lock()
@@ -656,6 +656,93 @@ func TestPruneHosts(t *testing.T) {
 	}
 }
 
+// TestRemovePieces tests the RemovePieces method of the SiaFile.
+func TestRemovePieces(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a siafile without partial chunk.
+	siaFilePath, _, source, rc, sk, fileSize, numChunks, fileMode := newTestFileParams(1, false)
+	sf, _, _ := customTestFileAndWAL(siaFilePath, source, rc, sk, fileSize, numChunks, fileMode)
+
+	// Add 2 random hostkeys to the file.
+	sf.addRandomHostKeys(2)
+	updates, err := sf.saveHeaderUpdates()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := sf.createAndApplyTransaction(updates...); err != nil {
+		t.Fatal(err)
+	}
+	hpks := sf.HostPublicKeys()
+
+	// Add one piece for every host to every pieceSet. The first piece of the
+	// first host uses a known root.
+	var lostRoot crypto.Hash
+	fastrand.Read(lostRoot[:])
+	for _, hk := range hpks {
+		err := sf.iterateChunksReadonly(func(chunk chunk) error {
+			for pieceIndex := range chunk.Pieces {
+				root := crypto.Hash{}
+				if chunk.Index == 0 && pieceIndex == 0 {
+					root = lostRoot
+				}
+				if err := sf.AddPiece(hk, uint64(chunk.Index), uint64(pieceIndex), root); err != nil {
+					t.Fatal(err)
+				}
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// Removing the pieces of an unknown host shouldn't remove anything.
+	lostRoots := map[crypto.Hash]struct{}{lostRoot: {}}
+	removed, err := sf.RemovePieces(types.SiaPublicKey{}, lostRoots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 0 {
+		t.Fatalf("Expected 0 removed pieces but was %v", removed)
+	}
+	// Remove the piece with the lost root of the first host.
+	removed, err = sf.RemovePieces(hpks[0], lostRoots)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if removed != 1 {
+		t.Fatalf("Expected 1 removed piece but was %v", removed)
+	}
+	// The first pieceSet of the first chunk should only contain the piece of
+	// the second host. All the other pieceSets should be untouched.
+	err = sf.iterateChunksReadonly(func(chunk chunk) error {
+		for pieceIndex, pieceSet := range chunk.Pieces {
+			expected := 2
+			if chunk.Index == 0 && pieceIndex == 0 {
+				expected = 1
+			}
+			if len(pieceSet) != expected {
+				t.Fatalf("Expected %v pieces in the set but was %v", expected, len(pieceSet))
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	pieces, err := sf.Pieces(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !pieces[0][0].HostPubKey.Equals(hpks[1]) {
+		t.Fatal("Wrong piece was removed")
+	}
+}
+
 // TestNumPieces tests the chunk's numPieces method.
 func TestNumPieces(t *testing.T) {
 	// create a random chunk.
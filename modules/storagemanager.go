@@ -1,6 +1,8 @@
 package modules
 
 import (
+	"time"
+
 	"gitlab.com/NebulousLabs/Sia/crypto"
 )
 
@@ -34,12 +36,54 @@ type (
 		SuccessfulReads  uint64 `json:"successfulreads"`
 		SuccessfulWrites uint64 `json:"successfulwrites"`
 
+		// CorruptSectors is the number of sectors in the storage folder whose
+		// data didn't match their Merkle root when they were last scrubbed.
+		CorruptSectors uint64 `json:"corruptsectors"`
+
 		// Certain operations on a storage folder can take a long time (Add,
-		// Remove, and Resize). The fields below indicate the progress of any
-		// long running operations that might be under way in the storage
-		// folder. Progress is always reported in bytes.
+		// Remove, Resize, and migrating sectors out of the folder). The fields
+		// below indicate the progress of any long running operations that
+		// might be under way in the storage folder. Progress is always
+		// reported in bytes.
 		ProgressNumerator   uint64
 		ProgressDenominator uint64
+
+		// MigrationError is the error of a failed sector migration out of the
+		// storage folder. The migration can be retried by starting it again.
+		MigrationError string `json:"migrationerror,omitempty"`
+	}
+
+	// SectorCacheStatus reports the size and the efficiency of the storage
+	// manager's cache of recently read sectors. Hits and Misses are counted
+	// since startup.
+	SectorCacheStatus struct {
+		Capacity uint64 `json:"capacity"` // bytes
+		Size     uint64 `json:"size"`     // bytes
+		Hits     uint64 `json:"hits"`
+		Misses   uint64 `json:"misses"`
+	}
+
+	// StorageScrubStatus reports the progress of the background scrubber
+	// which periodically reads every sector stored by the storage manager and
+	// verifies it against its Merkle root.
+	StorageScrubStatus struct {
+		// Running indicates whether a scrub is currently in progress.
+		// ScrubbedSectors and TotalSectors report the progress of the
+		// current scrub, or of the last scrub if none is running.
+		Running         bool   `json:"running"`
+		ScrubbedSectors uint64 `json:"scrubbedsectors"`
+		TotalSectors    uint64 `json:"totalsectors"`
+
+		// CorruptSectors is the number of sectors that are currently known to
+		// be corrupt. ReadErrors is the number of sectors that couldn't be
+		// read during the current or last scrub.
+		CorruptSectors uint64 `json:"corruptsectors"`
+		ReadErrors     uint64 `json:"readerrors"`
+
+		// CompletedScrubs is the number of full scrubs since startup.
+		CompletedScrubs uint64    `json:"completedscrubs"`
+		LastScrubStart  time.Time `json:"lastscrubstart"`
+		LastScrubEnd    time.Time `json:"lastscrubend"`
 	}
 
 	// A StorageManager is responsible for managing storage folders and
@@ -74,6 +118,10 @@ type (
 		// The storage manager needs to be able to shut down.
 		Close() error
 
+		// CorruptSectors returns the subset of the provided sector roots that
+		// were found to be corrupt by the background scrubber.
+		CorruptSectors(sectorRoots []crypto.Hash) []crypto.Hash
+
 		// DeleteSector deletes a sector, meaning that the manager will be
 		// unable to upload that sector and be unable to provide a storage
 		// proof on that sector. DeleteSector is for removing the data
@@ -82,10 +130,20 @@ type (
 		// requests to remove data.
 		DeleteSector(sectorRoot crypto.Hash) error
 
+		// MigrateSectors moves numSectors sectors from the source storage
+		// folder to the destination storage folder in the background, or all
+		// of the sectors in the source if numSectors is 0. The migration is
+		// resumed after an unclean or clean shutdown.
+		MigrateSectors(source, destination uint16, numSectors uint64) error
+
 		// ReadSector will read a sector from the storage manager, returning the
 		// bytes that match the input sector root.
 		ReadSector(sectorRoot crypto.Hash) ([]byte, error)
 
+		// RebalanceStorageFolders moves sectors between the storage folders in
+		// the background until all of them have about the same utilization.
+		RebalanceStorageFolders() error
+
 		// RemoveSector will remove a sector from the storage manager. The
 		// height at which the sector expires should be provided, so that the
 		// auto-expiry information for that sector can be properly updated.
@@ -120,6 +178,18 @@ type (
 		// that data will be lost.
 		ResizeStorageFolder(index uint16, newSize uint64, force bool) error
 
+		// ScrubStatus returns the progress of the background scrubber.
+		ScrubStatus() StorageScrubStatus
+
+		// SectorCacheStatus returns the size and the hit and miss counters of
+		// the sector cache.
+		SectorCacheStatus() SectorCacheStatus
+
+		// SetSectorCache sets the size of the sector cache in bytes and the
+		// directory that backs it. If the directory is empty, the cached
+		// sectors are kept in memory. A size of 0 disables the cache.
+		SetSectorCache(size uint64, dir string) error
+
 		// StorageFolders will return a list of storage folders tracked by the
 		// manager.
 		StorageFolders() []StorageFolderMetadata
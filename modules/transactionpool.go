@@ -3,6 +3,7 @@ package modules
 import (
 	"errors"
 	"strings"
+	"time"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
@@ -19,8 +20,33 @@ const (
 	// rules.
 	TransactionSizeLimit = 32e3
 
+	// RejectionConflict indicates that a transaction set conflicts with
+	// the consensus set or with the transaction pool.
+	RejectionConflict TransactionSetRejectionReason = "conflict"
+
+	// RejectionInvalid indicates that a transaction set was rejected for
+	// any other reason, such as being empty.
+	RejectionInvalid TransactionSetRejectionReason = "invalid"
+
+	// RejectionLowFee indicates that a transaction set didn't pay enough
+	// fees to be added to the transaction pool.
+	RejectionLowFee TransactionSetRejectionReason = "lowfee"
+
+	// RejectionNonStandard indicates that a transaction set doesn't follow
+	// the IsStandard rules of the transaction pool.
+	RejectionNonStandard TransactionSetRejectionReason = "nonstandard"
+
 	// consensusConflictPrefix is the prefix of every ConsensusConflict.
 	consensusConflictPrefix = "consensus conflict: "
+
+	// DefaultFeeTarget is the number of blocks for which siac shows the
+	// target based fee estimate.
+	DefaultFeeTarget = types.BlockHeight(3)
+
+	// DefaultFeeConfidence is the confidence of target based fee estimates,
+	// which are used if a wallet send or the renter's allowance sets a fee
+	// target.
+	DefaultFeeConfidence = 0.9
 )
 
 var (
@@ -100,6 +126,66 @@ type (
 		ReceiveUpdatedUnconfirmedTransactions(*TransactionPoolDiff)
 	}
 
+	// TargetFeeEstimate is an estimation of the fee per byte that gets a
+	// transaction confirmed within Target blocks with the given Confidence.
+	TargetFeeEstimate struct {
+		FeePerByte types.Currency    `json:"feeperbyte"`
+		Target     types.BlockHeight `json:"target"`
+		Confidence float64           `json:"confidence"`
+
+		// Samples is the number of recently confirmed transaction sets the
+		// estimation is based on. If there were too few samples, the
+		// estimation falls back to the fee percentiles of recent blocks, or
+		// to the maximum recommendation of FeeEstimation if no fees were
+		// recorded at all.
+		Samples  int  `json:"samples"`
+		Fallback bool `json:"fallback"`
+	}
+
+	// TransactionSetRejectionReason describes why the transaction pool
+	// rejected a transaction set.
+	TransactionSetRejectionReason string
+
+	// TransactionSetRejection is a transaction set that was rejected by the
+	// transaction pool. Peer is empty if the set was submitted locally.
+	TransactionSetRejection struct {
+		SetID          TransactionSetID              `json:"setid"`
+		TransactionIDs []types.TransactionID         `json:"transactionids"`
+		Size           uint64                        `json:"size"`
+		FeePerByte     types.Currency                `json:"feeperbyte"`
+		Time           time.Time                     `json:"time"`
+		Peer           NetAddress                    `json:"peer"`
+		Reason         TransactionSetRejectionReason `json:"reason"`
+		Error          string                        `json:"error"`
+	}
+
+	// TransactionSetInfo describes a transaction set in the transaction pool.
+	// Arrival is the time the first transaction of the set arrived and Peer
+	// the peer that relayed it, which is empty if it was submitted locally or
+	// re-added after a reorg.
+	TransactionSetInfo struct {
+		ID            TransactionSetID             `json:"id"`
+		Size          uint64                       `json:"size"`
+		Fees          types.Currency               `json:"fees"`
+		FeePerByte    types.Currency               `json:"feeperbyte"`
+		Arrival       time.Time                    `json:"arrival"`
+		ArrivalHeight types.BlockHeight            `json:"arrivalheight"`
+		Peer          NetAddress                   `json:"peer"`
+		Transactions  []UnconfirmedTransactionInfo `json:"transactions"`
+	}
+
+	// UnconfirmedTransactionInfo describes a transaction of a transaction set
+	// in the transaction pool. Parents are the unconfirmed transactions whose
+	// outputs the transaction spends, and Children the unconfirmed
+	// transactions that spend its outputs.
+	UnconfirmedTransactionInfo struct {
+		ID       types.TransactionID   `json:"id"`
+		Size     uint64                `json:"size"`
+		Fees     types.Currency        `json:"fees"`
+		Parents  []types.TransactionID `json:"parents"`
+		Children []types.TransactionID `json:"children"`
+	}
+
 	// A TransactionPool manages unconfirmed transactions.
 	TransactionPool interface {
 		Alerter
@@ -123,6 +209,13 @@ type (
 		// within 10 blocks.
 		FeeEstimation() (minimumRecommended, maximumRecommended types.Currency)
 
+		// FeeEstimationTarget returns an estimation for how high the
+		// transaction fee needs to be per byte to get a transaction confirmed
+		// within target blocks with the provided confidence. The estimation is
+		// based on the fees and waiting times of recently confirmed
+		// transactions.
+		FeeEstimationTarget(target types.BlockHeight, confidence float64) TargetFeeEstimate
+
 		// PurgeTransactionPool is a temporary function available to the miner. In
 		// the event that a miner mines an unacceptable block, the transaction pool
 		// will be purged to clear out the transaction pool and get rid of the
@@ -130,6 +223,16 @@ type (
 		// that make this condition necessary.
 		PurgeTransactionPool()
 
+		// ReplaceTransactionSet is like AcceptTransactionSet, but the set may
+		// replace the transaction sets in the pool whose inputs it double
+		// spends if it pays more fees than them. It is meant for bumping the
+		// fees of the node's own transactions.
+		ReplaceTransactionSet([]types.Transaction) error
+
+		// RecentRejections returns the transaction sets that were most recently
+		// rejected by the transaction pool, newest first.
+		RecentRejections() []TransactionSetRejection
+
 		// Transaction returns the transaction and unconfirmed parents
 		// corresponding to the provided transaction id.
 		Transaction(id types.TransactionID) (txn types.Transaction, unconfirmedParents []types.Transaction, exists bool)
@@ -137,6 +240,10 @@ type (
 		// Transactions returns the transactions of the transaction pool
 		Transactions() []types.Transaction
 
+		// TransactionSetsInfo returns the transaction sets in the transaction
+		// pool, ordered by arrival.
+		TransactionSetsInfo() []TransactionSetInfo
+
 		// TransactionConfirmed returns true if the transaction has been seen on the
 		// blockchain. Note, however, that the block containing the transaction may
 		// later be invalidated by a reorg.
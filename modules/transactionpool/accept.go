@@ -21,6 +21,11 @@ var (
 	errEmptySet     = errors.New("transaction set is empty")
 	errLowMinerFees = errors.New("transaction set needs more miner fees to be accepted")
 
+	// errLowReplacementFees is returned if a transaction set double spends the
+	// inputs of transactions in the pool without paying enough fees on top of
+	// theirs.
+	errLowReplacementFees = errors.New("transaction set needs to pay more fees in total and per byte than the transactions it replaces, and the additional fees need to cover its own size")
+
 	// ErrTxnSetNotAccepted is the error returned when the dependency
 	// DoNotAcceptTxnSet is used
 	ErrTxnSetNotAccepted = errors.New("transaction set was not accepted")
@@ -243,9 +248,154 @@ func (tp *TransactionPool) handleConflicts(ts []types.Transaction, conflicts []m
 	return superset, nil
 }
 
+// doubleSpentSets returns the conflicting sets which contain a transaction
+// that spends an input that is also spent by a different transaction of ts.
+func (tp *TransactionPool) doubleSpentSets(ts []types.Transaction, conflicts []modules.TransactionSetID) map[modules.TransactionSetID]struct{} {
+	spentBy := make(map[ObjectID]types.TransactionID)
+	for _, txn := range ts {
+		txid := txn.ID()
+		for _, sci := range txn.SiacoinInputs {
+			spentBy[ObjectID(sci.ParentID)] = txid
+		}
+		for _, sfi := range txn.SiafundInputs {
+			spentBy[ObjectID(sfi.ParentID)] = txid
+		}
+	}
+	doubleSpends := func(txn types.Transaction, oid ObjectID) bool {
+		spender, exists := spentBy[oid]
+		return exists && spender != txn.ID()
+	}
+
+	replaced := make(map[modules.TransactionSetID]struct{})
+	for _, conflict := range conflicts {
+		for _, txn := range tp.transactionSets[conflict] {
+			for _, sci := range txn.SiacoinInputs {
+				if doubleSpends(txn, ObjectID(sci.ParentID)) {
+					replaced[conflict] = struct{}{}
+				}
+			}
+			for _, sfi := range txn.SiafundInputs {
+				if doubleSpends(txn, ObjectID(sfi.ParentID)) {
+					replaced[conflict] = struct{}{}
+				}
+			}
+		}
+	}
+	return replaced
+}
+
+// replaceConflicts replaces the transaction sets which double spend inputs of
+// ts with ts, if ts pays more fees than them both in total and per byte. The
+// additional fees also have to pay for the size of ts at the fee that is
+// required to extend the pool, since the replaced sets were already relayed.
+// Every transaction of a replaced set is evicted, including the descendants of
+// the double spends, so ts has to contain any of their parents it still
+// depends on. The remaining conflicts are merged with ts like in
+// handleConflicts.
+func (tp *TransactionPool) replaceConflicts(ts []types.Transaction, conflicts []modules.TransactionSetID, replaced map[modules.TransactionSetID]struct{}, txnFn func([]types.Transaction) (modules.ConsensusChange, error)) ([]types.Transaction, error) {
+	setFees := func(set []types.Transaction) (fees types.Currency) {
+		for _, txn := range set {
+			for _, fee := range txn.MinerFees {
+				fees = fees.Add(fee)
+			}
+		}
+		return fees
+	}
+	var replacedFees types.Currency
+	var replacedSize int
+	for conflict := range replaced {
+		replacedFees = replacedFees.Add(setFees(tp.transactionSets[conflict]))
+		replacedSize += len(encoding.Marshal(tp.transactionSets[conflict]))
+	}
+	newFees := setFees(ts)
+	newSize := len(encoding.Marshal(ts))
+	// Compare the fees per byte by cross multiplying the sizes.
+	if newFees.Cmp(replacedFees) <= 0 || newFees.Mul64(uint64(replacedSize)).Cmp(replacedFees.Mul64(uint64(newSize))) <= 0 {
+		return nil, errLowReplacementFees
+	}
+	if newFees.Sub(replacedFees).Cmp(tp.requiredFeesToExtendTpool().Mul64(uint64(newSize))) < 0 {
+		return nil, errLowReplacementFees
+	}
+
+	// Merge the conflicts that aren't replaced with the input set, skipping
+	// the transactions of the input set that they already contain.
+	var superset []types.Transaction
+	kept := make(map[modules.TransactionSetID]struct{})
+	keptTxns := make(map[types.TransactionID]struct{})
+	for _, conflict := range conflicts {
+		if _, exists := replaced[conflict]; exists {
+			continue
+		}
+		if _, exists := kept[conflict]; exists {
+			continue
+		}
+		kept[conflict] = struct{}{}
+		for _, txn := range tp.transactionSets[conflict] {
+			keptTxns[txn.ID()] = struct{}{}
+			superset = append(superset, txn)
+		}
+	}
+	for _, txn := range ts {
+		if _, exists := keptTxns[txn.ID()]; !exists {
+			superset = append(superset, txn)
+		}
+	}
+
+	setSize, err := tp.checkTransactionSetComposition(superset)
+	if err != nil {
+		return nil, err
+	}
+	if tp.requiredFeesToExtendTpool().Mul64(setSize).Cmp(setFees(superset)) > 0 {
+		return nil, errLowMinerFees
+	}
+	cc, err := txnFn(superset)
+	if err != nil {
+		return nil, modules.NewConsensusConflict("provided replacement transaction set is invalid: " + err.Error())
+	}
+
+	// Evict the replaced sets, including the objects they created, and remove
+	// the merged conflicts.
+	for oid, setID := range tp.knownObjects {
+		if _, exists := replaced[setID]; exists {
+			delete(tp.knownObjects, oid)
+		}
+	}
+	for conflict := range replaced {
+		kept[conflict] = struct{}{}
+		tp.log.Debugln("Replacing transaction set", conflict)
+	}
+	for conflict := range kept {
+		tp.transactionListSize -= len(encoding.Marshal(tp.transactionSets[conflict]))
+		delete(tp.transactionSets, conflict)
+		delete(tp.transactionSetDiffs, conflict)
+	}
+
+	// Add the transaction set to the pool.
+	setID := modules.TransactionSetID(crypto.HashObject(superset))
+	tp.transactionSets[setID] = superset
+	for _, diff := range cc.SiacoinOutputDiffs {
+		tp.knownObjects[ObjectID(diff.ID)] = setID
+	}
+	for _, diff := range cc.FileContractDiffs {
+		tp.knownObjects[ObjectID(diff.ID)] = setID
+	}
+	for _, diff := range cc.SiafundOutputDiffs {
+		tp.knownObjects[ObjectID(diff.ID)] = setID
+	}
+	tp.transactionSetDiffs[setID] = &cc
+	tp.transactionListSize += len(encoding.Marshal(superset))
+	for _, txn := range superset {
+		if _, exists := tp.transactionHeights[txn.ID()]; !exists {
+			tp.transactionHeights[txn.ID()] = tp.blockHeight
+		}
+	}
+	return superset, nil
+}
+
 // acceptTransactionSet verifies that a transaction set is allowed to be in the
-// transaction pool, and then adds it to the transaction pool.
-func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn func([]types.Transaction) (modules.ConsensusChange, error)) (superset []types.Transaction, err error) {
+// transaction pool, and then adds it to the transaction pool. If replace is
+// set, the transaction set may replace the sets whose inputs it double spends.
+func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn func([]types.Transaction) (modules.ConsensusChange, error), replace bool) (superset []types.Transaction, err error) {
 	if len(ts) == 0 {
 		return nil, errEmptySet
 	}
@@ -300,6 +450,9 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn fu
 		}
 	}
 	if len(conflicts) > 0 {
+		if replaced := tp.doubleSpentSets(ts, conflicts); replace && len(replaced) > 0 {
+			return tp.replaceConflicts(ts, conflicts, replaced, txnFn)
+		}
 		return tp.handleConflicts(ts, conflicts, txnFn)
 	}
 	cc, err := txnFn(ts)
@@ -335,7 +488,7 @@ func (tp *TransactionPool) acceptTransactionSet(ts []types.Transaction, txnFn fu
 
 // submitTransactionSet will submit a transaction set to the transaction pool
 // and return the minimum superset for that transaction set.
-func (tp *TransactionPool) submitTransactionSet(ts []types.Transaction) ([]types.Transaction, error) {
+func (tp *TransactionPool) submitTransactionSet(ts []types.Transaction, peer modules.NetAddress, replace bool) ([]types.Transaction, error) {
 	// assert on consensus set to get special method
 	cs, ok := tp.consensusSet.(interface {
 		LockedTryTransactionSet(fn func(func(txns []types.Transaction) (modules.ConsensusChange, error)) error) error
@@ -351,18 +504,20 @@ func (tp *TransactionPool) submitTransactionSet(ts []types.Transaction) ([]types
 		defer tp.mu.Unlock()
 
 		// Attempt to get the transaction set into the transaction pool.
-		superset, acceptErr = tp.acceptTransactionSet(ts, txnFn)
+		superset, acceptErr = tp.acceptTransactionSet(ts, txnFn, replace)
 		if acceptErr == modules.ErrDuplicateTransactionSet {
 			tp.log.Debugln("Transaction set is a duplicate:", acceptErr)
 			return acceptErr
 		}
 		if acceptErr != nil {
+			tp.recordRejection(ts, peer, acceptErr)
 			tp.log.Debugln("Transaction set broadcast has failed:", acceptErr)
 			if build.DEBUG && modules.IsConsensusConflict(acceptErr) {
 				tp.printConflicts(ts)
 			}
 			return acceptErr
 		}
+		tp.recordArrivals(superset, peer)
 		// Notify subscribers of an accepted transaction set
 		tp.updateSubscribersTransactions()
 		return nil
@@ -385,14 +540,35 @@ func (tp *TransactionPool) AcceptTransactionSet(ts []types.Transaction) error {
 		return err
 	}
 	defer tp.tg.Done()
+	return tp.managedAcceptTransactionSet(ts, "", false)
+}
+
+// ReplaceTransactionSet is like AcceptTransactionSet, but the transaction set
+// may double spend the inputs of transaction sets in the pool. They are
+// replaced if the new set pays more fees than them. It is only used by the
+// wallet to bump the fees of its own transactions, sets relayed by peers can't
+// replace other sets.
+func (tp *TransactionPool) ReplaceTransactionSet(ts []types.Transaction) error {
+	if err := tp.tg.Add(); err != nil {
+		return err
+	}
+	defer tp.tg.Done()
+	return tp.managedAcceptTransactionSet(ts, "", true)
+}
 
+// managedAcceptTransactionSet adds a transaction set that was relayed by peer
+// to the unconfirmed set of transactions. The peer is empty if the set was
+// submitted locally. If replace is set, the set may replace the sets whose
+// inputs it double spends. If the transaction is accepted, it will be relayed
+// to connected peers.
+func (tp *TransactionPool) managedAcceptTransactionSet(ts []types.Transaction, peer modules.NetAddress, replace bool) error {
 	// Drop the transaction set and return ErrTxnSetNotAccepted
 	if tp.deps.Disrupt("DoNotAcceptTxnSet") {
 		return ErrTxnSetNotAccepted
 	}
 
 	tp.log.Debugln("Received a transaction (internal or external), attempting to broadcast")
-	minSuperSet, err := tp.submitTransactionSet(ts)
+	minSuperSet, err := tp.submitTransactionSet(ts, peer, replace)
 	if err == modules.ErrDuplicateTransactionSet {
 		return err
 	}
@@ -436,5 +612,5 @@ func (tp *TransactionPool) relayTransactionSet(conn modules.PeerConn) error {
 	if err != nil {
 		return err
 	}
-	return tp.AcceptTransactionSet(ts)
+	return tp.managedAcceptTransactionSet(ts, conn.RPCAddr(), false)
 }
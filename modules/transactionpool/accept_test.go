@@ -5,6 +5,7 @@ import (
 
 	"gitlab.com/NebulousLabs/fastrand"
 
+	"gitlab.com/NebulousLabs/Sia/encoding"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
@@ -121,6 +122,130 @@ func TestConflictingTransactionSets(t *testing.T) {
 	}
 }
 
+// TestReplaceTransactionSet checks that a transaction set which double spends
+// a set in the pool replaces it if it pays a higher fee.
+func TestReplaceTransactionSet(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create two sets which spend the same output, one of them spends the
+	// money in a miner fee.
+	fund := types.NewCurrency64(30e6)
+	txnBuilder, err := tpt.wallet.StartTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txnBuilder.FundSiacoins(fund); err != nil {
+		t.Fatal(err)
+	}
+	txnSet, err := txnBuilder.Sign(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnSetDoubleSpend := make([]types.Transaction, len(txnSet))
+	copy(txnSetDoubleSpend, txnSet)
+	txnIndex := len(txnSet) - 1
+	txnSet[txnIndex].MinerFees = append(txnSet[txnIndex].MinerFees, fund)
+	txnSetDoubleSpend[txnIndex].SiacoinOutputs = append(txnSetDoubleSpend[txnIndex].SiacoinOutputs, types.SiacoinOutput{Value: fund})
+
+	// The set paying the higher fee replaces the other one.
+	if err := tpt.tpool.AcceptTransactionSet(txnSetDoubleSpend); err != nil {
+		t.Fatal(err)
+	}
+	if err := tpt.tpool.ReplaceTransactionSet(txnSet); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, exists := tpt.tpool.Transaction(txnSetDoubleSpend[txnIndex].ID()); exists {
+		t.Error("replaced transaction is still in the pool")
+	}
+	if _, _, exists := tpt.tpool.Transaction(txnSet[txnIndex].ID()); !exists {
+		t.Error("replacement transaction is not in the pool")
+	}
+	// The replaced set can't come back with its lower fee.
+	err = tpt.tpool.ReplaceTransactionSet(txnSetDoubleSpend)
+	if err != errLowReplacementFees {
+		t.Error("expected errLowReplacementFees, got", err)
+	}
+	// The replacement should be mined.
+	block, _ := tpt.miner.FindBlock()
+	if err := tpt.cs.AcceptBlock(block); err != nil {
+		t.Fatal(err)
+	}
+	if len(tpt.tpool.TransactionList()) != 0 {
+		t.Error("transaction pool was not emptied after mining a block")
+	}
+}
+
+// TestReplacementIncrementalFees checks that a replacement set has to pay for
+// its own size on top of the fees of the sets it replaces once the pool
+// requires fees.
+func TestReplacementIncrementalFees(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Create three sets which spend the same output and only differ in their
+	// fees.
+	fund := types.SiacoinPrecision
+	txnBuilder, err := tpt.wallet.StartTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := txnBuilder.FundSiacoins(fund); err != nil {
+		t.Fatal(err)
+	}
+	parents, err := txnBuilder.Sign(false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	withFee := func(fee types.Currency) []types.Transaction {
+		set := make([]types.Transaction, len(parents))
+		copy(set, parents)
+		txn := &set[len(set)-1]
+		txn.MinerFees = append(append([]types.Currency(nil), txn.MinerFees...), fee)
+		txn.SiacoinOutputs = append(append([]types.SiacoinOutput(nil), txn.SiacoinOutputs...), types.SiacoinOutput{Value: fund.Sub(fee)})
+		return set
+	}
+	fee := fund.Div64(2)
+	original := withFee(fee)
+
+	// Pretend that the pool is large enough to require fees.
+	tpt.tpool.mu.Lock()
+	tpt.tpool.transactionListSize += TransactionPoolSizeForFee
+	required := tpt.tpool.requiredFeesToExtendTpool().Mul64(uint64(len(encoding.Marshal(original))))
+	tpt.tpool.mu.Unlock()
+	if required.IsZero() {
+		t.Fatal("pool doesn't require fees")
+	}
+	if err := tpt.tpool.AcceptTransactionSet(original); err != nil {
+		t.Fatal(err)
+	}
+
+	// Paying a single hasting more isn't enough.
+	if err := tpt.tpool.ReplaceTransactionSet(withFee(fee.Add(types.NewCurrency64(1)))); err != errLowReplacementFees {
+		t.Fatal("expected errLowReplacementFees, got", err)
+	}
+	// Paying for the size of the replacement is.
+	replacement := withFee(fee.Add(required.Mul64(2)))
+	if err := tpt.tpool.ReplaceTransactionSet(replacement); err != nil {
+		t.Fatal(err)
+	}
+	if _, _, exists := tpt.tpool.Transaction(replacement[len(replacement)-1].ID()); !exists {
+		t.Fatal("replacement transaction is not in the pool")
+	}
+}
+
 // TestCheckMinerFees probes the checkMinerFees method of the
 // transaction pool.
 func TestCheckMinerFees(t *testing.T) {
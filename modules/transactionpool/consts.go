@@ -16,6 +16,10 @@ const (
 
 // Constants related to the size and ease-of-entry of the transaction pool.
 const (
+	// recentRejectionsSize is the number of recently rejected transaction
+	// sets that the transaction pool keeps for introspection.
+	recentRejectionsSize = 250
+
 	// logSizeFrequency is how often the transaction pool size will be logged
 	// when running in debug mode.
 	logSizeFrequency = time.Minute * 5
@@ -59,6 +63,11 @@ const (
 	// added to the current tpool size when estimating a good fee rate for new
 	// transactions.
 	feeEstimationProportionalPadding = 1.25
+
+	// feeEstimatorSamplesPerBlock is the maximum number of confirmed
+	// transaction sets that are recorded per block for the target based fee
+	// estimation.
+	feeEstimatorSamplesPerBlock = 128
 )
 
 // Variables related to the persisting structures of the transaction pool.
@@ -76,6 +85,32 @@ var (
 	minEstimation = types.SiacoinPrecision.Div64(100).Div64(1e3)
 )
 
+// Variables related to the target based fee estimation.
+var (
+	// feeEstimatorDepth is the number of recent blocks whose fees are
+	// recorded for the target based fee estimation.
+	feeEstimatorDepth = build.Select(build.Var{
+		Standard: 432,
+		Dev:      50,
+		Testing:  20,
+	}).(int)
+
+	// feeEstimatorGroupSize is the number of transaction sets that are
+	// grouped together when checking how many of them were confirmed within
+	// the target. A fee is only recommended if every group of transaction
+	// sets paying at least that fee was confirmed within the target often
+	// enough.
+	feeEstimatorGroupSize = build.Select(build.Var{
+		Standard: 20,
+		Dev:      10,
+		Testing:  3,
+	}).(int)
+
+	// feePercentiles are the size weighted percentiles of the fees that are
+	// recorded for each block.
+	feePercentiles = []float64{0.1, 0.25, 0.5, 0.75, 0.9}
+)
+
 // Variables related to propagating transactions through the network.
 var (
 	// relayTransactionSetTimeout establishes the timeout for a relay
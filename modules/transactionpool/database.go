@@ -2,6 +2,7 @@ package transactionpool
 
 import (
 	"encoding/json"
+	"sort"
 
 	"gitlab.com/NebulousLabs/bolt"
 	"gitlab.com/NebulousLabs/errors"
@@ -22,6 +23,10 @@ var (
 	// transaction pool.
 	bucketBlockHeight = []byte("BlockHeight")
 
+	// bucketBlockFees holds the fees of the transactions confirmed in recent
+	// blocks, indexed by block height.
+	bucketBlockFees = []byte("BlockFees")
+
 	// bucketConfirmedTransactions holds the ids of every transaction that has
 	// been confirmed on the blockchain.
 	bucketConfirmedTransactions = []byte("ConfirmedTransactions")
@@ -79,6 +84,11 @@ type (
 	}
 )
 
+// deleteBlockFees deletes the fees recorded for the block at height.
+func (tp *TransactionPool) deleteBlockFees(tx *bolt.Tx, height types.BlockHeight) error {
+	return tx.Bucket(bucketBlockFees).Delete(encoding.Marshal(height))
+}
+
 // deleteTransaction deletes a transaction from the list of confirmed
 // transactions.
 func (tp *TransactionPool) deleteTransaction(tx *bolt.Tx, id types.TransactionID) error {
@@ -91,6 +101,24 @@ func (tp *TransactionPool) getBlockHeight(tx *bolt.Tx) (bh types.BlockHeight, er
 	return
 }
 
+// getBlockFees returns the fees recorded for all recent blocks, sorted by
+// height.
+func (tp *TransactionPool) getBlockFees(tx *bolt.Tx) ([]blockFees, error) {
+	var bfs []blockFees
+	err := tx.Bucket(bucketBlockFees).ForEach(func(_, v []byte) error {
+		var bf blockFees
+		if err := json.Unmarshal(v, &bf); err != nil {
+			return build.ExtendErr("unable to unmarshal block fees:", err)
+		}
+		bfs = append(bfs, bf)
+		return nil
+	})
+	sort.Slice(bfs, func(i, j int) bool {
+		return bfs[i].Height < bfs[j].Height
+	})
+	return bfs, err
+}
+
 // getFeeMedian will get the fee median struct stored in the database.
 func (tp *TransactionPool) getFeeMedian(tx *bolt.Tx) (medianPersist, error) {
 	medianBytes := tp.dbTx.Bucket(bucketFeeMedian).Get(fieldFeeMedian)
@@ -137,6 +165,15 @@ func (tp *TransactionPool) putBlockHeight(tx *bolt.Tx, height types.BlockHeight)
 	return tx.Bucket(bucketBlockHeight).Put(fieldBlockHeight, encoding.Marshal(height))
 }
 
+// putBlockFees puts the fees recorded for a block into the database.
+func (tp *TransactionPool) putBlockFees(tx *bolt.Tx, bf blockFees) error {
+	objBytes, err := json.Marshal(bf)
+	if err != nil {
+		return err
+	}
+	return tx.Bucket(bucketBlockFees).Put(encoding.Marshal(bf.Height), objBytes)
+}
+
 // putFeeMedian puts a median fees object into the database.
 func (tp *TransactionPool) putFeeMedian(tx *bolt.Tx, mp medianPersist) error {
 	objBytes, err := json.Marshal(mp)
@@ -0,0 +1,252 @@
+package transactionpool
+
+import (
+	"sort"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+type (
+	// feeSample is the fee per byte of a transaction set and the number of
+	// blocks it waited in the transaction pool. Sets that were dropped from
+	// the pool because they reached MaxTransactionAge are recorded as
+	// expired.
+	feeSample struct {
+		FeePerByte types.Currency
+		Wait       types.BlockHeight
+		Expired    bool
+	}
+
+	// blockFees summarizes the fees of the transactions confirmed in a block.
+	// Percentiles holds the size weighted fee per byte percentiles listed in
+	// feePercentiles, and is empty if the block did not contain any
+	// transactions. Samples only contains the sets that were seen in the
+	// transaction pool before they were confirmed.
+	blockFees struct {
+		Height      types.BlockHeight
+		Percentiles []types.Currency
+		Samples     []feeSample
+	}
+)
+
+// setFeePerByte returns the fee per byte paid by a transaction set and the
+// size of the set.
+func setFeePerByte(set []types.Transaction) (types.Currency, int) {
+	var fees types.Currency
+	var size int
+	for _, txn := range set {
+		size += txn.MarshalSiaSize()
+		for _, fee := range txn.MinerFees {
+			fees = fees.Add(fee)
+		}
+	}
+	if size == 0 {
+		return types.ZeroCurrency, 0
+	}
+	return fees.Div64(uint64(size)), size
+}
+
+// computeBlockFees summarizes the fees of the transactions in a block that was
+// applied at height. The transaction heights of the pool are used to find out
+// how long each transaction set waited before it was confirmed.
+func (tp *TransactionPool) computeBlockFees(block types.Block, height types.BlockHeight) blockFees {
+	bf := blockFees{Height: height}
+
+	type setFee struct {
+		fee  types.Currency
+		size int
+	}
+	var fees []setFee
+	var totalSize int
+	for _, set := range findSets(block.Transactions) {
+		fee, size := setFeePerByte(set)
+		fees = append(fees, setFee{fee: fee, size: size})
+		totalSize += size
+
+		// A set becomes attractive to miners once all of its transactions
+		// have arrived, so the wait is counted from the most recent arrival.
+		seen := false
+		var wait types.BlockHeight
+		for _, txn := range set {
+			seenHeight, exists := tp.transactionHeights[txn.ID()]
+			if !exists || seenHeight > height {
+				continue
+			}
+			if !seen || height-seenHeight < wait {
+				wait = height - seenHeight
+			}
+			seen = true
+		}
+		if seen {
+			bf.Samples = append(bf.Samples, feeSample{
+				FeePerByte: fee,
+				Wait:       wait,
+			})
+		}
+	}
+	if totalSize == 0 {
+		return bf
+	}
+
+	// Compute the size weighted percentiles.
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i].fee.Cmp(fees[j].fee) < 0
+	})
+	var progress, i int
+	for _, p := range feePercentiles {
+		for ; i < len(fees)-1; i++ {
+			if float64(progress+fees[i].size) > p*float64(totalSize) {
+				break
+			}
+			progress += fees[i].size
+		}
+		bf.Percentiles = append(bf.Percentiles, fees[i].fee)
+	}
+
+	// Keep the number of samples per block bounded by picking evenly spaced
+	// samples.
+	if len(bf.Samples) > feeEstimatorSamplesPerBlock {
+		samples := make([]feeSample, 0, feeEstimatorSamplesPerBlock)
+		for j := 0; j < feeEstimatorSamplesPerBlock; j++ {
+			samples = append(samples, bf.Samples[j*len(bf.Samples)/feeEstimatorSamplesPerBlock])
+		}
+		bf.Samples = samples
+	}
+	return bf
+}
+
+// applyBlockFees records the fees of a block that was applied at height.
+func (tp *TransactionPool) applyBlockFees(tx *bolt.Tx, block types.Block, height types.BlockHeight) error {
+	bf := tp.computeBlockFees(block, height)
+	tp.recentBlockFees = append(tp.recentBlockFees, bf)
+	if err := tp.putBlockFees(tx, bf); err != nil {
+		return err
+	}
+	for len(tp.recentBlockFees) > feeEstimatorDepth {
+		if err := tp.deleteBlockFees(tx, tp.recentBlockFees[0].Height); err != nil {
+			return err
+		}
+		tp.recentBlockFees = tp.recentBlockFees[1:]
+	}
+	return nil
+}
+
+// revertBlockFees removes the fees of a block that was reverted at height.
+func (tp *TransactionPool) revertBlockFees(tx *bolt.Tx, height types.BlockHeight) error {
+	n := len(tp.recentBlockFees)
+	if n == 0 || tp.recentBlockFees[n-1].Height != height {
+		return nil
+	}
+	tp.recentBlockFees = tp.recentBlockFees[:n-1]
+	return tp.deleteBlockFees(tx, height)
+}
+
+// addExpiredFees records transaction sets that were dropped from the pool
+// without being confirmed. They are added to the most recent block.
+func (tp *TransactionPool) addExpiredFees(tx *bolt.Tx, sets [][]types.Transaction) error {
+	n := len(tp.recentBlockFees)
+	if n == 0 || len(sets) == 0 {
+		return nil
+	}
+	bf := &tp.recentBlockFees[n-1]
+	for _, set := range sets {
+		if len(bf.Samples) >= feeEstimatorSamplesPerBlock {
+			break
+		}
+		fee, size := setFeePerByte(set)
+		if size == 0 {
+			continue
+		}
+		bf.Samples = append(bf.Samples, feeSample{
+			FeePerByte: fee,
+			Wait:       MaxTransactionAge,
+			Expired:    true,
+		})
+	}
+	return tp.putBlockFees(tx, *bf)
+}
+
+// FeeEstimationTarget returns an estimation for how high the transaction fee
+// needs to be per byte to get a transaction confirmed within target blocks
+// with the provided confidence.
+//
+// The recorded transaction sets are sorted by fee and split into groups of
+// feeEstimatorGroupSize, starting with the highest fees. The estimation is the
+// lowest fee of the last group for which the fraction of sets confirmed within
+// the target is at least the confidence, as long as all groups with higher
+// fees passed the same check.
+func (tp *TransactionPool) FeeEstimationTarget(target types.BlockHeight, confidence float64) (est modules.TargetFeeEstimate) {
+	est.Target = target
+	est.Confidence = confidence
+	if err := tp.tg.Add(); err != nil {
+		return
+	}
+	defer tp.tg.Done()
+	tp.mu.Lock()
+	defer tp.mu.Unlock()
+
+	min, max := tp.feeEstimation()
+	defer func() {
+		// Never recommend a fee that would not get the transaction into the
+		// pool.
+		if est.FeePerByte.Cmp(min) < 0 {
+			est.FeePerByte = min
+		}
+	}()
+
+	var samples []feeSample
+	for _, bf := range tp.recentBlockFees {
+		samples = append(samples, bf.Samples...)
+	}
+	sort.Slice(samples, func(i, j int) bool {
+		return samples[i].FeePerByte.Cmp(samples[j].FeePerByte) > 0
+	})
+	var confirmed, count int
+	for _, s := range samples {
+		count++
+		if !s.Expired && s.Wait <= target {
+			confirmed++
+		}
+		if count < feeEstimatorGroupSize {
+			continue
+		}
+		if float64(confirmed) < confidence*float64(count) {
+			break
+		}
+		est.FeePerByte = s.FeePerByte
+		est.Samples += count
+		confirmed, count = 0, 0
+	}
+	if est.Samples > 0 {
+		return
+	}
+
+	// There were not enough samples. Fall back to the median of the
+	// percentile of recent blocks that matches the confidence.
+	est.Fallback = true
+	pi := len(feePercentiles) - 1
+	for i, p := range feePercentiles {
+		if p >= confidence {
+			pi = i
+			break
+		}
+	}
+	var fees []types.Currency
+	for _, bf := range tp.recentBlockFees {
+		if len(bf.Percentiles) == len(feePercentiles) {
+			fees = append(fees, bf.Percentiles[pi])
+		}
+	}
+	if len(fees) == 0 {
+		est.FeePerByte = max
+		return
+	}
+	sort.Slice(fees, func(i, j int) bool {
+		return fees[i].Cmp(fees[j]) < 0
+	})
+	est.FeePerByte = fees[len(fees)/2]
+	return
+}
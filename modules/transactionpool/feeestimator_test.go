@@ -0,0 +1,148 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestBlockFees checks that the fees and waiting times of confirmed
+// transactions are recorded when a block is applied.
+func TestBlockFees(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Blocks without transactions are recorded without samples.
+	tpt.tpool.mu.Lock()
+	numBlocks := len(tpt.tpool.recentBlockFees)
+	tpt.tpool.mu.Unlock()
+	if numBlocks == 0 || numBlocks > feeEstimatorDepth {
+		t.Fatal("unexpected number of recorded blocks", numBlocks)
+	}
+
+	// Send a few transactions and confirm them in the next block. Sends that
+	// spend the change of a previous send end up in the same set.
+	for i := 0; i < 3; i++ {
+		_, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+	if _, err := tpt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	tpt.tpool.mu.Lock()
+	defer tpt.tpool.mu.Unlock()
+	bf := tpt.tpool.recentBlockFees[len(tpt.tpool.recentBlockFees)-1]
+	if bf.Height != tpt.tpool.blockHeight {
+		t.Fatal("block fees recorded at the wrong height", bf.Height, tpt.tpool.blockHeight)
+	}
+	if len(bf.Percentiles) != len(feePercentiles) {
+		t.Fatal("expected percentiles to be recorded", bf.Percentiles)
+	}
+	if len(bf.Samples) == 0 || len(bf.Samples) > 3 {
+		t.Fatal("unexpected number of samples", len(bf.Samples))
+	}
+	for _, s := range bf.Samples {
+		if s.Wait != 1 || s.Expired || s.FeePerByte.IsZero() {
+			t.Fatal("unexpected sample", s)
+		}
+	}
+
+	// The block fees are persisted.
+	bfs, err := tpt.tpool.getBlockFees(tpt.tpool.dbTx)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(bfs) != len(tpt.tpool.recentBlockFees) || len(bfs[len(bfs)-1].Samples) != len(bf.Samples) {
+		t.Fatal("block fees weren't persisted")
+	}
+}
+
+// TestFeeEstimationTarget checks that the target based fee estimation picks the
+// lowest fee that got transactions confirmed within the target.
+func TestFeeEstimationTarget(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Without any recorded fees the maximum recommendation is used.
+	tpt.tpool.mu.Lock()
+	tpt.tpool.recentBlockFees = nil
+	tpt.tpool.mu.Unlock()
+	min, max := tpt.tpool.FeeEstimation()
+	est := tpt.tpool.FeeEstimationTarget(1, 0.9)
+	if !est.Fallback || est.Samples != 0 || !est.FeePerByte.Equals(max) {
+		t.Fatal("unexpected estimate without data", est)
+	}
+
+	// Record high fee sets that waited a single block and low fee sets that
+	// waited longer.
+	high := types.SiacoinPrecision.Div64(1e3)
+	low := types.SiacoinPrecision.Div64(1e4)
+	var samples []feeSample
+	for i := 0; i < feeEstimatorGroupSize*2; i++ {
+		samples = append(samples, feeSample{FeePerByte: high.Add(types.NewCurrency64(uint64(i))), Wait: 1})
+		samples = append(samples, feeSample{FeePerByte: low.Add(types.NewCurrency64(uint64(i))), Wait: 4})
+	}
+	tpt.tpool.mu.Lock()
+	tpt.tpool.recentBlockFees = []blockFees{{Height: 1, Samples: samples}}
+	tpt.tpool.mu.Unlock()
+
+	est = tpt.tpool.FeeEstimationTarget(1, 0.9)
+	if est.Fallback || !est.FeePerByte.Equals(high) || est.Samples != feeEstimatorGroupSize*2 {
+		t.Fatal("unexpected estimate for a target of 1 block", est)
+	}
+	est = tpt.tpool.FeeEstimationTarget(4, 0.9)
+	if est.Fallback || !est.FeePerByte.Equals(low) || est.Samples != feeEstimatorGroupSize*4 {
+		t.Fatal("unexpected estimate for a target of 4 blocks", est)
+	}
+
+	// Expired sets never count as confirmed.
+	for i := range samples {
+		if samples[i].Wait == 4 {
+			samples[i].Expired = true
+		}
+	}
+	tpt.tpool.mu.Lock()
+	tpt.tpool.recentBlockFees = []blockFees{{Height: 1, Samples: samples}}
+	tpt.tpool.mu.Unlock()
+	est = tpt.tpool.FeeEstimationTarget(10, 0.9)
+	if !est.FeePerByte.Equals(high) {
+		t.Fatal("expired sets should not be counted as confirmed", est)
+	}
+
+	// Without samples, the percentiles of the recent blocks are used.
+	percentiles := make([]types.Currency, len(feePercentiles))
+	for i := range percentiles {
+		percentiles[i] = high.Mul64(uint64(i + 1))
+	}
+	tpt.tpool.mu.Lock()
+	tpt.tpool.recentBlockFees = []blockFees{{Height: 1, Percentiles: percentiles}}
+	tpt.tpool.mu.Unlock()
+	est = tpt.tpool.FeeEstimationTarget(1, 0.5)
+	if !est.Fallback || !est.FeePerByte.Equals(percentiles[2]) {
+		t.Fatal("unexpected estimate from the block percentiles", est)
+	}
+
+	// The estimate never drops below the minimum recommendation.
+	tpt.tpool.mu.Lock()
+	tpt.tpool.recentBlockFees = []blockFees{{Height: 1, Percentiles: make([]types.Currency, len(feePercentiles))}}
+	tpt.tpool.mu.Unlock()
+	est = tpt.tpool.FeeEstimationTarget(1, 0.9)
+	if !est.FeePerByte.Equals(min) {
+		t.Fatal("estimate is below the minimum recommendation", est, min)
+	}
+}
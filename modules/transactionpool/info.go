@@ -0,0 +1,195 @@
+package transactionpool
+
+import (
+	"bytes"
+	"sort"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// transactionArrival records when a transaction arrived in the transaction
+// pool and which peer relayed it.
+type transactionArrival struct {
+	time time.Time
+	peer modules.NetAddress
+}
+
+// rejectionReason returns the reason for which a transaction set was rejected
+// with err.
+func rejectionReason(err error) modules.TransactionSetRejectionReason {
+	switch {
+	case errors.Contains(err, errLowMinerFees) || errors.Contains(err, errLowReplacementFees):
+		return modules.RejectionLowFee
+	case modules.IsConsensusConflict(err):
+		return modules.RejectionConflict
+	case errors.Contains(err, modules.ErrLargeTransaction) ||
+		errors.Contains(err, modules.ErrLargeTransactionSet) ||
+		errors.Contains(err, modules.ErrInvalidArbPrefix) ||
+		errors.Contains(err, errUnrecognizedKeyType):
+		return modules.RejectionNonStandard
+	default:
+		return modules.RejectionInvalid
+	}
+}
+
+// recordArrivals records the arrival of the transactions of a set that were
+// not in the pool yet.
+func (tp *TransactionPool) recordArrivals(ts []types.Transaction, peer modules.NetAddress) {
+	now := time.Now()
+	for _, txn := range ts {
+		if _, exists := tp.transactionArrivals[txn.ID()]; !exists {
+			tp.transactionArrivals[txn.ID()] = transactionArrival{
+				time: now,
+				peer: peer,
+			}
+		}
+	}
+}
+
+// recordRejection adds a rejected transaction set to the ring of recent
+// rejections, overwriting the oldest rejection once the ring is full.
+func (tp *TransactionPool) recordRejection(ts []types.Transaction, peer modules.NetAddress, err error) {
+	fee, size := setFeePerByte(ts)
+	r := modules.TransactionSetRejection{
+		SetID:      modules.TransactionSetID(crypto.HashObject(ts)),
+		Size:       uint64(size),
+		FeePerByte: fee,
+		Time:       time.Now(),
+		Peer:       peer,
+		Reason:     rejectionReason(err),
+		Error:      err.Error(),
+	}
+	for _, txn := range ts {
+		r.TransactionIDs = append(r.TransactionIDs, txn.ID())
+	}
+	tp.recentRejections[tp.rejectionIndex%recentRejectionsSize] = r
+	tp.rejectionIndex++
+}
+
+// RecentRejections returns the transaction sets that were most recently
+// rejected by the transaction pool, newest first.
+func (tp *TransactionPool) RecentRejections() []modules.TransactionSetRejection {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+	n := tp.rejectionIndex
+	if n > recentRejectionsSize {
+		n = recentRejectionsSize
+	}
+	rejections := make([]modules.TransactionSetRejection, 0, n)
+	for i := uint64(1); i <= n; i++ {
+		rejections = append(rejections, tp.recentRejections[(tp.rejectionIndex-i)%recentRejectionsSize])
+	}
+	return rejections
+}
+
+// TransactionSetsInfo returns the transaction sets in the transaction pool,
+// ordered by arrival.
+func (tp *TransactionPool) TransactionSetsInfo() []modules.TransactionSetInfo {
+	tp.mu.RLock()
+	defer tp.mu.RUnlock()
+
+	// Map the objects created by unconfirmed transactions to the transactions
+	// creating them.
+	creators := make(map[ObjectID]types.TransactionID)
+	for _, ts := range tp.transactionSets {
+		for _, txn := range ts {
+			id := txn.ID()
+			for i := range txn.SiacoinOutputs {
+				creators[ObjectID(txn.SiacoinOutputID(uint64(i)))] = id
+			}
+			for i := range txn.FileContracts {
+				creators[ObjectID(txn.FileContractID(uint64(i)))] = id
+			}
+			for i := range txn.SiafundOutputs {
+				creators[ObjectID(txn.SiafundOutputID(uint64(i)))] = id
+			}
+		}
+	}
+
+	// Find the parents of each transaction.
+	parents := make(map[types.TransactionID][]types.TransactionID)
+	children := make(map[types.TransactionID][]types.TransactionID)
+	for _, ts := range tp.transactionSets {
+		for _, txn := range ts {
+			id := txn.ID()
+			var oids []ObjectID
+			for _, sci := range txn.SiacoinInputs {
+				oids = append(oids, ObjectID(sci.ParentID))
+			}
+			for _, fcr := range txn.FileContractRevisions {
+				oids = append(oids, ObjectID(fcr.ParentID))
+			}
+			for _, sp := range txn.StorageProofs {
+				oids = append(oids, ObjectID(sp.ParentID))
+			}
+			for _, sfi := range txn.SiafundInputs {
+				oids = append(oids, ObjectID(sfi.ParentID))
+			}
+			seen := make(map[types.TransactionID]struct{})
+			for _, oid := range oids {
+				parent, exists := creators[oid]
+				if _, dup := seen[parent]; !exists || dup || parent == id {
+					continue
+				}
+				seen[parent] = struct{}{}
+				parents[id] = append(parents[id], parent)
+				children[parent] = append(children[parent], id)
+			}
+		}
+	}
+
+	infos := make([]modules.TransactionSetInfo, 0, len(tp.transactionSets))
+	for setID, ts := range tp.transactionSets {
+		info := modules.TransactionSetInfo{
+			ID: setID,
+		}
+		var size int
+		var heightKnown bool
+		for _, txn := range ts {
+			id := txn.ID()
+			txnSize := txn.MarshalSiaSize()
+			var fees types.Currency
+			for _, fee := range txn.MinerFees {
+				fees = fees.Add(fee)
+			}
+			info.Transactions = append(info.Transactions, modules.UnconfirmedTransactionInfo{
+				ID:       id,
+				Size:     uint64(txnSize),
+				Fees:     fees,
+				Parents:  parents[id],
+				Children: children[id],
+			})
+			info.Fees = info.Fees.Add(fees)
+			size += txnSize
+
+			// The set arrived with its first transaction.
+			height, known := tp.transactionHeights[id]
+			if known && (!heightKnown || height < info.ArrivalHeight) {
+				info.ArrivalHeight = height
+				heightKnown = true
+			}
+			arrival, known := tp.transactionArrivals[id]
+			if known && (info.Arrival.IsZero() || arrival.time.Before(info.Arrival)) {
+				info.Arrival = arrival.time
+				info.Peer = arrival.peer
+			}
+		}
+		info.Size = uint64(size)
+		if size > 0 {
+			info.FeePerByte = info.Fees.Div64(uint64(size))
+		}
+		infos = append(infos, info)
+	}
+	sort.Slice(infos, func(i, j int) bool {
+		if !infos[i].Arrival.Equal(infos[j].Arrival) {
+			return infos[i].Arrival.Before(infos[j].Arrival)
+		}
+		return bytes.Compare(infos[i].ID[:], infos[j].ID[:]) < 0
+	})
+	return infos
+}
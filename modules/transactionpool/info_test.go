@@ -0,0 +1,146 @@
+package transactionpool
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestTransactionSetsInfo checks that the transaction sets in the pool are
+// reported together with the relationships between their transactions.
+func TestTransactionSetsInfo(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	if infos := tpt.tpool.TransactionSetsInfo(); len(infos) != 0 {
+		t.Fatal("expected an empty pool, got", len(infos))
+	}
+
+	// Send two transactions. The second one spends the change of the first
+	// one, so they end up in the same set.
+	var sent []types.TransactionID
+	for i := 0; i < 2; i++ {
+		txns, err := tpt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{})
+		if err != nil {
+			t.Fatal(err)
+		}
+		sent = append(sent, txns[len(txns)-1].ID())
+	}
+
+	tpt.tpool.mu.Lock()
+	height := tpt.tpool.blockHeight
+	tpt.tpool.mu.Unlock()
+	infos := tpt.tpool.TransactionSetsInfo()
+	txnInfos := make(map[types.TransactionID]modules.UnconfirmedTransactionInfo)
+	for _, info := range infos {
+		if info.Arrival.IsZero() || info.Peer != "" || info.ArrivalHeight != height {
+			t.Fatal("unexpected arrival", info.Arrival, info.Peer, info.ArrivalHeight)
+		}
+		if info.Size == 0 || info.FeePerByte.IsZero() || !info.FeePerByte.Equals(info.Fees.Div64(info.Size)) {
+			t.Fatal("unexpected size or fees", info.Size, info.Fees, info.FeePerByte)
+		}
+		var size uint64
+		for _, ti := range info.Transactions {
+			txnInfos[ti.ID] = ti
+			size += ti.Size
+		}
+		if size != info.Size {
+			t.Fatal("set size doesn't match the size of its transactions")
+		}
+	}
+	for _, id := range sent {
+		if _, exists := txnInfos[id]; !exists {
+			t.Fatal("sent transaction is missing from the pool", id)
+		}
+	}
+
+	// Every parent lists its children and vice versa.
+	var relations int
+	for id, ti := range txnInfos {
+		for _, parent := range ti.Parents {
+			relations++
+			found := false
+			for _, child := range txnInfos[parent].Children {
+				found = found || child == id
+			}
+			if !found {
+				t.Fatal("parent doesn't list its child")
+			}
+		}
+	}
+	if relations == 0 {
+		t.Fatal("expected unconfirmed parents")
+	}
+
+	// The sets leave the pool once they are confirmed.
+	if _, err := tpt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if infos := tpt.tpool.TransactionSetsInfo(); len(infos) != 0 {
+		t.Fatal("expected an empty pool, got", len(infos))
+	}
+}
+
+// TestRecentRejections checks that rejected transaction sets are recorded
+// with the reason for their rejection.
+func TestRecentRejections(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	tpt, err := createTpoolTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer tpt.Close()
+
+	// Submit a set with non-standard arbitrary data.
+	nonStandard := []types.Transaction{{ArbitraryData: [][]byte{fastrand.Bytes(32)}}}
+	if err := tpt.tpool.AcceptTransactionSet(nonStandard); err == nil {
+		t.Fatal("expected non-standard set to be rejected")
+	}
+	// Submit a set that spends an output that doesn't exist.
+	var parentID types.SiacoinOutputID
+	fastrand.Read(parentID[:])
+	invalid := []types.Transaction{{SiacoinInputs: []types.SiacoinInput{{ParentID: parentID}}}}
+	if err := tpt.tpool.AcceptTransactionSet(invalid); err == nil {
+		t.Fatal("expected invalid set to be rejected")
+	}
+
+	rejections := tpt.tpool.RecentRejections()
+	if len(rejections) != 2 {
+		t.Fatal("expected 2 rejections, got", len(rejections))
+	}
+	if rejections[0].Reason != modules.RejectionConflict || rejections[0].TransactionIDs[0] != invalid[0].ID() {
+		t.Fatal("unexpected rejection", rejections[0])
+	}
+	if rejections[1].Reason != modules.RejectionNonStandard || rejections[1].TransactionIDs[0] != nonStandard[0].ID() {
+		t.Fatal("unexpected rejection", rejections[1])
+	}
+	if rejections[1].Error == "" || rejections[1].Time.After(rejections[0].Time) {
+		t.Fatal("unexpected rejection", rejections[1])
+	}
+
+	// The ring only keeps the most recent rejections.
+	for i := 0; i < recentRejectionsSize; i++ {
+		txn := types.Transaction{ArbitraryData: [][]byte{fastrand.Bytes(32)}}
+		tpt.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	}
+	rejections = tpt.tpool.RecentRejections()
+	if len(rejections) != recentRejectionsSize {
+		t.Fatal("unexpected number of rejections", len(rejections))
+	}
+	for _, r := range rejections {
+		if r.Reason != modules.RejectionNonStandard {
+			t.Fatal("old rejections weren't overwritten")
+		}
+	}
+}
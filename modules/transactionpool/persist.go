@@ -74,6 +74,15 @@ func (tp *TransactionPool) resetDB(tx *bolt.Tx) error {
 		return err
 	}
 	_, err = tx.CreateBucket(bucketConfirmedTransactions)
+	if err != nil {
+		return err
+	}
+	err = tx.DeleteBucket(bucketBlockFees)
+	if err != nil {
+		return err
+	}
+	tp.recentBlockFees = nil
+	_, err = tx.CreateBucket(bucketBlockFees)
 	return err
 }
 
@@ -132,6 +141,7 @@ func (tp *TransactionPool) initPersist() error {
 		bucketRecentConsensusChange,
 		bucketConfirmedTransactions,
 		bucketFeeMedian,
+		bucketBlockFees,
 	}
 	for _, bucket := range buckets {
 		_, err := tp.dbTx.CreateBucketIfNotExists(bucket)
@@ -178,6 +188,12 @@ func (tp *TransactionPool) initPersist() error {
 		tp.recentMedianFee = mp.RecentMedianFee
 	}
 
+	// Get the fees of the recent blocks.
+	tp.recentBlockFees, err = tp.getBlockFees(tp.dbTx)
+	if err != nil {
+		return build.ExtendErr("unable to load the block fees", err)
+	}
+
 	// Subscribe to the consensus set using the most recent consensus change.
 	go func() {
 		err := tp.consensusSet.ConsensusSetSubscribe(tp, cc, tp.tg.StopChan())
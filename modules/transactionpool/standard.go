@@ -37,6 +37,12 @@ import (
 //		A group of dependent transactions cannot exceed 100kb to limit how
 //		quickly the transaction pool can be filled with new transactions.
 
+var (
+	// errUnrecognizedKeyType is returned if a transaction contains a public
+	// key of an unrecognized type.
+	errUnrecognizedKeyType = errors.New("unrecognized key type in transaction")
+)
+
 // checkUnlockConditions looks at the UnlockConditions and verifies that all
 // public keys are recognized. Unrecognized public keys are automatically
 // accepted as valid by the consnensus set, but rejected by the transaction
@@ -46,7 +52,7 @@ func checkUnlockConditions(uc types.UnlockConditions) error {
 	for _, pk := range uc.PublicKeys {
 		if pk.Algorithm != types.SignatureEntropy &&
 			pk.Algorithm != types.SignatureEd25519 {
-			return errors.New("unrecognized key type in transaction")
+			return errUnrecognizedKeyType
 		}
 	}
 
@@ -105,6 +111,7 @@ func isStandardTransaction(t types.Transaction) (uint64, error) {
 		// Check for a whilelisted prefix.
 		copy(prefix[:], arb)
 		if prefix == modules.PrefixHostAnnouncement ||
+			prefix == modules.PrefixHostAnnouncementV2 ||
 			prefix == modules.PrefixNonSia ||
 			prefix == modules.PrefixFileContractIdentifier {
 			continue
@@ -51,6 +51,7 @@ type (
 		knownObjects        map[ObjectID]modules.TransactionSetID
 		subscriberSets      map[modules.TransactionSetID]*modules.UnconfirmedTransactionSet
 		transactionHeights  map[types.TransactionID]types.BlockHeight
+		transactionArrivals map[types.TransactionID]transactionArrival
 		transactionSets     map[modules.TransactionSetID][]types.Transaction
 		transactionSetDiffs map[modules.TransactionSetID]*modules.ConsensusChange
 		transactionListSize int
@@ -59,6 +60,14 @@ type (
 		blockHeight     types.BlockHeight
 		recentMedians   []types.Currency
 		recentMedianFee types.Currency // SC per byte
+		recentBlockFees []blockFees
+
+		// recentRejections is a ring of the most recently rejected
+		// transaction sets. rejectionIndex is the total number of rejections,
+		// the next rejection is stored at rejectionIndex modulo the size of
+		// the ring.
+		recentRejections [recentRejectionsSize]modules.TransactionSetRejection
+		rejectionIndex   uint64
 
 		// The consensus change index tracks how many consensus changes have
 		// been sent to the transaction pool. When a new subscriber joins the
@@ -103,6 +112,7 @@ func NewCustomTPool(cs modules.ConsensusSet, g modules.Gateway, persistDir strin
 		knownObjects:        make(map[ObjectID]modules.TransactionSetID),
 		subscriberSets:      make(map[modules.TransactionSetID]*modules.UnconfirmedTransactionSet),
 		transactionHeights:  make(map[types.TransactionID]types.BlockHeight),
+		transactionArrivals: make(map[types.TransactionID]transactionArrival),
 		transactionSets:     make(map[modules.TransactionSetID][]types.Transaction),
 		transactionSetDiffs: make(map[modules.TransactionSetID]*modules.ConsensusChange),
 
@@ -147,7 +157,12 @@ func (tp *TransactionPool) FeeEstimation() (min, max types.Currency) {
 	defer tp.tg.Done()
 	tp.mu.Lock()
 	defer tp.mu.Unlock()
+	return tp.feeEstimation()
+}
 
+// feeEstimation returns the minimum and maximum estimated fee per transaction
+// byte.
+func (tp *TransactionPool) feeEstimation() (min, max types.Currency) {
 	// Use three methods to determine an acceptable fee. The first method looks
 	// at what fee is required to get into a block on the blockchain based on
 	// the actual fees of transactions confirmed in recent blocks. The second
@@ -167,6 +167,7 @@ func (tp *TransactionPool) purge() {
 	tp.transactionSets = make(map[modules.TransactionSetID][]types.Transaction)
 	tp.transactionSetDiffs = make(map[modules.TransactionSetID]*modules.ConsensusChange)
 	tp.transactionHeights = make(map[types.TransactionID]types.BlockHeight)
+	tp.transactionArrivals = make(map[types.TransactionID]transactionArrival)
 	tp.transactionListSize = 0
 }
 
@@ -204,6 +205,10 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 		recentID = block.ParentID
 
+		err := tp.revertBlockFees(tp.dbTx, tp.blockHeight)
+		if err != nil {
+			tp.log.Println("ERROR: could not revert the block fees:", err)
+		}
 		if tp.blockHeight > 0 || block.ID() != types.GenesisID {
 			tp.blockHeight--
 		}
@@ -241,6 +246,13 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 			}
 		}
 
+		// Record the fees of this block and how long its transactions waited
+		// in the pool.
+		err := tp.applyBlockFees(tp.dbTx, block, tp.blockHeight)
+		if err != nil {
+			tp.log.Println("ERROR: could not record the block fees:", err)
+		}
+
 		// Find the median transaction fee for this block.
 		type feeSummary struct {
 			fee  types.Currency
@@ -356,8 +368,9 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 		unconfirmedSets = append(unconfirmedSets, newTSet)
 	}
-	// Save all of the old transaction heights.
+	// Save all of the old transaction heights and arrivals.
 	oldHeights := tp.transactionHeights
+	oldArrivals := tp.transactionArrivals
 
 	// Purge the transaction pool. Some of the transactions sets may be invalid
 	// after the consensus change.
@@ -365,6 +378,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	// Prune transaction sets where all transactions have hit the max
 	// transaction age.
+	var expiredSets [][]types.Transaction
 	for i, tSet := range unconfirmedSets {
 		// Check whether all transactions in this transaction set are old.
 		old := true
@@ -385,6 +399,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		// All of the transactions in this set are old, this set should be
 		// evicted.
 		if old {
+			expiredSets = append(expiredSets, tSet)
 			unconfirmedSets[i] = []types.Transaction{}
 			for _, txn := range tSet {
 				tp.log.Debugln("Dropping a transaction because it has reached the MaxTransactionAge", txn.ID())
@@ -393,6 +408,11 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 	}
 
+	err = tp.addExpiredFees(tp.dbTx, expiredSets)
+	if err != nil {
+		tp.log.Println("ERROR: could not record the fees of expired transactions:", err)
+	}
+
 	// Scan through the reverted blocks and re-add any transactions that got
 	// reverted to the tpool.
 	addTransactionsBackTime := time.Now()
@@ -407,7 +427,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 			}
 
 			// Try adding the transaction back into the transaction pool.
-			tp.acceptTransactionSet([]types.Transaction{txn}, cc.TryTransactionSet) // Error is ignored.
+			tp.acceptTransactionSet([]types.Transaction{txn}, cc.TryTransactionSet, false) // Error is ignored.
 		}
 	}
 
@@ -427,7 +447,7 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 	// more rules need to be put in place.
 	for _, set := range unconfirmedSets {
 		for _, txn := range set {
-			tp.acceptTransactionSet([]types.Transaction{txn}, cc.TryTransactionSet) // Error is ignored.
+			tp.acceptTransactionSet([]types.Transaction{txn}, cc.TryTransactionSet, false) // Error is ignored.
 			// acceptTransactionSet will set the transaction height to the
 			// current height because of the purge mechanism. Reset the height
 			// to the original height before the purge.
@@ -435,6 +455,17 @@ func (tp *TransactionPool) ProcessConsensusChange(cc modules.ConsensusChange) {
 		}
 	}
 
+	// Restore the arrivals of the transactions that are back in the pool.
+	// Transactions of reverted blocks arrive again.
+	for _, set := range tp.transactionSets {
+		for _, txn := range set {
+			if arrival, exists := oldArrivals[txn.ID()]; exists {
+				tp.transactionArrivals[txn.ID()] = arrival
+			}
+		}
+		tp.recordArrivals(set, "")
+	}
+
 	// Log the size of the transaction pool following an integration of the
 	// block, this will tell us if all of the transactions have been consumed or
 	// not.
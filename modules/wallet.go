@@ -2,6 +2,7 @@ package modules
 
 import (
 	"bytes"
+	"encoding/json"
 	"errors"
 	"fmt"
 	"regexp"
@@ -27,6 +28,36 @@ const (
 	WalletDir = "wallet"
 )
 
+const (
+	// PaymentRequestPending indicates that nothing has been paid yet.
+	PaymentRequestPending PaymentRequestStatus = "pending"
+
+	// PaymentRequestPartial indicates that less than the requested amount
+	// has been paid.
+	PaymentRequestPartial PaymentRequestStatus = "partial"
+
+	// PaymentRequestComplete indicates that at least the requested amount
+	// has been paid.
+	PaymentRequestComplete PaymentRequestStatus = "complete"
+
+	// PaymentRequestExpired indicates that the request expired before the
+	// requested amount was paid.
+	PaymentRequestExpired PaymentRequestStatus = "expired"
+)
+
+const (
+	// SignerMethodAddresses is the JSON-RPC method of a wallet signer that
+	// returns the unlock conditions of a range of the signer's addresses.
+	// Its params are SignerAddressesParams and its result is a
+	// []types.UnlockConditions.
+	SignerMethodAddresses = "signer_addresses"
+
+	// SignerMethodSignTransaction is the JSON-RPC method of a wallet signer
+	// that signs a transaction. Its params are SignerSignTransactionParams and
+	// its result is the signed types.Transaction.
+	SignerMethodSignTransaction = "signer_signTransaction"
+)
+
 var (
 	// ErrBadEncryptionKey is returned if the incorrect encryption key to a
 	// file is provided.
@@ -118,16 +149,183 @@ type (
 	}
 
 	// A UnspentOutput is a SiacoinOutput or SiafundOutput that the wallet
-	// is tracking.
+	// is tracking. LockHeight is the timelock of the output's address; the
+	// output can't be spent before that height.
 	UnspentOutput struct {
 		ID                 types.OutputID    `json:"id"`
 		FundType           types.Specifier   `json:"fundtype"`
 		UnlockHash         types.UnlockHash  `json:"unlockhash"`
 		Value              types.Currency    `json:"value"`
 		ConfirmationHeight types.BlockHeight `json:"confirmationheight"`
+		LockHeight         types.BlockHeight `json:"lockheight"`
 		IsWatchOnly        bool              `json:"iswatchonly"`
 	}
 
+	// CoinControl controls how a transaction that sends siacoins is funded.
+	CoinControl struct {
+		// Inputs are the confirmed outputs of the wallet to spend. All of
+		// them are spent. If no inputs are provided, the wallet selects them.
+		Inputs []types.SiacoinOutputID `json:"inputs"`
+		// SpendAll spends all of the inputs, or all spendable outputs of the
+		// wallet if no inputs are provided, and sends their value minus the
+		// fee to the only output of the transaction.
+		SpendAll bool `json:"spendall"`
+		// ChangeAddress receives the change. It may be a watch-only address.
+		// If it isn't set, a new address of the wallet is used.
+		ChangeAddress types.UnlockHash `json:"changeaddress"`
+		// Fee is the exact fee of the transaction. FeePerByte is multiplied
+		// by the size of the transaction instead. If neither is set, the fee
+		// is the maximum estimate of the transaction pool, or, if FeeTarget
+		// is set, the fee needed to confirm within FeeTarget blocks.
+		Fee        types.Currency    `json:"fee"`
+		FeePerByte types.Currency    `json:"feeperbyte"`
+		FeeTarget  types.BlockHeight `json:"feetarget"`
+		// DryRun returns the unsigned transaction without broadcasting it or
+		// reserving its inputs. A dry run works while the wallet is locked
+		// and doesn't hand out a new address, so if ChangeAddress isn't set
+		// the change is sent to the empty address as a placeholder.
+		DryRun bool `json:"dryrun"`
+		// Account is the name of the account that funds the transaction and
+		// receives the change. If it isn't set, the primary account is used.
+		Account string `json:"account"`
+	}
+
+	// FeeBreakdown describes how the inputs of a transaction are split
+	// between its outputs, change and fee.
+	FeeBreakdown struct {
+		Inputs     types.Currency `json:"inputs"`
+		Outputs    types.Currency `json:"outputs"`
+		Change     types.Currency `json:"change"`
+		Fee        types.Currency `json:"fee"`
+		FeePerByte types.Currency `json:"feeperbyte"`
+		Size       uint64         `json:"size"`
+	}
+
+	// WalletAccount is a named sub-account of the wallet. The addresses of an
+	// account are derived from the primary seed, separately from the
+	// addresses of the primary account, which has an empty name. The outputs
+	// of an account are only spent by transactions funded from that account.
+	//
+	// Index is the index the account was derived at, and Addresses the number
+	// of addresses that were handed out. For the primary account, Index is
+	// always zero.
+	WalletAccount struct {
+		Name      string `json:"name"`
+		Index     uint64 `json:"index"`
+		Addresses uint64 `json:"addresses"`
+
+		ConfirmedSiacoinBalance     types.Currency `json:"confirmedsiacoinbalance"`
+		ConfirmedSiafundBalance     types.Currency `json:"confirmedsiafundbalance"`
+		UnconfirmedOutgoingSiacoins types.Currency `json:"unconfirmedoutgoingsiacoins"`
+		UnconfirmedIncomingSiacoins types.Currency `json:"unconfirmedincomingsiacoins"`
+	}
+
+	// AddressLabel is a label attached to an address by the user.
+	AddressLabel struct {
+		Address types.UnlockHash `json:"address"`
+		Label   string           `json:"label"`
+	}
+
+	// TransactionNote is a free-text note attached to a transaction by the
+	// user.
+	TransactionNote struct {
+		TransactionID types.TransactionID `json:"transactionid"`
+		Note          string              `json:"note"`
+	}
+
+	// PaymentRequestStatus describes how much of a payment request has been
+	// paid.
+	PaymentRequestStatus string
+
+	// A PaymentRequest is an expected payment of Amount to a fresh address of
+	// the wallet. Payments are counted towards the request if they are
+	// confirmed no later than the expiry height.
+	PaymentRequest struct {
+		Address      types.UnlockHash      `json:"address"`
+		Amount       types.Currency        `json:"amount"`
+		Label        string                `json:"label"`
+		CreateHeight types.BlockHeight     `json:"createheight"`
+		ExpiryHeight types.BlockHeight     `json:"expiryheight"`
+		Received     types.Currency        `json:"received"`
+		Status       PaymentRequestStatus  `json:"status"`
+		Transactions []types.TransactionID `json:"transactions"`
+	}
+
+	// A ScheduledPayment sends Amount to Destination once the blockchain
+	// reaches Height. Recurring payments are repeated every Interval blocks
+	// until Count payments were made, or indefinitely if Count is zero.
+	ScheduledPayment struct {
+		ID          string            `json:"id"`
+		Destination types.UnlockHash  `json:"destination"`
+		Amount      types.Currency    `json:"amount"`
+		Height      types.BlockHeight `json:"height"`
+		Interval    types.BlockHeight `json:"interval"`
+		Count       uint64            `json:"count"`
+
+		// Payments is the number of payments made so far, and LastTransaction
+		// the transaction of the most recent one. LastError is set if the
+		// most recent attempt to pay failed, in which case it is retried at
+		// the next block.
+		Payments        uint64              `json:"payments"`
+		LastTransaction types.TransactionID `json:"lasttransaction"`
+		LastError       string              `json:"lasterror"`
+	}
+
+	// WalletSigner describes the external signer of the wallet. If a signer
+	// is configured, the wallet funds transactions from the watched addresses
+	// of the signer and asks the signer to sign them, even while it is
+	// locked.
+	WalletSigner struct {
+		// Address is the URL of the signer, either http(s)://host:port or
+		// unix:///path/to/socket. A password in the URL is used for HTTP
+		// basic auth and is never returned by the wallet.
+		Address string `json:"address"`
+		// Addresses is the number of addresses of the signer that the wallet
+		// watches.
+		Addresses uint64 `json:"addresses"`
+	}
+
+	// SignerRequest is a JSON-RPC 2.0 request to a wallet signer.
+	SignerRequest struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      uint64          `json:"id"`
+		Method  string          `json:"method"`
+		Params  json.RawMessage `json:"params"`
+	}
+
+	// SignerResponse is a JSON-RPC 2.0 response of a wallet signer. Either
+	// Result or Error is set.
+	SignerResponse struct {
+		JSONRPC string          `json:"jsonrpc"`
+		ID      uint64          `json:"id"`
+		Result  json.RawMessage `json:"result,omitempty"`
+		Error   *SignerError    `json:"error,omitempty"`
+	}
+
+	// SignerError is the error of a failed JSON-RPC request to a wallet
+	// signer.
+	SignerError struct {
+		Code    int    `json:"code"`
+		Message string `json:"message"`
+	}
+
+	// SignerAddressesParams are the params of SignerMethodAddresses. They
+	// select the N addresses starting at key index Start.
+	SignerAddressesParams struct {
+		Start uint64 `json:"start"`
+		N     uint64 `json:"n"`
+	}
+
+	// SignerSignTransactionParams are the params of
+	// SignerMethodSignTransaction. ToSign contains the parent IDs of the
+	// inputs to sign, whose signatures are already present in the
+	// transaction but not yet filled in.
+	SignerSignTransactionParams struct {
+		Transaction types.Transaction `json:"transaction"`
+		ToSign      []crypto.Hash     `json:"tosign"`
+		Height      types.BlockHeight `json:"height"`
+	}
+
 	// TransactionBuilder is used to construct custom transactions. A transaction
 	// builder is initialized via 'RegisterTransaction' and then can be modified by
 	// adding funds or other fields. The transaction is completed by calling
@@ -378,10 +576,19 @@ type (
 		// primary seed.
 		NextAddress() (types.UnlockConditions, error)
 
+		// NextTimelockedAddress returns a new address generated from the
+		// primary seed whose outputs can't be spent before the timelock
+		// height.
+		NextTimelockedAddress(timelock types.BlockHeight) (types.UnlockConditions, error)
+
 		// NextAddresses returns n new coin addresses generated from the primary
 		// seed.
 		NextAddresses(uint64) ([]types.UnlockConditions, error)
 
+		// NextAccountAddress returns a new coin address of the named account.
+		// An empty name refers to the primary account.
+		NextAccountAddress(name string) (types.UnlockConditions, error)
+
 		// PrimarySeed returns the unencrypted primary seed of the wallet,
 		// along with a uint64 indicating how many addresses may be safely
 		// generated from the seed.
@@ -426,6 +633,10 @@ type (
 		// refund transactions.
 		ConfirmedBalance() (siacoinBalance types.Currency, siafundBalance types.Currency, siacoinClaimBalance types.Currency, err error)
 
+		// LockedBalance returns the part of the confirmed siacoin balance
+		// that is held by time-locked outputs which can't be spent yet.
+		LockedBalance() (types.Currency, error)
+
 		// UnconfirmedBalance returns the unconfirmed balance of the wallet.
 		// Outgoing funds and incoming funds are reported separately. Refund
 		// outputs are included, meaning that sending a single coin to
@@ -483,6 +694,34 @@ type (
 		// RegisterTransaction(types.Transaction{}, nil)
 		StartTransaction() (TransactionBuilder, error)
 
+		// RegisterAccountTransaction is like RegisterTransaction, but the
+		// returned TransactionBuilder funds the transaction from the named
+		// account and sends the change back to it.
+		RegisterAccountTransaction(name string, t types.Transaction, parents []types.Transaction) (TransactionBuilder, error)
+
+		// StartAccountTransaction is a convenience method that calls
+		// RegisterAccountTransaction(name, types.Transaction{}, nil)
+		StartAccountTransaction(name string) (TransactionBuilder, error)
+
+		// CreateAccount creates a named sub-account of the wallet.
+		CreateAccount(name string) (WalletAccount, error)
+
+		// RenameAccount changes the name of an account.
+		RenameAccount(name, newName string) error
+
+		// Accounts returns the accounts of the wallet with their balances,
+		// starting with the primary account.
+		Accounts() ([]WalletAccount, error)
+
+		// ConfirmedAccountBalance returns the confirmed siacoin balance of
+		// the named account, which is the amount the account can fund.
+		ConfirmedAccountBalance(name string) (types.Currency, error)
+
+		// AccountTransactions returns the transactions related to the
+		// addresses of the named account. Confirmed transactions are followed
+		// by unconfirmed transactions.
+		AccountTransactions(name string) ([]ProcessedTransaction, error)
+
 		// SendSiacoins is a tool for sending siacoins from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and
@@ -492,6 +731,49 @@ type (
 		// SendSiacoinsMulti sends coins to multiple addresses.
 		SendSiacoinsMulti(outputs []types.SiacoinOutput) ([]types.Transaction, error)
 
+		// SendSiacoinsCoinControl sends coins to multiple addresses, using the
+		// inputs, change address and fee chosen by the caller. In a dry run,
+		// the unsigned transaction is returned without being broadcast.
+		SendSiacoinsCoinControl(outputs []types.SiacoinOutput, cc CoinControl) (types.Transaction, FeeBreakdown, error)
+
+		// BumpTransactionFee raises the fee of an unconfirmed transaction of
+		// the wallet, either with a child transaction that pays for it or by
+		// replacing it with a copy that pays the higher fee.
+		BumpTransactionFee(txid types.TransactionID, feePerByte types.Currency, replace bool) (types.Transaction, FeeBreakdown, error)
+
+		// SetAddressLabel attaches a label to an address. An empty label
+		// removes the label of the address.
+		SetAddressLabel(addr types.UnlockHash, label string) error
+
+		// AddressLabels returns the labels of all labeled addresses.
+		AddressLabels() ([]AddressLabel, error)
+
+		// SetTransactionNote attaches a note to a transaction. An empty note
+		// removes the note of the transaction.
+		SetTransactionNote(txid types.TransactionID, note string) error
+
+		// TransactionNotes returns the notes of all transactions with a note.
+		TransactionNotes() ([]TransactionNote, error)
+
+		// CreatePaymentRequest requests a payment of amount to a fresh
+		// address of the wallet that expires after the given number of
+		// blocks. The address is labeled with the label of the request.
+		CreatePaymentRequest(amount types.Currency, expiry types.BlockHeight, label string) (PaymentRequest, error)
+
+		// PaymentRequests returns all payment requests of the wallet.
+		PaymentRequests() ([]PaymentRequest, error)
+
+		// SchedulePayment adds a payment to the queue of scheduled payments
+		// and returns it with its ID.
+		SchedulePayment(sp ScheduledPayment) (ScheduledPayment, error)
+
+		// ScheduledPayments returns the queue of scheduled payments.
+		ScheduledPayments() ([]ScheduledPayment, error)
+
+		// CancelScheduledPayment removes a payment from the queue of
+		// scheduled payments.
+		CancelScheduledPayment(id string) error
+
 		// SendSiafunds is a tool for sending siafunds from the wallet to an
 		// address. Sending money usually results in multiple transactions. The
 		// transactions are automatically given to the transaction pool, and
@@ -512,6 +794,37 @@ type (
 		// WatchAddresses returns the set of addresses that the wallet is
 		// currently watching.
 		WatchAddresses() ([]types.UnlockHash, error)
+
+		// CreateMultisigAddress creates an address that requires 'required'
+		// signatures from the provided public keys and adds it to the watch
+		// set. The unused flag has the same meaning as in AddWatchAddresses.
+		CreateMultisigAddress(pks []types.SiaPublicKey, required uint64, unused bool) (types.UnlockConditions, error)
+
+		// FundMultisigTransaction creates an unsigned transaction that sends
+		// the provided outputs from a watched multisig address.
+		FundMultisigTransaction(addr types.UnlockHash, outputs []types.SiacoinOutput) (PartiallySignedTransaction, error)
+
+		// SignMultisigTransaction adds the wallet's share of signatures to a
+		// partially signed transaction.
+		SignMultisigTransaction(pst PartiallySignedTransaction) (PartiallySignedTransaction, error)
+
+		// BroadcastMultisigTransaction submits a partially signed transaction
+		// to the transaction pool once every input has enough signatures.
+		BroadcastMultisigTransaction(pst PartiallySignedTransaction) (types.Transaction, error)
+
+		// SetSigner configures the external signer of the wallet. An empty
+		// address removes the signer. While a signer is configured, siacoins
+		// are sent from the addresses of the signer instead of the addresses
+		// of the wallet's seeds, and the wallet doesn't have to be unlocked.
+		SetSigner(address string) error
+
+		// Signer returns the external signer of the wallet.
+		Signer() (WalletSigner, error)
+
+		// AddSignerAddresses fetches the next n addresses from the signer and
+		// adds them to the watch set. The unused flag has the same meaning as
+		// in AddWatchAddresses.
+		AddSignerAddresses(n uint64, unused bool) ([]types.UnlockConditions, error)
 	}
 
 	// WalletSettings control the behavior of the Wallet.
@@ -526,6 +839,11 @@ func CalculateWalletTransactionID(tid types.TransactionID, oid types.OutputID) W
 	return WalletTransactionID(crypto.HashAll(tid, oid))
 }
 
+// Error implements the error interface.
+func (e *SignerError) Error() string {
+	return fmt.Sprintf("signer error %v: %v", e.Code, e.Message)
+}
+
 // SeedToString converts a wallet seed to a human friendly string.
 func SeedToString(seed Seed, did mnemonics.DictionaryID) (string, error) {
 	fullChecksum := crypto.HashObject(seed)
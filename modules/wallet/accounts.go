@@ -0,0 +1,583 @@
+package wallet
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errAccountExists is returned if an account is created or renamed with
+	// the name of an existing account.
+	errAccountExists = errors.New("an account with that name already exists")
+
+	// errAccountWithSigner is returned if a transaction is funded from an
+	// account while an external signer is configured.
+	errAccountWithSigner = errors.New("accounts can't be used while an external signer is configured")
+
+	// errInvalidAccountName is returned if an account name is empty, too long
+	// or contains characters other than letters, digits, '-' and '_'.
+	errInvalidAccountName = fmt.Errorf("account names must consist of 1 to %v letters, digits, '-' or '_'", maxAccountNameLength)
+
+	// errUnknownAccount is returned if an account doesn't exist.
+	errUnknownAccount = errors.New("unknown account")
+
+	// accountNameRegexp matches the valid account names.
+	accountNameRegexp = regexp.MustCompile(`^[a-zA-Z0-9_-]+$`)
+
+	// accountSeedSpecifier is used to derive the seeds of the accounts from
+	// the primary seed.
+	accountSeedSpecifier = types.NewSpecifier("account")
+)
+
+// walletAccount records how an account was derived from the primary seed and
+// how many of its addresses were handed out.
+type walletAccount struct {
+	Index    uint64
+	Progress uint64
+}
+
+// accountSeed derives the seed of the account at index from the primary seed.
+// The keys of the account are generated from this seed like the keys of the
+// primary seed, so the account can be scanned for with a seedScanner.
+func accountSeed(primarySeed modules.Seed, index uint64) modules.Seed {
+	return modules.Seed(crypto.HashAll(primarySeed, accountSeedSpecifier, index))
+}
+
+// discoveredAccountName is the name given to an account that was found while
+// recovering the wallet from its seed. Account names are not part of the
+// seed, so they can't be recovered.
+func discoveredAccountName(index uint64) string {
+	return fmt.Sprintf("account-%v", index)
+}
+
+// validateAccountName checks that name is a valid name for a new account.
+func validateAccountName(name string) error {
+	if len(name) > maxAccountNameLength || !accountNameRegexp.MatchString(name) {
+		return errInvalidAccountName
+	}
+	return nil
+}
+
+// checkAccount returns an error if there is no account with the given name.
+// The empty name refers to the primary account, which always exists.
+func (w *Wallet) checkAccount(tx *bolt.Tx, name string) error {
+	if name == "" {
+		return nil
+	}
+	if _, err := dbGetAccount(tx, name); err != nil {
+		return errUnknownAccount
+	}
+	return nil
+}
+
+// integrateAccountKeys loads the keys of an account into the wallet, starting
+// at index start and ending accountLookahead keys beyond the progress of the
+// account. The addresses are persisted so that they are known to belong to
+// the account while the wallet is locked.
+func (w *Wallet) integrateAccountKeys(tx *bolt.Tx, name string, wa walletAccount, start uint64) error {
+	end := wa.Progress + accountLookahead
+	if start >= end {
+		return nil
+	}
+	for _, sk := range generateKeys(accountSeed(w.primarySeed, wa.Index), start, end-start) {
+		uh := sk.UnlockConditions.UnlockHash()
+		w.keys[uh] = sk
+		w.accountKeys[uh] = name
+		if err := dbPutAccountAddress(tx, uh, wa.Index); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// integrateAccounts loads the keys of all accounts into the wallet.
+func (w *Wallet) integrateAccounts(tx *bolt.Tx) error {
+	accounts := make(map[string]walletAccount)
+	err := dbForEachAccount(tx, func(name string, wa walletAccount) {
+		accounts[name] = wa
+	})
+	if err != nil {
+		return err
+	}
+	for name, wa := range accounts {
+		if err := w.integrateAccountKeys(tx, name, wa, 0); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadAccountAddresses loads the persisted addresses of the accounts, which
+// lets a locked wallet compute the balances of its accounts.
+func (w *Wallet) loadAccountAddresses(tx *bolt.Tx) error {
+	names := make(map[uint64]string)
+	err := dbForEachAccount(tx, func(name string, wa walletAccount) {
+		names[wa.Index] = name
+	})
+	if err != nil {
+		return err
+	}
+	return dbForEachAccountAddress(tx, func(uh types.UnlockHash, index uint64) {
+		if name, ok := names[index]; ok {
+			w.accountKeys[uh] = name
+		}
+	})
+}
+
+// accountKeyIndex identifies a key of an account by the index of the account
+// and the index of the key within the account.
+type accountKeyIndex struct {
+	account uint64
+	index   uint64
+}
+
+// An accountScanner tracks the addresses of the accounts derived from a seed
+// while a seedScanner scans the blockchain. Accounts are derived at increasing
+// indices, so the scanned accounts have to extend accountGapLimit accounts
+// beyond the largest account that was used.
+type accountScanner struct {
+	addresses   map[types.UnlockHash]accountKeyIndex
+	largestSeen map[uint64]uint64 // largest key index seen per used account
+	numAccounts uint64
+	numIndices  uint64
+	seed        modules.Seed
+}
+
+// newAccountScanner returns an accountScanner for the first
+// 2*accountGapLimit accounts of primarySeed and the first accountLookahead
+// keys of each account.
+func newAccountScanner(primarySeed modules.Seed) (*accountScanner, error) {
+	s := &accountScanner{
+		addresses:   make(map[types.UnlockHash]accountKeyIndex),
+		largestSeen: make(map[uint64]uint64),
+		seed:        primarySeed,
+	}
+	return s, s.generateKeys(2*accountGapLimit, accountLookahead)
+}
+
+// generateKeys extends the scanner to the first numAccounts accounts and the
+// first numIndices keys of each account.
+func (s *accountScanner) generateKeys(numAccounts, numIndices uint64) error {
+	if numAccounts*numIndices > maxScanKeys {
+		return errMaxKeys
+	}
+	for account := uint64(0); account < numAccounts; account++ {
+		start := uint64(0)
+		if account < s.numAccounts {
+			start = s.numIndices
+		}
+		if start >= numIndices {
+			continue
+		}
+		for i, sk := range generateKeys(accountSeed(s.seed, account), start, numIndices-start) {
+			s.addresses[sk.UnlockConditions.UnlockHash()] = accountKeyIndex{
+				account: account,
+				index:   start + uint64(i),
+			}
+		}
+	}
+	s.numAccounts, s.numIndices = numAccounts, numIndices
+	return nil
+}
+
+// largestAccountSeen returns the largest index of a used account and whether
+// any account was used.
+func (s *accountScanner) largestAccountSeen() (largest uint64, used bool) {
+	for account := range s.largestSeen {
+		if !used || account > largest {
+			largest, used = account, true
+		}
+	}
+	return largest, used
+}
+
+// accountsDone reports whether the scanned accounts extend accountGapLimit
+// accounts beyond the largest used account.
+func (s *accountScanner) accountsDone() bool {
+	largest, used := s.largestAccountSeen()
+	return !used || largest+accountGapLimit < s.numAccounts
+}
+
+// indicesDone reports whether the scanned keys of every used account cover
+// all of its used keys.
+func (s *accountScanner) indicesDone() bool {
+	for _, largest := range s.largestSeen {
+		if largest >= s.numIndices/2 {
+			return false
+		}
+	}
+	return true
+}
+
+// done reports whether the scan found all of the used accounts and keys.
+func (s *accountScanner) done() bool {
+	return s.accountsDone() && s.indicesDone()
+}
+
+// grow doubles the number of scanned accounts or keys per account if the scan
+// isn't done yet.
+func (s *accountScanner) grow() error {
+	numAccounts, numIndices := s.numAccounts, s.numIndices
+	if !s.accountsDone() {
+		numAccounts *= 2
+	}
+	if !s.indicesDone() {
+		numIndices *= 2
+	}
+	return s.generateKeys(numAccounts, numIndices)
+}
+
+// processConsensusChange records the keys of the accounts that received
+// siacoins or siafunds.
+func (s *accountScanner) processConsensusChange(cc modules.ConsensusChange) {
+	var uhs []types.UnlockHash
+	for _, diff := range cc.SiacoinOutputDiffs {
+		uhs = append(uhs, diff.SiacoinOutput.UnlockHash)
+	}
+	for _, diff := range cc.SiafundOutputDiffs {
+		uhs = append(uhs, diff.SiafundOutput.UnlockHash)
+	}
+	for _, uh := range uhs {
+		aki, exists := s.addresses[uh]
+		if !exists {
+			continue
+		}
+		if largest, used := s.largestSeen[aki.account]; !used || aki.index > largest {
+			s.largestSeen[aki.account] = aki.index
+		}
+	}
+}
+
+// accounts returns the used accounts, ordered by their index. The progress of
+// an account gets the same 10% buffer as the primary seed.
+func (s *accountScanner) accounts() []walletAccount {
+	accounts := make([]walletAccount, 0, len(s.largestSeen))
+	for account, largest := range s.largestSeen {
+		progress := largest + 1
+		progress += progress / 10
+		accounts = append(accounts, walletAccount{
+			Index:    account,
+			Progress: progress,
+		})
+	}
+	sort.Slice(accounts, func(i, j int) bool {
+		return accounts[i].Index < accounts[j].Index
+	})
+	return accounts
+}
+
+// nextAccountAddress fetches the next address of the named account. The empty
+// name refers to the primary account.
+func (w *Wallet) nextAccountAddress(tx *bolt.Tx, name string) (types.UnlockConditions, error) {
+	if name == "" {
+		return w.nextPrimarySeedAddress(tx)
+	}
+	if !w.unlocked {
+		return types.UnlockConditions{}, modules.ErrLockedWallet
+	}
+	wa, err := dbGetAccount(tx, name)
+	if err != nil {
+		return types.UnlockConditions{}, errUnknownAccount
+	}
+	sk := generateSpendableKey(accountSeed(w.primarySeed, wa.Index), wa.Progress)
+	wa.Progress++
+	if err := dbPutAccount(tx, name, wa); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	// Extend the lookahead of the account by the key that was handed out.
+	if err := w.integrateAccountKeys(tx, name, wa, wa.Progress+accountLookahead-1); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	return sk.UnlockConditions, nil
+}
+
+// CreateAccount creates a named sub-account of the wallet. The keys of the
+// account are derived from the primary seed, so recovering the wallet from its
+// seed restores the account, although under the name "account-<index>".
+func (w *Wallet) CreateAccount(name string) (modules.WalletAccount, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.WalletAccount{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if err := validateAccountName(name); err != nil {
+		return modules.WalletAccount{}, err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.WalletAccount{}, modules.ErrLockedWallet
+	}
+	if _, err := dbGetAccount(w.dbTx, name); err == nil {
+		return modules.WalletAccount{}, errAccountExists
+	}
+
+	// Recovered accounts may have gaps between their indices, so the next
+	// index follows the largest index in use rather than the number of
+	// accounts.
+	var wa walletAccount
+	err := dbForEachAccount(w.dbTx, func(_ string, existing walletAccount) {
+		if existing.Index >= wa.Index {
+			wa.Index = existing.Index + 1
+		}
+	})
+	if err != nil {
+		return modules.WalletAccount{}, err
+	}
+	if err := dbPutAccount(w.dbTx, name, wa); err != nil {
+		return modules.WalletAccount{}, err
+	}
+	if err := w.integrateAccountKeys(w.dbTx, name, wa, 0); err != nil {
+		return modules.WalletAccount{}, err
+	}
+	if err := w.syncDB(); err != nil {
+		return modules.WalletAccount{}, err
+	}
+	return modules.WalletAccount{
+		Name:  name,
+		Index: wa.Index,
+	}, nil
+}
+
+// RenameAccount changes the name of an account. The primary account can't be
+// renamed.
+func (w *Wallet) RenameAccount(name, newName string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if err := validateAccountName(newName); err != nil {
+		return err
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	wa, err := dbGetAccount(w.dbTx, name)
+	if err != nil {
+		return errUnknownAccount
+	}
+	if _, err := dbGetAccount(w.dbTx, newName); err == nil {
+		return errAccountExists
+	}
+	if err := dbDeleteAccount(w.dbTx, name); err != nil {
+		return err
+	}
+	if err := dbPutAccount(w.dbTx, newName, wa); err != nil {
+		return err
+	}
+	for uh, account := range w.accountKeys {
+		if account == name {
+			w.accountKeys[uh] = newName
+		}
+	}
+	return w.syncDB()
+}
+
+// Accounts returns the accounts of the wallet with their balances, starting
+// with the primary account. The other accounts are ordered by index.
+func (w *Wallet) Accounts() ([]modules.WalletAccount, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	// dustThreshold has to be obtained separate from the lock
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	progress, err := dbGetPrimarySeedProgress(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	accounts := map[string]*modules.WalletAccount{
+		"": {Addresses: progress},
+	}
+	err = dbForEachAccount(w.dbTx, func(name string, wa walletAccount) {
+		accounts[name] = &modules.WalletAccount{
+			Name:      name,
+			Index:     wa.Index,
+			Addresses: wa.Progress,
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	// Outputs of addresses that don't belong to an account, such as watched
+	// addresses, count towards the primary account like they count towards
+	// the balance of the wallet.
+	err = dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(dustThreshold) > 0 {
+			wa := accounts[w.accountKeys[sco.UnlockHash]]
+			wa.ConfirmedSiacoinBalance = wa.ConfirmedSiacoinBalance.Add(sco.Value)
+		}
+	})
+	if err != nil {
+		return nil, err
+	}
+	err = dbForEachSiafundOutput(w.dbTx, func(_ types.SiafundOutputID, sfo types.SiafundOutput) {
+		wa := accounts[w.accountKeys[sfo.UnlockHash]]
+		wa.ConfirmedSiafundBalance = wa.ConfirmedSiafundBalance.Add(sfo.Value)
+	})
+	if err != nil {
+		return nil, err
+	}
+	for _, upt := range w.unconfirmedProcessedTransactions {
+		for _, input := range upt.Inputs {
+			if input.FundType == types.SpecifierSiacoinInput && input.WalletAddress {
+				wa := accounts[w.accountKeys[input.RelatedAddress]]
+				wa.UnconfirmedOutgoingSiacoins = wa.UnconfirmedOutgoingSiacoins.Add(input.Value)
+			}
+		}
+		for _, output := range upt.Outputs {
+			if output.FundType == types.SpecifierSiacoinOutput && output.WalletAddress && output.Value.Cmp(dustThreshold) > 0 {
+				wa := accounts[w.accountKeys[output.RelatedAddress]]
+				wa.UnconfirmedIncomingSiacoins = wa.UnconfirmedIncomingSiacoins.Add(output.Value)
+			}
+		}
+	}
+
+	was := make([]modules.WalletAccount, 0, len(accounts))
+	for _, wa := range accounts {
+		was = append(was, *wa)
+	}
+	sort.Slice(was, func(i, j int) bool {
+		if was[i].Name == "" || was[j].Name == "" {
+			return was[i].Name == ""
+		}
+		return was[i].Index < was[j].Index
+	})
+	return was, nil
+}
+
+// ConfirmedAccountBalance returns the confirmed siacoin balance of the named
+// account. The empty name refers to the primary account.
+func (w *Wallet) ConfirmedAccountBalance(name string) (balance types.Currency, err error) {
+	if err := w.tg.Add(); err != nil {
+		return types.ZeroCurrency, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	// dustThreshold has to be obtained separate from the lock
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return types.ZeroCurrency, modules.ErrWalletShutdown
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.checkAccount(w.dbTx, name); err != nil {
+		return types.ZeroCurrency, err
+	}
+	err = dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(dustThreshold) > 0 && w.accountKeys[sco.UnlockHash] == name {
+			balance = balance.Add(sco.Value)
+		}
+	})
+	return balance, err
+}
+
+// AccountTransactions returns the transactions related to the addresses of the
+// named account. Confirmed transactions are followed by unconfirmed
+// transactions.
+func (w *Wallet) AccountTransactions(name string) (pts []modules.ProcessedTransaction, err error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	// ensure durability of reported transactions
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err = w.syncDB(); err != nil {
+		return
+	}
+	if err := w.checkAccount(w.dbTx, name); err != nil {
+		return nil, err
+	}
+
+	relevant := func(pt modules.ProcessedTransaction) bool {
+		for _, input := range pt.Inputs {
+			if input.WalletAddress && w.accountKeys[input.RelatedAddress] == name {
+				return true
+			}
+		}
+		for _, output := range pt.Outputs {
+			if output.WalletAddress && w.accountKeys[output.RelatedAddress] == name {
+				return true
+			}
+		}
+		return false
+	}
+	it := dbProcessedTransactionsIterator(w.dbTx)
+	for it.next() {
+		if pt := it.value(); relevant(pt) {
+			pts = append(pts, pt)
+		}
+	}
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		if relevant(pt) {
+			pts = append(pts, pt)
+		}
+	}
+	return pts, nil
+}
+
+// NextAccountAddress returns an unlock hash of the named account that is ready
+// to receive siacoins or siafunds. The empty name refers to the primary
+// account.
+func (w *Wallet) NextAccountAddress(name string) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	uc, err := w.nextAccountAddress(w.dbTx, name)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	return uc, w.syncDB()
+}
+
+// RegisterAccountTransaction is like RegisterTransaction, but the returned
+// transaction builder funds the transaction from the named account and sends
+// the change back to it.
+func (w *Wallet) RegisterAccountTransaction(name string, t types.Transaction, parents []types.Transaction) (modules.TransactionBuilder, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if err := w.checkAccount(w.dbTx, name); err != nil {
+		return nil, err
+	}
+	tb := w.registerTransaction(t, parents)
+	tb.account = name
+	return tb, nil
+}
+
+// StartAccountTransaction is a convenience function that calls
+// RegisterAccountTransaction(name, types.Transaction{}, nil).
+func (w *Wallet) StartAccountTransaction(name string) (modules.TransactionBuilder, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, err
+	}
+	defer w.tg.Done()
+	return w.RegisterAccountTransaction(name, types.Transaction{}, nil)
+}
@@ -0,0 +1,350 @@
+package wallet
+
+import (
+	"path/filepath"
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+	"gitlab.com/NebulousLabs/fastrand"
+)
+
+// accountByName returns the account with the given name from the accounts of
+// the wallet.
+func accountByName(t *testing.T, w *Wallet, name string) modules.WalletAccount {
+	t.Helper()
+	accounts, err := w.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, wa := range accounts {
+		if wa.Name == name {
+			return wa
+		}
+	}
+	t.Fatal("account not found", name)
+	return modules.WalletAccount{}
+}
+
+// TestAccounts tests that the balances of accounts are kept separate from
+// the primary account.
+func TestAccounts(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if _, err := wt.wallet.CreateAccount("no spaces"); !errors.Contains(err, errInvalidAccountName) {
+		t.Fatal("expected errInvalidAccountName, got", err)
+	}
+	wa, err := wt.wallet.CreateAccount("hosting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.CreateAccount("hosting"); !errors.Contains(err, errAccountExists) {
+		t.Fatal("expected errAccountExists, got", err)
+	}
+	if _, err := wt.wallet.NextAccountAddress("storage"); !errors.Contains(err, errUnknownAccount) {
+		t.Fatal("expected errUnknownAccount, got", err)
+	}
+
+	// Fund the account from the primary account.
+	uc, err := wt.wallet.NextAccountAddress(wa.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(amount, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	wa = accountByName(t, wt.wallet, "hosting")
+	if !wa.ConfirmedSiacoinBalance.Equals(amount) || wa.Addresses != 1 {
+		t.Fatal("unexpected account", wa)
+	}
+	if balance, err := wt.wallet.ConfirmedAccountBalance("hosting"); err != nil || !balance.Equals(amount) {
+		t.Fatal("unexpected account balance", balance, err)
+	}
+	if _, err := wt.wallet.ConfirmedAccountBalance("storage"); !errors.Contains(err, errUnknownAccount) {
+		t.Fatal("expected errUnknownAccount, got", err)
+	}
+	total, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := accountByName(t, wt.wallet, "")
+	if !primary.ConfirmedSiacoinBalance.Add(amount).Equals(total) {
+		t.Fatal("account balances don't add up to the wallet balance")
+	}
+
+	// The primary account doesn't spend the outputs of the account.
+	cc := modules.CoinControl{SpendAll: true}
+	dest := types.SiacoinOutput{UnlockHash: types.UnlockHash{1}}
+	_, fb, err := wt.wallet.SendSiacoinsCoinControl([]types.SiacoinOutput{dest}, modules.CoinControl{SpendAll: true, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fb.Inputs.Equals(primary.ConfirmedSiacoinBalance) {
+		t.Fatal("primary account spends outputs of other accounts", fb.Inputs, primary.ConfirmedSiacoinBalance)
+	}
+
+	// Transactions funded from the account send the change back to it.
+	tb, err := wt.wallet.StartAccountTransaction("hosting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	spent := types.SiacoinPrecision.Mul64(30)
+	if err := tb.FundSiacoins(spent); err != nil {
+		t.Fatal(err)
+	}
+	tb.AddMinerFee(spent)
+	txns, err := tb.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(txns); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	wa = accountByName(t, wt.wallet, "hosting")
+	if !wa.ConfirmedSiacoinBalance.Equals(amount.Sub(spent)) {
+		t.Fatal("unexpected account balance", wa.ConfirmedSiacoinBalance.HumanString())
+	}
+	total, _, _, err = wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if primary = accountByName(t, wt.wallet, ""); !primary.ConfirmedSiacoinBalance.Add(wa.ConfirmedSiacoinBalance).Equals(total) {
+		t.Fatal("account balances don't add up to the wallet balance")
+	}
+	pts, err := wt.wallet.AccountTransactions("hosting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pts) != 3 {
+		t.Fatal("expected the funding, parent and spending transactions, got", len(pts))
+	}
+
+	// Coin control can spend from the account.
+	cc.Account = "hosting"
+	_, fb, err = wt.wallet.SendSiacoinsCoinControl([]types.SiacoinOutput{dest}, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fb.Inputs.Equals(wa.ConfirmedSiacoinBalance) {
+		t.Fatal("unexpected inputs", fb.Inputs.HumanString())
+	}
+
+	// Renaming keeps the keys of the account.
+	if err := wt.wallet.RenameAccount("hosting", "storage"); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	if wa = accountByName(t, wt.wallet, "storage"); wa.Index != 0 || wa.Addresses != 3 || wa.UnconfirmedOutgoingSiacoins.IsZero() {
+		t.Fatal("unexpected account after rename", wa)
+	}
+}
+
+// TestAccountsLocked tests that the balances of the accounts are known after
+// a restart, before the wallet is unlocked.
+func TestAccountsLocked(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	wa, err := wt.wallet.CreateAccount("hosting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	uc, err := wt.wallet.NextAccountAddress(wa.Name)
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(amount, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// Restart the wallet without unlocking it.
+	if err := wt.wallet.Close(); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet, err = New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, modules.WalletDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if balance, err := wt.wallet.ConfirmedAccountBalance("hosting"); err != nil || !balance.Equals(amount) {
+		t.Fatal("unexpected account balance", balance, err)
+	}
+	total, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	primary := accountByName(t, wt.wallet, "")
+	if !primary.ConfirmedSiacoinBalance.Add(amount).Equals(total) {
+		t.Fatal("account balances don't add up to the wallet balance")
+	}
+}
+
+// TestAccountsRecovery tests that the accounts of a wallet are restored when
+// the wallet is recovered from its seed.
+func TestAccountsRecovery(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create four accounts and only use the first and last one.
+	amount := types.SiacoinPrecision.Mul64(100)
+	for _, name := range []string{"hosting", "unused1", "unused2", "storage"} {
+		if _, err := wt.wallet.CreateAccount(name); err != nil {
+			t.Fatal(err)
+		}
+		if strings.HasPrefix(name, "unused") {
+			continue
+		}
+		uc, err := wt.wallet.NextAccountAddress(name)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, err := wt.wallet.SendSiacoins(amount, uc.UnlockHash()); err != nil {
+			t.Fatal(err)
+		}
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(build.TempDir(modules.WalletDir, t.Name()+"1"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.InitFromSeed(nil, seed); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unlock(crypto.NewWalletKey(crypto.HashObject(seed))); err != nil {
+		t.Fatal(err)
+	}
+
+	accounts, err := w.Accounts()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(accounts) != 3 {
+		t.Fatal("expected the primary account and 2 recovered accounts, got", len(accounts))
+	}
+	for i, index := range []uint64{0, 3} {
+		wa := accounts[i+1]
+		if wa.Index != index || wa.Name != discoveredAccountName(index) || !wa.ConfirmedSiacoinBalance.Equals(amount) {
+			t.Fatal("unexpected recovered account", wa)
+		}
+	}
+	if !accounts[0].ConfirmedSiacoinBalance.Equals(accountByName(t, wt.wallet, "").ConfirmedSiacoinBalance) {
+		t.Fatal("unexpected primary account balance", accounts[0].ConfirmedSiacoinBalance.HumanString())
+	}
+
+	// New addresses of a recovered account don't reuse used addresses.
+	uc, err := w.NextAccountAddress(discoveredAccountName(0))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if pts, _ := w.AddressTransactions(uc.UnlockHash()); len(pts) != 0 {
+		t.Fatal("recovered account reused an address")
+	}
+
+	// A new account doesn't reuse the index of a recovered account, even if
+	// there is a gap between the recovered indices.
+	wa, err := w.CreateAccount("hosting")
+	if err != nil {
+		t.Fatal(err)
+	}
+	if wa.Index != 4 {
+		t.Fatal("expected the new account to follow the largest recovered index, got", wa.Index)
+	}
+	if wa = accountByName(t, w, "hosting"); !wa.ConfirmedSiacoinBalance.IsZero() {
+		t.Fatal("new account shares outputs with a recovered account", wa.ConfirmedSiacoinBalance.HumanString())
+	}
+	if wa = accountByName(t, w, discoveredAccountName(3)); !wa.ConfirmedSiacoinBalance.Equals(amount) {
+		t.Fatal("recovered account lost its outputs", wa.ConfirmedSiacoinBalance.HumanString())
+	}
+}
+
+// TestAccountScanner checks that the accountScanner extends the scanned
+// accounts and keys until they cover the used accounts and keys.
+func TestAccountScanner(t *testing.T) {
+	t.Parallel()
+	var seed modules.Seed
+	fastrand.Read(seed[:])
+	s, err := newAccountScanner(seed)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !s.done() || len(s.accounts()) != 0 {
+		t.Fatal("scanner without used accounts should be done")
+	}
+
+	// Use a key in the upper half of an account that is within the gap
+	// limit of the scanned accounts.
+	account := uint64(2*accountGapLimit - accountGapLimit/2)
+	index := accountLookahead - 1
+	uh := generateSpendableKey(accountSeed(seed, account), index).UnlockConditions.UnlockHash()
+	s.processConsensusChange(modules.ConsensusChange{
+		SiacoinOutputDiffs: []modules.SiacoinOutputDiff{{
+			Direction:     modules.DiffApply,
+			SiacoinOutput: types.SiacoinOutput{UnlockHash: uh},
+		}},
+	})
+	if s.accountsDone() || s.indicesDone() {
+		t.Fatal("scanner should scan more accounts and keys")
+	}
+	if err := s.grow(); err != nil {
+		t.Fatal(err)
+	}
+	if !s.done() {
+		t.Fatal("scanner should be done after growing")
+	}
+	if s.numAccounts != 4*accountGapLimit || s.numIndices != 2*accountLookahead {
+		t.Fatal("unexpected number of accounts and keys", s.numAccounts, s.numIndices)
+	}
+	accounts := s.accounts()
+	progress := index + 1
+	progress += progress / 10
+	if len(accounts) != 1 || accounts[0].Index != account || accounts[0].Progress != progress {
+		t.Fatal("unexpected accounts", accounts)
+	}
+}
@@ -0,0 +1,293 @@
+package wallet
+
+import (
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errBumpNotNeeded is returned if a transaction already pays the
+	// requested fee.
+	errBumpNotNeeded = errors.New("transaction already pays the requested fee")
+
+	// errBumpOutputTooSmall is returned if the output that should pay for a
+	// fee bump can't cover the additional fee.
+	errBumpOutputTooSmall = errors.New("output of the wallet is too small to pay the additional fee")
+
+	// errNoBumpableOutput is returned if a transaction has no unspent output
+	// of the wallet that could pay for a fee bump.
+	errNoBumpableOutput = errors.New("transaction has no unspent output of the wallet to pay the additional fee")
+
+	// errReplaceForeignInputs is returned if a transaction that spends inputs
+	// that don't belong to the wallet is replaced.
+	errReplaceForeignInputs = errors.New("only transactions that exclusively spend siacoin outputs of the wallet can be replaced")
+
+	// errReplaceSpentOutputs is returned if a transaction whose outputs are
+	// spent by other unconfirmed transactions is replaced.
+	errReplaceSpentOutputs = errors.New("transaction has unconfirmed children and can't be replaced")
+
+	// errUnknownUnconfirmedTransaction is returned if a transaction that
+	// isn't an unconfirmed transaction of the wallet is bumped.
+	errUnknownUnconfirmedTransaction = errors.New("transaction is not an unconfirmed transaction of the wallet")
+)
+
+// unconfirmedTransaction returns the unconfirmed processed transaction with
+// the given id.
+func (w *Wallet) unconfirmedTransaction(txid types.TransactionID) (modules.ProcessedTransaction, bool) {
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		if pt.TransactionID == txid {
+			return pt, true
+		}
+	}
+	return modules.ProcessedTransaction{}, false
+}
+
+// transactionFees returns the sum of the miner fees of txn.
+func transactionFees(txn types.Transaction) (fees types.Currency) {
+	for _, fee := range txn.MinerFees {
+		fees = fees.Add(fee)
+	}
+	return fees
+}
+
+// childPaysForParent creates a transaction that spends the largest unspent
+// output of the wallet created by the unconfirmed transactions of set, paying
+// enough fees for the set and the child to reach feePerByte together. The
+// child is signed and its input is marked as spent.
+func (w *Wallet) childPaysForParent(set []types.Transaction, feePerByte, dustThreshold types.Currency) (types.Transaction, modules.FeeBreakdown, error) {
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	pending := make(map[types.OutputID]struct{})
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		for _, input := range pt.Inputs {
+			pending[input.ParentID] = struct{}{}
+		}
+	}
+
+	// Pick the largest output of the wallet that isn't spent yet.
+	var input types.SiacoinInput
+	var value types.Currency
+	var setFee types.Currency
+	for _, parent := range set {
+		setFee = setFee.Add(transactionFees(parent))
+		for i, sco := range parent.SiacoinOutputs {
+			spendKey, ok := w.keys[sco.UnlockHash]
+			if !ok || sco.Value.Cmp(value) <= 0 {
+				continue
+			}
+			id := parent.SiacoinOutputID(uint64(i))
+			if _, ok := pending[types.OutputID(id)]; ok {
+				continue
+			}
+			if _, err := dbGetSpentOutput(w.dbTx, types.OutputID(id)); err == nil {
+				continue
+			}
+			input = types.SiacoinInput{
+				ParentID:         id,
+				UnlockConditions: spendKey.UnlockConditions,
+			}
+			value = sco.Value
+		}
+	}
+	if value.IsZero() {
+		return types.Transaction{}, modules.FeeBreakdown{}, errNoBumpableOutput
+	}
+
+	// The size is estimated with the fee set to the value of the input, which
+	// is an upper bound of its encoded size.
+	child := types.Transaction{
+		SiacoinInputs:  []types.SiacoinInput{input},
+		SiacoinOutputs: []types.SiacoinOutput{{Value: value}},
+		MinerFees:      []types.Currency{value},
+	}
+	target := feePerByte.Mul64(uint64(len(encoding.Marshal(set))) + estimatedTxnSize(child))
+	if setFee.Cmp(target) >= 0 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errBumpNotNeeded
+	}
+	fee := target.Sub(setFee)
+	if value.Cmp(fee.Add(dustThreshold)) < 0 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errBumpOutputTooSmall
+	}
+	// The child pays back into the account of the output it spends.
+	uc, err := w.nextAccountAddress(w.dbTx, w.accountKeys[input.UnlockConditions.UnlockHash()])
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	child.SiacoinOutputs[0] = types.SiacoinOutput{
+		Value:      value.Sub(fee),
+		UnlockHash: uc.UnlockHash(),
+	}
+	child.MinerFees = []types.Currency{fee}
+
+	addSignatures(&child, types.CoveredFields{WholeTransaction: true}, input.UnlockConditions, crypto.Hash(input.ParentID), w.keys[input.UnlockConditions.UnlockHash()], height)
+	if err := dbPutSpentOutput(w.dbTx, types.OutputID(input.ParentID), height); err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	fb := modules.FeeBreakdown{
+		Inputs:  value,
+		Outputs: child.SiacoinOutputs[0].Value,
+		Fee:     fee,
+		Size:    uint64(len(encoding.Marshal(child))),
+	}
+	fb.FeePerByte = fb.Fee.Div64(fb.Size)
+	return child, fb, nil
+}
+
+// replaceWithHigherFee creates a copy of the transaction that pays feePerByte
+// by taking the additional fee from its last output to the wallet. All of the
+// inputs of the transaction have to belong to the wallet, so that the copy can
+// be signed again.
+func (w *Wallet) replaceWithHigherFee(pt modules.ProcessedTransaction, feePerByte, dustThreshold types.Currency) (types.Transaction, modules.FeeBreakdown, error) {
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	txn := pt.Transaction
+	if len(txn.SiafundInputs) != 0 || len(txn.SiacoinInputs) == 0 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errReplaceForeignInputs
+	}
+	for _, sci := range txn.SiacoinInputs {
+		if _, ok := w.keys[sci.UnlockConditions.UnlockHash()]; !ok {
+			return types.Transaction{}, modules.FeeBreakdown{}, errReplaceForeignInputs
+		}
+	}
+	// Replacing the transaction would evict the transactions that spend its
+	// outputs.
+	for i := range txn.SiacoinOutputs {
+		id := types.OutputID(txn.SiacoinOutputID(uint64(i)))
+		for _, other := range w.unconfirmedProcessedTransactions {
+			for _, input := range other.Inputs {
+				if input.ParentID == id {
+					return types.Transaction{}, modules.FeeBreakdown{}, errReplaceSpentOutputs
+				}
+			}
+		}
+	}
+	change := -1
+	for i, sco := range txn.SiacoinOutputs {
+		if _, ok := w.keys[sco.UnlockHash]; ok {
+			change = i
+		}
+	}
+	if change == -1 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errNoBumpableOutput
+	}
+
+	// The size is estimated with the fee set to the value of the change plus
+	// the old fee, which is an upper bound of its encoded size.
+	txn.SiacoinOutputs = append([]types.SiacoinOutput(nil), txn.SiacoinOutputs...)
+	txn.TransactionSignatures = nil
+	oldFee := transactionFees(txn)
+	txn.MinerFees = []types.Currency{oldFee.Add(txn.SiacoinOutputs[change].Value)}
+	fee := feePerByte.Mul64(estimatedTxnSize(txn))
+	if fee.Cmp(oldFee) <= 0 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errBumpNotNeeded
+	}
+	increase := fee.Sub(oldFee)
+	if txn.SiacoinOutputs[change].Value.Cmp(increase.Add(dustThreshold)) < 0 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errBumpOutputTooSmall
+	}
+	txn.SiacoinOutputs[change].Value = txn.SiacoinOutputs[change].Value.Sub(increase)
+	txn.MinerFees = []types.Currency{fee}
+
+	for _, sci := range txn.SiacoinInputs {
+		spendKey := w.keys[sci.UnlockConditions.UnlockHash()]
+		addSignatures(&txn, types.CoveredFields{WholeTransaction: true}, sci.UnlockConditions, crypto.Hash(sci.ParentID), spendKey, height)
+	}
+	fb := modules.FeeBreakdown{
+		Change: txn.SiacoinOutputs[change].Value,
+		Fee:    fee,
+		Size:   uint64(len(encoding.Marshal(txn))),
+	}
+	for _, input := range pt.Inputs {
+		fb.Inputs = fb.Inputs.Add(input.Value)
+	}
+	for i, sco := range txn.SiacoinOutputs {
+		if i != change {
+			fb.Outputs = fb.Outputs.Add(sco.Value)
+		}
+	}
+	fb.FeePerByte = fb.Fee.Div64(fb.Size)
+	return txn, fb, nil
+}
+
+// BumpTransactionFee raises the fee of an unconfirmed transaction of the
+// wallet to feePerByte, or to the maximum fee estimate of the transaction pool
+// if feePerByte is zero. By default, a child transaction that spends an output
+// of the wallet created by the transaction or its unconfirmed parents pays the
+// additional fee for all of them. If replace
+// is true, the transaction is instead replaced with a copy that pays the
+// higher fee from its change. The new transaction is returned.
+func (w *Wallet) BumpTransactionFee(txid types.TransactionID, feePerByte types.Currency, replace bool) (types.Transaction, modules.FeeBreakdown, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	// dustThreshold, the fee estimate and the parents have to be obtained
+	// separate from the lock
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	if feePerByte.IsZero() {
+		_, feePerByte = w.tpool.FeeEstimation()
+	}
+	_, parents, exists := w.tpool.Transaction(txid)
+	if !exists {
+		return types.Transaction{}, modules.FeeBreakdown{}, errUnknownUnconfirmedTransaction
+	}
+
+	w.mu.Lock()
+	if !w.unlocked {
+		w.mu.Unlock()
+		return types.Transaction{}, modules.FeeBreakdown{}, modules.ErrLockedWallet
+	}
+	pt, exists := w.unconfirmedTransaction(txid)
+	if !exists {
+		w.mu.Unlock()
+		return types.Transaction{}, modules.FeeBreakdown{}, errUnknownUnconfirmedTransaction
+	}
+	var txn types.Transaction
+	var fb modules.FeeBreakdown
+	if replace {
+		txn, fb, err = w.replaceWithHigherFee(pt, feePerByte, dustThreshold)
+	} else {
+		set := append(append([]types.Transaction(nil), parents...), pt.Transaction)
+		txn, fb, err = w.childPaysForParent(set, feePerByte, dustThreshold)
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+
+	txnSet := append([]types.Transaction(nil), parents...)
+	if !replace {
+		txnSet = append(txnSet, pt.Transaction)
+	}
+	txnSet = append(txnSet, txn)
+	accept := w.tpool.AcceptTransactionSet
+	if replace {
+		accept = w.tpool.ReplaceTransactionSet
+	}
+	if err := accept(txnSet); err != nil {
+		if !replace {
+			// Release the output so that it can be spent again.
+			w.mu.Lock()
+			if err := dbDeleteSpentOutput(w.dbTx, types.OutputID(txn.SiacoinInputs[0].ParentID)); err != nil {
+				w.log.Println("WARN: failed to release output of rejected child transaction:", err)
+			}
+			w.mu.Unlock()
+		}
+		w.log.Println("Attempt to bump transaction fee has failed - transaction pool rejected transaction:", err)
+		return types.Transaction{}, modules.FeeBreakdown{}, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Printf("Bumped the fee of transaction %v with transaction %v, paying %v", txid, txn.ID(), fb.Fee.HumanString())
+	return txn, fb, nil
+}
@@ -0,0 +1,85 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestBumpTransactionFee probes replacing a transaction with a higher fee and
+// bumping its fee with a child transaction.
+func TestBumpTransactionFee(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// send a transaction with a tiny fee and a change output
+	dest := []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: types.UnlockHash{1}}}
+	txn, fb, err := wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{Fee: types.NewCurrency64(1)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fb.Change.IsZero() {
+		t.Fatal("expected a change output")
+	}
+
+	// unknown transactions can't be bumped
+	_, _, err = wt.wallet.BumpTransactionFee(types.TransactionID{1}, types.ZeroCurrency, false)
+	if err != errUnknownUnconfirmedTransaction {
+		t.Fatal("expected errUnknownUnconfirmedTransaction, got", err)
+	}
+
+	// replace the transaction
+	feePerByte := types.NewCurrency64(1000)
+	replacement, rfb, err := wt.wallet.BumpTransactionFee(txn.ID(), feePerByte, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rfb.Fee.Cmp(feePerByte.Mul64(rfb.Size)) < 0 || !rfb.Change.Add(rfb.Fee).Equals(fb.Change.Add(fb.Fee)) {
+		t.Fatal("unexpected fee breakdown of the replacement", rfb)
+	}
+	if _, _, exists := wt.tpool.Transaction(txn.ID()); exists {
+		t.Fatal("replaced transaction is still in the transaction pool")
+	}
+	if _, _, exists := wt.tpool.Transaction(replacement.ID()); !exists {
+		t.Fatal("replacement isn't in the transaction pool")
+	}
+	// the replacement already pays the requested fee
+	_, _, err = wt.wallet.BumpTransactionFee(replacement.ID(), feePerByte, true)
+	if err != errBumpNotNeeded {
+		t.Fatal("expected errBumpNotNeeded, got", err)
+	}
+
+	// let a child pay for the replacement
+	child, cfb, err := wt.wallet.BumpTransactionFee(replacement.ID(), feePerByte.Mul64(2), false)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(child.SiacoinInputs) != 1 || child.SiacoinInputs[0].ParentID != replacement.SiacoinOutputID(1) {
+		t.Fatal("expected the child to spend the change of the replacement")
+	}
+	if cfb.Fee.IsZero() || !cfb.Inputs.Equals(cfb.Outputs.Add(cfb.Fee)) {
+		t.Fatal("unexpected fee breakdown of the child", cfb)
+	}
+	// the replacement can't be replaced anymore without evicting the child
+	_, _, err = wt.wallet.BumpTransactionFee(replacement.ID(), feePerByte.Mul64(4), true)
+	if err != errReplaceSpentOutputs {
+		t.Fatal("expected errReplaceSpentOutputs, got", err)
+	}
+
+	// both should be confirmed by the next block
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	for _, id := range []types.TransactionID{replacement.ID(), child.ID()} {
+		if confirmed, err := wt.tpool.TransactionConfirmed(id); err != nil || !confirmed {
+			t.Fatal("transaction wasn't confirmed", id, err)
+		}
+	}
+}
@@ -0,0 +1,328 @@
+package wallet
+
+import (
+	"sort"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errFeeAndFeePerByte is returned if both an exact fee and a fee per byte
+	// are requested.
+	errFeeAndFeePerByte = errors.New("cannot specify both a fee and a fee per byte")
+
+	// errFeeTarget is returned if a fee target is requested together with an
+	// exact fee or a fee per byte.
+	errFeeTarget = errors.New("cannot specify a fee target together with a fee or a fee per byte")
+
+	// errNoOutputs is returned if a transaction without outputs is funded.
+	errNoOutputs = errors.New("no outputs to send")
+
+	// errSpendAllOutputs is returned if all inputs are spent but the
+	// transaction doesn't have exactly one output to receive them.
+	errSpendAllOutputs = errors.New("spending all inputs requires exactly one output")
+)
+
+// estimatedTxnSize returns the encoded size of txn once each of its siacoin
+// inputs is signed.
+func estimatedTxnSize(txn types.Transaction) uint64 {
+	txn.TransactionSignatures = nil
+	for _, sci := range txn.SiacoinInputs {
+		for i := uint64(0); i < sci.UnlockConditions.SignaturesRequired; i++ {
+			txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+				ParentID:       crypto.Hash(sci.ParentID),
+				CoveredFields:  types.CoveredFields{WholeTransaction: true},
+				PublicKeyIndex: i,
+				Signature:      make([]byte, crypto.SignatureSize),
+			})
+		}
+	}
+	return uint64(len(encoding.Marshal(txn)))
+}
+
+// coinControlInputs returns the outputs that a coin-controlled transaction may
+// spend. Inputs requested by the caller must be confirmed and spendable by the
+// wallet, while selected inputs also must not be dust. All inputs must belong
+// to the account of the coin control. If remote is set, the outputs are spent
+// through the signer instead of the wallet's keys.
+func (w *Wallet) coinControlInputs(cc modules.CoinControl, height types.BlockHeight, dustThreshold types.Currency, remote bool) (so sortedOutputs, err error) {
+	checkOutput := func(tx *bolt.Tx, height types.BlockHeight, id types.SiacoinOutputID, output types.SiacoinOutput, dustThreshold types.Currency) error {
+		return w.checkAccountOutput(tx, height, id, output, dustThreshold, cc.Account)
+	}
+	if remote {
+		checkOutput = w.checkSignerOutput
+	}
+	pending := make(map[types.OutputID]struct{})
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		for _, input := range pt.Inputs {
+			if input.WalletAddress {
+				pending[input.ParentID] = struct{}{}
+			}
+		}
+	}
+
+	if len(cc.Inputs) == 0 {
+		err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+			if _, ok := pending[types.OutputID(scoid)]; ok {
+				return
+			}
+			if checkOutput(w.dbTx, height, scoid, sco, dustThreshold) != nil {
+				return
+			}
+			so.ids = append(so.ids, scoid)
+			so.outputs = append(so.outputs, sco)
+		})
+		sort.Sort(sort.Reverse(so))
+		return so, err
+	}
+
+	seen := make(map[types.SiacoinOutputID]struct{})
+	for _, id := range cc.Inputs {
+		if _, ok := seen[id]; ok {
+			return sortedOutputs{}, errors.New("output " + id.String() + " was requested more than once")
+		}
+		seen[id] = struct{}{}
+		sco, err := dbGetSiacoinOutput(w.dbTx, id)
+		if err != nil {
+			return sortedOutputs{}, errors.New("output " + id.String() + " is not a confirmed output of the wallet")
+		}
+		if _, ok := pending[types.OutputID(id)]; ok {
+			return sortedOutputs{}, errors.New("output " + id.String() + " is spent by an unconfirmed transaction")
+		}
+		if err := checkOutput(w.dbTx, height, id, sco, types.ZeroCurrency); err != nil {
+			return sortedOutputs{}, errors.AddContext(err, "cannot spend output "+id.String())
+		}
+		so.ids = append(so.ids, id)
+		so.outputs = append(so.outputs, sco)
+	}
+	return so, nil
+}
+
+// fundCoinControl creates a transaction that sends the outputs according to
+// the coin control. Unless it's a dry run, the transaction is signed and its
+// inputs are marked as spent. A dry run has no side effects, so it also works
+// while the wallet is locked and its change output is a placeholder if no
+// change address is provided. If remote is set, the transaction is funded from
+// the signer's addresses and left for the signer to sign.
+func (w *Wallet) fundCoinControl(outputs []types.SiacoinOutput, cc modules.CoinControl, feePerByte, dustThreshold types.Currency, remote bool) (types.Transaction, modules.FeeBreakdown, error) {
+	// A dry run only needs the public keys of the wallet, which stay loaded
+	// once the wallet was unlocked, and the signer signs for a locked wallet.
+	if !w.unlocked && !((cc.DryRun || remote) && w.subscribed) {
+		return types.Transaction{}, modules.FeeBreakdown{}, modules.ErrLockedWallet
+	}
+	if remote && cc.Account != "" {
+		return types.Transaction{}, modules.FeeBreakdown{}, errAccountWithSigner
+	}
+	if err := w.checkAccount(w.dbTx, cc.Account); err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	so, err := w.coinControlInputs(cc, height, dustThreshold, remote)
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+
+	txn := types.Transaction{
+		SiacoinOutputs: append([]types.SiacoinOutput(nil), outputs...),
+	}
+	var fb modules.FeeBreakdown
+	for _, sco := range outputs {
+		fb.Outputs = fb.Outputs.Add(sco.Value)
+	}
+	// The size is estimated with the fee and the value of the outputs set to
+	// the value of the inputs, which is an upper bound of their encoded size.
+	fee := func() types.Currency {
+		if !cc.Fee.IsZero() {
+			return cc.Fee
+		}
+		txn.MinerFees = []types.Currency{fb.Inputs}
+		return feePerByte.Mul64(estimatedTxnSize(txn))
+	}
+	addInput := func(i int) {
+		uc := w.keys[so.outputs[i].UnlockHash].UnlockConditions
+		if remote {
+			uc, _ = dbGetUnlockConditions(w.dbTx, so.outputs[i].UnlockHash)
+		}
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         so.ids[i],
+			UnlockConditions: uc,
+		})
+		fb.Inputs = fb.Inputs.Add(so.outputs[i].Value)
+	}
+	spendAll := cc.SpendAll || len(cc.Inputs) > 0
+	if spendAll {
+		for i := range so.ids {
+			addInput(i)
+		}
+	}
+
+	if cc.SpendAll {
+		// The only output receives everything but the fee.
+		txn.SiacoinOutputs[0].Value = fb.Inputs
+		fb.Fee = fee()
+		if fb.Inputs.Cmp(fb.Fee) <= 0 {
+			return types.Transaction{}, modules.FeeBreakdown{}, modules.ErrLowBalance
+		}
+		txn.SiacoinOutputs[0].Value = fb.Inputs.Sub(fb.Fee)
+		fb.Outputs = txn.SiacoinOutputs[0].Value
+	} else {
+		// Include a change output in the size estimate. It is removed if the
+		// change turns out to be dust.
+		txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{UnlockHash: cc.ChangeAddress})
+		change := &txn.SiacoinOutputs[len(txn.SiacoinOutputs)-1]
+		for i := range so.ids {
+			change.Value = fb.Inputs
+			fb.Fee = fee()
+			if spendAll || fb.Inputs.Cmp(fb.Outputs.Add(fb.Fee)) >= 0 {
+				break
+			}
+			addInput(i)
+		}
+		change.Value = fb.Inputs
+		fb.Fee = fee()
+		if fb.Inputs.Cmp(fb.Outputs.Add(fb.Fee)) < 0 {
+			return types.Transaction{}, modules.FeeBreakdown{}, modules.ErrLowBalance
+		}
+		fb.Change = fb.Inputs.Sub(fb.Outputs).Sub(fb.Fee)
+		if fb.Change.Cmp(dustThreshold) < 0 {
+			// Dust change is added to the fee.
+			txn.SiacoinOutputs = txn.SiacoinOutputs[:len(txn.SiacoinOutputs)-1]
+			fb.Fee = fb.Fee.Add(fb.Change)
+			fb.Change = types.ZeroCurrency
+		} else {
+			change.Value = fb.Change
+			if cc.ChangeAddress == (types.UnlockHash{}) && !cc.DryRun {
+				uc, err := w.nextAccountAddress(w.dbTx, cc.Account)
+				if err != nil {
+					return types.Transaction{}, modules.FeeBreakdown{}, err
+				}
+				change.UnlockHash = uc.UnlockHash()
+			}
+		}
+	}
+	txn.MinerFees = []types.Currency{fb.Fee}
+	fb.Size = estimatedTxnSize(txn)
+	fb.FeePerByte = fb.Fee.Div64(fb.Size)
+
+	if cc.DryRun || remote {
+		// Leave the signatures to be filled in, e.g. by /wallet/sign or the
+		// signer.
+		for _, sci := range txn.SiacoinInputs {
+			addSignerPlaceholders(&txn, types.CoveredFields{WholeTransaction: true}, sci.UnlockConditions, crypto.Hash(sci.ParentID))
+		}
+	}
+	if cc.DryRun {
+		return txn, fb, nil
+	}
+
+	if !remote {
+		for _, sci := range txn.SiacoinInputs {
+			spendKey := w.keys[sci.UnlockConditions.UnlockHash()]
+			addSignatures(&txn, types.CoveredFields{WholeTransaction: true}, sci.UnlockConditions, crypto.Hash(sci.ParentID), spendKey, height)
+		}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		if err := dbPutSpentOutput(w.dbTx, types.OutputID(sci.ParentID), height); err != nil {
+			return types.Transaction{}, modules.FeeBreakdown{}, err
+		}
+	}
+	return txn, fb, nil
+}
+
+// SendSiacoinsCoinControl creates a transaction that sends the outputs using
+// the inputs, change address and fee of the coin control. Unless it's a dry
+// run, the transaction is signed and broadcast.
+func (w *Wallet) SendSiacoinsCoinControl(outputs []types.SiacoinOutput, cc modules.CoinControl) (types.Transaction, modules.FeeBreakdown, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(outputs) == 0 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errNoOutputs
+	}
+	if cc.SpendAll && len(outputs) != 1 {
+		return types.Transaction{}, modules.FeeBreakdown{}, errSpendAllOutputs
+	}
+	if !cc.Fee.IsZero() && !cc.FeePerByte.IsZero() {
+		return types.Transaction{}, modules.FeeBreakdown{}, errFeeAndFeePerByte
+	}
+	if cc.FeeTarget != 0 && (!cc.Fee.IsZero() || !cc.FeePerByte.IsZero()) {
+		return types.Transaction{}, modules.FeeBreakdown{}, errFeeTarget
+	}
+	if !cc.DryRun && (!w.cs.Synced() || w.deps.Disrupt("UnsyncedConsensus")) {
+		return types.Transaction{}, modules.FeeBreakdown{}, errors.New("cannot send siacoin until fully synced")
+	}
+
+	// dustThreshold and the fee estimate have to be obtained separate from
+	// the lock
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	feePerByte := cc.FeePerByte
+	if cc.FeeTarget != 0 {
+		feePerByte = w.tpool.FeeEstimationTarget(cc.FeeTarget, modules.DefaultFeeConfidence).FeePerByte
+	} else if feePerByte.IsZero() {
+		_, feePerByte = w.tpool.FeeEstimation()
+	}
+
+	sc, err := w.managedSignerClient()
+	if err != nil {
+		return types.Transaction{}, modules.FeeBreakdown{}, err
+	}
+	remote := sc != nil
+	if remote && !cc.DryRun && !cc.SpendAll && cc.ChangeAddress == (types.UnlockHash{}) {
+		// The change has to be sent to an address of the signer.
+		ucs, err := w.AddSignerAddresses(1, true)
+		if err != nil {
+			return types.Transaction{}, modules.FeeBreakdown{}, errors.AddContext(err, "unable to get change address")
+		}
+		cc.ChangeAddress = ucs[0].UnlockHash()
+	}
+
+	w.mu.Lock()
+	txn, fb, err := w.fundCoinControl(outputs, cc, feePerByte, dustThreshold, remote)
+	w.mu.Unlock()
+	if err != nil || cc.DryRun {
+		return txn, fb, err
+	}
+
+	if remote {
+		signed, err := w.managedSignWithSigner(sc, txn)
+		if err != nil {
+			w.managedReleaseInputs(txn)
+			return types.Transaction{}, modules.FeeBreakdown{}, err
+		}
+		txn = signed
+	}
+	err = w.tpool.AcceptTransactionSet([]types.Transaction{txn})
+	if err != nil {
+		w.managedReleaseInputs(txn)
+		w.log.Println("Attempt to send coins has failed - transaction pool rejected transaction:", err)
+		return types.Transaction{}, modules.FeeBreakdown{}, build.ExtendErr("unable to get transaction accepted", err)
+	}
+	w.log.Printf("Successfully broadcast coin-controlled transaction with id %v, %v inputs and fee %v", txn.ID(), len(txn.SiacoinInputs), fb.Fee.HumanString())
+	return txn, fb, nil
+}
+
+// managedReleaseInputs releases the inputs of a transaction that wasn't
+// broadcast so that they can be spent again.
+func (w *Wallet) managedReleaseInputs(txn types.Transaction) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	for _, sci := range txn.SiacoinInputs {
+		if err := dbDeleteSpentOutput(w.dbTx, types.OutputID(sci.ParentID)); err != nil {
+			w.log.Println("WARN: failed to release input of rejected transaction:", err)
+		}
+	}
+}
@@ -0,0 +1,186 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestSendSiacoinsCoinControl probes sending siacoins with explicit inputs,
+// change addresses and fees.
+func TestSendSiacoinsCoinControl(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// split the wallet's funds into several outputs
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), uc.UnlockHash())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var scos []modules.UnspentOutput
+	for _, o := range outputs {
+		if o.FundType == types.SpecifierSiacoinOutput && !o.IsWatchOnly {
+			scos = append(scos, o)
+		}
+	}
+	if len(scos) < 2 {
+		t.Fatal("expected at least two outputs")
+	}
+	dest := []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: types.UnlockHash{1}}}
+
+	// conflicting fees are rejected
+	_, _, err = wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{Fee: types.NewCurrency64(1), FeePerByte: types.NewCurrency64(1)})
+	if err != errFeeAndFeePerByte {
+		t.Fatal("expected errFeeAndFeePerByte, got", err)
+	}
+	_, _, err = wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{FeePerByte: types.NewCurrency64(1), FeeTarget: 3})
+	if err != errFeeTarget {
+		t.Fatal("expected errFeeTarget, got", err)
+	}
+	// the target estimate is used if requested
+	_, fb, err := wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{FeeTarget: 3, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	est := wt.tpool.FeeEstimationTarget(3, modules.DefaultFeeConfidence).FeePerByte
+	_, estFb, err := wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{FeePerByte: est, DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fb.Fee.Equals(estFb.Fee) {
+		t.Fatal("fee doesn't match the target estimate", fb.Fee, estFb.Fee)
+	}
+	// unknown inputs are rejected
+	_, _, err = wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{Inputs: []types.SiacoinOutputID{{1}}})
+	if err == nil {
+		t.Fatal("expected unknown input to be rejected")
+	}
+
+	// a dry run with an explicit input and fee returns the unsigned
+	// transaction and doesn't reserve the input
+	input := types.SiacoinOutputID(scos[0].ID)
+	changeAddr := types.UnlockHash{2}
+	cc := modules.CoinControl{
+		Inputs:        []types.SiacoinOutputID{input},
+		ChangeAddress: changeAddr,
+		Fee:           types.SiacoinPrecision.Div64(10),
+		DryRun:        true,
+	}
+	txn, fb, err := wt.wallet.SendSiacoinsCoinControl(dest, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !fb.Inputs.Equals(scos[0].Value) || !fb.Fee.Equals(cc.Fee) || !fb.Inputs.Equals(fb.Outputs.Add(fb.Change).Add(fb.Fee)) {
+		t.Fatal("unexpected fee breakdown", fb)
+	}
+	if len(txn.SiacoinInputs) != 1 || txn.SiacoinInputs[0].ParentID != input {
+		t.Fatal("expected exactly the requested input", txn.SiacoinInputs)
+	}
+	if len(txn.SiacoinOutputs) != 2 || txn.SiacoinOutputs[1].UnlockHash != changeAddr || !txn.SiacoinOutputs[1].Value.Equals(fb.Change) {
+		t.Fatal("expected change to be sent to the change address", txn.SiacoinOutputs)
+	}
+	if len(txn.TransactionSignatures) == 0 || len(txn.TransactionSignatures[0].Signature) != 0 {
+		t.Fatal("expected an unsigned transaction")
+	}
+
+	// send it for real
+	cc.DryRun = false
+	txn, _, err = wt.wallet.SendSiacoinsCoinControl(dest, cc)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if txn.SiacoinInputs[0].ParentID != input || len(txn.TransactionSignatures[0].Signature) == 0 {
+		t.Fatal("expected a signed transaction spending the requested input")
+	}
+	// the input can't be spent twice
+	_, _, err = wt.wallet.SendSiacoinsCoinControl(dest, cc)
+	if err == nil {
+		t.Fatal("expected spent input to be rejected")
+	}
+
+	// spend all of another output with a fee per byte
+	input = types.SiacoinOutputID(scos[1].ID)
+	txn, fb, err = wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{
+		Inputs:     []types.SiacoinOutputID{input},
+		SpendAll:   true,
+		FeePerByte: types.NewCurrency64(1000),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(txn.SiacoinOutputs) != 1 || !fb.Change.IsZero() || !txn.SiacoinOutputs[0].Value.Add(fb.Fee).Equals(scos[1].Value) {
+		t.Fatal("expected the whole input to be sent", txn.SiacoinOutputs, fb)
+	}
+	if fb.Fee.Cmp(types.NewCurrency64(1000).Mul64(fb.Size)) < 0 || fb.FeePerByte.Cmp64(1000) < 0 {
+		t.Fatal("unexpected fee", fb)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestSendSiacoinsCoinControlDryRunLocked checks that a dry run works while
+// the wallet is locked and doesn't hand out a change address.
+func TestSendSiacoinsCoinControlDryRunLocked(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	wt.wallet.mu.Lock()
+	progress, err := dbGetPrimarySeedProgress(wt.wallet.dbTx)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dest := []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: types.UnlockHash{1}}}
+	txn, fb, err := wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{DryRun: true})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if fb.Change.IsZero() || len(txn.SiacoinOutputs) != 2 || txn.SiacoinOutputs[1].UnlockHash != (types.UnlockHash{}) {
+		t.Fatal("expected a placeholder change output", txn.SiacoinOutputs)
+	}
+	wt.wallet.mu.Lock()
+	newProgress, err := dbGetPrimarySeedProgress(wt.wallet.dbTx)
+	wt.wallet.mu.Unlock()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if newProgress != progress {
+		t.Fatal("dry run used up an address of the wallet", progress, newProgress)
+	}
+
+	// sending for real still requires an unlocked wallet
+	_, _, err = wt.wallet.SendSiacoinsCoinControl(dest, modules.CoinControl{})
+	if err != modules.ErrLockedWallet {
+		t.Fatal("expected ErrLockedWallet, got", err)
+	}
+}
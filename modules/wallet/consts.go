@@ -2,9 +2,18 @@ package wallet
 
 import (
 	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/types"
 )
 
 const (
+	// accountGapLimit is the number of consecutive unused accounts after
+	// which the discovery of accounts stops when recovering a wallet from its
+	// seed.
+	accountGapLimit = 20
+
+	// maxAccountNameLength is the maximum length in bytes of an account name.
+	maxAccountNameLength = 64
+
 	// defragBatchSize defines how many outputs are combined during one defrag.
 	defragBatchSize = 35
 
@@ -15,9 +24,47 @@ const (
 	// defragThreshold is the number of outputs a wallet is allowed before it is
 	// defragmented.
 	defragThreshold = 50
+
+	// maxLabelLength is the maximum length in bytes of an address label.
+	maxLabelLength = 256
+
+	// maxNoteLength is the maximum length in bytes of a transaction note.
+	maxNoteLength = 4096
+
+	// timelockLookahead is the number of time-locked address indices that
+	// are scanned for when recovering a wallet from its seed. The scan is
+	// repeated with twice as many indices if an address in the upper half
+	// was used.
+	timelockLookahead = 20
 )
 
 var (
+	// accountLookahead is the number of keys beyond the progress of an
+	// account that are tracked by the wallet.
+	accountLookahead = build.Select(build.Var{
+		Dev:      uint64(100),
+		Standard: uint64(1000),
+		Testing:  uint64(10),
+	}).(uint64)
+
+	// maxTimelockDuration is the maximum number of blocks between the
+	// current height and the timelock of a new time-locked address.
+	maxTimelockDuration = build.Select(build.Var{
+		Dev:      types.BlockHeight(1000),
+		Standard: 5 * types.BlocksPerYear,
+		Testing:  types.BlockHeight(100),
+	}).(types.BlockHeight)
+
+	// timelockGranularity is the granularity of the timelocks of time-locked
+	// addresses. Only timelocks that are a multiple of timelockGranularity
+	// are allowed, which limits the number of addresses that have to be
+	// scanned for when recovering a wallet from its seed.
+	timelockGranularity = build.Select(build.Var{
+		Dev:      types.BlockHeight(10),
+		Standard: types.BlocksPerDay,
+		Testing:  types.BlockHeight(5),
+	}).(types.BlockHeight)
+
 	// lookaheadBuffer together with lookaheadRescanThreshold defines the constant part
 	// of the maxLookahead
 	lookaheadBuffer = build.Select(build.Var{
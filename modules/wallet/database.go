@@ -16,6 +16,19 @@ import (
 )
 
 var (
+	// bucketAccounts maps the name of an account of the wallet to the index
+	// it was derived at and its progress.
+	bucketAccounts = []byte("bucketAccounts")
+	// bucketAccountAddresses maps the UnlockHash of an address of an account
+	// to the index of the account, so that the outputs of the accounts can be
+	// told apart while the wallet is locked.
+	bucketAccountAddresses = []byte("bucketAccountAddresses")
+	// bucketAddressLabels maps an UnlockHash to the label the user attached
+	// to it.
+	bucketAddressLabels = []byte("bucketAddressLabels")
+	// bucketPaymentRequests maps the UnlockHash of a payment request to the
+	// PaymentRequest.
+	bucketPaymentRequests = []byte("bucketPaymentRequests")
 	// bucketProcessedTransactions stores ProcessedTransactions in
 	// chronological order. Only transactions relevant to the wallet are
 	// stored. The key of this bucket is an autoincrementing integer.
@@ -34,11 +47,24 @@ var (
 	// outputs that the wallet controls are stored. The wallet uses these
 	// outputs to fund transactions.
 	bucketSiafundOutputs = []byte("bucketSiafundOutputs")
+	// bucketScheduledPayments maps the ID of a scheduled payment to the
+	// payment.
+	bucketScheduledPayments = []byte("bucketScheduledPayments")
+	// bucketSignerAddresses maps the UnlockHash of an address of the external
+	// signer to its key index. The UnlockConditions of the addresses are
+	// stored in bucketUnlockConditions.
+	bucketSignerAddresses = []byte("bucketSignerAddresses")
 	// bucketSpentOutputs maps an OutputID to the height at which it was
 	// spent. Only outputs spent by the wallet are stored. The wallet tracks
 	// these outputs so that it can reuse them if they are not confirmed on
 	// the blockchain.
 	bucketSpentOutputs = []byte("bucketSpentOutputs")
+	// bucketTimelockedAddresses maps the UnlockHash of a time-locked address
+	// of the wallet to the key index and timelock it was derived from.
+	bucketTimelockedAddresses = []byte("bucketTimelockedAddresses")
+	// bucketTransactionNotes maps a TransactionID to the note the user
+	// attached to it.
+	bucketTransactionNotes = []byte("bucketTransactionNotes")
 	// bucketUnlockConditions maps an UnlockHash to its UnlockConditions. It
 	// is used to track UnlockConditions manually stored by the user,
 	// typically with an offline wallet.
@@ -48,12 +74,20 @@ var (
 	bucketWallet = []byte("bucketWallet")
 
 	dbBuckets = [][]byte{
+		bucketAccounts,
+		bucketAccountAddresses,
+		bucketAddressLabels,
+		bucketPaymentRequests,
 		bucketProcessedTransactions,
 		bucketProcessedTxnIndex,
 		bucketAddrTransactions,
+		bucketScheduledPayments,
 		bucketSiacoinOutputs,
 		bucketSiafundOutputs,
+		bucketSignerAddresses,
 		bucketSpentOutputs,
+		bucketTimelockedAddresses,
+		bucketTransactionNotes,
 		bucketUnlockConditions,
 		bucketWallet,
 	}
@@ -70,6 +104,7 @@ var (
 	keySiafundPool            = []byte("keySiafundPool")
 	keySpendableKeyFiles      = []byte("keySpendableKeyFiles")
 	keySalt                   = []byte("keyUID")
+	keySigner                 = []byte("keySigner")
 	keyWalletPassword         = []byte("keyWalletPassword")
 	keyWatchedAddrs           = []byte("keyWatchedAddrs")
 )
@@ -204,6 +239,10 @@ func dbForEach(b *bolt.Bucket, fn interface{}) error {
 func dbPutSiacoinOutput(tx *bolt.Tx, id types.SiacoinOutputID, output types.SiacoinOutput) error {
 	return dbPut(tx.Bucket(bucketSiacoinOutputs), id, output)
 }
+func dbGetSiacoinOutput(tx *bolt.Tx, id types.SiacoinOutputID) (output types.SiacoinOutput, err error) {
+	err = dbGet(tx.Bucket(bucketSiacoinOutputs), id, &output)
+	return
+}
 func dbDeleteSiacoinOutput(tx *bolt.Tx, id types.SiacoinOutputID) error {
 	return dbDelete(tx.Bucket(bucketSiacoinOutputs), id)
 }
@@ -248,6 +287,93 @@ func dbGetUnlockConditions(tx *bolt.Tx, addr types.UnlockHash) (uc types.UnlockC
 	return
 }
 
+func dbPutAddressLabel(tx *bolt.Tx, addr types.UnlockHash, label string) error {
+	return dbPut(tx.Bucket(bucketAddressLabels), addr, label)
+}
+func dbDeleteAddressLabel(tx *bolt.Tx, addr types.UnlockHash) error {
+	return dbDelete(tx.Bucket(bucketAddressLabels), addr)
+}
+func dbForEachAddressLabel(tx *bolt.Tx, fn func(types.UnlockHash, string)) error {
+	return dbForEach(tx.Bucket(bucketAddressLabels), fn)
+}
+
+func dbPutTransactionNote(tx *bolt.Tx, txid types.TransactionID, note string) error {
+	return dbPut(tx.Bucket(bucketTransactionNotes), txid, note)
+}
+func dbDeleteTransactionNote(tx *bolt.Tx, txid types.TransactionID) error {
+	return dbDelete(tx.Bucket(bucketTransactionNotes), txid)
+}
+func dbForEachTransactionNote(tx *bolt.Tx, fn func(types.TransactionID, string)) error {
+	return dbForEach(tx.Bucket(bucketTransactionNotes), fn)
+}
+
+func dbPutPaymentRequest(tx *bolt.Tx, pr modules.PaymentRequest) error {
+	return dbPut(tx.Bucket(bucketPaymentRequests), pr.Address, pr)
+}
+func dbGetPaymentRequest(tx *bolt.Tx, addr types.UnlockHash) (pr modules.PaymentRequest, err error) {
+	err = dbGet(tx.Bucket(bucketPaymentRequests), addr, &pr)
+	return
+}
+func dbForEachPaymentRequest(tx *bolt.Tx, fn func(types.UnlockHash, modules.PaymentRequest)) error {
+	return dbForEach(tx.Bucket(bucketPaymentRequests), fn)
+}
+
+func dbPutScheduledPayment(tx *bolt.Tx, sp modules.ScheduledPayment) error {
+	return dbPut(tx.Bucket(bucketScheduledPayments), sp.ID, sp)
+}
+func dbGetScheduledPayment(tx *bolt.Tx, id string) (sp modules.ScheduledPayment, err error) {
+	err = dbGet(tx.Bucket(bucketScheduledPayments), id, &sp)
+	return
+}
+func dbDeleteScheduledPayment(tx *bolt.Tx, id string) error {
+	return dbDelete(tx.Bucket(bucketScheduledPayments), id)
+}
+func dbForEachScheduledPayment(tx *bolt.Tx, fn func(string, modules.ScheduledPayment)) error {
+	return dbForEach(tx.Bucket(bucketScheduledPayments), fn)
+}
+
+func dbPutTimelockedAddress(tx *bolt.Tx, addr types.UnlockHash, ta timelockedAddress) error {
+	return dbPut(tx.Bucket(bucketTimelockedAddresses), addr, ta)
+}
+func dbGetTimelockedAddress(tx *bolt.Tx, addr types.UnlockHash) (ta timelockedAddress, err error) {
+	err = dbGet(tx.Bucket(bucketTimelockedAddresses), addr, &ta)
+	return
+}
+func dbForEachTimelockedAddress(tx *bolt.Tx, fn func(types.UnlockHash, timelockedAddress)) error {
+	return dbForEach(tx.Bucket(bucketTimelockedAddresses), fn)
+}
+
+func dbPutAccount(tx *bolt.Tx, name string, wa walletAccount) error {
+	return dbPut(tx.Bucket(bucketAccounts), name, wa)
+}
+func dbGetAccount(tx *bolt.Tx, name string) (wa walletAccount, err error) {
+	err = dbGet(tx.Bucket(bucketAccounts), name, &wa)
+	return
+}
+func dbDeleteAccount(tx *bolt.Tx, name string) error {
+	return dbDelete(tx.Bucket(bucketAccounts), name)
+}
+func dbForEachAccount(tx *bolt.Tx, fn func(string, walletAccount)) error {
+	return dbForEach(tx.Bucket(bucketAccounts), fn)
+}
+
+func dbPutAccountAddress(tx *bolt.Tx, addr types.UnlockHash, index uint64) error {
+	return dbPut(tx.Bucket(bucketAccountAddresses), addr, index)
+}
+func dbForEachAccountAddress(tx *bolt.Tx, fn func(types.UnlockHash, uint64)) error {
+	return dbForEach(tx.Bucket(bucketAccountAddresses), fn)
+}
+
+func dbPutSignerAddress(tx *bolt.Tx, addr types.UnlockHash, index uint64) error {
+	return dbPut(tx.Bucket(bucketSignerAddresses), addr, index)
+}
+func dbIsSignerAddress(tx *bolt.Tx, addr types.UnlockHash) bool {
+	return tx.Bucket(bucketSignerAddresses).Get(encoding.Marshal(addr)) != nil
+}
+func dbForEachSignerAddress(tx *bolt.Tx, fn func(types.UnlockHash, uint64)) error {
+	return dbForEach(tx.Bucket(bucketSignerAddresses), fn)
+}
+
 // dbAddAddrTransaction appends a single transaction index to the set of
 // transactions associated with addr. If the index is already in the set, it is
 // not added again.
@@ -485,6 +611,18 @@ func dbPutSiafundPool(tx *bolt.Tx, pool types.Currency) error {
 	return tx.Bucket(bucketWallet).Put(keySiafundPool, encoding.Marshal(pool))
 }
 
+// dbGetSigner returns the address of the external signer, or the empty string
+// if no signer is configured.
+func dbGetSigner(tx *bolt.Tx) (address string) {
+	encoding.Unmarshal(tx.Bucket(bucketWallet).Get(keySigner), &address)
+	return
+}
+
+// dbPutSigner stores the address of the external signer.
+func dbPutSigner(tx *bolt.Tx, address string) error {
+	return tx.Bucket(bucketWallet).Put(keySigner, encoding.Marshal(address))
+}
+
 // dbPutWatchedAddresses stores the set of watched addresses.
 func dbPutWatchedAddresses(tx *bolt.Tx, addrs []types.UnlockHash) error {
 	return tx.Bucket(bucketWallet).Put(keyWatchedAddrs, encoding.Marshal(addrs))
@@ -256,6 +256,12 @@ func (w *Wallet) managedBlockingUnlock(masterKey crypto.CipherKey) (modules.Cons
 		w.integrateSeed(primarySeed, primarySeedProgress)
 		w.primarySeed = primarySeed
 		w.regenerateLookahead(primarySeedProgress)
+		if err := w.integrateTimelockedKeys(w.dbTx); err != nil {
+			return err
+		}
+		if err := w.integrateAccounts(w.dbTx); err != nil {
+			return err
+		}
 
 		// auxiliarySeedFiles
 		for _, sf := range auxiliarySeedFiles {
@@ -304,43 +310,105 @@ func (w *Wallet) managedBlockingUnlock(masterKey crypto.CipherKey) (modules.Cons
 
 // managedAsyncUnlock handles the async part of hte managedUnlock method.
 func (w *Wallet) managedAsyncUnlock(lastChange modules.ConsensusChangeID) error {
+	// If the wallet subscribed while locked to track the addresses of its
+	// signer, the outputs of its own keys were missed and the blockchain has
+	// to be rescanned.
+	w.mu.Lock()
+	subscribed := w.subscribed
+	rescan := w.subscribedLocked
+	if rescan {
+		err := w.resetScanProgress()
+		if err == nil {
+			err = w.syncDB()
+		}
+		if err != nil {
+			w.mu.Unlock()
+			return fmt.Errorf("failed to reset db for rescan: %v", err)
+		}
+		w.subscribedLocked = false
+	}
+	w.mu.Unlock()
+	if rescan {
+		w.cs.Unsubscribe(w)
+		w.tpool.Unsubscribe(w)
+		subscribed = false
+		lastChange = modules.ConsensusChangeBeginning
+	}
+
 	// Subscribe to the consensus set if this is the first unlock for the
 	// wallet object.
-	w.mu.RLock()
-	subscribed := w.subscribed
-	w.mu.RUnlock()
 	if !subscribed {
-		// Subscription can take a while, so spawn a goroutine to print the
-		// wallet height every few seconds. (If subscription completes
-		// quickly, nothing will be printed.)
-		done := make(chan struct{})
-		go w.rescanMessage(done)
-		defer close(done)
-
-		err := w.cs.ConsensusSetSubscribe(w, lastChange, w.tg.StopChan())
-		if err == modules.ErrInvalidConsensusChangeID {
-			// something went wrong; resubscribe from the beginning
-			err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
-			if err != nil {
-				return fmt.Errorf("failed to reset db during rescan: %v", err)
-			}
-			err = dbPutConsensusHeight(w.dbTx, 0)
-			if err != nil {
-				return fmt.Errorf("failed to reset db during rescan: %v", err)
-			}
-			err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+		return w.managedSubscribe(lastChange)
+	}
+	return nil
+}
+
+// managedSubscribe subscribes the wallet to the consensus set and the
+// transaction pool, starting at lastChange.
+func (w *Wallet) managedSubscribe(lastChange modules.ConsensusChangeID) error {
+	// Subscription can take a while, so spawn a goroutine to print the
+	// wallet height every few seconds. (If subscription completes
+	// quickly, nothing will be printed.)
+	done := make(chan struct{})
+	go w.rescanMessage(done)
+	defer close(done)
+
+	err := w.cs.ConsensusSetSubscribe(w, lastChange, w.tg.StopChan())
+	if err == modules.ErrInvalidConsensusChangeID {
+		// something went wrong; resubscribe from the beginning
+		err = dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning)
+		if err != nil {
+			return fmt.Errorf("failed to reset db during rescan: %v", err)
 		}
+		err = dbPutConsensusHeight(w.dbTx, 0)
 		if err != nil {
-			return fmt.Errorf("wallet subscription failed: %v", err)
+			return fmt.Errorf("failed to reset db during rescan: %v", err)
 		}
-		w.tpool.TransactionPoolSubscribe(w)
+		err = w.cs.ConsensusSetSubscribe(w, modules.ConsensusChangeBeginning, w.tg.StopChan())
+	}
+	if err != nil {
+		return fmt.Errorf("wallet subscription failed: %v", err)
 	}
+	w.tpool.TransactionPoolSubscribe(w)
+
 	w.mu.Lock()
 	w.subscribed = true
 	w.mu.Unlock()
 	return nil
 }
 
+// managedSubscribeWithSigner subscribes a wallet that hasn't been unlocked
+// yet if a signer is configured. This lets a locked or seedless wallet track
+// the outputs of the signer's addresses and spend them through the signer.
+func (w *Wallet) managedSubscribeWithSigner() error {
+	// Don't race with an unlock, which subscribes the wallet anyway.
+	if !w.scanLock.TryLock() {
+		return nil
+	}
+	defer w.scanLock.Unlock()
+
+	w.mu.Lock()
+	if w.subscribed || w.unlocked || dbGetSigner(w.dbTx) == "" {
+		w.mu.Unlock()
+		return nil
+	}
+	// The watched addresses are usually loaded by the unlock, but the
+	// addresses of the signer are among them.
+	var watchedAddrs []types.UnlockHash
+	err := encoding.Unmarshal(w.dbTx.Bucket(bucketWallet).Get(keyWatchedAddrs), &watchedAddrs)
+	if err != nil {
+		w.mu.Unlock()
+		return err
+	}
+	for _, addr := range watchedAddrs {
+		w.watchedAddrs[addr] = struct{}{}
+	}
+	lastChange := dbGetConsensusChangeID(w.dbTx)
+	w.subscribedLocked = true
+	w.mu.Unlock()
+	return w.managedSubscribe(lastChange)
+}
+
 // rescanMessage prints the blockheight every 3 seconds until done is closed.
 func (w *Wallet) rescanMessage(done chan struct{}) {
 	if build.Release == "testing" {
@@ -447,12 +515,14 @@ func (w *Wallet) Reset() error {
 	}
 	w.wipeSecrets()
 	w.keys = make(map[types.UnlockHash]spendableKey)
+	w.accountKeys = make(map[types.UnlockHash]string)
 	w.lookahead = make(map[types.UnlockHash]uint64)
 	w.seeds = []modules.Seed{}
 	w.unconfirmedProcessedTransactions = []modules.ProcessedTransaction{}
 	w.unlocked = false
 	w.encrypted = false
 	w.subscribed = false
+	w.subscribedLocked = false
 
 	return nil
 }
@@ -482,11 +552,22 @@ func (w *Wallet) InitFromSeed(masterKey crypto.CipherKey, seed modules.Seed) err
 	}
 	defer w.scanLock.Unlock()
 
-	// estimate the primarySeedProgress by scanning the blockchain
+	// estimate the primarySeedProgress by scanning the blockchain, and look
+	// for the time-locked addresses and the accounts derived from the seed in
+	// the same pass
 	s := newSeedScanner(seed, w.log)
+	s.timelock, err = newTimelockScanner(seed, w.cs.Height()+maxTimelockDuration)
+	if err != nil {
+		return err
+	}
+	s.accounts, err = newAccountScanner(seed)
+	if err != nil {
+		return err
+	}
 	if err := s.scan(w.cs, w.tg.StopChan()); err != nil {
 		return err
 	}
+	w.log.Printf("INFO: found %v time-locked addresses in blockchain", len(s.timelock.found))
 	// NOTE: each time the wallet generates a key for index n, it sets its
 	// progress to n+1, so the progress should be the largest index seen + 1.
 	// We also add 10% as a buffer because the seed may have addresses in the
@@ -495,11 +576,28 @@ func (w *Wallet) InitFromSeed(masterKey crypto.CipherKey, seed modules.Seed) err
 	progress += progress / 10
 	w.log.Printf("INFO: found key index %v in blockchain. Setting primary seed progress to %v", s.largestIndexSeen, progress)
 
+	accounts := s.accounts.accounts()
+	for _, wa := range accounts {
+		w.log.Printf("INFO: found account %v in blockchain. Setting account progress to %v", wa.Index, wa.Progress)
+	}
+
 	// initialize the wallet with the appropriate seed progress
 	w.mu.Lock()
 	defer w.mu.Unlock()
-	_, err = w.initEncryption(masterKey, seed, progress)
-	return err
+	if _, err = w.initEncryption(masterKey, seed, progress); err != nil {
+		return err
+	}
+	for _, wa := range accounts {
+		if err := dbPutAccount(w.dbTx, discoveredAccountName(wa.Index), wa); err != nil {
+			return err
+		}
+	}
+	for uh, ta := range s.timelock.found {
+		if err := dbPutTimelockedAddress(w.dbTx, uh, ta); err != nil {
+			return err
+		}
+	}
+	return nil
 }
 
 // Unlocked indicates whether the wallet is locked or unlocked.
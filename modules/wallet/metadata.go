@@ -0,0 +1,240 @@
+package wallet
+
+import (
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+var (
+	// errLabelTooLong is returned if an address label exceeds maxLabelLength.
+	errLabelTooLong = errors.New("label is too long")
+
+	// errNoteTooLong is returned if a transaction note exceeds maxNoteLength.
+	errNoteTooLong = errors.New("note is too long")
+
+	// errZeroExpiry is returned if a payment request that expires immediately
+	// is created.
+	errZeroExpiry = errors.New("payment request must expire at least one block in the future")
+
+	// errZeroPaymentAmount is returned if a payment request without an amount
+	// is created.
+	errZeroPaymentAmount = errors.New("payment request must request a nonzero amount")
+)
+
+// paymentRequestStatus returns the status of a payment request at the given
+// height.
+func paymentRequestStatus(pr modules.PaymentRequest, height types.BlockHeight) modules.PaymentRequestStatus {
+	switch {
+	case pr.Received.Cmp(pr.Amount) >= 0:
+		return modules.PaymentRequestComplete
+	case height > pr.ExpiryHeight:
+		return modules.PaymentRequestExpired
+	case !pr.Received.IsZero():
+		return modules.PaymentRequestPartial
+	default:
+		return modules.PaymentRequestPending
+	}
+}
+
+// updatePaymentRequests recomputes the payments received by the payment
+// requests whose addresses appear in the siacoin output diffs of the consensus
+// change. Complete and expired requests can only change if blocks are
+// reverted, so they are skipped otherwise.
+func (w *Wallet) updatePaymentRequests(tx *bolt.Tx, cc modules.ConsensusChange) error {
+	height, err := dbGetConsensusHeight(tx)
+	if err != nil {
+		return err
+	}
+	seen := make(map[types.UnlockHash]struct{})
+	for _, diff := range cc.SiacoinOutputDiffs {
+		addr := diff.SiacoinOutput.UnlockHash
+		if _, ok := seen[addr]; ok {
+			continue
+		}
+		seen[addr] = struct{}{}
+		pr, err := dbGetPaymentRequest(tx, addr)
+		if err == errNoKey {
+			continue
+		} else if err != nil {
+			return err
+		}
+		status := paymentRequestStatus(pr, height)
+		if len(cc.RevertedBlocks) == 0 && (status == modules.PaymentRequestComplete || status == modules.PaymentRequestExpired) {
+			continue
+		}
+		if err := updatePaymentRequest(tx, pr, height); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// updatePaymentRequest recomputes the payments received by a payment request
+// from the confirmed transactions of its address. Recomputing the request
+// from scratch keeps it correct across reorgs.
+func updatePaymentRequest(tx *bolt.Tx, pr modules.PaymentRequest, height types.BlockHeight) error {
+	indices, err := dbGetAddrTransactions(tx, pr.Address)
+	if err != nil && err != errNoKey {
+		return err
+	}
+	pr.Received = types.ZeroCurrency
+	pr.Transactions = nil
+	for _, i := range indices {
+		// The index of a reverted transaction may point to a different
+		// transaction, which is why the outputs are checked again.
+		pt, err := dbGetProcessedTransaction(tx, i)
+		if err != nil || pt.ConfirmationHeight > pr.ExpiryHeight {
+			continue
+		}
+		var paid bool
+		for _, output := range pt.Outputs {
+			if output.FundType == types.SpecifierSiacoinOutput && output.RelatedAddress == pr.Address {
+				pr.Received = pr.Received.Add(output.Value)
+				paid = true
+			}
+		}
+		if paid {
+			pr.Transactions = append(pr.Transactions, pt.TransactionID)
+		}
+	}
+	pr.Status = paymentRequestStatus(pr, height)
+	return dbPutPaymentRequest(tx, pr)
+}
+
+// SetAddressLabel attaches a label to an address. An empty label removes the
+// label of the address.
+func (w *Wallet) SetAddressLabel(addr types.UnlockHash, label string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(label) > maxLabelLength {
+		return errLabelTooLong
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if label == "" {
+		return dbDeleteAddressLabel(w.dbTx, addr)
+	}
+	return dbPutAddressLabel(w.dbTx, addr, label)
+}
+
+// AddressLabels returns the labels of all labeled addresses.
+func (w *Wallet) AddressLabels() ([]modules.AddressLabel, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	labels := []modules.AddressLabel{}
+	err := dbForEachAddressLabel(w.dbTx, func(addr types.UnlockHash, label string) {
+		labels = append(labels, modules.AddressLabel{Address: addr, Label: label})
+	})
+	return labels, err
+}
+
+// SetTransactionNote attaches a note to a transaction. An empty note removes
+// the note of the transaction.
+func (w *Wallet) SetTransactionNote(txid types.TransactionID, note string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(note) > maxNoteLength {
+		return errNoteTooLong
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if note == "" {
+		return dbDeleteTransactionNote(w.dbTx, txid)
+	}
+	return dbPutTransactionNote(w.dbTx, txid, note)
+}
+
+// TransactionNotes returns the notes of all transactions with a note.
+func (w *Wallet) TransactionNotes() ([]modules.TransactionNote, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	notes := []modules.TransactionNote{}
+	err := dbForEachTransactionNote(w.dbTx, func(txid types.TransactionID, note string) {
+		notes = append(notes, modules.TransactionNote{TransactionID: txid, Note: note})
+	})
+	return notes, err
+}
+
+// CreatePaymentRequest requests a payment of amount to a fresh address of the
+// wallet that expires after the given number of blocks. The address is
+// labeled with the label of the request.
+func (w *Wallet) CreatePaymentRequest(amount types.Currency, expiry types.BlockHeight, label string) (modules.PaymentRequest, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PaymentRequest{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if amount.IsZero() {
+		return modules.PaymentRequest{}, errZeroPaymentAmount
+	}
+	if expiry == 0 {
+		return modules.PaymentRequest{}, errZeroExpiry
+	}
+	if len(label) > maxLabelLength {
+		return modules.PaymentRequest{}, errLabelTooLong
+	}
+	uc, err := w.NextAddress()
+	if err != nil {
+		return modules.PaymentRequest{}, errors.AddContext(err, "unable to get a fresh address")
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	pr := modules.PaymentRequest{
+		Address:      uc.UnlockHash(),
+		Amount:       amount,
+		Label:        label,
+		CreateHeight: height,
+		ExpiryHeight: height + expiry,
+		Status:       modules.PaymentRequestPending,
+	}
+	if err := dbPutPaymentRequest(w.dbTx, pr); err != nil {
+		return modules.PaymentRequest{}, err
+	}
+	if label != "" {
+		if err := dbPutAddressLabel(w.dbTx, pr.Address, label); err != nil {
+			return modules.PaymentRequest{}, err
+		}
+	}
+	return pr, nil
+}
+
+// PaymentRequests returns all payment requests of the wallet.
+func (w *Wallet) PaymentRequests() ([]modules.PaymentRequest, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return nil, err
+	}
+	// The requests are only updated when payments to them change, so whether
+	// they expired is determined here.
+	prs := []modules.PaymentRequest{}
+	err = dbForEachPaymentRequest(w.dbTx, func(_ types.UnlockHash, pr modules.PaymentRequest) {
+		pr.Status = paymentRequestStatus(pr, height)
+		prs = append(prs, pr)
+	})
+	return prs, err
+}
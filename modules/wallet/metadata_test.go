@@ -0,0 +1,123 @@
+package wallet
+
+import (
+	"strings"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestWalletMetadata probes address labels and transaction notes.
+func TestWalletMetadata(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	addr := types.UnlockHash{1}
+	if err := wt.wallet.SetAddressLabel(addr, strings.Repeat("a", maxLabelLength+1)); err != errLabelTooLong {
+		t.Fatal("expected errLabelTooLong, got", err)
+	}
+	if err := wt.wallet.SetAddressLabel(addr, "customer"); err != nil {
+		t.Fatal(err)
+	}
+	labels, err := wt.wallet.AddressLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[0].Address != addr || labels[0].Label != "customer" {
+		t.Fatal("unexpected labels", labels)
+	}
+	if err := wt.wallet.SetAddressLabel(addr, ""); err != nil {
+		t.Fatal(err)
+	}
+	if labels, err := wt.wallet.AddressLabels(); err != nil || len(labels) != 0 {
+		t.Fatal("expected label to be removed", labels, err)
+	}
+
+	txid := types.TransactionID{2}
+	if err := wt.wallet.SetTransactionNote(txid, "invoice 42"); err != nil {
+		t.Fatal(err)
+	}
+	notes, err := wt.wallet.TransactionNotes()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(notes) != 1 || notes[0].TransactionID != txid || notes[0].Note != "invoice 42" {
+		t.Fatal("unexpected notes", notes)
+	}
+}
+
+// TestPaymentRequests checks that payment requests track partial, complete
+// and expired payments as blocks are mined.
+func TestPaymentRequests(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	if _, err := wt.wallet.CreatePaymentRequest(types.ZeroCurrency, 10, ""); err != errZeroPaymentAmount {
+		t.Fatal("expected errZeroPaymentAmount, got", err)
+	}
+	pr, err := wt.wallet.CreatePaymentRequest(types.SiacoinPrecision.Mul64(100), 10, "customer")
+	if err != nil {
+		t.Fatal(err)
+	}
+	expiring, err := wt.wallet.CreatePaymentRequest(types.SiacoinPrecision, 1, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	labels, err := wt.wallet.AddressLabels()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(labels) != 1 || labels[0].Address != pr.Address || labels[0].Label != "customer" {
+		t.Fatal("expected the address of the request to be labeled", labels)
+	}
+
+	requests := func() map[types.UnlockHash]modules.PaymentRequest {
+		prs, err := wt.wallet.PaymentRequests()
+		if err != nil {
+			t.Fatal(err)
+		}
+		m := make(map[types.UnlockHash]modules.PaymentRequest)
+		for _, pr := range prs {
+			m[pr.Address] = pr
+		}
+		return m
+	}
+	pay := func(amount types.Currency) {
+		if _, err := wt.wallet.SendSiacoins(amount, pr.Address); err != nil {
+			t.Fatal(err)
+		}
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// pay part of the request
+	pay(types.SiacoinPrecision.Mul64(40))
+	prs := requests()
+	if got := prs[pr.Address]; got.Status != modules.PaymentRequestPartial || !got.Received.Equals(types.SiacoinPrecision.Mul64(40)) || len(got.Transactions) != 1 {
+		t.Fatal("expected a partial payment", got)
+	}
+
+	// pay the rest
+	pay(types.SiacoinPrecision.Mul64(60))
+	prs = requests()
+	if got := prs[pr.Address]; got.Status != modules.PaymentRequestComplete || len(got.Transactions) != 2 {
+		t.Fatal("expected a complete payment", got)
+	}
+	if got := prs[expiring.Address]; got.Status != modules.PaymentRequestExpired {
+		t.Fatal("expected the unpaid request to expire", got)
+	}
+}
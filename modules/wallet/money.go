@@ -119,6 +119,15 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) (txn
 		return nil, errors.New("cannot send siacoin until fully synced")
 	}
 
+	// The signer signs for a locked wallet.
+	output := types.SiacoinOutput{
+		Value:      amount,
+		UnlockHash: dest,
+	}
+	if txns, ok, err := w.managedSendWithSigner([]types.SiacoinOutput{output}); ok {
+		return txns, err
+	}
+
 	w.mu.RLock()
 	unlocked := w.unlocked
 	w.mu.RUnlock()
@@ -129,10 +138,6 @@ func (w *Wallet) SendSiacoins(amount types.Currency, dest types.UnlockHash) (txn
 
 	_, tpoolFee := w.tpool.FeeEstimation()
 	tpoolFee = tpoolFee.Mul64(750) // Estimated transaction size in bytes
-	output := types.SiacoinOutput{
-		Value:      amount,
-		UnlockHash: dest,
-	}
 
 	txnBuilder, err := w.StartTransaction()
 	if err != nil {
@@ -186,6 +191,10 @@ func (w *Wallet) SendSiacoinsMulti(outputs []types.SiacoinOutput) (txns []types.
 		return nil, errors.New("cannot send siacoin until fully synced")
 	}
 
+	// The signer signs for a locked wallet.
+	if txns, ok, err := w.managedSendWithSigner(outputs); ok {
+		return txns, err
+	}
 	w.mu.RLock()
 	unlocked := w.unlocked
 	w.mu.RUnlock()
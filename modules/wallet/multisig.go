@@ -0,0 +1,245 @@
+package wallet
+
+import (
+	"sort"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errNoMultisigKeys is returned when the wallet is asked to sign a
+	// partially signed transaction that it holds no outstanding keys for.
+	errNoMultisigKeys = errors.New("wallet holds no keys that still need to sign the transaction")
+
+	// errUnknownMultisigAddress is returned when a spend is funded from an
+	// address that the wallet doesn't know the unlock conditions of.
+	errUnknownMultisigAddress = errors.New("no record of UnlockConditions for that multisig address")
+
+	// errUnwatchedMultisigAddress is returned when a spend is funded from an
+	// address that the wallet doesn't track the outputs of.
+	errUnwatchedMultisigAddress = errors.New("multisig address is not being watched by the wallet")
+)
+
+// estimatedMultisigTxnSize estimates the size in bytes of a transaction that
+// spends n outputs of a multisig address and creates m outputs.
+func estimatedMultisigTxnSize(uc types.UnlockConditions, n, m int) uint64 {
+	inputSize := 100 + 56*uint64(len(uc.PublicKeys)) + 150*uc.SignaturesRequired
+	return 500 + uint64(n)*inputSize + uint64(m)*60
+}
+
+// CreateMultisigAddress creates an address that requires 'required'
+// signatures from the provided public keys to be spent. The unlock conditions
+// of the address are stored and the address is added to the watch set. If the
+// address has never appeared in the blockchain, unused may be set to true to
+// skip the rescan.
+func (w *Wallet) CreateMultisigAddress(pks []types.SiaPublicKey, required uint64, unused bool) (types.UnlockConditions, error) {
+	uc, err := modules.NewMultisigUnlockConditions(pks, required)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	if err := w.AddUnlockConditions(uc); err != nil {
+		return types.UnlockConditions{}, errors.AddContext(err, "unable to store unlock conditions")
+	}
+	if err := w.AddWatchAddresses([]types.UnlockHash{uc.UnlockHash()}, unused); err != nil {
+		return types.UnlockConditions{}, errors.AddContext(err, "unable to watch address")
+	}
+	return uc, nil
+}
+
+// FundMultisigTransaction creates an unsigned transaction that sends the
+// provided outputs using the confirmed outputs of a watched multisig address.
+// The transaction fee is estimated from the transaction pool and any change
+// is returned to the multisig address.
+func (w *Wallet) FundMultisigTransaction(addr types.UnlockHash, outputs []types.SiacoinOutput) (modules.PartiallySignedTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartiallySignedTransaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if len(outputs) == 0 {
+		return modules.PartiallySignedTransaction{}, errors.New("no outputs to fund")
+	}
+	var amount types.Currency
+	for _, sco := range outputs {
+		amount = amount.Add(sco.Value)
+	}
+	_, tpoolFee := w.tpool.FeeEstimation()
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return modules.PartiallySignedTransaction{}, modules.ErrLockedWallet
+	}
+	if _, ok := w.watchedAddrs[addr]; !ok {
+		return modules.PartiallySignedTransaction{}, errUnwatchedMultisigAddress
+	}
+	uc, err := dbGetUnlockConditions(w.dbTx, addr)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, errUnknownMultisigAddress
+	}
+	consensusHeight, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	if consensusHeight < uc.Timelock {
+		return modules.PartiallySignedTransaction{}, errOutputTimelock
+	}
+
+	// Collect a value-sorted set of the confirmed outputs of the address that
+	// aren't spent by pending or recently funded transactions.
+	pending := make(map[types.OutputID]struct{})
+	for _, pt := range w.unconfirmedProcessedTransactions {
+		for _, input := range pt.Inputs {
+			pending[input.ParentID] = struct{}{}
+		}
+	}
+	var so sortedOutputs
+	err = dbForEachSiacoinOutput(w.dbTx, func(scoid types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.UnlockHash != addr {
+			return
+		}
+		if _, ok := pending[types.OutputID(scoid)]; ok {
+			return
+		}
+		if spendHeight, err := dbGetSpentOutput(w.dbTx, types.OutputID(scoid)); err == nil && spendHeight+RespendTimeout > consensusHeight {
+			return
+		}
+		so.ids = append(so.ids, scoid)
+		so.outputs = append(so.outputs, sco)
+	})
+	if err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	sort.Sort(sort.Reverse(so))
+
+	// Add inputs until the outputs and the fee are covered. The change output
+	// is included in the fee estimate.
+	txn := types.Transaction{
+		SiacoinOutputs: append([]types.SiacoinOutput(nil), outputs...),
+	}
+	var fund, fee types.Currency
+	for i := range so.ids {
+		txn.SiacoinInputs = append(txn.SiacoinInputs, types.SiacoinInput{
+			ParentID:         so.ids[i],
+			UnlockConditions: uc,
+		})
+		fund = fund.Add(so.outputs[i].Value)
+		fee = tpoolFee.Mul64(estimatedMultisigTxnSize(uc, len(txn.SiacoinInputs), len(outputs)+1))
+		if fund.Cmp(amount.Add(fee)) >= 0 {
+			break
+		}
+	}
+	if fund.Cmp(amount.Add(fee)) < 0 {
+		return modules.PartiallySignedTransaction{}, modules.ErrLowBalance
+	}
+	txn.MinerFees = []types.Currency{fee}
+	if change := fund.Sub(amount).Sub(fee); !change.IsZero() {
+		txn.SiacoinOutputs = append(txn.SiacoinOutputs, types.SiacoinOutput{
+			Value:      change,
+			UnlockHash: addr,
+		})
+	}
+
+	// Mark the outputs as spent so that they aren't used to fund another
+	// transaction while the cosigners are signing this one.
+	for _, sci := range txn.SiacoinInputs {
+		if err := dbPutSpentOutput(w.dbTx, types.OutputID(sci.ParentID), consensusHeight); err != nil {
+			return modules.PartiallySignedTransaction{}, err
+		}
+	}
+	return modules.PartiallySignedTransaction{Transaction: txn}, nil
+}
+
+// SignMultisigTransaction adds the wallet's share of signatures to a partially
+// signed transaction. Every input with unlock conditions that contain a public
+// key of the wallet is signed with that key through SignTransaction.
+func (w *Wallet) SignMultisigTransaction(pst modules.PartiallySignedTransaction) (modules.PartiallySignedTransaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+	defer w.tg.Done()
+	w.mu.RLock()
+	if !w.unlocked {
+		w.mu.RUnlock()
+		return modules.PartiallySignedTransaction{}, modules.ErrLockedWallet
+	}
+	secretKeys := secretKeysByPublicKey(w.keys)
+	w.mu.RUnlock()
+
+	type sigKey struct {
+		parentID crypto.Hash
+		index    uint64
+	}
+	signed := make(map[sigKey]struct{})
+	for _, sig := range pst.Signatures {
+		if len(sig.Signature) != 0 {
+			signed[sigKey{sig.ParentID, sig.PublicKeyIndex}] = struct{}{}
+		}
+	}
+
+	// Add a signature for every public key of the wallet that hasn't signed
+	// yet.
+	txn := pst.Transaction
+	txn.TransactionSignatures = nil
+	var toSign []crypto.Hash
+	addSignatures := func(id crypto.Hash, uc types.UnlockConditions) {
+		added := false
+		for i, pk := range uc.PublicKeys {
+			if _, ok := signed[sigKey{id, uint64(i)}]; ok {
+				continue
+			}
+			if _, ok := lookupSecretKey(secretKeys, pk); !ok {
+				continue
+			}
+			txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+				ParentID:       id,
+				CoveredFields:  types.CoveredFields{WholeTransaction: true},
+				PublicKeyIndex: uint64(i),
+			})
+			added = true
+		}
+		if added {
+			toSign = append(toSign, id)
+		}
+	}
+	for _, sci := range txn.SiacoinInputs {
+		addSignatures(crypto.Hash(sci.ParentID), sci.UnlockConditions)
+	}
+	for _, sfi := range txn.SiafundInputs {
+		addSignatures(crypto.Hash(sfi.ParentID), sfi.UnlockConditions)
+	}
+	if len(toSign) == 0 {
+		return modules.PartiallySignedTransaction{}, errNoMultisigKeys
+	}
+	if err := w.SignTransaction(&txn, toSign); err != nil {
+		return modules.PartiallySignedTransaction{}, err
+	}
+
+	pst.Signatures = append(append([]types.TransactionSignature(nil), pst.Signatures...), txn.TransactionSignatures...)
+	return pst, nil
+}
+
+// BroadcastMultisigTransaction submits a partially signed transaction and its
+// parents to the transaction pool once enough signatures have been collected
+// for every input.
+func (w *Wallet) BroadcastMultisigTransaction(pst modules.PartiallySignedTransaction) (types.Transaction, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.Transaction{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	txn, err := pst.SignedTransaction()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if err := txn.StandaloneValid(w.cs.Height()); err != nil {
+		return types.Transaction{}, errors.AddContext(err, "transaction is invalid")
+	}
+	txnSet := append(append([]types.Transaction(nil), pst.Parents...), txn)
+	if err := w.tpool.AcceptTransactionSet(txnSet); err != nil {
+		return types.Transaction{}, errors.AddContext(err, "unable to get transaction accepted")
+	}
+	w.log.Println("Broadcast multisig transaction", txn.ID())
+	return txn, nil
+}
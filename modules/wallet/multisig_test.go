@@ -0,0 +1,134 @@
+package wallet
+
+import (
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestMultisigWorkflow funds a 2-of-3 multisig address, signs a spend from it
+// with the wallet's key and a cosigner's key, and broadcasts it once enough
+// signatures have been merged.
+func TestMultisigWorkflow(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// use a key of the wallet and the keys of two cosigners
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cosignerSK, cosignerPK := crypto.GenerateKeyPair()
+	_, otherPK := crypto.GenerateKeyPair()
+	pks := []types.SiaPublicKey{
+		uc.PublicKeys[0],
+		types.Ed25519PublicKey(cosignerPK),
+		types.Ed25519PublicKey(otherPK),
+	}
+	msuc, err := wt.wallet.CreateMultisigAddress(pks, 2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	addr := msuc.UnlockHash()
+
+	// fund the multisig address
+	_, err = wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), addr)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the wallet must not spend the multisig outputs on its own
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision, types.UnlockHash{}); err != nil {
+		t.Fatal(err)
+	}
+
+	// spend from the multisig address
+	dest := types.UnlockHash{1}
+	pst, err := wt.wallet.FundMultisigTransaction(addr, []types.SiacoinOutput{{
+		Value:      types.SiacoinPrecision.Mul64(10),
+		UnlockHash: dest,
+	}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(pst.Transaction.SiacoinOutputs) != 2 || pst.Transaction.SiacoinOutputs[1].UnlockHash != addr {
+		t.Fatal("expected change to be returned to the multisig address")
+	}
+	// the outputs are reserved for the transaction
+	_, err = wt.wallet.FundMultisigTransaction(addr, []types.SiacoinOutput{{Value: types.SiacoinPrecision, UnlockHash: dest}})
+	if err != modules.ErrLowBalance {
+		t.Fatal("expected outputs to be reserved, got", err)
+	}
+
+	// sign our share
+	signed, err := wt.wallet.SignMultisigTransaction(pst)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if signed.ThresholdMet() {
+		t.Fatal("threshold shouldn't be met with a single signature")
+	}
+	if _, err := wt.wallet.SignMultisigTransaction(signed); err != errNoMultisigKeys {
+		t.Fatal("expected errNoMultisigKeys, got", err)
+	}
+	if _, err := wt.wallet.BroadcastMultisigTransaction(signed); err != modules.ErrMultisigThreshold {
+		t.Fatal("expected ErrMultisigThreshold, got", err)
+	}
+
+	// the cosigner signs independently
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	cosigned := pst
+	txn := pst.Transaction
+	txn.TransactionSignatures = []types.TransactionSignature{{
+		ParentID:       crypto.Hash(txn.SiacoinInputs[0].ParentID),
+		CoveredFields:  types.CoveredFields{WholeTransaction: true},
+		PublicKeyIndex: 1,
+	}}
+	sig := crypto.SignHash(txn.SigHash(0, height), cosignerSK)
+	txn.TransactionSignatures[0].Signature = sig[:]
+	cosigned.Signatures = txn.TransactionSignatures
+
+	merged, err := modules.MergePartiallySignedTransactions(signed, cosigned)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !merged.ThresholdMet() {
+		t.Fatal("threshold should be met", merged.Status())
+	}
+	final, err := wt.wallet.BroadcastMultisigTransaction(merged)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// the change should be the only remaining output of the address
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var remaining []modules.UnspentOutput
+	for _, o := range outputs {
+		if o.UnlockHash == addr {
+			remaining = append(remaining, o)
+		}
+	}
+	if len(remaining) != 1 || remaining[0].ID != types.OutputID(final.SiacoinOutputID(1)) || !remaining[0].IsWatchOnly {
+		t.Fatal("unexpected multisig outputs", remaining)
+	}
+}
@@ -97,10 +97,11 @@ outer:
 		}
 	}
 
-	// mark the watch-only outputs
+	// mark the watch-only and time-locked outputs
 	for i, o := range outputs {
 		_, ok := w.watchedAddrs[o.UnlockHash]
 		outputs[i].IsWatchOnly = ok
+		outputs[i].LockHeight = w.lockHeight(w.dbTx, o.UnlockHash)
 	}
 
 	return outputs, nil
@@ -168,13 +169,33 @@ func (w *Wallet) SignTransaction(txn *types.Transaction, toSign []crypto.Hash) e
 
 	// if toSign is empty, sign all inputs that we have keys for
 	if len(toSign) == 0 {
+		var secretKeys map[crypto.PublicKey]crypto.SecretKey
+		canSign := func(id crypto.Hash, uc types.UnlockConditions) bool {
+			if _, ok := w.keys[uc.UnlockHash()]; ok {
+				return true
+			}
+			// multisig inputs can be signed if one of their signatures
+			// refers to one of our keys
+			if secretKeys == nil {
+				secretKeys = secretKeysByPublicKey(w.keys)
+			}
+			for _, sig := range txn.TransactionSignatures {
+				if sig.ParentID != id || sig.PublicKeyIndex >= uint64(len(uc.PublicKeys)) {
+					continue
+				}
+				if _, ok := lookupSecretKey(secretKeys, uc.PublicKeys[sig.PublicKeyIndex]); ok {
+					return true
+				}
+			}
+			return false
+		}
 		for _, sci := range txn.SiacoinInputs {
-			if _, ok := w.keys[sci.UnlockConditions.UnlockHash()]; ok {
+			if canSign(crypto.Hash(sci.ParentID), sci.UnlockConditions) {
 				toSign = append(toSign, crypto.Hash(sci.ParentID))
 			}
 		}
 		for _, sfi := range txn.SiafundInputs {
-			if _, ok := w.keys[sfi.UnlockConditions.UnlockHash()]; ok {
+			if canSign(crypto.Hash(sfi.ParentID), sfi.UnlockConditions) {
 				toSign = append(toSign, crypto.Hash(sfi.ParentID))
 			}
 		}
@@ -230,7 +251,10 @@ func signTransaction(txn *types.Transaction, keys map[types.UnlockHash]spendable
 		}
 		return types.UnlockConditions{}, false
 	}
-	// helper function to lookup the secret key that can sign
+	// helper function to lookup the secret key that can sign. Inputs spending
+	// from multisig addresses aren't keyed by their unlock hash, so their
+	// keys are looked up by public key instead.
+	var secretKeys map[crypto.PublicKey]crypto.SecretKey
 	findSigningKey := func(uc types.UnlockConditions, pubkeyIndex uint64) (crypto.SecretKey, bool) {
 		if pubkeyIndex >= uint64(len(uc.PublicKeys)) {
 			return crypto.SecretKey{}, false
@@ -238,7 +262,10 @@ func signTransaction(txn *types.Transaction, keys map[types.UnlockHash]spendable
 		pk := uc.PublicKeys[pubkeyIndex]
 		sk, ok := keys[uc.UnlockHash()]
 		if !ok {
-			return crypto.SecretKey{}, false
+			if secretKeys == nil {
+				secretKeys = secretKeysByPublicKey(keys)
+			}
+			return lookupSecretKey(secretKeys, pk)
 		}
 		for _, key := range sk.SecretKeys {
 			pubKey := key.PublicKey()
@@ -250,41 +277,71 @@ func signTransaction(txn *types.Transaction, keys map[types.UnlockHash]spendable
 	}
 
 	for _, id := range toSign {
-		// find associated txn signature
-		sigIndex := -1
-		for i, sig := range txn.TransactionSignatures {
-			if sig.ParentID == id {
-				sigIndex = i
-				break
-			}
-		}
-		if sigIndex == -1 {
-			return errors.New("toSign references signatures not present in transaction")
-		}
 		// find associated input
 		uc, ok := findUnlockConditions(id)
 		if !ok {
 			return errors.New("toSign references IDs not present in transaction")
 		}
-		// lookup the signing key
-		sk, ok := findSigningKey(uc, txn.TransactionSignatures[sigIndex].PublicKeyIndex)
-		if !ok {
+		// sign every associated txn signature that we have the key for. A
+		// multisig input has one signature per cosigner, and only our share
+		// of them can be filled in.
+		found, signed := false, false
+		for sigIndex, sig := range txn.TransactionSignatures {
+			if sig.ParentID != id {
+				continue
+			}
+			found = true
+			// lookup the signing key
+			sk, ok := findSigningKey(uc, sig.PublicKeyIndex)
+			if !ok {
+				continue
+			}
+			// add signature
+			//
+			// NOTE: it's possible that the Signature field will already be
+			// filled out. Although we could save a bit of work by not signing
+			// it, in practice it's probably best to overwrite any existing
+			// signatures, since we know that ours will be valid.
+			sigHash := txn.SigHash(sigIndex, height)
+			encodedSig := crypto.SignHash(sigHash, sk)
+			txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
+			signed = true
+		}
+		if !found {
+			return errors.New("toSign references signatures not present in transaction")
+		}
+		if !signed {
 			return errors.New("could not locate signing key for " + id.String())
 		}
-		// add signature
-		//
-		// NOTE: it's possible that the Signature field will already be filled
-		// out. Although we could save a bit of work by not signing it, in
-		// practice it's probably best to overwrite any existing signatures,
-		// since we know that ours will be valid.
-		sigHash := txn.SigHash(sigIndex, height)
-		encodedSig := crypto.SignHash(sigHash, sk)
-		txn.TransactionSignatures[sigIndex].Signature = encodedSig[:]
 	}
 
 	return nil
 }
 
+// secretKeysByPublicKey maps the public keys of the provided spendable keys to
+// their secret keys.
+func secretKeysByPublicKey(keys map[types.UnlockHash]spendableKey) map[crypto.PublicKey]crypto.SecretKey {
+	secretKeys := make(map[crypto.PublicKey]crypto.SecretKey)
+	for _, sk := range keys {
+		for _, key := range sk.SecretKeys {
+			secretKeys[key.PublicKey()] = key
+		}
+	}
+	return secretKeys
+}
+
+// lookupSecretKey returns the secret key that belongs to the provided ed25519
+// public key.
+func lookupSecretKey(secretKeys map[crypto.PublicKey]crypto.SecretKey, spk types.SiaPublicKey) (crypto.SecretKey, bool) {
+	var pk crypto.PublicKey
+	if spk.Algorithm != types.SignatureEd25519 || len(spk.Key) != len(pk) {
+		return crypto.SecretKey{}, false
+	}
+	copy(pk[:], spk.Key)
+	sk, ok := secretKeys[pk]
+	return sk, ok
+}
+
 // AddWatchAddresses instructs the wallet to begin tracking a set of
 // addresses, in addition to the addresses it was previously tracking. If none
 // of the addresses have appeared in the blockchain, the unused flag may be
@@ -296,12 +353,22 @@ func (w *Wallet) AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 	}
 	defer w.tg.Done()
 
+	w.mu.RLock()
+	unlocked := w.unlocked
+	w.mu.RUnlock()
+	if !unlocked {
+		return modules.ErrLockedWallet
+	}
+	return w.managedAddWatchAddresses(addrs, unused)
+}
+
+// managedAddWatchAddresses adds addrs to the watch set. Unlike
+// AddWatchAddresses, it doesn't require the wallet to be unlocked, so that
+// the addresses of a signer can be added to a locked wallet.
+func (w *Wallet) managedAddWatchAddresses(addrs []types.UnlockHash, unused bool) error {
 	err := func() error {
 		w.mu.Lock()
 		defer w.mu.Unlock()
-		if !w.unlocked {
-			return modules.ErrLockedWallet
-		}
 
 		// update in-memory map
 		for _, addr := range addrs {
@@ -318,17 +385,7 @@ func (w *Wallet) AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 
 		if !unused {
 			// prepare to rescan
-			if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			w.unconfirmedProcessedTransactions = nil
-			if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
-				return err
-			}
-			if err := dbPutConsensusHeight(w.dbTx, 0); err != nil {
+			if err := w.resetScanProgress(); err != nil {
 				return err
 			}
 		}
@@ -355,6 +412,21 @@ func (w *Wallet) AddWatchAddresses(addrs []types.UnlockHash, unused bool) error
 	return nil
 }
 
+// resetScanProgress prepares the database for a rescan of the blockchain.
+func (w *Wallet) resetScanProgress() error {
+	if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
+		return err
+	}
+	if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
+		return err
+	}
+	w.unconfirmedProcessedTransactions = nil
+	if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
+		return err
+	}
+	return dbPutConsensusHeight(w.dbTx, 0)
+}
+
 // RemoveWatchAddresses instructs the wallet to stop tracking a set of
 // addresses and delete their associated transactions. If none of the
 // addresses have appeared in the blockchain, the unused flag may be set to
@@ -402,17 +474,7 @@ func (w *Wallet) RemoveWatchAddresses(addrs []types.UnlockHash, unused bool) err
 			}
 
 			// prepare to rescan
-			if err := w.dbTx.DeleteBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			if _, err := w.dbTx.CreateBucket(bucketProcessedTransactions); err != nil {
-				return err
-			}
-			w.unconfirmedProcessedTransactions = nil
-			if err := dbPutConsensusChangeID(w.dbTx, modules.ConsensusChangeBeginning); err != nil {
-				return err
-			}
-			if err := dbPutConsensusHeight(w.dbTx, 0); err != nil {
+			if err := w.resetScanProgress(); err != nil {
 				return err
 			}
 		}
@@ -147,6 +147,12 @@ func (w *Wallet) initPersist() error {
 		}
 	}
 
+	// load the addresses of the accounts, so that the balances of the
+	// accounts are known before the wallet is unlocked
+	if err := w.loadAccountAddresses(w.dbTx); err != nil {
+		return err
+	}
+
 	// ensure that the final db transaction is committed when the wallet closes
 	err = w.tg.AfterStop(func() error {
 		w.mu.Lock()
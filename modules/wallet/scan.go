@@ -59,9 +59,15 @@ type seedScanner struct {
 	largestIndexSeen uint64                      // largest index that has appeared in the blockchain
 	scannedHeight    types.BlockHeight
 	seed             modules.Seed
+	used             bool // whether any key has appeared in the blockchain
 	siacoinOutputs   map[types.SiacoinOutputID]scannedOutput
 	siafundOutputs   map[types.SiafundOutputID]scannedOutput
 
+	// timelock and accounts, if set, look for the time-locked addresses and
+	// the accounts of the seed in the same pass.
+	timelock *timelockScanner
+	accounts *accountScanner
+
 	log *persist.Logger
 }
 
@@ -123,6 +129,7 @@ func (s *seedScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
 		index, exists := s.keys[diff.SiacoinOutput.UnlockHash]
 		if exists {
 			s.log.Debugln("Seed scanner found a key used at index", index)
+			s.used = true
 			if index > s.largestIndexSeen {
 				s.largestIndexSeen = index
 			}
@@ -132,11 +139,19 @@ func (s *seedScanner) ProcessConsensusChange(cc modules.ConsensusChange) {
 		index, exists := s.keys[diff.SiafundOutput.UnlockHash]
 		if exists {
 			s.log.Debugln("Seed scanner found a key used at index", index)
+			s.used = true
 			if index > s.largestIndexSeen {
 				s.largestIndexSeen = index
 			}
 		}
 	}
+	if s.timelock != nil {
+		s.timelock.processConsensusChange(cc)
+	}
+	if s.accounts != nil {
+		s.accounts.processConsensusChange(cc)
+	}
+
 	// Adjust the scanned height and print the scan progress.
 	s.scannedHeight += types.BlockHeight(len(cc.AppliedBlocks) - len(cc.RevertedBlocks))
 	if !cc.Synced {
@@ -156,28 +171,47 @@ func (s *seedScanner) scan(cs modules.ConsensusSet, cancel <-chan struct{}) erro
 	// default).
 	//
 	// NOTE: since scanning is very slow, we aim to only scan once, which
-	// means generating many keys.
+	// means generating many keys. The time-locked addresses and the accounts
+	// are looked for in the same pass.
 	numKeys := numInitialKeys
-	for s.numKeys() < maxScanKeys {
-		s.generateKeys(numKeys)
-
+	s.generateKeys(numKeys)
+	for {
 		// Reset scan height between scans.
 		s.scannedHeight = 0
 		if err := cs.ConsensusSetSubscribe(s, modules.ConsensusChangeBeginning, cancel); err != nil {
 			return err
 		}
 		cs.Unsubscribe(s)
-		if s.largestIndexSeen < s.numKeys()/2 {
+		seedDone := s.largestIndexSeen < s.numKeys()/2
+		timelockDone := s.timelock == nil || s.timelock.done()
+		accountsDone := s.accounts == nil || s.accounts.done()
+		if seedDone && timelockDone && accountsDone {
 			return nil
 		}
-		// increase number of keys generated each iteration, capping so that
-		// we do not exceed maxScanKeys
-		numKeys *= scanMultiplier
-		if numKeys > maxScanKeys-s.numKeys() {
-			numKeys = maxScanKeys - s.numKeys()
+		if !seedDone {
+			if s.numKeys() >= maxScanKeys {
+				return errMaxKeys
+			}
+			// increase number of keys generated each iteration, capping so
+			// that we do not exceed maxScanKeys
+			numKeys *= scanMultiplier
+			if numKeys > maxScanKeys-s.numKeys() {
+				numKeys = maxScanKeys - s.numKeys()
+			}
+			s.generateKeys(numKeys)
+		}
+		if !timelockDone {
+			// double the number of time-locked indices
+			if err := s.timelock.generateKeys(s.timelock.numIndices); err != nil {
+				return err
+			}
+		}
+		if !accountsDone {
+			if err := s.accounts.grow(); err != nil {
+				return err
+			}
 		}
 	}
-	return errMaxKeys
 }
 
 // newSeedScanner returns a new seedScanner.
@@ -0,0 +1,173 @@
+package wallet
+
+import (
+	"encoding/hex"
+
+	"gitlab.com/NebulousLabs/fastrand"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errScheduledPaymentNotFound is returned if a scheduled payment doesn't
+	// exist.
+	errScheduledPaymentNotFound = errors.New("scheduled payment not found")
+
+	// errScheduledHeightInPast is returned if a payment is scheduled for a
+	// height that has already been reached.
+	errScheduledHeightInPast = errors.New("height of the payment must be above the current height")
+
+	// errScheduledCount is returned if a one-off payment is scheduled to be
+	// made more than once.
+	errScheduledCount = errors.New("payments without an interval can only be made once")
+)
+
+// scheduledPaymentFinished returns true if all payments of sp were made.
+func scheduledPaymentFinished(sp modules.ScheduledPayment) bool {
+	return sp.Interval == 0 || (sp.Count > 0 && sp.Payments >= sp.Count)
+}
+
+// SchedulePayment adds a payment to the queue of scheduled payments and
+// returns it with its ID.
+func (w *Wallet) SchedulePayment(sp modules.ScheduledPayment) (modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.ScheduledPayment{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if sp.Amount.IsZero() {
+		return modules.ScheduledPayment{}, errZeroPaymentAmount
+	}
+	if sp.Interval == 0 && sp.Count > 1 {
+		return modules.ScheduledPayment{}, errScheduledCount
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+	if sp.Height <= height {
+		return modules.ScheduledPayment{}, errScheduledHeightInPast
+	}
+	sp.ID = hex.EncodeToString(fastrand.Bytes(8))
+	sp.Payments = 0
+	sp.LastTransaction = types.TransactionID{}
+	sp.LastError = ""
+	if err := dbPutScheduledPayment(w.dbTx, sp); err != nil {
+		return modules.ScheduledPayment{}, err
+	}
+	return sp, w.syncDB()
+}
+
+// ScheduledPayments returns the queue of scheduled payments.
+func (w *Wallet) ScheduledPayments() ([]modules.ScheduledPayment, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	sps := []modules.ScheduledPayment{}
+	err := dbForEachScheduledPayment(w.dbTx, func(_ string, sp modules.ScheduledPayment) {
+		sps = append(sps, sp)
+	})
+	return sps, err
+}
+
+// CancelScheduledPayment removes a payment from the queue of scheduled
+// payments.
+func (w *Wallet) CancelScheduledPayment(id string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := dbGetScheduledPayment(w.dbTx, id); err != nil {
+		return errScheduledPaymentNotFound
+	}
+	if err := dbDeleteScheduledPayment(w.dbTx, id); err != nil {
+		return err
+	}
+	return w.syncDB()
+}
+
+// threadedSendScheduledPayments sends the scheduled payments that are due.
+func (w *Wallet) threadedSendScheduledPayments() {
+	if err := w.tg.Add(); err != nil {
+		return
+	}
+	defer w.tg.Done()
+
+	// Only one thread sends the payments at a time. Payments that are skipped
+	// are sent after the next block.
+	if !w.scheduledLock.TryLock() {
+		return
+	}
+	defer w.scheduledLock.Unlock()
+
+	var due []modules.ScheduledPayment
+	w.mu.Lock()
+	unlocked := w.unlocked
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err == nil {
+		err = dbForEachScheduledPayment(w.dbTx, func(_ string, sp modules.ScheduledPayment) {
+			if sp.Height <= height {
+				due = append(due, sp)
+			}
+		})
+	}
+	w.mu.Unlock()
+	if err != nil {
+		w.log.Println("WARN: failed to load scheduled payments:", err)
+		return
+	}
+	if !unlocked {
+		return
+	}
+	for _, sp := range due {
+		w.managedSendScheduledPayment(sp)
+	}
+}
+
+// managedSendScheduledPayment makes the next payment of sp. The payment is
+// only advanced once the transaction pool accepted the transaction, so a
+// payment that fails is retried after the next block.
+func (w *Wallet) managedSendScheduledPayment(sp modules.ScheduledPayment) {
+	txns, sendErr := w.SendSiacoins(sp.Amount, sp.Destination)
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if _, err := dbGetScheduledPayment(w.dbTx, sp.ID); err != nil {
+		// The payment was cancelled in the meantime.
+		return
+	}
+	var err error
+	if sendErr != nil {
+		w.log.Printf("Scheduled payment %v failed and is retried after the next block: %v", sp.ID, sendErr)
+		sp.LastError = sendErr.Error()
+		err = dbPutScheduledPayment(w.dbTx, sp)
+	} else {
+		next := sp
+		next.Payments++
+		next.Height += sp.Interval
+		next.LastTransaction = txns[len(txns)-1].ID()
+		if scheduledPaymentFinished(next) {
+			w.log.Printf("Sent the last scheduled payment %v in transaction %v", sp.ID, next.LastTransaction)
+			err = dbDeleteScheduledPayment(w.dbTx, sp.ID)
+		} else {
+			w.log.Printf("Sent scheduled payment %v in transaction %v, the next payment is at height %v", sp.ID, next.LastTransaction, next.Height)
+			next.LastError = ""
+			err = dbPutScheduledPayment(w.dbTx, next)
+		}
+	}
+	if err == nil {
+		err = w.syncDB()
+	}
+	if err != nil {
+		w.log.Println("WARN: failed to update scheduled payment:", err)
+	}
+}
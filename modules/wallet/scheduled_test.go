@@ -0,0 +1,152 @@
+package wallet
+
+import (
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// TestScheduledPayments tests that scheduled payments are sent at their
+// heights.
+func TestScheduledPayments(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := types.UnlockHash{1, 2, 3}
+	amount := types.SiacoinPrecision.Mul64(10)
+	if _, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Destination: dest, Amount: amount, Height: height}); !errors.Contains(err, errScheduledHeightInPast) {
+		t.Fatal("expected errScheduledHeightInPast, got", err)
+	}
+	if _, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Destination: dest, Amount: amount, Height: height + 1, Count: 2}); !errors.Contains(err, errScheduledCount) {
+		t.Fatal("expected errScheduledCount, got", err)
+	}
+
+	// Schedule a payment that is made twice, and one that is cancelled.
+	sp, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{
+		Destination: dest,
+		Amount:      amount,
+		Height:      height + 1,
+		Interval:    2,
+		Count:       2,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	cancelled, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{Destination: dest, Amount: amount, Height: height + 2})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.CancelScheduledPayment(cancelled.ID); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.CancelScheduledPayment(cancelled.ID); !errors.Contains(err, errScheduledPaymentNotFound) {
+		t.Fatal("expected errScheduledPaymentNotFound, got", err)
+	}
+
+	// paid returns the total value sent to dest.
+	paid := func() (total types.Currency) {
+		txns, err := wt.wallet.Transactions(0, ^types.BlockHeight(0))
+		if err != nil {
+			t.Fatal(err)
+		}
+		utxns, err := wt.wallet.UnconfirmedTransactions()
+		if err != nil {
+			t.Fatal(err)
+		}
+		txns = append(txns, utxns...)
+		for _, pt := range txns {
+			for _, sco := range pt.Transaction.SiacoinOutputs {
+				if sco.UnlockHash == dest {
+					total = total.Add(sco.Value)
+				}
+			}
+		}
+		return
+	}
+	for i, expected := range []uint64{1, 1, 2, 2} {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+		err := build.Retry(50, 100*time.Millisecond, func() error {
+			if p := paid(); !p.Equals(amount.Mul64(expected)) {
+				return errors.New("unexpected payments: " + p.HumanString())
+			}
+			return nil
+		})
+		if err != nil {
+			t.Fatal(i, err)
+		}
+	}
+
+	// The finished payment was removed from the queue.
+	sps, err := wt.wallet.ScheduledPayments()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(sps) != 0 {
+		t.Fatal("expected no scheduled payments", sps, sp)
+	}
+}
+
+// TestScheduledPaymentFailure tests that a scheduled payment that can't be
+// sent isn't advanced.
+func TestScheduledPaymentFailure(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	balance, _, _, err := wt.wallet.ConfirmedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	sp, err := wt.wallet.SchedulePayment(modules.ScheduledPayment{
+		Destination: types.UnlockHash{1, 2, 3},
+		Amount:      balance.Mul64(2),
+		Height:      height + 1,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(50, 100*time.Millisecond, func() error {
+		sps, err := wt.wallet.ScheduledPayments()
+		if err != nil {
+			return err
+		}
+		if len(sps) != 1 || sps[0].LastError == "" {
+			return errors.New("payment didn't fail")
+		}
+		if sps[0].Payments != 0 || sps[0].Height != sp.Height {
+			return errors.New("failed payment was advanced")
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,465 @@
+package wallet
+
+import (
+	"bytes"
+	"context"
+	"crypto/subtle"
+	"encoding/json"
+	"net"
+	"net/http"
+	"net/url"
+	"sync/atomic"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+const (
+	// maxSignerAddresses is the maximum number of addresses that can be
+	// requested from a signer at once.
+	maxSignerAddresses = 1000
+
+	// signerTimeout is the timeout of a request to a signer. Signing may
+	// require the signer to derive many keys, so it's fairly generous.
+	signerTimeout = 2 * time.Minute
+
+	// signerMaxMessageSize is the maximum size of a request to or a response
+	// from a signer.
+	signerMaxMessageSize = 1 << 22
+)
+
+// JSON-RPC 2.0 error codes returned by SignerHandler.
+const (
+	signerErrParse          = -32700
+	signerErrMethodNotFound = -32601
+	signerErrInvalidParams  = -32602
+	signerErrServer         = -32000
+)
+
+var (
+	// errNoSigner is returned if a signer is required but none is configured.
+	errNoSigner = errors.New("no signer is configured")
+
+	// errSignerAltered is returned if the signer returns a transaction that
+	// differs from the transaction it was asked to sign.
+	errSignerAltered = errors.New("signer altered the transaction")
+
+	// errSignerAddressCount is returned if the number of requested signer
+	// addresses is out of range.
+	errSignerAddressCount = errors.New("number of signer addresses must be between 1 and 1000")
+
+	// errSignerAddressScheme is returned if the address of a signer is
+	// neither an http(s) nor a unix URL.
+	errSignerAddressScheme = errors.New("signer address must be an http://, https:// or unix:// URL")
+)
+
+// signerClient calls the JSON-RPC methods of a wallet signer.
+type signerClient struct {
+	url      string
+	username string
+	password string
+	client   *http.Client
+	nextID   uint64
+}
+
+// newSignerClient returns a client for the signer at address.
+func newSignerClient(address string) (*signerClient, error) {
+	u, err := url.Parse(address)
+	if err != nil {
+		return nil, errors.AddContext(err, "invalid signer address")
+	}
+	sc := &signerClient{
+		client: &http.Client{Timeout: signerTimeout},
+	}
+	if u.User != nil {
+		sc.username = u.User.Username()
+		sc.password, _ = u.User.Password()
+		u.User = nil
+	}
+	switch u.Scheme {
+	case "http", "https":
+		if u.Host == "" {
+			return nil, errors.New("signer address is missing a host")
+		}
+		sc.url = u.String()
+	case "unix":
+		if u.Path == "" {
+			return nil, errors.New("signer address is missing a socket path")
+		}
+		path := u.Path
+		sc.client.Transport = &http.Transport{
+			DialContext: func(ctx context.Context, _, _ string) (net.Conn, error) {
+				var d net.Dialer
+				return d.DialContext(ctx, "unix", path)
+			},
+		}
+		sc.url = "http://signer/"
+	default:
+		return nil, errSignerAddressScheme
+	}
+	return sc, nil
+}
+
+// call calls method with params and decodes its result into result.
+func (sc *signerClient) call(method string, params, result interface{}) error {
+	p, err := json.Marshal(params)
+	if err != nil {
+		return err
+	}
+	id := atomic.AddUint64(&sc.nextID, 1)
+	body, err := json.Marshal(modules.SignerRequest{
+		JSONRPC: "2.0",
+		ID:      id,
+		Method:  method,
+		Params:  p,
+	})
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequest("POST", sc.url, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/json")
+	if sc.username != "" || sc.password != "" {
+		req.SetBasicAuth(sc.username, sc.password)
+	}
+	resp, err := sc.client.Do(req)
+	if err != nil {
+		return errors.AddContext(err, "unable to reach signer")
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode == http.StatusUnauthorized {
+		return errors.New("signer rejected the password")
+	}
+	var sr modules.SignerResponse
+	if err := json.NewDecoder(http.MaxBytesReader(nil, resp.Body, signerMaxMessageSize)).Decode(&sr); err != nil {
+		return errors.AddContext(err, "unable to decode signer response")
+	}
+	if sr.Error != nil {
+		return sr.Error
+	}
+	if sr.ID != id {
+		return errors.New("signer responded to the wrong request")
+	}
+	return json.Unmarshal(sr.Result, result)
+}
+
+// addresses returns the unlock conditions of the n signer addresses starting
+// at key index start.
+func (sc *signerClient) addresses(start, n uint64) ([]types.UnlockConditions, error) {
+	var ucs []types.UnlockConditions
+	err := sc.call(modules.SignerMethodAddresses, modules.SignerAddressesParams{Start: start, N: n}, &ucs)
+	if err != nil {
+		return nil, err
+	}
+	if uint64(len(ucs)) != n {
+		return nil, errors.New("signer returned the wrong number of addresses")
+	}
+	return ucs, nil
+}
+
+// signTransaction asks the signer to sign the inputs toSign of txn.
+func (sc *signerClient) signTransaction(txn types.Transaction, toSign []crypto.Hash, height types.BlockHeight) (types.Transaction, error) {
+	var signed types.Transaction
+	err := sc.call(modules.SignerMethodSignTransaction, modules.SignerSignTransactionParams{
+		Transaction: txn,
+		ToSign:      toSign,
+		Height:      height,
+	}, &signed)
+	return signed, err
+}
+
+// redactSignerAddress removes the password from the address of a signer.
+func redactSignerAddress(address string) string {
+	u, err := url.Parse(address)
+	if err != nil || u.User == nil {
+		return address
+	}
+	if _, ok := u.User.Password(); ok {
+		u.User = url.UserPassword(u.User.Username(), "xxxxx")
+	}
+	return u.String()
+}
+
+// SetSigner configures the external signer of the wallet. An empty address
+// removes the signer, but the addresses of the signer remain watched. The
+// wallet doesn't have to be unlocked or even have a seed to use a signer.
+func (w *Wallet) SetSigner(address string) error {
+	if err := w.tg.Add(); err != nil {
+		return modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if address != "" {
+		if _, err := newSignerClient(address); err != nil {
+			return err
+		}
+	}
+	w.mu.Lock()
+	err := dbPutSigner(w.dbTx, address)
+	if err == nil {
+		err = w.syncDB()
+	}
+	w.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	return w.managedSubscribeWithSigner()
+}
+
+// Signer returns the external signer of the wallet.
+func (w *Wallet) Signer() (modules.WalletSigner, error) {
+	if err := w.tg.Add(); err != nil {
+		return modules.WalletSigner{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	var ws modules.WalletSigner
+	ws.Address = redactSignerAddress(dbGetSigner(w.dbTx))
+	err := dbForEachSignerAddress(w.dbTx, func(types.UnlockHash, uint64) {
+		ws.Addresses++
+	})
+	return ws, err
+}
+
+// managedSignerClient returns a client for the configured signer, or nil if
+// no signer is configured.
+func (w *Wallet) managedSignerClient() (*signerClient, error) {
+	w.mu.Lock()
+	address := dbGetSigner(w.dbTx)
+	w.mu.Unlock()
+	if address == "" {
+		return nil, nil
+	}
+	return newSignerClient(address)
+}
+
+// AddSignerAddresses fetches the next n addresses from the signer and adds
+// them to the watch set. Their unlock conditions are stored so that outputs
+// sent to them can be spent through the signer.
+func (w *Wallet) AddSignerAddresses(n uint64, unused bool) ([]types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return nil, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	if n == 0 || n > maxSignerAddresses {
+		return nil, errSignerAddressCount
+	}
+	sc, err := w.managedSignerClient()
+	if err != nil {
+		return nil, err
+	} else if sc == nil {
+		return nil, errNoSigner
+	}
+	return w.managedAddSignerAddresses(sc, n, unused)
+}
+
+// managedAddSignerAddresses fetches the next n addresses from the signer sc
+// and adds them to the watch set.
+func (w *Wallet) managedAddSignerAddresses(sc *signerClient, n uint64, unused bool) ([]types.UnlockConditions, error) {
+	// Serialize the calls so that concurrent calls don't fetch the same
+	// addresses.
+	w.signerMu.Lock()
+	defer w.signerMu.Unlock()
+
+	var start uint64
+	w.mu.Lock()
+	err := dbForEachSignerAddress(w.dbTx, func(_ types.UnlockHash, index uint64) {
+		if index >= start {
+			start = index + 1
+		}
+	})
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+
+	ucs, err := sc.addresses(start, n)
+	if err != nil {
+		return nil, errors.AddContext(err, "unable to fetch addresses from signer")
+	}
+	addrs := make([]types.UnlockHash, len(ucs))
+	for i, uc := range ucs {
+		if uc.SignaturesRequired == 0 || uc.SignaturesRequired > uint64(len(uc.PublicKeys)) {
+			return nil, errors.New("signer returned invalid unlock conditions")
+		}
+		addrs[i] = uc.UnlockHash()
+	}
+
+	// Store the unlock conditions before watching the addresses, since
+	// watching them may trigger a rescan.
+	w.mu.Lock()
+	err = func() error {
+		for i, uc := range ucs {
+			if err := dbPutUnlockConditions(w.dbTx, uc); err != nil {
+				return err
+			}
+			if err := dbPutSignerAddress(w.dbTx, addrs[i], start+uint64(i)); err != nil {
+				return err
+			}
+		}
+		return w.syncDB()
+	}()
+	w.mu.Unlock()
+	if err != nil {
+		return nil, err
+	}
+	if err := w.managedAddWatchAddresses(addrs, unused); err != nil {
+		return nil, err
+	}
+	return ucs, nil
+}
+
+// checkSignerOutput is the equivalent of checkOutput for outputs that are
+// spent through the signer.
+func (w *Wallet) checkSignerOutput(tx *bolt.Tx, height types.BlockHeight, id types.SiacoinOutputID, output types.SiacoinOutput, dustThreshold types.Currency) error {
+	var uc types.UnlockConditions
+	ok := dbIsSignerAddress(tx, output.UnlockHash)
+	if ok {
+		var err error
+		uc, err = dbGetUnlockConditions(tx, output.UnlockHash)
+		ok = err == nil
+	}
+	return checkSpendableOutput(tx, height, id, output, dustThreshold, uc, ok)
+}
+
+// managedSignWithSigner asks the signer to sign all inputs of txn and checks
+// the signed transaction.
+func (w *Wallet) managedSignWithSigner(sc *signerClient, txn types.Transaction) (types.Transaction, error) {
+	w.mu.Lock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	w.mu.Unlock()
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	toSign := make([]crypto.Hash, len(txn.SiacoinInputs))
+	for i, sci := range txn.SiacoinInputs {
+		toSign[i] = crypto.Hash(sci.ParentID)
+	}
+	signed, err := signWithSigner(sc, txn, toSign, height)
+	if err != nil {
+		return types.Transaction{}, err
+	}
+	if err := signed.StandaloneValid(height); err != nil {
+		return types.Transaction{}, errors.AddContext(err, "signer returned an invalid transaction")
+	}
+	return signed, nil
+}
+
+// signWithSigner asks the signer to sign the inputs toSign of txn. Other
+// inputs may still be unsigned, so the transaction is only checked to be
+// unaltered.
+func signWithSigner(sc *signerClient, txn types.Transaction, toSign []crypto.Hash, height types.BlockHeight) (types.Transaction, error) {
+	signed, err := sc.signTransaction(txn, toSign, height)
+	if err != nil {
+		return types.Transaction{}, errors.AddContext(err, "signer failed to sign transaction")
+	}
+	if signed.ID() != txn.ID() || len(signed.TransactionSignatures) != len(txn.TransactionSignatures) {
+		return types.Transaction{}, errSignerAltered
+	}
+	return signed, nil
+}
+
+// addSignerPlaceholders adds empty signatures for the input parentID with the
+// unlock conditions uc, to be filled in by the signer.
+func addSignerPlaceholders(txn *types.Transaction, cf types.CoveredFields, uc types.UnlockConditions, parentID crypto.Hash) (newSigIndices []int) {
+	for i := uint64(0); i < uc.SignaturesRequired; i++ {
+		newSigIndices = append(newSigIndices, len(txn.TransactionSignatures))
+		txn.TransactionSignatures = append(txn.TransactionSignatures, types.TransactionSignature{
+			ParentID:       parentID,
+			CoveredFields:  cf,
+			PublicKeyIndex: i,
+		})
+	}
+	return newSigIndices
+}
+
+// managedSendWithSigner sends the outputs from the signer's addresses if a
+// signer is configured. ok is false if no signer is configured.
+func (w *Wallet) managedSendWithSigner(outputs []types.SiacoinOutput) (txns []types.Transaction, ok bool, err error) {
+	sc, err := w.managedSignerClient()
+	if err != nil {
+		return nil, true, err
+	} else if sc == nil {
+		return nil, false, nil
+	}
+	txn, _, err := w.SendSiacoinsCoinControl(outputs, modules.CoinControl{})
+	if err != nil {
+		return nil, true, err
+	}
+	return []types.Transaction{txn}, true, nil
+}
+
+// SignerHandler returns the handler of a wallet signer that holds seed. It
+// implements the JSON-RPC methods modules.SignerMethodAddresses and
+// modules.SignerMethodSignTransaction. If password is not empty, requests
+// must provide it through HTTP basic auth.
+func SignerHandler(seed modules.Seed, password string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		if req.Method != "POST" {
+			http.Error(w, "method not allowed", http.StatusMethodNotAllowed)
+			return
+		}
+		if password != "" {
+			_, pass, _ := req.BasicAuth()
+			if subtle.ConstantTimeCompare([]byte(pass), []byte(password)) != 1 {
+				w.Header().Set("WWW-Authenticate", `Basic realm="SiaSigner"`)
+				http.Error(w, "incorrect password", http.StatusUnauthorized)
+				return
+			}
+		}
+
+		var sr modules.SignerRequest
+		resp := modules.SignerResponse{JSONRPC: "2.0"}
+		fail := func(code int, err error) {
+			resp.Error = &modules.SignerError{Code: code, Message: err.Error()}
+		}
+		var result interface{}
+		if err := json.NewDecoder(http.MaxBytesReader(w, req.Body, signerMaxMessageSize)).Decode(&sr); err != nil {
+			fail(signerErrParse, err)
+		} else {
+			resp.ID = sr.ID
+			switch sr.Method {
+			case modules.SignerMethodAddresses:
+				var p modules.SignerAddressesParams
+				if err := json.Unmarshal(sr.Params, &p); err != nil {
+					fail(signerErrInvalidParams, err)
+				} else if p.N == 0 || p.N > maxSignerAddresses {
+					fail(signerErrInvalidParams, errSignerAddressCount)
+				} else {
+					ucs := make([]types.UnlockConditions, 0, p.N)
+					for _, sk := range generateKeys(seed, p.Start, p.N) {
+						ucs = append(ucs, sk.UnlockConditions)
+					}
+					result = ucs
+				}
+			case modules.SignerMethodSignTransaction:
+				var p modules.SignerSignTransactionParams
+				if err := json.Unmarshal(sr.Params, &p); err != nil {
+					fail(signerErrInvalidParams, err)
+				} else if err := SignTransaction(&p.Transaction, seed, p.ToSign, p.Height); err != nil {
+					fail(signerErrServer, err)
+				} else {
+					result = p.Transaction
+				}
+			default:
+				fail(signerErrMethodNotFound, errors.New("unknown method "+sr.Method))
+			}
+		}
+		if resp.Error == nil {
+			var err error
+			resp.Result, err = json.Marshal(result)
+			if err != nil {
+				fail(signerErrServer, err)
+				resp.Result = nil
+			}
+		}
+		w.Header().Set("Content-Type", "application/json")
+		json.NewEncoder(w).Encode(resp)
+	})
+}
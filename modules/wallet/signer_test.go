@@ -0,0 +1,234 @@
+package wallet
+
+import (
+	"net/http/httptest"
+	"net/url"
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestSigner tests sending siacoins from the addresses of an external signer.
+func TestSigner(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	seed := modules.Seed{1, 2, 3}
+	srv := httptest.NewServer(SignerHandler(seed, "foo"))
+	defer srv.Close()
+	u, err := url.Parse(srv.URL)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// A wrong password is rejected.
+	u.User = url.UserPassword("", "bar")
+	if err := wt.wallet.SetSigner(u.String()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.AddSignerAddresses(1, true); err == nil {
+		t.Fatal("expected wrong password to be rejected")
+	}
+	u.User = url.UserPassword("", "foo")
+	if err := wt.wallet.SetSigner(u.String()); err != nil {
+		t.Fatal(err)
+	}
+	ucs, err := wt.wallet.AddSignerAddresses(2, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	for i, sk := range generateKeys(seed, 0, 2) {
+		if sk.UnlockConditions.UnlockHash() != ucs[i].UnlockHash() {
+			t.Fatal("signer returned the wrong addresses")
+		}
+	}
+	ws, err := wt.wallet.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.Addresses != 2 || ws.Address == u.String() {
+		t.Fatal("unexpected signer", ws)
+	}
+
+	// Fund a signer address from the wallet's own keys. The wallet only sends
+	// through the signer while one is configured.
+	if err := wt.wallet.SetSigner(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), ucs[0].UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetSigner(u.String()); err != nil {
+		t.Fatal(err)
+	}
+
+	// Send from the signer's address to the wallet.
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := uc.UnlockHash()
+	txns, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), dest)
+	if err != nil {
+		t.Fatal(err)
+	}
+	txn := txns[len(txns)-1]
+	for _, sci := range txn.SiacoinInputs {
+		if sci.UnlockConditions.UnlockHash() != ucs[0].UnlockHash() {
+			t.Fatal("transaction wasn't funded by the signer")
+		}
+	}
+	// The change was sent to a new signer address.
+	ws, err = wt.wallet.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if ws.Addresses != 3 {
+		t.Fatal("expected a change address to be fetched from the signer", ws)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	changeAddr := generateKeys(seed, 2, 1)[0].UnlockConditions.UnlockHash()
+	var received, change bool
+	for _, o := range outputs {
+		if o.UnlockHash == dest && o.Value.Equals(types.SiacoinPrecision.Mul64(10)) {
+			received = true
+		} else if o.UnlockHash == changeAddr {
+			change = true
+		}
+	}
+	if !received || !change {
+		t.Fatal("signed transaction wasn't confirmed", received, change)
+	}
+}
+
+// TestSignerLocked tests that a locked or seedless wallet funds transactions
+// from the addresses of its signer and has them signed by the signer.
+func TestSignerLocked(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	seed := modules.Seed{4, 5, 6}
+	srv := httptest.NewServer(SignerHandler(seed, ""))
+	defer srv.Close()
+
+	// Fund a signer address from the wallet's own keys.
+	if err := wt.wallet.SetSigner(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	ucs, err := wt.wallet.AddSignerAddresses(1, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.SetSigner(""); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(100), ucs[0].UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+	uc, err := wt.wallet.NextAddress()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dest := uc.UnlockHash()
+
+	// Lock the wallet, the signer signs for it.
+	if err := wt.wallet.SetSigner(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(10), dest); err != nil {
+		t.Fatal(err)
+	}
+
+	// The transaction builder funds and signs through the signer as well.
+	tb, err := wt.wallet.StartTransaction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := tb.FundSiacoins(types.SiacoinPrecision.Mul64(21)); err != nil {
+		t.Fatal(err)
+	}
+	tb.AddMinerFee(types.SiacoinPrecision)
+	tb.AddSiacoinOutput(types.SiacoinOutput{Value: types.SiacoinPrecision.Mul64(20), UnlockHash: dest})
+	txnSet, err := tb.Sign(true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.tpool.AcceptTransactionSet(txnSet); err != nil {
+		t.Fatal(err)
+	}
+
+	// The miner needs an unlocked wallet.
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// A wallet without a seed can spend the outputs of the signer too.
+	w, err := New(wt.cs, wt.tpool, filepath.Join(wt.persistDir, "seedless"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.SetSigner(srv.URL); err != nil {
+		t.Fatal(err)
+	}
+	ws, err := wt.wallet.Signer()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.AddSignerAddresses(ws.Addresses, false); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := w.SendSiacoins(types.SiacoinPrecision.Mul64(30), dest); err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.miner.AddBlock(); err != nil {
+		t.Fatal(err)
+	}
+
+	// All of the payments were received by the wallet.
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	received := types.ZeroCurrency
+	for _, o := range outputs {
+		if o.UnlockHash == dest {
+			received = received.Add(o.Value)
+		}
+	}
+	if !received.Equals(types.SiacoinPrecision.Mul64(60)) {
+		t.Fatal("wallet didn't receive the payments", received.HumanString())
+	}
+}
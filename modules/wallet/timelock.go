@@ -0,0 +1,229 @@
+package wallet
+
+import (
+	"fmt"
+
+	"gitlab.com/NebulousLabs/bolt"
+
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+var (
+	// errTimelockInPast is returned if a time-locked address is requested
+	// for a height that has already been reached.
+	errTimelockInPast = errors.New("timelock must be above the current height")
+
+	// errTimelockGranularity is returned if a time-locked address is
+	// requested for a height that isn't a multiple of timelockGranularity.
+	errTimelockGranularity = fmt.Errorf("timelock must be a multiple of %v", timelockGranularity)
+
+	// errTimelockTooFar is returned if a time-locked address is requested for
+	// a height more than maxTimelockDuration blocks in the future.
+	errTimelockTooFar = fmt.Errorf("timelock must be at most %v blocks above the current height", maxTimelockDuration)
+
+	// timelockSeedSpecifier is used to derive the seed of the time-locked
+	// addresses from the primary seed.
+	timelockSeedSpecifier = types.NewSpecifier("timelock")
+)
+
+// timelockedAddress records how a time-locked address was derived from the
+// primary seed.
+type timelockedAddress struct {
+	Index    uint64
+	Timelock types.BlockHeight
+}
+
+// timelockSeed derives the seed of the time-locked addresses from the primary
+// seed.
+func timelockSeed(primarySeed modules.Seed) modules.Seed {
+	return modules.Seed(crypto.HashAll(primarySeed, timelockSeedSpecifier))
+}
+
+// generateTimelockedKey generates the spendable key at index of the
+// time-locked address seed, with the timelock applied to its unlock
+// conditions.
+func generateTimelockedKey(primarySeed modules.Seed, ta timelockedAddress) spendableKey {
+	sk := generateSpendableKey(timelockSeed(primarySeed), ta.Index)
+	sk.UnlockConditions.Timelock = ta.Timelock
+	return sk
+}
+
+// A timelockScanner tracks the time-locked addresses derived from a seed while
+// a seedScanner scans the blockchain. Every index is combined with every
+// timelock that is a multiple of timelockGranularity.
+type timelockScanner struct {
+	addresses        map[types.UnlockHash]timelockedAddress
+	found            map[types.UnlockHash]timelockedAddress
+	largestIndexSeen uint64
+	maxTimelock      types.BlockHeight
+	numIndices       uint64
+	seed             modules.Seed
+}
+
+// newTimelockScanner returns a timelockScanner for the first
+// timelockLookahead indices of the time-locked addresses of primarySeed and
+// timelocks up to maxTimelock. Since the timelocks of new addresses are
+// limited to maxTimelockDuration blocks above the current height, no used
+// address can have a timelock above the current height plus
+// maxTimelockDuration.
+func newTimelockScanner(primarySeed modules.Seed, maxTimelock types.BlockHeight) (*timelockScanner, error) {
+	s := &timelockScanner{
+		addresses:   make(map[types.UnlockHash]timelockedAddress),
+		found:       make(map[types.UnlockHash]timelockedAddress),
+		maxTimelock: maxTimelock,
+		seed:        timelockSeed(primarySeed),
+	}
+	return s, s.generateKeys(timelockLookahead)
+}
+
+// generateKeys adds the addresses of n more indices to the scanner.
+func (s *timelockScanner) generateKeys(n uint64) error {
+	if (s.numIndices+n)*uint64(s.maxTimelock/timelockGranularity) > maxScanKeys {
+		return errMaxKeys
+	}
+	for i, sk := range generateKeys(s.seed, s.numIndices, n) {
+		uc := sk.UnlockConditions
+		for timelock := timelockGranularity; timelock <= s.maxTimelock; timelock += timelockGranularity {
+			uc.Timelock = timelock
+			s.addresses[uc.UnlockHash()] = timelockedAddress{
+				Index:    s.numIndices + uint64(i),
+				Timelock: timelock,
+			}
+		}
+	}
+	s.numIndices += n
+	return nil
+}
+
+// done reports whether the scanned indices cover all of the used addresses.
+func (s *timelockScanner) done() bool {
+	return len(s.found) == 0 || s.largestIndexSeen < s.numIndices/2
+}
+
+// processConsensusChange records the time-locked addresses that received
+// siacoins or siafunds.
+func (s *timelockScanner) processConsensusChange(cc modules.ConsensusChange) {
+	var uhs []types.UnlockHash
+	for _, diff := range cc.SiacoinOutputDiffs {
+		uhs = append(uhs, diff.SiacoinOutput.UnlockHash)
+	}
+	for _, diff := range cc.SiafundOutputDiffs {
+		uhs = append(uhs, diff.SiafundOutput.UnlockHash)
+	}
+	for _, uh := range uhs {
+		ta, exists := s.addresses[uh]
+		if !exists {
+			continue
+		}
+		s.found[uh] = ta
+		if ta.Index > s.largestIndexSeen {
+			s.largestIndexSeen = ta.Index
+		}
+	}
+}
+
+// integrateTimelockedKeys loads the keys of the time-locked addresses into
+// the wallet.
+func (w *Wallet) integrateTimelockedKeys(tx *bolt.Tx) error {
+	return dbForEachTimelockedAddress(tx, func(_ types.UnlockHash, ta timelockedAddress) {
+		sk := generateTimelockedKey(w.primarySeed, ta)
+		w.keys[sk.UnlockConditions.UnlockHash()] = sk
+	})
+}
+
+// lockHeight returns the timelock of the address uh, or zero if outputs of
+// the address can be spent at any height.
+func (w *Wallet) lockHeight(tx *bolt.Tx, uh types.UnlockHash) types.BlockHeight {
+	if ta, err := dbGetTimelockedAddress(tx, uh); err == nil {
+		return ta.Timelock
+	}
+	if sk, ok := w.keys[uh]; ok {
+		return sk.UnlockConditions.Timelock
+	}
+	uc, _ := dbGetUnlockConditions(tx, uh)
+	return uc.Timelock
+}
+
+// NextTimelockedAddress returns a new address generated from the primary seed
+// whose outputs can't be spent before the timelock height. The timelock must
+// be a multiple of timelockGranularity and at most maxTimelockDuration blocks
+// above the current height, so that the address can be found again when the
+// wallet is recovered from its seed.
+func (w *Wallet) NextTimelockedAddress(timelock types.BlockHeight) (types.UnlockConditions, error) {
+	if err := w.tg.Add(); err != nil {
+		return types.UnlockConditions{}, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	if !w.unlocked {
+		return types.UnlockConditions{}, modules.ErrLockedWallet
+	}
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	if timelock <= height {
+		return types.UnlockConditions{}, errTimelockInPast
+	}
+	if timelock%timelockGranularity != 0 {
+		return types.UnlockConditions{}, errTimelockGranularity
+	}
+	if timelock > height+maxTimelockDuration {
+		return types.UnlockConditions{}, errTimelockTooFar
+	}
+
+	// Use the index after the largest index of the existing time-locked
+	// addresses. Recovered addresses might not have consecutive indices.
+	var ta timelockedAddress
+	err = dbForEachTimelockedAddress(w.dbTx, func(_ types.UnlockHash, existing timelockedAddress) {
+		if existing.Index >= ta.Index {
+			ta.Index = existing.Index + 1
+		}
+	})
+	if err != nil {
+		return types.UnlockConditions{}, err
+	}
+	ta.Timelock = timelock
+	sk := generateTimelockedKey(w.primarySeed, ta)
+	uh := sk.UnlockConditions.UnlockHash()
+	if err := dbPutTimelockedAddress(w.dbTx, uh, ta); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	w.keys[uh] = sk
+	if err := w.syncDB(); err != nil {
+		return types.UnlockConditions{}, err
+	}
+	return sk.UnlockConditions, nil
+}
+
+// LockedBalance returns the part of the confirmed siacoin balance that is
+// held by time-locked outputs which can't be spent yet.
+func (w *Wallet) LockedBalance() (locked types.Currency, err error) {
+	if err := w.tg.Add(); err != nil {
+		return types.ZeroCurrency, modules.ErrWalletShutdown
+	}
+	defer w.tg.Done()
+
+	// dustThreshold has to be obtained separate from the lock
+	dustThreshold, err := w.DustThreshold()
+	if err != nil {
+		return types.ZeroCurrency, modules.ErrWalletShutdown
+	}
+
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	height, err := dbGetConsensusHeight(w.dbTx)
+	if err != nil {
+		return types.ZeroCurrency, err
+	}
+	err = dbForEachSiacoinOutput(w.dbTx, func(_ types.SiacoinOutputID, sco types.SiacoinOutput) {
+		if sco.Value.Cmp(dustThreshold) > 0 && w.lockHeight(w.dbTx, sco.UnlockHash) > height {
+			locked = locked.Add(sco.Value)
+		}
+	})
+	return
+}
@@ -0,0 +1,192 @@
+package wallet
+
+import (
+	"path/filepath"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/types"
+	"gitlab.com/NebulousLabs/errors"
+)
+
+// TestTimelockedAddress tests sending to and spending from a time-locked
+// address of the wallet.
+func TestTimelockedAddress(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, err := wt.wallet.NextTimelockedAddress(height); !errors.Contains(err, errTimelockInPast) {
+		t.Fatal("expected errTimelockInPast, got", err)
+	}
+	if _, err := wt.wallet.NextTimelockedAddress(((height+maxTimelockDuration)/timelockGranularity + 1) * timelockGranularity); !errors.Contains(err, errTimelockTooFar) {
+		t.Fatal("expected errTimelockTooFar, got", err)
+	}
+	timelock := (height/timelockGranularity + 2) * timelockGranularity
+	if _, err := wt.wallet.NextTimelockedAddress(timelock + 1); !errors.Contains(err, errTimelockGranularity) {
+		t.Fatal("expected errTimelockGranularity, got", err)
+	}
+	uc, err := wt.wallet.NextTimelockedAddress(timelock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.Timelock != timelock {
+		t.Fatal("address isn't time-locked", uc)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	if _, err := wt.wallet.SendSiacoins(amount, uc.UnlockHash()); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	// The keys of the address are restored when the wallet is unlocked.
+	if err := wt.wallet.Lock(); err != nil {
+		t.Fatal(err)
+	}
+	if err := wt.wallet.Unlock(wt.walletMasterKey); err != nil {
+		t.Fatal(err)
+	}
+
+	outputs, err := wt.wallet.UnspentOutputs()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var locked modules.UnspentOutput
+	for _, o := range outputs {
+		if o.UnlockHash == uc.UnlockHash() {
+			locked = o
+		}
+	}
+	if locked.LockHeight != timelock || !locked.Value.Equals(amount) {
+		t.Fatal("unexpected time-locked output", locked)
+	}
+	lb, err := wt.wallet.LockedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lb.Equals(amount) {
+		t.Fatal("unexpected locked balance", lb.HumanString())
+	}
+
+	// The output can't be spent before the timelock.
+	cc := modules.CoinControl{Inputs: []types.SiacoinOutputID{types.SiacoinOutputID(locked.ID)}, SpendAll: true}
+	dest := types.SiacoinOutput{UnlockHash: types.UnlockHash{1}}
+	if _, _, err := wt.wallet.SendSiacoinsCoinControl([]types.SiacoinOutput{dest}, cc); !errors.Contains(err, errOutputTimelock) {
+		t.Fatal("expected errOutputTimelock, got", err)
+	}
+	for height < timelock {
+		if err := wt.addBlockNoPayout(); err != nil {
+			t.Fatal(err)
+		}
+		if height, err = wt.wallet.Height(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	lb, err = wt.wallet.LockedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lb.IsZero() {
+		t.Fatal("balance should be unlocked", lb.HumanString())
+	}
+	if _, _, err := wt.wallet.SendSiacoinsCoinControl([]types.SiacoinOutput{dest}, cc); err != nil {
+		t.Fatal(err)
+	}
+}
+
+// TestTimelockedAddressRecovery tests that time-locked addresses are restored
+// when the wallet is recovered from its seed.
+func TestTimelockedAddressRecovery(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	wt, err := createWalletTester(t.Name(), modules.ProdDependencies)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer wt.closeWt()
+
+	// Create three time-locked addresses and only fund the first and last
+	// one.
+	height, err := wt.wallet.Height()
+	if err != nil {
+		t.Fatal(err)
+	}
+	amount := types.SiacoinPrecision.Mul64(100)
+	var funded []types.UnlockConditions
+	for i := types.BlockHeight(0); i < 3; i++ {
+		timelock := (height/timelockGranularity + 2 + i) * timelockGranularity
+		uc, err := wt.wallet.NextTimelockedAddress(timelock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if i == 1 {
+			continue
+		}
+		if _, err := wt.wallet.SendSiacoins(amount, uc.UnlockHash()); err != nil {
+			t.Fatal(err)
+		}
+		funded = append(funded, uc)
+	}
+	if err := wt.addBlockNoPayout(); err != nil {
+		t.Fatal(err)
+	}
+
+	seed, _, err := wt.wallet.PrimarySeed()
+	if err != nil {
+		t.Fatal(err)
+	}
+	dir := filepath.Join(build.TempDir(modules.WalletDir, t.Name()+"1"), modules.WalletDir)
+	w, err := New(wt.cs, wt.tpool, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer w.Close()
+	if err := w.InitFromSeed(nil, seed); err != nil {
+		t.Fatal(err)
+	}
+	if err := w.Unlock(crypto.NewWalletKey(crypto.HashObject(seed))); err != nil {
+		t.Fatal(err)
+	}
+
+	// The funded addresses and their locked balance are restored.
+	lb, err := w.LockedBalance()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !lb.Equals(amount.Mul64(2)) {
+		t.Fatal("unexpected locked balance", lb.HumanString())
+	}
+	for _, uc := range funded {
+		if lh := w.lockHeight(w.dbTx, uc.UnlockHash()); lh != uc.Timelock {
+			t.Fatal("unexpected lock height of recovered address", lh, uc.Timelock)
+		}
+	}
+
+	// A new time-locked address doesn't reuse the index of a recovered
+	// address.
+	uc, err := w.NextTimelockedAddress(funded[0].Timelock)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if uc.UnlockHash() == funded[0].UnlockHash() {
+		t.Fatal("new time-locked address reused a recovered address")
+	}
+	ta, err := dbGetTimelockedAddress(w.dbTx, uc.UnlockHash())
+	if err != nil || ta.Index != 3 {
+		t.Fatal("expected the new address to follow the largest recovered index", ta, err)
+	}
+}
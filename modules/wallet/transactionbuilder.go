@@ -2,10 +2,10 @@ package wallet
 
 import (
 	"bytes"
-	"errors"
 	"sort"
 
 	"gitlab.com/NebulousLabs/bolt"
+	"gitlab.com/NebulousLabs/errors"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
@@ -19,6 +19,10 @@ var (
 	// meaning that future calls to Sign will result in an invalid transaction.
 	errBuilderAlreadySigned = errors.New("sign has already been called on this transaction builder, multiple calls can cause issues")
 
+	// errAccountOutput indicates an output belongs to a different account
+	// than the one funding the transaction.
+	errAccountOutput = errors.New("output belongs to a different account")
+
 	// errDustOutput indicates an output is not spendable because it is dust.
 	errDustOutput = errors.New("output is too small")
 
@@ -29,6 +33,10 @@ var (
 	// the allowed height.
 	errSpendHeightTooHigh = errors.New("output spend height exceeds the allowed height")
 
+	// errWatchOnlyOutput indicates an output belongs to a watched address that
+	// the wallet doesn't hold the keys for, e.g. a multisig address.
+	errWatchOnlyOutput = errors.New("output belongs to a watch-only address")
+
 	// errReplaceIndexOutOfBounds indicated that the output index is out of
 	// bounds.
 	errReplaceIndexOutOfBounds = errors.New("replacement output index out of bounds")
@@ -48,6 +56,10 @@ type transactionBuilder struct {
 	siafundInputs         []int
 	transactionSignatures []int
 
+	// account is the name of the account that funds the transaction and
+	// receives its change.
+	account string
+
 	wallet *Wallet
 }
 
@@ -96,7 +108,25 @@ func addSignatures(txn *types.Transaction, cf types.CoveredFields, uc types.Unlo
 }
 
 // checkOutput is a helper function used to determine if an output is usable.
+// Only outputs of the primary account are usable.
 func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id types.SiacoinOutputID, output types.SiacoinOutput, dustThreshold types.Currency) error {
+	return w.checkAccountOutput(tx, currentHeight, id, output, dustThreshold, "")
+}
+
+// checkAccountOutput is the equivalent of checkOutput for transactions that
+// are funded from the named account.
+func (w *Wallet) checkAccountOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id types.SiacoinOutputID, output types.SiacoinOutput, dustThreshold types.Currency, account string) error {
+	if w.accountKeys[output.UnlockHash] != account {
+		return errAccountOutput
+	}
+	spendKey, ok := w.keys[output.UnlockHash]
+	return checkSpendableOutput(tx, currentHeight, id, output, dustThreshold, spendKey.UnlockConditions, ok)
+}
+
+// checkSpendableOutput checks an output whose address has the unlock
+// conditions uc. ok reports whether the wallet is able to sign for the
+// address.
+func checkSpendableOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id types.SiacoinOutputID, output types.SiacoinOutput, dustThreshold types.Currency, uc types.UnlockConditions, ok bool) error {
 	// Check that an output is not dust
 	if output.Value.Cmp(dustThreshold) < 0 {
 		return errDustOutput
@@ -108,8 +138,10 @@ func (w *Wallet) checkOutput(tx *bolt.Tx, currentHeight types.BlockHeight, id ty
 			return errSpendHeightTooHigh
 		}
 	}
-	outputUnlockConditions := w.keys[output.UnlockHash].UnlockConditions
-	if currentHeight < outputUnlockConditions.Timelock {
+	if !ok {
+		return errWatchOnlyOutput
+	}
+	if currentHeight < uc.Timelock {
 		return errOutputTimelock
 	}
 
@@ -136,6 +168,7 @@ func (tb *transactionBuilder) Copy() modules.TransactionBuilder {
 	copy(copyBuilder.transactionSignatures, tb.transactionSignatures)
 
 	copyBuilder.signed = tb.signed
+	copyBuilder.account = tb.account
 	return copyBuilder
 }
 
@@ -189,7 +222,9 @@ func (tb *transactionBuilder) MarkWalletInputs() bool {
 // FundSiacoins will add a siacoin input of exactly 'amount' to the
 // transaction. A parent transaction may be needed to achieve an input with the
 // correct value. The siacoin input will not be signed until 'Sign' is called
-// on the transaction builder.
+// on the transaction builder. If a signer is configured, the input is funded
+// from the signer's addresses and the parent transaction is signed by the
+// signer.
 func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 	// dustThreshold has to be obtained separate from the lock
 	dustThreshold, err := tb.wallet.DustThreshold()
@@ -197,12 +232,64 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		return err
 	}
 
+	// The exact and refund outputs of the parent transaction have to be sent
+	// to addresses of the signer, which are fetched outside of the lock.
+	sc, err := tb.wallet.managedSignerClient()
+	if err != nil {
+		return err
+	}
+	var signerUCs []types.UnlockConditions
+	if sc != nil {
+		if tb.account != "" {
+			return errAccountWithSigner
+		}
+		signerUCs, err = tb.wallet.managedAddSignerAddresses(sc, 2, true)
+		if err != nil {
+			return errors.AddContext(err, "unable to get signer addresses")
+		}
+	}
+
 	tb.wallet.mu.Lock()
-	defer tb.wallet.mu.Unlock()
+	parentTxn, parentUnlockConditions, err := tb.fundSiacoins(amount, dustThreshold, signerUCs)
+	tb.wallet.mu.Unlock()
+	if err != nil {
+		return err
+	}
+	if sc != nil {
+		signed, err := tb.wallet.managedSignWithSigner(sc, parentTxn)
+		if err != nil {
+			tb.wallet.managedReleaseInputs(parentTxn)
+			tb.wallet.mu.Lock()
+			if err := dbDeleteSpentOutput(tb.wallet.dbTx, types.OutputID(parentTxn.SiacoinOutputID(0))); err != nil {
+				tb.wallet.log.Println("WARN: failed to release output of unsigned parent transaction:", err)
+			}
+			tb.wallet.mu.Unlock()
+			return err
+		}
+		parentTxn = signed
+	}
+
+	// Add the exact output.
+	newInput := types.SiacoinInput{
+		ParentID:         parentTxn.SiacoinOutputID(0),
+		UnlockConditions: parentUnlockConditions,
+	}
+	tb.newParents = append(tb.newParents, len(tb.parents))
+	tb.parents = append(tb.parents, parentTxn)
+	tb.siacoinInputs = append(tb.siacoinInputs, len(tb.transaction.SiacoinInputs))
+	tb.transaction.SiacoinInputs = append(tb.transaction.SiacoinInputs, newInput)
+	return nil
+}
 
+// fundSiacoins creates a parent transaction with an output of exactly amount
+// and marks the outputs it spends as spent. If signerUCs is set, the parent
+// transaction spends outputs of the signer's addresses, pays to signerUCs and
+// is left for the signer to sign.
+func (tb *transactionBuilder) fundSiacoins(amount, dustThreshold types.Currency, signerUCs []types.UnlockConditions) (types.Transaction, types.UnlockConditions, error) {
+	remote := signerUCs != nil
 	consensusHeight, err := dbGetConsensusHeight(tb.wallet.dbTx)
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, err
 	}
 
 	// Collect a value-sorted set of siacoin outputs.
@@ -212,13 +299,16 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		so.outputs = append(so.outputs, sco)
 	})
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, err
 	}
 	// Add all of the unconfirmed outputs as well.
 	for _, upt := range tb.wallet.unconfirmedProcessedTransactions {
 		for i, sco := range upt.Transaction.SiacoinOutputs {
 			// Determine if the output belongs to the wallet.
 			_, exists := tb.wallet.keys[sco.UnlockHash]
+			if remote {
+				exists = dbIsSignerAddress(tb.wallet.dbTx, sco.UnlockHash)
+			}
 			if !exists {
 				continue
 			}
@@ -242,7 +332,12 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		scoid := so.ids[i]
 		sco := so.outputs[i]
 		// Check that the output can be spent.
-		if err := tb.wallet.checkOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold); err != nil {
+		if remote {
+			err = tb.wallet.checkSignerOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold)
+		} else {
+			err = tb.wallet.checkAccountOutput(tb.wallet.dbTx, consensusHeight, scoid, sco, dustThreshold, tb.account)
+		}
+		if err != nil {
 			if err == errSpendHeightTooHigh {
 				potentialFund = potentialFund.Add(sco.Value)
 			}
@@ -250,9 +345,13 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		}
 
 		// Add a siacoin input for this output.
+		uc := tb.wallet.keys[sco.UnlockHash].UnlockConditions
+		if remote {
+			uc, _ = dbGetUnlockConditions(tb.wallet.dbTx, sco.UnlockHash)
+		}
 		sci := types.SiacoinInput{
 			ParentID:         scoid,
-			UnlockConditions: tb.wallet.keys[sco.UnlockHash].UnlockConditions,
+			UnlockConditions: uc,
 		}
 		parentTxn.SiacoinInputs = append(parentTxn.SiacoinInputs, sci)
 		spentScoids = append(spentScoids, scoid)
@@ -265,17 +364,25 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		}
 	}
 	if potentialFund.Cmp(amount) >= 0 && fund.Cmp(amount) < 0 {
-		return modules.ErrIncompleteTransactions
+		return types.Transaction{}, types.UnlockConditions{}, modules.ErrIncompleteTransactions
 	}
 	if fund.Cmp(amount) < 0 {
-		return modules.ErrLowBalance
+		return types.Transaction{}, types.UnlockConditions{}, modules.ErrLowBalance
 	}
 
 	// Create and add the output that will be used to fund the standard
 	// transaction.
-	parentUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	nextAddress := func() (types.UnlockConditions, error) {
+		if remote {
+			uc := signerUCs[0]
+			signerUCs = signerUCs[1:]
+			return uc, nil
+		}
+		return tb.wallet.nextAccountAddress(tb.wallet.dbTx, tb.account)
+	}
+	parentUnlockConditions, err := nextAddress()
 	if err != nil {
-		return err
+		return types.Transaction{}, types.UnlockConditions{}, err
 	}
 
 	exactOutput := types.SiacoinOutput{
@@ -286,9 +393,9 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		refundUnlockConditions, err := nextAddress()
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, err
 		}
 		refundOutput := types.SiacoinOutput{
 			Value:      fund.Sub(amount),
@@ -297,35 +404,31 @@ func (tb *transactionBuilder) FundSiacoins(amount types.Currency) error {
 		parentTxn.SiacoinOutputs = append(parentTxn.SiacoinOutputs, refundOutput)
 	}
 
-	// Sign all of the inputs to the parent transaction.
+	// Sign all of the inputs to the parent transaction, or leave them to the
+	// signer.
 	for _, sci := range parentTxn.SiacoinInputs {
+		if remote {
+			addSignerPlaceholders(&parentTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID))
+			continue
+		}
 		addSignatures(&parentTxn, types.FullCoveredFields, sci.UnlockConditions, crypto.Hash(sci.ParentID), tb.wallet.keys[sci.UnlockConditions.UnlockHash()], consensusHeight)
 	}
 	// Mark the parent output as spent. Must be done after the transaction is
-	// finished because otherwise the txid and output id will change.
+	// finished because otherwise the txid and output id will change. The
+	// signatures don't affect the ids, so the signer can sign it later.
 	err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(parentTxn.SiacoinOutputID(0)), consensusHeight)
 	if err != nil {
-		return err
-	}
-
-	// Add the exact output.
-	newInput := types.SiacoinInput{
-		ParentID:         parentTxn.SiacoinOutputID(0),
-		UnlockConditions: parentUnlockConditions,
+		return types.Transaction{}, types.UnlockConditions{}, err
 	}
-	tb.newParents = append(tb.newParents, len(tb.parents))
-	tb.parents = append(tb.parents, parentTxn)
-	tb.siacoinInputs = append(tb.siacoinInputs, len(tb.transaction.SiacoinInputs))
-	tb.transaction.SiacoinInputs = append(tb.transaction.SiacoinInputs, newInput)
 
 	// Mark all outputs that were spent as spent.
 	for _, scoid := range spentScoids {
 		err = dbPutSpentOutput(tb.wallet.dbTx, types.OutputID(scoid), consensusHeight)
 		if err != nil {
-			return err
+			return types.Transaction{}, types.UnlockConditions{}, err
 		}
 	}
-	return nil
+	return parentTxn, parentUnlockConditions, nil
 }
 
 // FundSiafunds will add a siafund input of exactly 'amount' to the
@@ -356,6 +459,9 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 		} else if err := encoding.Unmarshal(sfoBytes, &sfo); err != nil {
 			return err
 		}
+		if tb.wallet.accountKeys[sfo.UnlockHash] != tb.account {
+			continue
+		}
 
 		// Check that this output has not recently been spent by the wallet.
 		spendHeight, err := dbGetSpentOutput(tb.wallet.dbTx, types.OutputID(sfoid))
@@ -378,7 +484,7 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 		}
 
 		// Add a siafund input for this output.
-		parentClaimUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		parentClaimUnlockConditions, err := tb.wallet.nextAccountAddress(tb.wallet.dbTx, tb.account)
 		if err != nil {
 			return err
 		}
@@ -406,7 +512,7 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 
 	// Create and add the output that will be used to fund the standard
 	// transaction.
-	parentUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	parentUnlockConditions, err := tb.wallet.nextAccountAddress(tb.wallet.dbTx, tb.account)
 	if err != nil {
 		return err
 	}
@@ -418,7 +524,7 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 
 	// Create a refund output if needed.
 	if !amount.Equals(fund) {
-		refundUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+		refundUnlockConditions, err := tb.wallet.nextAccountAddress(tb.wallet.dbTx, tb.account)
 		if err != nil {
 			return err
 		}
@@ -435,7 +541,7 @@ func (tb *transactionBuilder) FundSiafunds(amount types.Currency) error {
 	}
 
 	// Add the exact output.
-	claimUnlockConditions, err := tb.wallet.nextPrimarySeedAddress(tb.wallet.dbTx)
+	claimUnlockConditions, err := tb.wallet.nextAccountAddress(tb.wallet.dbTx, tb.account)
 	if err != nil {
 		return err
 	}
@@ -669,16 +775,22 @@ func (tb *transactionBuilder) Sign(wholeTransaction bool) ([]types.Transaction,
 	}
 
 	// For each siacoin input in the transaction that we added, provide a
-	// signature.
-	tb.wallet.mu.RLock()
-	defer tb.wallet.mu.RUnlock()
+	// signature. Inputs of the signer's addresses are left for the signer.
+	numSigs, numSigIndices, signed := len(tb.transaction.TransactionSignatures), len(tb.transactionSignatures), tb.signed
+	var toSign []crypto.Hash
+	tb.wallet.mu.Lock()
 	for _, inputIndex := range tb.siacoinInputs {
 		input := tb.transaction.SiacoinInputs[inputIndex]
-		key, ok := tb.wallet.keys[input.UnlockConditions.UnlockHash()]
-		if !ok {
+		var newSigIndices []int
+		if key, ok := tb.wallet.keys[input.UnlockConditions.UnlockHash()]; ok {
+			newSigIndices = addSignatures(&tb.transaction, coveredFields, input.UnlockConditions, crypto.Hash(input.ParentID), key, consensusHeight)
+		} else if dbIsSignerAddress(tb.wallet.dbTx, input.UnlockConditions.UnlockHash()) {
+			newSigIndices = addSignerPlaceholders(&tb.transaction, coveredFields, input.UnlockConditions, crypto.Hash(input.ParentID))
+			toSign = append(toSign, crypto.Hash(input.ParentID))
+		} else {
+			tb.wallet.mu.Unlock()
 			return nil, errors.New("transaction builder added an input that it cannot sign")
 		}
-		newSigIndices := addSignatures(&tb.transaction, coveredFields, input.UnlockConditions, crypto.Hash(input.ParentID), key, consensusHeight)
 		tb.transactionSignatures = append(tb.transactionSignatures, newSigIndices...)
 		tb.signed = true // Signed is set to true after one successful signature to indicate that future signings can cause issues.
 	}
@@ -686,12 +798,38 @@ func (tb *transactionBuilder) Sign(wholeTransaction bool) ([]types.Transaction,
 		input := tb.transaction.SiafundInputs[inputIndex]
 		key, ok := tb.wallet.keys[input.UnlockConditions.UnlockHash()]
 		if !ok {
+			tb.wallet.mu.Unlock()
 			return nil, errors.New("transaction builder added an input that it cannot sign")
 		}
 		newSigIndices := addSignatures(&tb.transaction, coveredFields, input.UnlockConditions, crypto.Hash(input.ParentID), key, consensusHeight)
 		tb.transactionSignatures = append(tb.transactionSignatures, newSigIndices...)
 		tb.signed = true // Signed is set to true after one successful signature to indicate that future signings can cause issues.
 	}
+	tb.wallet.mu.Unlock()
+
+	if len(toSign) > 0 {
+		err := func() error {
+			sc, err := tb.wallet.managedSignerClient()
+			if err != nil {
+				return err
+			} else if sc == nil {
+				return errNoSigner
+			}
+			txn, err := signWithSigner(sc, tb.transaction, toSign, consensusHeight)
+			if err != nil {
+				return err
+			}
+			tb.transaction = txn
+			return nil
+		}()
+		if err != nil {
+			// Drop the signatures so that signing can be retried.
+			tb.transaction.TransactionSignatures = tb.transaction.TransactionSignatures[:numSigs]
+			tb.transactionSignatures = tb.transactionSignatures[:numSigIndices]
+			tb.signed = signed
+			return nil, err
+		}
+	}
 
 	// Get the transaction set and delete the transaction from the registry.
 	txnSet := append(tb.parents, tb.transaction)
@@ -541,6 +541,10 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 		w.log.Severe("ERROR: failed to apply consensus change:", err)
 		w.dbRollback = true
 	}
+	if err := w.updatePaymentRequests(w.dbTx, cc); err != nil {
+		w.log.Severe("ERROR: failed to update payment requests:", err)
+		w.dbRollback = true
+	}
 	if err := dbPutConsensusChangeID(w.dbTx, cc.ID); err != nil {
 		w.log.Severe("ERROR: failed to update consensus change ID:", err)
 		w.dbRollback = true
@@ -548,6 +552,7 @@ func (w *Wallet) ProcessConsensusChange(cc modules.ConsensusChange) {
 
 	if cc.Synced {
 		go w.threadedDefragWallet()
+		go w.threadedSendScheduledPayments()
 	}
 }
 
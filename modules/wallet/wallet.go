@@ -53,12 +53,15 @@ type Wallet struct {
 	// storing secret keys in memory. subscribed indicates whether the wallet
 	// has subscribed to the consensus set yet - the wallet is unable to
 	// subscribe to the consensus set until it has been unlocked for the first
-	// time. The primary seed is used to generate new addresses for the
+	// time, unless a signer is configured. subscribedLocked indicates that
+	// the wallet subscribed without its keys and has to rescan once it is
+	// unlocked. The primary seed is used to generate new addresses for the
 	// wallet.
-	encrypted   bool
-	unlocked    bool
-	subscribed  bool
-	primarySeed modules.Seed
+	encrypted        bool
+	unlocked         bool
+	subscribed       bool
+	subscribedLocked bool
+	primarySeed      modules.Seed
 
 	// The wallet's dependencies.
 	cs    modules.ConsensusSet
@@ -70,9 +73,11 @@ type Wallet struct {
 	// the keys that are tracked on the blockchain. All keys are pregenerated
 	// from the seeds, when checking new outputs or spending outputs, the seeds
 	// are not referenced at all. The seeds are only stored so that the user
-	// may access them.
+	// may access them. accountKeys maps the keys that belong to an account to
+	// the name of the account; keys of the primary account are not included.
 	seeds        []modules.Seed
 	keys         map[types.UnlockHash]spendableKey
+	accountKeys  map[types.UnlockHash]string
 	lookahead    map[types.UnlockHash]uint64
 	watchedAddrs map[types.UnlockHash]struct{}
 
@@ -105,6 +110,14 @@ type Wallet struct {
 	// initialization.
 	scanLock siasync.TryMutex
 
+	// scheduledLock ensures that only one thread sends the scheduled
+	// payments at a time.
+	scheduledLock siasync.TryMutex
+
+	// signerMu serializes fetching addresses from the external signer so
+	// that concurrent calls don't fetch the same addresses.
+	signerMu sync.Mutex
+
 	// The wallet's ThreadGroup tells tracked functions to shut down and
 	// blocks until they have all exited before returning from Close.
 	tg threadgroup.ThreadGroup
@@ -195,6 +208,7 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 		tpool: tpool,
 
 		keys:         make(map[types.UnlockHash]spendableKey),
+		accountKeys:  make(map[types.UnlockHash]string),
 		lookahead:    make(map[types.UnlockHash]uint64),
 		watchedAddrs: make(map[types.UnlockHash]struct{}),
 
@@ -208,6 +222,24 @@ func NewCustomWallet(cs modules.ConsensusSet, tpool modules.TransactionPool, per
 	if err != nil {
 		return nil, err
 	}
+
+	// A wallet with a signer doesn't have to be unlocked to track and spend
+	// the outputs of the signer's addresses.
+	w.mu.Lock()
+	signer := dbGetSigner(w.dbTx)
+	w.mu.Unlock()
+	if signer == "" {
+		return w, nil
+	}
+	go func() {
+		if err := w.tg.Add(); err != nil {
+			return
+		}
+		defer w.tg.Done()
+		if err := w.managedSubscribeWithSigner(); err != nil {
+			w.log.Println("WARN: failed to subscribe wallet with signer:", err)
+		}
+	}()
 	return w, nil
 }
 
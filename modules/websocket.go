@@ -0,0 +1,48 @@
+package modules
+
+import (
+	"errors"
+	"net"
+	"net/url"
+
+	"golang.org/x/net/websocket"
+)
+
+const (
+	// WebSocketRPCPath is the path at which hosts serve the renter-host
+	// protocol over WebSocket.
+	WebSocketRPCPath = "/rpc"
+)
+
+var (
+	// ErrWebSocketScheme is returned when dialing a WebSocket URL that doesn't
+	// use the ws or wss scheme.
+	ErrWebSocketScheme = errors.New("WebSocket URL must use the ws or wss scheme")
+)
+
+// DialWebSocket establishes a WebSocket connection to the provided ws:// or
+// wss:// URL. The returned connection carries the renter-host protocol in
+// binary frames, so it can be used like a raw TCP connection to the host. The
+// certificate of a wss:// URL is verified against the hostname of the URL.
+func DialWebSocket(dialer *net.Dialer, rawURL string) (net.Conn, error) {
+	u, err := url.Parse(rawURL)
+	if err != nil {
+		return nil, err
+	}
+	if u.Scheme != "ws" && u.Scheme != "wss" {
+		return nil, ErrWebSocketScheme
+	}
+	// The origin is only checked by browsers, the host authenticates the
+	// renter through the session handshake instead.
+	config, err := websocket.NewConfig(rawURL, "http://"+u.Host+"/")
+	if err != nil {
+		return nil, err
+	}
+	config.Dialer = dialer
+	ws, err := websocket.DialConfig(config)
+	if err != nil {
+		return nil, err
+	}
+	ws.PayloadType = websocket.BinaryFrame
+	return ws, nil
+}
@@ -0,0 +1,66 @@
+package modules
+
+import (
+	"bytes"
+	"io"
+	"net"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/fastrand"
+	"golang.org/x/net/websocket"
+)
+
+// newWebSocketEchoServer creates a test server that echoes everything it
+// receives over WebSocket.
+func newWebSocketEchoServer() *httptest.Server {
+	return httptest.NewServer(websocket.Server{
+		Handshake: func(*websocket.Config, *http.Request) error { return nil },
+		Handler: func(ws *websocket.Conn) {
+			ws.PayloadType = websocket.BinaryFrame
+			io.Copy(ws, ws)
+		},
+	})
+}
+
+// TestDialWebSocket checks that a WebSocket connection can be used like a raw
+// TCP connection.
+func TestDialWebSocket(t *testing.T) {
+	t.Parallel()
+	srv := newWebSocketEchoServer()
+	defer srv.Close()
+
+	dialer := &net.Dialer{Timeout: time.Minute}
+	wsURL := "ws" + strings.TrimPrefix(srv.URL, "http") + WebSocketRPCPath
+	conn, err := DialWebSocket(dialer, wsURL)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer conn.Close()
+	if err := conn.SetDeadline(time.Now().Add(time.Minute)); err != nil {
+		t.Fatal(err)
+	}
+
+	// Write binary data in multiple frames and read it back in one go.
+	data := fastrand.Bytes(1 << 16)
+	go func() {
+		conn.Write(data[:1000])
+		conn.Write(data[1000:])
+	}()
+	received := make([]byte, len(data))
+	if _, err := io.ReadFull(conn, received); err != nil {
+		t.Fatal(err)
+	}
+	if !bytes.Equal(data, received) {
+		t.Fatal("received data doesn't match the sent data")
+	}
+
+	// Only the ws and wss schemes are accepted.
+	_, err = DialWebSocket(dialer, srv.URL)
+	if err != ErrWebSocketScheme {
+		t.Fatal("expected ErrWebSocketScheme but got", err)
+	}
+}
@@ -103,6 +103,18 @@ type API struct {
 	tpool    modules.TransactionPool
 	wallet   modules.Wallet
 
+	// renterProfiles are the additional renter profiles of the node.
+	// profileAPIs caches the apis that serve the requests for those profiles.
+	renterProfiles modules.RenterProfiles
+	profileAPIs    map[string]*API
+	profileMu      sync.Mutex
+
+	// events publishes the events of the node's modules. eventStreamsStop is
+	// closed to end the open event streams.
+	events           modules.EventManager
+	eventStreamsOnce sync.Once
+	eventStreamsStop chan struct{}
+
 	downloadMu sync.Mutex
 	downloads  map[modules.DownloadID]func()
 	router     http.Handler
@@ -148,6 +160,8 @@ func New(cfg *modules.SiadConfig, requiredUserAgent string, requiredPassword str
 		tpool:             tp,
 		wallet:            w,
 		downloads:         make(map[modules.DownloadID]func()),
+		eventStreamsStop:  make(chan struct{}),
+		profileAPIs:       make(map[string]*API),
 		requiredUserAgent: requiredUserAgent,
 		requiredPassword:  requiredPassword,
 		siadConfig:        cfg,
@@ -24,6 +24,10 @@ type Client struct {
 	// UserAgent must match the User-Agent required by the siad server. If not
 	// set, it defaults to "Sia-Agent".
 	UserAgent string
+
+	// RenterProfile selects the renter profile the requests are meant for. If
+	// not set, requests are served by the main renter of the siad server.
+	RenterProfile string
 }
 
 // New creates a new Client using the provided address.
@@ -46,6 +50,9 @@ func (c *Client) NewRequest(method, resource string, body io.Reader) (*http.Requ
 		agent = "Sia-Agent"
 	}
 	req.Header.Set("User-Agent", agent)
+	if c.RenterProfile != "" {
+		req.Header.Set(api.RenterProfileHeader, c.RenterProfile)
+	}
 	if c.Password != "" {
 		req.SetBasicAuth("", c.Password)
 	}
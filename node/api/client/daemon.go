@@ -3,7 +3,9 @@ package client
 import (
 	"net/url"
 	"strconv"
+	"strings"
 
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/node/api"
 )
 
@@ -53,3 +55,43 @@ func (c *Client) DaemonUpdatePost() (err error) {
 	err = c.post("/daemon/update", "", nil)
 	return
 }
+
+// DaemonEventsGet requests the /daemon/events resource and returns the recent
+// events that were published after the event with the given ID.
+func (c *Client) DaemonEventsGet(since uint64) (deg api.DaemonEventsGet, err error) {
+	values := url.Values{}
+	values.Set("since", strconv.FormatUint(since, 10))
+	err = c.get("/daemon/events?"+values.Encode(), &deg)
+	return
+}
+
+// DaemonWebhooksGet requests the /daemon/webhooks resource.
+func (c *Client) DaemonWebhooksGet() (dwg api.DaemonWebhooksGet, err error) {
+	err = c.get("/daemon/webhooks", &dwg)
+	return
+}
+
+// DaemonWebhooksAddPost uses the /daemon/webhooks/add endpoint to add a
+// webhook that receives the events of the given types, or all events if no
+// types are specified.
+func (c *Client) DaemonWebhooksAddPost(webhookURL string, events []modules.EventType, secret string) (wh modules.Webhook, err error) {
+	types := make([]string, 0, len(events))
+	for _, et := range events {
+		types = append(types, string(et))
+	}
+	values := url.Values{}
+	values.Set("url", webhookURL)
+	values.Set("events", strings.Join(types, ","))
+	values.Set("secret", secret)
+	err = c.post("/daemon/webhooks/add", values.Encode(), &wh)
+	return
+}
+
+// DaemonWebhooksRemovePost uses the /daemon/webhooks/remove endpoint to remove
+// a webhook.
+func (c *Client) DaemonWebhooksRemovePost(id string) (err error) {
+	values := url.Values{}
+	values.Set("id", id)
+	err = c.post("/daemon/webhooks/remove", values.Encode(), nil)
+	return
+}
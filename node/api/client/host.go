@@ -2,12 +2,16 @@ package client
 
 import (
 	"fmt"
+	"math"
 	"net/url"
 	"strconv"
+	"strings"
+	"time"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/node/api"
+	"gitlab.com/NebulousLabs/Sia/types"
 )
 
 // HostParam is a parameter in the host's settings that can be changed via the
@@ -18,6 +22,9 @@ const (
 	// HostParamCollateralBudget is the collateral budget of the host in
 	// hastings.
 	HostParamCollateralBudget = HostParam("collateralbudget")
+	// HostParamCollateralForecastWindow is the number of blocks over which
+	// the host's collateral planner projects the needed wallet funds.
+	HostParamCollateralForecastWindow = HostParam("collateralforecastwindow")
 	// HostParamMaxCollateral is the max collateral of the host in hastings.
 	HostParamMaxCollateral = HostParam("maxcollateral")
 	// HostParamMinContractPrice is the min contract price in hastings.
@@ -52,6 +59,9 @@ const (
 	HostParamMaxReviseBatchSize = HostParam("maxrevisebatchsize")
 	// HostParamNetAddress is the announced netaddress of the host.
 	HostParamNetAddress = HostParam("netaddress")
+	// HostParamAlternateNetAddresses is a comma-separated list of the
+	// addresses announced alongside the netaddress of the host.
+	HostParamAlternateNetAddresses = HostParam("alternatenetaddresses")
 	// HostParamEphemeralAccountExpiry is the maximum amount of time an
 	// ephemeral account can be inactive before it expires and gets deleted.
 	HostParamEphemeralAccountExpiry = HostParam("ephemeralaccountexpiry")
@@ -61,6 +71,72 @@ const (
 	// HostParamMaxEphemeralAccountRisk is the maximum ephemeral account risk in
 	// hastings
 	HostParamMaxEphemeralAccountRisk = HostParam("maxephemeralaccountrisk")
+	// HostParamSectorCacheSize is the size of the host's sector cache in
+	// bytes.
+	HostParamSectorCacheSize = HostParam("sectorcachesize")
+	// HostParamSectorCacheDir is the directory that backs the host's sector
+	// cache.
+	HostParamSectorCacheDir = HostParam("sectorcachedir")
+	// HostParamWebSocketAddress is the address on which the host serves the
+	// renter-host protocol over WebSocket.
+	HostParamWebSocketAddress = HostParam("websocketaddress")
+	// HostParamWebSocketTLSCertFile is the TLS certificate file of the
+	// host's WebSocket listener.
+	HostParamWebSocketTLSCertFile = HostParam("websockettlscertfile")
+	// HostParamWebSocketTLSKeyFile is the TLS key file of the host's
+	// WebSocket listener.
+	HostParamWebSocketTLSKeyFile = HostParam("websockettlskeyfile")
+	// HostParamWalletAccount is the wallet account that funds the host's
+	// collateral and receives its payouts.
+	HostParamWalletAccount = HostParam("walletaccount")
+	// HostParamClientBandwidthLimit is the bandwidth limit of every client
+	// of the host in bytes per second.
+	HostParamClientBandwidthLimit = HostParam("clientbandwidthlimit")
+	// HostParamClientReadRPCLimit is the limit of read RPCs per second of
+	// every client of the host.
+	HostParamClientReadRPCLimit = HostParam("clientreadrpclimit")
+	// HostParamClientSettingsRPCLimit is the limit of settings RPCs per
+	// second of every client of the host.
+	HostParamClientSettingsRPCLimit = HostParam("clientsettingsrpclimit")
+	// HostParamClientWriteRPCLimit is the limit of write RPCs per second of
+	// every client of the host.
+	HostParamClientWriteRPCLimit = HostParam("clientwriterpclimit")
+	// HostParamAutoPricing indicates if the host's auto-pricing engine is
+	// enabled.
+	HostParamAutoPricing = HostParam("autopricing")
+	// HostParamAutoPricingBandwidthCapacity is the bandwidth capacity of the
+	// host in bytes per second used by the auto-pricing engine.
+	HostParamAutoPricingBandwidthCapacity = HostParam("autopricingbandwidthcapacity")
+	// HostParamAutoPricingMinBaseRPCPrice is the auto-pricing floor of the
+	// base RPC price in hastings.
+	HostParamAutoPricingMinBaseRPCPrice = HostParam("autopricingminbaserpcprice")
+	// HostParamAutoPricingMaxBaseRPCPrice is the auto-pricing ceiling of the
+	// base RPC price in hastings.
+	HostParamAutoPricingMaxBaseRPCPrice = HostParam("autopricingmaxbaserpcprice")
+	// HostParamAutoPricingMinDownloadBandwidthPrice is the auto-pricing floor of the
+	// download bandwidth price in hastings/byte.
+	HostParamAutoPricingMinDownloadBandwidthPrice = HostParam("autopricingmindownloadbandwidthprice")
+	// HostParamAutoPricingMaxDownloadBandwidthPrice is the auto-pricing ceiling of the
+	// download bandwidth price in hastings/byte.
+	HostParamAutoPricingMaxDownloadBandwidthPrice = HostParam("autopricingmaxdownloadbandwidthprice")
+	// HostParamAutoPricingMinSectorAccessPrice is the auto-pricing floor of the
+	// sector access price in hastings.
+	HostParamAutoPricingMinSectorAccessPrice = HostParam("autopricingminsectoraccessprice")
+	// HostParamAutoPricingMaxSectorAccessPrice is the auto-pricing ceiling of the
+	// sector access price in hastings.
+	HostParamAutoPricingMaxSectorAccessPrice = HostParam("autopricingmaxsectoraccessprice")
+	// HostParamAutoPricingMinStoragePrice is the auto-pricing floor of the
+	// storage price in hastings/byte/block.
+	HostParamAutoPricingMinStoragePrice = HostParam("autopricingminstorageprice")
+	// HostParamAutoPricingMaxStoragePrice is the auto-pricing ceiling of the
+	// storage price in hastings/byte/block.
+	HostParamAutoPricingMaxStoragePrice = HostParam("autopricingmaxstorageprice")
+	// HostParamAutoPricingMinUploadBandwidthPrice is the auto-pricing floor of the
+	// upload bandwidth price in hastings/byte.
+	HostParamAutoPricingMinUploadBandwidthPrice = HostParam("autopricingminuploadbandwidthprice")
+	// HostParamAutoPricingMaxUploadBandwidthPrice is the auto-pricing ceiling of the
+	// upload bandwidth price in hastings/byte.
+	HostParamAutoPricingMaxUploadBandwidthPrice = HostParam("autopricingmaxuploadbandwidthprice")
 )
 
 // HostAnnouncePost uses the /host/announce endpoint to announce the host to
@@ -77,6 +153,57 @@ func (c *Client) HostAnnounceAddrPost(address modules.NetAddress) (err error) {
 	return
 }
 
+// HostAnnounceAddrsPost uses the /host/announce endpoint to announce the host
+// to the network using the provided addresses, the first of which is the
+// primary address of the host.
+func (c *Client) HostAnnounceAddrsPost(addresses []modules.NetAddress) (err error) {
+	addrs := make([]string, 0, len(addresses))
+	for _, addr := range addresses {
+		addrs = append(addrs, string(addr))
+	}
+	values := url.Values{}
+	values.Set("netaddress", strings.Join(addrs, ","))
+	err = c.post("/host/announce", values.Encode(), nil)
+	return
+}
+
+// HostMaintenanceGet uses the /host/maintenance endpoint to get the
+// maintenance status of the host.
+func (c *Client) HostMaintenanceGet() (status modules.HostMaintenanceStatus, err error) {
+	err = c.get("/host/maintenance", &status)
+	return
+}
+
+// HostMaintenanceStartPost uses the /host/maintenance/start endpoint to put
+// the host into maintenance mode for the planned duration.
+func (c *Client) HostMaintenanceStartPost(duration time.Duration) (err error) {
+	values := url.Values{}
+	values.Set("duration", fmt.Sprint(uint64(math.Round(duration.Seconds()))))
+	err = c.post("/host/maintenance/start", values.Encode(), nil)
+	return
+}
+
+// HostMaintenanceStopPost uses the /host/maintenance/stop endpoint to take the
+// host out of maintenance mode.
+func (c *Client) HostMaintenanceStopPost() (err error) {
+	err = c.post("/host/maintenance/stop", "", nil)
+	return
+}
+
+// HostClientsGet uses the /host/clients endpoint to get the resources
+// consumed by the clients of the host.
+func (c *Client) HostClientsGet() (hcg api.HostClientsGET, err error) {
+	err = c.get("/host/clients", &hcg)
+	return
+}
+
+// HostContractGet uses the /host/contracts/:id endpoint to get the details of
+// a contract on the host, including its revision history.
+func (c *Client) HostContractGet(id types.FileContractID) (sod modules.StorageObligationDetail, err error) {
+	err = c.get("/host/contracts/"+id.String(), &sod)
+	return
+}
+
 // HostContractInfoGet uses the /host/contracts endpoint to get information
 // about contracts on the host.
 func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
@@ -84,6 +211,21 @@ func (c *Client) HostContractInfoGet() (cg api.ContractInfoGET, err error) {
 	return
 }
 
+// HostContractInfoQueryGet uses the /host/contracts endpoint to get a filtered,
+// sorted and paged list of the contracts on the host.
+func (c *Client) HostContractInfoQueryGet(query modules.StorageObligationQuery) (cg api.ContractInfoGET, err error) {
+	err = c.get("/host/contracts?"+storageObligationQueryValues(query).Encode(), &cg)
+	return
+}
+
+// HostContractSummaryGet uses the /host/contractsummary endpoint to get the
+// unresolved contracts on the host that match the query, aggregated by the
+// week in which they expire.
+func (c *Client) HostContractSummaryGet(query modules.StorageObligationQuery) (csg api.ContractSummaryGET, err error) {
+	err = c.get("/host/contractsummary?"+storageObligationQueryValues(query).Encode(), &csg)
+	return
+}
+
 // HostEstimateScoreGet requests the /host/estimatescore endpoint.
 func (c *Client) HostEstimateScoreGet(param, value string) (eg api.HostEstimateScoreGET, err error) {
 	err = c.get(fmt.Sprintf("/host/estimatescore?%v=%v", param, value), &eg)
@@ -119,6 +261,27 @@ func (c *Client) HostStorageFoldersAddPost(path string, size uint64) (err error)
 	return
 }
 
+// HostStorageFoldersMigratePost uses the /host/storage/folders/migrate api
+// endpoint to move sectors from one storage folder to another. If sectors is
+// 0, all of the sectors in the source folder are moved.
+func (c *Client) HostStorageFoldersMigratePost(source, destination string, sectors uint64) (err error) {
+	values := url.Values{}
+	values.Set("source", source)
+	values.Set("destination", destination)
+	values.Set("sectors", strconv.FormatUint(sectors, 10))
+	err = c.post("/host/storage/folders/migrate", values.Encode(), nil)
+	return
+}
+
+// HostStorageFoldersRebalancePost uses the /host/storage/folders/migrate api
+// endpoint to even out the utilization of the host's storage folders.
+func (c *Client) HostStorageFoldersRebalancePost() (err error) {
+	values := url.Values{}
+	values.Set("rebalance", "true")
+	err = c.post("/host/storage/folders/migrate", values.Encode(), nil)
+	return
+}
+
 // HostStorageFoldersRemovePost uses the /host/storage/folders/remove api
 // endpoint to remove a storage folder from a host.
 func (c *Client) HostStorageFoldersRemovePost(path string, force bool) (err error) {
@@ -151,3 +314,40 @@ func (c *Client) HostStorageSectorsDeletePost(root crypto.Hash) (err error) {
 	err = c.post("/host/storage/sectors/delete/"+root.String(), "", nil)
 	return
 }
+
+// storageObligationQueryValues encodes the non-zero fields of a storage
+// obligation query as query string values.
+func storageObligationQueryValues(query modules.StorageObligationQuery) url.Values {
+	values := url.Values{}
+	if query.Status != "" {
+		values.Set("status", query.Status)
+	}
+	if query.RenterPublicKey.Key != nil {
+		values.Set("renter", query.RenterPublicKey.String())
+	}
+	if query.MinExpiration != 0 {
+		values.Set("minexpiration", fmt.Sprint(query.MinExpiration))
+	}
+	if query.MaxExpiration != 0 {
+		values.Set("maxexpiration", fmt.Sprint(query.MaxExpiration))
+	}
+	if !query.MinRevenue.IsZero() {
+		values.Set("minrevenue", query.MinRevenue.String())
+	}
+	if !query.MinCollateral.IsZero() {
+		values.Set("mincollateral", query.MinCollateral.String())
+	}
+	if query.SortBy != "" {
+		values.Set("sortby", query.SortBy)
+	}
+	if query.Descending {
+		values.Set("descending", "true")
+	}
+	if query.Offset != 0 {
+		values.Set("offset", fmt.Sprint(query.Offset))
+	}
+	if query.Limit != 0 {
+		values.Set("limit", fmt.Sprint(query.Limit))
+	}
+	return values
+}
@@ -48,6 +48,28 @@ func (c *Client) HostDbFilterModePost(fm modules.FilterMode, hosts []types.SiaPu
 	return
 }
 
+// HostDbFilterRulesPost requests the /hostdb/filtermode POST endpoint with a
+// full set of filter rules.
+func (c *Client) HostDbFilterRulesPost(fm modules.FilterMode, rules modules.HostDBFilterRules) (err error) {
+	hdblp := api.HostdbFilterModePOST{
+		FilterMode: fm.String(),
+		Hosts:      rules.Hosts,
+
+		NetAddresses:         rules.NetAddresses,
+		Subnets:              rules.Subnets,
+		ExcludedHosts:        rules.ExcludedHosts,
+		ExcludedNetAddresses: rules.ExcludedNetAddresses,
+		ExcludedSubnets:      rules.ExcludedSubnets,
+	}
+
+	data, err := json.Marshal(hdblp)
+	if err != nil {
+		return err
+	}
+	err = c.post("/hostdb/filtermode", string(data), nil)
+	return
+}
+
 // HostDbHostsGet request the /hostdb/hosts/:pubkey endpoint's resources.
 func (c *Client) HostDbHostsGet(pk types.SiaPublicKey) (hhg api.HostdbHostsGET, err error) {
 	err = c.get("/hostdb/hosts/"+pk.String(), &hhg)
@@ -131,6 +131,32 @@ func (a *AllowanceRequestPost) WithMaxUploadBandwidthPrice(price types.Currency)
 	return a
 }
 
+// WithMigrationPriceMultiple adds the migrationpricemultiple field to the
+// request.
+func (a *AllowanceRequestPost) WithMigrationPriceMultiple(multiple float64) *AllowanceRequestPost {
+	a.values.Set("migrationpricemultiple", fmt.Sprint(multiple))
+	return a
+}
+
+// WithMigrationMinSuccessRate adds the migrationminsuccessrate field to the
+// request.
+func (a *AllowanceRequestPost) WithMigrationMinSuccessRate(rate float64) *AllowanceRequestPost {
+	a.values.Set("migrationminsuccessrate", fmt.Sprint(rate))
+	return a
+}
+
+// WithWalletAccount adds the walletaccount field to the request.
+func (a *AllowanceRequestPost) WithWalletAccount(account string) *AllowanceRequestPost {
+	a.values.Set("walletaccount", account)
+	return a
+}
+
+// WithFeeTarget adds the feetarget field to the request.
+func (a *AllowanceRequestPost) WithFeeTarget(target types.BlockHeight) *AllowanceRequestPost {
+	a.values.Set("feetarget", fmt.Sprint(target))
+	return a
+}
+
 // Send finalizes and sends the request.
 func (a *AllowanceRequestPost) Send() (err error) {
 	if a.sent {
@@ -171,6 +197,58 @@ func (c *Client) RenterContractCancelPost(id types.FileContractID) (err error) {
 	return
 }
 
+// RenterContractFormPost uses the /renter/contract/form endpoint to form a
+// contract with a specific host.
+func (c *Client) RenterContractFormPost(hostKey types.SiaPublicKey, funds types.Currency) (rcp api.RenterContractPOST, err error) {
+	values := url.Values{}
+	values.Set("hostkey", hostKey.String())
+	values.Set("funds", funds.String())
+	err = c.post("/renter/contract/form", values.Encode(), &rcp)
+	return
+}
+
+// RenterContractPinPost uses the /renter/contract/pin endpoint to pin a
+// contract.
+func (c *Client) RenterContractPinPost(id types.FileContractID) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	err = c.post("/renter/contract/pin", values.Encode(), nil)
+	return
+}
+
+// RenterContractRefreshPost uses the /renter/contract/refresh endpoint to
+// refresh a contract. If funds is zero, the renter picks the funding.
+func (c *Client) RenterContractRefreshPost(id types.FileContractID, funds types.Currency) (rcp api.RenterContractPOST, err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	if !funds.IsZero() {
+		values.Set("funds", funds.String())
+	}
+	err = c.post("/renter/contract/refresh", values.Encode(), &rcp)
+	return
+}
+
+// RenterContractRenewPost uses the /renter/contract/renew endpoint to renew a
+// contract early. If funds is zero, the renter picks the funding.
+func (c *Client) RenterContractRenewPost(id types.FileContractID, funds types.Currency) (rcp api.RenterContractPOST, err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	if !funds.IsZero() {
+		values.Set("funds", funds.String())
+	}
+	err = c.post("/renter/contract/renew", values.Encode(), &rcp)
+	return
+}
+
+// RenterContractUnpinPost uses the /renter/contract/unpin endpoint to unpin a
+// contract.
+func (c *Client) RenterContractUnpinPost(id types.FileContractID) (err error) {
+	values := url.Values{}
+	values.Set("id", id.String())
+	err = c.post("/renter/contract/unpin", values.Encode(), nil)
+	return
+}
+
 // RenterAllContractsGet requests the /renter/contracts resource with all
 // options set to true
 func (c *Client) RenterAllContractsGet() (rc api.RenterContracts, err error) {
@@ -469,6 +547,41 @@ func (c *Client) RenterPricesGet(allowance modules.Allowance) (rpg api.RenterPri
 	return
 }
 
+// RenterProfilesGet uses the /renter/profiles endpoint to get the names of the
+// renter profiles.
+func (c *Client) RenterProfilesGet() (rpg api.RenterProfilesGET, err error) {
+	err = c.get("/renter/profiles", &rpg)
+	return
+}
+
+// RenterProfilesCreatePost uses the /renter/profiles/create endpoint to create
+// a new renter profile.
+func (c *Client) RenterProfilesCreatePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/profiles/create", values.Encode(), nil)
+	return
+}
+
+// RenterProfilesRemovePost uses the /renter/profiles/remove endpoint to remove
+// a renter profile.
+func (c *Client) RenterProfilesRemovePost(name string) (err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/renter/profiles/remove", values.Encode(), nil)
+	return
+}
+
+// RenterAuditPost uses the /renter endpoint to change the renter's audit
+// interval and the maximum price of a single audit.
+func (c *Client) RenterAuditPost(interval time.Duration, maxPrice types.Currency) (err error) {
+	values := url.Values{}
+	values.Set("auditinterval", fmt.Sprint(uint64(interval.Seconds())))
+	values.Set("maxauditprice", maxPrice.String())
+	err = c.post("/renter", values.Encode(), nil)
+	return
+}
+
 // RenterRateLimitPost uses the /renter endpoint to change the renter's bandwidth rate
 // limit.
 func (c *Client) RenterRateLimitPost(readBPS, writeBPS int64) (err error) {
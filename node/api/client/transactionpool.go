@@ -2,8 +2,10 @@ package client
 
 import (
 	"encoding/base64"
+	"fmt"
 	"net/url"
 
+	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
 	"gitlab.com/NebulousLabs/Sia/node/api"
 	"gitlab.com/NebulousLabs/Sia/types"
@@ -15,6 +17,17 @@ func (c *Client) TransactionPoolFeeGet() (tfg api.TpoolFeeGET, err error) {
 	return
 }
 
+// TransactionPoolFeeTargetGet uses the /tpool/fee endpoint to get an
+// estimation of the fee that gets a transaction confirmed within target blocks
+// with the provided confidence.
+func (c *Client) TransactionPoolFeeTargetGet(target types.BlockHeight, confidence float64) (tfg api.TpoolFeeGET, err error) {
+	values := url.Values{}
+	values.Set("target", fmt.Sprint(target))
+	values.Set("confidence", fmt.Sprint(confidence))
+	err = c.get("/tpool/fee?"+values.Encode(), &tfg)
+	return
+}
+
 // TransactionPoolRawPost uses the /tpool/raw endpoint to send a raw
 // transaction to the transaction pool.
 func (c *Client) TransactionPoolRawPost(txn types.Transaction, parents []types.Transaction) (err error) {
@@ -31,3 +44,25 @@ func (c *Client) TransactionPoolTransactionsGet() (tptg api.TpoolTxnsGET, err er
 	err = c.get("/tpool/transactions", &tptg)
 	return
 }
+
+// TransactionPoolSetsGet uses the /tpool/sets endpoint to get the transaction
+// sets of the tpool.
+func (c *Client) TransactionPoolSetsGet() (tsg api.TpoolSetsGET, err error) {
+	err = c.get("/tpool/sets", &tsg)
+	return
+}
+
+// TransactionPoolSetGet uses the /tpool/sets/:id endpoint to get the
+// transaction set with the provided id, or the set containing the transaction
+// with the provided id.
+func (c *Client) TransactionPoolSetGet(id crypto.Hash) (tsg api.TpoolSetGET, err error) {
+	err = c.get("/tpool/sets/"+id.String(), &tsg)
+	return
+}
+
+// TransactionPoolRejectionsGet uses the /tpool/rejections endpoint to get the
+// transaction sets that were recently rejected by the tpool.
+func (c *Client) TransactionPoolRejectionsGet() (trg api.TpoolRejectionsGET, err error) {
+	err = c.get("/tpool/rejections", &trg)
+	return
+}
@@ -5,6 +5,7 @@ import (
 	"fmt"
 	"net/url"
 	"strconv"
+	"strings"
 
 	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/modules"
@@ -13,12 +14,62 @@ import (
 	mnemonics "gitlab.com/NebulousLabs/entropy-mnemonics"
 )
 
+// WalletAccountsGet requests the /wallet/accounts endpoint and returns the
+// accounts of the wallet.
+func (c *Client) WalletAccountsGet() (wag api.WalletAccountsGET, err error) {
+	err = c.get("/wallet/accounts", &wag)
+	return
+}
+
+// WalletAccountsPost uses the /wallet/accounts endpoint to create a new
+// account with the given name.
+func (c *Client) WalletAccountsPost(name string) (wa modules.WalletAccount, err error) {
+	values := url.Values{}
+	values.Set("name", name)
+	err = c.post("/wallet/accounts", values.Encode(), &wa)
+	return
+}
+
+// WalletAccountsRenamePost uses the /wallet/accounts/rename endpoint to
+// rename an account.
+func (c *Client) WalletAccountsRenamePost(name, newName string) error {
+	values := url.Values{}
+	values.Set("name", name)
+	values.Set("newname", newName)
+	return c.post("/wallet/accounts/rename", values.Encode(), nil)
+}
+
+// WalletAccountsTransactionsGet requests the /wallet/accounts/transactions
+// endpoint and returns the transactions of an account.
+func (c *Client) WalletAccountsTransactionsGet(account string) (watg api.WalletAccountTransactionsGET, err error) {
+	values := url.Values{}
+	values.Set("account", account)
+	err = c.get("/wallet/accounts/transactions?"+values.Encode(), &watg)
+	return
+}
+
+// WalletAccountAddressGet requests a new address of an account from the
+// /wallet/address endpoint.
+func (c *Client) WalletAccountAddressGet(account string) (wag api.WalletAddressGET, err error) {
+	values := url.Values{}
+	values.Set("account", account)
+	err = c.get("/wallet/address?"+values.Encode(), &wag)
+	return
+}
+
 // WalletAddressGet requests a new address from the /wallet/address endpoint
 func (c *Client) WalletAddressGet() (wag api.WalletAddressGET, err error) {
 	err = c.get("/wallet/address", &wag)
 	return
 }
 
+// WalletTimelockedAddressGet requests a new address from the /wallet/address
+// endpoint whose outputs can't be spent before the timelock height.
+func (c *Client) WalletTimelockedAddressGet(timelock types.BlockHeight) (wag api.WalletAddressGET, err error) {
+	err = c.get(fmt.Sprintf("/wallet/address?timelock=%v", timelock), &wag)
+	return
+}
+
 // WalletAddressesGet requests the wallets known addresses from the
 // /wallet/addresses endpoint.
 func (c *Client) WalletAddressesGet() (wag api.WalletAddressesGET, err error) {
@@ -94,6 +145,75 @@ func (c *Client) WalletLockPost() (err error) {
 	return
 }
 
+// WalletMultisigAddressPost uses the /wallet/multisig/address endpoint to
+// create a multisig address that requires 'required' signatures from the
+// provided public keys.
+func (c *Client) WalletMultisigAddressPost(pks []types.SiaPublicKey, required uint64, unused bool) (wmap api.WalletMultisigAddressPOST, err error) {
+	json, err := json.Marshal(api.WalletMultisigAddressPOSTParams{
+		PublicKeys:         pks,
+		SignaturesRequired: required,
+		Unused:             unused,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/address", string(json), &wmap)
+	return
+}
+
+// WalletMultisigBroadcastPost uses the /wallet/multisig/broadcast endpoint to
+// broadcast a partially signed transaction that has enough signatures.
+func (c *Client) WalletMultisigBroadcastPost(pst modules.PartiallySignedTransaction) (wmbp api.WalletMultisigBroadcastPOSTResp, err error) {
+	json, err := json.Marshal(api.WalletMultisigPOSTParams{
+		Transaction: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/broadcast", string(json), &wmbp)
+	return
+}
+
+// WalletMultisigFundPost uses the /wallet/multisig/fund endpoint to create an
+// unsigned transaction that sends the outputs from a multisig address.
+func (c *Client) WalletMultisigFundPost(addr types.UnlockHash, outputs []types.SiacoinOutput) (wmp api.WalletMultisigPOSTResp, err error) {
+	json, err := json.Marshal(api.WalletMultisigFundPOSTParams{
+		Address: addr,
+		Outputs: outputs,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/fund", string(json), &wmp)
+	return
+}
+
+// WalletMultisigMergePost uses the /wallet/multisig/merge endpoint to combine
+// the signatures of partially signed transactions.
+func (c *Client) WalletMultisigMergePost(psts ...modules.PartiallySignedTransaction) (wmp api.WalletMultisigPOSTResp, err error) {
+	json, err := json.Marshal(api.WalletMultisigMergePOSTParams{
+		Transactions: psts,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/merge", string(json), &wmp)
+	return
+}
+
+// WalletMultisigSignPost uses the /wallet/multisig/sign endpoint to add the
+// wallet's signatures to a partially signed transaction.
+func (c *Client) WalletMultisigSignPost(pst modules.PartiallySignedTransaction) (wmp api.WalletMultisigPOSTResp, err error) {
+	json, err := json.Marshal(api.WalletMultisigPOSTParams{
+		Transaction: pst,
+	})
+	if err != nil {
+		return
+	}
+	err = c.post("/wallet/multisig/sign", string(json), &wmp)
+	return
+}
+
 // WalletSeedPost uses the /wallet/seed endpoint to add a seed to the wallet's list
 // of seeds.
 func (c *Client) WalletSeedPost(seed, password string) (err error) {
@@ -111,6 +231,44 @@ func (c *Client) WalletSeedsGet() (wsg api.WalletSeedsGET, err error) {
 	return
 }
 
+// WalletSiacoinsCoinControlPost uses the /wallet/siacoins api endpoint to send
+// money to multiple addresses using the inputs, change address and fee of the
+// coin control.
+func (c *Client) WalletSiacoinsCoinControlPost(outputs []types.SiacoinOutput, cc modules.CoinControl) (wsp api.WalletSiacoinsPOST, err error) {
+	values := url.Values{}
+	marshaledOutputs, err := json.Marshal(outputs)
+	if err != nil {
+		return api.WalletSiacoinsPOST{}, err
+	}
+	values.Set("outputs", string(marshaledOutputs))
+	if len(cc.Inputs) > 0 {
+		inputs := make([]string, 0, len(cc.Inputs))
+		for _, id := range cc.Inputs {
+			inputs = append(inputs, id.String())
+		}
+		values.Set("inputs", strings.Join(inputs, ","))
+	}
+	values.Set("spendall", strconv.FormatBool(cc.SpendAll))
+	if cc.ChangeAddress != (types.UnlockHash{}) {
+		values.Set("changeaddress", cc.ChangeAddress.String())
+	}
+	if !cc.Fee.IsZero() {
+		values.Set("fee", cc.Fee.String())
+	}
+	if !cc.FeePerByte.IsZero() {
+		values.Set("feeperbyte", cc.FeePerByte.String())
+	}
+	if cc.FeeTarget != 0 {
+		values.Set("feetarget", fmt.Sprint(cc.FeeTarget))
+	}
+	values.Set("dryrun", strconv.FormatBool(cc.DryRun))
+	if cc.Account != "" {
+		values.Set("account", cc.Account)
+	}
+	err = c.post("/wallet/siacoins", values.Encode(), &wsp)
+	return
+}
+
 // WalletSiacoinsMultiPost uses the /wallet/siacoin api endpoint to send money
 // to multiple addresses at once
 func (c *Client) WalletSiacoinsMultiPost(outputs []types.SiacoinOutput) (wsp api.WalletSiacoinsPOST, err error) {
@@ -184,6 +342,85 @@ func (c *Client) WalletTransactionsGet(startHeight types.BlockHeight, endHeight
 	return
 }
 
+// WalletTransactionsLabelGet requests the /wallet/transactions api resource
+// for a certain range of heights, filtered to the transactions related to an
+// address with the given label.
+func (c *Client) WalletTransactionsLabelGet(startHeight types.BlockHeight, endHeight types.BlockHeight, label string) (wtg api.WalletTransactionsGET, err error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("label", label)
+	err = c.get("/wallet/transactions?"+values.Encode(), &wtg)
+	return
+}
+
+// WalletTransactionsCSVGet requests the /wallet/transactions api resource for
+// a certain range of heights and returns the transactions exported as CSV. If
+// label is not empty, only the transactions related to an address with that
+// label are exported.
+func (c *Client) WalletTransactionsCSVGet(startHeight types.BlockHeight, endHeight types.BlockHeight, label string) ([]byte, error) {
+	values := url.Values{}
+	values.Set("startheight", fmt.Sprint(startHeight))
+	values.Set("endheight", fmt.Sprint(endHeight))
+	values.Set("format", "csv")
+	if label != "" {
+		values.Set("label", label)
+	}
+	_, csv, err := c.getRawResponse("/wallet/transactions?" + values.Encode())
+	return csv, err
+}
+
+// WalletTransactionNotePost uses the /wallet/transaction/:id/note endpoint to
+// attach a note to a transaction. An empty note removes the note.
+func (c *Client) WalletTransactionNotePost(id types.TransactionID, note string) (err error) {
+	values := url.Values{}
+	values.Set("note", note)
+	err = c.post("/wallet/transaction/"+id.String()+"/note", values.Encode(), nil)
+	return
+}
+
+// WalletLabelsGet requests the /wallet/labels endpoint and returns the
+// address labels of the wallet.
+func (c *Client) WalletLabelsGet() (wlg api.WalletLabelsGET, err error) {
+	err = c.get("/wallet/labels", &wlg)
+	return
+}
+
+// WalletLabelsPost uses the /wallet/labels endpoint to attach a label to an
+// address. An empty label removes the label.
+func (c *Client) WalletLabelsPost(addr types.UnlockHash, label string) (err error) {
+	values := url.Values{}
+	values.Set("address", addr.String())
+	values.Set("label", label)
+	err = c.post("/wallet/labels", values.Encode(), nil)
+	return
+}
+
+// WalletNotesGet requests the /wallet/notes endpoint and returns the
+// transaction notes of the wallet.
+func (c *Client) WalletNotesGet() (wng api.WalletNotesGET, err error) {
+	err = c.get("/wallet/notes", &wng)
+	return
+}
+
+// WalletPaymentRequestsGet requests the /wallet/paymentrequests endpoint and
+// returns the payment requests of the wallet.
+func (c *Client) WalletPaymentRequestsGet() (wprg api.WalletPaymentRequestsGET, err error) {
+	err = c.get("/wallet/paymentrequests", &wprg)
+	return
+}
+
+// WalletPaymentRequestsPost uses the /wallet/paymentrequests endpoint to
+// request a payment to a fresh address that expires after expiry blocks.
+func (c *Client) WalletPaymentRequestsPost(amount types.Currency, expiry types.BlockHeight, label string) (pr modules.PaymentRequest, err error) {
+	values := url.Values{}
+	values.Set("amount", amount.String())
+	values.Set("expiry", fmt.Sprint(expiry))
+	values.Set("label", label)
+	err = c.post("/wallet/paymentrequests", values.Encode(), &pr)
+	return
+}
+
 // WalletTransactionGet requests the /wallet/transaction/:id api resource for a
 // certain TransactionID.
 func (c *Client) WalletTransactionGet(id types.TransactionID) (wtg api.WalletTransactionGETid, err error) {
@@ -191,6 +428,19 @@ func (c *Client) WalletTransactionGet(id types.TransactionID) (wtg api.WalletTra
 	return
 }
 
+// WalletTransactionBumpPost uses the /wallet/transaction/:id/bump endpoint to
+// raise the fee of an unconfirmed transaction of the wallet. A zero feePerByte
+// uses the fee estimate of the transaction pool.
+func (c *Client) WalletTransactionBumpPost(id types.TransactionID, feePerByte types.Currency, replace bool) (wtbp api.WalletTransactionBumpPOST, err error) {
+	values := url.Values{}
+	if !feePerByte.IsZero() {
+		values.Set("feeperbyte", feePerByte.String())
+	}
+	values.Set("replace", strconv.FormatBool(replace))
+	err = c.post("/wallet/transaction/"+id.String()+"/bump", values.Encode(), &wtbp)
+	return
+}
+
 // WalletUnlockPost uses the /wallet/unlock endpoint to unlock the wallet with
 // a given encryption key. Per default this key is the seed.
 func (c *Client) WalletUnlockPost(password string) (err error) {
@@ -214,6 +464,61 @@ func (c *Client) WalletUnspentGet() (wug api.WalletUnspentGET, err error) {
 	return
 }
 
+// WalletScheduledGet requests the /wallet/scheduled endpoint and returns the
+// queue of scheduled payments.
+func (c *Client) WalletScheduledGet() (wsg api.WalletScheduledGET, err error) {
+	err = c.get("/wallet/scheduled", &wsg)
+	return
+}
+
+// WalletScheduledPost uses the /wallet/scheduled endpoint to schedule a
+// payment of amount to dest at the given height. The payment is repeated
+// every interval blocks until count payments were made, or indefinitely if
+// count is zero. An interval of zero schedules a single payment.
+func (c *Client) WalletScheduledPost(dest types.UnlockHash, amount types.Currency, height, interval types.BlockHeight, count uint64) (sp modules.ScheduledPayment, err error) {
+	values := url.Values{}
+	values.Set("destination", dest.String())
+	values.Set("amount", amount.String())
+	values.Set("height", fmt.Sprint(height))
+	values.Set("interval", fmt.Sprint(interval))
+	values.Set("count", strconv.FormatUint(count, 10))
+	err = c.post("/wallet/scheduled", values.Encode(), &sp)
+	return
+}
+
+// WalletScheduledCancelPost uses the /wallet/scheduled/cancel endpoint to
+// remove a payment from the queue of scheduled payments.
+func (c *Client) WalletScheduledCancelPost(id string) error {
+	values := url.Values{}
+	values.Set("id", id)
+	return c.post("/wallet/scheduled/cancel", values.Encode(), nil)
+}
+
+// WalletSignerGet requests the /wallet/signer endpoint and returns the
+// external signer of the wallet.
+func (c *Client) WalletSignerGet() (ws modules.WalletSigner, err error) {
+	err = c.get("/wallet/signer", &ws)
+	return
+}
+
+// WalletSignerPost uses the /wallet/signer endpoint to configure the external
+// signer of the wallet. An empty address removes the signer.
+func (c *Client) WalletSignerPost(address string) error {
+	values := url.Values{}
+	values.Set("address", address)
+	return c.post("/wallet/signer", values.Encode(), nil)
+}
+
+// WalletSignerAddressesPost uses the /wallet/signer/addresses endpoint to
+// fetch the next n addresses from the signer and watch them.
+func (c *Client) WalletSignerAddressesPost(n uint64, unused bool) (wsap api.WalletSignerAddressesPOST, err error) {
+	values := url.Values{}
+	values.Set("n", strconv.FormatUint(n, 10))
+	values.Set("unused", strconv.FormatBool(unused))
+	err = c.post("/wallet/signer/addresses", values.Encode(), &wsap)
+	return
+}
+
 // WalletWatchGet requests the /wallet/watch endpoint and returns the set of
 // currently watched addresses.
 func (c *Client) WalletWatchGet() (wwg api.WalletWatchGET, err error) {
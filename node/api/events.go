@@ -0,0 +1,209 @@
+package api
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/julienschmidt/httprouter"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+const (
+	// eventStreamContentType is the content type of a Server-Sent Events
+	// stream. Requests to /daemon/events that accept it receive a stream of
+	// events instead of the recent events.
+	eventStreamContentType = "text/event-stream"
+
+	// eventStreamKeepAlive is the interval at which a comment is sent to
+	// otherwise idle event streams to keep the connection open.
+	eventStreamKeepAlive = 30 * time.Second
+)
+
+type (
+	// DaemonEventsGet contains the recent events of the node.
+	DaemonEventsGet struct {
+		Events []modules.Event `json:"events"`
+	}
+
+	// DaemonWebhooksGet contains the webhooks of the node.
+	DaemonWebhooksGet struct {
+		Webhooks []modules.Webhook `json:"webhooks"`
+	}
+)
+
+// SetEventManager allows for setting the event manager of the API at runtime.
+func (api *API) SetEventManager(em modules.EventManager) {
+	api.events = em
+	api.buildHTTPRoutes()
+}
+
+// StopEventStreams ends all open event streams. It is called when the server
+// shuts down since the streams would otherwise keep their connections open.
+func (api *API) StopEventStreams() {
+	api.eventStreamsOnce.Do(func() {
+		close(api.eventStreamsStop)
+	})
+}
+
+// parseEventTypes parses a comma separated list of event types.
+func parseEventTypes(s string) ([]modules.EventType, error) {
+	var types []modules.EventType
+	for _, t := range strings.Split(s, ",") {
+		if t == "" {
+			continue
+		}
+		et := modules.EventType(t)
+		if !et.IsValid() {
+			return nil, fmt.Errorf("unknown event type %q", t)
+		}
+		types = append(types, et)
+	}
+	return types, nil
+}
+
+// daemonEventsHandlerGET handles the API call that returns the recent events
+// of the node. If the request accepts Server-Sent Events, the events are
+// streamed instead.
+func (api *API) daemonEventsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	// The ID of the last event the caller received. Reconnecting SSE clients
+	// send it in the Last-Event-ID header.
+	var since uint64
+	sinceStr := req.FormValue("since")
+	if sinceStr == "" {
+		sinceStr = req.Header.Get("Last-Event-ID")
+	}
+	if sinceStr != "" {
+		var err error
+		since, err = strconv.ParseUint(sinceStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"unable to parse since: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	types, err := parseEventTypes(req.FormValue("events"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	wh := modules.Webhook{Events: types}
+
+	if !strings.Contains(req.Header.Get("Accept"), eventStreamContentType) {
+		events := []modules.Event{}
+		for _, e := range api.events.Events(since) {
+			if wh.Matches(e.Type) {
+				events = append(events, e)
+			}
+		}
+		WriteJSON(w, DaemonEventsGet{
+			Events: events,
+		})
+		return
+	}
+
+	flusher, ok := w.(http.Flusher)
+	if !ok {
+		WriteError(w, Error{"streaming is not supported"}, http.StatusInternalServerError)
+		return
+	}
+	// Subscribe before fetching the recent events to not miss any events in
+	// between. Events that were already sent are skipped.
+	c, cancel := api.events.Subscribe()
+	defer cancel()
+	w.Header().Set("Content-Type", eventStreamContentType)
+	w.Header().Set("Cache-Control", "no-cache")
+	w.WriteHeader(http.StatusOK)
+	send := func(e modules.Event) error {
+		if e.ID <= since {
+			return nil
+		}
+		since = e.ID
+		if !wh.Matches(e.Type) {
+			return nil
+		}
+		data, err := json.Marshal(e)
+		if err != nil {
+			return err
+		}
+		_, err = fmt.Fprintf(w, "id: %d\nevent: %s\ndata: %s\n\n", e.ID, e.Type, data)
+		return err
+	}
+	if since > 0 {
+		for _, e := range api.events.Events(since) {
+			if err := send(e); err != nil {
+				return
+			}
+		}
+	}
+	flusher.Flush()
+
+	keepAlive := time.NewTicker(eventStreamKeepAlive)
+	defer keepAlive.Stop()
+	for {
+		select {
+		case e, ok := <-c:
+			if !ok {
+				// The subscription ended, the client has to reconnect.
+				return
+			}
+			if err := send(e); err != nil {
+				return
+			}
+		case <-keepAlive.C:
+			if _, err := fmt.Fprint(w, ": keepalive\n\n"); err != nil {
+				return
+			}
+		case <-req.Context().Done():
+			return
+		case <-api.eventStreamsStop:
+			return
+		}
+		flusher.Flush()
+	}
+}
+
+// daemonWebhooksHandlerGET handles the API call that returns the webhooks of
+// the node.
+func (api *API) daemonWebhooksHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, DaemonWebhooksGet{
+		Webhooks: api.events.Webhooks(),
+	})
+}
+
+// daemonWebhooksAddHandlerPOST handles the API call to add a webhook.
+func (api *API) daemonWebhooksAddHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	url := req.FormValue("url")
+	if url == "" {
+		WriteError(w, Error{"url of the webhook needs to be specified"}, http.StatusBadRequest)
+		return
+	}
+	types, err := parseEventTypes(req.FormValue("events"))
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	wh, err := api.events.AddWebhook(url, types, req.FormValue("secret"))
+	if err != nil {
+		WriteError(w, Error{"unable to add webhook: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, wh)
+}
+
+// daemonWebhooksRemoveHandlerPOST handles the API call to remove a webhook.
+func (api *API) daemonWebhooksRemoveHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	id := req.FormValue("id")
+	if id == "" {
+		WriteError(w, Error{"id of the webhook needs to be specified"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.events.RemoveWebhook(id); err != nil {
+		WriteError(w, Error{"unable to remove webhook: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
@@ -4,6 +4,9 @@ import (
 	"errors"
 	"fmt"
 	"net/http"
+	"strconv"
+	"strings"
+	"time"
 
 	"github.com/julienschmidt/httprouter"
 
@@ -28,11 +31,27 @@ type (
 	// to /host/contracts - information for the host about stored obligations.
 	ContractInfoGET struct {
 		Contracts []modules.StorageObligation `json:"contracts"`
+		Total     uint64                      `json:"total"`
+	}
+
+	// ContractSummaryGET contains the information that is returned after a
+	// GET request to /host/contractsummary - the unresolved storage
+	// obligations of the host aggregated by the week in which they expire.
+	ContractSummaryGET struct {
+		Periods []modules.StorageObligationPeriod `json:"periods"`
+	}
+
+	// HostClientsGET contains the information that is returned after a GET
+	// request to /host/clients - the resources consumed by the clients of the
+	// host.
+	HostClientsGET struct {
+		Clients []modules.HostClient `json:"clients"`
 	}
 
 	// HostGET contains the information that is returned after a GET request to
 	// /host - a bunch of information about the status of the host.
 	HostGET struct {
+		CollateralForecast   modules.HostCollateralForecast   `json:"collateralforecast"`
 		ExternalSettings     modules.HostExternalSettings     `json:"externalsettings"`
 		FinancialMetrics     modules.HostFinancialMetrics     `json:"financialmetrics"`
 		InternalSettings     modules.HostInternalSettings     `json:"internalsettings"`
@@ -54,6 +73,8 @@ type (
 	// management on the host.
 	StorageGET struct {
 		Folders []modules.StorageFolderMetadata `json:"folders"`
+		Scrub   modules.StorageScrubStatus      `json:"scrub"`
+		Cache   modules.SectorCacheStatus       `json:"cache"`
 	}
 )
 
@@ -68,15 +89,105 @@ func folderIndex(folderPath string, storageFolders []modules.StorageFolderMetada
 	return -1, errStorageFolderNotFound
 }
 
+// hostClientsHandlerGET handles GET requests to the /host/clients API
+// endpoint, returning the resources consumed by the clients of the host.
+func (api *API) hostClientsHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	WriteJSON(w, HostClientsGET{
+		Clients: api.host.Clients(),
+	})
+}
+
+// parseStorageObligationQuery parses a request's query strings into a
+// modules.StorageObligationQuery.
+func parseStorageObligationQuery(req *http.Request) (modules.StorageObligationQuery, error) {
+	query := modules.StorageObligationQuery{
+		Status: req.FormValue("status"),
+		SortBy: req.FormValue("sortby"),
+	}
+	if renter := req.FormValue("renter"); renter != "" {
+		query.RenterPublicKey.LoadString(renter)
+		if query.RenterPublicKey.Key == nil {
+			return modules.StorageObligationQuery{}, errors.New("unable to parse renter public key")
+		}
+	}
+	for _, p := range []struct {
+		name string
+		dst  interface{}
+	}{
+		{"minexpiration", &query.MinExpiration},
+		{"maxexpiration", &query.MaxExpiration},
+		{"minrevenue", &query.MinRevenue},
+		{"mincollateral", &query.MinCollateral},
+		{"descending", &query.Descending},
+		{"offset", &query.Offset},
+		{"limit", &query.Limit},
+	} {
+		if v := req.FormValue(p.name); v != "" {
+			_, err := fmt.Sscan(v, p.dst)
+			if err != nil {
+				return modules.StorageObligationQuery{}, fmt.Errorf("unable to parse %v: %v", p.name, err)
+			}
+		}
+	}
+	return query, nil
+}
+
 // hostContractInfoHandler handles the API call to get the contract information of the host.
 // Information is retrieved via the storage obligations from the host database.
+// The obligations can be filtered, sorted and paged with query strings.
 func (api *API) hostContractInfoHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	query, err := parseStorageObligationQuery(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sos, total, err := api.host.QueryStorageObligations(query)
+	if err != nil {
+		WriteError(w, Error{"failed to query storage obligations: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
 	cg := ContractInfoGET{
-		Contracts: api.host.StorageObligations(),
+		Contracts: sos,
+		Total:     total,
 	}
 	WriteJSON(w, cg)
 }
 
+// hostContractHandler handles the API call to get the details of a single
+// storage obligation of the host, including its revision history.
+func (api *API) hostContractHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(ps.ByName("id")); err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sod, err := api.host.StorageObligation(fcid)
+	if err != nil {
+		WriteError(w, Error{"failed to get storage obligation: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, sod)
+}
+
+// hostContractSummaryHandler handles the API call to get the unresolved
+// storage obligations of the host aggregated by the week in which they
+// expire.
+func (api *API) hostContractSummaryHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	query, err := parseStorageObligationQuery(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	periods, err := api.host.StorageObligationsSummary(query)
+	if err != nil {
+		WriteError(w, Error{"failed to summarize storage obligations: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, ContractSummaryGET{
+		Periods: periods,
+	})
+}
+
 // hostHandlerGET handles GET requests to the /host API endpoint, returning key
 // information about the host.
 func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
@@ -87,7 +198,9 @@ func (api *API) hostHandlerGET(w http.ResponseWriter, req *http.Request, _ httpr
 	cs := api.host.ConnectabilityStatus()
 	ws := api.host.WorkingStatus()
 	pk := api.host.PublicKey()
+	cf := api.host.CollateralForecast()
 	hg := HostGET{
+		CollateralForecast:   cf,
 		ExternalSettings:     es,
 		FinancialMetrics:     fm,
 		InternalSettings:     is,
@@ -160,6 +273,10 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.NetAddress = x
 	}
+	if req.Form["alternatenetaddresses"] != nil {
+		// An empty value clears the alternate addresses.
+		settings.AlternateNetAddresses = parseNetAddresses(req.FormValue("alternatenetaddresses"))
+	}
 	if req.FormValue("windowsize") != "" {
 		var x types.BlockHeight
 		_, err := fmt.Sscan(req.FormValue("windowsize"), &x)
@@ -185,6 +302,14 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.CollateralBudget = x
 	}
+	if req.FormValue("collateralforecastwindow") != "" {
+		var x types.BlockHeight
+		_, err := fmt.Sscan(req.FormValue("collateralforecastwindow"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.CollateralForecastWindow = x
+	}
 	if req.FormValue("maxcollateral") != "" {
 		var x types.Currency
 		_, err := fmt.Sscan(req.FormValue("maxcollateral"), &x)
@@ -266,6 +391,157 @@ func (api *API) parseHostSettings(req *http.Request) (modules.HostInternalSettin
 		}
 		settings.MaxEphemeralAccountRisk = x
 	}
+	if req.FormValue("sectorcachesize") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("sectorcachesize"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.SectorCacheSize = x
+	}
+	if req.Form["sectorcachedir"] != nil {
+		settings.SectorCacheDir = req.FormValue("sectorcachedir")
+	}
+	if req.Form["websocketaddress"] != nil {
+		settings.WebSocketAddress = req.FormValue("websocketaddress")
+	}
+	if req.Form["websockettlscertfile"] != nil {
+		settings.WebSocketTLSCertFile = req.FormValue("websockettlscertfile")
+	}
+	if req.Form["websockettlskeyfile"] != nil {
+		settings.WebSocketTLSKeyFile = req.FormValue("websockettlskeyfile")
+	}
+	if req.Form["walletaccount"] != nil {
+		settings.WalletAccount = req.FormValue("walletaccount")
+	}
+	if req.FormValue("clientbandwidthlimit") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("clientbandwidthlimit"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ClientBandwidthLimit = x
+	}
+	if req.FormValue("clientreadrpclimit") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("clientreadrpclimit"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ClientReadRPCLimit = x
+	}
+	if req.FormValue("clientsettingsrpclimit") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("clientsettingsrpclimit"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ClientSettingsRPCLimit = x
+	}
+	if req.FormValue("clientwriterpclimit") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("clientwriterpclimit"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.ClientWriteRPCLimit = x
+	}
+	if req.FormValue("autopricing") != "" {
+		var x bool
+		_, err := fmt.Sscan(req.FormValue("autopricing"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.Enabled = x
+	}
+	if req.FormValue("autopricingbandwidthcapacity") != "" {
+		var x uint64
+		_, err := fmt.Sscan(req.FormValue("autopricingbandwidthcapacity"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.BandwidthCapacity = x
+	}
+	if req.FormValue("autopricingminbaserpcprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingminbaserpcprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MinBaseRPCPrice = x
+	}
+	if req.FormValue("autopricingmaxbaserpcprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingmaxbaserpcprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MaxBaseRPCPrice = x
+	}
+	if req.FormValue("autopricingmindownloadbandwidthprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingmindownloadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MinDownloadBandwidthPrice = x
+	}
+	if req.FormValue("autopricingmaxdownloadbandwidthprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingmaxdownloadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MaxDownloadBandwidthPrice = x
+	}
+	if req.FormValue("autopricingminsectoraccessprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingminsectoraccessprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MinSectorAccessPrice = x
+	}
+	if req.FormValue("autopricingmaxsectoraccessprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingmaxsectoraccessprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MaxSectorAccessPrice = x
+	}
+	if req.FormValue("autopricingminstorageprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingminstorageprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MinStoragePrice = x
+	}
+	if req.FormValue("autopricingmaxstorageprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingmaxstorageprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MaxStoragePrice = x
+	}
+	if req.FormValue("autopricingminuploadbandwidthprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingminuploadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MinUploadBandwidthPrice = x
+	}
+	if req.FormValue("autopricingmaxuploadbandwidthprice") != "" {
+		var x types.Currency
+		_, err := fmt.Sscan(req.FormValue("autopricingmaxuploadbandwidthprice"), &x)
+		if err != nil {
+			return modules.HostInternalSettings{}, err
+		}
+		settings.AutoPricing.MaxUploadBandwidthPrice = x
+	}
 
 	return settings, nil
 }
@@ -343,12 +619,23 @@ func (api *API) hostHandlerPOST(w http.ResponseWriter, req *http.Request, _ http
 	WriteSuccess(w)
 }
 
+// parseNetAddresses parses a comma-separated list of net addresses.
+func parseNetAddresses(s string) []modules.NetAddress {
+	var addrs []modules.NetAddress
+	for _, addr := range strings.Split(s, ",") {
+		if addr = strings.TrimSpace(addr); addr != "" {
+			addrs = append(addrs, modules.NetAddress(addr))
+		}
+	}
+	return addrs
+}
+
 // hostAnnounceHandler handles the API call to get the host to announce itself
 // to the network.
 func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	var err error
-	if addr := req.FormValue("netaddress"); addr != "" {
-		err = api.host.AnnounceAddress(modules.NetAddress(addr))
+	if addrs := parseNetAddresses(req.FormValue("netaddress")); len(addrs) > 0 {
+		err = api.host.AnnounceAddresses(addrs)
 	} else {
 		err = api.host.Announce()
 	}
@@ -359,11 +646,56 @@ func (api *API) hostAnnounceHandler(w http.ResponseWriter, req *http.Request, _
 	WriteSuccess(w)
 }
 
+// hostMaintenanceHandlerGET handles GET requests to the /host/maintenance API
+// endpoint, returning the maintenance status of the host.
+func (api *API) hostMaintenanceHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	status, err := api.host.MaintenanceStatus()
+	if err != nil {
+		WriteError(w, Error{"failed to get maintenance status: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, status)
+}
+
+// hostMaintenanceStartHandler handles the API call to put the host into
+// maintenance mode for the planned duration in seconds.
+func (api *API) hostMaintenanceStartHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	durationStr := req.FormValue("duration")
+	if durationStr == "" {
+		WriteError(w, Error{"duration must be specified"}, http.StatusBadRequest)
+		return
+	}
+	durationInt, err := strconv.ParseUint(durationStr, 10, 64)
+	if err != nil {
+		WriteError(w, Error{"failed to parse duration: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	err = api.host.StartMaintenance(time.Second * time.Duration(durationInt))
+	if err != nil {
+		WriteError(w, Error{"failed to start maintenance: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// hostMaintenanceStopHandler handles the API call to take the host out of
+// maintenance mode.
+func (api *API) hostMaintenanceStopHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	err := api.host.StopMaintenance()
+	if err != nil {
+		WriteError(w, Error{"failed to stop maintenance: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageHandler returns a bunch of information about storage management on
 // the host.
 func (api *API) storageHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	WriteJSON(w, StorageGET{
 		Folders: api.host.StorageFolders(),
+		Scrub:   api.host.ScrubStatus(),
+		Cache:   api.host.SectorCacheStatus(),
 	})
 }
 
@@ -384,6 +716,53 @@ func (api *API) storageFoldersAddHandler(w http.ResponseWriter, req *http.Reques
 	WriteSuccess(w)
 }
 
+// storageFoldersMigrateHandler starts a migration of sectors between storage
+// folders in the storage manager.
+func (api *API) storageFoldersMigrateHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if req.FormValue("rebalance") == "true" {
+		err := api.host.RebalanceStorageFolders()
+		if err != nil {
+			WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+			return
+		}
+		WriteSuccess(w)
+		return
+	}
+
+	sourcePath := req.FormValue("source")
+	destinationPath := req.FormValue("destination")
+	if sourcePath == "" || destinationPath == "" {
+		WriteError(w, Error{"source and destination parameters are required"}, http.StatusBadRequest)
+		return
+	}
+	storageFolders := api.host.StorageFolders()
+	sourceIndex, err := folderIndex(sourcePath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{"source: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	destinationIndex, err := folderIndex(destinationPath, storageFolders)
+	if err != nil {
+		WriteError(w, Error{"destination: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	var sectors uint64
+	if s := req.FormValue("sectors"); s != "" {
+		_, err = fmt.Sscan(s, &sectors)
+		if err != nil {
+			WriteError(w, Error{"unable to parse sectors: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	err = api.host.MigrateSectors(uint16(sourceIndex), uint16(destinationIndex), sectors)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
 // storageFoldersResizeHandler resizes a storage folder in the storage manager.
 func (api *API) storageFoldersResizeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	folderPath := req.FormValue("path")
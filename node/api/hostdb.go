@@ -47,6 +47,12 @@ type (
 	HostdbFilterModeGET struct {
 		FilterMode string   `json:"filtermode"`
 		Hosts      []string `json:"hosts"`
+
+		NetAddresses         []string `json:"netaddresses"`
+		Subnets              []string `json:"subnets"`
+		ExcludedHosts        []string `json:"excludedhosts"`
+		ExcludedNetAddresses []string `json:"excludednetaddresses"`
+		ExcludedSubnets      []string `json:"excludedsubnets"`
 	}
 
 	// HostdbFilterModePOST contains the information needed to set the the
@@ -54,6 +60,12 @@ type (
 	HostdbFilterModePOST struct {
 		FilterMode string               `json:"filtermode"`
 		Hosts      []types.SiaPublicKey `json:"hosts"`
+
+		NetAddresses         []string             `json:"netaddresses"`
+		Subnets              []string             `json:"subnets"`
+		ExcludedHosts        []types.SiaPublicKey `json:"excludedhosts"`
+		ExcludedNetAddresses []string             `json:"excludednetaddresses"`
+		ExcludedSubnets      []string             `json:"excludedsubnets"`
 	}
 )
 
@@ -168,19 +180,28 @@ func (api *API) hostdbHostsHandler(w http.ResponseWriter, req *http.Request, ps
 // mode
 func (api *API) hostdbFilterModeHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
 	// Get FilterMode
-	fm, hostMap, err := api.renter.Filter()
+	fm, rules, err := api.renter.FilterRules()
 	if err != nil {
 		WriteError(w, Error{"unable to get filter mode: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
-	// Build Slice of PubKeys
-	var hosts []string
-	for key := range hostMap {
-		hosts = append(hosts, key)
+	// Build Slices of PubKeys
+	var hosts, excludedHosts []string
+	for _, pk := range rules.Hosts {
+		hosts = append(hosts, pk.String())
+	}
+	for _, pk := range rules.ExcludedHosts {
+		excludedHosts = append(excludedHosts, pk.String())
 	}
 	WriteJSON(w, HostdbFilterModeGET{
 		FilterMode: fm.String(),
 		Hosts:      hosts,
+
+		NetAddresses:         rules.NetAddresses,
+		Subnets:              rules.Subnets,
+		ExcludedHosts:        excludedHosts,
+		ExcludedNetAddresses: rules.ExcludedNetAddresses,
+		ExcludedSubnets:      rules.ExcludedSubnets,
 	})
 }
 
@@ -202,7 +223,15 @@ func (api *API) hostdbFilterModeHandlerPOST(w http.ResponseWriter, req *http.Req
 	}
 
 	// Set list mode
-	if err := api.renter.SetFilterMode(fm, params.Hosts); err != nil {
+	rules := modules.HostDBFilterRules{
+		Hosts:                params.Hosts,
+		NetAddresses:         params.NetAddresses,
+		Subnets:              params.Subnets,
+		ExcludedHosts:        params.ExcludedHosts,
+		ExcludedNetAddresses: params.ExcludedNetAddresses,
+		ExcludedSubnets:      params.ExcludedSubnets,
+	}
+	if err := api.renter.SetFilterRules(fm, rules); err != nil {
 		WriteError(w, Error{"failed to set the list mode: " + err.Error()}, http.StatusBadRequest)
 		return
 	}
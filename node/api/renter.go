@@ -129,6 +129,24 @@ type (
 		GoodForRenew bool `json:"goodforrenew"`
 		// Signals if a contract has been marked as bad
 		BadContract bool `json:"badcontract"`
+		// Signals if a contract has been pinned by the user
+		Pinned bool `json:"pinned"`
+		// Signals if a contract has been marked for migration. The data of
+		// the contract is moved to other hosts once the migration is active.
+		Migrate         bool   `json:"migrate"`
+		MigrationActive bool   `json:"migrationactive"`
+		MigrationReason string `json:"migrationreason"`
+		// Results of the audits of the contract's sectors.
+		LastAudit        time.Time `json:"lastaudit"`
+		SuccessfulAudits uint64    `json:"successfulaudits"`
+		FailedAudits     uint64    `json:"failedaudits"`
+		LostSectors      uint64    `json:"lostsectors"`
+	}
+
+	// RenterContractPOST contains the ID of a contract that was formed,
+	// renewed or refreshed through the API.
+	RenterContractPOST struct {
+		ID types.FileContractID `json:"id"`
 	}
 
 	// RenterContracts contains the renter's contracts.
@@ -694,6 +712,39 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		settings.Allowance.MaxPeriodChurn = maxPeriodChurn
 		maxPeriodChurnSet = true
 	}
+	if str := req.FormValue("migrationpricemultiple"); str != "" {
+		var multiple float64
+		if _, err := fmt.Sscan(str, &multiple); err != nil {
+			WriteError(w, Error{"unable to parse migrationpricemultiple: " + err.Error()}, http.StatusBadRequest)
+			return
+		} else if multiple != 0 && multiple <= 1 {
+			WriteError(w, Error{"migrationpricemultiple must be greater than 1"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MigrationPriceMultiple = multiple
+	}
+	if str := req.FormValue("migrationminsuccessrate"); str != "" {
+		var rate float64
+		if _, err := fmt.Sscan(str, &rate); err != nil {
+			WriteError(w, Error{"unable to parse migrationminsuccessrate: " + err.Error()}, http.StatusBadRequest)
+			return
+		} else if rate < 0 || rate > 1 {
+			WriteError(w, Error{"migrationminsuccessrate must be between 0 and 1"}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.MigrationMinSuccessRate = rate
+	}
+	if req.Form["walletaccount"] != nil {
+		settings.Allowance.WalletAccount = req.FormValue("walletaccount")
+	}
+	if str := req.FormValue("feetarget"); str != "" {
+		var target types.BlockHeight
+		if _, err := fmt.Sscan(str, &target); err != nil {
+			WriteError(w, Error{"unable to parse feetarget: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.Allowance.FeeTarget = target
+	}
 	if str := req.FormValue("maxrpcprice"); str != "" {
 		price, ok := scanAmount(str)
 		if !ok {
@@ -858,6 +909,25 @@ func (api *API) renterHandlerPOST(w http.ResponseWriter, req *http.Request, _ ht
 		settings.MaxUploadSpeed = uploadSpeed
 	}
 
+	// Scan the audit interval in seconds. (optional parameter)
+	if ai := req.FormValue("auditinterval"); ai != "" {
+		var interval uint64
+		if _, err := fmt.Sscan(ai, &interval); err != nil {
+			WriteError(w, Error{"unable to parse auditinterval: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		settings.AuditInterval = time.Duration(interval) * time.Second
+	}
+	// Scan the maximum price of a single audit. (optional parameter)
+	if mp := req.FormValue("maxauditprice"); mp != "" {
+		price, ok := scanAmount(mp)
+		if !ok {
+			WriteError(w, Error{"unable to parse maxauditprice"}, http.StatusBadRequest)
+			return
+		}
+		settings.MaxAuditPrice = price
+	}
+
 	// Scan the checkforipviolation flag.
 	if ipc := req.FormValue("checkforipviolation"); ipc != "" {
 		var ipviolationcheck bool
@@ -914,6 +984,106 @@ func (api *API) renterContractCancelHandler(w http.ResponseWriter, req *http.Req
 	WriteSuccess(w)
 }
 
+// renterContractFormHandlerPOST handles the API call to form a contract with a
+// specific host.
+func (api *API) renterContractFormHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var hostKey types.SiaPublicKey
+	hostKey.LoadString(req.FormValue("hostkey"))
+	if hostKey.Key == nil {
+		WriteError(w, Error{"invalid host public key"}, http.StatusBadRequest)
+		return
+	}
+	funds, ok := scanAmount(req.FormValue("funds"))
+	if !ok {
+		WriteError(w, Error{"unable to parse funds"}, http.StatusBadRequest)
+		return
+	}
+	contract, err := api.renter.FormContract(hostKey, funds)
+	if err != nil {
+		WriteError(w, Error{"unable to form contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterContractPOST{ID: contract.ID})
+}
+
+// renterContractPinHandlerPOST handles the API call to pin a contract.
+func (api *API) renterContractPinHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(req.FormValue("id")); err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.PinContract(fcid); err != nil {
+		WriteError(w, Error{"unable to pin contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterContractRefreshHandlerPOST handles the API call to refresh a contract
+// that is running out of funds.
+func (api *API) renterContractRefreshHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	fcid, funds, err := parseContractRenewParams(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	contract, err := api.renter.RefreshContract(fcid, funds)
+	if err != nil {
+		WriteError(w, Error{"unable to refresh contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterContractPOST{ID: contract.ID})
+}
+
+// renterContractRenewHandlerPOST handles the API call to renew a contract
+// early.
+func (api *API) renterContractRenewHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	fcid, funds, err := parseContractRenewParams(req)
+	if err != nil {
+		WriteError(w, Error{err.Error()}, http.StatusBadRequest)
+		return
+	}
+	contract, err := api.renter.RenewContract(fcid, funds)
+	if err != nil {
+		WriteError(w, Error{"unable to renew contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, RenterContractPOST{ID: contract.ID})
+}
+
+// renterContractUnpinHandlerPOST handles the API call to unpin a contract.
+func (api *API) renterContractUnpinHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(req.FormValue("id")); err != nil {
+		WriteError(w, Error{"unable to parse id: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renter.UnpinContract(fcid); err != nil {
+		WriteError(w, Error{"unable to unpin contract: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// parseContractRenewParams parses the contract id and the optional funds of a
+// renew or refresh request.
+func parseContractRenewParams(req *http.Request) (types.FileContractID, types.Currency, error) {
+	var fcid types.FileContractID
+	if err := fcid.LoadString(req.FormValue("id")); err != nil {
+		return types.FileContractID{}, types.Currency{}, errors.AddContext(err, "unable to parse id")
+	}
+	var funds types.Currency
+	if f := req.FormValue("funds"); f != "" {
+		var ok bool
+		funds, ok = scanAmount(f)
+		if !ok {
+			return types.FileContractID{}, types.Currency{}, errors.New("unable to parse funds")
+		}
+	}
+	return fcid, funds, nil
+}
+
 // renterContractsHandler handles the API call to request the Renter's
 // contracts. Active and renewed contracts are returned by default
 //
@@ -1010,23 +1180,33 @@ func (api *API) parseRenterContracts(disabled, inactive, expired bool) RenterCon
 		}
 
 		// Build the contract.
+		migration, migrate := api.renter.MigratingContract(c.ID)
+		audit, _ := api.renter.ContractAuditStatus(c.ID)
 		contract := RenterContract{
 			BadContract:               c.Utility.BadContract,
 			DownloadSpending:          c.DownloadSpending,
 			EndHeight:                 c.EndHeight,
+			FailedAudits:              audit.FailedAudits,
 			Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
 			GoodForUpload:             c.Utility.GoodForUpload,
 			GoodForRenew:              c.Utility.GoodForRenew,
 			HostPublicKey:             c.HostPublicKey,
 			HostVersion:               hdbe.Version,
 			ID:                        c.ID,
+			LastAudit:                 audit.LastAudit,
 			LastTransaction:           c.Transaction,
+			LostSectors:               audit.LostSectors,
 			NetAddress:                netAddress,
+			Pinned:                    api.renter.PinnedContract(c.ID),
+			Migrate:                   migrate,
+			MigrationActive:           migration.Active,
+			MigrationReason:           migration.Reason,
 			RenterFunds:               c.RenterFunds,
 			Size:                      size,
 			StartHeight:               c.StartHeight,
 			StorageSpending:           c.StorageSpending,
 			StorageSpendingDeprecated: c.StorageSpending,
+			SuccessfulAudits:          audit.SuccessfulAudits,
 			TotalCost:                 c.TotalCost,
 			UploadSpending:            c.UploadSpending,
 		}
@@ -1074,23 +1254,28 @@ func (api *API) parseRenterContracts(disabled, inactive, expired bool) RenterCon
 		}
 
 		// Build contract
+		audit, _ := api.renter.ContractAuditStatus(c.ID)
 		contract := RenterContract{
 			BadContract:               c.Utility.BadContract,
 			DownloadSpending:          c.DownloadSpending,
 			EndHeight:                 c.EndHeight,
+			FailedAudits:              audit.FailedAudits,
 			Fees:                      c.TxnFee.Add(c.SiafundFee).Add(c.ContractFee),
 			GoodForUpload:             c.Utility.GoodForUpload,
 			GoodForRenew:              c.Utility.GoodForRenew,
 			HostPublicKey:             c.HostPublicKey,
 			HostVersion:               hdbe.Version,
 			ID:                        c.ID,
+			LastAudit:                 audit.LastAudit,
 			LastTransaction:           c.Transaction,
+			LostSectors:               audit.LostSectors,
 			NetAddress:                netAddress,
 			RenterFunds:               c.RenterFunds,
 			Size:                      size,
 			StartHeight:               c.StartHeight,
 			StorageSpending:           c.StorageSpending,
 			StorageSpendingDeprecated: c.StorageSpending,
+			SuccessfulAudits:          audit.SuccessfulAudits,
 			TotalCost:                 c.TotalCost,
 			UploadSpending:            c.UploadSpending,
 		}
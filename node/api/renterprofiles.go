@@ -0,0 +1,159 @@
+package api
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/julienschmidt/httprouter"
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+)
+
+const (
+	// RenterProfileHeader is the header that selects the renter profile a
+	// request is meant for. Requests without the header are served by the
+	// node's main renter.
+	RenterProfileHeader = "Sia-Renter-Profile"
+
+	// renterProfilePathPrefix is the path prefix that selects the renter
+	// profile a request is meant for, e.g. /profiles/<name>/renter/files. The
+	// prefix takes precedence over the RenterProfileHeader.
+	renterProfilePathPrefix = "/profiles/"
+)
+
+// errNoRenterProfiles is returned when a request selects a renter profile but
+// the node doesn't run any renter profiles.
+var errNoRenterProfiles = errors.New("node doesn't support renter profiles")
+
+type (
+	// RenterProfilesGET contains the names of the renter profiles.
+	RenterProfilesGET struct {
+		Profiles []string `json:"profiles"`
+	}
+)
+
+// parseRenterProfile returns the name of the renter profile selected by the
+// request and the request's path without the profile prefix. An empty name
+// refers to the main renter.
+func parseRenterProfile(req *http.Request) (string, string) {
+	path := req.URL.Path
+	if strings.HasPrefix(path, renterProfilePathPrefix) {
+		rest := strings.TrimPrefix(path, renterProfilePathPrefix)
+		if i := strings.Index(rest, "/"); i > 0 {
+			return rest[:i], rest[i:]
+		}
+	}
+	return req.Header.Get(RenterProfileHeader), path
+}
+
+// SetRenterProfiles allows for setting the renter profiles of the API at
+// runtime.
+func (api *API) SetRenterProfiles(rp modules.RenterProfiles) {
+	api.profileMu.Lock()
+	api.renterProfiles = rp
+	api.profileAPIs = make(map[string]*API)
+	api.profileMu.Unlock()
+	api.buildHTTPRoutes()
+}
+
+// managedProfileAPI returns the API that serves the requests for the renter
+// profile with the given name. The API shares all modules with the main API
+// except for the renter.
+func (api *API) managedProfileAPI(name string) (*API, error) {
+	api.profileMu.Lock()
+	defer api.profileMu.Unlock()
+	if api.renterProfiles == nil {
+		return nil, errNoRenterProfiles
+	}
+	r, err := api.renterProfiles.Profile(name)
+	if err != nil {
+		return nil, err
+	}
+	// Reuse the cached API if the profile wasn't replaced in the meantime.
+	if profileAPI, exists := api.profileAPIs[name]; exists && profileAPI.renter == r {
+		return profileAPI, nil
+	}
+	profileAPI := &API{
+		cs:                api.cs,
+		events:            api.events,
+		eventStreamsStop:  api.eventStreamsStop,
+		explorer:          api.explorer,
+		gateway:           api.gateway,
+		host:              api.host,
+		miner:             api.miner,
+		renter:            r,
+		tpool:             api.tpool,
+		wallet:            api.wallet,
+		downloads:         make(map[modules.DownloadID]func()),
+		requiredUserAgent: api.requiredUserAgent,
+		requiredPassword:  api.requiredPassword,
+		Shutdown:          api.Shutdown,
+		siadConfig:        api.siadConfig,
+	}
+	profileAPI.router = profileAPI.buildRouter()
+	api.profileAPIs[name] = profileAPI
+	return profileAPI, nil
+}
+
+// renterProfileHandler is middleware that forwards the requests that select a
+// renter profile to the API of that profile.
+func (api *API) renterProfileHandler(router *httprouter.Router) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		name, path := parseRenterProfile(req)
+		if path != req.URL.Path {
+			req.URL.Path = path
+			req.URL.RawPath = ""
+		}
+		if name == "" || name == modules.DefaultRenterProfile {
+			router.ServeHTTP(w, req)
+			return
+		}
+		profileAPI, err := api.managedProfileAPI(name)
+		if err != nil {
+			WriteError(w, Error{"unable to select renter profile: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		profileAPI.ServeHTTP(w, req)
+	})
+}
+
+// renterProfilesHandlerGET handles the API call to list the renter profiles.
+func (api *API) renterProfilesHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, RenterProfilesGET{
+		Profiles: api.renterProfiles.Profiles(),
+	})
+}
+
+// renterProfilesCreateHandlerPOST handles the API call to create a renter
+// profile.
+func (api *API) renterProfilesCreateHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if name == "" {
+		WriteError(w, Error{"name of the profile needs to be specified"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renterProfiles.CreateProfile(name); err != nil {
+		WriteError(w, Error{"unable to create renter profile: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// renterProfilesRemoveHandlerPOST handles the API call to remove a renter
+// profile.
+func (api *API) renterProfilesRemoveHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	name := req.FormValue("name")
+	if name == "" {
+		WriteError(w, Error{"name of the profile needs to be specified"}, http.StatusBadRequest)
+		return
+	}
+	if err := api.renterProfiles.RemoveProfile(name); err != nil {
+		WriteError(w, Error{"unable to remove renter profile: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	api.profileMu.Lock()
+	delete(api.profileAPIs, name)
+	api.profileMu.Unlock()
+	WriteSuccess(w)
+}
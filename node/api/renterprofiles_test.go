@@ -0,0 +1,33 @@
+package api
+
+import (
+	"net/http/httptest"
+	"testing"
+)
+
+// TestParseRenterProfile is a unit test for parseRenterProfile.
+func TestParseRenterProfile(t *testing.T) {
+	tests := []struct {
+		path    string
+		header  string
+		profile string
+		newPath string
+	}{
+		{"/renter/files", "", "", "/renter/files"},
+		{"/renter/files", "a", "a", "/renter/files"},
+		{"/profiles/a/renter/files", "", "a", "/renter/files"},
+		{"/profiles/a/renter/files", "b", "a", "/renter/files"},
+		{"/profiles/a", "", "", "/profiles/a"},
+		{"/profiles//renter", "", "", "/profiles//renter"},
+	}
+	for _, test := range tests {
+		req := httptest.NewRequest("GET", test.path, nil)
+		if test.header != "" {
+			req.Header.Set(RenterProfileHeader, test.header)
+		}
+		profile, path := parseRenterProfile(req)
+		if profile != test.profile || path != test.newPath {
+			t.Errorf("%v (%q): expected (%q, %q), got (%q, %q)", test.path, test.header, test.profile, test.newPath, profile, path)
+		}
+	}
+}
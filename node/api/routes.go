@@ -10,13 +10,22 @@ import (
 	"gitlab.com/NebulousLabs/Sia/build"
 )
 
-// buildHttpRoutes sets up and returns an * httprouter.Router.
-// it connected the Router to the given api using the required
-// parameters: requiredUserAgent and requiredPassword
+// buildHttpRoutes sets up the api's router and connects it to the given api
+// using the required parameters: requiredUserAgent and requiredPassword
 func (api *API) buildHTTPRoutes() {
+	router := api.buildRouter()
+
+	// Apply UserAgent middleware and set the Router
+	api.routerMu.Lock()
+	api.router = cleanCloseHandler(RequireUserAgent(api.renterProfileHandler(router), api.requiredUserAgent))
+	api.routerMu.Unlock()
+}
+
+// buildRouter sets up and returns an *httprouter.Router with all the routes of
+// the api's modules.
+func (api *API) buildRouter() *httprouter.Router {
 	router := httprouter.New()
 	requiredPassword := api.requiredPassword
-	requiredUserAgent := api.requiredUserAgent
 
 	router.NotFound = http.HandlerFunc(UnrecognizedCallHandler)
 	router.RedirectTrailingSlash = false
@@ -31,6 +40,14 @@ func (api *API) buildHTTPRoutes() {
 	router.GET("/daemon/settings", api.daemonSettingsHandlerGET)
 	router.POST("/daemon/settings", api.daemonSettingsHandlerPOST)
 
+	// Event API Calls
+	if api.events != nil {
+		router.GET("/daemon/events", api.daemonEventsHandlerGET)
+		router.GET("/daemon/webhooks", api.daemonWebhooksHandlerGET)
+		router.POST("/daemon/webhooks/add", RequirePassword(api.daemonWebhooksAddHandlerPOST, requiredPassword))
+		router.POST("/daemon/webhooks/remove", RequirePassword(api.daemonWebhooksRemoveHandlerPOST, requiredPassword))
+	}
+
 	// Consensus API Calls
 	if api.cs != nil {
 		router.GET("/consensus", api.consensusHandler)
@@ -62,13 +79,20 @@ func (api *API) buildHTTPRoutes() {
 		router.GET("/host", api.hostHandlerGET)                                                   // Get the host status.
 		router.POST("/host", RequirePassword(api.hostHandlerPOST, requiredPassword))              // Change the settings of the host.
 		router.POST("/host/announce", RequirePassword(api.hostAnnounceHandler, requiredPassword)) // Announce the host to the network.
+		router.GET("/host/clients", api.hostClientsHandlerGET)                                    // Get the resources consumed by clients.
 		router.GET("/host/contracts", api.hostContractInfoHandler)                                // Get info about contracts.
+		router.GET("/host/contracts/:id", api.hostContractHandler)                                // Get details of a contract.
+		router.GET("/host/contractsummary", api.hostContractSummaryHandler)                       // Get contract expirations per week.
 		router.GET("/host/estimatescore", api.hostEstimateScoreGET)
 		router.GET("/host/bandwidth", api.hostBandwidthHandlerGET)
+		router.GET("/host/maintenance", api.hostMaintenanceHandlerGET)
+		router.POST("/host/maintenance/start", RequirePassword(api.hostMaintenanceStartHandler, requiredPassword))
+		router.POST("/host/maintenance/stop", RequirePassword(api.hostMaintenanceStopHandler, requiredPassword))
 
 		// Calls pertaining to the storage manager that the host uses.
 		router.GET("/host/storage", api.storageHandler)
 		router.POST("/host/storage/folders/add", RequirePassword(api.storageFoldersAddHandler, requiredPassword))
+		router.POST("/host/storage/folders/migrate", RequirePassword(api.storageFoldersMigrateHandler, requiredPassword))
 		router.POST("/host/storage/folders/remove", RequirePassword(api.storageFoldersRemoveHandler, requiredPassword))
 		router.POST("/host/storage/folders/resize", RequirePassword(api.storageFoldersResizeHandler, requiredPassword))
 		router.POST("/host/storage/sectors/delete/:merkleroot", RequirePassword(api.storageSectorsDeleteHandler, requiredPassword))
@@ -93,6 +117,11 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/renter/backups/create", RequirePassword(api.renterBackupsCreateHandlerPOST, requiredPassword))
 		router.POST("/renter/backups/restore", RequirePassword(api.renterBackupsRestoreHandlerGET, requiredPassword))
 		router.POST("/renter/contract/cancel", RequirePassword(api.renterContractCancelHandler, requiredPassword))
+		router.POST("/renter/contract/form", RequirePassword(api.renterContractFormHandlerPOST, requiredPassword))
+		router.POST("/renter/contract/pin", RequirePassword(api.renterContractPinHandlerPOST, requiredPassword))
+		router.POST("/renter/contract/refresh", RequirePassword(api.renterContractRefreshHandlerPOST, requiredPassword))
+		router.POST("/renter/contract/renew", RequirePassword(api.renterContractRenewHandlerPOST, requiredPassword))
+		router.POST("/renter/contract/unpin", RequirePassword(api.renterContractUnpinHandlerPOST, requiredPassword))
 		router.GET("/renter/contracts", api.renterContractsHandler)
 		router.GET("/renter/contractorchurnstatus", api.renterContractorChurnStatus)
 
@@ -138,6 +167,13 @@ func (api *API) buildHTTPRoutes() {
 		router.GET("/hostdb/filtermode", api.hostdbFilterModeHandlerGET)
 		router.POST("/hostdb/filtermode", RequirePassword(api.hostdbFilterModeHandlerPOST, requiredPassword))
 
+		// Renter profile endpoints.
+		if api.renterProfiles != nil {
+			router.GET("/renter/profiles", api.renterProfilesHandlerGET)
+			router.POST("/renter/profiles/create", RequirePassword(api.renterProfilesCreateHandlerPOST, requiredPassword))
+			router.POST("/renter/profiles/remove", RequirePassword(api.renterProfilesRemoveHandlerPOST, requiredPassword))
+		}
+
 		// Renter watchdog endpoints.
 		router.GET("/renter/contractstatus", api.renterContractStatusHandler)
 
@@ -153,26 +189,51 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/tpool/raw", api.tpoolRawHandlerPOST)
 		router.GET("/tpool/confirmed/:id", api.tpoolConfirmedGET)
 		router.GET("/tpool/transactions", api.tpoolTransactionsHandler)
+		router.GET("/tpool/sets", api.tpoolSetsHandlerGET)
+		router.GET("/tpool/sets/:id", api.tpoolSetHandlerGET)
+		router.GET("/tpool/rejections", api.tpoolRejectionsHandlerGET)
 	}
 
 	// Wallet API Calls
 	if api.wallet != nil {
 		router.GET("/wallet", api.walletHandler)
 		router.POST("/wallet/033x", RequirePassword(api.wallet033xHandler, requiredPassword))
+		router.GET("/wallet/accounts", RequirePassword(api.walletAccountsHandlerGET, requiredPassword))
+		router.POST("/wallet/accounts", RequirePassword(api.walletAccountsHandlerPOST, requiredPassword))
+		router.POST("/wallet/accounts/rename", RequirePassword(api.walletAccountsRenameHandler, requiredPassword))
+		router.GET("/wallet/accounts/transactions", RequirePassword(api.walletAccountsTransactionsHandler, requiredPassword))
 		router.GET("/wallet/address", RequirePassword(api.walletAddressHandler, requiredPassword))
 		router.GET("/wallet/addresses", api.walletAddressesHandler)
 		router.GET("/wallet/seedaddrs", api.walletSeedAddressesHandler)
 		router.GET("/wallet/backup", RequirePassword(api.walletBackupHandler, requiredPassword))
 		router.POST("/wallet/init", RequirePassword(api.walletInitHandler, requiredPassword))
 		router.POST("/wallet/init/seed", RequirePassword(api.walletInitSeedHandler, requiredPassword))
+		router.GET("/wallet/labels", api.walletLabelsHandlerGET)
+		router.POST("/wallet/labels", RequirePassword(api.walletLabelsHandlerPOST, requiredPassword))
 		router.POST("/wallet/lock", RequirePassword(api.walletLockHandler, requiredPassword))
+		router.POST("/wallet/multisig/address", RequirePassword(api.walletMultisigAddressHandler, requiredPassword))
+		router.POST("/wallet/multisig/broadcast", RequirePassword(api.walletMultisigBroadcastHandler, requiredPassword))
+		router.POST("/wallet/multisig/fund", RequirePassword(api.walletMultisigFundHandler, requiredPassword))
+		router.POST("/wallet/multisig/merge", RequirePassword(api.walletMultisigMergeHandler, requiredPassword))
+		router.POST("/wallet/multisig/sign", RequirePassword(api.walletMultisigSignHandler, requiredPassword))
+		router.GET("/wallet/notes", api.walletNotesHandler)
+		router.GET("/wallet/paymentrequests", api.walletPaymentRequestsHandlerGET)
+		router.POST("/wallet/paymentrequests", RequirePassword(api.walletPaymentRequestsHandlerPOST, requiredPassword))
+		router.GET("/wallet/scheduled", RequirePassword(api.walletScheduledHandlerGET, requiredPassword))
+		router.POST("/wallet/scheduled", RequirePassword(api.walletScheduledHandlerPOST, requiredPassword))
+		router.POST("/wallet/scheduled/cancel", RequirePassword(api.walletScheduledCancelHandler, requiredPassword))
 		router.POST("/wallet/seed", RequirePassword(api.walletSeedHandler, requiredPassword))
 		router.GET("/wallet/seeds", RequirePassword(api.walletSeedsHandler, requiredPassword))
+		router.GET("/wallet/signer", RequirePassword(api.walletSignerHandlerGET, requiredPassword))
+		router.POST("/wallet/signer", RequirePassword(api.walletSignerHandlerPOST, requiredPassword))
+		router.POST("/wallet/signer/addresses", RequirePassword(api.walletSignerAddressesHandler, requiredPassword))
 		router.POST("/wallet/siacoins", RequirePassword(api.walletSiacoinsHandler, requiredPassword))
 		router.POST("/wallet/siafunds", RequirePassword(api.walletSiafundsHandler, requiredPassword))
 		router.POST("/wallet/siagkey", RequirePassword(api.walletSiagkeyHandler, requiredPassword))
 		router.POST("/wallet/sweep/seed", RequirePassword(api.walletSweepSeedHandler, requiredPassword))
 		router.GET("/wallet/transaction/:id", api.walletTransactionHandler)
+		router.POST("/wallet/transaction/:id/bump", RequirePassword(api.walletTransactionBumpHandler, requiredPassword))
+		router.POST("/wallet/transaction/:id/note", RequirePassword(api.walletTransactionNoteHandler, requiredPassword))
 		router.GET("/wallet/transactions", api.walletTransactionsHandler)
 		router.GET("/wallet/transactions/:addr", api.walletTransactionsAddrHandler)
 		router.GET("/wallet/verify/address/:addr", api.walletVerifyAddressHandler)
@@ -187,11 +248,7 @@ func (api *API) buildHTTPRoutes() {
 		router.POST("/wallet/watch", RequirePassword(api.walletWatchHandlerPOST, requiredPassword))
 	}
 
-	// Apply UserAgent middleware and return the Router
-	api.routerMu.Lock()
-	api.router = cleanCloseHandler(RequireUserAgent(router, requiredUserAgent))
-	api.routerMu.Unlock()
-	return
+	return router
 }
 
 // cleanCloseHandler wraps the entire API, ensuring that underlying conns are
@@ -258,5 +315,6 @@ func RequirePassword(h httprouter.Handle, password string) httprouter.Handle {
 
 // isUnrestricted checks if a request may bypass the useragent check.
 func isUnrestricted(req *http.Request) bool {
-	return strings.HasPrefix(req.URL.Path, "/renter/stream/")
+	_, path := parseRenterProfile(req)
+	return strings.HasPrefix(path, "/renter/stream/")
 }
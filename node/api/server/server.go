@@ -197,6 +197,8 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 
 		// Set the shutdown method to allow the api to shutdown the server.
 		api.Shutdown = srv.Close
+		// Event streams don't end on their own and would block the shutdown.
+		srv.apiServer.RegisterOnShutdown(api.StopEventStreams)
 
 		// Spin up a goroutine that serves the API and closes srv.done when
 		// finished.
@@ -226,6 +228,12 @@ func NewAsync(APIaddr string, requiredUserAgent string, requiredPassword string,
 		// Server wasn't shut down. Add node and replace modules.
 		srv.node = n
 		api.SetModules(n.ConsensusSet, n.Explorer, n.Gateway, n.Host, n.Miner, n.Renter, n.TransactionPool, n.Wallet)
+		if n.RenterProfiles != nil {
+			api.SetRenterProfiles(n.RenterProfiles)
+		}
+		if n.EventManager != nil {
+			api.SetEventManager(n.EventManager)
+		}
 		return srv, nil
 	}()
 	if err != nil {
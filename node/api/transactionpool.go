@@ -3,6 +3,7 @@ package api
 import (
 	"encoding/base64"
 	"encoding/json"
+	"fmt"
 	"net/http"
 
 	"github.com/julienschmidt/httprouter"
@@ -18,6 +19,9 @@ type (
 	TpoolFeeGET struct {
 		Minimum types.Currency `json:"minimum"`
 		Maximum types.Currency `json:"maximum"`
+
+		// Estimate is only set if a target was provided.
+		Estimate *modules.TargetFeeEstimate `json:"estimate,omitempty"`
 	}
 
 	// TpoolRawGET contains the requested transaction encoded to the raw
@@ -38,6 +42,24 @@ type (
 	TpoolTxnsGET struct {
 		Transactions []types.Transaction `json:"transactions"`
 	}
+
+	// TpoolSetsGET contains the transaction sets of the tpool.
+	TpoolSetsGET struct {
+		Sets []modules.TransactionSetInfo `json:"sets"`
+	}
+
+	// TpoolSetGET contains a transaction set of the tpool and its
+	// transactions.
+	TpoolSetGET struct {
+		Set          modules.TransactionSetInfo `json:"set"`
+		Transactions []types.Transaction        `json:"transactions"`
+	}
+
+	// TpoolRejectionsGET contains the transaction sets that were recently
+	// rejected by the tpool.
+	TpoolRejectionsGET struct {
+		Rejections []modules.TransactionSetRejection `json:"rejections"`
+	}
 )
 
 // decodeTransactionID will decode a transaction id from a string.
@@ -54,10 +76,33 @@ func decodeTransactionID(txidStr string) (types.TransactionID, error) {
 // fees are lower than the estimated fee may take longer to confirm.
 func (api *API) tpoolFeeHandlerGET(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	min, max := api.tpool.FeeEstimation()
-	WriteJSON(w, TpoolFeeGET{
+	tfg := TpoolFeeGET{
 		Minimum: min,
 		Maximum: max,
-	})
+	}
+
+	// Estimate the fee to get confirmed within the target if one was
+	// provided.
+	if targetStr := req.FormValue("target"); targetStr != "" {
+		var target types.BlockHeight
+		if _, err := fmt.Sscan(targetStr, &target); err != nil || target == 0 {
+			WriteError(w, Error{"unable to parse target: must be a number of blocks greater than zero"}, http.StatusBadRequest)
+			return
+		}
+		confidence := modules.DefaultFeeConfidence
+		if confidenceStr := req.FormValue("confidence"); confidenceStr != "" {
+			if _, err := fmt.Sscan(confidenceStr, &confidence); err != nil || confidence <= 0 || confidence > 1 {
+				WriteError(w, Error{"unable to parse confidence: must be a number between 0 and 1"}, http.StatusBadRequest)
+				return
+			}
+		}
+		est := api.tpool.FeeEstimationTarget(target, confidence)
+		tfg.Estimate = &est
+	} else if req.FormValue("confidence") != "" {
+		WriteError(w, Error{"confidence requires a target"}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, tfg)
 }
 
 // tpoolRawHandlerGET will provide the raw byte representation of a
@@ -147,3 +192,46 @@ func (api *API) tpoolTransactionsHandler(w http.ResponseWriter, req *http.Reques
 		Transactions: txns,
 	})
 }
+
+// tpoolRejectionsHandlerGET returns the transaction sets that were recently
+// rejected by the transaction pool, newest first.
+func (api *API) tpoolRejectionsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, TpoolRejectionsGET{
+		Rejections: api.tpool.RecentRejections(),
+	})
+}
+
+// tpoolSetsHandlerGET returns the transaction sets of the transaction pool.
+func (api *API) tpoolSetsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	WriteJSON(w, TpoolSetsGET{
+		Sets: api.tpool.TransactionSetsInfo(),
+	})
+}
+
+// tpoolSetHandlerGET returns the transaction set with the provided id, or the
+// set containing the transaction with the provided id.
+func (api *API) tpoolSetHandlerGET(w http.ResponseWriter, _ *http.Request, ps httprouter.Params) {
+	var id crypto.Hash
+	if err := id.LoadString(ps.ByName("id")); err != nil {
+		WriteError(w, Error{"error decoding id:" + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	for _, set := range api.tpool.TransactionSetsInfo() {
+		found := set.ID == modules.TransactionSetID(id)
+		for _, ti := range set.Transactions {
+			found = found || ti.ID == types.TransactionID(id)
+		}
+		if !found {
+			continue
+		}
+		tsg := TpoolSetGET{Set: set}
+		for _, ti := range set.Transactions {
+			if txn, _, exists := api.tpool.Transaction(ti.ID); exists {
+				tsg.Transactions = append(tsg.Transactions, txn)
+			}
+		}
+		WriteJSON(w, tsg)
+		return
+	}
+	WriteError(w, Error{"transaction set not found in transaction pool"}, http.StatusBadRequest)
+}
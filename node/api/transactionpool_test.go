@@ -9,7 +9,9 @@ import (
 	"time"
 
 	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/crypto"
 	"gitlab.com/NebulousLabs/Sia/encoding"
+	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/types"
 )
 
@@ -203,6 +205,26 @@ func TestTransactionPoolFee(t *testing.T) {
 	if !min.Equals(fees.Minimum) || !max.Equals(fees.Maximum) {
 		t.Fatal("fee mismatch")
 	}
+	if fees.Estimate != nil {
+		t.Fatal("estimate shouldn't be set without a target")
+	}
+
+	// Request an estimate for a target.
+	err = st.getAPI("/tpool/fee?target=3&confidence=0.8", &fees)
+	if err != nil {
+		t.Fatal(err)
+	}
+	est := st.tpool.FeeEstimationTarget(3, 0.8)
+	if fees.Estimate == nil || !fees.Estimate.FeePerByte.Equals(est.FeePerByte) || fees.Estimate.Target != 3 || fees.Estimate.Confidence != 0.8 {
+		t.Fatal("estimate mismatch", fees.Estimate, est)
+	}
+
+	// Invalid parameters are rejected.
+	for _, query := range []string{"target=0", "target=foo", "target=3&confidence=2", "confidence=0.5"} {
+		if err := st.getAPI("/tpool/fee?"+query, &fees); err == nil {
+			t.Fatal("expected query to be rejected:", query)
+		}
+	}
 }
 
 // TestTransactionPoolConfirmed tests the /tpool/confirmed endpoint.
@@ -256,3 +278,65 @@ func TestTransactionPoolConfirmed(t *testing.T) {
 		t.Fatal("transaction should not be confirmed")
 	}
 }
+
+// TestTransactionPoolSets tests the /tpool/sets and /tpool/rejections
+// endpoints.
+func TestTransactionPoolSets(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+	st, err := createServerTester(t.Name())
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer st.panicClose()
+
+	// Create a transaction.
+	txns, err := st.wallet.SendSiacoins(types.SiacoinPrecision.Mul64(1000), types.UnlockHash{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	txnID := txns[len(txns)-1].ID()
+
+	var tsg TpoolSetsGET
+	if err := st.getAPI("/tpool/sets", &tsg); err != nil {
+		t.Fatal(err)
+	}
+	if len(tsg.Sets) == 0 {
+		t.Fatal("expected transaction sets in the pool")
+	}
+
+	// The set can be looked up by the id of one of its transactions and by
+	// its own id.
+	var set TpoolSetGET
+	if err := st.getAPI("/tpool/sets/"+txnID.String(), &set); err != nil {
+		t.Fatal(err)
+	}
+	if len(set.Transactions) != len(set.Set.Transactions) || set.Transactions[len(set.Transactions)-1].ID() != txnID {
+		t.Fatal("unexpected transaction set", set.Set)
+	}
+	setID := crypto.Hash(set.Set.ID).String()
+	if err := st.getAPI("/tpool/sets/"+setID, &set); err != nil {
+		t.Fatal(err)
+	}
+	if err := st.getAPI("/tpool/sets/"+crypto.Hash{}.String(), &set); err == nil {
+		t.Fatal("expected unknown set to be rejected")
+	}
+
+	// Rejections are reported.
+	txn := types.Transaction{ArbitraryData: [][]byte{[]byte("non-standard arbitrary data")}}
+	values := url.Values{}
+	values.Set("transaction", base64.StdEncoding.EncodeToString(encoding.Marshal(txn)))
+	values.Set("parents", base64.StdEncoding.EncodeToString(encoding.Marshal([]types.Transaction{})))
+	if err := st.stdPostAPI("/tpool/raw", values); err == nil {
+		t.Fatal("expected non-standard transaction to be rejected")
+	}
+	var trg TpoolRejectionsGET
+	if err := st.getAPI("/tpool/rejections", &trg); err != nil {
+		t.Fatal(err)
+	}
+	if len(trg.Rejections) != 1 || trg.Rejections[0].Reason != modules.RejectionNonStandard || trg.Rejections[0].TransactionIDs[0] != txn.ID() {
+		t.Fatal("unexpected rejections", trg.Rejections)
+	}
+}
@@ -1,11 +1,13 @@
 package api
 
 import (
+	"encoding/csv"
 	"encoding/json"
 	"fmt"
 	"math"
 	"net/http"
 	"path/filepath"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -27,6 +29,8 @@ type (
 		Unlocked   bool              `json:"unlocked"`
 
 		ConfirmedSiacoinBalance     types.Currency `json:"confirmedsiacoinbalance"`
+		SpendableSiacoinBalance     types.Currency `json:"spendablesiacoinbalance"`
+		LockedSiacoinBalance        types.Currency `json:"lockedsiacoinbalance"`
 		UnconfirmedOutgoingSiacoins types.Currency `json:"unconfirmedoutgoingsiacoins"`
 		UnconfirmedIncomingSiacoins types.Currency `json:"unconfirmedincomingsiacoins"`
 
@@ -36,6 +40,17 @@ type (
 		DustThreshold types.Currency `json:"dustthreshold"`
 	}
 
+	// WalletAccountsGET contains the accounts of the wallet.
+	WalletAccountsGET struct {
+		Accounts []modules.WalletAccount `json:"accounts"`
+	}
+
+	// WalletAccountTransactionsGET contains the confirmed and unconfirmed
+	// transactions of a wallet account.
+	WalletAccountTransactionsGET struct {
+		Transactions []modules.ProcessedTransaction `json:"transactions"`
+	}
+
 	// WalletAddressGET contains an address returned by a GET call to
 	// /wallet/address.
 	WalletAddressGET struct {
@@ -59,6 +74,16 @@ type (
 	WalletSiacoinsPOST struct {
 		Transactions   []types.Transaction   `json:"transactions"`
 		TransactionIDs []types.TransactionID `json:"transactionids"`
+		// FeeBreakdown is only set if coin-control parameters were provided.
+		FeeBreakdown *modules.FeeBreakdown `json:"feebreakdown,omitempty"`
+	}
+
+	// WalletTransactionBumpPOST contains the transaction created in the POST
+	// call to /wallet/transaction/:id/bump.
+	WalletTransactionBumpPOST struct {
+		Transaction   types.Transaction    `json:"transaction"`
+		TransactionID types.TransactionID  `json:"transactionid"`
+		FeeBreakdown  modules.FeeBreakdown `json:"feebreakdown"`
 	}
 
 	// WalletSiafundsPOST contains the transaction sent in the POST call to
@@ -68,6 +93,54 @@ type (
 		TransactionIDs []types.TransactionID `json:"transactionids"`
 	}
 
+	// WalletMultisigAddressPOSTParams contains the public keys and the number
+	// of required signatures of a new multisig address.
+	WalletMultisigAddressPOSTParams struct {
+		PublicKeys         []types.SiaPublicKey `json:"publickeys"`
+		SignaturesRequired uint64               `json:"signaturesrequired"`
+		Unused             bool                 `json:"unused"`
+	}
+
+	// WalletMultisigAddressPOST contains a new multisig address and its
+	// unlock conditions.
+	WalletMultisigAddressPOST struct {
+		Address          types.UnlockHash       `json:"address"`
+		UnlockConditions types.UnlockConditions `json:"unlockconditions"`
+	}
+
+	// WalletMultisigFundPOSTParams contains the multisig address to spend
+	// from and the outputs to send.
+	WalletMultisigFundPOSTParams struct {
+		Address types.UnlockHash      `json:"address"`
+		Outputs []types.SiacoinOutput `json:"outputs"`
+	}
+
+	// WalletMultisigPOSTParams contains a partially signed transaction.
+	WalletMultisigPOSTParams struct {
+		Transaction modules.PartiallySignedTransaction `json:"transaction"`
+	}
+
+	// WalletMultisigMergePOSTParams contains the partially signed
+	// transactions to merge.
+	WalletMultisigMergePOSTParams struct {
+		Transactions []modules.PartiallySignedTransaction `json:"transactions"`
+	}
+
+	// WalletMultisigPOSTResp contains a partially signed transaction and the
+	// signatures collected for each of its inputs.
+	WalletMultisigPOSTResp struct {
+		Transaction  modules.PartiallySignedTransaction `json:"transaction"`
+		Status       []modules.MultisigInputStatus      `json:"status"`
+		ThresholdMet bool                               `json:"thresholdmet"`
+	}
+
+	// WalletMultisigBroadcastPOSTResp contains the transaction that was
+	// broadcast.
+	WalletMultisigBroadcastPOSTResp struct {
+		Transaction   types.Transaction   `json:"transaction"`
+		TransactionID types.TransactionID `json:"transactionid"`
+	}
+
 	// WalletSignPOSTParams contains the unsigned transaction and a set of
 	// inputs to sign.
 	WalletSignPOSTParams struct {
@@ -107,6 +180,32 @@ type (
 		UnconfirmedTransactions []modules.ProcessedTransaction `json:"unconfirmedtransactions"`
 	}
 
+	// WalletLabelsGET contains the address labels of the wallet.
+	WalletLabelsGET struct {
+		Labels []modules.AddressLabel `json:"labels"`
+	}
+
+	// WalletNotesGET contains the transaction notes of the wallet.
+	WalletNotesGET struct {
+		Notes []modules.TransactionNote `json:"notes"`
+	}
+
+	// WalletPaymentRequestsGET contains the payment requests of the wallet.
+	WalletPaymentRequestsGET struct {
+		PaymentRequests []modules.PaymentRequest `json:"paymentrequests"`
+	}
+
+	// WalletScheduledGET contains the queue of scheduled payments.
+	WalletScheduledGET struct {
+		ScheduledPayments []modules.ScheduledPayment `json:"scheduledpayments"`
+	}
+
+	// WalletSignerAddressesPOST contains the unlock conditions of the
+	// addresses fetched from the signer.
+	WalletSignerAddressesPOST struct {
+		UnlockConditions []types.UnlockConditions `json:"unlockconditions"`
+	}
+
 	// WalletTransactionsGETaddr contains the set of wallet transactions
 	// relevant to the input address provided in the call to
 	// /wallet/transaction/:addr
@@ -183,6 +282,15 @@ func (api *API) walletHandler(w http.ResponseWriter, req *http.Request, _ httpro
 		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
 		return
 	}
+	lockedBal, err := api.wallet.LockedBalance()
+	if err != nil {
+		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
+		return
+	}
+	if lockedBal.Cmp(siacoinBal) > 0 {
+		// A block might have arrived in between the calls.
+		lockedBal = siacoinBal
+	}
 	siacoinsOut, siacoinsIn, err := api.wallet.UnconfirmedBalance()
 	if err != nil {
 		WriteError(w, Error{fmt.Sprintf("Error when calling /wallet: %v", err)}, http.StatusBadRequest)
@@ -220,6 +328,8 @@ func (api *API) walletHandler(w http.ResponseWriter, req *http.Request, _ httpro
 		Height:     height,
 
 		ConfirmedSiacoinBalance:     siacoinBal,
+		SpendableSiacoinBalance:     siacoinBal.Sub(lockedBal),
+		LockedSiacoinBalance:        lockedBal,
 		UnconfirmedOutgoingSiacoins: siacoinsOut,
 		UnconfirmedIncomingSiacoins: siacoinsIn,
 
@@ -253,9 +363,71 @@ func (api *API) wallet033xHandler(w http.ResponseWriter, req *http.Request, _ ht
 	WriteError(w, Error{modules.ErrBadEncryptionKey.Error()}, http.StatusBadRequest)
 }
 
+// walletAccountsHandlerGET handles GET calls to /wallet/accounts.
+func (api *API) walletAccountsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	accounts, err := api.wallet.Accounts()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/accounts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletAccountsGET{
+		Accounts: accounts,
+	})
+}
+
+// walletAccountsHandlerPOST handles POST calls to /wallet/accounts.
+func (api *API) walletAccountsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	wa, err := api.wallet.CreateAccount(req.FormValue("name"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/accounts: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, wa)
+}
+
+// walletAccountsRenameHandler handles API calls to /wallet/accounts/rename.
+func (api *API) walletAccountsRenameHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.wallet.RenameAccount(req.FormValue("name"), req.FormValue("newname")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/accounts/rename: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletAccountsTransactionsHandler handles API calls to
+// /wallet/accounts/transactions.
+func (api *API) walletAccountsTransactionsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	pts, err := api.wallet.AccountTransactions(req.FormValue("account"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/accounts/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletAccountTransactionsGET{
+		Transactions: pts,
+	})
+}
+
 // walletAddressHandler handles API calls to /wallet/address.
 func (api *API) walletAddressHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	unlockConditions, err := api.wallet.NextAddress()
+	var unlockConditions types.UnlockConditions
+	var err error
+	account := req.FormValue("account")
+	if timelockStr := req.FormValue("timelock"); timelockStr != "" && account != "" {
+		WriteError(w, Error{"timelocked addresses can only be generated by the primary account"}, http.StatusBadRequest)
+		return
+	} else if account != "" {
+		unlockConditions, err = api.wallet.NextAccountAddress(account)
+	} else if timelockStr != "" {
+		var timelock uint64
+		timelock, err = strconv.ParseUint(timelockStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"unable to parse timelock: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		unlockConditions, err = api.wallet.NextTimelockedAddress(types.BlockHeight(timelock))
+	} else {
+		unlockConditions, err = api.wallet.NextAddress()
+	}
 	if err != nil {
 		WriteError(w, Error{"error when calling /wallet/addresses: " + err.Error()}, http.StatusBadRequest)
 		return
@@ -491,9 +663,78 @@ func (api *API) walletSeedsHandler(w http.ResponseWriter, req *http.Request, _ h
 	})
 }
 
+// parseCoinControl parses the coin-control parameters of a call to
+// /wallet/siacoins. The returned bool is false if none were provided.
+func parseCoinControl(req *http.Request) (cc modules.CoinControl, ok bool, err error) {
+	if inputs := req.FormValue("inputs"); inputs != "" {
+		for _, str := range strings.Split(inputs, ",") {
+			var id crypto.Hash
+			if err := id.LoadString(strings.TrimSpace(str)); err != nil {
+				return modules.CoinControl{}, false, errors.New("could not read input " + str + ": " + err.Error())
+			}
+			cc.Inputs = append(cc.Inputs, types.SiacoinOutputID(id))
+		}
+		ok = true
+	}
+	if spendAll := req.FormValue("spendall"); spendAll != "" {
+		cc.SpendAll, err = scanBool(spendAll)
+		if err != nil {
+			return modules.CoinControl{}, false, errors.New("could not read spendall: " + err.Error())
+		}
+		ok = true
+	}
+	if changeAddress := req.FormValue("changeaddress"); changeAddress != "" {
+		cc.ChangeAddress, err = scanAddress(changeAddress)
+		if err != nil {
+			return modules.CoinControl{}, false, errors.New("could not read changeaddress: " + err.Error())
+		}
+		ok = true
+	}
+	if fee := req.FormValue("fee"); fee != "" {
+		var valid bool
+		cc.Fee, valid = scanAmount(fee)
+		if !valid {
+			return modules.CoinControl{}, false, errors.New("could not read fee")
+		}
+		ok = true
+	}
+	if feePerByte := req.FormValue("feeperbyte"); feePerByte != "" {
+		var valid bool
+		cc.FeePerByte, valid = scanAmount(feePerByte)
+		if !valid {
+			return modules.CoinControl{}, false, errors.New("could not read feeperbyte")
+		}
+		ok = true
+	}
+	if feeTarget := req.FormValue("feetarget"); feeTarget != "" {
+		if _, err := fmt.Sscan(feeTarget, &cc.FeeTarget); err != nil {
+			return modules.CoinControl{}, false, errors.New("could not read feetarget: " + err.Error())
+		}
+		ok = true
+	}
+	if dryRun := req.FormValue("dryrun"); dryRun != "" {
+		cc.DryRun, err = scanBool(dryRun)
+		if err != nil {
+			return modules.CoinControl{}, false, errors.New("could not read dryrun: " + err.Error())
+		}
+		ok = true
+	}
+	if account := req.FormValue("account"); account != "" {
+		cc.Account = account
+		ok = true
+	}
+	return cc, ok, nil
+}
+
 // walletSiacoinsHandler handles API calls to /wallet/siacoins.
 func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
-	var txns []types.Transaction
+	cc, coinControl, err := parseCoinControl(req)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+
+	var outputs []types.SiacoinOutput
 	if req.FormValue("outputs") != "" {
 		// multiple amounts + destinations
 		if req.FormValue("amount") != "" || req.FormValue("destination") != "" {
@@ -501,36 +742,52 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 			return
 		}
 
-		var outputs []types.SiacoinOutput
 		err := json.Unmarshal([]byte(req.FormValue("outputs")), &outputs)
 		if err != nil {
 			WriteError(w, Error{"could not decode outputs: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
-		txns, err = api.wallet.SendSiacoinsMulti(outputs)
-		if err != nil {
-			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
-			return
-		}
 	} else {
-		// single amount + destination
-		amount, ok := scanAmount(req.FormValue("amount"))
-		if !ok {
-			WriteError(w, Error{"could not read amount from POST call to /wallet/siacoins"}, http.StatusBadRequest)
-			return
+		// single amount + destination. When spending all inputs, the
+		// destination receives their value and the amount may be omitted.
+		var amount types.Currency
+		if !cc.SpendAll || req.FormValue("amount") != "" {
+			var ok bool
+			amount, ok = scanAmount(req.FormValue("amount"))
+			if !ok {
+				WriteError(w, Error{"could not read amount from POST call to /wallet/siacoins"}, http.StatusBadRequest)
+				return
+			}
 		}
 		dest, err := scanAddress(req.FormValue("destination"))
 		if err != nil {
 			WriteError(w, Error{"could not read address from POST call to /wallet/siacoins"}, http.StatusBadRequest)
 			return
 		}
+		outputs = []types.SiacoinOutput{{Value: amount, UnlockHash: dest}}
+	}
 
-		txns, err = api.wallet.SendSiacoins(amount, dest)
+	var txns []types.Transaction
+	var fb *modules.FeeBreakdown
+	if coinControl {
+		txn, breakdown, err := api.wallet.SendSiacoinsCoinControl(outputs, cc)
+		if err != nil {
+			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+		txns, fb = []types.Transaction{txn}, &breakdown
+	} else if req.FormValue("outputs") != "" {
+		txns, err = api.wallet.SendSiacoinsMulti(outputs)
+		if err != nil {
+			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
+			return
+		}
+	} else {
+		txns, err = api.wallet.SendSiacoins(outputs[0].Value, outputs[0].UnlockHash)
 		if err != nil {
 			WriteError(w, Error{"error when calling /wallet/siacoins: " + err.Error()}, http.StatusInternalServerError)
 			return
 		}
-
 	}
 
 	var txids []types.TransactionID
@@ -540,6 +797,7 @@ func (api *API) walletSiacoinsHandler(w http.ResponseWriter, req *http.Request,
 	WriteJSON(w, WalletSiacoinsPOST{
 		Transactions:   txns,
 		TransactionIDs: txids,
+		FeeBreakdown:   fb,
 	})
 }
 
@@ -595,6 +853,119 @@ func (api *API) walletSweepSeedHandler(w http.ResponseWriter, req *http.Request,
 	})
 }
 
+// walletScheduledHandlerGET handles GET calls to /wallet/scheduled.
+func (api *API) walletScheduledHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	sps, err := api.wallet.ScheduledPayments()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/scheduled: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletScheduledGET{
+		ScheduledPayments: sps,
+	})
+}
+
+// walletScheduledHandlerPOST handles POST calls to /wallet/scheduled.
+func (api *API) walletScheduledHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var sp modules.ScheduledPayment
+	if err := sp.Destination.LoadString(req.FormValue("destination")); err != nil {
+		WriteError(w, Error{"could not read destination: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	amount, ok := scanAmount(req.FormValue("amount"))
+	if !ok {
+		WriteError(w, Error{"could not read amount"}, http.StatusBadRequest)
+		return
+	}
+	sp.Amount = amount
+	height, err := strconv.ParseUint(req.FormValue("height"), 10, 64)
+	if err != nil {
+		WriteError(w, Error{"could not read height: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	sp.Height = types.BlockHeight(height)
+	if intervalStr := req.FormValue("interval"); intervalStr != "" {
+		interval, err := strconv.ParseUint(intervalStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"could not read interval: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+		sp.Interval = types.BlockHeight(interval)
+	}
+	if countStr := req.FormValue("count"); countStr != "" {
+		sp.Count, err = strconv.ParseUint(countStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"could not read count: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	sp, err = api.wallet.SchedulePayment(sp)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/scheduled: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, sp)
+}
+
+// walletScheduledCancelHandler handles API calls to
+// /wallet/scheduled/cancel.
+func (api *API) walletScheduledCancelHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.wallet.CancelScheduledPayment(req.FormValue("id")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/scheduled/cancel: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletSignerHandlerGET handles GET calls to /wallet/signer.
+func (api *API) walletSignerHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	ws, err := api.wallet.Signer()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/signer: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, ws)
+}
+
+// walletSignerHandlerPOST handles POST calls to /wallet/signer.
+func (api *API) walletSignerHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	if err := api.wallet.SetSigner(req.FormValue("address")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/signer: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletSignerAddressesHandler handles API calls to /wallet/signer/addresses.
+func (api *API) walletSignerAddressesHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	n := uint64(1)
+	if nStr := req.FormValue("n"); nStr != "" {
+		var err error
+		n, err = strconv.ParseUint(nStr, 10, 64)
+		if err != nil {
+			WriteError(w, Error{"unable to parse n: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	var unused bool
+	if unusedStr := req.FormValue("unused"); unusedStr != "" {
+		var err error
+		unused, err = strconv.ParseBool(unusedStr)
+		if err != nil {
+			WriteError(w, Error{"unable to parse unused: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+	ucs, err := api.wallet.AddSignerAddresses(n, unused)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/signer/addresses: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletSignerAddressesPOST{
+		UnlockConditions: ucs,
+	})
+}
+
 // walletTransactionHandler handles API calls to /wallet/transaction/:id.
 func (api *API) walletTransactionHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
 	// Parse the id from the url.
@@ -620,6 +991,45 @@ func (api *API) walletTransactionHandler(w http.ResponseWriter, req *http.Reques
 	})
 }
 
+// walletTransactionBumpHandler handles API calls to
+// /wallet/transaction/:id/bump.
+func (api *API) walletTransactionBumpHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var id types.TransactionID
+	if err := id.UnmarshalJSON([]byte("\"" + ps.ByName("id") + "\"")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/transaction/:id/bump: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	var feePerByte types.Currency
+	if fpb := req.FormValue("feeperbyte"); fpb != "" {
+		var ok bool
+		feePerByte, ok = scanAmount(fpb)
+		if !ok {
+			WriteError(w, Error{"could not read feeperbyte"}, http.StatusBadRequest)
+			return
+		}
+	}
+	var replace bool
+	if r := req.FormValue("replace"); r != "" {
+		var err error
+		replace, err = scanBool(r)
+		if err != nil {
+			WriteError(w, Error{"could not read replace: " + err.Error()}, http.StatusBadRequest)
+			return
+		}
+	}
+
+	txn, fb, err := api.wallet.BumpTransactionFee(id, feePerByte, replace)
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transaction/:id/bump: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletTransactionBumpPOST{
+		Transaction:   txn,
+		TransactionID: txn.ID(),
+		FeeBreakdown:  fb,
+	})
+}
+
 // walletTransactionsHandler handles API calls to /wallet/transactions.
 func (api *API) walletTransactionsHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	startheightStr, endheightStr := req.FormValue("startheight"), req.FormValue("endheight")
@@ -656,10 +1066,214 @@ func (api *API) walletTransactionsHandler(w http.ResponseWriter, req *http.Reque
 		return
 	}
 
-	WriteJSON(w, WalletTransactionsGET{
-		ConfirmedTransactions:   confirmedTxns,
-		UnconfirmedTransactions: unconfirmedTxns,
-	})
+	format := req.FormValue("format")
+	if format != "" && format != "json" && format != "csv" {
+		WriteError(w, Error{"format must be either json or csv"}, http.StatusBadRequest)
+		return
+	}
+	label := req.FormValue("label")
+	if label == "" && format != "csv" {
+		WriteJSON(w, WalletTransactionsGET{
+			ConfirmedTransactions:   confirmedTxns,
+			UnconfirmedTransactions: unconfirmedTxns,
+		})
+		return
+	}
+
+	// Filter the transactions by the labels of their addresses.
+	labelList, err := api.wallet.AddressLabels()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	labels := make(map[types.UnlockHash]string)
+	for _, l := range labelList {
+		labels[l.Address] = l.Label
+	}
+	if label != "" {
+		confirmedTxns = filterTransactionsByLabel(confirmedTxns, labels, label)
+		unconfirmedTxns = filterTransactionsByLabel(unconfirmedTxns, labels, label)
+	}
+	if format != "csv" {
+		WriteJSON(w, WalletTransactionsGET{
+			ConfirmedTransactions:   confirmedTxns,
+			UnconfirmedTransactions: unconfirmedTxns,
+		})
+		return
+	}
+
+	noteList, err := api.wallet.TransactionNotes()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	notes := make(map[types.TransactionID]string)
+	for _, n := range noteList {
+		notes[n.TransactionID] = n.Note
+	}
+	w.Header().Set("Content-Type", "text/csv")
+	w.Header().Set("Content-Disposition", `attachment; filename="transactions.csv"`)
+	writeTransactionsCSV(w, confirmedTxns, unconfirmedTxns, labels, notes)
+}
+
+// transactionLabels returns the sorted, distinct labels of the addresses
+// related to a transaction.
+func transactionLabels(pt modules.ProcessedTransaction, labels map[types.UnlockHash]string) []string {
+	seen := make(map[string]struct{})
+	var related []string
+	add := func(addr types.UnlockHash) {
+		label, ok := labels[addr]
+		if _, exists := seen[label]; !ok || exists {
+			return
+		}
+		seen[label] = struct{}{}
+		related = append(related, label)
+	}
+	for _, input := range pt.Inputs {
+		add(input.RelatedAddress)
+	}
+	for _, output := range pt.Outputs {
+		add(output.RelatedAddress)
+	}
+	sort.Strings(related)
+	return related
+}
+
+// filterTransactionsByLabel returns the transactions related to an address
+// with the given label.
+func filterTransactionsByLabel(pts []modules.ProcessedTransaction, labels map[types.UnlockHash]string, label string) []modules.ProcessedTransaction {
+	filtered := []modules.ProcessedTransaction{}
+	for _, pt := range pts {
+		for _, l := range transactionLabels(pt, labels) {
+			if l == label {
+				filtered = append(filtered, pt)
+				break
+			}
+		}
+	}
+	return filtered
+}
+
+// writeTransactionsCSV writes the transactions as CSV, one row per
+// transaction with the siacoins it moved in and out of the wallet, the labels
+// of its addresses and its note.
+func writeTransactionsCSV(w http.ResponseWriter, confirmed, unconfirmed []modules.ProcessedTransaction, labels map[types.UnlockHash]string, notes map[types.TransactionID]string) {
+	cw := csv.NewWriter(w)
+	cw.Write([]string{"transactionid", "confirmed", "confirmationheight", "confirmationtimestamp", "incoming", "outgoing", "labels", "note"})
+	writeRows := func(pts []modules.ProcessedTransaction, confirmed bool) {
+		for _, pt := range pts {
+			var incoming, outgoing types.Currency
+			for _, input := range pt.Inputs {
+				if input.FundType == types.SpecifierSiacoinInput && input.WalletAddress {
+					outgoing = outgoing.Add(input.Value)
+				}
+			}
+			for _, output := range pt.Outputs {
+				if (output.FundType == types.SpecifierSiacoinOutput || output.FundType == types.SpecifierMinerPayout) && output.WalletAddress {
+					incoming = incoming.Add(output.Value)
+				}
+			}
+			height, timestamp := "", ""
+			if confirmed {
+				height = fmt.Sprint(pt.ConfirmationHeight)
+				timestamp = fmt.Sprint(pt.ConfirmationTimestamp)
+			}
+			cw.Write([]string{
+				pt.TransactionID.String(),
+				strconv.FormatBool(confirmed),
+				height,
+				timestamp,
+				incoming.String(),
+				outgoing.String(),
+				strings.Join(transactionLabels(pt, labels), ";"),
+				notes[pt.TransactionID],
+			})
+		}
+	}
+	writeRows(confirmed, true)
+	writeRows(unconfirmed, false)
+	cw.Flush()
+}
+
+// walletLabelsHandlerGET handles GET API calls to /wallet/labels.
+func (api *API) walletLabelsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	labels, err := api.wallet.AddressLabels()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/labels: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletLabelsGET{Labels: labels})
+}
+
+// walletLabelsHandlerPOST handles POST API calls to /wallet/labels.
+func (api *API) walletLabelsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	addr, err := scanAddress(req.FormValue("address"))
+	if err != nil {
+		WriteError(w, Error{"could not read address: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SetAddressLabel(addr, req.FormValue("label")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/labels: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletNotesHandler handles API calls to /wallet/notes.
+func (api *API) walletNotesHandler(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	notes, err := api.wallet.TransactionNotes()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/notes: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletNotesGET{Notes: notes})
+}
+
+// walletTransactionNoteHandler handles API calls to
+// /wallet/transaction/:id/note.
+func (api *API) walletTransactionNoteHandler(w http.ResponseWriter, req *http.Request, ps httprouter.Params) {
+	var id types.TransactionID
+	if err := id.UnmarshalJSON([]byte("\"" + ps.ByName("id") + "\"")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/transaction/:id/note: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	if err := api.wallet.SetTransactionNote(id, req.FormValue("note")); err != nil {
+		WriteError(w, Error{"error when calling /wallet/transaction/:id/note: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteSuccess(w)
+}
+
+// walletPaymentRequestsHandlerGET handles GET API calls to
+// /wallet/paymentrequests.
+func (api *API) walletPaymentRequestsHandlerGET(w http.ResponseWriter, _ *http.Request, _ httprouter.Params) {
+	prs, err := api.wallet.PaymentRequests()
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/paymentrequests: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletPaymentRequestsGET{PaymentRequests: prs})
+}
+
+// walletPaymentRequestsHandlerPOST handles POST API calls to
+// /wallet/paymentrequests.
+func (api *API) walletPaymentRequestsHandlerPOST(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	amount, ok := scanAmount(req.FormValue("amount"))
+	if !ok {
+		WriteError(w, Error{"could not read amount"}, http.StatusBadRequest)
+		return
+	}
+	expiry, err := strconv.ParseUint(req.FormValue("expiry"), 10, 64)
+	if err != nil {
+		WriteError(w, Error{"could not read expiry: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pr, err := api.wallet.CreatePaymentRequest(amount, types.BlockHeight(expiry), req.FormValue("label"))
+	if err != nil {
+		WriteError(w, Error{"error when calling /wallet/paymentrequests: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, pr)
 }
 
 // walletTransactionsAddrHandler handles API calls to
@@ -828,6 +1442,103 @@ func (api *API) walletSignHandler(w http.ResponseWriter, req *http.Request, _ ht
 	})
 }
 
+// writeMultisigResp writes a partially signed transaction along with its
+// signature status.
+func writeMultisigResp(w http.ResponseWriter, pst modules.PartiallySignedTransaction) {
+	WriteJSON(w, WalletMultisigPOSTResp{
+		Transaction:  pst,
+		Status:       pst.Status(),
+		ThresholdMet: pst.ThresholdMet(),
+	})
+}
+
+// walletMultisigAddressHandler handles API calls to /wallet/multisig/address.
+func (api *API) walletMultisigAddressHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigAddressPOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	uc, err := api.wallet.CreateMultisigAddress(params.PublicKeys, params.SignaturesRequired, params.Unused)
+	if err != nil {
+		WriteError(w, Error{"failed to create multisig address: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigAddressPOST{
+		Address:          uc.UnlockHash(),
+		UnlockConditions: uc,
+	})
+}
+
+// walletMultisigFundHandler handles API calls to /wallet/multisig/fund.
+func (api *API) walletMultisigFundHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigFundPOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := api.wallet.FundMultisigTransaction(params.Address, params.Outputs)
+	if err != nil {
+		WriteError(w, Error{"failed to fund multisig transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	writeMultisigResp(w, pst)
+}
+
+// walletMultisigSignHandler handles API calls to /wallet/multisig/sign.
+func (api *API) walletMultisigSignHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigPOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := api.wallet.SignMultisigTransaction(params.Transaction)
+	if err != nil {
+		WriteError(w, Error{"failed to sign multisig transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	writeMultisigResp(w, pst)
+}
+
+// walletMultisigMergeHandler handles API calls to /wallet/multisig/merge.
+func (api *API) walletMultisigMergeHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigMergePOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	pst, err := modules.MergePartiallySignedTransactions(params.Transactions...)
+	if err != nil {
+		WriteError(w, Error{"failed to merge multisig transactions: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	writeMultisigResp(w, pst)
+}
+
+// walletMultisigBroadcastHandler handles API calls to
+// /wallet/multisig/broadcast.
+func (api *API) walletMultisigBroadcastHandler(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
+	var params WalletMultisigPOSTParams
+	err := json.NewDecoder(req.Body).Decode(&params)
+	if err != nil {
+		WriteError(w, Error{"invalid parameters: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	txn, err := api.wallet.BroadcastMultisigTransaction(params.Transaction)
+	if err != nil {
+		WriteError(w, Error{"failed to broadcast multisig transaction: " + err.Error()}, http.StatusBadRequest)
+		return
+	}
+	WriteJSON(w, WalletMultisigBroadcastPOSTResp{
+		Transaction:   txn,
+		TransactionID: txn.ID(),
+	})
+}
+
 // walletWatchHandlerGET handles GET calls to /wallet/watch.
 func (api *API) walletWatchHandlerGET(w http.ResponseWriter, req *http.Request, _ httprouter.Params) {
 	addrs, err := api.wallet.WatchAddresses()
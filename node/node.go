@@ -19,6 +19,7 @@ import (
 	"gitlab.com/NebulousLabs/Sia/build"
 	"gitlab.com/NebulousLabs/Sia/modules"
 	"gitlab.com/NebulousLabs/Sia/modules/consensus"
+	"gitlab.com/NebulousLabs/Sia/modules/events"
 	"gitlab.com/NebulousLabs/Sia/modules/explorer"
 	"gitlab.com/NebulousLabs/Sia/modules/gateway"
 	"gitlab.com/NebulousLabs/Sia/modules/host"
@@ -126,6 +127,13 @@ type Node struct {
 	TransactionPool modules.TransactionPool
 	Wallet          modules.Wallet
 
+	// RenterProfiles runs the additional renter profiles of the node. It is
+	// only created together with the renter.
+	RenterProfiles modules.RenterProfiles
+
+	// EventManager publishes the events of the other modules of the node.
+	EventManager modules.EventManager
+
 	// The high level directory where all the persistence gets stored for the
 	// modules.
 	Dir string
@@ -180,9 +188,17 @@ func printfRelease(format string, a ...interface{}) (int, error) {
 // Close will call close on every module within the node, combining and
 // returning the errors.
 func (n *Node) Close() (err error) {
+	if n.EventManager != nil {
+		printlnRelease("Closing event manager...")
+		err = errors.Compose(n.EventManager.Close())
+	}
+	if n.RenterProfiles != nil {
+		printlnRelease("Closing renter profiles...")
+		err = errors.Compose(err, n.RenterProfiles.Close())
+	}
 	if n.Renter != nil {
 		printlnRelease("Closing renter...")
-		err = errors.Compose(n.Renter.Close())
+		err = errors.Compose(err, n.Renter.Close())
 	}
 	if n.Host != nil {
 		printlnRelease("Closing host...")
@@ -484,6 +500,30 @@ func New(params NodeParams, loadStartTime time.Time) (*Node, <-chan error) {
 		errChan <- errors.Extend(err, errors.New("unable to create renter"))
 		return nil, errChan
 	}
+
+	// Let the host's auto-pricing engine use the renter's view of the other
+	// hosts on the network.
+	if h != nil && r != nil {
+		h.SetMarket(r)
+	}
+
+	// Renter profiles.
+	var rp modules.RenterProfiles
+	if params.CreateRenter {
+		pm, err := renter.NewProfileManager(g, cs, tp, w, filepath.Join(dir, modules.RenterProfilesDir))
+		if err != nil {
+			errChan <- errors.Extend(err, errors.New("unable to create renter profiles"))
+			return nil, errChan
+		}
+		rp = pm
+	}
+
+	// Event manager.
+	em, err := events.New(g, cs, tp, w, r, h, filepath.Join(dir, modules.EventsDir))
+	if err != nil {
+		errChan <- errors.Extend(err, errors.New("unable to create event manager"))
+		return nil, errChan
+	}
 	printfRelease("API is now available, synchronous startup completed in %.3f seconds\n", time.Since(loadStartTime).Seconds())
 	go func() {
 		errChan <- errors.Compose(<-errChanCS, <-errChanRenter)
@@ -492,11 +532,13 @@ func New(params NodeParams, loadStartTime time.Time) (*Node, <-chan error) {
 
 	return &Node{
 		ConsensusSet:    cs,
+		EventManager:    em,
 		Explorer:        e,
 		Gateway:         g,
 		Host:            h,
 		Miner:           m,
 		Renter:          r,
+		RenterProfiles:  rp,
 		TransactionPool: tp,
 		Wallet:          w,
 
@@ -0,0 +1,136 @@
+package daemon
+
+import (
+	"bufio"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/errors"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/node"
+	"gitlab.com/NebulousLabs/Sia/siatest"
+)
+
+// TestDaemonEvents checks that the events of a node can be fetched, streamed
+// and posted to webhooks.
+func TestDaemonEvents(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	testDir := daemonTestDir(t.Name())
+
+	// Create a new server
+	testNode, err := siatest.NewCleanNode(node.Miner(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	closed := false
+	defer func() {
+		if closed {
+			return
+		}
+		if err := testNode.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Add a webhook for new blocks.
+	received := make(chan modules.Event, 10)
+	srv := httptest.NewServer(http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var e modules.Event
+		if err := json.NewDecoder(req.Body).Decode(&e); err != nil {
+			t.Error(err)
+		}
+		received <- e
+	}))
+	defer srv.Close()
+	if _, err := testNode.DaemonWebhooksAddPost(srv.URL, []modules.EventType{"foo"}, ""); err == nil {
+		t.Fatal("expected an error for an unknown event type")
+	}
+	wh, err := testNode.DaemonWebhooksAddPost(srv.URL, []modules.EventType{modules.EventConsensusBlock}, "")
+	if err != nil {
+		t.Fatal(err)
+	}
+	dwg, err := testNode.DaemonWebhooksGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if len(dwg.Webhooks) != 1 || dwg.Webhooks[0].ID != wh.ID {
+		t.Fatal("unexpected webhooks", dwg.Webhooks)
+	}
+
+	// Open an event stream.
+	req, err := testNode.NewRequest("GET", "/daemon/events", nil)
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Accept", "text/event-stream")
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		t.Fatal("unexpected status", resp.Status)
+	}
+
+	// Mine a block. Its event is returned by the API, streamed and posted to
+	// the webhook.
+	if err := testNode.MineBlock(); err != nil {
+		t.Fatal(err)
+	}
+	cg, err := testNode.ConsensusGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	var blockEvent modules.Event
+	err = build.Retry(100, 100*time.Millisecond, func() error {
+		deg, err := testNode.DaemonEventsGet(0)
+		if err != nil {
+			return err
+		}
+		for _, e := range deg.Events {
+			if e.Type == modules.EventConsensusBlock {
+				blockEvent = e
+				return nil
+			}
+		}
+		return errors.New("block event not found")
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if data := blockEvent.Data.(map[string]interface{}); data["blockid"] != cg.CurrentBlock.String() {
+		t.Fatal("unexpected block event", blockEvent)
+	}
+
+	scanner := bufio.NewScanner(resp.Body)
+	var lines []string
+	for scanner.Scan() && scanner.Text() != "" {
+		lines = append(lines, scanner.Text())
+	}
+	if len(lines) != 3 || lines[1] != "event: "+string(modules.EventConsensusBlock) || !strings.HasPrefix(lines[2], "data: ") {
+		t.Fatal("unexpected streamed event", lines)
+	}
+
+	select {
+	case e := <-received:
+		if e.ID != blockEvent.ID {
+			t.Fatal("unexpected webhook event", e)
+		}
+	case <-time.After(10 * time.Second):
+		t.Fatal("event wasn't posted to the webhook")
+	}
+
+	// The open stream doesn't prevent the node from shutting down.
+	closed = true
+	if err := testNode.Close(); err != nil {
+		t.Fatal(err)
+	}
+}
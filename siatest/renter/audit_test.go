@@ -0,0 +1,82 @@
+package renter
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"gitlab.com/NebulousLabs/Sia/build"
+	"gitlab.com/NebulousLabs/Sia/siatest"
+	"gitlab.com/NebulousLabs/Sia/types"
+)
+
+// TestRenterAudits tests that the renter audits the hosts it stores data with
+// once audits are enabled.
+func TestRenterAudits(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a group for testing
+	groupParams := siatest.GroupParams{
+		Hosts:   2,
+		Renters: 1,
+		Miners:  1,
+	}
+	testDir := renterTestDir(t.Name())
+	tg, err := siatest.NewGroupFromTemplate(testDir, groupParams)
+	if err != nil {
+		t.Fatal("Failed to create group:", err)
+	}
+	defer func() {
+		if err := tg.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+	r := tg.Renters()[0]
+
+	// Upload a file to store data on all hosts.
+	_, _, err = r.UploadNewFileBlocking(100, 1, 1, false)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Without audits enabled, no contract should be audited.
+	rc, err := r.RenterContractsGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	for _, c := range rc.ActiveContracts {
+		if c.SuccessfulAudits != 0 || c.FailedAudits != 0 {
+			t.Fatal("contract shouldn't be audited yet")
+		}
+	}
+
+	// Enable audits. Every contract with data should pass its audits.
+	if err := r.RenterAuditPost(time.Second, types.ZeroCurrency); err != nil {
+		t.Fatal(err)
+	}
+	err = build.Retry(60, time.Second, func() error {
+		rc, err := r.RenterContractsGet()
+		if err != nil {
+			return err
+		}
+		for _, c := range rc.ActiveContracts {
+			if c.Size == 0 {
+				continue
+			}
+			if c.FailedAudits != 0 || c.LostSectors != 0 {
+				return fmt.Errorf("contract failed %v audits", c.FailedAudits)
+			}
+			if c.SuccessfulAudits == 0 {
+				return errors.New("contract wasn't audited yet")
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+}
@@ -0,0 +1,87 @@
+package renter
+
+import (
+	"reflect"
+	"testing"
+
+	"gitlab.com/NebulousLabs/Sia/modules"
+	"gitlab.com/NebulousLabs/Sia/node"
+	"gitlab.com/NebulousLabs/Sia/siatest"
+)
+
+// TestRenterProfiles tests creating renter profiles and selecting them through
+// the API.
+func TestRenterProfiles(t *testing.T) {
+	if testing.Short() {
+		t.SkipNow()
+	}
+	t.Parallel()
+
+	// Create a renter.
+	testDir := renterTestDir(t.Name())
+	r, err := siatest.NewCleanNode(node.Renter(testDir))
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer func() {
+		if err := r.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}()
+
+	// Create a profile.
+	if err := r.RenterProfilesCreatePost("project"); err != nil {
+		t.Fatal(err)
+	}
+	rpg, err := r.RenterProfilesGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rpg.Profiles, []string{"project"}) {
+		t.Fatal("wrong profiles", rpg.Profiles)
+	}
+
+	// Setting the allowance of the profile shouldn't change the allowance of
+	// the main renter.
+	pc := r.Client
+	pc.RenterProfile = "project"
+	rg, err := pc.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !reflect.DeepEqual(rg.Settings.Allowance, modules.Allowance{}) {
+		t.Fatal("new profile shouldn't have an allowance")
+	}
+	if err := pc.RenterPostAllowance(siatest.DefaultAllowance); err != nil {
+		t.Fatal(err)
+	}
+	rg, err = pc.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !rg.Settings.Allowance.Funds.Equals(siatest.DefaultAllowance.Funds) {
+		t.Fatal("allowance of the profile wasn't set")
+	}
+	rg, err = r.RenterGet()
+	if err != nil {
+		t.Fatal(err)
+	}
+	if rg.Settings.Allowance.Funds.Equals(siatest.DefaultAllowance.Funds) {
+		t.Fatal("allowance of the main renter shouldn't change")
+	}
+
+	// Selecting an unknown profile should fail.
+	pc.RenterProfile = "unknown"
+	if _, err := pc.RenterGet(); err == nil {
+		t.Fatal("selecting an unknown profile should fail")
+	}
+
+	// Remove the profile.
+	if err := r.RenterProfilesRemovePost("project"); err != nil {
+		t.Fatal(err)
+	}
+	pc.RenterProfile = "project"
+	if _, err := pc.RenterGet(); err == nil {
+		t.Fatal("selecting a removed profile should fail")
+	}
+}